@@ -10,11 +10,15 @@ import (
 	"time"
 
 	"github.com/jagadeesh/grainlify/backend/internal/api"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/kyc"
 	"github.com/jagadeesh/grainlify/backend/internal/migrate"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
 	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
 )
 
@@ -43,8 +47,18 @@ func main() {
 		"nats_url_set", cfg.NATSURL != "",
 		"github_oauth_client_id_set", cfg.GitHubOAuthClientID != "",
 		"public_base_url", cfg.PublicBaseURL,
+		"stellar_network", cfg.SorobanNetwork,
 	)
 
+	network := stellarnet.FromConfig(cfg)
+	if err := network.Validate(cfg.Env); err != nil {
+		slog.Error("invalid stellar network configuration", "step", "3", "action", "stellar_network_invalid",
+			"network", network.Name,
+			"error", err,
+		)
+		os.Exit(1)
+	}
+
 	slog.Info("connecting to database", "step", "4", "action", "connecting_to_database")
 	var database *db.DB
 	if cfg.DBURL == "" {
@@ -110,6 +124,14 @@ func main() {
 		} else {
 			slog.Info("migrations skipped", "step", "5", "action", "migrations_skipped", "reason", "AUTO_MIGRATE=false")
 		}
+
+		if err := network.Bind(context.Background(), database.Pool); err != nil {
+			slog.Error("stellar network bind failed", "step", "5", "action", "stellar_network_bind_failed",
+				"network", network.Name,
+				"error", err,
+			)
+			os.Exit(1)
+		}
 	}
 
 	slog.Info("connecting to nats", "step", "6", "action", "connecting_to_nats")
@@ -164,6 +186,18 @@ func main() {
 		)
 	}
 
+	// Expired OAuth states / magic-link tokens are purged on every instance (idempotent deletes).
+	if database != nil && database.Pool != nil {
+		go auth.RunStateCleanup(context.Background(), database.Pool, 15*time.Minute)
+		// KYC rows stored before encryption (or without a key) get their personal data sealed.
+		go kyc.EncryptExisting(context.Background(), database.Pool, cfg.KYCKeyB64())
+		// GitHub token refresh/revocation detection; rows are claimed with SKIP LOCKED.
+		go github.RunTokenHealthCheck(context.Background(), database.Pool, cfg.TokenEncKeyB64, github.OAuthConfig{
+			ClientID:     cfg.GitHubOAuthClientID,
+			ClientSecret: cfg.GitHubOAuthClientSecret,
+		}, 15*time.Minute)
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
 		slog.Info("starting http server", "step", "9", "action", "starting_http_server",
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/migrate"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+	"github.com/jagadeesh/grainlify/backend/internal/sorobanindexer"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
+)
+
+// Soroban event indexer: streams contract events into soroban_events. With PAYOUT_VERIFY_ACCOUNT
+// set it also watches that account for the deposits that verify payout addresses, and it credits
+// memo-tagged payments to the PROGRAM_DEPOSIT_ACCOUNTS to reward program budgets, announcing them
+// on NATS_URL when set.
+//
+//	indexer                     resume from the stored cursor
+//	indexer -replay-from 123456 rewind to ledger 123456 first, re-reading everything after it
+func main() {
+	name := flag.String("name", sorobanindexer.DefaultName, "cursor name; indexers watching different contracts need different names")
+	replayFrom := flag.Uint("replay-from", 0, "rewind the cursor to this ledger before indexing")
+	flag.Parse()
+
+	config.LoadDotenv()
+	cfg := config.Load()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: cfg.LogLevel(),
+	}))
+	slog.SetDefault(logger)
+
+	contracts := cfg.IndexedContractIDs()
+	if cfg.SorobanRPCURL == "" || len(contracts) == 0 {
+		slog.Error("soroban indexer needs SOROBAN_RPC_URL and SOROBAN_INDEXER_CONTRACTS (or ESCROW_CONTRACT_ID)")
+		os.Exit(1)
+	}
+	network := stellarnet.FromConfig(cfg)
+	if err := network.Validate(cfg.Env); err != nil {
+		slog.Error("invalid stellar network configuration", "network", network.Name, "error", err)
+		os.Exit(1)
+	}
+	client, err := soroban.NewClient(soroban.Config{
+		RPCURL:            network.RPCURL,
+		NetworkPassphrase: network.Passphrase,
+		HorizonURL:        network.HorizonURL,
+		Network:           soroban.Network(network.Name),
+	})
+	if err != nil {
+		slog.Error("soroban client init failed", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	d, err := db.Connect(connectCtx, cfg.DBURL)
+	cancel()
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		os.Exit(1)
+	}
+	defer d.Close()
+
+	if cfg.AutoMigrate {
+		if err := migrate.Up(ctx, d.Pool); err != nil {
+			slog.Error("migrate up failed", "error", err)
+			os.Exit(1)
+		}
+	}
+	if err := network.Bind(ctx, d.Pool); err != nil {
+		slog.Error("stellar network bind failed", "network", network.Name, "error", err)
+		os.Exit(1)
+	}
+
+	if *replayFrom > 0 {
+		if err := sorobanindexer.Replay(ctx, d.Pool, *name, uint32(*replayFrom)); err != nil {
+			slog.Error("replay failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("soroban indexer rewound", "name", *name, "from_ledger", *replayFrom)
+	}
+
+	var eventBus bus.Bus
+	if cfg.NATSURL != "" {
+		b, err := natsbus.Connect(cfg.NATSURL)
+		if err != nil {
+			slog.Error("nats connection failed", "error", err)
+			os.Exit(1)
+		}
+		eventBus = b
+		defer eventBus.Close()
+	}
+
+	horizonURL := network.HorizonURL
+	if cfg.PayoutVerifyAccount != "" {
+		deposits := sorobanindexer.NewDepositWatcher(horizonURL, cfg.PayoutVerifyAccount, d.Pool, cfg.SorobanIndexerPollInterval)
+		go func() {
+			if err := deposits.Run(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("payout deposit watcher stopped", "error", err)
+			}
+		}()
+	}
+	for _, account := range cfg.ProgramDepositAccountList() {
+		if !stellar.ValidAccount(account) {
+			slog.Error("invalid PROGRAM_DEPOSIT_ACCOUNTS entry", "account", account)
+			os.Exit(1)
+		}
+		w := sorobanindexer.NewProgramDepositWatcher(horizonURL, account, d.Pool, eventBus, cfg.SorobanIndexerPollInterval)
+		go func() {
+			if err := w.Run(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("program deposit watcher stopped", "account", account, "error", err)
+			}
+		}()
+	}
+
+	ix := sorobanindexer.New(client, d.Pool, sorobanindexer.Options{
+		Name:         *name,
+		ContractIDs:  contracts,
+		StartLedger:  uint32(cfg.SorobanIndexerStartLedger),
+		PollInterval: cfg.SorobanIndexerPollInterval,
+	})
+	if err := ix.Run(ctx); err != nil && ctx.Err() == nil {
+		slog.Error("soroban indexer stopped", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("soroban indexer shut down")
+}
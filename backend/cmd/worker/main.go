@@ -1,14 +1,108 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/ingest"
+	"github.com/jagadeesh/grainlify/backend/internal/migrate"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+	"github.com/jagadeesh/grainlify/backend/internal/worker"
 )
 
-// Worker entrypoint placeholder.
-//
-// This repo currently does not ship a worker binary in this workspace snapshot,
-// but Go tooling expects a valid package in ./cmd/worker.
+// Background worker: runs the sync job queue and its periodic tasks (the API runs the same worker
+// in-process only when NATS_URL is unset). With NATS_URL it also consumes the bus: GitHub webhooks
+// published by the API, sync job wake-ups, and bounty events, which update the bounty's GitHub
+// issue.
 func main() {
-	log.Println("worker is not implemented in this build")
+	config.LoadDotenv()
+	cfg := config.Load()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: cfg.LogLevel(),
+	}))
+	slog.SetDefault(logger)
+
+	if cfg.DBURL == "" {
+		slog.Error("worker needs DB_URL")
+		os.Exit(1)
+	}
+	network := stellarnet.FromConfig(cfg)
+	if err := network.Validate(cfg.Env); err != nil {
+		slog.Error("invalid stellar network configuration", "network", network.Name, "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	d, err := db.Connect(connectCtx, cfg.DBURL)
+	cancel()
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		os.Exit(1)
+	}
+	defer d.Close()
+
+	if cfg.AutoMigrate {
+		if err := migrate.Up(ctx, d.Pool); err != nil {
+			slog.Error("migrate up failed", "error", err)
+			os.Exit(1)
+		}
+	}
+	if err := network.Bind(ctx, d.Pool); err != nil {
+		slog.Error("stellar network bind failed", "network", network.Name, "error", err)
+		os.Exit(1)
+	}
+
+	w := syncjobs.New(cfg, d.Pool)
+
+	if cfg.NATSURL != "" {
+		b, err := natsbus.Connect(cfg.NATSURL)
+		if err != nil {
+			slog.Error("nats connection failed", "error", err)
+			os.Exit(1)
+		}
+		defer b.Close()
+		w.SetBus(b)
+		if err := subscribe(ctx, b.Conn(), d, b, w); err != nil {
+			slog.Error("nats subscribe failed", "error", err)
+			os.Exit(1)
+		}
+	} else {
+		slog.Info("nats skipped", "reason", "NATS_URL not set")
+	}
+
+	slog.Info("worker started", "network", network.Name)
+	if err := w.Run(ctx); err != nil && ctx.Err() == nil {
+		slog.Error("worker stopped", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("worker shut down")
 }
 
+// subscribe starts the bus consumers.
+func subscribe(ctx context.Context, nc *nats.Conn, d *db.DB, b bus.Bus, w *syncjobs.Worker) error {
+	webhooks := &worker.GitHubWebhookConsumer{Ingest: &ingest.GitHubWebhookIngestor{Pool: d.Pool, Bus: b}}
+	if err := webhooks.Subscribe(ctx, nc, ""); err != nil {
+		return err
+	}
+	jobs := &worker.SyncJobConsumer{Worker: w}
+	if err := jobs.Subscribe(ctx, nc); err != nil {
+		return err
+	}
+	bountyIssues := &worker.BountyIssueConsumer{Worker: w}
+	return bountyIssues.Subscribe(ctx, nc, "")
+}
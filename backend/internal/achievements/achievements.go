@@ -0,0 +1,341 @@
+// Package achievements awards contributor badges. Badges are evaluated from the contributions table
+// whenever new contributions arrive (webhook deliveries and sync jobs) and stored in user_badges,
+// keyed by GitHub login. Only contributions to verified and archived projects count, as on profiles.
+package achievements
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Badge keys stored in user_badges.badge.
+const (
+	BadgeFirstContribution = "first_contribution"
+	BadgeFirstMergedPR     = "first_merged_pr"
+	BadgeTenMergedPRs      = "ten_merged_prs"
+	BadgeEcosystemRegular  = "ecosystem_regular"
+	BadgeStreak7           = "streak_7"
+	BadgeStreak30          = "streak_30"
+)
+
+// EcosystemRegularThreshold is how many issues and PRs in one ecosystem earn BadgeEcosystemRegular.
+const EcosystemRegularThreshold = 10
+
+// Definition describes a badge for API responses.
+type Definition struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// PerEcosystem badges are awarded once for every ecosystem that qualifies.
+	PerEcosystem bool `json:"per_ecosystem"`
+}
+
+// Catalog lists every badge, in display order.
+var Catalog = []Definition{
+	{Key: BadgeFirstContribution, Name: "First Contribution", Description: "Opened a first issue or pull request"},
+	{Key: BadgeFirstMergedPR, Name: "First Merged PR", Description: "Got a first pull request merged"},
+	{Key: BadgeTenMergedPRs, Name: "Ten Merged PRs", Description: "Got 10 pull requests merged"},
+	{Key: BadgeEcosystemRegular, Name: "Ecosystem Regular", Description: "Made 10 contributions in one ecosystem", PerEcosystem: true},
+	{Key: BadgeStreak7, Name: "Week Streak", Description: "Contributed 7 days in a row"},
+	{Key: BadgeStreak30, Name: "Month Streak", Description: "Contributed 30 days in a row"},
+}
+
+// Lookup returns the definition of key.
+func Lookup(key string) (Definition, bool) {
+	for _, d := range Catalog {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Earned is a badge held by a contributor.
+type Earned struct {
+	Definition
+	EcosystemName *string   `json:"ecosystem_name,omitempty"`
+	EcosystemSlug *string   `json:"ecosystem_slug,omitempty"`
+	AwardedAt     time.Time `json:"awarded_at"`
+}
+
+// award is a badge to insert; ecosystemID is nil for global badges.
+type award struct {
+	badge       string
+	ecosystemID *uuid.UUID
+}
+
+// stats are the contribution counts the badges are decided from.
+type stats struct {
+	contributions int
+	mergedPRs     int
+	// ecosystems holds the ecosystems (ancestors included) with at least EcosystemRegularThreshold
+	// issues and PRs.
+	ecosystems    []uuid.UUID
+	longestStreak int
+}
+
+// awards returns the badges s qualifies for.
+func (s stats) awards() []award {
+	var out []award
+	if s.contributions > 0 {
+		out = append(out, award{badge: BadgeFirstContribution})
+	}
+	if s.mergedPRs >= 1 {
+		out = append(out, award{badge: BadgeFirstMergedPR})
+	}
+	if s.mergedPRs >= 10 {
+		out = append(out, award{badge: BadgeTenMergedPRs})
+	}
+	for i := range s.ecosystems {
+		out = append(out, award{badge: BadgeEcosystemRegular, ecosystemID: &s.ecosystems[i]})
+	}
+	if s.longestStreak >= 7 {
+		out = append(out, award{badge: BadgeStreak7})
+	}
+	if s.longestStreak >= 30 {
+		out = append(out, award{badge: BadgeStreak30})
+	}
+	return out
+}
+
+// Evaluate awards login every badge it qualifies for and does not hold yet. Badges are never taken
+// back. Returns how many badges were newly awarded.
+func Evaluate(ctx context.Context, pool *pgxpool.Pool, login string) (int, error) {
+	login = strings.TrimSpace(login)
+	if pool == nil || login == "" {
+		return 0, nil
+	}
+	s, err := loadStats(ctx, pool, login)
+	if err != nil {
+		return 0, err
+	}
+	awarded := 0
+	for _, a := range s.awards() {
+		ct, err := pool.Exec(ctx, `
+INSERT INTO user_badges (github_login, badge, ecosystem_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`, login, a.badge, a.ecosystemID)
+		if err != nil {
+			return awarded, err
+		}
+		awarded += int(ct.RowsAffected())
+	}
+	if awarded > 0 {
+		slog.Info("badges awarded", "login", login, "count", awarded)
+	}
+	return awarded, nil
+}
+
+// EvaluateProject evaluates every author of the project's issues, PRs and commits seen since since,
+// i.e. those a sync that started at since upserted.
+func EvaluateProject(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, since time.Time) error {
+	rows, err := pool.Query(ctx, `
+SELECT DISTINCT author_login FROM (
+  SELECT author_login FROM github_issues WHERE project_id = $1 AND last_seen_at >= $2
+  UNION
+  SELECT author_login FROM github_pull_requests WHERE project_id = $1 AND last_seen_at >= $2
+  UNION
+  SELECT author_login FROM github_commits WHERE project_id = $1 AND last_seen_at >= $2
+) authors
+WHERE author_login IS NOT NULL AND author_login <> ''
+`, projectID, since)
+	if err != nil {
+		return err
+	}
+	var logins []string
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			rows.Close()
+			return err
+		}
+		logins = append(logins, login)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, login := range logins {
+		if _, err := Evaluate(ctx, pool, login); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("badge evaluation failed", "login", login, "project_id", projectID, "error", err)
+		}
+	}
+	return nil
+}
+
+func loadStats(ctx context.Context, pool *pgxpool.Pool, login string) (stats, error) {
+	var s stats
+	err := pool.QueryRow(ctx, `
+WITH listed AS (
+  SELECT id FROM projects WHERE status IN ('verified', 'archived')
+)
+SELECT
+  (SELECT COUNT(*) FROM contributions c WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND c.project_id IN (SELECT id FROM listed)),
+  (SELECT COUNT(*) FROM github_pull_requests pr WHERE pr.author_login = $1 AND pr.merged IS TRUE AND pr.project_id IN (SELECT id FROM listed))
+`, login).Scan(&s.contributions, &s.mergedPRs)
+	if err != nil {
+		return s, err
+	}
+
+	// A child ecosystem also counts toward its ancestors, as on profiles.
+	rows, err := pool.Query(ctx, `
+SELECT ec.ancestor_id
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+JOIN ecosystem_closure ec ON ec.descendant_id = p.ecosystem_id
+JOIN ecosystems e ON e.id = ec.ancestor_id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND e.status = 'active'
+GROUP BY ec.ancestor_id
+HAVING COUNT(*) >= $2
+`, login, EcosystemRegularThreshold)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return s, err
+		}
+		s.ecosystems = append(s.ecosystems, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+
+	// Days with an issue, PR or default-branch commit, as the contribution calendar counts them.
+	rows, err = pool.Query(ctx, `
+SELECT DISTINCT DATE(c.occurred_at AT TIME ZONE 'UTC')
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+WHERE c.author_login = $1 AND p.status IN ('verified', 'archived')
+`, login)
+	if err != nil {
+		return s, err
+	}
+	var days []time.Time
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			rows.Close()
+			return s, err
+		}
+		days = append(days, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+	s.longestStreak = LongestStreak(days)
+	return s, nil
+}
+
+// LongestStreak returns the most consecutive calendar days (UTC) among days. Order and duplicates
+// don't matter.
+func LongestStreak(days []time.Time) int {
+	if len(days) == 0 {
+		return 0
+	}
+	dates := utcDates(days)
+
+	longest, run := 1, 1
+	for i := 1; i < len(dates); i++ {
+		switch {
+		case dates[i].Equal(dates[i-1]):
+			continue
+		case dates[i].Equal(dates[i-1].AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// CurrentStreak returns how many consecutive days (UTC) up to now's day have a contribution. A
+// streak still counts while today has none yet, as long as yesterday does.
+func CurrentStreak(days []time.Time, now time.Time) int {
+	has := map[time.Time]bool{}
+	for _, d := range utcDates(days) {
+		has[d] = true
+	}
+	day := utcDate(now)
+	if !has[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	n := 0
+	for has[day] {
+		n++
+		day = day.AddDate(0, 0, -1)
+	}
+	return n
+}
+
+func utcDate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// utcDates truncates days to their UTC dates, sorted ascending.
+func utcDates(days []time.Time) []time.Time {
+	dates := make([]time.Time, len(days))
+	for i, d := range days {
+		dates[i] = utcDate(d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// ForLogin returns the badges login holds, in catalog order and oldest first within a badge.
+func ForLogin(ctx context.Context, pool *pgxpool.Pool, login string) ([]Earned, error) {
+	rows, err := pool.Query(ctx, `
+SELECT b.badge, e.name, e.slug, b.awarded_at
+FROM user_badges b
+LEFT JOIN ecosystems e ON e.id = b.ecosystem_id
+WHERE LOWER(b.github_login) = LOWER($1)
+ORDER BY b.awarded_at ASC, e.name ASC
+`, login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Earned{}
+	for rows.Next() {
+		var key string
+		var e Earned
+		if err := rows.Scan(&key, &e.EcosystemName, &e.EcosystemSlug, &e.AwardedAt); err != nil {
+			return nil, err
+		}
+		def, ok := Lookup(key)
+		if !ok {
+			// Retired badge.
+			continue
+		}
+		e.Definition = def
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	order := map[string]int{}
+	for i, d := range Catalog {
+		order[d.Key] = i
+	}
+	sort.SliceStable(out, func(i, j int) bool { return order[out[i].Key] < order[out[j].Key] })
+	return out, nil
+}
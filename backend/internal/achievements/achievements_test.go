@@ -0,0 +1,99 @@
+package achievements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLongestStreak(t *testing.T) {
+	cases := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{"empty", nil, 0},
+		{"single", []time.Time{day("2026-03-01T10:00:00Z")}, 1},
+		{"same day twice", []time.Time{day("2026-03-01T01:00:00Z"), day("2026-03-01T23:00:00Z")}, 1},
+		{"unordered run", []time.Time{
+			day("2026-03-03T09:00:00Z"),
+			day("2026-03-01T09:00:00Z"),
+			day("2026-03-02T09:00:00Z"),
+		}, 3},
+		{"gap resets", []time.Time{
+			day("2026-03-01T09:00:00Z"),
+			day("2026-03-02T09:00:00Z"),
+			day("2026-03-04T09:00:00Z"),
+			day("2026-03-05T09:00:00Z"),
+			day("2026-03-06T09:00:00Z"),
+		}, 3},
+		{"month boundary", []time.Time{day("2026-02-28T12:00:00Z"), day("2026-03-01T12:00:00Z")}, 2},
+		{"days are UTC", []time.Time{
+			day("2026-03-01T23:30:00-02:00"), // 2026-03-02 UTC
+			day("2026-03-03T00:30:00Z"),
+		}, 2},
+	}
+	for _, tc := range cases {
+		if got := LongestStreak(tc.days); got != tc.want {
+			t.Errorf("%s: LongestStreak = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	now := day("2026-03-10T15:00:00Z")
+	cases := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{"empty", nil, 0},
+		{"through today", []time.Time{day("2026-03-08T10:00:00Z"), day("2026-03-09T10:00:00Z"), day("2026-03-10T08:00:00Z")}, 3},
+		{"through yesterday", []time.Time{day("2026-03-08T10:00:00Z"), day("2026-03-09T10:00:00Z")}, 2},
+		{"broken", []time.Time{day("2026-03-07T10:00:00Z"), day("2026-03-08T10:00:00Z")}, 0},
+		{"older run ignored", []time.Time{
+			day("2026-03-01T10:00:00Z"),
+			day("2026-03-02T10:00:00Z"),
+			day("2026-03-03T10:00:00Z"),
+			day("2026-03-10T10:00:00Z"),
+		}, 1},
+	}
+	for _, tc := range cases {
+		if got := CurrentStreak(tc.days, now); got != tc.want {
+			t.Errorf("%s: CurrentStreak = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestStatsAwards(t *testing.T) {
+	eco := uuid.New()
+	s := stats{contributions: 12, mergedPRs: 10, ecosystems: []uuid.UUID{eco}, longestStreak: 7}
+	got := map[string]bool{}
+	for _, a := range s.awards() {
+		got[a.badge] = true
+		if a.badge == BadgeEcosystemRegular && (a.ecosystemID == nil || *a.ecosystemID != eco) {
+			t.Errorf("ecosystem badge for %v, want %v", a.ecosystemID, eco)
+		}
+	}
+	for _, want := range []string{BadgeFirstContribution, BadgeFirstMergedPR, BadgeTenMergedPRs, BadgeEcosystemRegular, BadgeStreak7} {
+		if !got[want] {
+			t.Errorf("missing badge %s", want)
+		}
+	}
+	if got[BadgeStreak30] {
+		t.Errorf("streak_30 awarded for a 7-day streak")
+	}
+
+	if n := len((stats{}).awards()); n != 0 {
+		t.Errorf("no contributions awarded %d badges", n)
+	}
+}
@@ -10,12 +10,15 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/handlers"
+	"github.com/jagadeesh/grainlify/backend/internal/mail"
+	"github.com/jagadeesh/grainlify/backend/internal/ratelimit"
 )
 
 type Deps struct {
@@ -143,47 +146,158 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	app.Get("/health", handlers.Health())
 	app.Get("/ready", handlers.Ready(deps.DB))
 
+	// Auth middleware checks token jti against auth_sessions so revoked sessions are rejected.
+	var sessionPool *pgxpool.Pool
+	if deps.DB != nil {
+		sessionPool = deps.DB.Pool
+	}
+	requireAuth := auth.RequireAuth(cfg.JWTSecret, sessionPool)
+	auth.UsePermissionStore(sessionPool)
+	auth.ConfigureTokens(cfg.JWTIssuer, cfg.JWTAudience)
+
+	// Rate limits for unauthenticated auth endpoints (nonce enumeration / signature brute force).
+	var rlStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if strings.EqualFold(cfg.AuthRateLimitStore, "postgres") && sessionPool != nil {
+		rlStore = ratelimit.NewPostgresStore(sessionPool)
+	}
+	nonceLimit := ratelimit.New(rlStore,
+		ratelimit.Rule{Name: "nonce_ip", Limit: 30, Window: time.Minute, Key: ratelimit.ByIP},
+		ratelimit.Rule{Name: "nonce_addr", Limit: 10, Window: time.Minute, Key: ratelimit.ByBodyField("address")},
+	)
+	verifyLimit := ratelimit.New(rlStore,
+		ratelimit.Rule{Name: "verify_ip", Limit: 20, Window: time.Minute, Key: ratelimit.ByIP},
+		ratelimit.Rule{Name: "verify_addr", Limit: 5, Window: time.Minute, Key: ratelimit.ByBodyField("address")},
+	)
+	oauthStartLimit := ratelimit.New(rlStore,
+		ratelimit.Rule{Name: "oauth_start_ip", Limit: 30, Window: time.Minute, Key: ratelimit.ByIP},
+	)
+
 	authHandler := handlers.NewAuthHandler(cfg, deps.DB)
 	authGroup := app.Group("/auth")
-	app.Get("/me", auth.RequireAuth(cfg.JWTSecret), authHandler.Me())
-	app.Post("/me/github/resync", auth.RequireAuth(cfg.JWTSecret), authHandler.ResyncGitHubProfile())
+	// Wallet login (signed structured message)
+	authGroup.Post("/nonce", nonceLimit, authHandler.Nonce())
+	authGroup.Post("/verify", verifyLimit, authHandler.Verify())
+	app.Get("/me", requireAuth, authHandler.Me())
+	app.Post("/me/github/resync", requireAuth, authHandler.ResyncGitHubProfile())
+
+	// Account data export / deletion (GDPR)
+	account := handlers.NewAccountHandler(cfg, deps.DB)
+	app.Get("/me/export", requireAuth, account.Export())
+	app.Delete("/me", requireAuth, account.Delete())
+	app.Post("/me/merge", requireAuth, account.Merge())
+
+	// Payout addresses (Stellar accounts bounty payouts go to; separate from login wallets)
+	payoutAddresses := handlers.NewPayoutAddressesHandler(cfg, deps.DB)
+	app.Get("/me/payout-addresses", requireAuth, payoutAddresses.List())
+	app.Post("/me/payout-addresses", requireAuth, payoutAddresses.Add())
+	app.Post("/me/payout-addresses/:id/challenge", requireAuth, payoutAddresses.RenewChallenge())
+	app.Post("/me/payout-addresses/:id/verify", requireAuth, verifyLimit, payoutAddresses.Verify())
+	app.Post("/me/payout-addresses/:id/primary", requireAuth, payoutAddresses.SetPrimary())
+	app.Delete("/me/payout-addresses/:id", requireAuth, payoutAddresses.Remove())
+
+	// Session management (list / revoke issued tokens)
+	sessions := handlers.NewSessionsHandler(cfg, deps.DB)
+	authGroup.Get("/sessions", requireAuth, sessions.List())
+	authGroup.Delete("/sessions/:id", requireAuth, sessions.Revoke())
+
+	// TOTP second factor (required for admin routes once enrolled, or always with ADMIN_REQUIRE_2FA)
+	twoFactor := handlers.NewTwoFactorHandler(cfg, deps.DB)
+	authGroup.Post("/2fa/enroll", requireAuth, twoFactor.Enroll())
+	// Online guessing of the 6-digit code, even with a stolen session.
+	totpLimit := ratelimit.New(rlStore,
+		ratelimit.Rule{Name: "totp_user", Limit: 5, Window: 15 * time.Minute, Key: ratelimit.ByLocal(auth.LocalUserID)},
+		ratelimit.Rule{Name: "totp_ip", Limit: 20, Window: 15 * time.Minute, Key: ratelimit.ByIP},
+	)
+	authGroup.Post("/2fa/verify", requireAuth, totpLimit, twoFactor.Verify())
+	authGroup.Post("/2fa/disable", requireAuth, totpLimit, twoFactor.Disable())
+
+	// Additional wallets linked to an existing account (nonce-based proof of ownership)
+	wallets := handlers.NewWalletsHandler(cfg, deps.DB)
+	authGroup.Post("/wallets/nonce", requireAuth, nonceLimit, wallets.LinkNonce())
+	authGroup.Post("/wallets/link", requireAuth, verifyLimit, wallets.Link())
+	authGroup.Get("/wallets", requireAuth, wallets.List())
+	authGroup.Delete("/wallets/:address", requireAuth, wallets.Unlink())
 
 	// User profile endpoints
 	userProfile := handlers.NewUserProfileHandler(cfg, deps.DB)
-	app.Get("/profile", auth.RequireAuth(cfg.JWTSecret), userProfile.Profile())
+	app.Get("/profile", requireAuth, userProfile.Profile())
 	app.Get("/profile/public", userProfile.PublicProfile()) // Public profile endpoint (no auth required)
-	app.Get("/profile/calendar", auth.RequireAuth(cfg.JWTSecret), userProfile.ContributionCalendar())
-	app.Get("/profile/activity", auth.RequireAuth(cfg.JWTSecret), userProfile.ContributionActivity())
-	app.Get("/profile/projects", auth.RequireAuth(cfg.JWTSecret), userProfile.ProjectsContributed())
-	app.Get("/profile/projects-led", auth.RequireAuth(cfg.JWTSecret), userProfile.ProjectsLed())
-	app.Put("/profile/update", auth.RequireAuth(cfg.JWTSecret), userProfile.UpdateProfile())
-	app.Put("/profile/avatar", auth.RequireAuth(cfg.JWTSecret), userProfile.UpdateAvatar())
+	contributors := handlers.NewContributorsPublicHandler(deps.DB)
+	app.Get("/contributors/:login", contributors.Get())
+	app.Get("/profile/calendar", requireAuth, userProfile.ContributionCalendar())
+	app.Get("/profile/activity", requireAuth, userProfile.ContributionActivity())
+	app.Get("/profile/badges", requireAuth, userProfile.Badges())
+	app.Get("/profile/digest", requireAuth, userProfile.Digest())
+
+	// Signed contribution attestations and the public keys to verify them
+	attestations := handlers.NewAttestationsHandler(cfg, deps.DB)
+	app.Get("/profile/attestation", requireAuth, attestations.Issue())
+	app.Get("/attestations/keys", attestations.Keys())
+	app.Post("/attestations/verify", attestations.Verify())
+	app.Get("/profile/projects", requireAuth, userProfile.ProjectsContributed())
+	app.Get("/profile/projects-led", requireAuth, userProfile.ProjectsLed())
+	app.Get("/profile/settings", requireAuth, userProfile.ProfileSettings())
+	app.Patch("/profile/settings", requireAuth, userProfile.UpdateProfileSettings())
+	app.Put("/profile/update", requireAuth, userProfile.UpdateProfile())
+	app.Put("/profile/avatar", requireAuth, userProfile.UpdateAvatar())
 
 	ghOAuth := handlers.NewGitHubOAuthHandler(cfg, deps.DB)
 	// GitHub-only login/signup:
-	authGroup.Get("/github/login/start", ghOAuth.LoginStart())
+	authGroup.Get("/github/login/start", oauthStartLimit, ghOAuth.LoginStart())
 	// Alias to unified callback (for backwards compatibility with older callback URLs).
 	authGroup.Get("/github/login/callback", ghOAuth.CallbackUnified())
 
 	// Legacy "link GitHub to existing account" endpoints (still available).
-	authGroup.Post("/github/start", auth.RequireAuth(cfg.JWTSecret), ghOAuth.Start())
+	authGroup.Post("/github/start", requireAuth, oauthStartLimit, ghOAuth.Start())
 	authGroup.Get("/github/callback", ghOAuth.CallbackUnified())
-	authGroup.Get("/github/status", auth.RequireAuth(cfg.JWTSecret), ghOAuth.Status())
+	authGroup.Get("/github/status", requireAuth, ghOAuth.Status())
+
+	// Bitbucket Cloud account linking (repository host for Bitbucket projects)
+	bitbucketHandler := handlers.NewBitbucketHandler(cfg, deps.DB)
+	authGroup.Post("/bitbucket/start", requireAuth, oauthStartLimit, bitbucketHandler.Start())
+	authGroup.Get("/bitbucket/callback", bitbucketHandler.Callback())
+	authGroup.Get("/bitbucket/status", requireAuth, bitbucketHandler.Status())
+
+	// Additional OAuth login providers (Google, Discord)
+	oauthProviders := handlers.NewOAuthProvidersHandler(cfg, deps.DB)
+	authGroup.Get("/oauth/identities", requireAuth, oauthProviders.Identities())
+	authGroup.Get("/oauth/:provider/login/start", oauthStartLimit, oauthProviders.LoginStart())
+	authGroup.Post("/oauth/:provider/link/start", requireAuth, oauthStartLimit, oauthProviders.LinkStart())
+	authGroup.Get("/oauth/:provider/callback", oauthProviders.Callback())
+
+	// Passwordless email (magic-link) login
+	mailer := mail.New(mail.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	})
+	emailLogin := handlers.NewEmailLoginHandler(cfg, deps.DB, mailer)
+	emailStartLimit := ratelimit.New(rlStore,
+		ratelimit.Rule{Name: "email_start_ip", Limit: 10, Window: time.Minute, Key: ratelimit.ByIP},
+		ratelimit.Rule{Name: "email_start_addr", Limit: 3, Window: 10 * time.Minute, Key: ratelimit.ByBodyField("email")},
+	)
+	authGroup.Post("/email/start", emailStartLimit, emailLogin.Start())
+	authGroup.Get("/email/callback", emailLogin.Callback())
 
 	// GitHub App installation endpoints
 	ghApp := handlers.NewGitHubAppHandler(cfg, deps.DB)
-	authGroup.Post("/github/app/install/start", auth.RequireAuth(cfg.JWTSecret), ghApp.StartInstallation())
+	authGroup.Post("/github/app/install/start", requireAuth, ghApp.StartInstallation())
 	app.Get("/auth/github/app/install/callback", ghApp.HandleInstallationCallback())
 
 	// KYC verification endpoints
 	kyc := handlers.NewKYCHandler(cfg, deps.DB)
-	authGroup.Post("/kyc/start", auth.RequireAuth(cfg.JWTSecret), kyc.Start())
-	authGroup.Get("/kyc/status", auth.RequireAuth(cfg.JWTSecret), kyc.Status())
+	authGroup.Post("/kyc/start", requireAuth, kyc.Start())
+	authGroup.Get("/kyc/status", requireAuth, kyc.Status())
+	authGroup.Get("/kyc/history", requireAuth, kyc.History())
 
 	// Public ecosystems list and detail (includes computed project_count and user_count).
+	// /ecosystems/:id hands non-UUID params on to the slug detail page.
 	ecosystems := handlers.NewEcosystemsPublicHandler(deps.DB)
 	app.Get("/ecosystems", ecosystems.ListActive())
 	app.Get("/ecosystems/:id", ecosystems.GetByID())
+	app.Get("/ecosystems/:slug", ecosystems.Detail())
 
 	// Open Source Week (public)
 	osw := handlers.NewOpenSourceWeekHandler(deps.DB)
@@ -205,53 +319,169 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	app.Get("/projects/filters", projectsPublic.FilterOptions())
 
 	projects := handlers.NewProjectsHandler(cfg, deps.DB)
-	app.Post("/projects", auth.RequireAuth(cfg.JWTSecret), projects.Create())
+	app.Post("/projects", requireAuth, auth.RequirePermission(auth.PermProjectsCreate), projects.Create())
 	// IMPORTANT: /projects/mine and /projects/pending-setup must come BEFORE /projects/:id to avoid route conflict
-	app.Get("/projects/mine", auth.RequireAuth(cfg.JWTSecret), projects.Mine())
-	app.Get("/projects/pending-setup", auth.RequireAuth(cfg.JWTSecret), projects.PendingSetup())
+	app.Get("/projects/mine", requireAuth, projects.Mine())
+	app.Get("/projects/pending-setup", requireAuth, projects.PendingSetup())
+	app.Post("/projects/import/org", requireAuth, auth.RequirePermission(auth.PermProjectsCreate), projects.ImportOrg())
 
 	// These routes with :id must come AFTER specific routes like /projects/mine
 	app.Get("/projects/:id", projectsPublic.Get())
-	app.Put("/projects/:id/metadata", auth.RequireAuth(cfg.JWTSecret), projects.UpdateMetadata())
+	app.Put("/projects/:id/metadata", requireAuth, projects.UpdateMetadata())
+	app.Patch("/projects/:id", requireAuth, projects.Patch())
+	app.Delete("/projects/:id", requireAuth, projects.Delete())
+	app.Post("/projects/:id/archive", requireAuth, projects.Archive())
+	app.Post("/projects/:id/unarchive", requireAuth, projects.Unarchive())
+	app.Get("/projects/:id/members", requireAuth, projects.Members())
+	app.Get("/projects/:id/contributors", requireAuth, projects.Contributors())
+	app.Post("/projects/:id/members", requireAuth, projects.InviteMember())
+	app.Post("/projects/:id/members/accept", requireAuth, projects.AcceptInvitation())
+	app.Delete("/projects/:id/members/:user_id", requireAuth, projects.RemoveMember())
+	app.Post("/projects/:id/transfer", requireAuth, projects.TransferOwnership())
+	app.Get("/me/project-invitations", requireAuth, projects.MyInvitations())
 	app.Get("/projects/:id/issues/public", projectsPublic.IssuesPublic())
 	app.Get("/projects/:id/prs/public", projectsPublic.PRsPublic())
-	app.Post("/projects/:id/verify", auth.RequireAuth(cfg.JWTSecret), projects.Verify())
-
-	sync := handlers.NewSyncHandler(deps.DB)
-	app.Post("/projects/:id/sync", auth.RequireAuth(cfg.JWTSecret), sync.EnqueueFullSync())
-	app.Get("/projects/:id/sync/jobs", auth.RequireAuth(cfg.JWTSecret), sync.JobsForProject())
-
+	app.Post("/projects/:id/verify", requireAuth, auth.RequirePermission(auth.PermProjectsVerify), projects.Verify())
+	app.Post("/projects/:id/webhook/repair", requireAuth, auth.RequirePermission(auth.PermProjectsVerify), projects.RepairWebhook())
+
+	orgs := handlers.NewOrganizationsHandler(cfg, deps.DB)
+	app.Post("/organizations", requireAuth, auth.RequirePermission(auth.PermProjectsVerify), orgs.Link())
+	app.Get("/organizations/mine", requireAuth, orgs.Mine())
+	app.Delete("/organizations/:id", requireAuth, orgs.Unlink())
+
+	sync := handlers.NewSyncHandler(deps.DB, deps.Bus)
+	app.Post("/projects/:id/sync", requireAuth, auth.RequirePermission(auth.PermProjectsSync), sync.EnqueueFullSync())
+	app.Get("/projects/:id/sync/jobs", auth.RequireScope(cfg.JWTSecret, sessionPool, auth.ScopeSyncRead), sync.JobsForProject())
+	app.Get("/projects/:id/sync/schedule", requireAuth, sync.GetSchedule())
+	app.Put("/projects/:id/sync/schedule", requireAuth, auth.RequirePermission(auth.PermProjectsSync), sync.PutSchedule())
+	app.Delete("/projects/:id/sync/schedule", requireAuth, auth.RequirePermission(auth.PermProjectsSync), sync.DeleteSchedule())
+
+	// Read-only project data also accepts scoped tokens (dashboards/widgets) bound to the project.
+	projectRead := auth.RequireScope(cfg.JWTSecret, sessionPool, auth.ScopeProjectsRead)
 	data := handlers.NewProjectDataHandler(deps.DB)
-	app.Get("/projects/:id/issues", auth.RequireAuth(cfg.JWTSecret), data.Issues())
-	app.Get("/projects/:id/prs", auth.RequireAuth(cfg.JWTSecret), data.PRs())
-	app.Get("/projects/:id/events", auth.RequireAuth(cfg.JWTSecret), data.Events())
+	app.Get("/projects/:id/issues", projectRead, data.Issues())
+	app.Get("/projects/:id/prs", projectRead, data.PRs())
+	app.Get("/projects/:id/events", projectRead, data.Events())
+
+	scopedTokens := handlers.NewScopedTokensHandler(cfg, deps.DB)
+	app.Post("/projects/:id/tokens", requireAuth, scopedTokens.Create())
+	app.Get("/projects/:id/tokens", requireAuth, scopedTokens.List())
+	app.Delete("/projects/:id/tokens/:token_id", requireAuth, scopedTokens.Revoke())
 
 	issueApps := handlers.NewIssueApplicationsHandler(cfg, deps.DB)
-	app.Post("/projects/:id/issues/:number/apply", auth.RequireAuth(cfg.JWTSecret), issueApps.Apply())
-	app.Post("/projects/:id/issues/:number/bot-comment", auth.RequireAuth(cfg.JWTSecret), issueApps.PostBotComment())
-	app.Post("/projects/:id/issues/:number/withdraw", auth.RequireAuth(cfg.JWTSecret), issueApps.Withdraw())
-	app.Post("/projects/:id/issues/:number/assign", auth.RequireAuth(cfg.JWTSecret), issueApps.Assign())
-	app.Post("/projects/:id/issues/:number/unassign", auth.RequireAuth(cfg.JWTSecret), issueApps.Unassign())
-	app.Post("/projects/:id/issues/:number/reject", auth.RequireAuth(cfg.JWTSecret), issueApps.Reject())
+	app.Post("/projects/:id/issues/:number/apply", requireAuth, issueApps.Apply())
+	app.Post("/projects/:id/issues/:number/bot-comment", requireAuth, issueApps.PostBotComment())
+	app.Post("/projects/:id/issues/:number/withdraw", requireAuth, issueApps.Withdraw())
+	app.Post("/projects/:id/issues/:number/assign", requireAuth, issueApps.Assign())
+	app.Post("/projects/:id/issues/:number/unassign", requireAuth, issueApps.Unassign())
+	app.Post("/projects/:id/issues/:number/reject", requireAuth, issueApps.Reject())
+
+	// Bounties: maintainers fund synced issues; the open list is public.
+	bountiesH := handlers.NewBountiesHandler(cfg, deps.DB, deps.Bus)
+	app.Post("/projects/:id/bounties", requireAuth, bountiesH.Create())
+	app.Get("/bounties", bountiesH.List())
+	app.Get("/bounties/:id", bountiesH.Get())
+	// Routes that move bounty money need a verified KYC.
+	requireKYC := auth.RequireKYC(sessionPool)
+	app.Post("/bounties/:id/claim", requireAuth, requireKYC, bountiesH.Claim())
+	app.Post("/bounties/:id/transition", requireAuth, bountiesH.Transition())
+	app.Post("/bounties/:id/payout", requireAuth, requireKYC, bountiesH.CreatePayout())
+	app.Get("/bounties/:id/payouts", requireAuth, bountiesH.Payouts())
+	app.Post("/bounties/:id/escrow", requireAuth, bountiesH.InitiateEscrow())
+	app.Get("/bounties/:id/escrow", requireAuth, bountiesH.Escrow())
+	// An admin's token gives the admin half of an escrow approval, so it needs the second factor
+	// the /admin routes ask for.
+	requireAdminMFA := auth.RequireAdminMFA(sessionPool, cfg.AdminRequire2FA)
+	app.Post("/bounties/:id/escrow/release", requireAuth, requireAdminMFA, requireKYC, bountiesH.ReleaseEscrow())
+	app.Post("/bounties/:id/escrow/refund", requireAuth, requireAdminMFA, bountiesH.RefundEscrow())
+
+	// Supported assets with display metadata and USD prices (public; edits are admin-only below).
+	assetsH := handlers.NewAssetsHandler(deps.DB)
+	app.Get("/assets", assetsH.List())
+
+	// Reward programs
+	programsH := handlers.NewProgramsHandler(cfg, deps.DB)
+	app.Get("/programs", programsH.List())
+	app.Get("/programs/:id", programsH.Get())
 
 	admin := handlers.NewAdminHandler(cfg, deps.DB)
-	adminGroup := app.Group("/admin", auth.RequireAuth(cfg.JWTSecret))
+	adminGroup := app.Group("/admin", requireAuth, requireAdminMFA)
 	adminGroup.Post("/bootstrap", admin.BootstrapAdmin())
-	adminGroup.Get("/users", auth.RequireRole("admin"), admin.ListUsers())
-	adminGroup.Put("/users/:id/role", auth.RequireRole("admin"), admin.SetUserRole())
+	adminGroup.Get("/users", auth.RequirePermission(auth.PermUsersRead), admin.ListUsers())
+	adminGroup.Put("/users/:id/role", auth.RequirePermission(auth.PermUsersManageRoles), admin.SetUserRole())
+	adminGroup.Post("/users/:id/logout", auth.RequirePermission(auth.PermUsersForceLogout), admin.ForceLogout())
+	adminGroup.Post("/impersonate/:user_id", auth.RequirePermission(auth.PermUsersImpersonate), admin.Impersonate())
+	adminGroup.Post("/projects/:id/webhook/rotate-secret", auth.RequirePermission(auth.PermWebhooksRotateSecret), admin.RotateWebhookSecret())
+
+	projectReview := handlers.NewProjectReviewHandler(cfg, deps.DB, mailer)
+	adminGroup.Get("/projects", auth.RequirePermission(auth.PermProjectsReview), projectReview.List())
+	adminGroup.Post("/projects/:id/approve", auth.RequirePermission(auth.PermProjectsReview), projectReview.Approve())
+	adminGroup.Post("/projects/:id/reject", auth.RequirePermission(auth.PermProjectsReview), projectReview.Reject())
+
+	// Role/permission matrix
+	rolesAdmin := handlers.NewRolesAdminHandler(deps.DB)
+	adminGroup.Get("/permissions", auth.RequirePermission(auth.PermRolesManage), rolesAdmin.ListPermissions())
+	adminGroup.Get("/roles", auth.RequirePermission(auth.PermRolesManage), rolesAdmin.ListRoles())
+	adminGroup.Post("/roles", auth.RequirePermission(auth.PermRolesManage), rolesAdmin.CreateRole())
+	adminGroup.Put("/roles/:role/permissions", auth.RequirePermission(auth.PermRolesManage), rolesAdmin.SetRolePermissions())
+	adminGroup.Delete("/roles/:role", auth.RequirePermission(auth.PermRolesManage), rolesAdmin.DeleteRole())
+
+	// Dead-letter sync jobs
+	jobsAdmin := handlers.NewJobsAdminHandler(deps.DB)
+	adminGroup.Get("/jobs/dead", auth.RequirePermission(auth.PermJobsManage), jobsAdmin.ListDead())
+	adminGroup.Get("/jobs/stats", auth.RequirePermission(auth.PermJobsManage), jobsAdmin.Stats())
+	adminGroup.Get("/workers", auth.RequirePermission(auth.PermJobsManage), jobsAdmin.ListWorkers())
+	adminGroup.Post("/jobs/:id/requeue", auth.RequirePermission(auth.PermJobsManage), jobsAdmin.Requeue())
+
+	// KYC screening review
+	kycAdmin := handlers.NewKYCAdminHandler(cfg, deps.DB)
+	adminGroup.Get("/kyc/blocked", auth.RequirePermission(auth.PermKYCManage), kycAdmin.ListBlocked())
+	adminGroup.Post("/kyc/users/:user_id/override", auth.RequirePermission(auth.PermKYCManage), kycAdmin.Override())
+	adminGroup.Delete("/kyc/:user_id/session", auth.RequirePermission(auth.PermKYCManage), kycAdmin.DeleteSession())
 
 	ecosystemsAdmin := handlers.NewEcosystemsAdminHandler(deps.DB)
-	adminGroup.Get("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.List())
-	adminGroup.Get("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.GetByID())
-	adminGroup.Post("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.Create())
-	adminGroup.Put("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Update())
-	adminGroup.Delete("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Delete())
+	adminGroup.Get("/ecosystems", auth.RequirePermission(auth.PermEcosystemsManage), ecosystemsAdmin.List())
+	adminGroup.Get("/ecosystems/:id", auth.RequirePermission(auth.PermEcosystemsManage), ecosystemsAdmin.GetByID())
+	adminGroup.Post("/ecosystems", auth.RequirePermission(auth.PermEcosystemsManage), ecosystemsAdmin.Create())
+	adminGroup.Put("/ecosystems/:id", auth.RequirePermission(auth.PermEcosystemsManage), ecosystemsAdmin.Update())
+	adminGroup.Delete("/ecosystems/:id", auth.RequirePermission(auth.PermEcosystemsManage), ecosystemsAdmin.Delete())
+
+	// Canonical project tags (admin)
+	tagsAdmin := handlers.NewTagsAdminHandler(deps.DB)
+	adminGroup.Get("/tags", auth.RequirePermission(auth.PermTagsManage), tagsAdmin.List())
+	adminGroup.Post("/tags", auth.RequirePermission(auth.PermTagsManage), tagsAdmin.Create())
+	adminGroup.Post("/tags/:id/aliases", auth.RequirePermission(auth.PermTagsManage), tagsAdmin.AddAlias())
+	adminGroup.Post("/tags/:id/merge", auth.RequirePermission(auth.PermTagsManage), tagsAdmin.Merge())
 
 	// Open Source Week (admin)
 	oswAdmin := handlers.NewOpenSourceWeekAdminHandler(deps.DB)
-	adminGroup.Get("/open-source-week/events", auth.RequireRole("admin"), oswAdmin.List())
-	adminGroup.Post("/open-source-week/events", auth.RequireRole("admin"), oswAdmin.Create())
-	adminGroup.Delete("/open-source-week/events/:id", auth.RequireRole("admin"), oswAdmin.Delete())
+	adminGroup.Get("/open-source-week/events", auth.RequirePermission(auth.PermOpenSourceWeekManage), oswAdmin.List())
+	adminGroup.Post("/open-source-week/events", auth.RequirePermission(auth.PermOpenSourceWeekManage), oswAdmin.Create())
+	adminGroup.Delete("/open-source-week/events/:id", auth.RequirePermission(auth.PermOpenSourceWeekManage), oswAdmin.Delete())
+
+	// Reward programs (admin)
+	adminGroup.Get("/programs", auth.RequirePermission(auth.PermProgramsManage), programsH.AdminList())
+	adminGroup.Get("/programs/:id", auth.RequirePermission(auth.PermProgramsManage), programsH.AdminGet())
+	adminGroup.Post("/programs", auth.RequirePermission(auth.PermProgramsManage), programsH.Create())
+	adminGroup.Put("/programs/:id", auth.RequirePermission(auth.PermProgramsManage), programsH.Update())
+
+	// Payout reconciliation issues (admin)
+	reconciliation := handlers.NewReconciliationHandler(deps.DB)
+	adminGroup.Get("/reconciliation/issues", auth.RequirePermission(auth.PermReconciliationManage), reconciliation.List())
+	adminGroup.Post("/reconciliation/issues/:id/resolve", auth.RequirePermission(auth.PermReconciliationManage), reconciliation.Resolve())
+
+	// Approvals of payouts above PAYOUT_APPROVAL_THRESHOLD_USD (admin)
+	payoutApprovals := handlers.NewPayoutApprovalsHandler(deps.DB)
+	adminGroup.Get("/payouts", auth.RequirePermission(auth.PermPayoutsApprove), payoutApprovals.List())
+	adminGroup.Post("/payouts/:id/approve", auth.RequirePermission(auth.PermPayoutsApprove), payoutApprovals.Approve())
+	adminGroup.Post("/payouts/:id/reject", auth.RequirePermission(auth.PermPayoutsApprove), payoutApprovals.Reject())
+
+	// Asset metadata and manual prices (admin)
+	adminGroup.Put("/assets/:code", auth.RequirePermission(auth.PermAssetsManage), assetsH.Update())
+
+	// Prometheus scrape endpoint (token-protected, see METRICS_TOKEN)
+	metricsHandler := handlers.NewMetricsHandler(cfg, deps.DB)
+	app.Get("/metrics", metricsHandler.Scrape())
 
 	webhooks := handlers.NewGitHubWebhooksHandler(cfg, deps.DB, deps.Bus)
 	// Register webhook endpoint with explicit OPTIONS support for CORS
@@ -264,11 +494,15 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	})
 	app.Post("/webhooks/github", webhooks.Receive())
 	app.Post("/webhooks/github/", webhooks.Receive())
+	app.Post("/webhooks/bitbucket", bitbucketHandler.Receive())
 
 	// Didit webhook handler (supports both GET callback redirects and POST webhook events)
 	diditWebhook := handlers.NewDiditWebhookHandler(cfg, deps.DB)
 	app.Get("/webhooks/didit", diditWebhook.Receive())
 	app.Post("/webhooks/didit", diditWebhook.Receive())
+	adminGroup.Get("/kyc/webhook-events", auth.RequirePermission(auth.PermKYCManage), diditWebhook.ListDeliveries())
+	adminGroup.Get("/kyc/webhook-events/:id", auth.RequirePermission(auth.PermKYCManage), diditWebhook.GetDelivery())
+	adminGroup.Post("/kyc/webhook-events/:id/reprocess", auth.RequirePermission(auth.PermKYCManage), diditWebhook.Reprocess())
 
 	// Add catch-all 404 handler to log unmatched routes (helps debug routing issues)
 	app.Use(func(c *fiber.Ctx) error {
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+const testJWTSecret = "test-secret"
+
+// bearer returns an Authorization header for a new user with the KYC status.
+func bearer(t *testing.T, kycStatus string) string {
+	t.Helper()
+	userID := uuid.New()
+	auth.CacheKYCStatus(userID, kycStatus)
+	token, err := auth.IssueJWT(testJWTSecret, uuid.Nil, userID, "contributor", 0, "", "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// adminBearer returns an Authorization header for a new admin with verified KYC, with or without
+// the mfa claim.
+func adminBearer(t *testing.T, mfa bool) string {
+	t.Helper()
+	userID := uuid.New()
+	auth.CacheKYCStatus(userID, "verified")
+	claims := auth.Claims{Role: "admin", MFA: mfa}
+	claims.Subject = userID.String()
+	token, err := auth.SignJWT(testJWTSecret, claims, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestMoneyRoutesRequireKYC(t *testing.T) {
+	app := New(config.Config{JWTSecret: testJWTSecret}, Deps{})
+	bounty := "/bounties/" + uuid.NewString()
+
+	for _, path := range []string{bounty + "/claim", bounty + "/payout", bounty + "/escrow/release"} {
+		for _, tc := range []struct {
+			status string
+			want   int
+		}{
+			{"", fiber.StatusForbidden},
+			{"in_review", fiber.StatusForbidden},
+			{"rejected", fiber.StatusForbidden},
+			// Past the gate; the handler has no database.
+			{"verified", fiber.StatusServiceUnavailable},
+		} {
+			req := httptest.NewRequest("POST", path, nil)
+			req.Header.Set("Authorization", bearer(t, tc.status))
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("%s with kyc %q: status %d, want %d", path, tc.status, resp.StatusCode, tc.want)
+			}
+		}
+	}
+}
+
+func TestTOTPAttemptsAreRateLimited(t *testing.T) {
+	app := New(config.Config{JWTSecret: testJWTSecret}, Deps{})
+	attacker, victim := bearer(t, ""), bearer(t, "")
+	try := func(path, authz string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"code":"000000"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authz)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	// Verify and disable share the budget of 5 codes per user.
+	for i := 1; i <= 5; i++ {
+		path := "/auth/2fa/verify"
+		if i%2 == 0 {
+			path = "/auth/2fa/disable"
+		}
+		if got := try(path, attacker); got == fiber.StatusTooManyRequests {
+			t.Fatalf("attempt %d: rate limited too early", i)
+		}
+	}
+	if got := try("/auth/2fa/verify", attacker); got != fiber.StatusTooManyRequests {
+		t.Fatalf("sixth code: status %d, want %d", got, fiber.StatusTooManyRequests)
+	}
+	if got := try("/auth/2fa/disable", attacker); got != fiber.StatusTooManyRequests {
+		t.Fatalf("disable after the limit: status %d, want %d", got, fiber.StatusTooManyRequests)
+	}
+	if got := try("/auth/2fa/verify", victim); got == fiber.StatusTooManyRequests {
+		t.Fatal("another user was rate limited")
+	}
+}
+
+func TestEscrowApprovalNeedsAdminMFA(t *testing.T) {
+	app := New(config.Config{JWTSecret: testJWTSecret, AdminRequire2FA: true}, Deps{})
+	bounty := "/bounties/" + uuid.NewString()
+
+	for _, path := range []string{bounty + "/escrow/release", bounty + "/escrow/refund"} {
+		for _, tc := range []struct {
+			mfa  bool
+			want int
+		}{
+			{false, fiber.StatusForbidden},
+			// Past the gate; escrow is not configured.
+			{true, fiber.StatusServiceUnavailable},
+		} {
+			req := httptest.NewRequest("POST", path, nil)
+			req.Header.Set("Authorization", adminBearer(t, tc.mfa))
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("%s with mfa %v: status %d, want %d", path, tc.mfa, resp.StatusCode, tc.want)
+			}
+		}
+	}
+}
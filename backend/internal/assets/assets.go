@@ -0,0 +1,179 @@
+// Package assets keeps the assets table: the Stellar assets Grainlify supports, with the metadata
+// needed to show amounts in them (name, symbol, decimals) and an optional USD price. The sync worker
+// adds the assets the configuration names and refreshes prices from ASSET_PRICE_SOURCE; handlers join
+// the table to return bounty amounts as human-readable values.
+package assets
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultDecimals is the precision of classic Stellar assets.
+const DefaultDecimals = 7
+
+// Asset is a row of the assets table. USDPrice is nil when no price is known; PriceExpiresAt is
+// nil for a price set by hand, which does not expire.
+type Asset struct {
+	Code           string     `json:"code"`
+	Issuer         *string    `json:"issuer"`
+	Name           *string    `json:"name"`
+	Symbol         string     `json:"symbol"`
+	Decimals       int        `json:"decimals"`
+	USDPrice       *string    `json:"usd_price"`
+	PriceSource    *string    `json:"price_source"`
+	PriceUpdatedAt *time.Time `json:"price_updated_at"`
+	PriceExpiresAt *time.Time `json:"price_expires_at"`
+}
+
+// FreshPrice returns the asset's USD price, or nil when it has none or it expired.
+func (a *Asset) FreshPrice(now time.Time) *string {
+	if a == nil || a.USDPrice == nil || a.PriceExpiresAt != nil && now.After(*a.PriceExpiresAt) {
+		return nil
+	}
+	return a.USDPrice
+}
+
+// Columns is the select list Scan reads.
+const Columns = `code, issuer, name, symbol, decimals, usd_price::text, price_source, price_updated_at, price_expires_at`
+
+// Scan reads an asset selected with Columns.
+func Scan(row pgx.Row) (Asset, error) {
+	var a Asset
+	err := row.Scan(&a.Code, &a.Issuer, &a.Name, &a.Symbol, &a.Decimals, &a.USDPrice, &a.PriceSource,
+		&a.PriceUpdatedAt, &a.PriceExpiresAt)
+	return a, err
+}
+
+// Execer is a pool or transaction.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Sync adds the assets in codes (code to issuer, "" for XLM or an unknown issuer) that the table is
+// missing and fills in issuers it lacks. Metadata edited by admins is left alone.
+func Sync(ctx context.Context, db Execer, codes map[string]string) error {
+	for code, issuer := range codes {
+		if _, err := db.Exec(ctx, `
+INSERT INTO assets (code, issuer, symbol, decimals) VALUES ($1, NULLIF($2, ''), $1, $3)
+ON CONFLICT (code) DO UPDATE SET issuer = EXCLUDED.issuer, updated_at = now()
+WHERE assets.issuer IS NULL AND EXCLUDED.issuer IS NOT NULL
+`, code, issuer, DefaultDecimals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Querier is a pool or transaction.
+type Querier interface {
+	Execer
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// RefreshPrices fetches the USD price of every asset in the table from src and stores the ones it
+// returns, valid for maxAge. Assets the source does not price keep their old price until it expires.
+func RefreshPrices(ctx context.Context, db Querier, src PriceSource, maxAge time.Duration, now time.Time) (int, error) {
+	rows, err := db.Query(ctx, `SELECT code FROM assets ORDER BY code`)
+	if err != nil {
+		return 0, err
+	}
+	codes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, err
+	}
+	prices, err := src.Prices(ctx, codes)
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(prices))
+	for code := range prices {
+		keys = append(keys, code)
+	}
+	sort.Strings(keys)
+	n := 0
+	for _, code := range keys {
+		tag, err := db.Exec(ctx, `
+UPDATE assets
+SET usd_price = $2::numeric, price_source = $3, price_updated_at = $4, price_expires_at = $5, updated_at = now()
+WHERE code = $1
+`, code, prices[code], src.Name(), now, now.Add(maxAge))
+		if err != nil {
+			return n, err
+		}
+		n += int(tag.RowsAffected())
+	}
+	return n, nil
+}
+
+// ErrInvalidAmount is returned by FormatAmount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FormatAmount renders a non-negative decimal amount with at most decimals fractional digits,
+// without trailing zeros and with thousands separators: "1250.5000000" becomes "1,250.5".
+func FormatAmount(amount string, decimals int) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || r.Sign() < 0 {
+		return "", ErrInvalidAmount
+	}
+	s := r.FloatString(max(decimals, 0))
+	whole, frac, _ := strings.Cut(s, ".")
+	frac = strings.TrimRight(frac, "0")
+
+	var b strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String(), nil
+}
+
+// Value is an amount of an asset, ready to show.
+type Value struct {
+	Amount     string     `json:"amount"`
+	Asset      string     `json:"asset"`
+	Name       *string    `json:"name"`
+	Symbol     string     `json:"symbol"`
+	Decimals   int        `json:"decimals"`
+	Display    string     `json:"display"`
+	USD        *string    `json:"usd"`
+	USDPriceAt *time.Time `json:"usd_price_at"`
+}
+
+// NewValue describes amount of the asset code. a is the asset's row, or nil when the asset is not
+// in the table; then the code is the symbol and there is no USD value. USD values are rounded to
+// cents and left out when the price expired.
+func NewValue(amount, code string, a *Asset, now time.Time) Value {
+	v := Value{Amount: amount, Asset: code, Symbol: code, Decimals: DefaultDecimals}
+	if a != nil {
+		v.Name, v.Symbol, v.Decimals = a.Name, a.Symbol, a.Decimals
+	}
+	display, err := FormatAmount(amount, v.Decimals)
+	if err != nil {
+		display = amount
+	}
+	v.Display = display + " " + v.Symbol
+
+	if price := a.FreshPrice(now); price != nil {
+		x, ok1 := new(big.Rat).SetString(amount)
+		p, ok2 := new(big.Rat).SetString(*price)
+		if ok1 && ok2 {
+			usd := new(big.Rat).Mul(x, p).FloatString(2)
+			v.USD, v.USDPriceAt = &usd, a.PriceUpdatedAt
+		}
+	}
+	return v
+}
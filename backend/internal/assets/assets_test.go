@@ -0,0 +1,70 @@
+package assets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"1250.5000000", 7, "1,250.5"},
+		{"1234567", 7, "1,234,567"},
+		{"0.0000001", 7, "0.0000001"},
+		{"999.995", 2, "1,000"},
+		{"12.345", 0, "12"},
+	}
+	for _, c := range cases {
+		if got, err := FormatAmount(c.amount, c.decimals); err != nil || got != c.want {
+			t.Errorf("FormatAmount(%q, %d) = %q, %v; want %q", c.amount, c.decimals, got, err, c.want)
+		}
+	}
+	if _, err := FormatAmount("-1", 7); err == nil {
+		t.Error("negative amount accepted")
+	}
+}
+
+func TestNewValue(t *testing.T) {
+	now := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	name, price := "USD Coin", "0.9998"
+	updated, expires := now.Add(-10*time.Minute), now.Add(50*time.Minute)
+	usdc := &Asset{Code: "USDC", Name: &name, Symbol: "USDC", Decimals: 7, USDPrice: &price, PriceUpdatedAt: &updated, PriceExpiresAt: &expires}
+
+	v := NewValue("1250.5", "USDC", usdc, now)
+	if v.Display != "1,250.5 USDC" || v.USD == nil || *v.USD != "1250.25" {
+		t.Fatalf("NewValue = %+v", v)
+	}
+	if v := NewValue("1250.5", "USDC", usdc, expires.Add(time.Second)); v.USD != nil {
+		t.Errorf("expired price used: %s", *v.USD)
+	}
+	if v := NewValue("10", "EURC", nil, now); v.Display != "10 EURC" || v.USD != nil || v.Name != nil {
+		t.Errorf("unknown asset value = %+v", v)
+	}
+}
+
+func TestJSONFeedPrices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"xlm": 0.1234, "USDC": "1.00", "BAD": "-3", "AQUA": 1e-3}`))
+	}))
+	defer srv.Close()
+	src, err := NewPriceSource(srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := src.Prices(context.Background(), []string{"XLM", "USDC", "BAD", "AQUA", "EURC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["XLM"] != "0.1234" || got["USDC"] != "1.00" {
+		t.Errorf("Prices = %v", got)
+	}
+	if _, err := NewPriceSource("coingecko", "XLM"); err == nil {
+		t.Error("price id without coin id accepted")
+	}
+}
@@ -0,0 +1,156 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PriceSource returns USD prices (decimal strings) by asset code. Codes it cannot price are left
+// out of the result.
+type PriceSource interface {
+	Name() string
+	Prices(ctx context.Context, codes []string) (map[string]string, error)
+}
+
+// coinGeckoURL is the CoinGecko simple price endpoint.
+const coinGeckoURL = "https://api.coingecko.com/api/v3/simple/price"
+
+// NewPriceSource returns the source ASSET_PRICE_SOURCE names: "coingecko", which looks codes up by
+// the CODE:coin-id pairs in ids, or an http(s) URL serving a JSON object of code to USD price. It
+// returns nil when source is empty.
+func NewPriceSource(source, ids string) (PriceSource, error) {
+	source = strings.TrimSpace(source)
+	client := &http.Client{Timeout: 15 * time.Second}
+	switch {
+	case source == "":
+		return nil, nil
+	case strings.EqualFold(source, "coingecko"):
+		m, err := parseIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		return &coinGecko{http: client, baseURL: coinGeckoURL, ids: m}, nil
+	case strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://"):
+		if _, err := url.Parse(source); err != nil {
+			return nil, err
+		}
+		return &jsonFeed{http: client, url: source}, nil
+	}
+	return nil, fmt.Errorf("unknown price source %q (want coingecko or a URL)", source)
+}
+
+// parseIDs reads comma-separated CODE:coin-id pairs.
+func parseIDs(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, id, ok := strings.Cut(pair, ":")
+		code, id = strings.ToUpper(strings.TrimSpace(code)), strings.TrimSpace(id)
+		if !ok || code == "" || id == "" {
+			return nil, fmt.Errorf("invalid price id %q (want CODE:coin-id)", pair)
+		}
+		out[code] = id
+	}
+	return out, nil
+}
+
+// coinGecko prices assets with CoinGecko's simple price API.
+type coinGecko struct {
+	http    *http.Client
+	baseURL string
+	ids     map[string]string
+}
+
+func (s *coinGecko) Name() string { return "coingecko" }
+
+func (s *coinGecko) Prices(ctx context.Context, codes []string) (map[string]string, error) {
+	var ids []string
+	for _, code := range codes {
+		if id, ok := s.ids[code]; ok {
+			ids = append(ids, id)
+		}
+	}
+	out := map[string]string{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	q := url.Values{"ids": {strings.Join(ids, ",")}, "vs_currencies": {"usd"}}
+	var body map[string]struct {
+		USD json.Number `json:"usd"`
+	}
+	if err := getJSON(ctx, s.http, s.baseURL+"?"+q.Encode(), &body); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if price, ok := body[s.ids[code]]; ok && ValidPrice(string(price.USD)) {
+			out[code] = string(price.USD)
+		}
+	}
+	return out, nil
+}
+
+// jsonFeed prices assets from a URL serving {"XLM": 0.12, "USDC": "1.00"}.
+type jsonFeed struct {
+	http *http.Client
+	url  string
+}
+
+func (s *jsonFeed) Name() string { return "url" }
+
+func (s *jsonFeed) Prices(ctx context.Context, codes []string) (map[string]string, error) {
+	var body map[string]any
+	if err := getJSON(ctx, s.http, s.url, &body); err != nil {
+		return nil, err
+	}
+	byCode := map[string]string{}
+	for k, v := range body {
+		switch p := v.(type) {
+		case json.Number:
+			byCode[strings.ToUpper(k)] = string(p)
+		case string:
+			byCode[strings.ToUpper(k)] = strings.TrimSpace(p)
+		}
+	}
+	out := map[string]string{}
+	for _, code := range codes {
+		if price, ok := byCode[code]; ok && ValidPrice(price) {
+			out[code] = price
+		}
+	}
+	return out, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("price source returned %s", resp.Status)
+	}
+	dec := json.NewDecoder(io.LimitReader(resp.Body, 1<<20))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+// ValidPrice reports whether s is a non-negative decimal the usd_price column can hold.
+func ValidPrice(s string) bool {
+	r, ok := new(big.Rat).SetString(s)
+	return ok && r.Sign() >= 0 && !strings.ContainsAny(s, "eE/")
+}
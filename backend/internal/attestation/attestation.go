@@ -0,0 +1,170 @@
+// Package attestation signs and verifies contribution attestations: a contributor's stats for a
+// period, issued as an EdDSA (Ed25519) JWT so grant programs can check them against the public keys
+// at GET /attestations/keys, with any JOSE library or POST /attestations/verify.
+//
+// The signing key is a 32-byte Ed25519 seed (ATTESTATION_SIGNING_KEY_B64). To rotate it, move the
+// old key's public half to ATTESTATION_RETIRED_PUBLIC_KEYS: attestations it signed still verify and
+// it stays published, but nothing new is signed with it.
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// Issuer and Audience are set on every attestation so it cannot pass for a login token.
+	Issuer   = "grainlify"
+	Audience = "grainlify-attestation"
+	// TokenType is the typ header of an attestation.
+	TokenType = "grainlify-attestation+jwt"
+)
+
+// ErrUnknownKey is returned by Verify for attestations signed with a key this server does not know.
+var ErrUnknownKey = errors.New("unknown attestation key")
+
+// Stats are the attested contribution counts for the period, on verified and archived projects.
+type Stats struct {
+	Issues             int      `json:"issues"`
+	PullRequests       int      `json:"pull_requests"`
+	MergedPullRequests int      `json:"merged_pull_requests"`
+	Commits            int      `json:"commits"`
+	Projects           int      `json:"projects"`
+	Ecosystems         []string `json:"ecosystems"`
+}
+
+// Claims is the attestation payload. Subject is the GitHub login; From and To are inclusive UTC dates.
+type Claims struct {
+	GitHubUserID int64  `json:"github_user_id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Stats        Stats  `json:"stats"`
+	jwt.RegisteredClaims
+}
+
+// Key is a published verification key in JWK form.
+type Key struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Current is false for retired keys, which verify old attestations but sign nothing new.
+	Current bool `json:"current"`
+}
+
+// Signer issues attestations with the current key and verifies them with any known key.
+type Signer struct {
+	key    ed25519.PrivateKey
+	kid    string
+	keys   map[string]ed25519.PublicKey
+	retire []string
+}
+
+// NewSigner loads the signing seed and the retired public keys (comma-separated), all standard
+// base64. It returns nil, nil when no seed is configured.
+func NewSigner(seedB64, retiredB64 string) (*Signer, error) {
+	if strings.TrimSpace(seedB64) == "" {
+		return nil, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(seedB64))
+	if err != nil {
+		return nil, fmt.Errorf("decode ATTESTATION_SIGNING_KEY_B64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("ATTESTATION_SIGNING_KEY_B64 must decode to %d bytes", ed25519.SeedSize)
+	}
+	s := &Signer{key: ed25519.NewKeyFromSeed(seed), keys: map[string]ed25519.PublicKey{}}
+	pub := s.key.Public().(ed25519.PublicKey)
+	s.kid = KeyID(pub)
+	s.keys[s.kid] = pub
+	for _, part := range strings.Split(retiredB64, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(part)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("ATTESTATION_RETIRED_PUBLIC_KEYS: %q is not a base64 Ed25519 public key", part)
+		}
+		kid := KeyID(raw)
+		if _, ok := s.keys[kid]; !ok {
+			s.keys[kid] = ed25519.PublicKey(raw)
+			s.retire = append(s.retire, kid)
+		}
+	}
+	return s, nil
+}
+
+// KeyID derives the kid of a public key: the first 8 bytes of its SHA-256, in hex.
+func KeyID(pub []byte) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Keys returns the current key followed by the retired ones.
+func (s *Signer) Keys() []Key {
+	out := make([]Key, 0, 1+len(s.retire))
+	for i, kid := range append([]string{s.kid}, s.retire...) {
+		out = append(out, Key{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(s.keys[kid]),
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: "EdDSA",
+			Current:   i == 0,
+		})
+	}
+	return out
+}
+
+// Sign stamps the registered claims (issuer, audience, subject, issued-at, a fresh ID) and signs c.
+func (s *Signer) Sign(login string, c Claims, now time.Time) (string, Claims, error) {
+	c.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:   Issuer,
+		Subject:  login,
+		Audience: jwt.ClaimStrings{Audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		ID:       uuid.NewString(),
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodEdDSA, c)
+	t.Header["kid"] = s.kid
+	t.Header["typ"] = TokenType
+	signed, err := t.SignedString(s.key)
+	return signed, c, err
+}
+
+// Verify checks the signature against the key named by the kid header and returns the claims.
+func (s *Signer) Verify(token string) (*Claims, string, error) {
+	var kid string
+	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(t *jwt.Token) (any, error) {
+		if t.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		kid, _ = t.Header["kid"].(string)
+		pub, ok := s.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return pub, nil
+	}, jwt.WithIssuer(Issuer), jwt.WithAudience(Audience), jwt.WithIssuedAt())
+	if err != nil {
+		return nil, kid, err
+	}
+	claims, ok := parsed.Claims.(*Claims)
+	if !ok || !parsed.Valid {
+		return nil, kid, fmt.Errorf("invalid attestation")
+	}
+	return claims, kid, nil
+}
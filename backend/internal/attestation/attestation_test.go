@@ -0,0 +1,86 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func seed(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, ed25519.SeedSize))
+}
+
+func publicKey(b byte) string {
+	pub := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{b}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+func TestSignVerify(t *testing.T) {
+	s, err := NewSigner(seed(1), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := s.Sign("alice", Claims{
+		GitHubUserID: 42,
+		From:         "2026-01-01",
+		To:           "2026-03-31",
+		Stats:        Stats{PullRequests: 3, MergedPullRequests: 2, Ecosystems: []string{"Starknet"}},
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, kid, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if kid != s.Keys()[0].KeyID || claims.Subject != "alice" || claims.Stats.MergedPullRequests != 2 || claims.GitHubUserID != 42 {
+		t.Errorf("Verify returned kid %q, claims %+v", kid, claims)
+	}
+
+	// Tampering with the payload breaks the signature.
+	tampered := []byte(token)
+	tampered[len(token)/2] ^= 1
+	if _, _, err := s.Verify(string(tampered)); err == nil {
+		t.Error("tampered attestation verified")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old, _ := NewSigner(seed(1), "")
+	token, _, err := old.Sign("alice", Claims{From: "2026-01-01", To: "2026-01-31"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := NewSigner(seed(2), publicKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rotated.Verify(token); err != nil {
+		t.Errorf("attestation signed by a retired key: %v", err)
+	}
+	keys := rotated.Keys()
+	if len(keys) != 2 || !keys[0].Current || keys[1].Current || keys[1].KeyID != old.Keys()[0].KeyID {
+		t.Errorf("Keys() = %+v", keys)
+	}
+
+	other, _ := NewSigner(seed(3), "")
+	if _, _, err := other.Verify(token); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown key: got %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestNewSignerConfig(t *testing.T) {
+	if s, err := NewSigner("", ""); s != nil || err != nil {
+		t.Errorf("empty seed: got %v, %v", s, err)
+	}
+	if _, err := NewSigner("c2hvcnQ=", ""); err == nil {
+		t.Error("short seed accepted")
+	}
+	if _, err := NewSigner(seed(1), "not-a-key"); err == nil {
+		t.Error("malformed retired key accepted")
+	}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunStateCleanup periodically deletes expired OAuth states and magic-link tokens.
+// Consumed rows are deleted at callback time; this removes the ones that were never used.
+// Safe to run on every API instance. Blocks until ctx is cancelled.
+func RunStateCleanup(ctx context.Context, pool *pgxpool.Pool, interval time.Duration) {
+	if pool == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cleanupExpiredStates(ctx, pool)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func cleanupExpiredStates(ctx context.Context, pool *pgxpool.Pool) {
+	ct, err := pool.Exec(ctx, `DELETE FROM oauth_states WHERE expires_at <= now()`)
+	if err != nil {
+		slog.Warn("oauth state cleanup failed", "error", err)
+		return
+	}
+	states := ct.RowsAffected()
+
+	ct, err = pool.Exec(ctx, `DELETE FROM email_login_tokens WHERE expires_at <= now()`)
+	if err != nil {
+		slog.Warn("email login token cleanup failed", "error", err)
+		return
+	}
+	if states > 0 || ct.RowsAffected() > 0 {
+		slog.Info("expired auth states cleaned up",
+			"oauth_states", states,
+			"email_login_tokens", ct.RowsAffected(),
+		)
+	}
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrIdentityLinkedElsewhere = errors.New("identity_linked_to_other_user")
+
+type LinkedIdentity struct {
+	Provider    string    `json:"provider"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResolveOAuthIdentity maps an external identity to a user, creating or merging as needed:
+//  1. identity already linked -> that user (must equal linkUserID when linking)
+//  2. linkUserID set          -> attach identity to that user
+//  3. verified email matches another verified identity -> attach to that identity's user
+//  4. otherwise               -> create a new user
+func ResolveOAuthIdentity(ctx context.Context, pool *pgxpool.Pool, id OAuthIdentity, linkUserID *uuid.UUID) (User, error) {
+	if pool == nil {
+		return User{}, fmt.Errorf("db not configured")
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return User{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var u User
+	err = tx.QueryRow(ctx, `
+SELECT u.id, u.role
+FROM oauth_identities oi
+JOIN users u ON u.id = oi.user_id
+WHERE oi.provider = $1 AND oi.subject = $2
+`, id.Provider, id.Subject).Scan(&u.ID, &u.Role)
+	switch {
+	case err == nil:
+		if linkUserID != nil && *linkUserID != u.ID {
+			return User{}, ErrIdentityLinkedElsewhere
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return User{}, err
+	case linkUserID != nil:
+		if err := tx.QueryRow(ctx, `SELECT id, role FROM users WHERE id = $1`, *linkUserID).Scan(&u.ID, &u.Role); err != nil {
+			return User{}, err
+		}
+	default:
+		found := false
+		if id.EmailVerified && strings.TrimSpace(id.Email) != "" {
+			err = tx.QueryRow(ctx, `
+SELECT u.id, u.role
+FROM oauth_identities oi
+JOIN users u ON u.id = oi.user_id
+WHERE oi.email_verified AND LOWER(oi.email) = LOWER($1)
+ORDER BY oi.created_at ASC
+LIMIT 1
+`, id.Email).Scan(&u.ID, &u.Role)
+			if err == nil {
+				found = true
+			} else if !errors.Is(err, pgx.ErrNoRows) {
+				return User{}, err
+			}
+		}
+		if !found {
+			err = tx.QueryRow(ctx, `
+INSERT INTO users (display_name) VALUES ($1)
+RETURNING id, role
+`, nullIfEmpty(id.Name)).Scan(&u.ID, &u.Role)
+			if err != nil {
+				return User{}, err
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+INSERT INTO oauth_identities (user_id, provider, subject, email, email_verified, display_name, avatar_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (provider, subject) DO UPDATE SET
+  email = EXCLUDED.email,
+  email_verified = EXCLUDED.email_verified,
+  display_name = EXCLUDED.display_name,
+  avatar_url = EXCLUDED.avatar_url,
+  updated_at = now()
+`, u.ID, id.Provider, id.Subject, nullIfEmpty(id.Email), id.EmailVerified, nullIfEmpty(id.Name), nullIfEmpty(id.AvatarURL))
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func ListIdentities(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]LinkedIdentity, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT provider, subject, COALESCE(email, ''), COALESCE(display_name, ''), COALESCE(avatar_url, ''), created_at
+FROM oauth_identities
+WHERE user_id = $1
+ORDER BY created_at ASC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []LinkedIdentity
+	for rows.Next() {
+		var li LinkedIdentity
+		if err := rows.Scan(&li.Provider, &li.Subject, &li.Email, &li.DisplayName, &li.AvatarURL, &li.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, li)
+	}
+	return out, rows.Err()
+}
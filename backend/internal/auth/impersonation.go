@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IssueImpersonationJWT signs a token whose subject is userID and whose act claim records adminID.
+// The token is backed by a session on the impersonated user so it can be revoked like any other.
+func IssueImpersonationJWT(ctx context.Context, pool *pgxpool.Pool, secret string, adminID uuid.UUID, userID uuid.UUID, role string, userAgent string, ip string, ttl time.Duration) (string, Session, error) {
+	tv, err := TokenVersion(ctx, pool, userID)
+	if err != nil {
+		return "", Session{}, err
+	}
+	s, err := CreateSession(ctx, pool, userID, userAgent, ip, ttl)
+	if err != nil {
+		return "", Session{}, err
+	}
+	claims := Claims{
+		Role:         role,
+		TokenVersion: tv,
+		Actor:        &Actor{Subject: adminID.String()},
+	}
+	claims.Subject = userID.String()
+	claims.ID = s.ID.String()
+	token, err := SignJWT(secret, claims, ttl)
+	if err != nil {
+		return "", Session{}, err
+	}
+	return token, s, nil
+}
+
+// auditImpersonatedRequest records a request made under an impersonation token. Failures are logged,
+// never surfaced to the caller.
+func auditImpersonatedRequest(c *fiber.Ctx, pool *pgxpool.Pool, claims *Claims, handlerErr error) {
+	status := c.Response().StatusCode()
+	var fe *fiber.Error
+	if errors.As(handlerErr, &fe) {
+		status = fe.Code
+	} else if handlerErr != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	slog.Info("impersonated request",
+		"admin_user_id", claims.Actor.Subject,
+		"user_id", claims.Subject,
+		"session_id", claims.ID,
+		"method", c.Method(),
+		"path", c.Path(),
+		"status", status,
+		"request_id", c.Locals("requestid"),
+	)
+	if pool == nil {
+		return
+	}
+
+	var sessionID *uuid.UUID
+	if id, err := uuid.Parse(claims.ID); err == nil {
+		sessionID = &id
+	}
+	_, err := pool.Exec(c.Context(), `
+INSERT INTO impersonation_audit (session_id, admin_user_id, user_id, method, path, status, ip)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`, sessionID, claims.Actor.Subject, claims.Subject, c.Method(), c.Path(), status, nullIfEmpty(c.IP()))
+	if err != nil {
+		slog.Error("impersonation audit insert failed",
+			"admin_user_id", claims.Actor.Subject,
+			"user_id", claims.Subject,
+			"error", err,
+		)
+	}
+}
@@ -13,9 +13,54 @@ type Claims struct {
 	Role       string `json:"role"`
 	WalletType string `json:"wallet_type,omitempty"`
 	Address    string `json:"address,omitempty"`
+	// MFA is set when the session completed a TOTP second factor.
+	MFA bool `json:"mfa,omitempty"`
+	// TokenVersion must match users.token_version; admins bump it to force logout.
+	TokenVersion int `json:"tv,omitempty"`
+	// Scope is set on scoped tokens (space-separated); such tokens are only accepted by RequireScope.
+	Scope     string `json:"scope,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Actor is set on impersonation tokens: Subject is the impersonated user, Actor.Subject the admin (RFC 8693 "act").
+	Actor *Actor `json:"act,omitempty"`
 }
 
-func IssueJWT(secret string, userID uuid.UUID, role string, walletType WalletType, address string, ttl time.Duration) (string, error) {
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// tokenClaims holds the iss/aud stamped on issued tokens and required when parsing.
+var tokenClaims struct {
+	issuer   string
+	audience string
+}
+
+// ConfigureTokens sets the issuer and audience used by SignJWT and enforced by ParseJWT.
+// Empty values disable the respective claim.
+func ConfigureTokens(issuer string, audience string) {
+	tokenClaims.issuer = issuer
+	tokenClaims.audience = audience
+}
+
+// IssueJWT signs a token for userID. sessionID becomes the jti; pass uuid.Nil for a token
+// that is not backed by a server-side session.
+func IssueJWT(secret string, sessionID uuid.UUID, userID uuid.UUID, role string, tokenVersion int, walletType WalletType, address string, ttl time.Duration) (string, error) {
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: userID.String(),
+		},
+		Role:         role,
+		TokenVersion: tokenVersion,
+		WalletType:   string(walletType),
+		Address:      address,
+	}
+	if sessionID != uuid.Nil {
+		claims.ID = sessionID.String()
+	}
+	return SignJWT(secret, claims, ttl)
+}
+
+// SignJWT stamps iat/exp on claims and signs them. Use it when a token needs claims beyond IssueJWT's arguments.
+func SignJWT(secret string, claims Claims, ttl time.Duration) (string, error) {
 	if secret == "" {
 		return "", fmt.Errorf("JWT_SECRET is required")
 	}
@@ -24,15 +69,12 @@ func IssueJWT(secret string, userID uuid.UUID, role string, walletType WalletTyp
 	}
 
 	now := time.Now()
-	claims := Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   userID.String(),
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
-		},
-		Role:       role,
-		WalletType: string(walletType),
-		Address:    address,
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	claims.Issuer = tokenClaims.issuer
+	claims.Audience = nil
+	if tokenClaims.audience != "" {
+		claims.Audience = jwt.ClaimStrings{tokenClaims.audience}
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -43,12 +85,19 @@ func ParseJWT(secret string, tokenString string) (*Claims, error) {
 	if secret == "" {
 		return nil, fmt.Errorf("JWT_SECRET is required")
 	}
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if tokenClaims.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(tokenClaims.issuer))
+	}
+	if tokenClaims.audience != "" {
+		opts = append(opts, jwt.WithAudience(tokenClaims.audience))
+	}
 	parsed, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
 		if token.Method != jwt.SigningMethodHS256 {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
 		return []byte(secret), nil
-	})
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseJWTEnforcesIssuerAndAudience(t *testing.T) {
+	defer ConfigureTokens("", "")
+
+	ConfigureTokens("grainlify", "grainlify-api")
+	token, err := IssueJWT("secret", uuid.Nil, uuid.New(), "contributor", 0, "", "", time.Minute)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := ParseJWT("secret", token); err != nil {
+		t.Fatalf("parse with matching iss/aud: %v", err)
+	}
+
+	ConfigureTokens("grainlify", "other-api")
+	if _, err := ParseJWT("secret", token); err == nil {
+		t.Fatal("expected audience mismatch to be rejected")
+	}
+
+	ConfigureTokens("someone-else", "grainlify-api")
+	if _, err := ParseJWT("secret", token); err == nil {
+		t.Fatal("expected issuer mismatch to be rejected")
+	}
+}
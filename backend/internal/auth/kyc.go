@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	kycCacheTTL = 30 * time.Second
+	// Past this many cached users, expired entries are swept on the next load.
+	kycCacheMaxEntries = 10000
+)
+
+type kycCacheEntry struct {
+	status   string // "" when the user never started KYC
+	loadedAt time.Time
+}
+
+// kycCache holds users' current KYC status briefly; RequireKYC runs on every gated request.
+var kycCache = struct {
+	sync.Mutex
+	byUser map[uuid.UUID]kycCacheEntry
+}{byUser: map[uuid.UUID]kycCacheEntry{}}
+
+// KYCStatus returns the user's current KYC status (from their latest KYC session), or "" when they
+// never started one. Results are cached for kycCacheTTL.
+func KYCStatus(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (string, error) {
+	kycCache.Lock()
+	e, ok := kycCache.byUser[userID]
+	kycCache.Unlock()
+	if ok && time.Since(e.loadedAt) <= kycCacheTTL {
+		return e.status, nil
+	}
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+
+	var status string
+	err := pool.QueryRow(ctx, `SELECT status FROM user_kyc WHERE user_id = $1`, userID).Scan(&status)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	kycCache.Lock()
+	if len(kycCache.byUser) >= kycCacheMaxEntries {
+		for id, e := range kycCache.byUser {
+			if time.Since(e.loadedAt) > kycCacheTTL {
+				delete(kycCache.byUser, id)
+			}
+		}
+	}
+	kycCache.byUser[userID] = kycCacheEntry{status: status, loadedAt: time.Now()}
+	kycCache.Unlock()
+	return status, nil
+}
+
+// CacheKYCStatus records status as the user's current KYC status for kycCacheTTL; kyc.UpdateStatus
+// calls it once a change is committed.
+func CacheKYCStatus(userID uuid.UUID, status string) {
+	kycCache.Lock()
+	defer kycCache.Unlock()
+	kycCache.byUser[userID] = kycCacheEntry{status: status, loadedAt: time.Now()}
+}
+
+// InvalidateKYC drops the cached KYC status of the user (call after it changes).
+func InvalidateKYC(userID uuid.UUID) {
+	kycCache.Lock()
+	defer kycCache.Unlock()
+	delete(kycCache.byUser, userID)
+}
+
+// kycDenialReason explains why status does not pass RequireKYC, or returns "" when it does.
+func kycDenialReason(status string) string {
+	switch status {
+	case "verified":
+		return ""
+	case "", "not_started":
+		return "kyc_not_started"
+	case "pending", "in_review":
+		return "kyc_in_progress"
+	case "rejected":
+		return "kyc_rejected"
+	case "expired":
+		return "kyc_expired"
+	case "blocked":
+		return "kyc_blocked"
+	default:
+		return "kyc_unverified"
+	}
+}
+
+// RequireKYC allows the request only if the caller has completed KYC verification. Otherwise it
+// responds 403 kyc_required with the reason and current status, so clients can send the user to
+// the right step. Must run after RequireAuth.
+func RequireKYC(pool *pgxpool.Pool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sub, _ := c.Locals(LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		status, err := KYCStatus(c.Context(), pool, userID)
+		if err != nil {
+			slog.Error("kyc check failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "kyc_check_failed"})
+		}
+		if reason := kycDenialReason(status); reason != "" {
+			var current any
+			if status != "" {
+				current = status
+			}
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":      "kyc_required",
+				"reason":     reason,
+				"kyc_status": current,
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRequireKYC(t *testing.T) {
+	app := fiber.New()
+	var caller uuid.UUID
+	app.Get("/payout", func(c *fiber.Ctx) error {
+		c.Locals(LocalUserID, caller.String())
+		return c.Next()
+	}, RequireKYC(nil), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	cases := []struct {
+		status string
+		want   int
+	}{
+		{"verified", fiber.StatusOK},
+		{"", fiber.StatusForbidden},
+		{"in_review", fiber.StatusForbidden},
+		{"rejected", fiber.StatusForbidden},
+		{"blocked", fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		caller = uuid.New()
+		kycCache.Lock()
+		kycCache.byUser[caller] = kycCacheEntry{status: tc.status, loadedAt: time.Now()}
+		kycCache.Unlock()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/payout", nil))
+		if err != nil {
+			t.Fatalf("%q: %v", tc.status, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Errorf("%q: status %d, want %d", tc.status, resp.StatusCode, tc.want)
+		}
+	}
+
+	// Without a cached status or a pool the check cannot be made.
+	caller = uuid.New()
+	resp, err := app.Test(httptest.NewRequest("GET", "/payout", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("uncached: status %d, want %d", resp.StatusCode, fiber.StatusServiceUnavailable)
+	}
+}
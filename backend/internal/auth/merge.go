@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrMergeSameUser       = errors.New("merge_same_user")
+	ErrMergeUserNotFound   = errors.New("merge_user_not_found")
+	ErrMergeGitHubConflict = errors.New("merge_github_conflict")
+)
+
+// KYC conflict resolution when both accounts carry KYC state.
+const (
+	MergeKYCAuto   = "auto"   // keep whichever account is verified, preferring the target
+	MergeKYCTarget = "target" // always keep the target's KYC
+	MergeKYCSource = "source" // always take the source's KYC
+)
+
+type MergeOptions struct {
+	KYC string
+}
+
+// MergeResult summarises what moved from the source account into the target.
+type MergeResult struct {
+	MergeID         int64  `json:"merge_id"`
+	Wallets         int64  `json:"wallets"`
+	Identities      int64  `json:"identities"`
+	PayoutAddresses int64  `json:"payout_addresses"`
+	Projects        int64  `json:"projects"`
+	GitHubMoved     bool   `json:"github_moved"`
+	KYCFrom         string `json:"kyc_from"`
+	Role            string `json:"role"`
+	ProfileFilledIn int    `json:"profile_fields_filled"`
+	SessionsRevoked int64  `json:"sessions_revoked"`
+	TOTPFrom        string `json:"totp_from,omitempty"`
+}
+
+// profileColumns are copied from the source only where the target has no value.
+var profileColumns = []string{
+	"display_name", "first_name", "last_name", "location", "website", "bio", "avatar_url",
+	"telegram", "linkedin", "whatsapp", "twitter", "discord",
+}
+
+// MergeAccounts folds sourceID into targetID in one transaction and deletes the source user.
+// Wallets, OAuth identities, projects and (if the target has none) the GitHub account move over;
+// since contributions are attributed via the GitHub account they follow it. KYC is resolved per
+// opts.KYC, empty profile fields on the target are filled from the source, and the merge is
+// recorded in account_merges.
+func MergeAccounts(ctx context.Context, pool *pgxpool.Pool, targetID uuid.UUID, sourceID uuid.UUID, opts MergeOptions) (MergeResult, error) {
+	if pool == nil {
+		return MergeResult{}, fmt.Errorf("db not configured")
+	}
+	if targetID == sourceID {
+		return MergeResult{}, ErrMergeSameUser
+	}
+	switch opts.KYC {
+	case "":
+		opts.KYC = MergeKYCAuto
+	case MergeKYCAuto, MergeKYCTarget, MergeKYCSource:
+	default:
+		return MergeResult{}, fmt.Errorf("invalid kyc strategy")
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return MergeResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock both rows in a stable order to avoid deadlocks with a concurrent reverse merge.
+	type userRow struct {
+		role          string
+		githubUserID  *int64
+		kycStatus     *string
+		hasGitHubAcct bool
+		hasTOTP       bool
+	}
+	load := func(id uuid.UUID) (userRow, error) {
+		var r userRow
+		err := tx.QueryRow(ctx, `
+SELECT u.role, u.github_user_id, (SELECT k.status FROM user_kyc k WHERE k.user_id = u.id),
+       EXISTS(SELECT 1 FROM github_accounts g WHERE g.user_id = u.id),
+       EXISTS(SELECT 1 FROM user_totp t WHERE t.user_id = u.id AND t.enabled_at IS NOT NULL)
+FROM users u
+WHERE u.id = $1
+FOR UPDATE
+`, id).Scan(&r.role, &r.githubUserID, &r.kycStatus, &r.hasGitHubAcct, &r.hasTOTP)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r, ErrMergeUserNotFound
+		}
+		return r, err
+	}
+	first, second := targetID, sourceID
+	if second.String() < first.String() {
+		first, second = second, first
+	}
+	rows := map[uuid.UUID]userRow{}
+	for _, id := range []uuid.UUID{first, second} {
+		r, err := load(id)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		rows[id] = r
+	}
+	target, source := rows[targetID], rows[sourceID]
+
+	if (target.hasGitHubAcct && source.hasGitHubAcct) || (target.githubUserID != nil && source.githubUserID != nil && *target.githubUserID != *source.githubUserID) {
+		return MergeResult{}, ErrMergeGitHubConflict
+	}
+
+	var res MergeResult
+
+	ct, err := tx.Exec(ctx, `UPDATE wallets SET user_id = $1 WHERE user_id = $2`, targetID, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	res.Wallets = ct.RowsAffected()
+
+	ct, err = tx.Exec(ctx, `UPDATE oauth_identities SET user_id = $1, updated_at = now() WHERE user_id = $2`, targetID, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	res.Identities = ct.RowsAffected()
+
+	// Payout addresses the target already has stay with it; the target's primary one wins.
+	ct, err = tx.Exec(ctx, `
+UPDATE payout_addresses s
+SET user_id = $1,
+    is_primary = s.is_primary AND NOT EXISTS (SELECT 1 FROM payout_addresses t WHERE t.user_id = $1 AND t.is_primary),
+    updated_at = now()
+WHERE s.user_id = $2
+  AND NOT EXISTS (SELECT 1 FROM payout_addresses t WHERE t.user_id = $1 AND t.address = s.address)
+`, targetID, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	res.PayoutAddresses = ct.RowsAffected()
+
+	ct, err = tx.Exec(ctx, `UPDATE projects SET owner_user_id = $1, updated_at = now() WHERE owner_user_id = $2`, targetID, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	res.Projects = ct.RowsAffected()
+
+	// Memberships follow the account; where both held one on a project, the higher role wins.
+	if _, err := tx.Exec(ctx, `
+INSERT INTO project_members (project_id, user_id, role, invited_by, accepted_at, created_at)
+SELECT project_id, $1, role, invited_by, accepted_at, created_at FROM project_members WHERE user_id = $2
+ON CONFLICT (project_id, user_id) DO UPDATE SET
+  role = CASE
+    WHEN 'owner' IN (project_members.role, EXCLUDED.role) THEN 'owner'
+    WHEN 'maintainer' IN (project_members.role, EXCLUDED.role) THEN 'maintainer'
+    ELSE 'viewer'
+  END,
+  accepted_at = COALESCE(project_members.accepted_at, EXCLUDED.accepted_at),
+  updated_at = now()
+`, targetID, sourceID); err != nil {
+		return MergeResult{}, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM project_members WHERE user_id = $1`, sourceID); err != nil {
+		return MergeResult{}, err
+	}
+
+	if source.hasGitHubAcct {
+		if _, err := tx.Exec(ctx, `UPDATE github_accounts SET user_id = $1, updated_at = now() WHERE user_id = $2`, targetID, sourceID); err != nil {
+			return MergeResult{}, err
+		}
+		res.GitHubMoved = true
+	}
+	if source.githubUserID != nil && target.githubUserID == nil {
+		// github_user_id is UNIQUE: clear it on the source before setting it on the target.
+		if _, err := tx.Exec(ctx, `UPDATE users SET github_user_id = NULL WHERE id = $1`, sourceID); err != nil {
+			return MergeResult{}, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE users SET github_user_id = $2 WHERE id = $1`, targetID, *source.githubUserID); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	res.KYCFrom = "target"
+	takeSourceKYC := false
+	switch opts.KYC {
+	case MergeKYCSource:
+		takeSourceKYC = source.kycStatus != nil
+	case MergeKYCAuto:
+		targetVerified := target.kycStatus != nil && *target.kycStatus == "verified"
+		sourceVerified := source.kycStatus != nil && *source.kycStatus == "verified"
+		takeSourceKYC = !targetVerified && (sourceVerified || (target.kycStatus == nil && source.kycStatus != nil))
+	}
+	if takeSourceKYC {
+		// The source's sessions move to the target, its current one becoming the target's current session.
+		var kycSessionID *string
+		if err := tx.QueryRow(ctx, `
+SELECT session_id FROM user_kyc WHERE user_id = $1
+`, sourceID).Scan(&kycSessionID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return MergeResult{}, err
+		}
+		if _, err := tx.Exec(ctx, `
+UPDATE kyc_sessions
+SET user_id = $1,
+    activated_at = CASE WHEN id = (SELECT id FROM user_kyc WHERE user_id = $2) THEN now() ELSE activated_at END,
+    updated_at = now()
+WHERE user_id = $2
+`, targetID, sourceID); err != nil {
+			return MergeResult{}, err
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO kyc_events (user_id, old_status, new_status, source, session_id)
+SELECT $1, $2, $3, 'account_merge', $4
+WHERE $2::text IS DISTINCT FROM $3::text
+`, targetID, target.kycStatus, source.kycStatus, kycSessionID); err != nil {
+			return MergeResult{}, err
+		}
+		res.KYCFrom = "source"
+	}
+
+	res.Role = target.role
+	if target.role == "contributor" && source.role != "contributor" {
+		res.Role = source.role
+		if _, err := tx.Exec(ctx, `UPDATE users SET role = $2 WHERE id = $1`, targetID, source.role); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	for _, col := range profileColumns {
+		ct, err := tx.Exec(ctx, fmt.Sprintf(`
+UPDATE users t SET %[1]s = s.%[1]s
+FROM users s
+WHERE t.id = $1 AND s.id = $2
+  AND (t.%[1]s IS NULL OR t.%[1]s = '') AND s.%[1]s IS NOT NULL AND s.%[1]s <> ''
+`, col), targetID, sourceID)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		res.ProfileFilledIn += int(ct.RowsAffected())
+	}
+
+	// TOTP stays with the target; the source's secret is dropped with the source row unless the target has none.
+	if target.hasTOTP {
+		res.TOTPFrom = "target"
+	} else if source.hasTOTP {
+		res.TOTPFrom = "source"
+		if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, targetID); err != nil {
+			return MergeResult{}, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE user_totp SET user_id = $1 WHERE user_id = $2`, targetID, sourceID); err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	ct, err = tx.Exec(ctx, `UPDATE auth_sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, sourceID)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	res.SessionsRevoked = ct.RowsAffected()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, sourceID); err != nil {
+		return MergeResult{}, err
+	}
+
+	details, err := json.Marshal(res)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if err := tx.QueryRow(ctx, `
+INSERT INTO account_merges (target_user_id, source_user_id, kyc_strategy, details)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`, targetID, sourceID, opts.KYC, details).Scan(&res.MergeID); err != nil {
+		return MergeResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return MergeResult{}, err
+	}
+	if takeSourceKYC {
+		InvalidateKYC(targetID)
+		InvalidateKYC(sourceID)
+	}
+	return res, nil
+}
@@ -1,16 +1,202 @@
 package auth
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
-func LoginMessage(nonce string) string {
-	// Keep this stable; clients must sign this exact string.
-	return fmt.Sprintf("Patchwork login. Nonce: %s", nonce)
+// Statements shown to the user inside the signed message.
+const (
+	LoginStatement      = "Sign in to Grainlify."
+	LinkWalletStatement = "Link this wallet to your Grainlify account."
+)
+
+// SignInMessage is an EIP-4361 (Sign-In with Ethereum) style message. It binds a signature to
+// our domain, the wallet address, a single-use nonce and a validity window, so a signature
+// collected by another site (or replayed later) is rejected. The same format is used for all
+// wallet types; ChainID identifies the chain (e.g. "1", "stellar:testnet", "solana:mainnet").
+type SignInMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+}
+
+const signInMessageVersion = "1"
+
+// NewSignInMessage builds a message for the given wallet, valid until expiresAt.
+func NewSignInMessage(domain string, uri string, walletType WalletType, address string, statement string, chainID string, nonce string, expiresAt time.Time) SignInMessage {
+	if chainID == "" {
+		chainID = DefaultChainID(walletType)
+	}
+	return SignInMessage{
+		Domain:         domain,
+		Address:        address,
+		Statement:      statement,
+		URI:            uri,
+		Version:        signInMessageVersion,
+		ChainID:        chainID,
+		Nonce:          nonce,
+		IssuedAt:       time.Now().UTC().Truncate(time.Second),
+		ExpirationTime: expiresAt.UTC().Truncate(time.Second),
+	}
+}
+
+// DefaultChainID returns the chain identifier used when the client does not specify one.
+func DefaultChainID(walletType WalletType) string {
+	switch walletType {
+	case WalletTypeEVM:
+		return "1"
+	case WalletTypeSolana:
+		return "solana:mainnet"
+	case WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
+		return "stellar:pubnet"
+	default:
+		return ""
+	}
+}
+
+func (m SignInMessage) accountLabel() string {
+	switch {
+	case strings.HasPrefix(m.ChainID, "solana:"):
+		return "Solana"
+	case strings.HasPrefix(m.ChainID, "stellar:"):
+		return "Stellar"
+	default:
+		return "Ethereum"
+	}
 }
 
-// LegacyLoginMessage is kept temporarily for compatibility with early clients/tests.
-func LegacyLoginMessage(nonce string) string {
-	return fmt.Sprintf("Patchwork login\nNonce: %s", nonce)
+// String renders the canonical text clients must sign byte-for-byte.
+func (m SignInMessage) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s wants you to sign in with your %s account:\n", m.Domain, m.accountLabel())
+	b.WriteString(m.Address + "\n\n")
+	b.WriteString(m.Statement + "\n\n")
+	b.WriteString("URI: " + m.URI + "\n")
+	b.WriteString("Version: " + m.Version + "\n")
+	b.WriteString("Chain ID: " + m.ChainID + "\n")
+	b.WriteString("Nonce: " + m.Nonce + "\n")
+	b.WriteString("Issued At: " + m.IssuedAt.Format(time.RFC3339) + "\n")
+	b.WriteString("Expiration Time: " + m.ExpirationTime.Format(time.RFC3339))
+	return b.String()
 }
 
+// ParseSignInMessage parses the text produced by SignInMessage.String.
+func ParseSignInMessage(s string) (SignInMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if len(lines) < 11 {
+		return SignInMessage{}, fmt.Errorf("message too short")
+	}
 
+	var m SignInMessage
+	header := lines[0]
+	idx := strings.Index(header, " wants you to sign in with your ")
+	if idx <= 0 || !strings.HasSuffix(header, " account:") {
+		return SignInMessage{}, fmt.Errorf("invalid message header")
+	}
+	m.Domain = header[:idx]
+	m.Address = strings.TrimSpace(lines[1])
+	if lines[2] != "" || lines[4] != "" {
+		return SignInMessage{}, fmt.Errorf("invalid message layout")
+	}
+	m.Statement = lines[3]
 
+	fields := map[string]*string{
+		"URI":      &m.URI,
+		"Version":  &m.Version,
+		"Chain ID": &m.ChainID,
+		"Nonce":    &m.Nonce,
+	}
+	var issuedAt, expiresAt string
+	fields["Issued At"] = &issuedAt
+	fields["Expiration Time"] = &expiresAt
+
+	for _, line := range lines[5:] {
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ": ")
+		if !ok {
+			return SignInMessage{}, fmt.Errorf("invalid field line")
+		}
+		dst, known := fields[k]
+		if !known {
+			return SignInMessage{}, fmt.Errorf("unknown field %q", k)
+		}
+		if *dst != "" {
+			return SignInMessage{}, fmt.Errorf("duplicate field %q", k)
+		}
+		*dst = v
+	}
+
+	var err error
+	if m.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return SignInMessage{}, fmt.Errorf("invalid Issued At")
+	}
+	if m.ExpirationTime, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return SignInMessage{}, fmt.Errorf("invalid Expiration Time")
+	}
+	if m.URI == "" || m.Version == "" || m.ChainID == "" || m.Nonce == "" {
+		return SignInMessage{}, fmt.Errorf("missing required field")
+	}
+	return m, nil
+}
+
+// Validate checks the parsed message against what the server expects for this request.
+func (m SignInMessage) Validate(domain string, walletType WalletType, address string, nonce string, statement string, now time.Time) error {
+	if m.Domain != domain {
+		return fmt.Errorf("domain mismatch")
+	}
+	if m.Version != signInMessageVersion {
+		return fmt.Errorf("unsupported version")
+	}
+	if m.Statement != statement {
+		return fmt.Errorf("statement mismatch")
+	}
+	if m.Nonce != nonce {
+		return fmt.Errorf("nonce mismatch")
+	}
+	msgAddr, err := NormalizeAddress(walletType, m.Address)
+	if err != nil || msgAddr != address {
+		return fmt.Errorf("address mismatch")
+	}
+	if !chainMatchesWallet(walletType, m.ChainID) {
+		return fmt.Errorf("chain mismatch")
+	}
+	// Allow a little clock skew for issued-at.
+	if m.IssuedAt.After(now.Add(time.Minute)) {
+		return fmt.Errorf("message issued in the future")
+	}
+	if !now.Before(m.ExpirationTime) {
+		return fmt.Errorf("message expired")
+	}
+	return nil
+}
+
+func chainMatchesWallet(walletType WalletType, chainID string) bool {
+	switch walletType {
+	case WalletTypeEVM:
+		if chainID == "" {
+			return false
+		}
+		for _, r := range chainID {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return true
+	case WalletTypeSolana:
+		return strings.HasPrefix(chainID, "solana:")
+	case WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
+		return strings.HasPrefix(chainID, "stellar:")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignInMessageRoundTrip(t *testing.T) {
+	addr := "0x52908400098527886e0f7030069857d2e4169ee7"
+	expires := time.Now().Add(10 * time.Minute)
+	msg := NewSignInMessage("app.grainlify.com", "https://app.grainlify.com", WalletTypeEVM, addr, LoginStatement, "", "abc123", expires)
+
+	parsed, err := ParseSignInMessage(msg.String())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed != msg {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", parsed, msg)
+	}
+	if err := parsed.Validate("app.grainlify.com", WalletTypeEVM, addr, "abc123", LoginStatement, time.Now()); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestSignInMessageRejects(t *testing.T) {
+	addr := "0x52908400098527886e0f7030069857d2e4169ee7"
+	now := time.Now()
+	msg := NewSignInMessage("app.grainlify.com", "https://app.grainlify.com", WalletTypeEVM, addr, LoginStatement, "", "abc123", now.Add(10*time.Minute))
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"other domain", msg.Validate("evil.example", WalletTypeEVM, addr, "abc123", LoginStatement, now)},
+		{"other nonce", msg.Validate("app.grainlify.com", WalletTypeEVM, addr, "zzz", LoginStatement, now)},
+		{"link statement", msg.Validate("app.grainlify.com", WalletTypeEVM, addr, "abc123", LinkWalletStatement, now)},
+		{"expired", msg.Validate("app.grainlify.com", WalletTypeEVM, addr, "abc123", LoginStatement, now.Add(time.Hour))},
+		{"wrong chain", msg.Validate("app.grainlify.com", WalletTypeSolana, addr, "abc123", LoginStatement, now)},
+	}
+	for _, tc := range cases {
+		if tc.err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+
+	if _, err := ParseSignInMessage("Patchwork login. Nonce: abc123"); err == nil {
+		t.Error("expected legacy message to be rejected")
+	}
+}
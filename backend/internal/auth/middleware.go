@@ -1,18 +1,33 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	LocalUserID = "user_id"
 	LocalRole   = "role"
+
+	LocalSessionID = "session_id"
+	LocalMFA       = "mfa"
+	// LocalActorID holds the real admin's user ID when the request uses an impersonation token.
+	LocalActorID = "actor_id"
+	// LocalScopes holds the granted scopes ([]string) when the request uses a scoped token.
+	LocalScopes = "scopes"
 )
 
-func RequireAuth(jwtSecret string) fiber.Handler {
+// RequireAuth validates the bearer JWT. When pool is non-nil, the token's tv claim must match
+// users.token_version (bumped to force logout), and tokens carrying a jti are checked against
+// auth_sessions so revoked sessions stop working before the token expires.
+// Tokens without a jti (issued before session tracking) are accepted until they expire.
+// Scoped tokens are rejected; see RequireScope.
+func RequireAuth(jwtSecret string, pool *pgxpool.Pool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		h := strings.TrimSpace(c.Get("Authorization"))
 		if h == "" || !strings.HasPrefix(strings.ToLower(h), "bearer ") {
@@ -51,9 +66,72 @@ func RequireAuth(jwtSecret string) fiber.Handler {
 				"error": "invalid_token",
 			})
 		}
+		if claims.Scope != "" {
+			// Scoped tokens are only valid on routes guarded by RequireScope.
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "scoped_token_not_allowed",
+			})
+		}
+
+		if pool != nil {
+			userID, err := uuid.Parse(claims.Subject)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "invalid_token",
+				})
+			}
+			var sessionID *uuid.UUID
+			if claims.ID != "" {
+				id, err := uuid.Parse(claims.ID)
+				if err != nil {
+					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+						"error": "invalid_token",
+					})
+				}
+				sessionID = &id
+			}
+			versionOK, sessionOK, err := tokenState(c.Context(), pool, userID, claims.TokenVersion, sessionID)
+			if errors.Is(err, ErrUserNotFound) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "invalid_token",
+				})
+			}
+			if err != nil {
+				slog.Error("auth middleware: session lookup failed",
+					"path", c.Path(),
+					"session_id", claims.ID,
+					"error", err,
+					"request_id", c.Locals("requestid"),
+				)
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+					"error": "session_check_failed",
+				})
+			}
+			if !versionOK {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "token_invalidated",
+				})
+			}
+			if !sessionOK {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "session_revoked",
+				})
+			}
+			if claims.ID != "" {
+				c.Locals(LocalSessionID, claims.ID)
+			}
+		}
 
 		c.Locals(LocalUserID, claims.Subject)
 		c.Locals(LocalRole, claims.Role)
+		c.Locals(LocalMFA, claims.MFA)
+
+		if claims.Actor != nil && claims.Actor.Subject != "" {
+			c.Locals(LocalActorID, claims.Actor.Subject)
+			err := c.Next()
+			auditImpersonatedRequest(c, pool, claims, err)
+			return err
+		}
 		return c.Next()
 	}
 }
@@ -79,6 +157,42 @@ func RequireRole(roles ...string) fiber.Handler {
 	}
 }
 
+// RequireAdminMFA enforces a TOTP second factor for admin callers. Admins who enrolled TOTP must
+// present a token with the mfa claim; when requireEnrollment is set, admins without TOTP are rejected too.
+// Non-admin callers pass through (permission/role middleware decides their access).
+// Must run after RequireAuth.
+func RequireAdminMFA(pool *pgxpool.Pool, requireEnrollment bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals(LocalRole).(string)
+		if role != superuserRole {
+			return c.Next()
+		}
+		if mfa, _ := c.Locals(LocalMFA).(bool); mfa {
+			return c.Next()
+		}
+
+		sub, _ := c.Locals(LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		enrolled := false
+		if pool != nil {
+			enrolled, err = TOTPEnabled(c.Context(), pool, userID)
+			if err != nil {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "mfa_check_failed"})
+			}
+		}
+		if enrolled {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "mfa_required"})
+		}
+		if requireEnrollment {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "mfa_enrollment_required"})
+		}
+		return c.Next()
+	}
+}
+
 
 
 
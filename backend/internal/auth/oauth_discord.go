@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+const ProviderDiscord = "discord"
+
+type discordProvider struct {
+	cfg OAuthProviderConfig
+}
+
+func NewDiscordProvider(cfg OAuthProviderConfig) OAuthProvider {
+	return &discordProvider{cfg: cfg}
+}
+
+func (p *discordProvider) Name() string { return ProviderDiscord }
+
+func (p *discordProvider) AuthorizeURL(state string) (string, error) {
+	return buildAuthorizeURL("https://discord.com/oauth2/authorize", p.cfg, state,
+		[]string{"identify", "email"},
+		nil,
+	)
+}
+
+func (p *discordProvider) Exchange(ctx context.Context, code string) (OAuthIdentity, error) {
+	token, err := exchangeCodeForToken(ctx, "https://discord.com/api/oauth2/token", p.cfg, code)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	var u struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+		Email      string `json:"email"`
+		Verified   bool   `json:"verified"`
+		Avatar     string `json:"avatar"`
+	}
+	if err := getJSONWithBearer(ctx, "https://discord.com/api/users/@me", token, &u); err != nil {
+		return OAuthIdentity{}, err
+	}
+	if u.ID == "" {
+		return OAuthIdentity{}, fmt.Errorf("discord user missing id")
+	}
+
+	name := u.GlobalName
+	if name == "" {
+		name = u.Username
+	}
+	var avatarURL string
+	if u.Avatar != "" {
+		avatarURL = fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", u.ID, u.Avatar)
+	}
+
+	return OAuthIdentity{
+		Provider:      ProviderDiscord,
+		Subject:       u.ID,
+		Email:         u.Email,
+		EmailVerified: u.Verified,
+		Name:          name,
+		AvatarURL:     avatarURL,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+const ProviderGoogle = "google"
+
+type googleProvider struct {
+	cfg OAuthProviderConfig
+}
+
+func NewGoogleProvider(cfg OAuthProviderConfig) OAuthProvider {
+	return &googleProvider{cfg: cfg}
+}
+
+func (p *googleProvider) Name() string { return ProviderGoogle }
+
+func (p *googleProvider) AuthorizeURL(state string) (string, error) {
+	return buildAuthorizeURL("https://accounts.google.com/o/oauth2/v2/auth", p.cfg, state,
+		[]string{"openid", "email", "profile"},
+		map[string]string{"prompt": "select_account"},
+	)
+}
+
+func (p *googleProvider) Exchange(ctx context.Context, code string) (OAuthIdentity, error) {
+	token, err := exchangeCodeForToken(ctx, "https://oauth2.googleapis.com/token", p.cfg, code)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := getJSONWithBearer(ctx, "https://openidconnect.googleapis.com/v1/userinfo", token, &info); err != nil {
+		return OAuthIdentity{}, err
+	}
+	if info.Sub == "" {
+		return OAuthIdentity{}, fmt.Errorf("google userinfo missing sub")
+	}
+
+	return OAuthIdentity{
+		Provider:      ProviderGoogle,
+		Subject:       info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+		AvatarURL:     info.Picture,
+	}, nil
+}
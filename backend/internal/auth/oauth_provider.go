@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuthIdentity is the normalized identity returned by an external OAuth provider.
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string // Provider-scoped stable user ID.
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
+
+// OAuthProvider is implemented by each external login provider (Google, Discord, ...).
+// GitHub keeps its dedicated flow in handlers/github_oauth.go since it also stores repo tokens.
+type OAuthProvider interface {
+	Name() string
+	AuthorizeURL(state string) (string, error)
+	// Exchange trades an authorization code for the user's identity.
+	Exchange(ctx context.Context, code string) (OAuthIdentity, error)
+}
+
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+func (c OAuthProviderConfig) configured() bool {
+	return c.ClientID != "" && c.ClientSecret != "" && c.RedirectURL != ""
+}
+
+var oauthHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func buildAuthorizeURL(endpoint string, cfg OAuthProviderConfig, state string, scopes []string, extra map[string]string) (string, error) {
+	if !cfg.configured() {
+		return "", fmt.Errorf("oauth provider not configured")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.RedirectURL)
+	q.Set("response_type", "code")
+	q.Set("state", state)
+	q.Set("scope", strings.Join(scopes, " "))
+	for k, v := range extra {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// exchangeCodeForToken performs a standard form-encoded authorization_code grant.
+func exchangeCodeForToken(ctx context.Context, tokenURL string, cfg OAuthProviderConfig, code string) (string, error) {
+	if !cfg.configured() {
+		return "", fmt.Errorf("oauth provider not configured")
+	}
+	if code == "" {
+		return "", fmt.Errorf("code is required")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectURL)
+	form.Set("client_id", cfg.ClientID)
+	form.Set("client_secret", cfg.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("token exchange failed: status %d", resp.StatusCode)
+	}
+
+	var tr struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", err
+	}
+	if tr.AccessToken == "" {
+		return "", fmt.Errorf("token exchange returned empty token")
+	}
+	return tr.AccessToken, nil
+}
+
+func getJSONWithBearer(ctx context.Context, endpoint string, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("userinfo request failed: status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Permission keys referenced from code. The full set lives in the permissions table.
+const (
+	PermProjectsCreate       = "projects:create"
+	PermProjectsVerify       = "projects:verify"
+	PermProjectsSync         = "projects:sync"
+	PermUsersRead            = "users:read"
+	PermUsersManageRoles     = "users:manage_roles"
+	PermUsersImpersonate     = "users:impersonate"
+	PermUsersForceLogout     = "users:force_logout"
+	PermRolesManage          = "roles:manage"
+	PermEcosystemsManage     = "ecosystems:manage"
+	PermOpenSourceWeekManage = "open_source_week:manage"
+	PermWebhooksRotateSecret = "webhooks:rotate_secret"
+	PermJobsManage           = "jobs:manage"
+	PermKYCManage            = "kyc:manage"
+	PermProjectsReview       = "projects:review"
+	PermTagsManage           = "tags:manage"
+	PermProgramsManage       = "programs:manage"
+	PermReconciliationManage = "reconciliation:manage"
+	PermAssetsManage         = "assets:manage"
+	PermPayoutsApprove       = "payouts:approve"
+)
+
+const (
+	permissionCacheTTL = 30 * time.Second
+	superuserRole      = "admin"
+)
+
+// permissionMatrix caches role_permissions in memory; RequirePermission runs on hot paths.
+var permissionMatrix = struct {
+	sync.RWMutex
+	pool     *pgxpool.Pool
+	byRole   map[string]map[string]struct{}
+	loadedAt time.Time
+}{}
+
+// UsePermissionStore configures the pool RequirePermission loads the role/permission matrix from.
+func UsePermissionStore(pool *pgxpool.Pool) {
+	permissionMatrix.Lock()
+	defer permissionMatrix.Unlock()
+	permissionMatrix.pool = pool
+	permissionMatrix.byRole = nil
+}
+
+// InvalidatePermissions forces the next permission check to reload the matrix (call after grants change).
+func InvalidatePermissions() {
+	permissionMatrix.Lock()
+	defer permissionMatrix.Unlock()
+	permissionMatrix.byRole = nil
+}
+
+func loadPermissionMatrix(ctx context.Context, pool *pgxpool.Pool) (map[string]map[string]struct{}, error) {
+	rows, err := pool.Query(ctx, `SELECT role, permission FROM role_permissions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]map[string]struct{}{}
+	for rows.Next() {
+		var role, perm string
+		if err := rows.Scan(&role, &perm); err != nil {
+			return nil, err
+		}
+		if out[role] == nil {
+			out[role] = map[string]struct{}{}
+		}
+		out[role][perm] = struct{}{}
+	}
+	return out, rows.Err()
+}
+
+// RoleHasPermission reports whether role is granted perm. The admin role is a superuser.
+func RoleHasPermission(ctx context.Context, role string, perm string) (bool, error) {
+	if role == superuserRole {
+		return true, nil
+	}
+
+	permissionMatrix.RLock()
+	byRole, loadedAt, pool := permissionMatrix.byRole, permissionMatrix.loadedAt, permissionMatrix.pool
+	permissionMatrix.RUnlock()
+
+	if byRole == nil || time.Since(loadedAt) > permissionCacheTTL {
+		if pool == nil {
+			return false, fmt.Errorf("permission store not configured")
+		}
+		m, err := loadPermissionMatrix(ctx, pool)
+		if err != nil {
+			return false, err
+		}
+		permissionMatrix.Lock()
+		permissionMatrix.byRole = m
+		permissionMatrix.loadedAt = time.Now()
+		permissionMatrix.Unlock()
+		byRole = m
+	}
+
+	_, ok := byRole[role][perm]
+	return ok, nil
+}
+
+// RequirePermission allows the request if the caller's role has any of the given permissions.
+// Must run after RequireAuth.
+func RequirePermission(perms ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals(LocalRole).(string)
+		if role == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "missing_role",
+			})
+		}
+		for _, p := range perms {
+			ok, err := RoleHasPermission(c.Context(), role, p)
+			if err != nil {
+				slog.Error("permission check failed",
+					"role", role,
+					"permission", p,
+					"error", err,
+				)
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+					"error": "permission_check_failed",
+				})
+			}
+			if ok {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":       "insufficient_permission",
+			"permissions": perms,
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scopes that can be granted to scoped tokens. Scoped tokens are bound to one project.
+const (
+	ScopeProjectsRead = "projects:read"
+	ScopeSyncRead     = "sync:read"
+)
+
+var ErrScopedTokenNotFound = errors.New("scoped_token_not_found")
+
+// KnownScope reports whether s can be granted to a scoped token.
+func KnownScope(s string) bool {
+	switch s {
+	case ScopeProjectsRead, ScopeSyncRead:
+		return true
+	}
+	return false
+}
+
+type ScopedToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	ProjectID  uuid.UUID
+	Name       string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// IssueScopedJWT records a scoped token for a project and signs it. The token's jti is the
+// scoped_tokens row ID so it can be listed and revoked; its subject is the minting user.
+func IssueScopedJWT(ctx context.Context, pool *pgxpool.Pool, secret string, userID uuid.UUID, projectID uuid.UUID, name string, scopes []string, ttl time.Duration) (string, ScopedToken, error) {
+	if pool == nil {
+		return "", ScopedToken{}, fmt.Errorf("db not configured")
+	}
+	tv, err := TokenVersion(ctx, pool, userID)
+	if err != nil {
+		return "", ScopedToken{}, err
+	}
+	t := ScopedToken{UserID: userID, ProjectID: projectID, Name: name, Scopes: scopes}
+	err = pool.QueryRow(ctx, `
+INSERT INTO scoped_tokens (user_id, project_id, name, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, expires_at
+`, userID, projectID, name, scopes, time.Now().UTC().Add(ttl)).Scan(&t.ID, &t.CreatedAt, &t.ExpiresAt)
+	if err != nil {
+		return "", ScopedToken{}, err
+	}
+
+	claims := Claims{
+		TokenVersion: tv,
+		Scope:        strings.Join(scopes, " "),
+		ProjectID:    projectID.String(),
+	}
+	claims.Subject = userID.String()
+	claims.ID = t.ID.String()
+	token, err := SignJWT(secret, claims, ttl)
+	if err != nil {
+		return "", ScopedToken{}, err
+	}
+	return token, t, nil
+}
+
+// ListScopedTokens returns a project's scoped tokens, newest first (including revoked/expired ones).
+func ListScopedTokens(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) ([]ScopedToken, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, user_id, project_id, name, scopes, created_at, expires_at, revoked_at, last_used_at
+FROM scoped_tokens
+WHERE project_id = $1
+ORDER BY created_at DESC
+`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ScopedToken
+	for rows.Next() {
+		var t ScopedToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.ProjectID, &t.Name, &t.Scopes, &t.CreatedAt, &t.ExpiresAt, &t.RevokedAt, &t.LastUsedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// RevokeScopedToken revokes a project's scoped token. Revoking twice is not an error.
+func RevokeScopedToken(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, tokenID uuid.UUID) error {
+	ct, err := pool.Exec(ctx, `
+UPDATE scoped_tokens SET revoked_at = COALESCE(revoked_at, now())
+WHERE id = $1 AND project_id = $2
+`, tokenID, projectID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrScopedTokenNotFound
+	}
+	return nil
+}
+
+// RequireScope authenticates routes that also accept scoped tokens. Regular user tokens are handled
+// exactly like RequireAuth. Scoped tokens must carry every scope in scopes, must not be revoked, and
+// when the route has an :id parameter it must match the project the token is bound to.
+func RequireScope(jwtSecret string, pool *pgxpool.Pool, scopes ...string) fiber.Handler {
+	userAuth := RequireAuth(jwtSecret, pool)
+	return func(c *fiber.Ctx) error {
+		h := strings.TrimSpace(c.Get("Authorization"))
+		if len(h) < len("bearer ") || !strings.EqualFold(h[:len("bearer ")], "bearer ") {
+			return userAuth(c)
+		}
+		claims, err := ParseJWT(jwtSecret, strings.TrimSpace(h[len("bearer "):]))
+		if err != nil || claims.Scope == "" {
+			return userAuth(c)
+		}
+
+		granted := strings.Fields(claims.Scope)
+		for _, want := range scopes {
+			if !containsString(granted, want) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "insufficient_scope",
+				})
+			}
+		}
+		if id := c.Params("id"); id != "" && id != claims.ProjectID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "insufficient_scope",
+			})
+		}
+
+		if pool != nil {
+			tokenID, err := uuid.Parse(claims.ID)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "invalid_token",
+				})
+			}
+			active, err := scopedTokenActive(c.Context(), pool, tokenID, claims.TokenVersion)
+			if err != nil {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+					"error": "session_check_failed",
+				})
+			}
+			if !active {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "token_revoked",
+				})
+			}
+		}
+
+		c.Locals(LocalUserID, claims.Subject)
+		c.Locals(LocalScopes, granted)
+		return c.Next()
+	}
+}
+
+// scopedTokenActive checks the token row and the owner's token_version, and touches last_used_at.
+func scopedTokenActive(ctx context.Context, pool *pgxpool.Pool, tokenID uuid.UUID, tokenVersion int) (bool, error) {
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+UPDATE scoped_tokens st SET last_used_at = now()
+FROM users u
+WHERE st.id = $1
+  AND u.id = st.user_id
+  AND u.token_version = $2
+  AND st.revoked_at IS NULL
+  AND st.expires_at > now()
+RETURNING st.id
+`, tokenID, tokenVersion).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRequireScope(t *testing.T) {
+	projectID := uuid.New()
+	claims := Claims{Scope: ScopeProjectsRead, ProjectID: projectID.String()}
+	claims.Subject = uuid.NewString()
+	claims.ID = uuid.NewString()
+	token, err := SignJWT("secret", claims, time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/read/:id", RequireScope("secret", nil, ScopeProjectsRead), ok)
+	app.Get("/sync/:id", RequireScope("secret", nil, ScopeSyncRead), ok)
+	app.Get("/private", RequireAuth("secret", nil), ok)
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/read/" + projectID.String(), fiber.StatusOK},
+		{"/read/" + uuid.NewString(), fiber.StatusForbidden},
+		{"/sync/" + projectID.String(), fiber.StatusForbidden},
+		{"/private", fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.path, resp.StatusCode, tc.want)
+		}
+	}
+}
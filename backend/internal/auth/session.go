@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Session is a server-side record for an issued JWT. The session ID is used as the token's jti.
+type Session struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+var ErrSessionNotFound = errors.New("session_not_found")
+
+func CreateSession(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, userAgent string, ip string, ttl time.Duration) (Session, error) {
+	if pool == nil {
+		return Session{}, fmt.Errorf("db not configured")
+	}
+	s := Session{
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        ip,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	err := pool.QueryRow(ctx, `
+INSERT INTO auth_sessions (user_id, user_agent, ip, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at
+`, userID, nullIfEmpty(userAgent), nullIfEmpty(ip), s.ExpiresAt).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return Session{}, err
+	}
+	return s, nil
+}
+
+// SessionActive reports whether the session exists, is not revoked and has not expired.
+func SessionActive(ctx context.Context, pool *pgxpool.Pool, sessionID uuid.UUID) (bool, error) {
+	if pool == nil {
+		return false, fmt.Errorf("db not configured")
+	}
+	var active bool
+	err := pool.QueryRow(ctx, `
+SELECT EXISTS(
+  SELECT 1 FROM auth_sessions
+  WHERE id = $1 AND revoked_at IS NULL AND expires_at > now()
+)
+`, sessionID).Scan(&active)
+	return active, err
+}
+
+// ListActiveSessions returns the user's non-revoked, non-expired sessions, newest first.
+func ListActiveSessions(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]Session, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip, ''), created_at, expires_at, revoked_at
+FROM auth_sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+ORDER BY created_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Session
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetSession loads a single session by ID (revoked or not).
+func GetSession(ctx context.Context, pool *pgxpool.Pool, sessionID uuid.UUID) (Session, error) {
+	if pool == nil {
+		return Session{}, fmt.Errorf("db not configured")
+	}
+	var s Session
+	err := pool.QueryRow(ctx, `
+SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip, ''), created_at, expires_at, revoked_at
+FROM auth_sessions
+WHERE id = $1
+`, sessionID).Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Session{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	return s, nil
+}
+
+// RevokeSession marks a session as revoked. Revoking an already-revoked session is a no-op.
+func RevokeSession(ctx context.Context, pool *pgxpool.Pool, sessionID uuid.UUID) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	_, err := pool.Exec(ctx, `
+UPDATE auth_sessions SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+`, sessionID)
+	return err
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUserNotFound = errors.New("user_not_found")
+
+// TokenVersion returns the user's current token_version, stamped into new tokens as "tv".
+func TokenVersion(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	var v int
+	err := pool.QueryRow(ctx, `SELECT token_version FROM users WHERE id = $1`, userID).Scan(&v)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrUserNotFound
+	}
+	return v, err
+}
+
+// BumpTokenVersion invalidates every outstanding token for the user and revokes their sessions.
+// Returns the new version.
+func BumpTokenVersion(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (int, error) {
+	if pool == nil {
+		return 0, fmt.Errorf("db not configured")
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var v int
+	err = tx.QueryRow(ctx, `
+UPDATE users SET token_version = token_version + 1, updated_at = now()
+WHERE id = $1
+RETURNING token_version
+`, userID).Scan(&v)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE auth_sessions SET revoked_at = now()
+WHERE user_id = $1 AND revoked_at IS NULL
+`, userID); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// tokenState reports whether the token's version is current and, when sessionID is set,
+// whether its session is still active. A missing user yields ErrUserNotFound.
+func tokenState(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, tokenVersion int, sessionID *uuid.UUID) (versionOK bool, sessionOK bool, err error) {
+	err = pool.QueryRow(ctx, `
+SELECT u.token_version = $2,
+       ($3::uuid IS NULL OR EXISTS(
+         SELECT 1 FROM auth_sessions s
+         WHERE s.id = $3 AND s.user_id = u.id AND s.revoked_at IS NULL AND s.expires_at > now()
+       ))
+FROM users u
+WHERE u.id = $1
+`, userID, tokenVersion, sessionID).Scan(&versionOK, &sessionOK)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, ErrUserNotFound
+	}
+	return versionOK, sessionOK, err
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+var (
+	ErrTOTPNotEnrolled = errors.New("totp_not_enrolled")
+	ErrTOTPInvalidCode = errors.New("invalid_totp_code")
+)
+
+// TOTPEnabled reports whether the user completed TOTP enrollment.
+func TOTPEnabled(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (bool, error) {
+	var enabled bool
+	err := pool.QueryRow(ctx, `
+SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = $1 AND enabled_at IS NOT NULL)
+`, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// StartTOTPEnrollment stores a fresh (not yet enabled) encrypted secret for the user and returns it.
+// Re-enrolling replaces any pending secret but never an already-enabled one.
+func StartTOTPEnrollment(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, encKeyB64 string) (string, error) {
+	if pool == nil {
+		return "", fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(encKeyB64)
+	if err != nil {
+		return "", err
+	}
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	enc, err := cryptox.EncryptAESGCM(key, []byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	ct, err := pool.Exec(ctx, `
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+  secret = EXCLUDED.secret,
+  last_used_step = NULL,
+  created_at = now()
+WHERE user_totp.enabled_at IS NULL
+`, userID, enc)
+	if err != nil {
+		return "", err
+	}
+	if ct.RowsAffected() == 0 {
+		return "", fmt.Errorf("totp_already_enabled")
+	}
+	return secret, nil
+}
+
+// VerifyTOTP checks a code for the user, enabling a pending enrollment on first success.
+// Each time step can only be used once.
+func VerifyTOTP(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, encKeyB64 string, code string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	key, err := cryptox.KeyFromB64(encKeyB64)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var enc []byte
+	var lastStep *int64
+	err = tx.QueryRow(ctx, `
+SELECT secret, last_used_step FROM user_totp WHERE user_id = $1 FOR UPDATE
+`, userID).Scan(&enc, &lastStep)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTOTPNotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+	secret, err := cryptox.DecryptAESGCM(key, enc)
+	if err != nil {
+		return fmt.Errorf("decrypt totp secret failed")
+	}
+
+	step, ok := ValidateTOTP(string(secret), code, time.Now())
+	if !ok || (lastStep != nil && step <= *lastStep) {
+		return ErrTOTPInvalidCode
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE user_totp
+SET last_used_step = $2, enabled_at = COALESCE(enabled_at, now())
+WHERE user_id = $1
+`, userID, step); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DisableTOTP removes the user's TOTP enrollment.
+func DisableTOTP(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	return err
+}
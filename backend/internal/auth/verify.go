@@ -20,11 +20,12 @@ const (
 	WalletTypeEVM              WalletType = "evm"
 	WalletTypeStellarEd25519   WalletType = "stellar_ed25519"
 	WalletTypeStellarSecp256k1 WalletType = "stellar_secp256k1"
+	WalletTypeSolana           WalletType = "solana"
 )
 
 func NormalizeWalletType(v string) (WalletType, error) {
 	switch WalletType(strings.ToLower(strings.TrimSpace(v))) {
-	case WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
+	case WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1, WalletTypeSolana:
 		return WalletType(strings.ToLower(strings.TrimSpace(v))), nil
 	default:
 		return "", fmt.Errorf("unsupported wallet_type")
@@ -50,6 +51,8 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 	case WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
 		// For now we treat `address` as an opaque identifier (often public key hex or account-hash).
 		return strings.ToLower(a), nil
+	case WalletTypeSolana:
+		return normalizeSolanaAddress(a)
 	default:
 		return "", fmt.Errorf("unsupported wallet_type")
 	}
@@ -58,12 +61,27 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 // VerifySignature verifies a wallet signature against our canonical login message.
 //
 // Inputs:
-// - signatureHex: hex string (0x prefix optional)
-// - publicKeyHex: required for Stellar; ignored for EVM
+// - signatureHex: hex string (0x prefix optional); base58 is also accepted for Solana
+// - publicKeyHex: required for Stellar; ignored for EVM and Solana (the address is the key)
 func VerifySignature(t WalletType, address string, message string, signatureHex string, publicKeyHex string) error {
+	return VerifySignatureWithScheme(t, "", address, message, signatureHex, publicKeyHex)
+}
+
+// VerifySignatureWithScheme is VerifySignature with an explicit EVM scheme (eip191 or eip712).
+// scheme is ignored for non-EVM wallet types.
+func VerifySignatureWithScheme(t WalletType, scheme string, address string, message string, signatureHex string, publicKeyHex string) error {
 	switch t {
 	case WalletTypeEVM:
-		return verifyEVM(address, message, signatureHex)
+		switch strings.ToLower(strings.TrimSpace(scheme)) {
+		case "", SignatureSchemeEIP191:
+			return verifyEVM(address, message, signatureHex)
+		case SignatureSchemeEIP712:
+			return verifyEVMTypedData(address, message, signatureHex)
+		default:
+			return fmt.Errorf("unsupported signature_type")
+		}
+	case WalletTypeSolana:
+		return verifySolana(address, message, signatureHex)
 	case WalletTypeStellarEd25519:
 		return verifyStellarEd25519(message, signatureHex, publicKeyHex)
 	case WalletTypeStellarSecp256k1:
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+)
+
+// normalizeSolanaAddress validates a base58 ed25519 public key. Base58 is case-sensitive, so the
+// address is kept verbatim (unlike the hex-based wallet types).
+func normalizeSolanaAddress(addr string) (string, error) {
+	pk, err := decodeBase58(addr)
+	if err != nil || len(pk) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid solana address")
+	}
+	return addr, nil
+}
+
+// verifySolana checks an ed25519 signature (as produced by Phantom's signMessage) over the raw
+// UTF-8 message. The public key is the address itself; the signature may be base58 or hex.
+func verifySolana(address string, message string, signature string) error {
+	pk, err := decodeBase58(address)
+	if err != nil || len(pk) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid solana address")
+	}
+
+	sig := strings.TrimSpace(signature)
+	var sigBytes []byte
+	if b, err := decodeHex(sig); err == nil && len(b) == ed25519.SignatureSize {
+		sigBytes = b
+	} else if b, err := decodeBase58(sig); err == nil && len(b) == ed25519.SignatureSize {
+		sigBytes = b
+	} else {
+		return fmt.Errorf("invalid signature")
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(pk), []byte(message), sigBytes) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
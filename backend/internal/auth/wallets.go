@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrWalletLinkedElsewhere = errors.New("wallet_linked_to_other_user")
+	ErrWalletNotFound        = errors.New("wallet_not_found")
+	ErrLastLoginMethod       = errors.New("last_login_method")
+)
+
+type LinkedWallet struct {
+	Wallet
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConsumeNonceAndLinkWallet consumes a wallet nonce and attaches the wallet to an existing user.
+// Linking a wallet the user already owns is a no-op; wallets owned by another user are rejected.
+func ConsumeNonceAndLinkWallet(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, walletType WalletType, address string, nonce string, publicKey string) (Wallet, error) {
+	if pool == nil {
+		return Wallet{}, fmt.Errorf("db not configured")
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Wallet{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var nonceID uuid.UUID
+	err = tx.QueryRow(ctx, `
+SELECT id
+FROM auth_nonces
+WHERE wallet_type = $1
+  AND address = $2
+  AND nonce = $3
+  AND used_at IS NULL
+  AND expires_at > now()
+FOR UPDATE
+`, string(walletType), address, nonce).Scan(&nonceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Wallet{}, fmt.Errorf("invalid_or_expired_nonce")
+	}
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE auth_nonces SET used_at = now() WHERE id = $1`, nonceID); err != nil {
+		return Wallet{}, err
+	}
+
+	var ownerID uuid.UUID
+	err = tx.QueryRow(ctx, `
+SELECT user_id FROM wallets WHERE wallet_type = $1 AND address = $2
+`, string(walletType), address).Scan(&ownerID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		_, err = tx.Exec(ctx, `
+INSERT INTO wallets (user_id, wallet_type, address, public_key)
+VALUES ($1, $2, $3, $4)
+`, userID, string(walletType), address, nullIfEmpty(publicKey))
+		if err != nil {
+			return Wallet{}, err
+		}
+	case err != nil:
+		return Wallet{}, err
+	case ownerID != userID:
+		return Wallet{}, ErrWalletLinkedElsewhere
+	default:
+		if publicKey != "" {
+			_, _ = tx.Exec(ctx, `
+UPDATE wallets
+SET public_key = COALESCE(public_key, $3)
+WHERE wallet_type = $1 AND address = $2
+`, string(walletType), address, publicKey)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Wallet{}, err
+	}
+	return Wallet{WalletType: walletType, Address: address, PublicKey: publicKey}, nil
+}
+
+func ListWallets(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) ([]LinkedWallet, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("db not configured")
+	}
+	rows, err := pool.Query(ctx, `
+SELECT wallet_type, address, COALESCE(public_key, ''), created_at
+FROM wallets
+WHERE user_id = $1
+ORDER BY created_at ASC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []LinkedWallet
+	for rows.Next() {
+		var w LinkedWallet
+		var wType string
+		if err := rows.Scan(&wType, &w.Address, &w.PublicKey, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		w.WalletType = WalletType(wType)
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// UnlinkWallet removes a wallet from the user. walletType may be empty to match any type.
+// The user's last wallet cannot be removed unless another login method (GitHub, OAuth identity) is linked.
+func UnlinkWallet(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, walletType WalletType, address string) error {
+	if pool == nil {
+		return fmt.Errorf("db not configured")
+	}
+	address = strings.TrimSpace(address)
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var walletCount int
+	var hasOtherLogin bool
+	err = tx.QueryRow(ctx, `
+SELECT
+  (SELECT COUNT(*) FROM wallets WHERE user_id = $1),
+  EXISTS(SELECT 1 FROM github_accounts WHERE user_id = $1)
+    OR EXISTS(SELECT 1 FROM oauth_identities WHERE user_id = $1)
+`, userID).Scan(&walletCount, &hasOtherLogin)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+DELETE FROM wallets
+WHERE user_id = $1
+  AND (address = $2 OR (wallet_type <> 'solana' AND address = LOWER($2)))
+  AND ($3 = '' OR wallet_type = $3)
+`, userID, address, string(walletType))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWalletNotFound
+	}
+	if int64(walletCount) <= tag.RowsAffected() && !hasOtherLogin {
+		return ErrLastLoginMethod
+	}
+
+	return tx.Commit(ctx)
+}
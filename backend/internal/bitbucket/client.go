@@ -0,0 +1,98 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const apiBaseURL = "https://api.bitbucket.org/2.0/"
+
+type Client struct {
+	HTTP *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// APIError is returned for non-2xx Bitbucket API responses.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := strings.TrimSpace(e.Message)
+	if msg == "" {
+		msg = "bitbucket api error"
+	}
+	return fmt.Sprintf("%s: status %d", msg, e.StatusCode)
+}
+
+func parseAPIError(resp *http.Response) error {
+	b, _ := io.ReadAll(resp.Body)
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(b, &payload)
+	return &APIError{StatusCode: resp.StatusCode, Message: payload.Error.Message}
+}
+
+// do sends a request to the Bitbucket API. u is either a path relative to the 2.0 API or an
+// absolute "next" URL from a paginated response. in is JSON-encoded when non-nil; out is decoded
+// when non-nil.
+func (c *Client) do(ctx context.Context, method string, accessToken string, u string, in any, out any) error {
+	if !strings.HasPrefix(u, "https://") {
+		u = apiBaseURL + strings.TrimPrefix(u, "/")
+	} else if !strings.HasPrefix(u, apiBaseURL) {
+		// Pagination URLs come from the API; never send the token anywhere else.
+		return fmt.Errorf("invalid bitbucket api url")
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func splitFullName(fullName string) (workspace string, slug string, err error) {
+	parts := strings.Split(strings.TrimSpace(fullName), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repo full name")
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,92 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	authorizeURL = "https://bitbucket.org/site/oauth2/authorize"
+	tokenURL     = "https://bitbucket.org/site/oauth2/access_token"
+)
+
+// OAuthConfig identifies the Bitbucket OAuth consumer. Scopes (account, repository,
+// repository:admin, issue, webhook) are set on the consumer itself, not per request.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+func AuthorizeURL(clientID string, state string) (string, error) {
+	if clientID == "" {
+		return "", fmt.Errorf("bitbucket oauth not configured")
+	}
+	u, _ := url.Parse(authorizeURL)
+	q := u.Query()
+	q.Set("client_id", clientID)
+	q.Set("response_type", "code")
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scopes       string `json:"scopes"`
+}
+
+// ExpiresAt is when the access token expires (Bitbucket tokens last two hours).
+func (tr TokenResponse) ExpiresAt(now time.Time) time.Time {
+	return now.Add(time.Duration(tr.ExpiresIn) * time.Second)
+}
+
+func ExchangeCode(ctx context.Context, code string, cfg OAuthConfig) (TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	return postToken(ctx, form, cfg)
+}
+
+func RefreshAccessToken(ctx context.Context, refreshToken string, cfg OAuthConfig) (TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	return postToken(ctx, form, cfg)
+}
+
+func postToken(ctx context.Context, form url.Values, cfg OAuthConfig) (TokenResponse, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return TokenResponse{}, fmt.Errorf("bitbucket oauth not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	req.SetBasicAuth(cfg.ClientID, cfg.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return TokenResponse{}, parseAPIError(resp)
+	}
+	var tr TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return TokenResponse{}, err
+	}
+	if tr.AccessToken == "" {
+		return TokenResponse{}, fmt.Errorf("bitbucket token exchange returned empty token")
+	}
+	return tr, nil
+}
@@ -0,0 +1,184 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type User struct {
+	UUID        string `json:"uuid"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Links       struct {
+		Avatar struct {
+			Href string `json:"href"`
+		} `json:"avatar"`
+	} `json:"links"`
+}
+
+func (c *Client) GetCurrentUser(ctx context.Context, accessToken string) (User, error) {
+	var u User
+	if err := c.do(ctx, http.MethodGet, accessToken, "user", nil, &u); err != nil {
+		return User{}, err
+	}
+	if u.UUID == "" {
+		return User{}, fmt.Errorf("bitbucket user missing uuid")
+	}
+	return u, nil
+}
+
+type Repo struct {
+	UUID        string `json:"uuid"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	Language    string `json:"language"`
+	HasIssues   bool   `json:"has_issues"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func repoPath(fullName string) (string, error) {
+	workspace, slug, err := splitFullName(fullName)
+	if err != nil {
+		return "", err
+	}
+	return "repositories/" + url.PathEscape(workspace) + "/" + url.PathEscape(slug), nil
+}
+
+func (c *Client) GetRepo(ctx context.Context, accessToken string, fullName string) (Repo, error) {
+	p, err := repoPath(fullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	var r Repo
+	if err := c.do(ctx, http.MethodGet, accessToken, p, nil, &r); err != nil {
+		return Repo{}, err
+	}
+	if r.UUID == "" || r.FullName == "" {
+		return Repo{}, fmt.Errorf("invalid bitbucket repo response")
+	}
+	return r, nil
+}
+
+// RepoPermission returns the caller's permission on the repo: "admin", "write", "read" or "".
+func (c *Client) RepoPermission(ctx context.Context, accessToken string, fullName string) (string, error) {
+	q := url.Values{}
+	q.Set("q", fmt.Sprintf("repository.full_name=%q", fullName))
+	var page struct {
+		Values []struct {
+			Permission string `json:"permission"`
+		} `json:"values"`
+	}
+	if err := c.do(ctx, http.MethodGet, accessToken, "user/permissions/repositories?"+q.Encode(), nil, &page); err != nil {
+		return "", err
+	}
+	if len(page.Values) == 0 {
+		return "", nil
+	}
+	return page.Values[0].Permission, nil
+}
+
+type CreateWebhookRequest struct {
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+}
+
+type Webhook struct {
+	UUID string `json:"uuid"`
+	URL  string `json:"url"`
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, accessToken string, fullName string, req CreateWebhookRequest) (Webhook, error) {
+	p, err := repoPath(fullName)
+	if err != nil {
+		return Webhook{}, err
+	}
+	var wh Webhook
+	if err := c.do(ctx, http.MethodPost, accessToken, p+"/hooks", req, &wh); err != nil {
+		return Webhook{}, err
+	}
+	return wh, nil
+}
+
+// DeleteWebhook removes a repository webhook. A 404 (already gone) is treated as success.
+func (c *Client) DeleteWebhook(ctx context.Context, accessToken string, fullName string, hookUUID string) error {
+	p, err := repoPath(fullName)
+	if err != nil {
+		return err
+	}
+	if hookUUID == "" {
+		return fmt.Errorf("invalid webhook id")
+	}
+	err = c.do(ctx, http.MethodDelete, accessToken, p+"/hooks/"+url.PathEscape(hookUUID), nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+type Issue struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Reporter *struct {
+		Nickname string `json:"nickname"`
+	} `json:"reporter"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	CreatedOn string `json:"created_on"`
+	UpdatedOn string `json:"updated_on"`
+}
+
+// IsOpen maps Bitbucket's workflow states onto open/closed.
+func (i Issue) IsOpen() bool {
+	switch i.State {
+	case "new", "open", "on hold":
+		return true
+	}
+	return false
+}
+
+// ListIssuesPage lists issues, most recently updated first. Pass "" as cursor for the first page,
+// then the returned next until it is "". Repos with the issue tracker disabled return 404.
+func (c *Client) ListIssuesPage(ctx context.Context, accessToken string, fullName string, cursor string) ([]Issue, string, error) {
+	target := cursor
+	if target == "" {
+		p, err := repoPath(fullName)
+		if err != nil {
+			return nil, "", err
+		}
+		q := url.Values{}
+		q.Set("pagelen", strconv.Itoa(50))
+		q.Set("sort", "-updated_on")
+		target = p + "/issues?" + q.Encode()
+	}
+	var page struct {
+		Values []Issue `json:"values"`
+		Next   string  `json:"next"`
+	}
+	if err := c.do(ctx, http.MethodGet, accessToken, target, nil, &page); err != nil {
+		return nil, "", err
+	}
+	return page.Values, page.Next, nil
+}
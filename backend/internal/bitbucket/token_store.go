@@ -0,0 +1,117 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+var ErrNotLinked = errors.New("bitbucket_not_linked")
+
+type LinkedAccount struct {
+	UUID        string
+	Username    string
+	AccessToken string
+}
+
+// SaveAccount links (or re-links) the Bitbucket account for userID, storing both tokens encrypted.
+func SaveAccount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, u User, tr TokenResponse, tokenEncKeyB64 string) error {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+	encAccess, err := cryptox.EncryptAESGCM(key, []byte(tr.AccessToken))
+	if err != nil {
+		return err
+	}
+	encRefresh, err := cryptox.EncryptAESGCM(key, []byte(tr.RefreshToken))
+	if err != nil {
+		return err
+	}
+	_, err = pool.Exec(ctx, `
+INSERT INTO bitbucket_accounts (user_id, bitbucket_uuid, username, display_name, avatar_url, access_token, refresh_token, token_expires_at, scopes)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''))
+ON CONFLICT (user_id) DO UPDATE SET
+  bitbucket_uuid = EXCLUDED.bitbucket_uuid,
+  username = EXCLUDED.username,
+  display_name = EXCLUDED.display_name,
+  avatar_url = EXCLUDED.avatar_url,
+  access_token = EXCLUDED.access_token,
+  refresh_token = EXCLUDED.refresh_token,
+  token_expires_at = EXCLUDED.token_expires_at,
+  scopes = EXCLUDED.scopes,
+  updated_at = now()
+`, userID, u.UUID, u.Username, u.DisplayName, u.Links.Avatar.Href, encAccess, encRefresh, tr.ExpiresAt(time.Now().UTC()), tr.Scopes)
+	return err
+}
+
+// GetLinkedAccount returns the user's Bitbucket account with a usable access token, refreshing
+// it first when it expires within five minutes.
+func GetLinkedAccount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, tokenEncKeyB64 string, cfg OAuthConfig) (LinkedAccount, error) {
+	if pool == nil {
+		return LinkedAccount{}, fmt.Errorf("db not configured")
+	}
+	var acct LinkedAccount
+	var encAccess, encRefresh []byte
+	var expiresAt time.Time
+	err := pool.QueryRow(ctx, `
+SELECT bitbucket_uuid, username, access_token, refresh_token, token_expires_at
+FROM bitbucket_accounts
+WHERE user_id = $1
+`, userID).Scan(&acct.UUID, &acct.Username, &encAccess, &encRefresh, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LinkedAccount{}, ErrNotLinked
+	}
+	if err != nil {
+		return LinkedAccount{}, err
+	}
+
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return LinkedAccount{}, err
+	}
+	if time.Until(expiresAt) > 5*time.Minute {
+		b, err := cryptox.DecryptAESGCM(key, encAccess)
+		if err != nil {
+			return LinkedAccount{}, fmt.Errorf("decrypt bitbucket token failed")
+		}
+		acct.AccessToken = string(b)
+		return acct, nil
+	}
+
+	refresh, err := cryptox.DecryptAESGCM(key, encRefresh)
+	if err != nil {
+		return LinkedAccount{}, fmt.Errorf("decrypt bitbucket refresh token failed")
+	}
+	tr, err := RefreshAccessToken(ctx, string(refresh), cfg)
+	if err != nil {
+		return LinkedAccount{}, fmt.Errorf("bitbucket token refresh failed: %w", err)
+	}
+	if tr.RefreshToken == "" {
+		tr.RefreshToken = string(refresh)
+	}
+	newAccess, err := cryptox.EncryptAESGCM(key, []byte(tr.AccessToken))
+	if err != nil {
+		return LinkedAccount{}, err
+	}
+	newRefresh, err := cryptox.EncryptAESGCM(key, []byte(tr.RefreshToken))
+	if err != nil {
+		return LinkedAccount{}, err
+	}
+	if _, err := pool.Exec(ctx, `
+UPDATE bitbucket_accounts
+SET access_token = $2, refresh_token = $3, token_expires_at = $4, updated_at = now()
+WHERE user_id = $1
+`, userID, newAccess, newRefresh, tr.ExpiresAt(time.Now().UTC())); err != nil {
+		return LinkedAccount{}, err
+	}
+	acct.AccessToken = tr.AccessToken
+	return acct, nil
+}
@@ -0,0 +1,160 @@
+// Package bounties holds the rules of the bounty lifecycle: which status changes are allowed and
+// what a valid amount and asset look like. The handlers and tables live elsewhere.
+package bounties
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bounty statuses. A bounty reaches pending_payout only through a contributor's claim with a merged
+// pull request (see CanClaim); maintainers then approve the payout or send it back.
+const (
+	StatusOpen          = "open"
+	StatusClaimed       = "claimed"
+	StatusPendingPayout = "pending_payout"
+	StatusPaid          = "paid"
+	StatusCancelled     = "cancelled"
+)
+
+var transitions = map[string][]string{
+	StatusOpen:          {StatusClaimed, StatusCancelled},
+	StatusClaimed:       {StatusOpen, StatusPaid, StatusCancelled},
+	StatusPendingPayout: {StatusOpen, StatusClaimed, StatusPaid, StatusCancelled},
+}
+
+// ValidStatus reports whether s is a bounty status.
+func ValidStatus(s string) bool {
+	switch s {
+	case StatusOpen, StatusClaimed, StatusPendingPayout, StatusPaid, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanClaim reports whether a contributor may claim a bounty in status s with their pull request. A
+// claimed bounty can only be claimed by its claimant; the handler checks that.
+func CanClaim(s string) bool {
+	return s == StatusOpen || s == StatusClaimed
+}
+
+// Reasons CheckClaim rejects a claim.
+var (
+	ErrDeadlinePassed     = errors.New("the bounty's deadline has passed")
+	ErrMergedBeforeBounty = errors.New("the pull request was not merged after the bounty was created")
+)
+
+// DeadlinePassed reports whether a bounty with deadline (nil for none) can no longer be claimed at now.
+func DeadlinePassed(deadline *time.Time, now time.Time) bool {
+	return deadline != nil && !now.Before(*deadline)
+}
+
+// CheckClaim checks the timing of a claim made at now with a pull request merged at mergedAt (nil
+// when unknown): the bounty's deadline must not have passed, and the pull request must have been
+// merged after the bounty was created, so work that predates the bounty cannot collect it.
+func CheckClaim(deadline *time.Time, createdAt time.Time, mergedAt *time.Time, now time.Time) error {
+	if DeadlinePassed(deadline, now) {
+		return ErrDeadlinePassed
+	}
+	if mergedAt == nil || !mergedAt.After(createdAt) {
+		return ErrMergedBeforeBounty
+	}
+	return nil
+}
+
+// Payable reports whether a bounty in status s has a contributor to pay: it is claimed or its claim
+// awaits payout.
+func Payable(s string) bool {
+	return s == StatusClaimed || s == StatusPendingPayout
+}
+
+// CanTransition reports whether a bounty in status from may move to status to. Paid and cancelled
+// bounties are final. Moving to pending_payout is not a transition; it takes a claim.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next lists the statuses a bounty in status from may move to.
+func Next(from string) []string {
+	return append([]string{}, transitions[from]...)
+}
+
+var (
+	amountRe = regexp.MustCompile(`^[0-9]{1,31}(\.[0-9]{1,7})?$`)
+	assetRe  = regexp.MustCompile(`^[A-Z0-9]{1,12}$`)
+)
+
+// ErrInvalidAmount is returned by ParseAmount.
+var ErrInvalidAmount = errors.New("amount must be a positive decimal with at most 7 decimal places")
+
+// ParseAmount validates a decimal amount string (Stellar precision: 7 decimal places) and returns it
+// normalized, without leading zeros or trailing fractional zeros.
+func ParseAmount(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !amountRe.MatchString(s) {
+		return "", ErrInvalidAmount
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	whole = strings.TrimLeft(whole, "0")
+	frac = strings.TrimRight(frac, "0")
+	if whole == "" && frac == "" {
+		return "", ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if frac == "" {
+		return whole, nil
+	}
+	return whole + "." + frac, nil
+}
+
+// NormalizeAsset upper-cases an asset code ("XLM", "USDC") and reports whether it is valid: 1-12
+// letters or digits, like a Stellar asset code.
+func NormalizeAsset(s string) (string, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return s, assetRe.MatchString(s)
+}
+
+// Decimals is the precision of Stellar assets, and of the escrow contract's token amounts.
+const Decimals = 7
+
+// ToUnits converts a decimal amount to token base units (amount × 10^7) as an integer string, the
+// form of the escrow contract's i128 amounts.
+func ToUnits(amount string) (string, error) {
+	amount, err := ParseAmount(amount)
+	if err != nil {
+		return "", err
+	}
+	whole, frac, _ := strings.Cut(amount, ".")
+	units := strings.TrimLeft(whole+frac+strings.Repeat("0", Decimals-len(frac)), "0")
+	return units, nil
+}
+
+// issueRefRe matches an issue reference in a pull request's title or body: "#42", "owner/repo#42" or
+// "https://github.com/owner/repo/issues/42".
+var issueRefRe = regexp.MustCompile(`(?i)(?:https?://github\.com/([\w.-]+/[\w.-]+)/issues/|([\w.-]+/[\w.-]+)?#)([0-9]+)\b`)
+
+// References reports whether text references issue number of the repository fullName. A bare "#42"
+// refers to the pull request's own repository, which must be fullName.
+func References(text, fullName string, number int) bool {
+	want := strconv.Itoa(number)
+	for _, m := range issueRefRe.FindAllStringSubmatch(text, -1) {
+		repo := m[1]
+		if repo == "" {
+			repo = m[2]
+		}
+		if m[3] == want && (repo == "" || strings.EqualFold(repo, fullName)) {
+			return true
+		}
+	}
+	return false
+}
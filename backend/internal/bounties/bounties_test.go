@@ -0,0 +1,134 @@
+package bounties
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]string{
+		{StatusOpen, StatusClaimed},
+		{StatusOpen, StatusCancelled},
+		{StatusClaimed, StatusOpen},
+		{StatusClaimed, StatusPaid},
+		{StatusClaimed, StatusCancelled},
+		{StatusPendingPayout, StatusPaid},
+		{StatusPendingPayout, StatusOpen},
+	}
+	for _, tr := range allowed {
+		if !CanTransition(tr[0], tr[1]) {
+			t.Errorf("%s -> %s should be allowed", tr[0], tr[1])
+		}
+	}
+	denied := [][2]string{
+		{StatusOpen, StatusPaid},
+		{StatusOpen, StatusOpen},
+		{StatusPaid, StatusOpen},
+		{StatusPaid, StatusCancelled},
+		{StatusCancelled, StatusOpen},
+		{StatusOpen, StatusPendingPayout},
+		{StatusClaimed, StatusPendingPayout},
+		{"unknown", StatusOpen},
+	}
+	for _, tr := range denied {
+		if CanTransition(tr[0], tr[1]) {
+			t.Errorf("%s -> %s should be denied", tr[0], tr[1])
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	ok := map[string]string{
+		"250":          "250",
+		"250.50":       "250.5",
+		"0.0000001":    "0.0000001",
+		"007.1000000":  "7.1",
+		" 12.0 ":       "12",
+		"1000000000.5": "1000000000.5",
+	}
+	for in, want := range ok {
+		got, err := ParseAmount(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "0.0", "-5", "1.12345678", "1e5", "12.", ".5", "abc"} {
+		if got, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestNormalizeAsset(t *testing.T) {
+	if got, ok := NormalizeAsset(" usdc "); !ok || got != "USDC" {
+		t.Errorf("NormalizeAsset(usdc) = %q, %v", got, ok)
+	}
+	for _, in := range []string{"", "US-DC", "TOOLONGASSETCODE"} {
+		if _, ok := NormalizeAsset(in); ok {
+			t.Errorf("NormalizeAsset(%q) accepted", in)
+		}
+	}
+}
+
+func TestToUnits(t *testing.T) {
+	for in, want := range map[string]string{"250": "2500000000", "0.0000001": "1", "12.5": "125000000"} {
+		if got, err := ToUnits(in); err != nil || got != want {
+			t.Errorf("ToUnits(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	const repo = "Owner/Repo"
+	for _, text := range []string{
+		"Fixes #42",
+		"closes owner/repo#42.",
+		"See https://github.com/owner/repo/issues/42 for details",
+		"(#42)",
+	} {
+		if !References(text, repo, 42) {
+			t.Errorf("References(%q) = false", text)
+		}
+	}
+	for _, text := range []string{
+		"Fixes #420",
+		"Fixes other/repo#42",
+		"https://github.com/other/repo/issues/42",
+		"issue 42",
+		"",
+	} {
+		if References(text, repo, 42) {
+			t.Errorf("References(%q) = true", text)
+		}
+	}
+}
+
+func TestCheckClaim(t *testing.T) {
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := created.Add(10 * 24 * time.Hour)
+	at := func(d time.Duration) *time.Time {
+		t := created.Add(d)
+		return &t
+	}
+	cases := []struct {
+		name     string
+		deadline *time.Time
+		mergedAt *time.Time
+		want     error
+	}{
+		{"no deadline, merged after creation", nil, at(time.Hour), nil},
+		{"before deadline", at(30 * 24 * time.Hour), at(time.Hour), nil},
+		{"deadline passed", at(5 * 24 * time.Hour), at(time.Hour), ErrDeadlinePassed},
+		{"deadline is now", at(10 * 24 * time.Hour), at(time.Hour), ErrDeadlinePassed},
+		{"merged before the bounty", nil, at(-24 * time.Hour), ErrMergedBeforeBounty},
+		{"merged as the bounty was created", nil, at(0), ErrMergedBeforeBounty},
+		{"merge time unknown", nil, nil, ErrMergedBeforeBounty},
+		{"old pull request after the deadline", at(5 * 24 * time.Hour), at(-time.Hour), ErrDeadlinePassed},
+	}
+	for _, tc := range cases {
+		if err := CheckClaim(tc.deadline, created, tc.mergedAt, now); !errors.Is(err, tc.want) {
+			t.Errorf("%s: CheckClaim = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package config
 
 import (
 	"log/slog"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -16,6 +18,14 @@ type Config struct {
 	AutoMigrate bool
 
 	JWTSecret string
+	// iss/aud stamped on issued tokens and required by ParseJWT.
+	JWTIssuer   string
+	JWTAudience string
+	// Token lifetimes: wallet signature logins, and all other logins (GitHub, OAuth, email, 2FA step-up).
+	JWTWalletTTL time.Duration
+	JWTLoginTTL  time.Duration
+	// Per-role overrides, e.g. "admin=30m,maintainer=2h" (JWT_ROLE_TTLS). Applies to every login method.
+	JWTRoleTTLs map[string]time.Duration
 
 	NATSURL string
 
@@ -26,6 +36,20 @@ type Config struct {
 	GitHubLoginRedirectURL        string // Alternative callback URL (deprecated, use GitHubOAuthRedirectURL)
 	GitHubLoginSuccessRedirectURL string
 
+	// Additional OAuth login providers. Redirect URLs default to PUBLIC_BASE_URL + /auth/oauth/{provider}/callback.
+	GoogleOAuthClientID      string
+	GoogleOAuthClientSecret  string
+	GoogleOAuthRedirectURL   string
+	DiscordOAuthClientID     string
+	DiscordOAuthClientSecret string
+	DiscordOAuthRedirectURL  string
+
+	// Bitbucket Cloud repository host. The OAuth consumer needs account, repository:admin, issue and
+	// webhook scopes; its callback URL must be PUBLIC_BASE_URL + /auth/bitbucket/callback.
+	BitbucketOAuthClientID     string
+	BitbucketOAuthClientSecret string
+	BitbucketWebhookSecret     string
+
 	// GitHub App configuration (for organization installations)
 	GitHubAppID         string // GitHub App ID (numeric)
 	GitHubAppSlug       string // GitHub App slug (e.g., "grainlify")
@@ -33,6 +57,43 @@ type Config struct {
 
 	// Used to validate GitHub webhook signatures (X-Hub-Signature-256).
 	GitHubWebhookSecret string
+	// Repo webhooks get a per-project secret, rotated this often; the previous secret is still
+	// accepted for WebhookSecretGrace after a rotation.
+	WebhookSecretRotationInterval time.Duration
+	WebhookSecretGrace            time.Duration
+
+	// Sync issues/PRs via batched GraphQL queries (true) or REST pages (false).
+	GitHubSyncGraphQL bool
+	// How often stars/forks/description/archived state are re-fetched for verified projects.
+	RepoMetadataRefreshInterval time.Duration
+	// Issue/PR syncs are incremental (updated since the last run); a full pass runs at least this often.
+	GitHubFullSyncInterval time.Duration
+	// How often verified projects re-check that the owner still has admin or push access.
+	OwnershipReverifyInterval time.Duration
+	// How often the precomputed ecosystem detail aggregates (ecosystem_stats) are rebuilt.
+	EcosystemStatsInterval time.Duration
+	// How often the precomputed leaderboards (leaderboard_snapshots) are rebuilt.
+	LeaderboardRefreshInterval time.Duration
+	// Newly verified projects wait in pending_review until an admin approves them; false publishes
+	// them as soon as verification succeeds.
+	ProjectReviewRequired bool
+	// Email weekly/monthly contribution digests to users who set digest_frequency (needs SMTP).
+	DigestEmailsEnabled bool
+	// Failed sync jobs are retried with exponential backoff (base doubling per attempt, capped at
+	// max, with jitter) until they have run SyncJobMaxAttempts times, then marked dead.
+	SyncJobMaxAttempts    int
+	SyncJobRetryBaseDelay time.Duration
+	SyncJobRetryMaxDelay  time.Duration
+	// Number of sync jobs run in parallel (at most one per project at a time).
+	SyncWorkerConcurrency int
+	// A running job whose worker hasn't heartbeated for this long is presumed abandoned and requeued.
+	SyncJobVisibilityTimeout time.Duration
+	// How often idle sync workers poll for due jobs. Workers subscribed to sync.job.enqueued wake
+	// as soon as a job is queued, so with NATS this is only a fallback and can be raised.
+	SyncWorkerPollInterval time.Duration
+	// Cap on running jobs of a type across all workers, e.g. sync_commits:1 (SYNC_LIMITS). Types
+	// without an entry are only bound by the worker concurrency.
+	SyncJobLimits map[string]int
 
 	// Public base URL of this backend, used when registering GitHub webhooks.
 	PublicBaseURL string
@@ -45,17 +106,50 @@ type Config struct {
 	// Example: "http://localhost:5173,https://grainlify.figma.site"
 	CORSOrigins string
 
+	// Domain bound into signed wallet login messages (e.g. "app.grainlify.com").
+	// If empty, the host of FrontendBaseURL is used.
+	AuthDomain string
+
+	// Backend for auth rate-limit counters: "memory" (per instance, default) or "postgres" (shared).
+	AuthRateLimitStore string
+
 	// Used to encrypt stored OAuth access tokens at rest. Must be 32 bytes base64 (AES-256-GCM key).
 	TokenEncKeyB64 string
+	// Encrypts the personal data in stored KYC results (same format). Falls back to TokenEncKeyB64.
+	KYCEncKeyB64 string
+
+	// Ed25519 seed (32 bytes base64) that signs contribution attestations; empty disables them.
+	AttestationSigningKeyB64 string
+	// Comma-separated base64 public keys of rotated-out signing keys; their attestations still verify.
+	AttestationRetiredPublicKeys string
 
 	// Dev/admin convenience: allow promoting a logged-in user to admin via a shared token.
 	AdminBootstrapToken string
 
+	// Reject admin requests from admins who have not enrolled TOTP (enrolled admins always need the mfa claim).
+	AdminRequire2FA bool
+
+	// Bearer token Prometheus must present to scrape /metrics; empty disables the endpoint.
+	MetricsToken string
+
+	// Outgoing mail (magic-link login). If SMTPHost is empty, mail is logged instead of sent.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
 	// Didit KYC verification
 	DiditAPIKey        string
 	DiditWorkflowID    string
 	DiditWebhookSecret string
 
+	// Screening of verified KYC sessions: ISO 3166-1 alpha-3 country codes (as Didit reports them)
+	// whose nationals or documents are blocked, and an optional sanctions-list provider to query.
+	KYCBlockedCountries []string
+	KYCSanctionsURL     string
+	KYCSanctionsAPIKey  string
+
 	// Soroban configuration
 	SorobanRPCURL            string
 	SorobanNetworkPassphrase string
@@ -64,6 +158,47 @@ type Config struct {
 	EscrowContractID         string
 	ProgramEscrowContractID  string
 	TokenContractID          string
+	// EscrowAsset is the asset code of the escrow contract's token; only bounties in it can be escrowed.
+	EscrowAsset string
+
+	// Soroban event indexer (cmd/indexer). Contracts default to the escrow and program escrow contracts.
+	SorobanIndexerContracts    string
+	SorobanIndexerStartLedger  int
+	SorobanIndexerPollInterval time.Duration
+
+	// Bounty payouts, paid by the sync worker. PayoutSigner is "keypair" (PayoutSourceSecret) or
+	// "remote" (PayoutSignerURL, PayoutSignerToken, PayoutSourceAccount); empty turns payouts off.
+	PayoutSigner        string
+	PayoutSourceSecret  string
+	PayoutSignerURL     string
+	PayoutSignerToken   string
+	PayoutSourceAccount string
+	PayoutHorizonURL    string
+	PayoutAssetIssuers  string
+	PayoutMaxAttempts   int
+	PayoutTxTimeout     time.Duration
+	// PayoutVerifyAccount receives the memo-tagged deposits that verify payout addresses; cmd/indexer
+	// watches it on Horizon. Empty leaves signed messages as the only way to verify.
+	PayoutVerifyAccount string
+	// Payouts worth more than PayoutApprovalThresholdUSD (priced from the assets table) wait for
+	// PayoutApprovalQuorum admins to approve them before they are sent. A payout in an asset without a
+	// current price counts as above the threshold. Empty turns the check off.
+	PayoutApprovalThresholdUSD string
+	PayoutApprovalQuorum       int
+
+	// Reward programs are closed out (allocations computed) this long after their window ends, so
+	// contributions synced late still count.
+	ProgramCloseDelay time.Duration
+	// ProgramDepositAccounts (comma-separated) receive program funding: cmd/indexer credits a payment
+	// to one of them whose text memo is a program's deposit_memo to that program's budget.
+	ProgramDepositAccounts string
+
+	// Asset prices for showing bounty values in USD. AssetPriceSource is "coingecko" (coin ids from
+	// AssetPriceIDs, CODE:coin-id pairs) or a URL serving {"CODE": price}; empty turns prices off.
+	// Fetched prices are shown for AssetPriceMaxAge.
+	AssetPriceSource string
+	AssetPriceIDs    string
+	AssetPriceMaxAge time.Duration
 }
 
 func Load() Config {
@@ -85,7 +220,12 @@ func Load() Config {
 		DBURL:       getEnv("DB_URL", ""),
 		AutoMigrate: getEnvBool("AUTO_MIGRATE", false),
 
-		JWTSecret: getEnv("JWT_SECRET", ""),
+		JWTSecret:    getEnv("JWT_SECRET", ""),
+		JWTIssuer:    getEnv("JWT_ISSUER", "grainlify"),
+		JWTAudience:  getEnv("JWT_AUDIENCE", "grainlify-api"),
+		JWTWalletTTL: getEnvDuration("JWT_WALLET_TTL", 15*time.Minute),
+		JWTLoginTTL:  getEnvDuration("JWT_LOGIN_TTL", 60*time.Minute),
+		JWTRoleTTLs:  parseRoleTTLs(getEnv("JWT_ROLE_TTLS", "")),
 
 		NATSURL: getEnv("NATS_URL", ""),
 
@@ -96,25 +236,76 @@ func Load() Config {
 		GitHubLoginRedirectURL:        getEnv("GITHUB_LOGIN_REDIRECT_URL", ""),
 		GitHubLoginSuccessRedirectURL: getEnv("GITHUB_LOGIN_SUCCESS_REDIRECT_URL", ""),
 
+		GoogleOAuthClientID:      getEnv("GOOGLE_OAUTH_CLIENT_ID", ""),
+		GoogleOAuthClientSecret:  getEnv("GOOGLE_OAUTH_CLIENT_SECRET", ""),
+		GoogleOAuthRedirectURL:   getEnv("GOOGLE_OAUTH_REDIRECT_URL", ""),
+		DiscordOAuthClientID:     getEnv("DISCORD_OAUTH_CLIENT_ID", ""),
+		DiscordOAuthClientSecret: getEnv("DISCORD_OAUTH_CLIENT_SECRET", ""),
+		DiscordOAuthRedirectURL:  getEnv("DISCORD_OAUTH_REDIRECT_URL", ""),
+
+		BitbucketOAuthClientID:     getEnv("BITBUCKET_OAUTH_CLIENT_ID", ""),
+		BitbucketOAuthClientSecret: getEnv("BITBUCKET_OAUTH_CLIENT_SECRET", ""),
+		BitbucketWebhookSecret:     getEnv("BITBUCKET_WEBHOOK_SECRET", ""),
+
 		GitHubAppID:         getEnv("GITHUB_APP_ID", ""),
 		GitHubAppSlug:       getEnv("GITHUB_APP_SLUG", ""),
 		GitHubAppPrivateKey: getEnv("GITHUB_APP_PRIVATE_KEY", ""),
 
 		GitHubWebhookSecret: getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		GitHubSyncGraphQL:   getEnvBool("GITHUB_SYNC_GRAPHQL", true),
+
+		RepoMetadataRefreshInterval: getEnvDuration("REPO_METADATA_REFRESH_INTERVAL", 24*time.Hour),
+		GitHubFullSyncInterval:      getEnvDuration("GITHUB_FULL_SYNC_INTERVAL", 7*24*time.Hour),
+		OwnershipReverifyInterval:   getEnvDuration("OWNERSHIP_REVERIFY_INTERVAL", 24*time.Hour),
+		EcosystemStatsInterval:      getEnvDuration("ECOSYSTEM_STATS_INTERVAL", 1*time.Hour),
+		LeaderboardRefreshInterval:  getEnvDuration("LEADERBOARD_REFRESH_INTERVAL", 1*time.Hour),
+		ProjectReviewRequired:       getEnvBool("PROJECT_REVIEW_REQUIRED", true),
+		DigestEmailsEnabled:         getEnvBool("DIGEST_EMAILS_ENABLED", false),
+
+		SyncJobMaxAttempts:       getEnvInt("SYNC_JOB_MAX_ATTEMPTS", 5),
+		SyncJobRetryBaseDelay:    getEnvDuration("SYNC_JOB_RETRY_BASE_DELAY", 30*time.Second),
+		SyncJobRetryMaxDelay:     getEnvDuration("SYNC_JOB_RETRY_MAX_DELAY", 1*time.Hour),
+		SyncWorkerConcurrency:    getEnvInt("SYNC_WORKER_CONCURRENCY", 4),
+		SyncJobVisibilityTimeout: getEnvDuration("SYNC_JOB_VISIBILITY_TIMEOUT", 10*time.Minute),
+		SyncWorkerPollInterval:   getEnvDuration("SYNC_WORKER_POLL_INTERVAL", 1*time.Second),
+		SyncJobLimits:            parseSyncLimits(getEnv("SYNC_LIMITS", "")),
+
+		WebhookSecretRotationInterval: getEnvDuration("WEBHOOK_SECRET_ROTATION_INTERVAL", 90*24*time.Hour),
+		WebhookSecretGrace:            getEnvDuration("WEBHOOK_SECRET_GRACE", 24*time.Hour),
 
 		PublicBaseURL: getEnv("PUBLIC_BASE_URL", ""),
 
 		FrontendBaseURL: getEnv("FRONTEND_BASE_URL", ""),
 		CORSOrigins:     getEnv("CORS_ORIGINS", ""),
+		AuthDomain:      getEnv("AUTH_DOMAIN", ""),
+
+		AuthRateLimitStore: getEnv("AUTH_RATE_LIMIT_STORE", "memory"),
 
 		TokenEncKeyB64: getEnv("TOKEN_ENC_KEY_B64", ""),
+		KYCEncKeyB64:   getEnv("KYC_ENC_KEY_B64", ""),
+
+		AttestationSigningKeyB64:     getEnv("ATTESTATION_SIGNING_KEY_B64", ""),
+		AttestationRetiredPublicKeys: getEnv("ATTESTATION_RETIRED_PUBLIC_KEYS", ""),
 
 		AdminBootstrapToken: strings.TrimSpace(getEnv("ADMIN_BOOTSTRAP_TOKEN", "")),
+		AdminRequire2FA:     getEnvBool("ADMIN_REQUIRE_2FA", false),
+
+		MetricsToken: strings.TrimSpace(getEnv("METRICS_TOKEN", "")),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "Grainlify <no-reply@grainlify.com>"),
 
 		DiditAPIKey:        getEnv("DIDIT_API_KEY", ""),
 		DiditWorkflowID:    getEnv("DIDIT_WORKFLOW_ID", ""),
 		DiditWebhookSecret: getEnv("DIDIT_WEBHOOK_SECRET", ""),
 
+		KYCBlockedCountries: parseCountryList(getEnv("KYC_BLOCKED_COUNTRIES", "")),
+		KYCSanctionsURL:     getEnv("KYC_SANCTIONS_URL", ""),
+		KYCSanctionsAPIKey:  getEnv("KYC_SANCTIONS_API_KEY", ""),
+
 		// Soroban configuration
 		SorobanRPCURL:            getEnv("SOROBAN_RPC_URL", ""),
 		SorobanNetworkPassphrase: getEnv("SOROBAN_NETWORK_PASSPHRASE", ""),
@@ -123,6 +314,32 @@ func Load() Config {
 		EscrowContractID:         getEnv("ESCROW_CONTRACT_ID", ""),
 		ProgramEscrowContractID:  getEnv("PROGRAM_ESCROW_CONTRACT_ID", ""),
 		TokenContractID:          getEnv("TOKEN_CONTRACT_ID", ""),
+		EscrowAsset:              strings.ToUpper(getEnv("ESCROW_ASSET", "")),
+
+		SorobanIndexerContracts:    getEnv("SOROBAN_INDEXER_CONTRACTS", ""),
+		SorobanIndexerStartLedger:  getEnvInt("SOROBAN_INDEXER_START_LEDGER", 0),
+		SorobanIndexerPollInterval: getEnvDuration("SOROBAN_INDEXER_POLL_INTERVAL", 5*time.Second),
+
+		PayoutSigner:        getEnv("PAYOUT_SIGNER", ""),
+		PayoutSourceSecret:  getEnv("PAYOUT_SOURCE_SECRET", ""),
+		PayoutSignerURL:     getEnv("PAYOUT_SIGNER_URL", ""),
+		PayoutSignerToken:   getEnv("PAYOUT_SIGNER_TOKEN", ""),
+		PayoutSourceAccount: getEnv("PAYOUT_SOURCE_ACCOUNT", ""),
+		PayoutHorizonURL:    getEnv("PAYOUT_HORIZON_URL", ""),
+		PayoutAssetIssuers:  getEnv("PAYOUT_ASSET_ISSUERS", ""),
+		PayoutMaxAttempts:   getEnvInt("PAYOUT_MAX_ATTEMPTS", 5),
+		PayoutTxTimeout:     getEnvDuration("PAYOUT_TX_TIMEOUT", 5*time.Minute),
+		PayoutVerifyAccount: strings.TrimSpace(getEnv("PAYOUT_VERIFY_ACCOUNT", "")),
+
+		PayoutApprovalThresholdUSD: strings.TrimSpace(getEnv("PAYOUT_APPROVAL_THRESHOLD_USD", "")),
+		PayoutApprovalQuorum:       getEnvInt("PAYOUT_APPROVAL_QUORUM", 2),
+
+		ProgramCloseDelay:      getEnvDuration("PROGRAM_CLOSE_DELAY", 24*time.Hour),
+		ProgramDepositAccounts: getEnv("PROGRAM_DEPOSIT_ACCOUNTS", ""),
+
+		AssetPriceSource: strings.TrimSpace(getEnv("ASSET_PRICE_SOURCE", "")),
+		AssetPriceIDs:    getEnv("ASSET_PRICE_IDS", "XLM:stellar,USDC:usd-coin"),
+		AssetPriceMaxAge: getEnvDuration("ASSET_PRICE_MAX_AGE", time.Hour),
 	}
 }
 
@@ -145,6 +362,71 @@ func (c Config) LogLevel() slog.Leveler {
 	}
 }
 
+// TokenTTL returns the JWT lifetime for role, falling back to the login method's default.
+func (c Config) TokenTTL(role string, methodDefault time.Duration) time.Duration {
+	if ttl, ok := c.JWTRoleTTLs[role]; ok {
+		return ttl
+	}
+	return methodDefault
+}
+
+// KYCKeyB64 returns the key KYC personal data is encrypted with, or "" when none is configured.
+func (c Config) KYCKeyB64() string {
+	if c.KYCEncKeyB64 != "" {
+		return c.KYCEncKeyB64
+	}
+	return c.TokenEncKeyB64
+}
+
+// IndexedContractIDs returns the contracts the Soroban indexer watches: SOROBAN_INDEXER_CONTRACTS
+// (comma-separated), else the configured escrow contracts.
+func (c Config) IndexedContractIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(c.SorobanIndexerContracts, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		return ids
+	}
+	for _, id := range []string{c.EscrowContractID, c.ProgramEscrowContractID} {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ProgramDepositAccountList returns the accounts in PROGRAM_DEPOSIT_ACCOUNTS.
+func (c Config) ProgramDepositAccountList() []string {
+	var out []string
+	for _, a := range strings.Split(c.ProgramDepositAccounts, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// SignInDomain returns the domain and URI that wallet login messages must be bound to.
+func (c Config) SignInDomain() (domain string, uri string) {
+	uri = strings.TrimRight(strings.TrimSpace(c.FrontendBaseURL), "/")
+	domain = strings.TrimSpace(c.AuthDomain)
+	if domain == "" && uri != "" {
+		if u, err := url.Parse(uri); err == nil {
+			domain = u.Host
+		}
+	}
+	if domain == "" {
+		domain = "localhost"
+	}
+	if uri == "" {
+		uri = "https://" + domain
+	}
+	return domain, uri
+}
+
 func getEnv(key, fallback string) string {
 	v := os.Getenv(key)
 	if strings.TrimSpace(v) == "" {
@@ -167,3 +449,78 @@ func getEnvBool(key string, fallback bool) bool {
 		return fallback
 	}
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("invalid duration in env, using default", "key", key, "value", v, "default", fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		slog.Warn("invalid integer in env, using default", "key", key, "value", v, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+// parseRoleTTLs parses "role=duration,role=duration". Invalid entries are skipped with a warning.
+func parseRoleTTLs(v string) map[string]time.Duration {
+	out := map[string]time.Duration{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		role, ds, ok := strings.Cut(part, "=")
+		d, err := time.ParseDuration(strings.TrimSpace(ds))
+		if !ok || err != nil || d <= 0 || strings.TrimSpace(role) == "" {
+			slog.Warn("invalid JWT_ROLE_TTLS entry, skipping", "entry", part)
+			continue
+		}
+		out[strings.TrimSpace(role)] = d
+	}
+	return out
+}
+
+// parseSyncLimits parses "job_type:n,job_type:n". Invalid entries are skipped with a warning.
+func parseSyncLimits(v string) map[string]int {
+	out := map[string]int{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		jobType, ns, ok := strings.Cut(part, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(ns))
+		if !ok || err != nil || n <= 0 || strings.TrimSpace(jobType) == "" {
+			slog.Warn("invalid SYNC_LIMITS entry, skipping", "entry", part)
+			continue
+		}
+		out[strings.TrimSpace(jobType)] = n
+	}
+	return out
+}
+
+// parseCountryList parses KYC_BLOCKED_COUNTRIES: comma-separated country codes, upper-cased.
+func parseCountryList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if code := strings.ToUpper(strings.TrimSpace(part)); code != "" {
+			out = append(out, code)
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+// Package contributions maintains the contributions table, a denormalized row per issue, PR and
+// default-branch commit with a known author. github_issues, github_pull_requests and github_commits
+// remain the source of truth; profile and contribution queries read this table instead.
+package contributions
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SyncProject copies the project's issues, PRs and commits upserted since since (by last_seen_at)
+// into contributions. Rows without an author or a date are skipped; a row whose author or date
+// changed is updated in place.
+func SyncProject(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, since time.Time) error {
+	if _, err := pool.Exec(ctx, `
+INSERT INTO contributions (kind, source_id, project_id, author_login, occurred_at)
+SELECT 'issue', id, project_id, author_login, created_at_github
+FROM github_issues
+WHERE project_id = $1 AND last_seen_at >= $2
+  AND author_login IS NOT NULL AND author_login <> '' AND created_at_github IS NOT NULL
+ON CONFLICT (kind, source_id) WHERE source_id IS NOT NULL DO UPDATE SET
+  author_login = EXCLUDED.author_login,
+  occurred_at = EXCLUDED.occurred_at
+`, projectID, since); err != nil {
+		return err
+	}
+	if _, err := pool.Exec(ctx, `
+INSERT INTO contributions (kind, source_id, project_id, author_login, occurred_at)
+SELECT 'pull_request', id, project_id, author_login, created_at_github
+FROM github_pull_requests
+WHERE project_id = $1 AND last_seen_at >= $2
+  AND author_login IS NOT NULL AND author_login <> '' AND created_at_github IS NOT NULL
+ON CONFLICT (kind, source_id) WHERE source_id IS NOT NULL DO UPDATE SET
+  author_login = EXCLUDED.author_login,
+  occurred_at = EXCLUDED.occurred_at
+`, projectID, since); err != nil {
+		return err
+	}
+	_, err := pool.Exec(ctx, `
+INSERT INTO contributions (kind, sha, project_id, author_login, occurred_at)
+SELECT 'commit', sha, project_id, author_login, committed_at
+FROM github_commits
+WHERE project_id = $1 AND last_seen_at >= $2
+  AND author_login IS NOT NULL AND author_login <> '' AND committed_at IS NOT NULL
+ON CONFLICT (project_id, sha) WHERE sha IS NOT NULL DO UPDATE SET
+  author_login = EXCLUDED.author_login,
+  occurred_at = EXCLUDED.occurred_at
+`, projectID, since)
+	return err
+}
+
+// BackfillLogin attributes a newly linked GitHub account's past contributions. Commits recorded
+// without an author login (the push or API had no linked GitHub user) are claimed when their author
+// email is the account's GitHub noreply address, then every issue, PR and commit by the login is
+// copied into contributions. Returns how many commits were attributed.
+func BackfillLogin(ctx context.Context, pool *pgxpool.Pool, githubUserID int64, login string) (int64, error) {
+	ct, err := pool.Exec(ctx, `
+UPDATE github_commits
+SET author_login = $2
+WHERE (author_login IS NULL OR author_login = '')
+  AND LOWER(author_email) IN (
+    LOWER($2) || '@users.noreply.github.com',
+    $1::text || '+' || LOWER($2) || '@users.noreply.github.com'
+  )
+`, githubUserID, login)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := pool.Exec(ctx, `
+INSERT INTO contributions (kind, source_id, project_id, author_login, occurred_at)
+SELECT 'issue', id, project_id, author_login, created_at_github
+FROM github_issues
+WHERE author_login = $1 AND created_at_github IS NOT NULL
+UNION ALL
+SELECT 'pull_request', id, project_id, author_login, created_at_github
+FROM github_pull_requests
+WHERE author_login = $1 AND created_at_github IS NOT NULL
+ON CONFLICT (kind, source_id) WHERE source_id IS NOT NULL DO NOTHING
+`, login); err != nil {
+		return 0, err
+	}
+	_, err = pool.Exec(ctx, `
+INSERT INTO contributions (kind, sha, project_id, author_login, occurred_at)
+SELECT 'commit', sha, project_id, author_login, committed_at
+FROM github_commits
+WHERE author_login = $1 AND committed_at IS NOT NULL
+ON CONFLICT (project_id, sha) WHERE sha IS NOT NULL DO UPDATE SET
+  author_login = EXCLUDED.author_login
+`, login)
+	return ct.RowsAffected(), err
+}
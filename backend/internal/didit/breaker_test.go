@@ -0,0 +1,40 @@
+package didit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(2, 50*time.Millisecond)
+
+	b.record(false)
+	if !b.allow() {
+		t.Fatal("one failure should not open the breaker")
+	}
+	b.record(false)
+	if b.allow() {
+		t.Fatal("breaker should be open after threshold failures")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("a trial call should be allowed after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("only one trial call at a time")
+	}
+	b.record(false)
+	if b.allow() {
+		t.Fatal("failed trial should reopen the breaker")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("trial after second cooldown")
+	}
+	b.record(true)
+	if !b.allow() || !b.allow() {
+		t.Fatal("successful trial should close the breaker")
+	}
+}
@@ -4,25 +4,97 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"time"
 )
 
 const BaseURL = "https://verification.didit.me/v2"
 
+// ErrUnavailable is returned without calling Didit while the circuit breaker is open, i.e. after
+// repeated failures; callers should fall back to stored state.
+var ErrUnavailable = errors.New("didit temporarily disabled after repeated failures")
+
 type Client struct {
 	HTTP      *http.Client
 	APIKey    string
 	UserAgent string
+	// CallTimeout bounds each HTTP attempt. Zero means no per-call limit beyond HTTP.Timeout.
+	CallTimeout time.Duration
+	// MaxRetries is how many times an idempotent request (GET, DELETE) is retried after a timeout,
+	// network error, 429 or 5xx, with exponential backoff.
+	MaxRetries int
+
+	breaker *breaker
 }
 
 func NewClient(apiKey string) *Client {
 	return &Client{
-		HTTP:      &http.Client{Timeout: 30 * time.Second},
-		APIKey:    apiKey,
-		UserAgent: "patchwork-backend",
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+		APIKey:      apiKey,
+		UserAgent:   "patchwork-backend",
+		CallTimeout: 5 * time.Second,
+		MaxRetries:  2,
+		breaker:     newBreaker(5, 30*time.Second),
+	}
+}
+
+// retryBaseDelay is the backoff before the first retry; it doubles per attempt, with jitter.
+const retryBaseDelay = 200 * time.Millisecond
+
+// send performs one HTTP attempt under CallTimeout and the circuit breaker. transient reports
+// whether the failure (network error, timeout, 429 or 5xx) is worth retrying and counts against
+// the provider's health; 4xx answers such as 404 are Didit working as intended.
+func (c *Client) send(ctx context.Context, newReq func(context.Context) (*http.Request, error)) (status int, body []byte, transient bool, err error) {
+	if !c.breaker.allow() {
+		return 0, nil, false, ErrUnavailable
+	}
+	if c.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.CallTimeout)
+		defer cancel()
+	}
+	req, err := newReq(ctx)
+	if err != nil {
+		return 0, nil, false, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		c.breaker.record(false)
+		return 0, nil, true, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		c.breaker.record(false)
+		return 0, nil, true, fmt.Errorf("read response body: %w", err)
+	}
+	transient = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	c.breaker.record(!transient)
+	return resp.StatusCode, body, transient, nil
+}
+
+// sendWithRetry is send for idempotent requests: transient failures are retried up to MaxRetries
+// times. The last attempt's outcome is returned.
+func (c *Client) sendWithRetry(ctx context.Context, newReq func(context.Context) (*http.Request, error)) (status int, body []byte, err error) {
+	for attempt := 0; ; attempt++ {
+		var transient bool
+		status, body, transient, err = c.send(ctx, newReq)
+		if !transient || attempt >= c.MaxRetries || ctx.Err() != nil {
+			return status, body, err
+		}
+		d := retryBaseDelay << attempt
+		d += time.Duration(rand.Int64N(int64(d)))
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return status, body, err
+		case <-t.C:
+		}
 	}
 }
 
@@ -48,30 +120,25 @@ func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (C
 		return CreateSessionResponse{}, fmt.Errorf("marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return CreateSessionResponse{}, fmt.Errorf("create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
-	httpReq.Header.Set("x-api-key", c.APIKey)
-	if c.UserAgent != "" {
-		httpReq.Header.Set("User-Agent", c.UserAgent)
-	}
-
-	resp, err := c.HTTP.Do(httpReq)
-	if err != nil {
-		return CreateSessionResponse{}, fmt.Errorf("http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read the full response body for error details
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// Sessions are not idempotent: a create is never retried.
+	status, bodyBytes, _, err := c.send(ctx, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("x-api-key", c.APIKey)
+		if c.UserAgent != "" {
+			httpReq.Header.Set("User-Agent", c.UserAgent)
+		}
+		return httpReq, nil
+	})
 	if err != nil {
-		return CreateSessionResponse{}, fmt.Errorf("read response body: %w", err)
+		return CreateSessionResponse{}, err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if status < 200 || status >= 300 {
 		// Try to parse error response
 		var errBody struct {
 			Error   string `json:"error"`
@@ -95,7 +162,7 @@ func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (C
 			errMsg = "unknown error"
 		}
 		
-		return CreateSessionResponse{}, fmt.Errorf("didit create session failed: status %d, error: %s, body: %s", resp.StatusCode, errMsg, string(bodyBytes))
+		return CreateSessionResponse{}, fmt.Errorf("didit create session failed: status %d, error: %s, body: %s", status, errMsg, string(bodyBytes))
 	}
 
 	var result CreateSessionResponse
@@ -120,34 +187,28 @@ type SessionDecisionResponse struct {
 func (c *Client) GetSessionDecision(ctx context.Context, sessionID string) (SessionDecisionResponse, error) {
 	url := fmt.Sprintf("%s/session/%s/decision/", BaseURL, sessionID)
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return SessionDecisionResponse{}, fmt.Errorf("create request: %w", err)
-	}
-	httpReq.Header.Set("Accept", "application/json")
-	httpReq.Header.Set("x-api-key", c.APIKey)
-	if c.UserAgent != "" {
-		httpReq.Header.Set("User-Agent", c.UserAgent)
-	}
-
-	resp, err := c.HTTP.Do(httpReq)
-	if err != nil {
-		return SessionDecisionResponse{}, fmt.Errorf("http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read the raw response body for debugging
-	bodyBytes, err := io.ReadAll(resp.Body)
+	status, bodyBytes, err := c.sendWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("x-api-key", c.APIKey)
+		if c.UserAgent != "" {
+			httpReq.Header.Set("User-Agent", c.UserAgent)
+		}
+		return httpReq, nil
+	})
 	if err != nil {
-		return SessionDecisionResponse{}, fmt.Errorf("read response body: %w", err)
+		return SessionDecisionResponse{}, err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if status < 200 || status >= 300 {
 		var errBody struct {
 			Error string `json:"error"`
 		}
 		_ = json.Unmarshal(bodyBytes, &errBody)
-		return SessionDecisionResponse{}, fmt.Errorf("didit get decision failed: status %d, error: %s, body: %s", resp.StatusCode, errBody.Error, string(bodyBytes))
+		return SessionDecisionResponse{}, fmt.Errorf("didit get decision failed: status %d, error: %s, body: %s", status, errBody.Error, string(bodyBytes))
 	}
 
 	// First, unmarshal into a generic map to capture all fields
@@ -182,3 +243,36 @@ func (c *Client) GetSessionDecision(ctx context.Context, sessionID string) (Sess
 	return result, nil
 }
 
+
+// DeleteSession deletes a verification session at Didit, cancelling it if the user has not finished.
+// A session Didit no longer has counts as deleted.
+func (c *Client) DeleteSession(ctx context.Context, sessionID string) error {
+	url := fmt.Sprintf("%s/session/%s/delete/", BaseURL, sessionID)
+
+	status, bodyBytes, err := c.sendWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("x-api-key", c.APIKey)
+		if c.UserAgent != "" {
+			httpReq.Header.Set("User-Agent", c.UserAgent)
+		}
+		return httpReq, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return nil
+	}
+	if status < 200 || status >= 300 {
+		var errBody struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(bodyBytes, &errBody)
+		return fmt.Errorf("didit delete session failed: status %d, error: %s, body: %s", status, errBody.Error, string(bodyBytes))
+	}
+	return nil
+}
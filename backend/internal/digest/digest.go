@@ -0,0 +1,275 @@
+// Package digest builds per-user contribution summaries for a week or a month: contributions made,
+// the change in leaderboard rank since the previous digest, and the projects newly listed and
+// bounties newly opened in the ecosystems the user contributes to. GET /profile/digest serves them on demand; the sync worker
+// emails them to users who opted in with users.digest_frequency.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Periods a digest can cover.
+const (
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+)
+
+// newProjectsLimit and newBountiesLimit cap the projects and bounties listed in one digest.
+const (
+	newProjectsLimit = 10
+	newBountiesLimit = 10
+)
+
+// Digest is one user's summary of a period.
+type Digest struct {
+	Period        string    `json:"period"`
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `json:"period_end"`
+	Login         string    `json:"login"`
+	Contributions Counts    `json:"contributions"`
+	Rank          Rank      `json:"rank"`
+	NewProjects   []Project `json:"new_projects"`
+	NewBounties   []Bounty  `json:"new_bounties"`
+}
+
+// Counts are the contributions made during the period. Total counts issues and PRs, like
+// contributions_count on the profile.
+type Counts struct {
+	Total              int `json:"total"`
+	Issues             int `json:"issues"`
+	PullRequests       int `json:"pull_requests"`
+	MergedPullRequests int `json:"merged_pull_requests"`
+	Commits            int `json:"commits"`
+}
+
+// Rank is the user's position on the all-time global leaderboard. Previous is the rank recorded
+// with the user's previous digest of the same period; Change is positive when the user moved up.
+type Rank struct {
+	Current  *int `json:"current"`
+	Previous *int `json:"previous"`
+	Change   *int `json:"change"`
+}
+
+// Project is a project listed during the period.
+type Project struct {
+	ID             string  `json:"id"`
+	GitHubFullName string  `json:"github_full_name"`
+	EcosystemName  string  `json:"ecosystem_name"`
+	Language       *string `json:"language,omitempty"`
+}
+
+// Bounty is a bounty opened during the period that is still open.
+type Bounty struct {
+	ID             string `json:"id"`
+	GitHubFullName string `json:"github_full_name"`
+	IssueNumber    int    `json:"issue_number"`
+	IssueTitle     string `json:"issue_title"`
+	Amount         string `json:"amount"`
+	Asset          string `json:"asset"`
+}
+
+// ValidPeriod reports whether p is a digest period.
+func ValidPeriod(p string) bool {
+	return p == PeriodWeekly || p == PeriodMonthly
+}
+
+// Window returns the last complete period before now, in UTC: the previous Monday-to-Monday week,
+// or the previous calendar month. end is exclusive.
+func Window(period string, now time.Time) (start, end time.Time) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if period == PeriodMonthly {
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -1, 0), end
+	}
+	// time.Weekday counts from Sunday; weeks here start on Monday.
+	end = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return end.AddDate(0, 0, -7), end
+}
+
+// Build computes the digest of login for the last complete period before now.
+func Build(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, login, period string, now time.Time) (Digest, error) {
+	start, end := Window(period, now)
+	d := Digest{Period: period, PeriodStart: start, PeriodEnd: end, Login: login, NewProjects: []Project{}, NewBounties: []Bounty{}}
+
+	err := pool.QueryRow(ctx, `
+SELECT COUNT(*) FILTER (WHERE c.kind = 'issue'),
+       COUNT(*) FILTER (WHERE c.kind = 'pull_request'),
+       COUNT(*) FILTER (WHERE c.kind = 'commit'),
+       (SELECT COUNT(*) FROM github_pull_requests pr
+        JOIN projects mp ON mp.id = pr.project_id
+        WHERE pr.author_login = $1 AND pr.merged IS TRUE
+          AND pr.merged_at_github >= $2 AND pr.merged_at_github < $3
+          AND mp.status IN ('verified', 'archived'))
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+WHERE c.author_login = $1 AND c.occurred_at >= $2 AND c.occurred_at < $3
+  AND p.status IN ('verified', 'archived')
+`, login, start, end).Scan(&d.Contributions.Issues, &d.Contributions.PullRequests, &d.Contributions.Commits, &d.Contributions.MergedPullRequests)
+	if err != nil {
+		return d, err
+	}
+	d.Contributions.Total = d.Contributions.Issues + d.Contributions.PullRequests
+
+	err = pool.QueryRow(ctx, `
+SELECT rank FROM leaderboard_snapshots
+WHERE period = 'all' AND ecosystem_id IS NULL AND LOWER(login) = LOWER($1)
+`, login).Scan(&d.Rank.Current)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return d, err
+	}
+	err = pool.QueryRow(ctx, `
+SELECT global_rank FROM user_digests
+WHERE user_id = $1 AND period = $2 AND period_start < $3
+ORDER BY period_start DESC
+LIMIT 1
+`, userID, period, start).Scan(&d.Rank.Previous)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return d, err
+	}
+	if d.Rank.Current != nil && d.Rank.Previous != nil {
+		change := *d.Rank.Previous - *d.Rank.Current
+		d.Rank.Change = &change
+	}
+
+	rows, err := pool.Query(ctx, `
+SELECT p.id, p.github_full_name, e.name, p.language
+FROM projects p
+JOIN ecosystems e ON e.id = p.ecosystem_id
+WHERE p.status = 'verified' AND p.deleted_at IS NULL
+  AND p.approved_at >= $2 AND p.approved_at < $3
+  AND p.ecosystem_id IN (
+    SELECT DISTINCT cp.ecosystem_id
+    FROM contributions c
+    JOIN projects cp ON cp.id = c.project_id
+    WHERE c.author_login = $1 AND cp.ecosystem_id IS NOT NULL
+  )
+ORDER BY p.approved_at DESC
+LIMIT $4
+`, login, start, end, newProjectsLimit)
+	if err != nil {
+		return d, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p Project
+		var id uuid.UUID
+		if err := rows.Scan(&id, &p.GitHubFullName, &p.EcosystemName, &p.Language); err != nil {
+			return d, err
+		}
+		p.ID = id.String()
+		d.NewProjects = append(d.NewProjects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return d, err
+	}
+
+	rows, err = pool.Query(ctx, `
+SELECT b.id, p.github_full_name, gi.number, COALESCE(gi.title, ''), b.amount::text, b.asset
+FROM bounties b
+JOIN projects p ON p.id = b.project_id
+JOIN github_issues gi ON gi.id = b.issue_id
+WHERE b.status = 'open' AND (b.deadline IS NULL OR b.deadline > $4)
+  AND b.created_at >= $2 AND b.created_at < $3
+  AND p.status = 'verified' AND p.deleted_at IS NULL
+  AND p.ecosystem_id IN (
+    SELECT DISTINCT cp.ecosystem_id
+    FROM contributions c
+    JOIN projects cp ON cp.id = c.project_id
+    WHERE c.author_login = $1 AND cp.ecosystem_id IS NOT NULL
+  )
+ORDER BY b.created_at DESC
+LIMIT $5
+`, login, start, end, now, newBountiesLimit)
+	if err != nil {
+		return d, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b Bounty
+		var id uuid.UUID
+		if err := rows.Scan(&id, &b.GitHubFullName, &b.IssueNumber, &b.IssueTitle, &b.Amount, &b.Asset); err != nil {
+			return d, err
+		}
+		b.ID = id.String()
+		if amount, err := bounties.ParseAmount(b.Amount); err == nil {
+			b.Amount = amount
+		}
+		d.NewBounties = append(d.NewBounties, b)
+	}
+	return d, rows.Err()
+}
+
+// Empty reports whether the digest has nothing worth emailing.
+func (d Digest) Empty() bool {
+	c := d.Contributions
+	return c.Total == 0 && c.Commits == 0 && len(d.NewProjects) == 0 && len(d.NewBounties) == 0 && (d.Rank.Change == nil || *d.Rank.Change == 0)
+}
+
+// Text renders the digest as a plain-text email. link is the frontend base URL.
+func (d Digest) Text(link string) (subject, body string) {
+	label := "week"
+	if d.Period == PeriodMonthly {
+		label = "month"
+	}
+	last := d.PeriodEnd.AddDate(0, 0, -1)
+	subject = fmt.Sprintf("Your Grainlify %s: %s - %s", label, d.PeriodStart.Format("Jan 2"), last.Format("Jan 2"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s, here is your %s on Grainlify.\n\n", d.Login, label)
+	c := d.Contributions
+	fmt.Fprintf(&b, "Contributions: %d (%d issues, %d pull requests, %d merged), plus %d commits.\n",
+		c.Total, c.Issues, c.PullRequests, c.MergedPullRequests, c.Commits)
+	switch {
+	case d.Rank.Current == nil:
+		b.WriteString("You are not on the leaderboard yet.\n")
+	case d.Rank.Change == nil || *d.Rank.Change == 0:
+		fmt.Fprintf(&b, "Leaderboard rank: #%d.\n", *d.Rank.Current)
+	case *d.Rank.Change > 0:
+		fmt.Fprintf(&b, "Leaderboard rank: #%d, up %d since your last digest.\n", *d.Rank.Current, *d.Rank.Change)
+	default:
+		fmt.Fprintf(&b, "Leaderboard rank: #%d, down %d since your last digest.\n", *d.Rank.Current, -*d.Rank.Change)
+	}
+	if len(d.NewProjects) > 0 {
+		b.WriteString("\nNew projects in your ecosystems:\n")
+		for _, p := range d.NewProjects {
+			fmt.Fprintf(&b, "- %s (%s)\n", p.GitHubFullName, p.EcosystemName)
+		}
+	}
+	if len(d.NewBounties) > 0 {
+		b.WriteString("\nNew bounties in your ecosystems:\n")
+		for _, bounty := range d.NewBounties {
+			fmt.Fprintf(&b, "- %s %s: %s#%d %s\n", bounty.Amount, bounty.Asset, bounty.GitHubFullName, bounty.IssueNumber, bounty.IssueTitle)
+		}
+	}
+	if link != "" {
+		b.WriteString("\n" + strings.TrimRight(link, "/") + "\n")
+	}
+	b.WriteString("\nYou get this email because digests are on in your profile settings.\n")
+	return subject, b.String()
+}
+
+// Store records d for userID. It returns false when a digest of the same period was already
+// stored, which is how concurrent workers avoid sending it twice.
+func Store(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, d Digest) (id uuid.UUID, stored bool, err error) {
+	err = pool.QueryRow(ctx, `
+INSERT INTO user_digests (user_id, period, period_start, period_end, global_rank, body)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, period, period_start) DO NOTHING
+RETURNING id
+`, userID, d.Period, d.PeriodStart, d.PeriodEnd, d.Rank.Current, d).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, false, nil
+	}
+	return id, err == nil, err
+}
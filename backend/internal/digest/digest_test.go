@@ -0,0 +1,71 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	cases := []struct {
+		period     string
+		now        string
+		start, end string
+	}{
+		// Wednesday: the previous Monday-to-Monday week.
+		{PeriodWeekly, "2026-03-11T15:00:00Z", "2026-03-02T00:00:00Z", "2026-03-09T00:00:00Z"},
+		// Monday: the week that just ended.
+		{PeriodWeekly, "2026-03-09T00:30:00Z", "2026-03-02T00:00:00Z", "2026-03-09T00:00:00Z"},
+		// Sunday belongs to the week that started the Monday before.
+		{PeriodWeekly, "2026-03-15T23:00:00Z", "2026-03-02T00:00:00Z", "2026-03-09T00:00:00Z"},
+		{PeriodMonthly, "2026-03-11T15:00:00Z", "2026-02-01T00:00:00Z", "2026-03-01T00:00:00Z"},
+		{PeriodMonthly, "2026-01-01T00:00:00Z", "2025-12-01T00:00:00Z", "2026-01-01T00:00:00Z"},
+		// Evaluated in UTC.
+		{PeriodMonthly, "2026-03-31T22:00:00-05:00", "2026-03-01T00:00:00Z", "2026-04-01T00:00:00Z"},
+	}
+	for _, tc := range cases {
+		now, _ := time.Parse(time.RFC3339, tc.now)
+		start, end := Window(tc.period, now)
+		if got := start.Format(time.RFC3339); got != tc.start {
+			t.Errorf("Window(%s, %s) start = %s, want %s", tc.period, tc.now, got, tc.start)
+		}
+		if got := end.Format(time.RFC3339); got != tc.end {
+			t.Errorf("Window(%s, %s) end = %s, want %s", tc.period, tc.now, got, tc.end)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	current, previous, change := 12, 20, 8
+	d := Digest{
+		Period:        PeriodWeekly,
+		PeriodStart:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:     time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		Login:         "alice",
+		Contributions: Counts{Total: 5, Issues: 2, PullRequests: 3, MergedPullRequests: 2, Commits: 9},
+		Rank:          Rank{Current: &current, Previous: &previous, Change: &change},
+		NewProjects:   []Project{{GitHubFullName: "acme/widget", EcosystemName: "Starknet"}},
+		NewBounties:   []Bounty{{GitHubFullName: "acme/widget", IssueNumber: 42, IssueTitle: "Add dark mode", Amount: "250.5", Asset: "USDC"}},
+	}
+	subject, body := d.Text("https://grainlify.example/")
+	if subject != "Your Grainlify week: Mar 2 - Mar 8" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"Contributions: 5 (2 issues, 3 pull requests, 2 merged), plus 9 commits.",
+		"Leaderboard rank: #12, up 8 since your last digest.",
+		"- acme/widget (Starknet)",
+		"- 250.5 USDC: acme/widget#42 Add dark mode",
+		"https://grainlify.example\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	if d.Empty() {
+		t.Error("digest with contributions reported empty")
+	}
+	if !(Digest{}).Empty() {
+		t.Error("zero digest not empty")
+	}
+}
@@ -4,21 +4,48 @@ import "encoding/json"
 
 const (
 	SubjectGitHubWebhookReceived = "github.webhook.received"
+	SubjectSyncJobEnqueued       = "sync.job.enqueued"
+	SubjectProgramDeposit        = "program.deposit.received"
+	SubjectBountyCreated         = "bounty.created"
+	SubjectBountyClaimed         = "bounty.claimed"
+	SubjectBountyPaid            = "bounty.paid"
+	// SubjectBountyAll matches every bounty subject.
+	SubjectBountyAll = "bounty.*"
 )
 
-type GitHubWebhookReceived struct {
-	DeliveryID   string          `json:"delivery_id"`
-	Event        string          `json:"event"`
-	Action       string          `json:"action,omitempty"`
-	RepoFullName string          `json:"repo_full_name,omitempty"`
-	Payload      json.RawMessage `json:"payload"`
+// SyncJobEnqueued tells sync workers that jobs were queued, so they claim them now instead of at
+// the next poll. It carries no job state: workers still claim from sync_jobs.
+type SyncJobEnqueued struct {
+	ProjectID string   `json:"project_id"`
+	JobTypes  []string `json:"job_types"`
 }
 
+// BountyChanged reports that a bounty was created, claimed with a pull request or paid. Workers
+// mirror its status onto the bounty's GitHub issue.
+type BountyChanged struct {
+	BountyID string `json:"bounty_id"`
+	Status   string `json:"status"`
+}
 
+// ProgramDeposit reports a payment with a program's deposit memo to a watched account. Credited
+// deposits were added to the program's budget (now Budget); Reason says why others were not.
+type ProgramDeposit struct {
+	ProgramID string `json:"program_id"`
+	DepositID string `json:"deposit_id"`
+	TxHash    string `json:"tx_hash"`
+	From      string `json:"from"`
+	Amount    string `json:"amount"`
+	Asset     string `json:"asset"`
+	Credited  bool   `json:"credited"`
+	Reason    string `json:"reason,omitempty"`
+	Budget    string `json:"budget,omitempty"`
+}
 
-
-
-
-
-
-
+type GitHubWebhookReceived struct {
+	DeliveryID     string          `json:"delivery_id"`
+	Event          string          `json:"event"`
+	Action         string          `json:"action,omitempty"`
+	RepoFullName   string          `json:"repo_full_name,omitempty"`
+	HookTargetType string          `json:"hook_target_type,omitempty"` // repository, organization or integration
+	Payload        json.RawMessage `json:"payload"`
+}
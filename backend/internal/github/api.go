@@ -3,14 +3,23 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
 type Client struct {
 	HTTP      *http.Client
 	UserAgent string
+	// Cache enables conditional requests for list endpoints; nil disables caching.
+	Cache HTTPCache
+	// Budget, when set, receives every observed quota so other processes share it.
+	Budget *RateBudget
+
+	rlMu       sync.Mutex
+	rateLimits map[string]RateLimit // by token fingerprint + resource
 }
 
 func NewClient() *Client {
@@ -20,6 +29,22 @@ func NewClient() *Client {
 	}
 }
 
+// StatusError is returned for non-2xx GitHub API responses.
+type StatusError struct {
+	Op   string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: status %d", e.Op, e.Code)
+}
+
+// IsUnauthorized reports whether err is a GitHub 401, i.e. the token is revoked or expired.
+func IsUnauthorized(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.Code == http.StatusUnauthorized
+}
+
 type User struct {
 	ID        int64  `json:"id"`
 	Login     string `json:"login"`
@@ -29,6 +54,8 @@ type User struct {
 	Location  string `json:"location"`
 	Bio       string `json:"bio"`
 	Blog      string `json:"blog"` // Website URL
+	Company   string `json:"company"`
+	HTMLURL   string `json:"html_url"`
 }
 
 type Email struct {
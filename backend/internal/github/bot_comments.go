@@ -0,0 +1,32 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PostIssueComment posts body on an issue or PR as the GitHub App installation, so the comment is
+// authored by the Grainlify bot rather than a user. A cached installation token that GitHub rejects
+// is dropped and the post retried once with a fresh one.
+func (c *Client) PostIssueComment(ctx context.Context, apps *InstallationTokenSource, installationID string, fullName string, number int, body string) (IssueComment, error) {
+	if strings.TrimSpace(installationID) == "" {
+		return IssueComment{}, fmt.Errorf("project has no github app installation")
+	}
+	token, err := apps.Token(ctx, installationID)
+	if err != nil {
+		return IssueComment{}, err
+	}
+	comment, err := c.CreateIssueComment(ctx, token, fullName, number, body)
+	var apiErr *GitHubAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		apps.Forget(installationID)
+		if token, err = apps.Token(ctx, installationID); err != nil {
+			return IssueComment{}, err
+		}
+		comment, err = c.CreateIssueComment(ctx, token, fullName, number, body)
+	}
+	return comment, err
+}
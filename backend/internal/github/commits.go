@@ -0,0 +1,71 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type CommitListItem struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	// Author is the GitHub account matched to the commit email; nil when GitHub can't match it.
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ListCommitsPage lists commits on the default branch, newest first, one page per call (cursor as in
+// ListIssuesPage). When since is non-zero only commits after it are returned. An empty repository
+// yields no items rather than GitHub's 409.
+func (c *Client) ListCommitsPage(ctx context.Context, accessToken string, fullName string, since time.Time, cursor string) (items []CommitListItem, next string, notModified bool, err error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, "", false, err
+	}
+	u, _ := url.Parse("https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/commits")
+	q := u.Query()
+	q.Set("per_page", "100")
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	u.RawQuery = q.Encode()
+	target, err := pageURL(cursor, u)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.getConditional(req, "github list commits failed")
+	if err != nil {
+		var se *StatusError
+		if errors.As(err, &se) && se.Code == http.StatusConflict {
+			return nil, "", false, nil
+		}
+		return nil, "", false, err
+	}
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, "", false, err
+	}
+	return items, NextPageURL(resp.Link), resp.NotModified, nil
+}
@@ -0,0 +1,363 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const graphqlURL = "https://api.github.com/graphql"
+
+// GraphQL page sizes. Issues/PRs are fetched 50 at a time with their first 100 comments / 50
+// reviews nested, which replaces one REST list page plus one comments request per issue.
+const (
+	graphqlPageSize     = 50
+	graphqlCommentsSize = 100
+	graphqlReviewsSize  = 50
+)
+
+type graphqlError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// GraphQL runs a query against the GitHub GraphQL API and decodes the data field into out.
+func (c *Client) GraphQL(ctx context.Context, accessToken string, query string, variables map[string]any, out any) error {
+	b, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphqlURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := c.observeRateLimit(req, resp); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Op: "github graphql failed", Code: resp.StatusCode}
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		msgs := make([]string, 0, len(envelope.Errors))
+		for _, e := range envelope.Errors {
+			if e.Type == "RATE_LIMITED" {
+				reset := time.Now().Add(time.Minute)
+				if rl, ok := c.RateLimit(accessToken, "graphql"); ok && rl.Reset.After(time.Now()) {
+					reset = rl.Reset
+				}
+				return &RateLimitError{Reset: reset}
+			}
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("github graphql errors: %s", strings.Join(msgs, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+type gqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type gqlActor struct {
+	Login string `json:"login"`
+}
+
+// IssueWithComments is an issue and its comments in the same shapes the REST client returns.
+type IssueWithComments struct {
+	IssueListItem
+	CommentList []IssueComment
+}
+
+// PRReview is a pull request review.
+type PRReview struct {
+	ID    int64  `json:"id"`
+	State string `json:"state"`
+	Body  string `json:"body"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	SubmittedAt *string `json:"submitted_at"`
+}
+
+// PRWithReviews is a pull request and its reviews.
+type PRWithReviews struct {
+	PRListItem
+	Reviews      []PRReview
+	ReviewsTotal int
+}
+
+const issuesQuery = `
+query($owner: String!, $name: String!, $first: Int!, $after: String, $comments: Int!) {
+  repository(owner: $owner, name: $name) {
+    issues(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        databaseId number state title body url createdAt updatedAt closedAt
+        author { login }
+        assignees(first: 20) { nodes { login } }
+        labels(first: 20) { nodes { name color } }
+        comments(first: $comments) {
+          totalCount
+          nodes { databaseId body createdAt updatedAt author { login } }
+        }
+      }
+    }
+  }
+}`
+
+// ListIssuesGraphQL fetches one page of issues with assignees, labels and comments in a single
+// request. Pass the returned cursor to fetch the next page; it is empty on the last page.
+func (c *Client) ListIssuesGraphQL(ctx context.Context, accessToken string, fullName string, after string) ([]IssueWithComments, string, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var data struct {
+		Repository *struct {
+			Issues struct {
+				PageInfo gqlPageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					DatabaseID int64     `json:"databaseId"`
+					Number     int       `json:"number"`
+					State      string    `json:"state"`
+					Title      string    `json:"title"`
+					Body       string    `json:"body"`
+					URL        string    `json:"url"`
+					CreatedAt  *string   `json:"createdAt"`
+					UpdatedAt  *string   `json:"updatedAt"`
+					ClosedAt   *string   `json:"closedAt"`
+					Author     *gqlActor `json:"author"`
+					Assignees  struct {
+						Nodes []gqlActor `json:"nodes"`
+					} `json:"assignees"`
+					Labels struct {
+						Nodes []struct {
+							Name  string `json:"name"`
+							Color string `json:"color"`
+						} `json:"nodes"`
+					} `json:"labels"`
+					Comments struct {
+						TotalCount int `json:"totalCount"`
+						Nodes      []struct {
+							DatabaseID int64     `json:"databaseId"`
+							Body       string    `json:"body"`
+							CreatedAt  string    `json:"createdAt"`
+							UpdatedAt  string    `json:"updatedAt"`
+							Author     *gqlActor `json:"author"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"nodes"`
+			} `json:"issues"`
+		} `json:"repository"`
+	}
+	err = c.GraphQL(ctx, accessToken, issuesQuery, map[string]any{
+		"owner":    owner,
+		"name":     repo,
+		"first":    graphqlPageSize,
+		"after":    nullIfEmptyCursor(after),
+		"comments": graphqlCommentsSize,
+	}, &data)
+	if err != nil {
+		return nil, "", err
+	}
+	if data.Repository == nil {
+		return nil, "", fmt.Errorf("github graphql: repository not found")
+	}
+
+	conn := data.Repository.Issues
+	out := make([]IssueWithComments, 0, len(conn.Nodes))
+	for _, n := range conn.Nodes {
+		var it IssueWithComments
+		it.ID = n.DatabaseID
+		it.Number = n.Number
+		it.State = strings.ToLower(n.State)
+		it.Title = n.Title
+		it.Body = n.Body
+		it.HTMLURL = n.URL
+		it.User.Login = actorLogin(n.Author)
+		for _, a := range n.Assignees.Nodes {
+			it.Assignees = append(it.Assignees, struct {
+				Login string `json:"login"`
+			}{Login: a.Login})
+		}
+		for _, l := range n.Labels.Nodes {
+			it.Labels = append(it.Labels, struct {
+				Name  string `json:"name"`
+				Color string `json:"color"`
+			}{Name: l.Name, Color: l.Color})
+		}
+		it.Comments = n.Comments.TotalCount
+		it.CreatedAt, it.UpdatedAt, it.ClosedAt = n.CreatedAt, n.UpdatedAt, n.ClosedAt
+
+		it.CommentList = make([]IssueComment, 0, len(n.Comments.Nodes))
+		for _, cm := range n.Comments.Nodes {
+			var ic IssueComment
+			ic.ID = cm.DatabaseID
+			ic.Body = cm.Body
+			ic.User.Login = actorLogin(cm.Author)
+			ic.CreatedAt = cm.CreatedAt
+			ic.UpdatedAt = cm.UpdatedAt
+			it.CommentList = append(it.CommentList, ic)
+		}
+		out = append(out, it)
+	}
+
+	next := ""
+	if conn.PageInfo.HasNextPage {
+		next = conn.PageInfo.EndCursor
+	}
+	return out, next, nil
+}
+
+const pullRequestsQuery = `
+query($owner: String!, $name: String!, $first: Int!, $after: String, $reviews: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        databaseId number state title body url merged mergedAt createdAt updatedAt closedAt
+        author { login }
+        reviews(first: $reviews) {
+          totalCount
+          nodes { databaseId state body submittedAt author { login } }
+        }
+      }
+    }
+  }
+}`
+
+// ListPRsGraphQL fetches one page of pull requests with their reviews in a single request.
+// Pass the returned cursor to fetch the next page; it is empty on the last page.
+func (c *Client) ListPRsGraphQL(ctx context.Context, accessToken string, fullName string, after string) ([]PRWithReviews, string, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var data struct {
+		Repository *struct {
+			PullRequests struct {
+				PageInfo gqlPageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					DatabaseID int64     `json:"databaseId"`
+					Number     int       `json:"number"`
+					State      string    `json:"state"`
+					Title      string    `json:"title"`
+					Body       string    `json:"body"`
+					URL        string    `json:"url"`
+					Merged     bool      `json:"merged"`
+					MergedAt   *string   `json:"mergedAt"`
+					CreatedAt  *string   `json:"createdAt"`
+					UpdatedAt  *string   `json:"updatedAt"`
+					ClosedAt   *string   `json:"closedAt"`
+					Author     *gqlActor `json:"author"`
+					Reviews    struct {
+						TotalCount int `json:"totalCount"`
+						Nodes      []struct {
+							DatabaseID  int64     `json:"databaseId"`
+							State       string    `json:"state"`
+							Body        string    `json:"body"`
+							SubmittedAt *string   `json:"submittedAt"`
+							Author      *gqlActor `json:"author"`
+						} `json:"nodes"`
+					} `json:"reviews"`
+				} `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	}
+	err = c.GraphQL(ctx, accessToken, pullRequestsQuery, map[string]any{
+		"owner":   owner,
+		"name":    repo,
+		"first":   graphqlPageSize,
+		"after":   nullIfEmptyCursor(after),
+		"reviews": graphqlReviewsSize,
+	}, &data)
+	if err != nil {
+		return nil, "", err
+	}
+	if data.Repository == nil {
+		return nil, "", fmt.Errorf("github graphql: repository not found")
+	}
+
+	conn := data.Repository.PullRequests
+	out := make([]PRWithReviews, 0, len(conn.Nodes))
+	for _, n := range conn.Nodes {
+		var pr PRWithReviews
+		pr.ID = n.DatabaseID
+		pr.Number = n.Number
+		// REST reports merged PRs as "closed" with merged=true.
+		pr.State = "open"
+		if n.State != "OPEN" {
+			pr.State = "closed"
+		}
+		pr.Title = n.Title
+		pr.Body = n.Body
+		pr.HTMLURL = n.URL
+		pr.User.Login = actorLogin(n.Author)
+		pr.Merged = n.Merged
+		pr.MergedAt, pr.CreatedAt, pr.UpdatedAt, pr.ClosedAt = n.MergedAt, n.CreatedAt, n.UpdatedAt, n.ClosedAt
+
+		pr.ReviewsTotal = n.Reviews.TotalCount
+		pr.Reviews = make([]PRReview, 0, len(n.Reviews.Nodes))
+		for _, r := range n.Reviews.Nodes {
+			var rv PRReview
+			rv.ID = r.DatabaseID
+			rv.State = strings.ToLower(r.State)
+			rv.Body = r.Body
+			rv.User.Login = actorLogin(r.Author)
+			rv.SubmittedAt = r.SubmittedAt
+			pr.Reviews = append(pr.Reviews, rv)
+		}
+		out = append(out, pr)
+	}
+
+	next := ""
+	if conn.PageInfo.HasNextPage {
+		next = conn.PageInfo.EndCursor
+	}
+	return out, next, nil
+}
+
+// actorLogin handles deleted users ("ghost"), which GraphQL returns as a null author.
+func actorLogin(a *gqlActor) string {
+	if a == nil {
+		return "ghost"
+	}
+	return a.Login
+}
+
+func nullIfEmptyCursor(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CacheEntry is a cached GitHub response body with its validators and Link header.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	Link         string
+	Body         []byte
+}
+
+// HTTPCache stores validators and bodies for conditional GETs.
+type HTTPCache interface {
+	Get(ctx context.Context, key string) (CacheEntry, bool, error)
+	Put(ctx context.Context, key string, e CacheEntry) error
+}
+
+// PostgresHTTPCache persists entries in github_http_cache so they survive restarts and are
+// shared by every worker.
+type PostgresHTTPCache struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresHTTPCache(pool *pgxpool.Pool) *PostgresHTTPCache {
+	return &PostgresHTTPCache{pool: pool}
+}
+
+func (p *PostgresHTTPCache) Get(ctx context.Context, key string) (CacheEntry, bool, error) {
+	var e CacheEntry
+	var etag, lastModified, link *string
+	err := p.pool.QueryRow(ctx, `
+SELECT etag, last_modified, link, body FROM github_http_cache WHERE cache_key = $1
+`, key).Scan(&etag, &lastModified, &link, &e.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	if etag != nil {
+		e.ETag = *etag
+	}
+	if lastModified != nil {
+		e.LastModified = *lastModified
+	}
+	if link != nil {
+		e.Link = *link
+	}
+	return e, true, nil
+}
+
+func (p *PostgresHTTPCache) Put(ctx context.Context, key string, e CacheEntry) error {
+	_, err := p.pool.Exec(ctx, `
+INSERT INTO github_http_cache (cache_key, etag, last_modified, link, body, updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5, now())
+ON CONFLICT (cache_key) DO UPDATE SET
+  etag = EXCLUDED.etag,
+  last_modified = EXCLUDED.last_modified,
+  link = EXCLUDED.link,
+  body = EXCLUDED.body,
+  updated_at = now()
+`, key, e.ETag, e.LastModified, e.Link, e.Body)
+	return err
+}
+
+// PurgeOlderThan drops entries not refreshed within maxAge (e.g. repos no longer synced).
+func (p *PostgresHTTPCache) PurgeOlderThan(ctx context.Context, maxAge time.Duration) (int64, error) {
+	ct, err := p.pool.Exec(ctx, `DELETE FROM github_http_cache WHERE updated_at < $1`, time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// condResponse is the result of getConditional.
+type condResponse struct {
+	Body        []byte
+	Link        string
+	NotModified bool
+}
+
+// getConditional performs req, sending If-None-Match/If-Modified-Since from the cache when
+// available. On 304 the cached body (and Link header) is returned with NotModified set. Non-2xx
+// responses are returned as *StatusError with op. Cache failures never fail the request.
+func (c *Client) getConditional(req *http.Request, op string) (condResponse, error) {
+	ctx := req.Context()
+	key := req.Method + " " + req.URL.String()
+
+	var cached CacheEntry
+	var hit bool
+	if c.Cache != nil {
+		cached, hit, _ = c.Cache.Get(ctx, key)
+		if hit {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return condResponse{}, err
+	}
+	defer resp.Body.Close()
+	if err := c.observeRateLimit(req, resp); err != nil {
+		return condResponse{}, err
+	}
+
+	link := resp.Header.Get("Link")
+	if resp.StatusCode == http.StatusNotModified && hit {
+		if link == "" {
+			link = cached.Link
+		}
+		return condResponse{Body: cached.Body, Link: link, NotModified: true}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return condResponse{}, &StatusError{Op: op, Code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return condResponse{}, err
+	}
+	if c.Cache != nil {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			_ = c.Cache.Put(ctx, key, CacheEntry{ETag: etag, LastModified: lastModified, Link: link, Body: body})
+		}
+	}
+	return condResponse{Body: body, Link: link}, nil
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InstallationTokenSource caches installation access tokens per installation until shortly before
+// they expire (GitHub issues them for one hour).
+type InstallationTokenSource struct {
+	app *GitHubAppClient
+
+	mu     sync.Mutex
+	tokens map[string]InstallationTokenResponse
+}
+
+// NewInstallationTokenSource returns nil when the GitHub App is not configured.
+func NewInstallationTokenSource(appID string, privateKeyPEM string) (*InstallationTokenSource, error) {
+	if strings.TrimSpace(appID) == "" || strings.TrimSpace(privateKeyPEM) == "" {
+		return nil, nil
+	}
+	app, err := NewGitHubAppClient(appID, privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &InstallationTokenSource{app: app, tokens: map[string]InstallationTokenResponse{}}, nil
+}
+
+// Token returns a valid installation token, minting a new one when the cached token is close to expiry.
+func (s *InstallationTokenSource) Token(ctx context.Context, installationID string) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("github app not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.tokens[installationID]; ok && time.Until(cached.ExpiresAt) > 5*time.Minute {
+		return cached.Token, nil
+	}
+	tok, err := s.app.CreateInstallationToken(ctx, installationID)
+	if err != nil {
+		return "", err
+	}
+	if tok.ExpiresAt.IsZero() {
+		tok.ExpiresAt = time.Now().Add(50 * time.Minute)
+	}
+	s.tokens[installationID] = tok
+	return tok.Token, nil
+}
+
+// Forget drops a cached token (e.g. after the installation was removed).
+func (s *InstallationTokenSource) Forget(installationID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.tokens, installationID)
+	s.mu.Unlock()
+}
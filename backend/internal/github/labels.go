@@ -0,0 +1,93 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// AddIssueLabels adds labels to an issue, creating labels the repo does not have yet. Requires
+// issues write permission.
+func (c *Client) AddIssueLabels(ctx context.Context, accessToken string, fullName string, issueNumber int, labels []string) error {
+	if issueNumber <= 0 || len(labels) == 0 {
+		return fmt.Errorf("invalid issue number or labels")
+	}
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues/" + fmt.Sprintf("%d", issueNumber) + "/labels"
+	return c.sendJSON(ctx, http.MethodPost, accessToken, u, map[string][]string{"labels": labels})
+}
+
+// RemoveIssueLabel removes a label from an issue. A label the issue does not have is not an error.
+func (c *Client) RemoveIssueLabel(ctx context.Context, accessToken string, fullName string, issueNumber int, label string) error {
+	if issueNumber <= 0 || label == "" {
+		return fmt.Errorf("invalid issue number or label")
+	}
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues/" + fmt.Sprintf("%d", issueNumber) + "/labels/" + url.PathEscape(label)
+	err = c.sendJSON(ctx, http.MethodDelete, accessToken, u, nil)
+	var apiErr *GitHubAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// UpdateIssueComment replaces the body of an issue comment. The token must belong to the comment's
+// author (for bot comments, the app installation).
+func (c *Client) UpdateIssueComment(ctx context.Context, accessToken string, fullName string, commentID int64, body string) error {
+	if commentID <= 0 || body == "" {
+		return fmt.Errorf("invalid comment id or body")
+	}
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues/comments/" + fmt.Sprintf("%d", commentID)
+	return c.sendJSON(ctx, http.MethodPatch, accessToken, u, map[string]string{"body": body})
+}
+
+// sendJSON sends payload (none when nil) and discards the response body.
+func (c *Client) sendJSON(ctx context.Context, method string, accessToken string, u string, payload any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type IssueListItem struct {
@@ -52,21 +53,32 @@ type PRListItem struct {
 	ClosedAt  *string `json:"closed_at"`
 }
 
-func (c *Client) ListIssuesPage(ctx context.Context, accessToken string, fullName string, page int) ([]IssueListItem, error) {
+// ListIssuesPage fetches one page of issues, most recently updated first. When since is non-zero
+// only issues updated after it are returned. Pass "" as cursor for the first page, then the
+// returned next until it is "" (no rel="next" in the Link header).
+func (c *Client) ListIssuesPage(ctx context.Context, accessToken string, fullName string, since time.Time, cursor string) (items []IssueListItem, next string, notModified bool, err error) {
 	owner, repo, err := splitFullName(fullName)
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
 	u, _ := url.Parse("https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues")
 	q := u.Query()
 	q.Set("state", "all")
+	q.Set("sort", "updated")
+	q.Set("direction", "desc")
 	q.Set("per_page", "100")
-	q.Set("page", strconv.Itoa(page))
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
 	u.RawQuery = q.Encode()
+	target, err := pageURL(cursor, u)
+	if err != nil {
+		return nil, "", false, err
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Accept", "application/vnd.github+json")
@@ -74,38 +86,39 @@ func (c *Client) ListIssuesPage(ctx context.Context, accessToken string, fullNam
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.getConditional(req, "github list issues failed")
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("github list issues failed: status %d", resp.StatusCode)
-	}
-
-	var items []IssueListItem
-	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
-		return nil, err
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, "", false, err
 	}
-	return items, nil
+	return items, NextPageURL(resp.Link), resp.NotModified, nil
 }
 
-func (c *Client) ListPRsPage(ctx context.Context, accessToken string, fullName string, page int) ([]PRListItem, error) {
+// ListPRsPage fetches one page of PRs, most recently updated first (the pulls API has no since
+// filter, so incremental callers stop once items are older than their cursor). Pass "" as cursor
+// for the first page, then the returned next until it is "" (no rel="next" in the Link header).
+func (c *Client) ListPRsPage(ctx context.Context, accessToken string, fullName string, cursor string) (items []PRListItem, next string, notModified bool, err error) {
 	owner, repo, err := splitFullName(fullName)
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
 	u, _ := url.Parse("https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/pulls")
 	q := u.Query()
 	q.Set("state", "all")
+	q.Set("sort", "updated")
+	q.Set("direction", "desc")
 	q.Set("per_page", "100")
-	q.Set("page", strconv.Itoa(page))
 	u.RawQuery = q.Encode()
+	target, err := pageURL(cursor, u)
+	if err != nil {
+		return nil, "", false, err
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Accept", "application/vnd.github+json")
@@ -113,21 +126,14 @@ func (c *Client) ListPRsPage(ctx context.Context, accessToken string, fullName s
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.getConditional(req, "github list prs failed")
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("github list prs failed: status %d", resp.StatusCode)
-	}
-
-	var items []PRListItem
-	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
-		return nil, err
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, "", false, err
 	}
-	return items, nil
+	return items, NextPageURL(resp.Link), resp.NotModified, nil
 }
 
 // IssueComment represents a comment on a GitHub issue.
@@ -160,23 +166,80 @@ func (c *Client) ListIssueComments(ctx context.Context, accessToken string, full
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.getConditional(req, "github list issue comments failed")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("github list issue comments failed: status %d", resp.StatusCode)
-	}
-
 	var comments []IssueComment
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+	if err := json.Unmarshal(resp.Body, &comments); err != nil {
 		return nil, err
 	}
 	return comments, nil
 }
 
+// RepoIssueComment is a comment from the repository-wide comments listing, which covers issues and
+// pull requests alike and identifies the target only by its issue_url.
+type RepoIssueComment struct {
+	IssueComment
+	HTMLURL  string `json:"html_url"`
+	IssueURL string `json:"issue_url"`
+}
+
+// IssueNumber is the number of the issue or PR the comment belongs to, or 0 if issue_url is malformed.
+func (c RepoIssueComment) IssueNumber() int {
+	i := strings.LastIndex(c.IssueURL, "/")
+	if i < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(c.IssueURL[i+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// ListRepoIssueCommentsPage fetches one page of the repository's issue and PR comments, least
+// recently updated first, so a walk from since ends at the newest comment. Pass "" as cursor for
+// the first page, then the returned next until it is "".
+func (c *Client) ListRepoIssueCommentsPage(ctx context.Context, accessToken string, fullName string, since time.Time, cursor string) (items []RepoIssueComment, next string, notModified bool, err error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, "", false, err
+	}
+	u, _ := url.Parse("https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/issues/comments")
+	q := u.Query()
+	q.Set("sort", "updated")
+	q.Set("direction", "asc")
+	q.Set("per_page", "100")
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	u.RawQuery = q.Encode()
+	target, err := pageURL(cursor, u)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.getConditional(req, "github list repo issue comments failed")
+	if err != nil {
+		return nil, "", false, err
+	}
+	if err := json.Unmarshal(resp.Body, &items); err != nil {
+		return nil, "", false, err
+	}
+	return items, NextPageURL(resp.Link), resp.NotModified, nil
+}
+
 func looksLikeRFC3339(s string) bool {
 	// cheap heuristic; actual parsing happens where stored.
 	return strings.Contains(s, "T") && (strings.HasSuffix(s, "Z") || strings.Contains(s, "+") || strings.Contains(s, "-"))
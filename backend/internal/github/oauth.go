@@ -48,6 +48,30 @@ type TokenResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
 	Scope       string `json:"scope"`
+	// Set only when the app has user-to-server token expiration enabled (seconds).
+	ExpiresIn             int    `json:"expires_in,omitempty"`
+	RefreshToken          string `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in,omitempty"`
+	// GitHub reports some errors (e.g. bad_refresh_token) with status 200.
+	Error string `json:"error,omitempty"`
+}
+
+// ExpiresAt returns when the access token expires, or nil for non-expiring tokens.
+func (tr TokenResponse) ExpiresAt(now time.Time) *time.Time {
+	if tr.ExpiresIn <= 0 {
+		return nil
+	}
+	t := now.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	return &t
+}
+
+// RefreshTokenExpiresAt returns when the refresh token expires, or nil if there is none.
+func (tr TokenResponse) RefreshTokenExpiresAt(now time.Time) *time.Time {
+	if tr.RefreshTokenExpiresIn <= 0 {
+		return nil
+	}
+	t := now.Add(time.Duration(tr.RefreshTokenExpiresIn) * time.Second)
+	return &t
 }
 
 func ExchangeCode(ctx context.Context, code string, cfg OAuthConfig) (TokenResponse, error) {
@@ -58,12 +82,32 @@ func ExchangeCode(ctx context.Context, code string, cfg OAuthConfig) (TokenRespo
 		return TokenResponse{}, fmt.Errorf("code is required")
 	}
 
-	body := map[string]string{
+	return postAccessToken(ctx, map[string]string{
 		"client_id":     cfg.ClientID,
 		"client_secret": cfg.ClientSecret,
 		"code":          code,
 		"redirect_uri":  cfg.RedirectURL,
+	})
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access/refresh token pair.
+// The old refresh token is invalidated by GitHub.
+func RefreshAccessToken(ctx context.Context, refreshToken string, cfg OAuthConfig) (TokenResponse, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return TokenResponse{}, fmt.Errorf("github oauth not configured")
+	}
+	if refreshToken == "" {
+		return TokenResponse{}, fmt.Errorf("refresh token is required")
 	}
+	return postAccessToken(ctx, map[string]string{
+		"client_id":     cfg.ClientID,
+		"client_secret": cfg.ClientSecret,
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
+
+func postAccessToken(ctx context.Context, body map[string]string) (TokenResponse, error) {
 	b, _ := json.Marshal(body)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://github.com/login/oauth/access_token", bytes.NewReader(b))
@@ -88,29 +132,76 @@ func ExchangeCode(ctx context.Context, code string, cfg OAuthConfig) (TokenRespo
 	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
 		return TokenResponse{}, err
 	}
+	if tr.Error != "" {
+		return TokenResponse{}, fmt.Errorf("token exchange failed: %s", tr.Error)
+	}
 	if tr.AccessToken == "" {
 		return TokenResponse{}, fmt.Errorf("token exchange returned empty token")
 	}
 	return tr, nil
 }
 
+// RevokeGrant revokes the OAuth app authorization (and all its tokens) for the user owning accessToken.
+func RevokeGrant(ctx context.Context, accessToken string, cfg OAuthConfig) error {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return fmt.Errorf("github oauth not configured")
+	}
+	b, _ := json.Marshal(map[string]string{"access_token": accessToken})
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "https://api.github.com/applications/"+url.PathEscape(cfg.ClientID)+"/grant", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(cfg.ClientID, cfg.ClientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
 
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
+	// 404/422 mean the grant or token is already gone.
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("grant revoke failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
 
+// CheckToken reports whether accessToken is still valid for the OAuth app. A revoked or expired
+// token returns false with a nil error; transport and unexpected status errors are returned.
+func CheckToken(ctx context.Context, accessToken string, cfg OAuthConfig) (bool, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return false, fmt.Errorf("github oauth not configured")
+	}
+	b, _ := json.Marshal(map[string]string{"access_token": accessToken})
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/applications/"+url.PathEscape(cfg.ClientID)+"/token", bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(cfg.ClientID, cfg.ClientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
 
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
 
-
-
-
-
-
-
-
-
-
-
-
-
-
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("token check failed: status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Org struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+// OrgMembership is the authenticated user's membership in an org ("admin" or "member").
+type OrgMembership struct {
+	State string `json:"state"`
+	Role  string `json:"role"`
+}
+
+func (c *Client) GetOrg(ctx context.Context, accessToken string, org string) (Org, error) {
+	var o Org
+	if err := c.getJSON(ctx, accessToken, "https://api.github.com/orgs/"+url.PathEscape(org), &o); err != nil {
+		return Org{}, err
+	}
+	if o.ID == 0 {
+		return Org{}, fmt.Errorf("invalid github org response")
+	}
+	return o, nil
+}
+
+// GetOrgMembership requires the read:org scope.
+func (c *Client) GetOrgMembership(ctx context.Context, accessToken string, org string) (OrgMembership, error) {
+	var m OrgMembership
+	err := c.getJSON(ctx, accessToken, "https://api.github.com/user/memberships/orgs/"+url.PathEscape(org), &m)
+	return m, err
+}
+
+// CreateOrgWebhook registers an organization webhook (requires admin:org_hook). It receives
+// events for every repository in the org.
+func (c *Client) CreateOrgWebhook(ctx context.Context, accessToken string, org string, req CreateWebhookRequest) (Webhook, error) {
+	if req.URL == "" || req.Secret == "" {
+		return Webhook{}, fmt.Errorf("webhook url and secret are required")
+	}
+	if len(req.Events) == 0 {
+		req.Events = []string{"issues", "pull_request", "pull_request_review", "push", "issue_comment", "pull_request_review_comment", "discussion", "release", "repository"}
+	}
+	b, _ := json.Marshal(map[string]any{
+		"name":   "web",
+		"active": req.Active,
+		"events": req.Events,
+		"config": map[string]any{
+			"url":          req.URL,
+			"content_type": "json",
+			"secret":       req.Secret,
+			"insecure_ssl": "0",
+		},
+	})
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/orgs/"+url.PathEscape(org)+"/hooks", bytes.NewReader(b))
+	if err != nil {
+		return Webhook{}, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return Webhook{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Webhook{}, parseGitHubAPIError(resp)
+	}
+	var wh Webhook
+	if err := json.NewDecoder(resp.Body).Decode(&wh); err != nil {
+		return Webhook{}, err
+	}
+	if wh.ID == 0 {
+		return Webhook{}, fmt.Errorf("invalid github webhook response")
+	}
+	return wh, nil
+}
+
+// DeleteOrgWebhook removes an organization webhook. A 404 (already gone) is treated as success.
+func (c *Client) DeleteOrgWebhook(ctx context.Context, accessToken string, org string, hookID int64) error {
+	u := "https://api.github.com/orgs/" + url.PathEscape(org) + "/hooks/" + fmt.Sprintf("%d", hookID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
+
+// ListOrgRepos lists the org's repositories visible to the token, following pagination up to max
+// repos (0 for no limit). Each repo carries the token's permissions on it.
+func (c *Client) ListOrgRepos(ctx context.Context, accessToken string, org string, max int) ([]Repo, error) {
+	u := "https://api.github.com/orgs/" + url.PathEscape(org) + "/repos?type=all&sort=full_name&per_page=100"
+	var out []Repo
+	for u != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
+
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			err := parseGitHubAPIError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var page []Repo
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if max > 0 && len(out) >= max {
+			return out[:max], nil
+		}
+		u = NextPageURL(resp.Header.Get("Link"))
+	}
+	return out, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, accessToken string, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
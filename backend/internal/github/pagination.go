@@ -0,0 +1,41 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const apiBaseURL = "https://api.github.com/"
+
+// NextPageURL returns the rel="next" target of a GitHub Link header, or "" on the last page.
+func NextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segs := strings.Split(part, ";")
+		if len(segs) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(segs[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, p := range segs[1:] {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
+
+// pageURL resolves a list request's URL: cursor is a next-page URL returned by an earlier call, or ""
+// for the first page. Cursors must point at the GitHub API since the request carries the token.
+func pageURL(cursor string, first *url.URL) (string, error) {
+	if cursor == "" {
+		return first.String(), nil
+	}
+	if !strings.HasPrefix(cursor, apiBaseURL) {
+		return "", fmt.Errorf("invalid github page cursor")
+	}
+	return cursor, nil
+}
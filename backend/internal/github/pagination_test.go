@@ -0,0 +1,21 @@
+package github
+
+import "testing"
+
+func TestNextPageURL(t *testing.T) {
+	cases := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=9>; rel="last"`,
+			"https://api.github.com/repositories/1/issues?page=2"},
+		{`<https://api.github.com/repositories/1/issues?page=1>; rel="prev", <https://api.github.com/repositories/1/issues?page=1>; rel="first"`, ""},
+		{`<https://api.github.com/x?page=3>; rel="last", <https://api.github.com/x?page=2>; rel="next"`, "https://api.github.com/x?page=2"},
+	}
+	for _, tc := range cases {
+		if got := NextPageURL(tc.link); got != tc.want {
+			t.Errorf("NextPageURL(%q) = %q, want %q", tc.link, got, tc.want)
+		}
+	}
+}
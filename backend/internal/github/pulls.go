@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// PullRequest is a single pull request as returned by GET /repos/{owner}/{repo}/pulls/{number}.
+type PullRequest struct {
+	ID      int64  `json:"id"`
+	Number  int    `json:"number"`
+	State   string `json:"state"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Merged   bool    `json:"merged"`
+	MergedAt *string `json:"merged_at"`
+}
+
+// GetPullRequest fetches one pull request. A missing PR (or a repo the token cannot see) returns a
+// *GitHubAPIError with StatusCode 404.
+func (c *Client) GetPullRequest(ctx context.Context, accessToken string, fullName string, number int) (PullRequest, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	var pr PullRequest
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/pulls/" + strconv.Itoa(number)
+	if err := c.getJSON(ctx, accessToken, u, &pr); err != nil {
+		return PullRequest{}, err
+	}
+	return pr, nil
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RateBudget is a GitHub quota shared by every process using a token, so concurrent workers on the
+// same owner's token spend from one budget instead of each trusting the headers it last saw.
+type RateBudget struct {
+	pool *pgxpool.Pool
+}
+
+func NewRateBudget(pool *pgxpool.Pool) *RateBudget {
+	return &RateBudget{pool: pool}
+}
+
+// Reserve takes one request from the shared budget for accessToken and resource and returns the
+// quota left after it. ok is false when nothing is known for the current window yet; the first
+// response's headers fill it in.
+func (b *RateBudget) Reserve(ctx context.Context, accessToken string, resource string) (rl RateLimit, ok bool, err error) {
+	rl.Resource = resource
+	err = b.pool.QueryRow(ctx, `
+UPDATE github_rate_budgets
+SET remaining = GREATEST(remaining - 1, 0)
+WHERE token_key = $1 AND resource = $2 AND reset_at > now()
+RETURNING limit_total, remaining, reset_at, observed_at
+`, tokenKey(accessToken), resource).Scan(&rl.Limit, &rl.Remaining, &rl.Reset, &rl.ObservedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return RateLimit{}, false, nil
+	}
+	if err != nil {
+		return RateLimit{}, false, err
+	}
+	return rl, true, nil
+}
+
+// Observe records the quota GitHub reported for accessToken. Within the same window the lower
+// remaining wins, since reservations for requests still in flight are not in the headers yet.
+func (b *RateBudget) Observe(ctx context.Context, accessToken string, rl RateLimit) error {
+	_, err := b.pool.Exec(ctx, `
+INSERT INTO github_rate_budgets (token_key, resource, limit_total, remaining, reset_at, observed_at)
+VALUES ($1, $2, $3, $4, $5, now())
+ON CONFLICT (token_key, resource) DO UPDATE SET
+  limit_total = EXCLUDED.limit_total,
+  remaining = CASE
+    WHEN github_rate_budgets.reset_at = EXCLUDED.reset_at THEN LEAST(github_rate_budgets.remaining, EXCLUDED.remaining)
+    ELSE EXCLUDED.remaining
+  END,
+  reset_at = EXCLUDED.reset_at,
+  observed_at = now()
+`, tokenKey(accessToken), rl.Resource, rl.Limit, rl.Remaining, rl.Reset)
+	return err
+}
+
+// PurgeExpired drops budgets whose window ended more than maxAge ago (tokens no longer in use).
+func (b *RateBudget) PurgeExpired(ctx context.Context, maxAge time.Duration) (int64, error) {
+	ct, err := b.pool.Exec(ctx, `DELETE FROM github_rate_budgets WHERE reset_at < $1`, time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the quota GitHub reported on the most recent response for a token.
+type RateLimit struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	Resource   string // "core", "graphql", ...
+	ObservedAt time.Time
+}
+
+// RateLimitError is returned when GitHub rejects a request because the quota is exhausted
+// (403/429 with X-RateLimit-Remaining: 0, or a secondary limit with Retry-After).
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("github rate limit exceeded, resets at %s", e.Reset.UTC().Format(time.RFC3339))
+}
+
+// RateLimit returns the last quota observed for accessToken and resource ("core" for REST,
+// "graphql" for GraphQL).
+func (c *Client) RateLimit(accessToken string, resource string) (RateLimit, bool) {
+	c.rlMu.Lock()
+	defer c.rlMu.Unlock()
+	rl, ok := c.rateLimits[tokenKey(accessToken)+":"+resource]
+	return rl, ok
+}
+
+// observeRateLimit records the quota headers of resp for the token used in req and converts
+// quota rejections into *RateLimitError.
+func (c *Client) observeRateLimit(req *http.Request, resp *http.Response) error {
+	h := resp.Header
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err == nil {
+		limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+		resetUnix, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+		resource := h.Get("X-RateLimit-Resource")
+		if resource == "" {
+			resource = "core"
+		}
+		rl := RateLimit{
+			Limit:      limit,
+			Remaining:  remaining,
+			Reset:      time.Unix(resetUnix, 0),
+			Resource:   resource,
+			ObservedAt: time.Now(),
+		}
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		c.rlMu.Lock()
+		if c.rateLimits == nil {
+			c.rateLimits = map[string]RateLimit{}
+		}
+		c.rateLimits[tokenKey(token)+":"+resource] = rl
+		c.rlMu.Unlock()
+		if c.Budget != nil {
+			if err := c.Budget.Observe(req.Context(), token, rl); err != nil {
+				slog.Debug("github rate budget update failed", "resource", resource, "error", err)
+			}
+		}
+
+		if remaining == 0 && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) {
+			return &RateLimitError{Reset: rl.Reset}
+		}
+	}
+	// Secondary (abuse) limits carry Retry-After instead of an exhausted quota.
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+			return &RateLimitError{Reset: time.Now().Add(time.Duration(secs) * time.Second)}
+		}
+	}
+	return nil
+}
+
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
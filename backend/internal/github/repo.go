@@ -19,19 +19,38 @@ type Repo struct {
 		Login     string `json:"login"`
 		AvatarURL string `json:"avatar_url"`
 	} `json:"owner"`
-	FullName        string `json:"full_name"`
-	HTMLURL         string `json:"html_url"`
-	Homepage        string `json:"homepage"`
-	Private         bool   `json:"private"`
-	StargazersCount int    `json:"stargazers_count"`
-	ForksCount      int    `json:"forks_count"`
-	OpenIssuesCount int    `json:"open_issues_count"`
-	Description     string `json:"description"`
+	FullName        string   `json:"full_name"`
+	HTMLURL         string   `json:"html_url"`
+	Homepage        string   `json:"homepage"`
+	Private         bool     `json:"private"`
+	StargazersCount int      `json:"stargazers_count"`
+	ForksCount      int      `json:"forks_count"`
+	OpenIssuesCount int      `json:"open_issues_count"`
+	Description     string   `json:"description"`
+	DefaultBranch   string   `json:"default_branch"`
+	Topics          []string `json:"topics"`
+	Language        string   `json:"language"`
+	Archived        bool     `json:"archived"`
+	Fork            bool     `json:"fork"`
+	License         *struct {
+		SPDXID string `json:"spdx_id"`
+		Name   string `json:"name"`
+	} `json:"license"`
 	Permissions struct {
 		Admin bool `json:"admin"`
 		Push  bool `json:"push"`
 		Pull  bool `json:"pull"`
 	} `json:"permissions"`
+	// Parent and Source are set on forks fetched individually: the repo it was forked from and the
+	// root of the fork network.
+	Parent *RepoRef `json:"parent,omitempty"`
+	Source *RepoRef `json:"source,omitempty"`
+}
+
+// RepoRef identifies another repository, e.g. a fork's parent.
+type RepoRef struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
 }
 
 type GitHubAPIError struct {
@@ -103,8 +122,15 @@ func (c *Client) GetRepo(ctx context.Context, accessToken string, fullName strin
 	if err != nil {
 		return Repo{}, err
 	}
-	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	return c.getRepo(ctx, accessToken, "https://api.github.com/repos/"+url.PathEscape(owner)+"/"+url.PathEscape(repo))
+}
+
+// GetRepoByID fetches a repository by its numeric ID, which survives renames and transfers.
+func (c *Client) GetRepoByID(ctx context.Context, accessToken string, id int64) (Repo, error) {
+	return c.getRepo(ctx, accessToken, "https://api.github.com/repositories/"+strconv.FormatInt(id, 10))
+}
 
+func (c *Client) getRepo(ctx context.Context, accessToken string, u string) (Repo, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return Repo{}, err
@@ -178,58 +204,99 @@ func (c *Client) GetRepoLanguages(ctx context.Context, accessToken string, fullN
 
 // ReadmeResponse represents the GitHub API response for README content
 type ReadmeResponse struct {
-	Name    string `json:"name"`
-	Path    string `json:"path"`
-	Content string `json:"content"` // Base64 encoded
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	SHA      string `json:"sha"`
+	Content  string `json:"content"` // Base64 encoded
 	Encoding string `json:"encoding"`
 }
 
+// ReadmeFile is a repository's README with its content decoded.
+type ReadmeFile struct {
+	Path    string
+	SHA     string
+	Content string
+}
+
 // GetReadme fetches the README.md content from a GitHub repository
 func (c *Client) GetReadme(ctx context.Context, accessToken string, fullName string) (string, error) {
-	owner, repo, err := splitFullName(fullName)
+	f, err := c.GetReadmeFile(ctx, accessToken, fullName)
 	if err != nil {
 		return "", err
 	}
-	// GitHub API endpoint for README (automatically finds README.md, README, etc.)
-	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/readme"
+	return f.Content, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+// GetReadmeFile fetches the repository's README (GitHub picks README.md, README, etc.). A repo
+// without one yields a *GitHubAPIError with status 404.
+func (c *Client) GetReadmeFile(ctx context.Context, accessToken string, fullName string) (ReadmeFile, error) {
+	resp, err := c.readmeRequest(ctx, accessToken, fullName, "application/vnd.github+json")
 	if err != nil {
-		return "", err
+		return ReadmeFile{}, err
 	}
-	if strings.TrimSpace(accessToken) != "" {
-		req.Header.Set("Authorization", "Bearer "+accessToken)
+	defer resp.Body.Close()
+
+	var readme ReadmeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&readme); err != nil {
+		return ReadmeFile{}, err
 	}
-	req.Header.Set("Accept", "application/vnd.github+json")
-	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
+	content := readme.Content
+	if readme.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(readme.Content)
+		if err != nil {
+			return ReadmeFile{}, err
+		}
+		content = string(decoded)
 	}
+	return ReadmeFile{Path: readme.Path, SHA: readme.SHA, Content: content}, nil
+}
 
-	resp, err := c.HTTP.Do(req)
+// GetReadmeHTML returns the README rendered by GitHub. GitHub sanitizes the markup it renders
+// (no scripts, event handlers or unsafe URLs), the same output github.com shows.
+func (c *Client) GetReadmeHTML(ctx context.Context, accessToken string, fullName string) (string, error) {
+	resp, err := c.readmeRequest(ctx, accessToken, fullName, "application/vnd.github.html+json")
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, ReadmeMaxBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(b) > ReadmeMaxBytes {
+		return "", fmt.Errorf("rendered readme exceeds %d bytes", ReadmeMaxBytes)
+	}
+	return string(b), nil
+}
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("readme not found: status %d", resp.StatusCode)
+func (c *Client) readmeRequest(ctx context.Context, accessToken string, fullName string, accept string) (*http.Response, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
 	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/readme"
 
-	var readme ReadmeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&readme); err != nil {
-		return "", err
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(accessToken) != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	req.Header.Set("Accept", accept)
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	// Decode base64 content
-	if readme.Encoding == "base64" {
-		decoded, err := base64.StdEncoding.DecodeString(readme.Content)
-		if err != nil {
-			return "", err
-		}
-		return string(decoded), nil
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, parseGitHubAPIError(resp)
 	}
-	// If not base64, return as-is (shouldn't happen with GitHub API)
-	return readme.Content, nil
+	return resp, nil
 }
 
 func splitFullName(fullName string) (string, string, error) {
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SaveRepoMetadata persists the listing fields of repo on the project. description and language are
+// only filled in when the maintainer hasn't set them, since both are editable in project metadata.
+func SaveRepoMetadata(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, repo Repo) error {
+	var licenseSPDX, licenseName *string
+	if repo.License != nil {
+		// GitHub reports unrecognised licenses as NOASSERTION.
+		if repo.License.SPDXID != "" && repo.License.SPDXID != "NOASSERTION" {
+			licenseSPDX = &repo.License.SPDXID
+		}
+		if repo.License.Name != "" {
+			licenseName = &repo.License.Name
+		}
+	}
+	topics := repo.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	_, err := pool.Exec(ctx, `
+UPDATE projects
+SET github_repo_id = $2,
+    stars_count = $3,
+    forks_count = $4,
+    description = COALESCE(NULLIF(description, ''), NULLIF($5, '')),
+    default_branch = NULLIF($6, ''),
+    topics = $7,
+    license_spdx_id = $8,
+    license_name = $9,
+    github_language = NULLIF($10, ''),
+    language = COALESCE(NULLIF(language, ''), NULLIF($10, '')),
+    archived = $11,
+    repo_unavailable_at = NULL,
+    repo_metadata_updated_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, projectID, repo.ID, repo.StargazersCount, repo.ForksCount, repo.Description, repo.DefaultBranch,
+		topics, licenseSPDX, licenseName, repo.Language, repo.Archived)
+	return err
+}
+
+// SaveRepoLanguages replaces the project's language breakdown with langs (bytes per language, as
+// returned by GetRepoLanguages), storing each language's share of the total.
+func SaveRepoLanguages(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, langs map[string]int64) error {
+	var total int64
+	for _, n := range langs {
+		total += n
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM project_languages WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+	for name, n := range langs {
+		if total <= 0 || n <= 0 {
+			continue
+		}
+		pct := math.Round(float64(n)*10000/float64(total)) / 100
+		if _, err := tx.Exec(ctx, `
+INSERT INTO project_languages (project_id, language, bytes, percentage, updated_at)
+VALUES ($1, $2, $3, $4, now())
+`, projectID, name, n, pct); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkRepoUnavailable records that the project's repo could not be found, hiding it from public listings.
+func MarkRepoUnavailable(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `
+UPDATE projects
+SET repo_unavailable_at = COALESCE(repo_unavailable_at, now()),
+    repo_metadata_updated_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, projectID)
+	return err
+}
+
+// UpdateRepoFullName points the project tracking repoID at fullName after a rename or transfer,
+// re-deriving its organization from the new owner. It returns the number of projects updated.
+func UpdateRepoFullName(ctx context.Context, pool *pgxpool.Pool, repoID int64, fullName string) (int64, error) {
+	ct, err := pool.Exec(ctx, `
+UPDATE projects
+SET github_full_name = $2,
+    organization_id = (SELECT o.id FROM organizations o WHERE lower(o.login) = lower(split_part($2, '/', 1))),
+    updated_at = now()
+WHERE github_repo_id = $1
+  AND github_full_name <> $2
+  AND deleted_at IS NULL
+`, repoID, fullName)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// ReadmeMaxBytes caps the README stored per project; longer markdown is truncated and its HTML
+// rendering is not stored.
+const ReadmeMaxBytes = 512 << 10
+
+// RefreshReadme fetches the repo's README and caches its markdown and GitHub-rendered HTML on the
+// project. An unchanged README (same blob SHA) is not rendered again; a repo without one clears the
+// cache.
+func RefreshReadme(ctx context.Context, pool *pgxpool.Pool, gh *Client, projectID uuid.UUID, token string, fullName string) error {
+	f, err := gh.GetReadmeFile(ctx, token, fullName)
+	if err != nil {
+		var apiErr *GitHubAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			_, err = pool.Exec(ctx, `
+UPDATE projects
+SET readme_markdown = NULL, readme_html = NULL, readme_path = NULL, readme_sha = NULL, readme_fetched_at = now()
+WHERE id = $1
+`, projectID)
+		}
+		return err
+	}
+
+	var storedSHA *string
+	if err := pool.QueryRow(ctx, `SELECT readme_sha FROM projects WHERE id = $1`, projectID).Scan(&storedSHA); err != nil {
+		return err
+	}
+	if storedSHA != nil && *storedSHA == f.SHA {
+		_, err := pool.Exec(ctx, `UPDATE projects SET readme_fetched_at = now() WHERE id = $1`, projectID)
+		return err
+	}
+
+	markdown := truncateUTF8(f.Content, ReadmeMaxBytes)
+	sha := f.SHA
+	var html *string
+	if len(f.Content) <= ReadmeMaxBytes {
+		h, err := gh.GetReadmeHTML(ctx, token, fullName)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			html = &h
+		} else {
+			// Keep the markdown but forget the SHA so the next refresh renders it again.
+			sha = ""
+		}
+	}
+	_, err = pool.Exec(ctx, `
+UPDATE projects
+SET readme_markdown = $2, readme_html = $3, readme_path = NULLIF($4, ''), readme_sha = NULLIF($5, ''), readme_fetched_at = now()
+WHERE id = $1
+`, projectID, markdown, html, f.Path, sha)
+	return err
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,259 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// github_accounts.token_status values.
+const (
+	TokenStatusActive  = "active"
+	TokenStatusRevoked = "revoked"
+)
+
+var ErrTokenRevoked = errors.New("github_token_revoked")
+
+const (
+	// Expiring tokens are refreshed once they are within this window of expiry.
+	tokenRefreshWindow = time.Hour
+	// Non-expiring tokens are re-validated against GitHub at most this often.
+	tokenCheckInterval = 24 * time.Hour
+	tokenCheckBatch    = 50
+)
+
+// MarkTokenRevoked flags the user's GitHub token as unusable until they re-link GitHub.
+func MarkTokenRevoked(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `
+UPDATE github_accounts
+SET token_status = 'revoked', token_checked_at = now(), updated_at = now()
+WHERE user_id = $1
+`, userID)
+	return err
+}
+
+// RefreshLinkedToken exchanges the stored refresh token for a new pair and stores both.
+// The row stays locked during the exchange so concurrent refreshes cannot invalidate each other.
+func RefreshLinkedToken(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, tokenEncKeyB64 string, cfg OAuthConfig) error {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var encRefresh []byte
+	var refreshExpiresAt *time.Time
+	err = tx.QueryRow(ctx, `
+SELECT refresh_token, refresh_token_expires_at
+FROM github_accounts
+WHERE user_id = $1 AND token_status = 'active'
+FOR UPDATE
+`, userID).Scan(&encRefresh, &refreshExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("github_not_linked")
+	}
+	if err != nil {
+		return err
+	}
+	if len(encRefresh) == 0 {
+		return fmt.Errorf("no refresh token")
+	}
+
+	now := time.Now().UTC()
+	if refreshExpiresAt != nil && now.After(*refreshExpiresAt) {
+		if _, err := tx.Exec(ctx, `UPDATE github_accounts SET token_status = 'revoked', token_checked_at = now(), updated_at = now() WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		return ErrTokenRevoked
+	}
+
+	refresh, err := cryptox.DecryptAESGCM(key, encRefresh)
+	if err != nil {
+		return fmt.Errorf("decrypt github refresh token failed")
+	}
+	tr, err := RefreshAccessToken(ctx, string(refresh), cfg)
+	if err != nil {
+		slog.Warn("github token refresh failed, marking revoked", "user_id", userID, "error", err)
+		if _, err := tx.Exec(ctx, `UPDATE github_accounts SET token_status = 'revoked', token_checked_at = now(), updated_at = now() WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		return ErrTokenRevoked
+	}
+
+	encToken, encNewRefresh, err := encryptTokenPair(key, tr)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+UPDATE github_accounts
+SET access_token = $2,
+    refresh_token = $3,
+    token_expires_at = $4,
+    refresh_token_expires_at = $5,
+    token_status = 'active',
+    token_checked_at = now(),
+    updated_at = now()
+WHERE user_id = $1
+`, userID, encToken, encNewRefresh, tr.ExpiresAt(now), tr.RefreshTokenExpiresAt(now))
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CheckLinkedToken validates the user's stored token with GitHub, refreshing it first when it is
+// an expiring token close to expiry. Returns the resulting token_status.
+func CheckLinkedToken(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, tokenEncKeyB64 string, cfg OAuthConfig) (string, error) {
+	var hasRefresh bool
+	var expiresAt *time.Time
+	err := pool.QueryRow(ctx, `
+SELECT refresh_token IS NOT NULL, token_expires_at
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&hasRefresh, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("github_not_linked")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if hasRefresh && expiresAt != nil && time.Until(*expiresAt) < tokenRefreshWindow {
+		if err := RefreshLinkedToken(ctx, pool, userID, tokenEncKeyB64, cfg); err != nil {
+			if errors.Is(err, ErrTokenRevoked) {
+				return TokenStatusRevoked, nil
+			}
+			return "", err
+		}
+		return TokenStatusActive, nil
+	}
+
+	linked, err := GetLinkedAccount(ctx, pool, userID, tokenEncKeyB64)
+	if errors.Is(err, ErrTokenRevoked) {
+		return TokenStatusRevoked, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	valid, err := CheckToken(ctx, linked.AccessToken, cfg)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		if err := MarkTokenRevoked(ctx, pool, userID); err != nil {
+			return "", err
+		}
+		return TokenStatusRevoked, nil
+	}
+	if _, err := pool.Exec(ctx, `UPDATE github_accounts SET token_checked_at = now() WHERE user_id = $1`, userID); err != nil {
+		return "", err
+	}
+	return TokenStatusActive, nil
+}
+
+// RunTokenHealthCheck periodically refreshes expiring GitHub tokens and re-validates stale ones,
+// marking revoked tokens so sync jobs and the UI can prompt the owner to re-link.
+// Rows are claimed with SKIP LOCKED, so it is safe to run on every instance. Blocks until ctx is cancelled.
+func RunTokenHealthCheck(ctx context.Context, pool *pgxpool.Pool, tokenEncKeyB64 string, cfg OAuthConfig, interval time.Duration) {
+	if pool == nil || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkTokenBatch(ctx, pool, tokenEncKeyB64, cfg)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkTokenBatch(ctx context.Context, pool *pgxpool.Pool, tokenEncKeyB64 string, cfg OAuthConfig) {
+	// Claim a batch by stamping token_checked_at so other instances skip it.
+	rows, err := pool.Query(ctx, `
+UPDATE github_accounts SET token_checked_at = now()
+WHERE user_id IN (
+  SELECT user_id FROM github_accounts
+  WHERE token_status = 'active'
+    AND (
+      (refresh_token IS NOT NULL AND token_expires_at < now() + $1 * interval '1 second')
+      OR token_checked_at IS NULL
+      OR token_checked_at < now() - $2 * interval '1 second'
+    )
+  ORDER BY token_checked_at NULLS FIRST
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING user_id
+`, int(tokenRefreshWindow.Seconds()), int(tokenCheckInterval.Seconds()), tokenCheckBatch)
+	if err != nil {
+		slog.Warn("github token health check: claim failed", "error", err)
+		return
+	}
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err == nil {
+			userIDs = append(userIDs, id)
+		}
+	}
+	rows.Close()
+
+	revoked := 0
+	for _, id := range userIDs {
+		status, err := CheckLinkedToken(ctx, pool, id, tokenEncKeyB64, cfg)
+		if err != nil {
+			slog.Warn("github token health check failed", "user_id", id, "error", err)
+			continue
+		}
+		if status == TokenStatusRevoked {
+			revoked++
+		}
+	}
+	if len(userIDs) > 0 {
+		slog.Info("github token health check completed",
+			"checked", len(userIDs),
+			"revoked", revoked,
+		)
+	}
+}
+
+func encryptTokenPair(key []byte, tr TokenResponse) (access []byte, refresh []byte, err error) {
+	access, err = cryptox.EncryptAESGCM(key, []byte(tr.AccessToken))
+	if err != nil {
+		return nil, nil, err
+	}
+	if tr.RefreshToken != "" {
+		refresh, err = cryptox.EncryptAESGCM(key, []byte(tr.RefreshToken))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return access, refresh, nil
+}
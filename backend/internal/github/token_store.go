@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -26,17 +27,21 @@ func GetLinkedAccount(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID,
 	var githubUserID int64
 	var login string
 	var encToken []byte
+	var tokenStatus string
 	err := pool.QueryRow(ctx, `
-SELECT github_user_id, login, access_token
+SELECT github_user_id, login, access_token, token_status
 FROM github_accounts
 WHERE user_id = $1
-`, userID).Scan(&githubUserID, &login, &encToken)
+`, userID).Scan(&githubUserID, &login, &encToken, &tokenStatus)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return LinkedAccount{}, fmt.Errorf("github_not_linked")
 	}
 	if err != nil {
 		return LinkedAccount{}, err
 	}
+	if tokenStatus == TokenStatusRevoked {
+		return LinkedAccount{}, ErrTokenRevoked
+	}
 
 	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
 	if err != nil {
@@ -54,23 +59,69 @@ WHERE user_id = $1
 	}, nil
 }
 
+// How a project's repository is accessed.
+const (
+	AuthModeInstallation = "installation" // GitHub App installation token
+	AuthModeOAuth        = "oauth"        // project owner's OAuth token
+)
 
+// ProjectToken is a token for acting on a project's repository.
+type ProjectToken struct {
+	Token          string
+	Mode           string
+	InstallationID string
+}
 
+// GetProjectToken prefers an installation token when the project is linked to a GitHub App
+// installation and apps is configured, and falls back to the owner's OAuth token otherwise.
+func GetProjectToken(ctx context.Context, pool *pgxpool.Pool, apps *InstallationTokenSource, projectID uuid.UUID, tokenEncKeyB64 string) (ProjectToken, error) {
+	if pool == nil {
+		return ProjectToken{}, fmt.Errorf("db not configured")
+	}
 
+	var ownerUserID uuid.UUID
+	var installationID *string
+	err := pool.QueryRow(ctx, `
+SELECT owner_user_id, github_app_installation_id
+FROM projects
+WHERE id = $1
+`, projectID).Scan(&ownerUserID, &installationID)
+	if err != nil {
+		return ProjectToken{}, err
+	}
 
+	if apps != nil && installationID != nil && *installationID != "" {
+		tok, err := apps.Token(ctx, *installationID)
+		if err == nil {
+			return ProjectToken{Token: tok, Mode: AuthModeInstallation, InstallationID: *installationID}, nil
+		}
+		slog.Warn("github installation token failed, falling back to owner oauth token",
+			"project_id", projectID,
+			"installation_id", *installationID,
+			"error", err,
+		)
+	}
 
+	linked, err := GetLinkedAccount(ctx, pool, ownerUserID, tokenEncKeyB64)
+	if err != nil {
+		return ProjectToken{}, err
+	}
+	return ProjectToken{Token: linked.AccessToken, Mode: AuthModeOAuth}, nil
+}
 
-
-
-
-
-
-
-
-
-
-
-
-
-
-
+// SaveAccountProfile stores u's public profile fields on the user's linked GitHub account.
+func SaveAccountProfile(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID, u User) error {
+	_, err := pool.Exec(ctx, `
+UPDATE github_accounts
+SET login = $2,
+    avatar_url = NULLIF($3, ''),
+    name = NULLIF($4, ''),
+    bio = NULLIF($5, ''),
+    company = NULLIF($6, ''),
+    profile_url = NULLIF($7, ''),
+    profile_synced_at = now(),
+    updated_at = now()
+WHERE user_id = $1 AND github_user_id = $8
+`, userID, u.Login, u.AvatarURL, u.Name, u.Bio, u.Company, u.HTMLURL, u.ID)
+	return err
+}
@@ -17,7 +17,13 @@ type CreateWebhookRequest struct {
 }
 
 type Webhook struct {
-	ID int64 `json:"id"`
+	ID     int64    `json:"id"`
+	Active bool     `json:"active"`
+	Events []string `json:"events"`
+	Config struct {
+		URL         string `json:"url"`
+		ContentType string `json:"content_type"`
+	} `json:"config"`
 }
 
 func (c *Client) CreateWebhook(ctx context.Context, accessToken string, fullName string, req CreateWebhookRequest) (Webhook, error) {
@@ -25,7 +31,7 @@ func (c *Client) CreateWebhook(ctx context.Context, accessToken string, fullName
 		return Webhook{}, fmt.Errorf("webhook url and secret are required")
 	}
 	if len(req.Events) == 0 {
-		req.Events = []string{"issues", "pull_request", "pull_request_review", "push"}
+		req.Events = []string{"issues", "pull_request", "pull_request_review", "push", "issue_comment", "pull_request_review_comment", "discussion", "release"}
 	}
 
 	owner, repo, err := splitFullName(fullName)
@@ -78,4 +84,147 @@ func (c *Client) CreateWebhook(ctx context.Context, accessToken string, fullName
 	return wh, nil
 }
 
+// DeleteWebhook removes a repository webhook. A 404 (already gone) is treated as success.
+func (c *Client) DeleteWebhook(ctx context.Context, accessToken string, fullName string, hookID int64) error {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	if hookID <= 0 {
+		return fmt.Errorf("invalid webhook id")
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/hooks/" + fmt.Sprintf("%d", hookID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
+
+// ListWebhooks returns the repository's webhooks (first 100).
+func (c *Client) ListWebhooks(ctx context.Context, accessToken string, fullName string) ([]Webhook, error) {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return nil, err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/hooks?per_page=100"
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, parseGitHubAPIError(resp)
+	}
+	var hooks []Webhook
+	if err := json.NewDecoder(resp.Body).Decode(&hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook rewrites a webhook's config, events and active flag (e.g. after the secret rotates;
+// GitHub never returns the secret, so it cannot be compared).
+func (c *Client) UpdateWebhook(ctx context.Context, accessToken string, fullName string, hookID int64, req CreateWebhookRequest) error {
+	if req.URL == "" || req.Secret == "" {
+		return fmt.Errorf("webhook url and secret are required")
+	}
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/hooks/" + fmt.Sprintf("%d", hookID)
+
+	b, _ := json.Marshal(map[string]any{
+		"active": req.Active,
+		"events": req.Events,
+		"config": map[string]any{
+			"url":          req.URL,
+			"content_type": "json",
+			"secret":       req.Secret,
+			"insecure_ssl": "0",
+		},
+	})
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
+
+// PingWebhook asks GitHub to send a ping event to the webhook.
+func (c *Client) PingWebhook(ctx context.Context, accessToken string, fullName string, hookID int64) error {
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/hooks/" + fmt.Sprintf("%d", hookID) + "/pings"
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
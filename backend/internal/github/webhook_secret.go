@@ -0,0 +1,156 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// NewWebhookSecret returns a random 32-byte secret, hex encoded.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ProjectWebhookSecret returns the project's own webhook secret, or "" when the project still uses
+// the global GITHUB_WEBHOOK_SECRET.
+func ProjectWebhookSecret(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, tokenEncKeyB64 string) (string, error) {
+	var enc []byte
+	err := pool.QueryRow(ctx, `SELECT webhook_secret FROM projects WHERE id = $1`, projectID).Scan(&enc)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return "", nil
+	}
+	return decryptSecret(enc, tokenEncKeyB64)
+}
+
+// WebhookSecretsForRepo returns the secrets a delivery for GitHub repository repoID may be signed
+// with: the project's current secret, plus the previous one while it is within grace of the
+// rotation. ok is false when the repo has no per-project secret (org, app and legacy hooks use the
+// global secret).
+func WebhookSecretsForRepo(ctx context.Context, pool *pgxpool.Pool, repoID int64, tokenEncKeyB64 string, grace time.Duration) (secrets []string, ok bool, err error) {
+	var current, previous []byte
+	var rotatedAt *time.Time
+	err = pool.QueryRow(ctx, `
+SELECT webhook_secret, webhook_secret_previous, webhook_secret_rotated_at
+FROM projects
+WHERE github_repo_id = $1 AND webhook_secret IS NOT NULL AND deleted_at IS NULL
+LIMIT 1
+`, repoID).Scan(&current, &previous, &rotatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	s, err := decryptSecret(current, tokenEncKeyB64)
+	if err != nil {
+		return nil, false, err
+	}
+	secrets = append(secrets, s)
+	if previous != nil && rotatedAt != nil && time.Since(*rotatedAt) < grace {
+		if p, err := decryptSecret(previous, tokenEncKeyB64); err == nil {
+			secrets = append(secrets, p)
+		}
+	}
+	return secrets, true, nil
+}
+
+// SaveWebhookSecret stores secret as the project's current webhook secret and previous as the one
+// still accepted during the rotation grace window.
+func SaveWebhookSecret(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID, secret string, previous string, tokenEncKeyB64 string) error {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+	encCurrent, err := cryptox.EncryptAESGCM(key, []byte(secret))
+	if err != nil {
+		return err
+	}
+	var encPrevious []byte
+	if previous != "" {
+		if encPrevious, err = cryptox.EncryptAESGCM(key, []byte(previous)); err != nil {
+			return err
+		}
+	}
+	_, err = pool.Exec(ctx, `
+UPDATE projects
+SET webhook_secret = $2,
+    webhook_secret_previous = $3,
+    webhook_secret_rotated_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, projectID, encCurrent, encPrevious)
+	return err
+}
+
+func decryptSecret(enc []byte, tokenEncKeyB64 string) (string, error) {
+	key, err := cryptox.KeyFromB64(tokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	b, err := cryptox.DecryptAESGCM(key, enc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// UpdateWebhookConfig rewrites only a repo hook's delivery config (URL and secret), leaving its
+// events and active flag alone.
+func (c *Client) UpdateWebhookConfig(ctx context.Context, accessToken string, fullName string, hookID int64, hookURL string, secret string) error {
+	if hookURL == "" || secret == "" {
+		return fmt.Errorf("webhook url and secret are required")
+	}
+	owner, repo, err := splitFullName(fullName)
+	if err != nil {
+		return err
+	}
+	u := "https://api.github.com/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/hooks/" + fmt.Sprintf("%d", hookID) + "/config"
+
+	b, _ := json.Marshal(map[string]any{
+		"url":          hookURL,
+		"content_type": "json",
+		"secret":       secret,
+		"insecure_ssl": "0",
+	})
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return parseGitHubAPIError(resp)
+	}
+	return nil
+}
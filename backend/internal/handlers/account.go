@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// AccountHandler implements GDPR-style data export and account deletion for the current user.
+type AccountHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewAccountHandler(cfg config.Config, d *db.DB) *AccountHandler {
+	return &AccountHandler{cfg: cfg, db: d}
+}
+
+// Export returns every row owned by the current user as JSON. Secrets (OAuth tokens) are omitted.
+func (h *AccountHandler) Export() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var userJSON, walletsJSON, githubJSON, identitiesJSON, projectsJSON, sessionsJSON, payoutAddressesJSON []byte
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT
+  (SELECT to_jsonb(u) FROM users u WHERE u.id = $1),
+  (SELECT COALESCE(jsonb_agg(to_jsonb(w) - 'user_id'), '[]'::jsonb) FROM wallets w WHERE w.user_id = $1),
+  (SELECT to_jsonb(g) - 'access_token' - 'user_id' FROM github_accounts g WHERE g.user_id = $1),
+  (SELECT COALESCE(jsonb_agg(to_jsonb(oi) - 'user_id'), '[]'::jsonb) FROM oauth_identities oi WHERE oi.user_id = $1),
+  (SELECT COALESCE(jsonb_agg(to_jsonb(p)), '[]'::jsonb) FROM projects p WHERE p.owner_user_id = $1),
+  (SELECT COALESCE(jsonb_agg(to_jsonb(s) - 'user_id'), '[]'::jsonb) FROM auth_sessions s WHERE s.user_id = $1),
+  (SELECT COALESCE(jsonb_agg(to_jsonb(pa) - 'user_id'), '[]'::jsonb) FROM payout_addresses pa WHERE pa.user_id = $1)
+`, userID).Scan(&userJSON, &walletsJSON, &githubJSON, &identitiesJSON, &projectsJSON, &sessionsJSON, &payoutAddressesJSON)
+		if err != nil {
+			slog.Error("account export failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "export_failed"})
+		}
+		if userJSON == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+		}
+
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="grainlify-export.json"`)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"exported_at":      time.Now().UTC(),
+			"user":             json.RawMessage(userJSON),
+			"wallets":          json.RawMessage(walletsJSON),
+			"github_account":   rawOrNull(githubJSON),
+			"oauth_identities": json.RawMessage(identitiesJSON),
+			"projects":         json.RawMessage(projectsJSON),
+			"sessions":         json.RawMessage(sessionsJSON),
+			"payout_addresses": json.RawMessage(payoutAddressesJSON),
+		})
+	}
+}
+
+func rawOrNull(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(b)
+}
+
+type deleteAccountRequest struct {
+	Confirm bool `json:"confirm"`
+	// TransferProjectsTo hands owned projects to another user. If empty, DeleteProjects must be true
+	// when the user owns projects.
+	TransferProjectsTo string `json:"transfer_projects_to"`
+	DeleteProjects     bool   `json:"delete_projects"`
+}
+
+type ownedProject struct {
+	id        uuid.UUID
+	fullName  string
+	webhookID *int64
+}
+
+// Delete purges the current user and all user-owned rows in one transaction.
+// After commit, webhooks of deleted projects are removed and the GitHub OAuth grant is revoked (best effort).
+func (h *AccountHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req deleteAccountRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		if !req.Confirm {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "confirmation_required"})
+		}
+		var transferTo *uuid.UUID
+		if req.TransferProjectsTo != "" {
+			t, err := uuid.Parse(req.TransferProjectsTo)
+			if err != nil || t == userID {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_transfer_target"})
+			}
+			transferTo = &t
+		}
+
+		// Load the GitHub token before the row is deleted; needed for webhook cleanup and grant revocation.
+		linked, linkErr := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_delete_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		rows, err := tx.Query(c.Context(), `
+SELECT id, github_full_name, webhook_id
+FROM projects
+WHERE owner_user_id = $1
+FOR UPDATE
+`, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_delete_failed"})
+		}
+		var owned []ownedProject
+		for rows.Next() {
+			var p ownedProject
+			if err := rows.Scan(&p.id, &p.fullName, &p.webhookID); err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_delete_failed"})
+			}
+			owned = append(owned, p)
+		}
+		rows.Close()
+
+		var deletedProjects []ownedProject
+		if len(owned) > 0 {
+			switch {
+			case transferTo != nil:
+				var exists bool
+				if err := tx.QueryRow(c.Context(), `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, *transferTo).Scan(&exists); err != nil || !exists {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_transfer_target"})
+				}
+				if _, err := tx.Exec(c.Context(), `
+UPDATE projects SET owner_user_id = $2, updated_at = now()
+WHERE owner_user_id = $1
+`, userID, *transferTo); err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_transfer_failed"})
+				}
+				if _, err := tx.Exec(c.Context(), `
+INSERT INTO project_members (project_id, user_id, role, accepted_at)
+SELECT id, $1, 'owner', now() FROM projects WHERE owner_user_id = $1
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'owner', accepted_at = COALESCE(project_members.accepted_at, now()), updated_at = now()
+`, *transferTo); err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_transfer_failed"})
+				}
+			case req.DeleteProjects:
+				if _, err := tx.Exec(c.Context(), `DELETE FROM projects WHERE owner_user_id = $1`, userID); err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_delete_failed"})
+				}
+				deletedProjects = owned
+			default:
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error":          "projects_owned",
+					"message":        "Set transfer_projects_to or delete_projects to handle owned projects",
+					"projects_count": len(owned),
+				})
+			}
+		}
+
+		// Wallets, GitHub account, OAuth identities, sessions, OAuth states and KYC columns go with the user row.
+		if _, err := tx.Exec(c.Context(), `DELETE FROM users WHERE id = $1`, userID); err != nil {
+			slog.Error("account delete failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_delete_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_delete_failed"})
+		}
+
+		var webhooksDeleted int
+		githubRevoked := false
+		if linkErr == nil {
+			gh := github.NewClient()
+			for _, p := range deletedProjects {
+				if p.webhookID == nil {
+					continue
+				}
+				if err := gh.DeleteWebhook(c.Context(), linked.AccessToken, p.fullName, *p.webhookID); err != nil {
+					slog.Warn("account delete: webhook cleanup failed",
+						"project_id", p.id,
+						"github_full_name", p.fullName,
+						"error", err,
+					)
+					continue
+				}
+				webhooksDeleted++
+			}
+			if err := github.RevokeGrant(c.Context(), linked.AccessToken, github.OAuthConfig{
+				ClientID:     h.cfg.GitHubOAuthClientID,
+				ClientSecret: h.cfg.GitHubOAuthClientSecret,
+			}); err != nil {
+				slog.Warn("account delete: github grant revoke failed", "user_id", userID, "error", err)
+			} else {
+				githubRevoked = true
+			}
+		}
+
+		slog.Info("account deleted",
+			"user_id", userID,
+			"projects_transferred", transferTo != nil && len(owned) > 0,
+			"projects_deleted", len(deletedProjects),
+			"webhooks_deleted", webhooksDeleted,
+			"github_grant_revoked", githubRevoked,
+		)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":                   true,
+			"projects_deleted":     len(deletedProjects),
+			"webhooks_deleted":     webhooksDeleted,
+			"github_grant_revoked": githubRevoked,
+		})
+	}
+}
+
+type mergeAccountRequest struct {
+	Confirm bool `json:"confirm"`
+	// SourceToken is a valid login token for the account being merged in, proving the caller controls it.
+	SourceToken string `json:"source_token"`
+	// KYC selects conflict resolution: "auto" (default), "target" or "source".
+	KYC string `json:"kyc,omitempty"`
+}
+
+// Merge folds another account the caller controls (e.g. a wallet-created user) into the current one.
+// The other account is proven by a token obtained by signing in to it; it is deleted after the merge.
+func (h *AccountHandler) Merge() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if actor, _ := c.Locals(auth.LocalActorID).(string); actor != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not_allowed_while_impersonating"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req mergeAccountRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		if !req.Confirm {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "confirmation_required"})
+		}
+		switch req.KYC {
+		case "", auth.MergeKYCAuto, auth.MergeKYCTarget, auth.MergeKYCSource:
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_kyc_strategy"})
+		}
+
+		claims, err := auth.ParseJWT(h.cfg.JWTSecret, req.SourceToken)
+		if err != nil || claims.Actor != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_source_token"})
+		}
+		if claims.ID != "" {
+			sessionID, err := uuid.Parse(claims.ID)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_source_token"})
+			}
+			active, err := auth.SessionActive(c.Context(), h.db.Pool, sessionID)
+			if err != nil {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "session_check_failed"})
+			}
+			if !active {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_source_token"})
+			}
+		}
+		sourceID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_source_token"})
+		}
+		if tv, err := auth.TokenVersion(c.Context(), h.db.Pool, sourceID); err != nil || tv != claims.TokenVersion {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_source_token"})
+		}
+
+		res, err := auth.MergeAccounts(c.Context(), h.db.Pool, userID, sourceID, auth.MergeOptions{KYC: req.KYC})
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrMergeSameUser):
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_merge_same_account"})
+			case errors.Is(err, auth.ErrMergeUserNotFound):
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			case errors.Is(err, auth.ErrMergeGitHubConflict):
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error":   "github_conflict",
+					"message": "Both accounts have a GitHub account linked; unlink one before merging",
+				})
+			default:
+				slog.Error("account merge failed", "target_user_id", userID, "source_user_id", sourceID, "error", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_merge_failed"})
+			}
+		}
+
+		slog.Info("accounts merged",
+			"target_user_id", userID,
+			"source_user_id", sourceID,
+			"merge_id", res.MergeID,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":     true,
+			"merged": res,
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -80,7 +81,11 @@ func (h *AdminHandler) SetUserRole() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
 		}
 		role := strings.TrimSpace(req.Role)
-		if role != "contributor" && role != "maintainer" && role != "admin" {
+		var roleExists bool
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT EXISTS(SELECT 1 FROM roles WHERE name = $1)`, role).Scan(&roleExists); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		if !roleExists {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_role"})
 		}
 		ct, err := h.db.Pool.Exec(c.Context(), `
@@ -93,10 +98,88 @@ WHERE id = $1
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
 		}
+		// Tokens carry the role claim; invalidate them so the new role takes effect immediately.
+		if _, err := auth.BumpTokenVersion(c.Context(), h.db.Pool, userID); err != nil {
+			slog.Error("token invalidation after role change failed", "user_id", userID, "error", err)
+		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
 }
 
+// ForceLogout bumps the user's token_version and revokes their sessions, invalidating every outstanding token.
+func (h *AdminHandler) ForceLogout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+		}
+
+		v, err := auth.BumpTokenVersion(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			if errors.Is(err, auth.ErrUserNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "force_logout_failed"})
+		}
+
+		adminID, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Warn("admin forced logout",
+			"admin_user_id", adminID,
+			"user_id", userID,
+			"token_version", v,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "token_version": v})
+	}
+}
+
+// RotateWebhookSecret queues an immediate rotation of the project's repo webhook secret (e.g. after a
+// suspected leak), ahead of the scheduled rotation.
+func (h *AdminHandler) RotateWebhookSecret() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.TokenEncKeyB64 == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "token_encryption_not_configured"})
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+
+		var webhookID *int64
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT webhook_id FROM projects WHERE id = $1 AND provider = 'github' AND deleted_at IS NULL
+`, projectID).Scan(&webhookID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		if webhookID == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "project_has_no_repo_webhook"})
+		}
+
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at, priority)
+VALUES ($1, 'rotate_webhook_secret', 'pending', now(), 10)
+ON CONFLICT (project_id, job_type) WHERE status = 'pending'
+DO UPDATE SET priority = GREATEST(sync_jobs.priority, EXCLUDED.priority), updated_at = now()
+`, projectID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "enqueue_failed"})
+		}
+
+		adminID, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Warn("admin requested webhook secret rotation", "admin_user_id", adminID, "project_id", projectID)
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true})
+	}
+}
+
 // BootstrapAdmin promotes the currently authenticated user to admin if they know the bootstrap token.
 // This allows any authenticated user with the correct bootstrap token to become an admin.
 //
@@ -136,7 +219,7 @@ func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 
 		// If user is already an admin, no need to update
 		if currentRole == "admin" {
-			jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, "admin", "", "", 60*time.Minute)
+			jwtToken, err := issueSessionJWT(c, h.cfg, h.db, userID, "admin", "", "", h.cfg.TokenTTL("admin", h.cfg.JWTLoginTTL))
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
 			}
@@ -153,7 +236,7 @@ func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bootstrap_failed"})
 		}
 
-		jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, "admin", "", "", 60*time.Minute)
+		jwtToken, err := issueSessionJWT(c, h.cfg, h.db, userID, "admin", "", "", h.cfg.TokenTTL("admin", h.cfg.JWTLoginTTL))
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
 		}
@@ -168,3 +251,66 @@ func (h *AdminHandler) BootstrapAdmin() fiber.Handler {
 
 
 
+
+// Impersonate issues a short-lived token acting as another user for support debugging.
+// The token's act claim records the admin, and every request made with it is written to impersonation_audit.
+// Admin accounts cannot be impersonated, and impersonation tokens cannot start another impersonation.
+func (h *AdminHandler) Impersonate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.JWTSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+		}
+		if actor, _ := c.Locals(auth.LocalActorID).(string); actor != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "already_impersonating"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		targetID, err := uuid.Parse(c.Params("user_id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+		}
+		if targetID == adminID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_impersonate_self"})
+		}
+
+		var targetRole string
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT role FROM users WHERE id = $1`, targetID).Scan(&targetRole); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "impersonation_failed"})
+		}
+		if targetRole == "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot_impersonate_admin"})
+		}
+
+		ttl := 15 * time.Minute
+		token, s, err := auth.IssueImpersonationJWT(c.Context(), h.db.Pool, h.cfg.JWTSecret, adminID, targetID, targetRole, c.Get("User-Agent"), c.IP(), ttl)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+
+		slog.Warn("admin impersonation started",
+			"admin_user_id", adminID,
+			"user_id", targetID,
+			"session_id", s.ID,
+			"expires_at", s.ExpiresAt,
+			"remote_ip", c.IP(),
+		)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token":         token,
+			"user_id":       targetID.String(),
+			"role":          targetRole,
+			"session_id":    s.ID.String(),
+			"expires_at":    s.ExpiresAt,
+			"admin_user_id": adminID.String(),
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -42,6 +43,7 @@ SELECT
   e.links,
   e.key_areas,
   e.technologies,
+  e.parent_id,
   COUNT(p.id) AS project_count,
   COUNT(DISTINCT p.owner_user_id) AS user_count
 FROM ecosystems e
@@ -62,9 +64,10 @@ LIMIT 200
 			var desc, website, logoURL, about *string
 			var linksJSON, keyAreasJSON, technologiesJSON []byte
 			var createdAt, updatedAt time.Time
+			var parentID *uuid.UUID
 			var projectCnt int64
 			var userCnt int64
-			if err := rows.Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON, &projectCnt, &userCnt); err != nil {
+			if err := rows.Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON, &parentID, &projectCnt, &userCnt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
 			}
 			var links, keyAreas, technologies interface{}
@@ -91,6 +94,7 @@ LIMIT 200
 				"links":          links,
 				"key_areas":      keyAreas,
 				"technologies":   technologies,
+				"parent_id":      parentID,
 				"project_count":  projectCnt,
 				"user_count":     userCnt,
 			})
@@ -115,12 +119,13 @@ func (h *EcosystemsAdminHandler) GetByID() fiber.Handler {
 		var desc, website, logoURL, about *string
 		var linksJSON, keyAreasJSON, technologiesJSON []byte
 		var createdAt, updatedAt time.Time
+		var parentID *uuid.UUID
 		err = h.db.Pool.QueryRow(c.Context(), `
 SELECT e.id, e.slug, e.name, e.description, e.website_url, e.logo_url, e.status, e.created_at, e.updated_at,
-       e.about, e.links, e.key_areas, e.technologies
+       e.about, e.links, e.key_areas, e.technologies, e.parent_id
 FROM ecosystems e
 WHERE e.id = $1
-`, ecoID).Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON)
+`, ecoID).Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON, &parentID)
 		if err != nil {
 			if err.Error() == "no rows in result set" {
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
@@ -153,6 +158,7 @@ WHERE e.id = $1
 			"links":          links,
 			"key_areas":      keyAreas,
 			"technologies":   technologies,
+			"parent_id":      parentID,
 			"project_count":  projectCnt,
 			"user_count":     userCnt,
 		})
@@ -170,6 +176,47 @@ type ecosystemUpsertRequest struct {
 	Links        json.RawMessage `json:"links"`        // [{"label":"...","url":"..."}]
 	KeyAreas     json.RawMessage `json:"key_areas"`     // [{"title":"...","description":"..."}]
 	Technologies json.RawMessage `json:"technologies"` // ["..."]
+	// ParentID nests the ecosystem under another one; "" clears it, omitted leaves it unchanged.
+	ParentID *string `json:"parent_id"`
+}
+
+// parseEcosystemParent reads the optional parent_id of an upsert. set is false when the field was
+// omitted; a nil parent with set true means "no parent".
+func parseEcosystemParent(raw *string) (parent *uuid.UUID, set bool, err error) {
+	if raw == nil {
+		return nil, false, nil
+	}
+	v := strings.TrimSpace(*raw)
+	if v == "" {
+		return nil, true, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, true, err
+	}
+	return &id, true, nil
+}
+
+// checkEcosystemParent returns an error code when parentID can't be the parent of ecoID: it must
+// exist, and must not be ecoID itself or one of its descendants (that would close a cycle).
+// ecoID is uuid.Nil for a new ecosystem, which has no descendants yet.
+func (h *EcosystemsAdminHandler) checkEcosystemParent(ctx context.Context, ecoID, parentID uuid.UUID) (string, error) {
+	var exists, cycle bool
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT
+  EXISTS (SELECT 1 FROM ecosystems WHERE id = $2),
+  EXISTS (SELECT 1 FROM ecosystem_closure WHERE ancestor_id = $1 AND descendant_id = $2)
+`, ecoID, parentID).Scan(&exists, &cycle)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "parent_not_found", nil
+	}
+	if cycle {
+		return "ecosystem_parent_cycle", nil
+	}
+	return "", nil
 }
 
 func (h *EcosystemsAdminHandler) Create() fiber.Handler {
@@ -210,13 +257,26 @@ func (h *EcosystemsAdminHandler) Create() fiber.Handler {
 		if len(technologiesJSON) == 0 {
 			technologiesJSON = []byte("[]")
 		}
+		parentID, _, err := parseEcosystemParent(req.ParentID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_parent_id"})
+		}
+		if parentID != nil {
+			code, err := h.checkEcosystemParent(c.Context(), uuid.Nil, *parentID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
+			}
+			if code != "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+			}
+		}
 
 		var id uuid.UUID
-		err := h.db.Pool.QueryRow(c.Context(), `
-INSERT INTO ecosystems (slug, name, description, website_url, logo_url, status, about, links, key_areas, technologies)
-VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), NULLIF($5,''), $6, NULLIF($7,''), $8::jsonb, $9::jsonb, $10::jsonb)
+		err = h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO ecosystems (slug, name, description, website_url, logo_url, status, about, links, key_areas, technologies, parent_id)
+VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), NULLIF($5,''), $6, NULLIF($7,''), $8::jsonb, $9::jsonb, $10::jsonb, $11)
 RETURNING id
-`, slug, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), strings.TrimSpace(req.LogoURL), status, strings.TrimSpace(req.About), linksJSON, keyAreasJSON, technologiesJSON).Scan(&id)
+`, slug, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), strings.TrimSpace(req.LogoURL), status, strings.TrimSpace(req.About), linksJSON, keyAreasJSON, technologiesJSON, parentID).Scan(&id)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
 		}
@@ -268,6 +328,20 @@ func (h *EcosystemsAdminHandler) Update() fiber.Handler {
 			technologiesJSON = []byte("[]")
 		}
 
+		parentID, setParent, err := parseEcosystemParent(req.ParentID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_parent_id"})
+		}
+		if parentID != nil {
+			code, err := h.checkEcosystemParent(c.Context(), ecoID, *parentID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+			}
+			if code != "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+			}
+		}
+
 		aboutVal := strings.TrimSpace(req.About)
 		ct, err := h.db.Pool.Exec(c.Context(), `
 UPDATE ecosystems
@@ -281,9 +355,10 @@ SET slug = COALESCE($2, slug),
     links = COALESCE($9::jsonb, links),
     key_areas = COALESCE($10::jsonb, key_areas),
     technologies = COALESCE($11::jsonb, technologies),
+    parent_id = CASE WHEN $12 THEN $13::uuid ELSE parent_id END,
     updated_at = now()
 WHERE id = $1
-`, ecoID, slugVal, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), strings.TrimSpace(req.LogoURL), status, aboutVal, linksJSON, keyAreasJSON, technologiesJSON)
+`, ecoID, slugVal, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), strings.TrimSpace(req.LogoURL), status, aboutVal, linksJSON, keyAreasJSON, technologiesJSON, setParent, parentID)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
 		}
@@ -312,6 +387,13 @@ func (h *EcosystemsAdminHandler) Delete() fiber.Handler {
 		if projectCount > 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_has_projects", "message": "Cannot delete ecosystem with existing projects"})
 		}
+		var childCount int64
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT COUNT(*) FROM ecosystems WHERE parent_id = $1`, ecoID).Scan(&childCount); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_check_failed"})
+		}
+		if childCount > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_has_children", "message": "Cannot delete ecosystem with child ecosystems"})
+		}
 
 		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM ecosystems WHERE id = $1`, ecoID)
 		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
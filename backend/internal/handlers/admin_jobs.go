@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// JobsAdminHandler gives operators a view of the sync queue: jobs that ran out of retries (to
+// inspect and replay), queue stats, and the worker fleet.
+type JobsAdminHandler struct {
+	db *db.DB
+}
+
+func NewJobsAdminHandler(d *db.DB) *JobsAdminHandler {
+	return &JobsAdminHandler{db: d}
+}
+
+// ListDead returns dead jobs (and failed ones from before retries existed), most recent first.
+// Optional filters: job_type, project_id. Paged with limit (max 200) and offset.
+func (h *JobsAdminHandler) ListDead() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		var projectID *uuid.UUID
+		if v := strings.TrimSpace(c.Query("project_id")); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			}
+			projectID = &id
+		}
+		jobType := strings.TrimSpace(c.Query("job_type"))
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT j.id, j.project_id, p.github_full_name, j.job_type, j.status, j.attempts, j.last_error, j.run_at, j.created_at, j.updated_at
+FROM sync_jobs j
+JOIN projects p ON p.id = j.project_id
+WHERE j.status IN ('dead', 'failed')
+  AND ($1::uuid IS NULL OR j.project_id = $1)
+  AND ($2 = '' OR j.job_type = $2)
+ORDER BY j.updated_at DESC
+LIMIT $3 OFFSET $4
+`, projectID, jobType, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id, pid uuid.UUID
+			var fullName, jt, status string
+			var attempts int
+			var lastErr *string
+			var runAt, createdAt, updatedAt time.Time
+			if err := rows.Scan(&id, &pid, &fullName, &jt, &status, &attempts, &lastErr, &runAt, &createdAt, &updatedAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":               id.String(),
+				"project_id":       pid.String(),
+				"github_full_name": fullName,
+				"job_type":         jt,
+				"status":           status,
+				"attempts":         attempts,
+				"last_error":       lastErr,
+				"run_at":           runAt,
+				"created_at":       createdAt,
+				"updated_at":       updatedAt,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"jobs": out, "limit": limit, "offset": offset})
+	}
+}
+
+// Requeue puts a dead job back in the queue with a fresh set of attempts. The last error is kept
+// until the job runs again.
+func (h *JobsAdminHandler) Requeue() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		jobID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_job_id"})
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `
+UPDATE sync_jobs
+SET status = 'pending', attempts = 0, run_at = now(), locked_at = NULL, locked_by = NULL, updated_at = now()
+WHERE id = $1 AND status IN ('dead', 'failed')
+`, jobID)
+		if db.IsUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "job_already_queued"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "requeue_failed"})
+		}
+		if ct.RowsAffected() == 0 {
+			var status string
+			err := h.db.Pool.QueryRow(c.Context(), `SELECT status FROM sync_jobs WHERE id = $1`, jobID).Scan(&status)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "job_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "requeue_failed"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "job_not_dead", "status": status})
+		}
+
+		adminID, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Info("admin requeued dead sync job", "admin_user_id", adminID, "job_id", jobID)
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true})
+	}
+}
+
+// syncJobStateCounts returns the number of unfinished and dead jobs keyed by job type, then status.
+func syncJobStateCounts(c *fiber.Ctx, d *db.DB) (map[string]map[string]int64, error) {
+	rows, err := d.Pool.Query(c.Context(), `
+SELECT job_type, status, count(*)
+FROM sync_jobs
+WHERE status IN ('pending', 'running', 'retrying', 'dead', 'failed')
+GROUP BY job_type, status
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]map[string]int64{}
+	for rows.Next() {
+		var jobType, status string
+		var n int64
+		if err := rows.Scan(&jobType, &status, &n); err != nil {
+			return nil, err
+		}
+		if out[jobType] == nil {
+			out[jobType] = map[string]int64{}
+		}
+		out[jobType][status] = n
+	}
+	return out, rows.Err()
+}
+
+// oldestDueJobAge returns how long the longest-waiting due job has been waiting, in seconds.
+func oldestDueJobAge(c *fiber.Ctx, d *db.DB) (float64, error) {
+	var age *float64
+	err := d.Pool.QueryRow(c.Context(), `
+SELECT EXTRACT(EPOCH FROM now() - min(run_at))::float8
+FROM sync_jobs
+WHERE status IN ('pending', 'retrying') AND run_at <= now()
+`).Scan(&age)
+	if err != nil || age == nil {
+		return 0, err
+	}
+	return *age, nil
+}
+
+// Stats summarises the queue: current counts per job type and status, the age of the oldest due
+// job, and for attempts finished within window (default 24h) completions, failures, average and
+// p95 runtime, and average queue latency.
+func (h *JobsAdminHandler) Stats() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		window := 24 * time.Hour
+		if v := strings.TrimSpace(c.Query("window")); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 || d > 30*24*time.Hour {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_window"})
+			}
+			window = d
+		}
+
+		counts, err := syncJobStateCounts(c, h.db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_stats_failed"})
+		}
+		oldest, err := oldestDueJobAge(c, h.db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_stats_failed"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT job_type,
+       count(*) FILTER (WHERE status = 'completed'),
+       count(*) FILTER (WHERE status IN ('retrying', 'dead')),
+       avg(EXTRACT(EPOCH FROM finished_at - started_at)) FILTER (WHERE status = 'completed')::float8,
+       (percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM finished_at - started_at)) FILTER (WHERE status = 'completed'))::float8,
+       avg(EXTRACT(EPOCH FROM started_at - run_at)) FILTER (WHERE status = 'completed')::float8
+FROM sync_jobs
+WHERE finished_at > now() - $1 * interval '1 second'
+  AND started_at IS NOT NULL
+GROUP BY job_type
+`, int64(window.Seconds()))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_stats_failed"})
+		}
+		defer rows.Close()
+
+		type finished struct {
+			completed, failed       int64
+			avgRuntime, p95, avgLat *float64
+		}
+		recent := map[string]finished{}
+		for rows.Next() {
+			var jobType string
+			var f finished
+			if err := rows.Scan(&jobType, &f.completed, &f.failed, &f.avgRuntime, &f.p95, &f.avgLat); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_stats_failed"})
+			}
+			recent[jobType] = f
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_stats_failed"})
+		}
+
+		totals := map[string]int64{}
+		types := map[string]bool{}
+		for jt, byStatus := range counts {
+			types[jt] = true
+			for status, n := range byStatus {
+				totals[status] += n
+			}
+		}
+		for jt := range recent {
+			types[jt] = true
+		}
+		byType := []fiber.Map{}
+		for jt := range types {
+			f := recent[jt]
+			byType = append(byType, fiber.Map{
+				"job_type":                  jt,
+				"pending":                   counts[jt]["pending"],
+				"running":                   counts[jt]["running"],
+				"retrying":                  counts[jt]["retrying"],
+				"dead":                      counts[jt]["dead"] + counts[jt]["failed"],
+				"completed":                 f.completed,
+				"failed_attempts":           f.failed,
+				"avg_runtime_seconds":       f.avgRuntime,
+				"p95_runtime_seconds":       f.p95,
+				"avg_queue_latency_seconds": f.avgLat,
+			})
+		}
+		sort.Slice(byType, func(i, j int) bool { return byType[i]["job_type"].(string) < byType[j]["job_type"].(string) })
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"window": window.String(),
+			"totals": fiber.Map{
+				"pending":  totals["pending"],
+				"running":  totals["running"],
+				"retrying": totals["retrying"],
+				"dead":     totals["dead"] + totals["failed"],
+			},
+			"oldest_due_seconds": oldest,
+			"by_job_type":        byType,
+		})
+	}
+}
+
+// workerDeadAfter is how long a worker may go without a heartbeat (beats every 15s) before it is
+// reported dead.
+const workerDeadAfter = time.Minute
+
+// ListWorkers returns the sync worker fleet: each worker's version, current jobs and processed
+// counts, with its state: alive, stopped (clean shutdown) or dead (heartbeat overdue).
+func (h *JobsAdminHandler) ListWorkers() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, hostname, version, concurrency, current_jobs, jobs_completed, jobs_failed, started_at, last_heartbeat_at, stopped_at,
+       last_heartbeat_at > now() - $1 * interval '1 second'
+FROM workers
+ORDER BY last_heartbeat_at DESC
+`, int64(workerDeadAfter.Seconds()))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "workers_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id, hostname, version string
+			var concurrency int
+			var currentJobs []byte
+			var completed, failed int64
+			var startedAt, lastHeartbeat time.Time
+			var stoppedAt *time.Time
+			var fresh bool
+			if err := rows.Scan(&id, &hostname, &version, &concurrency, &currentJobs, &completed, &failed, &startedAt, &lastHeartbeat, &stoppedAt, &fresh); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "workers_list_failed"})
+			}
+			state := "alive"
+			switch {
+			case stoppedAt != nil:
+				state = "stopped"
+			case !fresh:
+				state = "dead"
+			}
+			out = append(out, fiber.Map{
+				"id":                id,
+				"hostname":          hostname,
+				"version":           version,
+				"concurrency":       concurrency,
+				"current_jobs":      json.RawMessage(currentJobs),
+				"jobs_completed":    completed,
+				"jobs_failed":       failed,
+				"started_at":        startedAt,
+				"last_heartbeat_at": lastHeartbeat,
+				"stopped_at":        stoppedAt,
+				"state":             state,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "workers_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"workers": out})
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/didit"
+	"github.com/jagadeesh/grainlify/backend/internal/kyc"
+)
+
+// KYCAdminHandler lets compliance admins review users whose verified KYC session was blocked by
+// screening, and approve or reject them, and lets support reset a user's KYC session.
+type KYCAdminHandler struct {
+	cfg   config.Config
+	db    *db.DB
+	didit *didit.Client
+}
+
+func NewKYCAdminHandler(cfg config.Config, d *db.DB) *KYCAdminHandler {
+	h := &KYCAdminHandler{cfg: cfg, db: d}
+	if cfg.DiditAPIKey != "" {
+		h.didit = didit.NewClient(cfg.DiditAPIKey)
+	}
+	return h
+}
+
+// ListBlocked returns users whose current KYC session is blocked, most recently updated first, with
+// the screening result. Paged with limit (max 200) and offset.
+func (h *KYCAdminHandler) ListBlocked() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT user_id, session_id, screening, updated_at
+FROM user_kyc
+WHERE status = 'blocked'
+ORDER BY updated_at DESC
+LIMIT $1 OFFSET $2
+`, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var userID uuid.UUID
+			var sessionID *string
+			var screening []byte
+			var updatedAt time.Time
+			if err := rows.Scan(&userID, &sessionID, &screening, &updatedAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_list_failed"})
+			}
+			var sc *kyc.Screening
+			if len(screening) > 0 {
+				sc = &kyc.Screening{}
+				_ = json.Unmarshal(screening, sc)
+			}
+			out = append(out, fiber.Map{
+				"user_id":    userID,
+				"session_id": sessionID,
+				"screening":  sc,
+				"updated_at": updatedAt,
+			})
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"users": out})
+	}
+}
+
+// Override decides a blocked KYC session: "approve" makes the user verified, "reject" rejects them.
+// The decision is kept on the session, so later Didit updates of it do not undo it.
+func (h *KYCAdminHandler) Override() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, err := uuid.Parse(c.Params("user_id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+		}
+		var req struct {
+			Decision string `json:"decision"`
+			Reason   string `json:"reason"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		var override string
+		switch strings.TrimSpace(req.Decision) {
+		case "approve":
+			override = "approved"
+		case "reject":
+			override = "rejected"
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_decision"})
+		}
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reason_required"})
+		}
+		adminSub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(adminSub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		ct, err := kyc.UpdateStatus(c.Context(), h.db.Pool, h.cfg.KYCKeyB64(), userID, "admin", nil, `
+UPDATE kyc_sessions
+SET status = CASE WHEN $2 = 'approved' THEN 'verified' ELSE 'rejected' END,
+    verified_at = CASE WHEN $2 = 'approved' THEN now() ELSE verified_at END,
+    screening_override = $2,
+    screening_override_by = $3,
+    screening_override_reason = $4,
+    screening_override_at = now(),
+    updated_at = now()
+WHERE id = (SELECT id FROM user_kyc WHERE user_id = $1)
+  AND status = 'blocked'
+`, userID, override, adminID, reason)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_override_failed"})
+		}
+		if ct.RowsAffected() == 0 {
+			var status *string
+			if err := h.db.Pool.QueryRow(c.Context(), `SELECT status FROM user_kyc WHERE user_id = $1`, userID).Scan(&status); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_override_failed"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "kyc_not_blocked", "status": status})
+		}
+
+		slog.Info("admin overrode kyc screening", "admin_user_id", adminID, "user_id", userID, "decision", override)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "decision": override})
+	}
+}
+
+// DeleteSession resets the user's current KYC session: it is deleted at Didit (cancelling it if
+// still open), then expired locally with its stored results cleared, so the user can start over.
+// The reset is recorded in the user's KYC history.
+func (h *KYCAdminHandler) DeleteSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.didit == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "kyc_not_configured"})
+		}
+		userID, err := uuid.Parse(c.Params("user_id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+		}
+		adminSub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(adminSub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var sessionID *string
+		var status string
+		err = h.db.Pool.QueryRow(c.Context(), `SELECT session_id, status FROM user_kyc WHERE user_id = $1`, userID).Scan(&sessionID, &status)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && (sessionID == nil || status == "expired")) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "kyc_session_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_session_delete_failed"})
+		}
+
+		if err := h.didit.DeleteSession(c.Context(), *sessionID); err != nil {
+			slog.Error("didit session delete failed", "user_id", userID, "session_id", *sessionID, "error", err)
+			if errors.Is(err, didit.ErrUnavailable) {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "didit_unavailable"})
+			}
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "didit_delete_failed"})
+		}
+
+		payload, _ := json.Marshal(fiber.Map{"action": "session_deleted", "admin_user_id": adminID})
+		_, err = kyc.UpdateStatus(c.Context(), h.db.Pool, h.cfg.KYCKeyB64(), userID, "admin", payload, `
+UPDATE kyc_sessions
+SET status = 'expired',
+    data = '{}'::jsonb,
+    pii_enc = NULL,
+    screening = NULL,
+    updated_at = now()
+WHERE session_id = $1
+`, *sessionID)
+		if err != nil {
+			slog.Error("failed to reset kyc session after didit delete", "user_id", userID, "session_id", *sessionID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_session_delete_failed"})
+		}
+
+		slog.Info("admin deleted kyc session", "admin_user_id", adminID, "user_id", userID, "session_id", *sessionID, "previous_status", status)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "session_id": *sessionID})
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/mail"
+)
+
+// ProjectReviewHandler is the admin moderation queue for project submissions: projects that passed
+// verification wait in pending_review until approved (listed publicly) or rejected with a reason.
+// Owners are emailed the decision.
+type ProjectReviewHandler struct {
+	cfg    config.Config
+	db     *db.DB
+	mailer mail.Mailer
+}
+
+func NewProjectReviewHandler(cfg config.Config, d *db.DB, mailer mail.Mailer) *ProjectReviewHandler {
+	return &ProjectReviewHandler{cfg: cfg, db: d, mailer: mailer}
+}
+
+var projectStatuses = map[string]bool{
+	"pending_verification": true,
+	"pending_review":       true,
+	"verified":             true,
+	"rejected":             true,
+	"archived":             true,
+}
+
+// List returns projects in the given status (default pending_review, oldest submission first so the
+// queue is worked in order; other statuses newest first). Paged with limit (max 200) and offset.
+func (h *ProjectReviewHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		status := strings.TrimSpace(c.Query("status", "pending_review"))
+		if !projectStatuses[status] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		order := "p.updated_at DESC"
+		if status == "pending_review" {
+			order = "p.verified_at ASC NULLS LAST, p.created_at ASC"
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), fmt.Sprintf(`
+SELECT p.id, p.github_full_name, p.provider, p.status, p.owner_user_id, ga.login,
+       e.name, p.description, p.language, p.category,
+       p.verified_at, p.reviewed_by, p.reviewed_at, p.review_reason, p.created_at
+FROM projects p
+LEFT JOIN github_accounts ga ON ga.user_id = p.owner_user_id
+LEFT JOIN ecosystems e ON e.id = p.ecosystem_id
+WHERE p.status = $1 AND p.deleted_at IS NULL
+ORDER BY %s
+LIMIT $2 OFFSET $3
+`, order), status, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id, ownerID uuid.UUID
+			var fullName, provider, st string
+			var ownerLogin, ecosystemName, description, language, category, reviewReason *string
+			var verifiedAt, reviewedAt *time.Time
+			var reviewedBy *uuid.UUID
+			var createdAt time.Time
+			if err := rows.Scan(&id, &fullName, &provider, &st, &ownerID, &ownerLogin,
+				&ecosystemName, &description, &language, &category,
+				&verifiedAt, &reviewedBy, &reviewedAt, &reviewReason, &createdAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":               id.String(),
+				"github_full_name": fullName,
+				"provider":         provider,
+				"status":           st,
+				"owner_user_id":    ownerID.String(),
+				"owner_login":      ownerLogin,
+				"ecosystem_name":   ecosystemName,
+				"description":      description,
+				"language":         language,
+				"category":         category,
+				"verified_at":      verifiedAt,
+				"reviewed_by":      reviewedBy,
+				"reviewed_at":      reviewedAt,
+				"review_reason":    reviewReason,
+				"created_at":       createdAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"projects": out, "limit": limit, "offset": offset})
+	}
+}
+
+type projectReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Approve publishes a project waiting in review (or re-admits a rejected one that had passed
+// verification). The reason is optional.
+func (h *ProjectReviewHandler) Approve() fiber.Handler {
+	return h.decide(true)
+}
+
+// Reject takes a project out of the queue, or delists a verified one, with a reason shown to its
+// owner. Re-verifying the project submits it for review again.
+func (h *ProjectReviewHandler) Reject() fiber.Handler {
+	return h.decide(false)
+}
+
+func (h *ProjectReviewHandler) decide(approve bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+		var req projectReviewRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+			}
+		}
+		reason := strings.TrimSpace(req.Reason)
+		if !approve && reason == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reason_required"})
+		}
+		adminSub, _ := c.Locals(auth.LocalUserID).(string)
+		adminID, err := uuid.Parse(adminSub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		query := `
+UPDATE projects
+SET status = 'rejected',
+    approved_at = NULL,
+    reviewed_by = $2,
+    reviewed_at = now(),
+    review_reason = NULLIF($3, ''),
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND status IN ('pending_review', 'verified')
+RETURNING owner_user_id, github_full_name
+`
+		if approve {
+			query = `
+UPDATE projects
+SET status = 'verified',
+    approved_at = now(),
+    reviewed_by = $2,
+    reviewed_at = now(),
+    review_reason = NULLIF($3, ''),
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND status IN ('pending_review', 'rejected') AND verified_at IS NOT NULL
+RETURNING owner_user_id, github_full_name
+`
+		}
+		var ownerID uuid.UUID
+		var fullName string
+		err = h.db.Pool.QueryRow(c.Context(), query, projectID, adminID, reason).Scan(&ownerID, &fullName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			var status string
+			err := h.db.Pool.QueryRow(c.Context(), `
+SELECT status FROM projects WHERE id = $1 AND deleted_at IS NULL
+`, projectID).Scan(&status)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_review_failed"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "project_not_reviewable", "status": status})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_review_failed"})
+		}
+
+		status := "rejected"
+		if approve {
+			status = "verified"
+		}
+		slog.Info("admin reviewed project",
+			"admin_user_id", adminID,
+			"project_id", projectID,
+			"repo", fullName,
+			"status", status,
+		)
+		go h.notifyOwner(context.Background(), ownerID, fullName, approve, reason)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "status": status})
+	}
+}
+
+// notifyOwner emails the review decision to the project owner: their verified login email when they
+// have one, otherwise the primary email of their GitHub account. Best effort; failures are logged.
+func (h *ProjectReviewHandler) notifyOwner(ctx context.Context, ownerID uuid.UUID, fullName string, approved bool, reason string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	to, err := h.ownerEmail(ctx, ownerID)
+	if err != nil || to == "" {
+		slog.Warn("project review: owner has no email, not notified", "user_id", ownerID, "repo", fullName, "error", err)
+		return
+	}
+
+	link := strings.TrimRight(h.cfg.FrontendBaseURL, "/")
+	var msg mail.Message
+	if approved {
+		msg = mail.Message{
+			To:      to,
+			Subject: "Your project " + fullName + " is now listed on Grainlify",
+			Text:    "Good news: " + fullName + " was approved and now appears in the Grainlify project catalog.\n\n" + link,
+		}
+	} else {
+		msg = mail.Message{
+			To:      to,
+			Subject: "Your project " + fullName + " was not approved",
+			Text: "An administrator reviewed " + fullName + " and did not approve it for the Grainlify catalog.\n\n" +
+				"Reason: " + reason + "\n\n" +
+				"You can address this and verify the project again to resubmit it.\n\n" + link,
+		}
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		slog.Warn("project review: owner notification failed", "user_id", ownerID, "repo", fullName, "error", err)
+	}
+}
+
+func (h *ProjectReviewHandler) ownerEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email string
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT email FROM oauth_identities
+WHERE user_id = $1 AND email_verified AND email IS NOT NULL AND email <> ''
+ORDER BY updated_at DESC
+LIMIT 1
+`, userID).Scan(&email)
+	if err == nil {
+		return email, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	account, err := github.GetLinkedAccount(ctx, h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	return github.NewClient().GetPrimaryEmail(ctx, account.AccessToken)
+}
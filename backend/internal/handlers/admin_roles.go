@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// RolesAdminHandler manages roles and the role/permission matrix.
+type RolesAdminHandler struct {
+	db *db.DB
+}
+
+func NewRolesAdminHandler(d *db.DB) *RolesAdminHandler {
+	return &RolesAdminHandler{db: d}
+}
+
+var roleNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+func (h *RolesAdminHandler) ListPermissions() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT key, COALESCE(description, '')
+FROM permissions
+ORDER BY key
+`)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "permissions_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var key, desc string
+			if err := rows.Scan(&key, &desc); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "permissions_list_failed"})
+			}
+			out = append(out, fiber.Map{"key": key, "description": desc})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"permissions": out})
+	}
+}
+
+// ListRoles returns every role with its granted permissions.
+func (h *RolesAdminHandler) ListRoles() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT r.name, COALESCE(r.description, ''), r.builtin, r.created_at,
+       COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+FROM roles r
+LEFT JOIN role_permissions rp ON rp.role = r.name
+GROUP BY r.name
+ORDER BY r.builtin DESC, r.name
+`)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "roles_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var name, desc string
+			var builtin bool
+			var createdAt time.Time
+			var perms []string
+			if err := rows.Scan(&name, &desc, &builtin, &createdAt, &perms); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "roles_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"name":        name,
+				"description": desc,
+				"builtin":     builtin,
+				"permissions": perms,
+				"created_at":  createdAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"roles": out})
+	}
+}
+
+type createRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (h *RolesAdminHandler) CreateRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		var req createRoleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		name := strings.ToLower(strings.TrimSpace(req.Name))
+		if !roleNameRe.MatchString(name) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_role_name"})
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `
+INSERT INTO roles (name, description) VALUES ($1, NULLIF($2, ''))
+ON CONFLICT (name) DO NOTHING
+`, name, strings.TrimSpace(req.Description))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_create_failed"})
+		}
+		if ct.RowsAffected() == 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "role_exists"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"name": name})
+	}
+}
+
+// DeleteRole removes a custom role. Built-in roles and roles still assigned to users cannot be deleted.
+func (h *RolesAdminHandler) DeleteRole() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		name := c.Params("role")
+
+		var builtin bool
+		var inUse bool
+		err := h.db.Pool.QueryRow(c.Context(), `
+SELECT r.builtin, EXISTS(SELECT 1 FROM users u WHERE u.role = r.name)
+FROM roles r
+WHERE r.name = $1
+`, name).Scan(&builtin, &inUse)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "role_not_found"})
+		}
+		if builtin {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "role_builtin"})
+		}
+		if inUse {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "role_in_use"})
+		}
+
+		if _, err := h.db.Pool.Exec(c.Context(), `DELETE FROM roles WHERE name = $1`, name); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_delete_failed"})
+		}
+		auth.InvalidatePermissions()
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+type setRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// SetRolePermissions replaces the permission set granted to a role.
+func (h *RolesAdminHandler) SetRolePermissions() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		name := c.Params("role")
+
+		var req setRolePermissionsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		perms := make([]string, 0, len(req.Permissions))
+		seen := map[string]struct{}{}
+		for _, p := range req.Permissions {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			perms = append(perms, p)
+		}
+		sort.Strings(perms)
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var exists bool
+		if err := tx.QueryRow(c.Context(), `SELECT EXISTS(SELECT 1 FROM roles WHERE name = $1)`, name).Scan(&exists); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		if !exists {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "role_not_found"})
+		}
+
+		var known int
+		if err := tx.QueryRow(c.Context(), `SELECT COUNT(*) FROM permissions WHERE key = ANY($1)`, perms).Scan(&known); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		if known != len(perms) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unknown_permission"})
+		}
+
+		if _, err := tx.Exec(c.Context(), `DELETE FROM role_permissions WHERE role = $1`, name); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO role_permissions (role, permission)
+SELECT $1, unnest($2::text[])
+`, name, perms); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "role_update_failed"})
+		}
+
+		auth.InvalidatePermissions()
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"role": name, "permissions": perms})
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
+)
+
+// TagsAdminHandler manages the canonical tag catalog: listing tags with their aliases and usage,
+// creating tags, adding aliases and merging near-duplicates.
+type TagsAdminHandler struct {
+	db *db.DB
+}
+
+func NewTagsAdminHandler(d *db.DB) *TagsAdminHandler {
+	return &TagsAdminHandler{db: d}
+}
+
+// List returns tags with their aliases and project counts, most used first. q filters by slug or
+// alias prefix. Paged with limit (max 500) and offset.
+func (h *TagsAdminHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		limit := c.QueryInt("limit", 100)
+		if limit <= 0 || limit > 500 {
+			limit = 100
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+		q := tags.Normalize(c.Query("q"))
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT t.id, t.slug, t.name, t.created_at, t.updated_at,
+       COALESCE((SELECT array_agg(a.alias ORDER BY a.alias) FROM tag_aliases a WHERE a.tag_id = t.id AND a.alias <> t.slug), ARRAY[]::TEXT[]),
+       (SELECT COUNT(*) FROM project_tags pt WHERE pt.tag_id = t.id) AS project_count
+FROM tags t
+WHERE $1 = '' OR EXISTS (SELECT 1 FROM tag_aliases a WHERE a.tag_id = t.id AND a.alias LIKE $1 || '%')
+ORDER BY project_count DESC, t.slug ASC
+LIMIT $2 OFFSET $3
+`, q, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tags_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id uuid.UUID
+			var slug, name string
+			var createdAt, updatedAt time.Time
+			var aliases []string
+			var projectCount int64
+			if err := rows.Scan(&id, &slug, &name, &createdAt, &updatedAt, &aliases, &projectCount); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tags_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":            id.String(),
+				"slug":          slug,
+				"name":          name,
+				"aliases":       aliases,
+				"project_count": projectCount,
+				"created_at":    createdAt,
+				"updated_at":    updatedAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"tags": out, "limit": limit, "offset": offset})
+	}
+}
+
+type createTagRequest struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// Create adds a tag. Its slug is the normalized name; aliases are extra spellings that resolve to it.
+func (h *TagsAdminHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		var req createTagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		name := strings.TrimSpace(req.Name)
+		slug := tags.Normalize(name)
+		if slug == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_required"})
+		}
+		aliases := []string{slug}
+		for _, a := range req.Aliases {
+			if a = tags.Normalize(a); a != "" && a != slug {
+				aliases = append(aliases, a)
+			}
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_create_failed"})
+		}
+		defer tx.Rollback(c.Context())
+
+		var id uuid.UUID
+		err = tx.QueryRow(c.Context(), `
+INSERT INTO tags (slug, name) VALUES ($1, $2)
+ON CONFLICT (slug) DO NOTHING
+RETURNING id
+`, slug, name).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "tag_exists", "slug": slug})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_create_failed"})
+		}
+		for _, a := range aliases {
+			ct, err := tx.Exec(c.Context(), `
+INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2)
+ON CONFLICT (alias) DO NOTHING
+`, a, id)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_create_failed"})
+			}
+			if ct.RowsAffected() == 0 {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "alias_in_use", "alias": a})
+			}
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_create_failed"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id.String(), "slug": slug, "name": name, "aliases": aliases[1:]})
+	}
+}
+
+type addTagAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// AddAlias makes another spelling resolve to the tag for future saves and filters. Projects already
+// carrying a separate tag under that spelling are not touched; merge that tag instead.
+func (h *TagsAdminHandler) AddAlias() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		tagID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_tag_id"})
+		}
+		var req addTagAliasRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		alias := tags.Normalize(req.Alias)
+		if alias == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "alias_required"})
+		}
+
+		var owner uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+WITH ins AS (
+  INSERT INTO tag_aliases (alias, tag_id)
+  SELECT $1, id FROM tags WHERE id = $2
+  ON CONFLICT (alias) DO NOTHING
+  RETURNING tag_id
+)
+SELECT tag_id FROM ins
+UNION ALL
+SELECT tag_id FROM tag_aliases WHERE alias = $1
+LIMIT 1
+`, alias, tagID).Scan(&owner)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "tag_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_alias_failed"})
+		}
+		if owner != tagID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "alias_in_use", "alias": alias, "tag_id": owner.String()})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "alias": alias})
+	}
+}
+
+type mergeTagRequest struct {
+	TargetID string `json:"target_id"`
+}
+
+// Merge folds the tag into target_id: its spellings resolve to the target from now on and every
+// project carrying it is retagged.
+func (h *TagsAdminHandler) Merge() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sourceID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_tag_id"})
+		}
+		var req mergeTagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		targetID, err := uuid.Parse(strings.TrimSpace(req.TargetID))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_target_id"})
+		}
+		if targetID == sourceID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_merge_into_self"})
+		}
+
+		n, err := tags.Merge(c.Context(), h.db.Pool, sourceID, targetID)
+		if errors.Is(err, tags.ErrTagNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "tag_not_found"})
+		}
+		if err != nil {
+			slog.Error("tag merge failed", "source_id", sourceID, "target_id", targetID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tag_merge_failed"})
+		}
+
+		adminSub, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Info("admin merged tags",
+			"admin_user_id", adminSub,
+			"source_id", sourceID,
+			"target_id", targetID,
+			"projects_retagged", n,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "projects_retagged": n})
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+// AssetsHandler serves the assets table: the Stellar assets bounties can be in, with the metadata
+// and USD prices used to show their values.
+type AssetsHandler struct {
+	db *db.DB
+}
+
+func NewAssetsHandler(d *db.DB) *AssetsHandler {
+	return &AssetsHandler{db: d}
+}
+
+// List returns every supported asset, by code.
+func (h *AssetsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		rows, err := h.db.Pool.Query(c.Context(), `SELECT `+assets.Columns+` FROM assets ORDER BY code`)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "assets_list_failed"})
+		}
+		defer rows.Close()
+		out := []assets.Asset{}
+		for rows.Next() {
+			a, err := assets.Scan(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "assets_list_failed"})
+			}
+			out = append(out, a)
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "assets_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"assets": out})
+	}
+}
+
+type updateAssetRequest struct {
+	Issuer   *string `json:"issuer"`
+	Name     *string `json:"name"`
+	Symbol   *string `json:"symbol"`
+	Decimals *int    `json:"decimals"`
+	// USDPrice sets a price by hand; it does not expire and stays until the price source replaces
+	// it. An empty string clears the price.
+	USDPrice *string `json:"usd_price"`
+}
+
+// Update creates or edits an asset. Fields left out keep their value; a new asset defaults to its
+// code as symbol and 7 decimals.
+func (h *AssetsHandler) Update() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		code := strings.ToUpper(strings.TrimSpace(c.Params("code")))
+		if code == "" || len(code) > 12 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_asset_code"})
+		}
+		var req updateAssetRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		if req.Issuer != nil {
+			*req.Issuer = strings.TrimSpace(*req.Issuer)
+			if code == stellar.NativeAsset && *req.Issuer != "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "native_asset_has_no_issuer"})
+			}
+			if *req.Issuer != "" && !stellar.ValidAccount(*req.Issuer) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_issuer"})
+			}
+		}
+		if req.Name != nil {
+			*req.Name = strings.TrimSpace(*req.Name)
+			if len(*req.Name) > 100 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_too_long"})
+			}
+		}
+		if req.Symbol != nil {
+			*req.Symbol = strings.TrimSpace(*req.Symbol)
+			if *req.Symbol == "" || len(*req.Symbol) > 12 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_symbol"})
+			}
+		}
+		if req.Decimals != nil && (*req.Decimals < 0 || *req.Decimals > 18) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_decimals"})
+		}
+		var price *string
+		clearPrice := false
+		if req.USDPrice != nil {
+			p := strings.TrimSpace(*req.USDPrice)
+			switch {
+			case p == "":
+				clearPrice = true
+			case !assets.ValidPrice(p):
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_usd_price"})
+			default:
+				price = &p
+			}
+		}
+
+		a, err := assets.Scan(h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO assets (code, issuer, name, symbol, decimals, usd_price, price_source, price_updated_at)
+VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), COALESCE($4, $1), COALESCE($5, 7), $6::numeric,
+  CASE WHEN $6 IS NOT NULL THEN 'manual' END, CASE WHEN $6 IS NOT NULL THEN now() END)
+ON CONFLICT (code) DO UPDATE SET
+  issuer = CASE WHEN $2::text IS NULL THEN assets.issuer ELSE NULLIF($2, '') END,
+  name = CASE WHEN $3::text IS NULL THEN assets.name ELSE NULLIF($3, '') END,
+  symbol = COALESCE($4, assets.symbol),
+  decimals = COALESCE($5, assets.decimals),
+  usd_price = CASE WHEN $6 IS NOT NULL THEN $6::numeric WHEN $7 THEN NULL ELSE assets.usd_price END,
+  price_source = CASE WHEN $6 IS NOT NULL THEN 'manual' WHEN $7 THEN NULL ELSE assets.price_source END,
+  price_updated_at = CASE WHEN $6 IS NOT NULL THEN now() WHEN $7 THEN NULL ELSE assets.price_updated_at END,
+  price_expires_at = CASE WHEN $6 IS NOT NULL OR $7 THEN NULL ELSE assets.price_expires_at END,
+  updated_at = now()
+RETURNING `+assets.Columns, code, req.Issuer, req.Name, req.Symbol, req.Decimals, price, clearPrice))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "asset_update_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(a)
+	}
+}
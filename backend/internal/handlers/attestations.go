@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/attestation"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// attestationMaxDays caps the period one attestation covers.
+const attestationMaxDays = 5 * 366
+
+// AttestationsHandler issues signed contribution attestations and publishes the keys to check them.
+type AttestationsHandler struct {
+	cfg    config.Config
+	db     *db.DB
+	signer *attestation.Signer
+}
+
+func NewAttestationsHandler(cfg config.Config, d *db.DB) *AttestationsHandler {
+	signer, err := attestation.NewSigner(cfg.AttestationSigningKeyB64, cfg.AttestationRetiredPublicKeys)
+	if err != nil {
+		slog.Error("attestation signing key invalid; attestations disabled", "error", err)
+		signer = nil
+	}
+	return &AttestationsHandler{cfg: cfg, db: d, signer: signer}
+}
+
+// Issue signs the authenticated user's contribution stats for ?from= to ?to= (inclusive UTC dates,
+// default the last 365 days up to today).
+func (h *AttestationsHandler) Issue() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.signer == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "attestations_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		to, err := parseAttestationDate(c.Query("to"), today)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_to", "message": "to must be a YYYY-MM-DD date"})
+		}
+		from, err := parseAttestationDate(c.Query("from"), to.AddDate(0, 0, -364))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_from", "message": "from must be a YYYY-MM-DD date"})
+		}
+		if to.After(today) || from.After(to) || to.Sub(from) >= attestationMaxDays*24*time.Hour {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_period", "message": "from must not be after to, to must not be in the future, and the period is at most 5 years"})
+		}
+
+		var githubUserID int64
+		var login string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT github_user_id, login
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&githubUserID, &login)
+		if err != nil || login == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+		}
+
+		claims := attestation.Claims{
+			GitHubUserID: githubUserID,
+			From:         from.Format("2006-01-02"),
+			To:           to.Format("2006-01-02"),
+			Stats:        attestation.Stats{Ecosystems: []string{}},
+		}
+		end := to.AddDate(0, 0, 1)
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT COUNT(*) FILTER (WHERE c.kind = 'issue'),
+       COUNT(*) FILTER (WHERE c.kind = 'pull_request'),
+       COUNT(*) FILTER (WHERE c.kind = 'commit'),
+       COUNT(DISTINCT c.project_id) FILTER (WHERE c.kind IN ('issue', 'pull_request')),
+       (SELECT COUNT(*) FROM github_pull_requests pr
+        JOIN projects mp ON mp.id = pr.project_id
+        WHERE pr.author_login = $1 AND pr.merged IS TRUE
+          AND pr.merged_at_github >= $2 AND pr.merged_at_github < $3
+          AND mp.status IN ('verified', 'archived'))
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+WHERE c.author_login = $1 AND c.occurred_at >= $2 AND c.occurred_at < $3
+  AND p.status IN ('verified', 'archived')
+`, login, from, end).Scan(&claims.Stats.Issues, &claims.Stats.PullRequests, &claims.Stats.Commits,
+			&claims.Stats.Projects, &claims.Stats.MergedPullRequests)
+		if err != nil {
+			slog.Error("attestation: stats failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attestation_failed"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT DISTINCT e.name
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+JOIN ecosystems e ON e.id = p.ecosystem_id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND c.occurred_at >= $2 AND c.occurred_at < $3
+  AND p.status IN ('verified', 'archived')
+ORDER BY e.name
+`, login, from, end)
+		if err != nil {
+			slog.Error("attestation: ecosystems failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attestation_failed"})
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attestation_failed"})
+			}
+			claims.Stats.Ecosystems = append(claims.Stats.Ecosystems, name)
+		}
+		rows.Close()
+
+		token, signed, err := h.signer.Sign(login, claims, now)
+		if err != nil {
+			slog.Error("attestation: signing failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attestation_failed"})
+		}
+		slog.Info("attestation issued", "user_id", userID, "login", login, "jti", signed.ID, "from", signed.From, "to", signed.To)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"attestation": token,
+			"kid":         h.signer.Keys()[0].KeyID,
+			"claims":      signed,
+		})
+	}
+}
+
+// Keys publishes the attestation verification keys as a JWK set.
+func (h *AttestationsHandler) Keys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.signer == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "attestations_not_configured"})
+		}
+		c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"keys": h.signer.Keys()})
+	}
+}
+
+// Verify checks an attestation's signature. Invalid attestations get 200 with valid=false and the
+// reason, so verifiers can tell a bad attestation from a bad request.
+func (h *AttestationsHandler) Verify() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.signer == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "attestations_not_configured"})
+		}
+		var req struct {
+			Attestation string `json:"attestation"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		token := strings.TrimSpace(req.Attestation)
+		if token == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "attestation_required"})
+		}
+
+		claims, kid, err := h.signer.Verify(token)
+		if err != nil {
+			reason := "invalid_attestation"
+			if errors.Is(err, attestation.ErrUnknownKey) {
+				reason = "unknown_key"
+			}
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"valid": false, "kid": kid, "reason": reason})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"valid": true, "kid": kid, "claims": claims})
+	}
+}
+
+func parseAttestationDate(s string, fallback time.Time) (time.Time, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
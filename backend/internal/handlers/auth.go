@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -25,6 +27,8 @@ func NewAuthHandler(cfg config.Config, d *db.DB) *AuthHandler {
 type nonceRequest struct {
 	WalletType string `json:"wallet_type"`
 	Address    string `json:"address"`
+	// ChainID is optional; defaults per wallet type (e.g. "1" for EVM, "stellar:pubnet").
+	ChainID string `json:"chain_id,omitempty"`
 }
 
 func (h *AuthHandler) Nonce() fiber.Handler {
@@ -52,11 +56,11 @@ func (h *AuthHandler) Nonce() fiber.Handler {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "nonce_create_failed"})
 		}
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"nonce":      n.Nonce,
-			"message":    auth.LoginMessage(n.Nonce),
-			"expires_at": n.ExpiresAt,
-		})
+		resp, ok := signInChallenge(c, h.cfg, wType, addr, req.ChainID, auth.LoginStatement, n)
+		if !ok {
+			return nil
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
 	}
 }
 
@@ -64,8 +68,12 @@ type verifyRequest struct {
 	WalletType string `json:"wallet_type"`
 	Address    string `json:"address"`
 	Nonce      string `json:"nonce"`
-	Signature  string `json:"signature"`
-	PublicKey  string `json:"public_key,omitempty"`
+	// Message is the exact structured message returned by the nonce endpoint, as signed.
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	PublicKey string `json:"public_key,omitempty"`
+	// SignatureType selects the EVM scheme: "eip191" (personal_sign, default) or "eip712".
+	SignatureType string `json:"signature_type,omitempty"`
 }
 
 func (h *AuthHandler) Verify() fiber.Handler {
@@ -94,21 +102,8 @@ func (h *AuthHandler) Verify() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_nonce_or_signature"})
 		}
 
-		// Be tolerant during early dev: accept both the current canonical message and the
-		// legacy newline message (so signing tools that copied `\n` vs newline don't block you).
-		msgs := []string{
-			auth.LoginMessage(req.Nonce),
-			auth.LegacyLoginMessage(req.Nonce),
-		}
-		var sigOK bool
-		for _, msg := range msgs {
-			if err := auth.VerifySignature(wType, addr, msg, req.Signature, req.PublicKey); err == nil {
-				sigOK = true
-				break
-			}
-		}
-		if !sigOK {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+		if !checkSignInMessage(c, h.cfg, wType, addr, req, auth.LoginStatement) {
+			return nil
 		}
 
 		res, err := auth.ConsumeNonceAndUpsertUser(c.Context(), h.db.Pool, wType, addr, req.Nonce, req.PublicKey)
@@ -119,7 +114,7 @@ func (h *AuthHandler) Verify() fiber.Handler {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_failed"})
 		}
 
-		token, err := auth.IssueJWT(h.cfg.JWTSecret, res.User.ID, res.User.Role, res.Wallet.WalletType, res.Wallet.Address, 15*time.Minute)
+		token, err := issueSessionJWT(c, h.cfg, h.db, res.User.ID, res.User.Role, res.Wallet.WalletType, res.Wallet.Address, h.cfg.TokenTTL(res.User.Role, h.cfg.JWTWalletTTL))
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
 		}
@@ -210,68 +205,26 @@ WHERE id = $1
 				} else if ghUser.Blog != "" {
 					githubMap["website"] = ghUser.Blog
 				}
+				if ghUser.Company != "" {
+					githubMap["company"] = ghUser.Company
+				}
+				if ghUser.HTMLURL != "" {
+					githubMap["profile_url"] = ghUser.HTMLURL
+				}
 				response["github"] = githubMap
+				// Keep the stored profile current (best effort).
+				if err := github.SaveAccountProfile(c.Context(), h.db.Pool, userID, ghUser); err != nil {
+					slog.Warn("failed to store github profile", "error", err, "user_id", userID)
+				}
 			} else {
 				// Fallback to database values if GitHub API fails
-				var githubLogin *string
-				var githubAvatarURL *string
-				_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT login, avatar_url
-FROM github_accounts
-WHERE user_id = $1
-`, userID).Scan(&githubLogin, &githubAvatarURL)
-				if githubLogin != nil {
-					githubMap := fiber.Map{
-						"login": *githubLogin,
-					}
-					// Use database avatar_url if set, otherwise use GitHub account avatar
-					if avatarURL != nil && *avatarURL != "" {
-						githubMap["avatar_url"] = *avatarURL
-					} else if githubAvatarURL != nil && *githubAvatarURL != "" {
-						githubMap["avatar_url"] = *githubAvatarURL
-					}
-					// Add profile fields from database
-					if location != nil && *location != "" {
-						githubMap["location"] = *location
-					}
-					if bio != nil && *bio != "" {
-						githubMap["bio"] = *bio
-					}
-					if website != nil && *website != "" {
-						githubMap["website"] = *website
-					}
+				if githubMap := h.storedGitHubProfile(c.Context(), userID, avatarURL, location, bio, website); githubMap != nil {
 					response["github"] = githubMap
 				}
 			}
 		} else {
 			// No GitHub account linked, try to get from database anyway
-			var githubLogin *string
-			var githubAvatarURL *string
-			_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT login, avatar_url
-FROM github_accounts
-WHERE user_id = $1
-`, userID).Scan(&githubLogin, &githubAvatarURL)
-			if githubLogin != nil {
-				githubMap := fiber.Map{
-					"login": *githubLogin,
-				}
-				// Use database avatar_url if set, otherwise use GitHub account avatar
-				if avatarURL != nil && *avatarURL != "" {
-					githubMap["avatar_url"] = *avatarURL
-				} else if githubAvatarURL != nil && *githubAvatarURL != "" {
-					githubMap["avatar_url"] = *githubAvatarURL
-				}
-				// Add profile fields from database
-				if location != nil && *location != "" {
-					githubMap["location"] = *location
-				}
-				if bio != nil && *bio != "" {
-					githubMap["bio"] = *bio
-				}
-				if website != nil && *website != "" {
-					githubMap["website"] = *website
-				}
+			if githubMap := h.storedGitHubProfile(c.Context(), userID, avatarURL, location, bio, website); githubMap != nil {
 				response["github"] = githubMap
 			}
 		}
@@ -303,6 +256,40 @@ WHERE user_id = $1
 	}
 }
 
+// storedGitHubProfile builds the /me github object from the stored account, for when GitHub can't be
+// asked. Profile fields the user edited take precedence over GitHub's. Returns nil when no account is linked.
+func (h *AuthHandler) storedGitHubProfile(ctx context.Context, userID uuid.UUID, avatarURL, location, bio, website *string) fiber.Map {
+	var login string
+	var ghAvatarURL, name, ghBio, company, profileURL *string
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT login, avatar_url, name, bio, company, profile_url
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&login, &ghAvatarURL, &name, &ghBio, &company, &profileURL)
+	if err != nil {
+		return nil
+	}
+	githubMap := fiber.Map{
+		"login": login,
+	}
+	set := func(key string, vals ...*string) {
+		for _, v := range vals {
+			if v != nil && *v != "" {
+				githubMap[key] = *v
+				return
+			}
+		}
+	}
+	set("avatar_url", avatarURL, ghAvatarURL)
+	set("name", name)
+	set("location", location)
+	set("bio", bio, ghBio)
+	set("website", website)
+	set("company", company)
+	set("profile_url", profileURL)
+	return githubMap
+}
+
 // ResyncGitHubProfile fetches fresh GitHub profile data including email
 func (h *AuthHandler) ResyncGitHubProfile() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -338,12 +325,7 @@ func (h *AuthHandler) ResyncGitHubProfile() fiber.Handler {
 		}
 
 		// Update github_accounts table with fresh data
-		_, err = h.db.Pool.Exec(c.Context(), `
-UPDATE github_accounts
-SET login = $1, avatar_url = $2, updated_at = now()
-WHERE user_id = $3
-`, ghUser.Login, ghUser.AvatarURL, userID)
-		if err != nil {
+		if err := github.SaveAccountProfile(c.Context(), h.db.Pool, userID, ghUser); err != nil {
 			slog.Error("failed to update github_accounts", "error", err, "user_id", userID)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "update_failed"})
 		}
@@ -370,6 +352,12 @@ WHERE user_id = $3
 		if ghUser.Blog != "" {
 			githubMap["website"] = ghUser.Blog
 		}
+		if ghUser.Company != "" {
+			githubMap["company"] = ghUser.Company
+		}
+		if ghUser.HTMLURL != "" {
+			githubMap["profile_url"] = ghUser.HTMLURL
+		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"github": githubMap,
@@ -377,4 +365,51 @@ WHERE user_id = $3
 	}
 }
 
+// signInChallenge builds the structured message for a freshly created nonce. It writes a 400
+// response and returns ok=false if the requested chain does not fit the wallet type.
+func signInChallenge(c *fiber.Ctx, cfg config.Config, wType auth.WalletType, addr string, chainID string, statement string, n auth.Nonce) (fiber.Map, bool) {
+	domain, uri := cfg.SignInDomain()
+	msg := auth.NewSignInMessage(domain, uri, wType, addr, statement, strings.TrimSpace(chainID), n.Nonce, n.ExpiresAt)
+	if err := msg.Validate(domain, wType, addr, n.Nonce, statement, time.Now()); err != nil {
+		_ = c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_chain_id"})
+		return nil, false
+	}
+
+	text := msg.String()
+	resp := fiber.Map{
+		"nonce":      n.Nonce,
+		"message":    text,
+		"domain":     msg.Domain,
+		"chain_id":   msg.ChainID,
+		"expires_at": msg.ExpirationTime,
+	}
+	if wType == auth.WalletTypeEVM {
+		// For wallets that prefer eth_signTypedData_v4 (signature_type=eip712).
+		resp["typed_data"] = auth.LoginTypedData(text)
+	}
+	return resp, true
+}
 
+// checkSignInMessage parses and validates the submitted message against this server's domain and
+// the request, then verifies the signature over the exact text. On failure it writes the response.
+func checkSignInMessage(c *fiber.Ctx, cfg config.Config, wType auth.WalletType, addr string, req verifyRequest, statement string) bool {
+	if req.Message == "" {
+		_ = c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_message"})
+		return false
+	}
+	msg, err := auth.ParseSignInMessage(req.Message)
+	if err != nil {
+		_ = c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_message", "message": err.Error()})
+		return false
+	}
+	domain, _ := cfg.SignInDomain()
+	if err := msg.Validate(domain, wType, addr, req.Nonce, statement, time.Now()); err != nil {
+		_ = c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "message_rejected", "message": err.Error()})
+		return false
+	}
+	if err := auth.VerifySignatureWithScheme(wType, req.SignatureType, addr, req.Message, req.Signature, req.PublicKey); err != nil {
+		_ = c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bitbucket"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// BitbucketHandler links Bitbucket Cloud accounts and receives Bitbucket repository webhooks.
+type BitbucketHandler struct {
+	cfg config.Config
+	db  *db.DB
+	bb  *bitbucket.Client
+}
+
+func NewBitbucketHandler(cfg config.Config, d *db.DB) *BitbucketHandler {
+	return &BitbucketHandler{cfg: cfg, db: d, bb: bitbucket.NewClient()}
+}
+
+func (h *BitbucketHandler) oauthConfig() bitbucket.OAuthConfig {
+	return bitbucket.OAuthConfig{ClientID: h.cfg.BitbucketOAuthClientID, ClientSecret: h.cfg.BitbucketOAuthClientSecret}
+}
+
+// Start begins linking a Bitbucket account to the signed-in user.
+func (h *BitbucketHandler) Start() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.BitbucketOAuthClientID == "" || h.cfg.BitbucketOAuthClientSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "bitbucket_oauth_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		state := randomState(32)
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO oauth_states (state, user_id, kind, expires_at)
+VALUES ($1, $2, 'bitbucket_link', $3)
+`, state, userID, time.Now().UTC().Add(10*time.Minute))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+		}
+
+		authURL, err := bitbucket.AuthorizeURL(h.cfg.BitbucketOAuthClientID, state)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"url": authURL})
+	}
+}
+
+// Callback completes the link started by Start and stores the account's tokens.
+func (h *BitbucketHandler) Callback() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		code := c.Query("code")
+		state := c.Query("state")
+		if code == "" || state == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_code_or_state"})
+		}
+
+		var userID uuid.UUID
+		err := h.db.Pool.QueryRow(c.Context(), `
+DELETE FROM oauth_states
+WHERE state = $1 AND kind = 'bitbucket_link' AND expires_at > now()
+RETURNING user_id
+`, state).Scan(&userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_or_expired_state"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_lookup_failed"})
+		}
+
+		tr, err := bitbucket.ExchangeCode(c.Context(), code, h.oauthConfig())
+		if err != nil {
+			slog.Warn("bitbucket oauth: code exchange failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "token_exchange_failed"})
+		}
+		u, err := h.bb.GetCurrentUser(c.Context(), tr.AccessToken)
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "bitbucket_user_fetch_failed"})
+		}
+		if err := bitbucket.SaveAccount(c.Context(), h.db.Pool, userID, u, tr, h.cfg.TokenEncKeyB64); err != nil {
+			slog.Error("bitbucket oauth: account save failed", "user_id", userID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "account_save_failed"})
+		}
+
+		if h.cfg.GitHubOAuthSuccessRedirectURL != "" {
+			if ru, err := url.Parse(h.cfg.GitHubOAuthSuccessRedirectURL); err == nil {
+				q := ru.Query()
+				q.Set("linked", "true")
+				q.Set("bitbucket", u.Username)
+				ru.RawQuery = q.Encode()
+				return c.Redirect(ru.String(), fiber.StatusFound)
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"linked":    true,
+			"bitbucket": fiber.Map{"uuid": u.UUID, "username": u.Username},
+		})
+	}
+}
+
+func (h *BitbucketHandler) Status() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var bbUUID, username string
+		var avatarURL *string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT bitbucket_uuid, username, avatar_url FROM bitbucket_accounts WHERE user_id = $1
+`, userID).Scan(&bbUUID, &username, &avatarURL)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"linked": false})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "status_failed"})
+		}
+		account := fiber.Map{"uuid": bbUUID, "username": username}
+		if avatarURL != nil && *avatarURL != "" {
+			account["avatar_url"] = *avatarURL
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"linked": true, "bitbucket": account})
+	}
+}
+
+// Receive accepts Bitbucket repository webhooks. Bitbucket signs the body the same way GitHub
+// does (sha256=<hex>) but sends it in X-Hub-Signature. Issue and push events queue an issue sync
+// for the matching project; the worker does the rest through the repohost interface.
+func (h *BitbucketHandler) Receive() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.cfg.BitbucketWebhookSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_secret_not_configured"})
+		}
+		body := c.Body()
+		if !verifyGitHubSignature(body, strings.TrimSpace(c.Get("X-Hub-Signature")), h.cfg.BitbucketWebhookSecret) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+		}
+		event := strings.TrimSpace(c.Get("X-Event-Key"))
+
+		var payload struct {
+			Repository struct {
+				FullName string `json:"full_name"`
+			} `json:"repository"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Repository.FullName == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payload"})
+		}
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"ok": true})
+		}
+
+		if !strings.HasPrefix(event, "issue:") && event != "repo:push" {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"ok": true})
+		}
+		var projectID uuid.UUID
+		err := h.db.Pool.QueryRow(c.Context(), `
+SELECT id FROM projects
+WHERE provider = 'bitbucket' AND lower(github_full_name) = lower($1) AND deleted_at IS NULL
+`, payload.Repository.FullName).Scan(&projectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"ok": true})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		_, _ = h.db.Pool.Exec(c.Context(), `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at, priority)
+VALUES ($1, 'sync_issues', 'pending', now(), 20)
+ON CONFLICT (project_id, job_type) WHERE status = 'pending'
+DO UPDATE SET priority = GREATEST(sync_jobs.priority, EXCLUDED.priority), updated_at = now()
+`, projectID)
+		slog.Info("bitbucket webhook received", "event", event, "project_id", projectID)
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"ok": true})
+	}
+}
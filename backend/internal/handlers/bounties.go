@@ -0,0 +1,693 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
+	"github.com/jagadeesh/grainlify/backend/internal/payoutaddress"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+)
+
+type BountiesHandler struct {
+	cfg config.Config
+	db  *db.DB
+	bus bus.Bus
+}
+
+func NewBountiesHandler(cfg config.Config, d *db.DB, b bus.Bus) *BountiesHandler {
+	return &BountiesHandler{cfg: cfg, db: d, bus: b}
+}
+
+// bountyColumns is the select list scanned by scanBounty; b, p and gi are bounties, projects and
+// github_issues.
+const bountyColumns = `b.id, b.project_id, p.github_full_name, gi.number, COALESCE(gi.title, ''), COALESCE(gi.url, ''),
+  b.amount::text, b.asset, b.deadline, b.status, b.claimant_login, b.claimed_at, b.paid_at, b.payment_tx_hash,
+  b.funded_at, b.claim_pr_number, b.claim_pr_url, b.claim_submitted_at, b.created_at, b.updated_at, a.code, a.name, a.symbol, a.decimals, a.usd_price::text, a.price_updated_at,
+  a.price_expires_at`
+
+const bountyFrom = `FROM bounties b
+JOIN projects p ON p.id = b.project_id
+JOIN github_issues gi ON gi.id = b.issue_id
+LEFT JOIN assets a ON a.code = b.asset`
+
+var txHashRe = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+func scanBounty(row pgx.Row) (fiber.Map, time.Time, uuid.UUID, error) {
+	var id, projectID uuid.UUID
+	var fullName, title, url, amount, asset, status string
+	var number int
+	var deadline, claimedAt, paidAt, fundedAt, claimSubmittedAt *time.Time
+	var claimant, txHash, claimPRURL *string
+	var claimPR *int
+	var createdAt, updatedAt time.Time
+	var assetCode, assetName, assetSymbol, usdPrice *string
+	var assetDecimals *int
+	var priceUpdatedAt, priceExpiresAt *time.Time
+	if err := row.Scan(&id, &projectID, &fullName, &number, &title, &url, &amount, &asset, &deadline, &status,
+		&claimant, &claimedAt, &paidAt, &txHash, &fundedAt, &claimPR, &claimPRURL, &claimSubmittedAt, &createdAt,
+		&updatedAt, &assetCode, &assetName,
+		&assetSymbol, &assetDecimals, &usdPrice, &priceUpdatedAt, &priceExpiresAt); err != nil {
+		return nil, time.Time{}, uuid.Nil, err
+	}
+	if n, err := bounties.ParseAmount(amount); err == nil {
+		amount = n
+	}
+	var meta *assets.Asset
+	if assetCode != nil && assetSymbol != nil && assetDecimals != nil {
+		meta = &assets.Asset{Code: *assetCode, Name: assetName, Symbol: *assetSymbol, Decimals: *assetDecimals,
+			USDPrice: usdPrice, PriceUpdatedAt: priceUpdatedAt, PriceExpiresAt: priceExpiresAt}
+	}
+	if url == "" {
+		url = fmt.Sprintf("https://github.com/%s/issues/%d", fullName, number)
+	}
+	var claim fiber.Map
+	if claimPR != nil {
+		claim = fiber.Map{"pr_number": *claimPR, "pr_url": claimPRURL, "submitted_at": claimSubmittedAt}
+	}
+	return fiber.Map{
+		"id":               id.String(),
+		"project_id":       projectID.String(),
+		"github_full_name": fullName,
+		"issue": fiber.Map{
+			"number": number,
+			"title":  title,
+			"url":    url,
+		},
+		"amount":          amount,
+		"asset":           asset,
+		"value":           assets.NewValue(amount, asset, meta, time.Now()),
+		"deadline":        deadline,
+		"status":          status,
+		"claimant_login":  claimant,
+		"claimed_at":      claimedAt,
+		"paid_at":         paidAt,
+		"payment_tx_hash": txHash,
+		"funded_at":       fundedAt,
+		"claim":           claim,
+		"created_at":      createdAt,
+		"updated_at":      updatedAt,
+	}, createdAt, id, nil
+}
+
+// canManageBounties reports whether the caller may create and transition bounties on the project:
+// admins and the project's maintainers. It writes the error response when not (ok is false then).
+func (h *BountiesHandler) canManageBounties(c *fiber.Ctx, projectID uuid.UUID) (userID uuid.UUID, ok bool, err error) {
+	userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err = uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+	}
+	var owner uuid.UUID
+	err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id FROM projects WHERE id = $1 AND status = 'verified' AND deleted_at IS NULL
+`, projectID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+	}
+	if err != nil {
+		return uuid.Nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	if role, _ := c.Locals(auth.LocalRole).(string); role == "admin" {
+		return userID, true, nil
+	}
+	allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, projectRoleMaintainer)
+	if err != nil {
+		return uuid.Nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	if !allowed {
+		return uuid.Nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+	}
+	return userID, true, nil
+}
+
+type createBountyRequest struct {
+	IssueNumber int         `json:"issue_number"`
+	Amount      json.Number `json:"amount"`
+	Asset       string      `json:"asset"`
+	Deadline    *time.Time  `json:"deadline"`
+}
+
+// Create attaches a bounty to one of the project's open synced issues. Maintainers and admins only;
+// an issue has at most one open or claimed bounty at a time.
+func (h *BountiesHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+		userID, ok, err := h.canManageBounties(c, projectID)
+		if !ok {
+			return err
+		}
+
+		var req createBountyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		if req.IssueNumber <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_issue_number"})
+		}
+		amount, err := bounties.ParseAmount(req.Amount.String())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_amount"})
+		}
+		asset, valid := bounties.NormalizeAsset(req.Asset)
+		if !valid {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_asset"})
+		}
+		if req.Deadline != nil && !req.Deadline.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_deadline"})
+		}
+
+		var issueID uuid.UUID
+		var state string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT id, COALESCE(state, '') FROM github_issues WHERE project_id = $1 AND number = $2
+`, projectID, req.IssueNumber).Scan(&issueID, &state)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "issue_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		if !strings.EqualFold(state, "open") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "issue_not_open"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var bountyID uuid.UUID
+		err = tx.QueryRow(c.Context(), `
+INSERT INTO bounties (project_id, issue_id, amount, asset, deadline, created_by)
+VALUES ($1, $2, $3::numeric, $4, $5, $6)
+RETURNING id
+`, projectID, issueID, amount, asset, req.Deadline, userID).Scan(&bountyID)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_exists"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO bounty_events (bounty_id, to_status, actor_user_id) VALUES ($1, 'open', $2)
+`, bountyID, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		out, _, _, err := scanBounty(tx.QueryRow(c.Context(), `SELECT `+bountyColumns+` `+bountyFrom+` WHERE b.id = $1`, bountyID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_create_failed"})
+		}
+		syncjobs.PublishBounty(c.Context(), h.bus, events.SubjectBountyCreated, bountyID.String(), bounties.StatusOpen)
+		return c.Status(fiber.StatusCreated).JSON(out)
+	}
+}
+
+// List returns the bounties on listed projects, newest first, paged like the project data lists.
+// Query params:
+//   - status: open (default), claimed or paid; open leaves out bounties past their deadline
+//   - project_id: one project's bounties
+//   - ecosystem: filter by ecosystem name (case-insensitive), including its child ecosystems
+//   - asset: filter by asset code
+func (h *BountiesHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+
+		status := strings.ToLower(strings.TrimSpace(c.Query("status", bounties.StatusOpen)))
+		if !bounties.ValidStatus(status) || status == bounties.StatusCancelled {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		conditions := []string{"p.status = 'verified'", "p.deleted_at IS NULL", "b.status = $1"}
+		args := []any{status}
+		if status == bounties.StatusOpen {
+			conditions = append(conditions, "(b.deadline IS NULL OR b.deadline > now())")
+		}
+		if raw := strings.TrimSpace(c.Query("project_id")); raw != "" {
+			projectID, err := uuid.Parse(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+			}
+			args = append(args, projectID)
+			conditions = append(conditions, fmt.Sprintf("b.project_id = $%d", len(args)))
+		}
+		if ecosystem := strings.TrimSpace(c.Query("ecosystem")); ecosystem != "" {
+			args = append(args, ecosystem)
+			conditions = append(conditions, fmt.Sprintf(`p.ecosystem_id IN (
+  SELECT ec.descendant_id FROM ecosystem_closure ec
+  INNER JOIN ecosystems ea ON ea.id = ec.ancestor_id
+  WHERE LOWER(TRIM(ea.name)) = LOWER($%d)
+)`, len(args)))
+		}
+		if raw := strings.TrimSpace(c.Query("asset")); raw != "" {
+			asset, valid := bounties.NormalizeAsset(raw)
+			if !valid {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_asset"})
+			}
+			args = append(args, asset)
+			conditions = append(conditions, fmt.Sprintf("b.asset = $%d", len(args)))
+		}
+		if cursor != nil {
+			afterID, err := uuid.Parse(cursor.ID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			args = append(args, cursor.At, afterID)
+			conditions = append(conditions, fmt.Sprintf("(b.created_at, b.id) < ($%d, $%d)", len(args)-1, len(args)))
+		}
+		args = append(args, limit+1)
+
+		rows, err := h.db.Pool.Query(c.Context(), `SELECT `+bountyColumns+` `+bountyFrom+`
+WHERE `+strings.Join(conditions, " AND ")+`
+ORDER BY b.created_at DESC, b.id DESC
+LIMIT $`+fmt.Sprint(len(args)), args...)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounties_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		var last pagination.Cursor
+		n := 0
+		for rows.Next() {
+			b, createdAt, id, err := scanBounty(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounties_list_failed"})
+			}
+			if n++; n > limit {
+				continue
+			}
+			last = pagination.Cursor{At: createdAt, ID: id.String()}
+			out = append(out, b)
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounties_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"bounties": out, "next_cursor": pagination.Next(n, limit, last)})
+	}
+}
+
+// Get returns one bounty on a listed project with its status history, oldest first.
+func (h *BountiesHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		out, _, _, err := scanBounty(h.db.Pool.QueryRow(c.Context(), `SELECT `+bountyColumns+` `+bountyFrom+`
+WHERE b.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
+`, bountyID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT from_status, to_status, note, created_at FROM bounty_events WHERE bounty_id = $1 ORDER BY created_at, id
+`, bountyID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		defer rows.Close()
+		history := []fiber.Map{}
+		for rows.Next() {
+			var from, note *string
+			var to string
+			var at time.Time
+			if err := rows.Scan(&from, &to, &note, &at); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+			}
+			history = append(history, fiber.Map{"from_status": from, "to_status": to, "note": note, "created_at": at})
+		}
+		out["history"] = history
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type transitionBountyRequest struct {
+	Status        string `json:"status"`
+	ClaimantLogin string `json:"claimant_login"`
+	PaymentTxHash string `json:"payment_tx_hash"`
+	Note          string `json:"note"`
+}
+
+// clearClaim is the SET list that drops a contributor's pull request claim.
+const clearClaim = "claim_pr_number = NULL, claim_pr_url = NULL, claim_submitted_at = NULL"
+
+// Transition moves a bounty along its lifecycle (see bounties.CanTransition). Claiming needs the
+// claimant's GitHub login; reopening a claimed bounty clears it. Moving a pending_payout bounty back
+// to open or claimed rejects the contributor's pull request claim. Maintainers and admins only.
+func (h *BountiesHandler) Transition() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+
+		var req transitionBountyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		to := strings.ToLower(strings.TrimSpace(req.Status))
+		if !bounties.ValidStatus(to) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		claimant := strings.TrimPrefix(strings.TrimSpace(req.ClaimantLogin), "@")
+		if to == bounties.StatusClaimed && claimant == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "claimant_login_required"})
+		}
+		txHash := strings.ToLower(strings.TrimSpace(req.PaymentTxHash))
+		if txHash != "" && !txHashRe.MatchString(txHash) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payment_tx_hash"})
+		}
+		note := strings.TrimSpace(req.Note)
+		if len(note) > 2000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "note_too_long"})
+		}
+
+		var projectID uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `SELECT project_id FROM bounties WHERE id = $1`, bountyID).Scan(&projectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		userID, ok, err := h.canManageBounties(c, projectID)
+		if !ok {
+			return err
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var from string
+		var paying, escrowed bool
+		if err := tx.QueryRow(c.Context(), `
+SELECT status,
+  EXISTS(SELECT 1 FROM payouts WHERE bounty_id = $1 AND status IN ('awaiting_approval', 'pending', 'submitted')),
+  EXISTS(SELECT 1 FROM bounty_escrows WHERE bounty_id = $1 AND status = 'locked')
+FROM bounties WHERE id = $1 FOR UPDATE
+`, bountyID).Scan(&from, &paying, &escrowed); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if paying {
+			// The payout worker moves the bounty to paid once the payment confirms.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_in_progress"})
+		}
+		if escrowed && (to == bounties.StatusPaid || to == bounties.StatusCancelled) {
+			// Escrowed funds are paid or returned by releasing or refunding the escrow.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "escrow_locked"})
+		}
+		if !bounties.CanTransition(from, to) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "invalid_transition", "status": from, "allowed": bounties.Next(from)})
+		}
+		if to == bounties.StatusClaimed {
+			// Claimants must be payable: a verified payout address, not just a login wallet.
+			addr, err := payoutaddress.ForLogin(c.Context(), tx, claimant)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+			}
+			if addr == "" {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "claimant_payout_address_required"})
+			}
+		}
+
+		var set string
+		args := []any{bountyID, to}
+		switch to {
+		case bounties.StatusClaimed:
+			args = append(args, claimant)
+			set = "claimant_login = $3, claimed_at = now(), " + clearClaim
+		case bounties.StatusOpen:
+			set = "claimant_login = NULL, claimed_at = NULL, " + clearClaim
+		case bounties.StatusPaid:
+			var hash *string
+			if txHash != "" {
+				hash = &txHash
+			}
+			args = append(args, hash)
+			set = "paid_at = now(), payment_tx_hash = $3"
+		case bounties.StatusCancelled:
+			set = "cancelled_at = now()"
+		}
+		if _, err := tx.Exec(c.Context(), `UPDATE bounties SET status = $2, `+set+`, updated_at = now() WHERE id = $1`, args...); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		var notePtr *string
+		if note != "" {
+			notePtr = &note
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO bounty_events (bounty_id, from_status, to_status, actor_user_id, note) VALUES ($1, $2, $3, $4, $5)
+`, bountyID, from, to, userID, notePtr); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		out, _, _, err := scanBounty(tx.QueryRow(c.Context(), `SELECT `+bountyColumns+` `+bountyFrom+` WHERE b.id = $1`, bountyID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if to == bounties.StatusPaid {
+			syncjobs.PublishBounty(c.Context(), h.bus, events.SubjectBountyPaid, bountyID.String(), to)
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type createPayoutRequest struct {
+	Destination string `json:"destination"`
+}
+
+// payoutColumns is the select list scanned by scanPayout.
+const payoutColumns = `id, bounty_id, destination, amount::text, asset, asset_issuer, status, approvals_required, tx_hash, ledger,
+  attempts, last_error, submitted_at, confirmed_at, failed_at, created_at`
+
+func scanPayout(row pgx.Row) (fiber.Map, time.Time, uuid.UUID, error) {
+	var id, bountyID uuid.UUID
+	var destination, amount, asset, status string
+	var issuer, txHash, lastError *string
+	var ledger *int64
+	var attempts, approvalsRequired int
+	var submittedAt, confirmedAt, failedAt *time.Time
+	var createdAt time.Time
+	if err := row.Scan(&id, &bountyID, &destination, &amount, &asset, &issuer, &status, &approvalsRequired, &txHash, &ledger,
+		&attempts, &lastError, &submittedAt, &confirmedAt, &failedAt, &createdAt); err != nil {
+		return nil, time.Time{}, uuid.Nil, err
+	}
+	if n, err := bounties.ParseAmount(amount); err == nil {
+		amount = n
+	}
+	return fiber.Map{
+		"id":                 id.String(),
+		"bounty_id":          bountyID.String(),
+		"destination":        destination,
+		"amount":             amount,
+		"asset":              asset,
+		"asset_issuer":       issuer,
+		"status":             status,
+		"approvals_required": approvalsRequired,
+		"tx_hash":            txHash,
+		"ledger":             ledger,
+		"attempts":           attempts,
+		"last_error":         lastError,
+		"submitted_at":       submittedAt,
+		"confirmed_at":       confirmedAt,
+		"failed_at":          failedAt,
+		"created_at":         createdAt,
+	}, createdAt, id, nil
+}
+
+// CreatePayout approves paying a claimed bounty, or one whose claim awaits payout, on Stellar. The sync worker submits the payment
+// and marks the bounty paid once it confirms. The destination must be one of the claimant's verified
+// payout addresses and defaults to their primary one. Maintainers and admins only.
+func (h *BountiesHandler) CreatePayout() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if strings.TrimSpace(h.cfg.PayoutSigner) == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "payouts_not_configured"})
+		}
+		issuers, err := stellar.ParseIssuers(h.cfg.PayoutAssetIssuers)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "payouts_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		var req createPayoutRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+			}
+		}
+
+		var projectID uuid.UUID
+		var status, amount, asset string
+		var claimant *string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT project_id, status, amount::text, asset, claimant_login FROM bounties WHERE id = $1
+`, bountyID).Scan(&projectID, &status, &amount, &asset, &claimant)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		userID, ok, err := h.canManageBounties(c, projectID)
+		if !ok {
+			return err
+		}
+		if !bounties.Payable(status) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_not_claimed", "status": status})
+		}
+		var issuer *string
+		if asset != stellar.NativeAsset {
+			i, ok := issuers[asset]
+			if !ok {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "asset_not_payable"})
+			}
+			issuer = &i
+		}
+
+		var escrowed bool
+		if err := h.db.Pool.QueryRow(c.Context(), `
+SELECT EXISTS(SELECT 1 FROM bounty_escrows WHERE bounty_id = $1 AND status = 'locked')
+`, bountyID).Scan(&escrowed); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		if escrowed {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "escrow_locked"})
+		}
+
+		requested := strings.TrimSpace(req.Destination)
+		if requested != "" && !stellar.ValidAccount(requested) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_destination"})
+		}
+		if claimant == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_not_claimed", "status": status})
+		}
+		// The money only goes to an address the claimant verified, whoever approves the payout.
+		destination, err := payoutaddress.Destination(c.Context(), h.db.Pool, *claimant, requested)
+		switch {
+		case errors.Is(err, payoutaddress.ErrNoVerifiedAddress):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "destination_required"})
+		case errors.Is(err, payoutaddress.ErrUnverifiedDestination):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "destination_not_verified"})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+
+		// Large payouts wait for admin approvals (see PAYOUT_APPROVAL_THRESHOLD_USD).
+		approvals, err := h.approvalsRequired(c, amount, asset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_create_failed"})
+		}
+		payoutStatus := "pending"
+		if approvals > 0 {
+			payoutStatus = "awaiting_approval"
+		}
+
+		out, _, _, err := scanPayout(h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO payouts (bounty_id, destination, amount, asset, asset_issuer, approved_by, status, approvals_required)
+VALUES ($1, $2, $3::numeric, $4, $5, $6, $7, $8)
+RETURNING `+payoutColumns, bountyID, destination, amount, asset, issuer, userID, payoutStatus, approvals))
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_exists"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_create_failed"})
+		}
+		return c.Status(fiber.StatusAccepted).JSON(out)
+	}
+}
+
+// Payouts lists a bounty's payouts, newest first. Maintainers and admins only.
+func (h *BountiesHandler) Payouts() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		var projectID uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `SELECT project_id FROM bounties WHERE id = $1`, bountyID).Scan(&projectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		if _, ok, err := h.canManageBounties(c, projectID); !ok {
+			return err
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+payoutColumns+` FROM payouts WHERE bounty_id = $1 ORDER BY created_at DESC
+`, bountyID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+		}
+		defer rows.Close()
+		out := []fiber.Map{}
+		for rows.Next() {
+			p, _, _, err := scanPayout(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+			}
+			out = append(out, p)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"payouts": out})
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/payoutaddress"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+)
+
+type claimBountyRequest struct {
+	PRNumber int    `json:"pr_number"`
+	PRURL    string `json:"pr_url"`
+}
+
+// prURLRe matches a pull request URL: https://github.com/owner/repo/pull/123.
+var prURLRe = regexp.MustCompile(`^https://github\.com/([\w.-]+/[\w.-]+)/pull/([0-9]+)/?$`)
+
+// Claim lets a contributor claim a bounty with their merged pull request. The PR is fetched from
+// GitHub and must be merged, authored by the caller's linked GitHub login and reference the bounty's
+// issue, and it must have been merged after the bounty was created and the claim made before the
+// bounty's deadline. The bounty then waits in pending_payout for a maintainer to approve the payout.
+// Open bounties can be claimed by anyone; a claimed bounty only by its claimant.
+func (h *BountiesHandler) Claim() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if strings.TrimSpace(h.cfg.TokenEncKeyB64) == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "token_encryption_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		var req claimBountyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		prNumber, prRepo := req.PRNumber, ""
+		if raw := strings.TrimSpace(req.PRURL); raw != "" {
+			m := prURLRe.FindStringSubmatch(raw)
+			if m == nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_pr_url"})
+			}
+			n, _ := strconv.Atoi(m[2])
+			if prNumber != 0 && prNumber != n {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "pr_number_mismatch"})
+			}
+			prNumber, prRepo = n, m[1]
+		}
+		if prNumber <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "pr_number_required"})
+		}
+
+		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+		}
+
+		var projectID uuid.UUID
+		var fullName, status string
+		var issueNumber int
+		var claimant *string
+		var deadline *time.Time
+		var createdAt time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT b.project_id, p.github_full_name, gi.number, b.status, b.claimant_login, b.deadline, b.created_at
+FROM bounties b
+JOIN projects p ON p.id = b.project_id
+JOIN github_issues gi ON gi.id = b.issue_id
+WHERE b.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
+`, bountyID).Scan(&projectID, &fullName, &issueNumber, &status, &claimant, &deadline, &createdAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		if prRepo != "" && !strings.EqualFold(prRepo, fullName) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "pr_not_in_project"})
+		}
+		if ok, resp := claimable(c, status, claimant, linked.Login); !ok {
+			return resp
+		}
+		if bounties.DeadlinePassed(deadline, time.Now()) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_deadline_passed", "deadline": deadline})
+		}
+		addr, err := payoutaddress.ForLogin(c.Context(), h.db.Pool, linked.Login)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		if addr == "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "claimant_payout_address_required"})
+		}
+
+		pr, err := github.NewClient().GetPullRequest(c.Context(), linked.AccessToken, fullName, prNumber)
+		var apiErr *github.GitHubAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "pr_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "github_pr_lookup_failed"})
+		}
+		if !pr.Merged {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "pr_not_merged"})
+		}
+		if !strings.EqualFold(pr.User.Login, linked.Login) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "pr_not_authored_by_claimer"})
+		}
+		if !bounties.References(pr.Title+"\n"+pr.Body, fullName, issueNumber) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "pr_does_not_reference_issue"})
+		}
+		var mergedAt *time.Time
+		if pr.MergedAt != nil {
+			if t, err := time.Parse(time.RFC3339, *pr.MergedAt); err == nil {
+				mergedAt = &t
+			}
+		}
+		switch err := bounties.CheckClaim(deadline, createdAt, mergedAt, time.Now()); {
+		case errors.Is(err, bounties.ErrDeadlinePassed):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_deadline_passed", "deadline": deadline})
+		case errors.Is(err, bounties.ErrMergedBeforeBounty):
+			// Work that predates the bounty cannot collect it.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "pr_merged_before_bounty"})
+		}
+		prURL := pr.HTMLURL
+		if prURL == "" {
+			prURL = fmt.Sprintf("https://github.com/%s/pull/%d", fullName, prNumber)
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		// The status may have moved while GitHub was asked.
+		if err := tx.QueryRow(c.Context(), `SELECT status, claimant_login FROM bounties WHERE id = $1 FOR UPDATE`, bountyID).
+			Scan(&status, &claimant); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if ok, resp := claimable(c, status, claimant, linked.Login); !ok {
+			return resp
+		}
+		_, err = tx.Exec(c.Context(), `
+UPDATE bounties
+SET status = $2, claimant_login = $3, claimed_at = COALESCE(claimed_at, now()), claim_pr_number = $4, claim_pr_url = $5,
+    claim_submitted_at = now(), updated_at = now()
+WHERE id = $1
+`, bountyID, bounties.StatusPendingPayout, linked.Login, prNumber, prURL)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "pr_already_claimed"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO bounty_events (bounty_id, from_status, to_status, actor_user_id, note) VALUES ($1, $2, $3, $4, $5)
+`, bountyID, status, bounties.StatusPendingPayout, userID, fmt.Sprintf("Claimed with merged pull request #%d", prNumber)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		out, _, _, err := scanBounty(tx.QueryRow(c.Context(), `SELECT `+bountyColumns+` `+bountyFrom+` WHERE b.id = $1`, bountyID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_update_failed"})
+		}
+		syncjobs.PublishBounty(c.Context(), h.bus, events.SubjectBountyClaimed, bountyID.String(), bounties.StatusPendingPayout)
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// claimable reports whether login may claim a bounty in status with the given claimant. When not,
+// it writes the error response.
+func claimable(c *fiber.Ctx, status string, claimant *string, login string) (bool, error) {
+	if !bounties.CanClaim(status) {
+		return false, c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_not_claimable", "status": status})
+	}
+	if status == bounties.StatusClaimed && (claimant == nil || !strings.EqualFold(*claimant, login)) {
+		return false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "bounty_claimed_by_other"})
+	}
+	return true, nil
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/payoutaddress"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
+)
+
+// Escrow actions that need a maintainer's and an admin's approval.
+const (
+	escrowRelease = "release"
+	escrowRefund  = "refund"
+)
+
+const escrowColumns = `id, contract_id, onchain_id, depositor, amount_units::text, deadline, status, lock_tx_hash, locked_at,
+  release_to, release_tx_hash, released_at, refund_tx_hash, refunded_at, submitting_action, submitting_at, created_at`
+
+func scanEscrow(row pgx.Row) (fiber.Map, uuid.UUID, error) {
+	var id uuid.UUID
+	var contractID, depositor, units, status string
+	var onchainID int64
+	var deadline, createdAt time.Time
+	var lockTx, releaseTo, releaseTx, refundTx, submitting *string
+	var lockedAt, releasedAt, refundedAt, submittingAt *time.Time
+	if err := row.Scan(&id, &contractID, &onchainID, &depositor, &units, &deadline, &status, &lockTx, &lockedAt,
+		&releaseTo, &releaseTx, &releasedAt, &refundTx, &refundedAt, &submitting, &submittingAt, &createdAt); err != nil {
+		return nil, uuid.Nil, err
+	}
+	return fiber.Map{
+		"id":                id.String(),
+		"contract_id":       contractID,
+		"onchain_id":        onchainID,
+		"depositor":         depositor,
+		"amount_units":      units,
+		"deadline":          deadline,
+		"status":            status,
+		"lock_tx_hash":      lockTx,
+		"locked_at":         lockedAt,
+		"release_to":        releaseTo,
+		"release_tx_hash":   releaseTx,
+		"released_at":       releasedAt,
+		"refund_tx_hash":    refundTx,
+		"refunded_at":       refundedAt,
+		"submitting_action": submitting,
+		"submitting_at":     submittingAt,
+		"created_at":        createdAt,
+	}, id, nil
+}
+
+type initiateEscrowRequest struct {
+	Depositor string     `json:"depositor"`
+	Deadline  *time.Time `json:"deadline"`
+}
+
+// InitiateEscrow reserves an escrow for an open or claimed bounty and returns the lock_funds call
+// the depositor's wallet signs. The Soroban indexer marks the bounty funded when the deposit lands.
+// Initiating again abandons a deposit that has not landed. Maintainers and admins only.
+func (h *BountiesHandler) InitiateEscrow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		contractID, err := soroban.ContractStrkey(strings.TrimSpace(h.cfg.EscrowContractID))
+		if err != nil || h.cfg.EscrowAsset == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "escrow_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		var req initiateEscrowRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		depositor := strings.TrimSpace(req.Depositor)
+		if !stellar.ValidAccount(depositor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_depositor"})
+		}
+
+		var projectID uuid.UUID
+		var status, amount, asset string
+		var deadline *time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT project_id, status, amount::text, asset, deadline FROM bounties WHERE id = $1
+`, bountyID).Scan(&projectID, &status, &amount, &asset, &deadline)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		userID, ok, err := h.canManageBounties(c, projectID)
+		if !ok {
+			return err
+		}
+		if status != bounties.StatusOpen && !bounties.Payable(status) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_closed", "status": status})
+		}
+		if asset != h.cfg.EscrowAsset {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "asset_not_escrowable"})
+		}
+		if req.Deadline != nil {
+			deadline = req.Deadline
+		}
+		if deadline == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "deadline_required"})
+		}
+		if !deadline.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_deadline"})
+		}
+		units, err := bounties.ToUnits(amount)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_create_failed"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_create_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		if _, err := tx.Exec(c.Context(), `
+UPDATE bounty_escrows SET status = 'abandoned', updated_at = now()
+WHERE bounty_id = $1 AND status = 'awaiting_deposit'
+`, bountyID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_create_failed"})
+		}
+		out, _, err := scanEscrow(tx.QueryRow(c.Context(), `
+INSERT INTO bounty_escrows (bounty_id, contract_id, depositor, amount_units, deadline, created_by)
+VALUES ($1, $2, $3, $4::numeric, $5, $6)
+RETURNING `+escrowColumns, bountyID, contractID, depositor, units, deadline.UTC().Truncate(time.Second), userID))
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_funded"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_create_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_create_failed"})
+		}
+
+		network := stellarnet.FromConfig(h.cfg)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"escrow": out,
+			// The deposit the depositor's wallet signs and submits.
+			"invocation": fiber.Map{
+				"contract_id":        contractID,
+				"function":           "lock_funds",
+				"network":            network.Name,
+				"network_passphrase": network.Passphrase,
+				"args": fiber.Map{
+					"depositor": depositor,
+					"bounty_id": out["onchain_id"],
+					"amount":    units,
+					"deadline":  deadline.Unix(),
+				},
+			},
+		})
+	}
+}
+
+// Escrow returns the bounty's escrows, newest first, with the pending release/refund approvals of
+// the locked one. Maintainers and admins only.
+func (h *BountiesHandler) Escrow() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+		var projectID uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `SELECT project_id FROM bounties WHERE id = $1`, bountyID).Scan(&projectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "bounty_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "bounty_lookup_failed"})
+		}
+		if _, ok, err := h.canManageBounties(c, projectID); !ok {
+			return err
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+escrowColumns+` FROM bounty_escrows WHERE bounty_id = $1 ORDER BY created_at DESC
+`, bountyID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+		}
+		escrows := []fiber.Map{}
+		var lockedID uuid.UUID
+		for rows.Next() {
+			e, id, err := scanEscrow(rows)
+			if err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+			}
+			if e["status"] == "locked" {
+				lockedID = id
+			}
+			escrows = append(escrows, e)
+		}
+		rows.Close()
+
+		approvals := []fiber.Map{}
+		if lockedID != uuid.Nil {
+			if approvals, err = h.escrowApprovals(c, lockedID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"escrows": escrows, "approvals": approvals})
+	}
+}
+
+func (h *BountiesHandler) escrowApprovals(c *fiber.Ctx, escrowID uuid.UUID) ([]fiber.Map, error) {
+	rows, err := h.db.Pool.Query(c.Context(), `
+SELECT action, approver_role, approved_by, recipient, created_at
+FROM bounty_escrow_approvals WHERE escrow_id = $1 ORDER BY created_at
+`, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []fiber.Map{}
+	for rows.Next() {
+		var action, role string
+		var by *uuid.UUID
+		var recipient *string
+		var at time.Time
+		if err := rows.Scan(&action, &role, &by, &recipient, &at); err != nil {
+			return nil, err
+		}
+		out = append(out, fiber.Map{"action": action, "approver_role": role, "approved_by": by, "recipient": recipient, "created_at": at})
+	}
+	return out, rows.Err()
+}
+
+// ReleaseEscrow approves releasing a claimed bounty's escrow to the claimant's primary verified
+// payout address. See approveEscrow.
+func (h *BountiesHandler) ReleaseEscrow() fiber.Handler {
+	return h.approveEscrow(escrowRelease)
+}
+
+// RefundEscrow approves refunding a bounty's escrow to its depositor; the contract only allows it
+// after the escrow deadline. See approveEscrow.
+func (h *BountiesHandler) RefundEscrow() fiber.Handler {
+	return h.approveEscrow(escrowRefund)
+}
+
+// escrowSubmitTimeout is how long an escrow action whose transaction hash was never recorded blocks
+// new approvals. By then the indexer has settled the escrow if the call landed.
+const escrowSubmitTimeout = 15 * time.Minute
+
+// approveEscrow records the caller's approval of action on the bounty's locked escrow: a project
+// maintainer's or an admin's, by the caller's role. Once both have approved, the contract is called
+// with the escrow admin key (SOROBAN_SOURCE_SECRET) and the Soroban indexer confirms the outcome.
+// Approving again after a failed call retries it.
+//
+// Approvals are recorded under the escrow's row lock, and the approval completing the pair marks
+// the escrow submitting before the contract is called, so concurrent approvals call it exactly once.
+func (h *BountiesHandler) approveEscrow(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if strings.TrimSpace(h.cfg.EscrowContractID) == "" || strings.TrimSpace(h.cfg.SorobanRPCURL) == "" ||
+			strings.TrimSpace(h.cfg.SorobanSourceSecret) == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "escrow_not_configured"})
+		}
+		bountyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_bounty_id"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_approval_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var projectID, escrowID uuid.UUID
+		var status string
+		var claimant, releaseTx, refundTx, submitting *string
+		var onchainID int64
+		var submittingAt *time.Time
+		err = tx.QueryRow(c.Context(), `
+SELECT b.project_id, b.status, b.claimant_login, e.id, e.onchain_id, e.release_tx_hash, e.refund_tx_hash,
+  e.submitting_action, e.submitting_at
+FROM bounty_escrows e
+JOIN bounties b ON b.id = e.bounty_id
+WHERE e.bounty_id = $1 AND e.status = 'locked'
+FOR UPDATE OF e
+`, bountyID).Scan(&projectID, &status, &claimant, &escrowID, &onchainID, &releaseTx, &refundTx, &submitting, &submittingAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "escrow_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+		}
+		userID, ok, err := h.canManageBounties(c, projectID)
+		if !ok {
+			return err
+		}
+		if releaseTx != nil || refundTx != nil ||
+			(submitting != nil && submittingAt != nil && time.Since(*submittingAt) < escrowSubmitTimeout) {
+			// Submitted; waiting for the indexer to see the contract event.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "escrow_action_submitted"})
+		}
+		approverRole := projectRoleMaintainer
+		if role, _ := c.Locals(auth.LocalRole).(string); role == "admin" {
+			approverRole = "admin"
+		}
+
+		var recipient *string
+		if action == escrowRelease {
+			if !bounties.Payable(status) || claimant == nil {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "bounty_not_claimed", "status": status})
+			}
+			// Escrowed funds only go to an address the claimant verified.
+			to, err := payoutaddress.Destination(c.Context(), tx, *claimant, "")
+			if errors.Is(err, payoutaddress.ErrNoVerifiedAddress) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "claimant_payout_address_required"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+			}
+			recipient = &to
+		}
+
+		// Both approvals of a release must name the same recipient.
+		var otherRecipient *string
+		err = tx.QueryRow(c.Context(), `
+SELECT recipient FROM bounty_escrow_approvals WHERE escrow_id = $1 AND action = $2 AND approver_role <> $3
+`, escrowID, action, approverRole).Scan(&otherRecipient)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_lookup_failed"})
+		}
+		otherApproved := err == nil
+		if otherApproved && recipient != nil && (otherRecipient == nil || *otherRecipient != *recipient) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "recipient_mismatch", "approved_recipient": otherRecipient})
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO bounty_escrow_approvals (escrow_id, action, approver_role, approved_by, recipient)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (escrow_id, action, approver_role) DO UPDATE SET
+  approved_by = EXCLUDED.approved_by, recipient = EXCLUDED.recipient, created_at = now()
+`, escrowID, action, approverRole, userID, recipient); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_approval_failed"})
+		}
+		if !otherApproved {
+			if err := tx.Commit(c.Context()); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_approval_failed"})
+			}
+			waiting := "admin"
+			if approverRole == "admin" {
+				waiting = projectRoleMaintainer
+			}
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"action": action, "approved_by": approverRole, "waiting_for": waiting})
+		}
+
+		contract, err := h.escrowContract()
+		if err != nil {
+			slog.Error("escrow client init failed", "error", err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "escrow_not_configured"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+UPDATE bounty_escrows
+SET submitting_action = $2, submitting_at = now(), release_to = COALESCE($3, release_to), updated_at = now()
+WHERE id = $1
+`, escrowID, action, recipient); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_approval_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "escrow_approval_failed"})
+		}
+
+		var result *soroban.TransactionResult
+		if action == escrowRelease {
+			result, err = contract.ReleaseFunds(c.Context(), uint64(onchainID), *recipient)
+		} else {
+			result, err = contract.Refund(c.Context(), uint64(onchainID))
+		}
+		if err != nil {
+			slog.Warn("escrow contract call failed", "bounty_id", bountyID, "action", action, "error", err)
+			if _, err := h.db.Pool.Exec(c.Context(), `
+UPDATE bounty_escrows SET submitting_action = NULL, submitting_at = NULL, updated_at = now()
+WHERE id = $1 AND submitting_action = $2
+`, escrowID, action); err != nil {
+				// Left submitting: approvals are blocked until escrowSubmitTimeout.
+				slog.Error("failed to clear escrow submission", "bounty_id", bountyID, "action", action, "error", err)
+			}
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "escrow_call_failed"})
+		}
+
+		column := "release_tx_hash"
+		if action == escrowRefund {
+			column = "refund_tx_hash"
+		}
+		if _, err := h.db.Pool.Exec(c.Context(), `
+UPDATE bounty_escrows SET `+column+` = $2, updated_at = now() WHERE id = $1
+`, escrowID, result.Hash); err != nil {
+			// The escrow stays submitting, which blocks new approvals; the indexer records the
+			// transaction from the contract event.
+			slog.Error("failed to record escrow transaction; the indexer will settle the escrow",
+				"bounty_id", bountyID, "action", action, "tx_hash", result.Hash, "error", err)
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"action": action, "tx_hash": result.Hash, "status": result.Status})
+	}
+}
+
+func (h *BountiesHandler) escrowContract() (*soroban.EscrowContract, error) {
+	network := stellarnet.FromConfig(h.cfg)
+	client, err := soroban.NewClient(soroban.Config{
+		RPCURL:            network.RPCURL,
+		NetworkPassphrase: network.Passphrase,
+		HorizonURL:        network.HorizonURL,
+		Network:           soroban.Network(network.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	txb, err := soroban.NewTransactionBuilder(client, h.cfg.SorobanSourceSecret, soroban.DefaultRetryConfig())
+	if err != nil {
+		return nil, err
+	}
+	return soroban.NewEscrowContract(client, txb, h.cfg.EscrowContractID), nil
+}
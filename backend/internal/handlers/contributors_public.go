@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/achievements"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+const (
+	// contributorProfileTTL is how long a public contributor page is served from memory; the
+	// aggregates behind it scan all of the contributor's issues, PRs and commits.
+	contributorProfileTTL = 10 * time.Minute
+	// Past this many cached profiles, expired entries are swept on the next store.
+	contributorProfileMaxEntries = 5000
+)
+
+type contributorProfileEntry struct {
+	body     fiber.Map
+	loadedAt time.Time
+}
+
+// ContributorsPublicHandler serves public contributor pages by GitHub login, for users who opted in
+// with public_profile.
+type ContributorsPublicHandler struct {
+	db *db.DB
+
+	mu    sync.Mutex
+	cache map[string]contributorProfileEntry
+}
+
+func NewContributorsPublicHandler(d *db.DB) *ContributorsPublicHandler {
+	return &ContributorsPublicHandler{db: d, cache: map[string]contributorProfileEntry{}}
+}
+
+// Get returns the contributor's contribution counts, top languages and ecosystems and the last
+// year's contribution calendar. Only users who turned on public_profile are shown; everyone else,
+// including logins unknown here, gets the same 404. The opt-in and the profile settings are checked
+// on every request so changes take effect at once; the aggregates are cached for contributorProfileTTL.
+func (h *ContributorsPublicHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		login := strings.TrimSpace(c.Params("login"))
+		if !githubLoginRe.MatchString(login) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_login"})
+		}
+
+		var canonicalLogin string
+		var settings profileSettings
+		err := h.db.Pool.QueryRow(c.Context(), `
+SELECT ga.login, `+profileSettingsColumn+`
+FROM github_accounts ga
+JOIN users u ON u.id = ga.user_id
+LEFT JOIN user_profiles up ON up.user_id = u.id
+WHERE LOWER(ga.login) = LOWER($1)
+`, login).Scan(append([]any{&canonicalLogin}, settings.scanArgs()...)...)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !settings.PublicProfile) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "contributor_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributor_lookup_failed"})
+		}
+
+		key := strings.ToLower(canonicalLogin)
+		h.mu.Lock()
+		e, ok := h.cache[key]
+		h.mu.Unlock()
+		if !ok || time.Since(e.loadedAt) > contributorProfileTTL {
+			body, err := h.load(c.Context(), canonicalLogin)
+			if err != nil {
+				slog.Error("contributor profile load failed", "login", canonicalLogin, "error", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributor_profile_failed"})
+			}
+			e = contributorProfileEntry{body: body, loadedAt: time.Now()}
+			h.store(key, e)
+		}
+
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.Status(fiber.StatusOK).JSON(withProfileSettings(e.body, settings))
+	}
+}
+
+// withProfileSettings applies the user's display settings to a cached profile. The settings are
+// read on every request, like the opt-in, so changes show without waiting for the cache.
+func withProfileSettings(body fiber.Map, s profileSettings) fiber.Map {
+	out := make(fiber.Map, len(body))
+	for k, v := range body {
+		out[k] = v
+	}
+	if s.DisplayName != nil {
+		out["name"] = *s.DisplayName
+	}
+	if s.AvatarURL != nil {
+		out["avatar_url"] = *s.AvatarURL
+	}
+	if !s.ShowBio {
+		out["bio"] = nil
+	}
+	if !s.ShowBadges {
+		out["badges"] = []achievements.Earned{}
+	}
+	return out
+}
+
+func (h *ContributorsPublicHandler) store(key string, e contributorProfileEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.cache) >= contributorProfileMaxEntries {
+		for k, old := range h.cache {
+			if time.Since(old.loadedAt) > contributorProfileTTL {
+				delete(h.cache, k)
+			}
+		}
+	}
+	h.cache[key] = e
+}
+
+// load computes the public profile of login. Contributions count on verified and archived projects,
+// as on the user's own profile.
+func (h *ContributorsPublicHandler) load(ctx context.Context, login string) (fiber.Map, error) {
+	var name, bio, avatarURL, profileURL *string
+	var issues, prs, mergedPRs, commits, projectsCount int
+	err := h.db.Pool.QueryRow(ctx, `
+WITH listed AS (
+  SELECT id FROM projects WHERE status IN ('verified', 'archived')
+)
+SELECT ga.name,
+       COALESCE(NULLIF(u.bio, ''), ga.bio),
+       COALESCE(NULLIF(u.avatar_url, ''), ga.avatar_url),
+       ga.profile_url,
+       (SELECT COUNT(*) FROM contributions c WHERE c.author_login = ga.login AND c.kind = 'issue' AND c.project_id IN (SELECT id FROM listed)),
+       (SELECT COUNT(*) FROM contributions c WHERE c.author_login = ga.login AND c.kind = 'pull_request' AND c.project_id IN (SELECT id FROM listed)),
+       (SELECT COUNT(*) FROM github_pull_requests pr WHERE pr.author_login = ga.login AND pr.merged IS TRUE AND pr.project_id IN (SELECT id FROM listed)),
+       (SELECT COUNT(*) FROM contributions c WHERE c.author_login = ga.login AND c.kind = 'commit' AND c.project_id IN (SELECT id FROM listed)),
+       (SELECT COUNT(DISTINCT c.project_id) FROM contributions c
+        WHERE c.author_login = ga.login AND c.kind IN ('issue', 'pull_request') AND c.project_id IN (SELECT id FROM listed))
+FROM github_accounts ga
+JOIN users u ON u.id = ga.user_id
+WHERE ga.login = $1
+`, login).Scan(&name, &bio, &avatarURL, &profileURL, &issues, &prs, &mergedPRs, &commits, &projectsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := []fiber.Map{}
+	rows, err := h.db.Pool.Query(ctx, `
+SELECT p.language, COUNT(*) AS contribution_count
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND p.language IS NOT NULL AND p.language <> ''
+GROUP BY p.language
+ORDER BY contribution_count DESC, p.language ASC
+LIMIT 10
+`, login)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var lang string
+		var n int
+		if err := rows.Scan(&lang, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		languages = append(languages, fiber.Map{"language": lang, "contribution_count": n})
+	}
+	rows.Close()
+
+	// A child ecosystem also counts toward its ancestors, as on the user's own profile.
+	ecosystems := []fiber.Map{}
+	rows, err = h.db.Pool.Query(ctx, `
+SELECT e.name, e.slug, COUNT(*) AS contribution_count
+FROM contributions c
+JOIN projects p ON p.id = c.project_id
+JOIN ecosystem_closure ec ON ec.descendant_id = p.ecosystem_id
+JOIN ecosystems e ON e.id = ec.ancestor_id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND e.status = 'active'
+GROUP BY e.id, e.name, e.slug
+ORDER BY contribution_count DESC, e.name ASC
+LIMIT 10
+`, login)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var ecoName, slug string
+		var n int
+		if err := rows.Scan(&ecoName, &slug, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ecosystems = append(ecosystems, fiber.Map{"ecosystem_name": ecoName, "ecosystem_slug": slug, "contribution_count": n})
+	}
+	rows.Close()
+
+	calendar, calendarTotal, err := loadContributionCalendar(ctx, h.db.Pool, login, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	badges, err := achievements.ForLogin(ctx, h.db.Pool, login)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar := "https://github.com/" + login + ".png?size=200"
+	if avatarURL != nil && *avatarURL != "" {
+		avatar = *avatarURL
+	}
+	profile := "https://github.com/" + login
+	if profileURL != nil && *profileURL != "" {
+		profile = *profileURL
+	}
+	return fiber.Map{
+		"login":       login,
+		"name":        name,
+		"bio":         bio,
+		"avatar_url":  avatar,
+		"profile_url": profile,
+		"contributions": fiber.Map{
+			"total":                issues + prs,
+			"issues":               issues,
+			"pull_requests":        prs,
+			"merged_pull_requests": mergedPRs,
+			"commits":              commits,
+		},
+		"projects_contributed_to_count": projectsCount,
+		"languages":                     languages,
+		"ecosystems":                    ecosystems,
+		"badges":                        badges,
+		"calendar": fiber.Map{
+			"days":  calendar,
+			"total": calendarTotal,
+		},
+		"computed_at": time.Now().UTC(),
+	}, nil
+}
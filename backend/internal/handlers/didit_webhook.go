@@ -1,22 +1,34 @@
 package handlers
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/didit"
+	"github.com/jagadeesh/grainlify/backend/internal/kyc"
 )
 
 type DiditWebhookHandler struct {
-	cfg   config.Config
-	db    *db.DB
-	didit *didit.Client
+	cfg      config.Config
+	db       *db.DB
+	didit    *didit.Client
+	screener *kyc.Screener
 }
 
 func NewDiditWebhookHandler(cfg config.Config, d *db.DB) *DiditWebhookHandler {
@@ -25,124 +37,270 @@ func NewDiditWebhookHandler(cfg config.Config, d *db.DB) *DiditWebhookHandler {
 		diditClient = didit.NewClient(cfg.DiditAPIKey)
 	}
 	return &DiditWebhookHandler{
-		cfg:   cfg,
-		db:    d,
-		didit: diditClient,
+		cfg:      cfg,
+		db:       d,
+		didit:    diditClient,
+		screener: newKYCScreener(cfg),
 	}
 }
 
+// diditWebhookMaxSkew is how far X-Timestamp may be from now before a delivery is rejected as stale
+// (or replayed).
+const diditWebhookMaxSkew = 5 * time.Minute
+
 // WebhookEvent represents a Didit webhook event
 type WebhookEvent struct {
+	EventID   string                 `json:"event_id,omitempty"`
 	Event     string                 `json:"event"` // e.g., "status.updated", "data.updated"
 	SessionID string                 `json:"session_id"`
 	Data      map[string]interface{} `json:"data,omitempty"`
 	Status    string                 `json:"status,omitempty"`
+	Timestamp int64                  `json:"timestamp,omitempty"` // unix seconds when Didit emitted the event
 }
 
 // Receive handles incoming Didit webhook events and callback redirects
 // Supports both:
 // - GET requests with query params (callback redirect from Didit)
 // - POST requests with JSON body (webhook events from Didit)
+//
+// POSTs must carry a valid X-Signature (HMAC-SHA256 of the raw body with DIDIT_WEBHOOK_SECRET) and
+// a fresh X-Timestamp. GET redirects come from the user's browser and are unsigned, so their status
+// is never trusted: it is only applied as confirmed by the Didit API.
+//
+// Every accepted delivery is archived in didit_webhook_events before it is processed, and its
+// outcome recorded there.
 func (h *DiditWebhookHandler) Receive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
 
-		var sessionID string
-		var status string
+		var d diditDelivery
+		archived := kyc.WebhookDelivery{Kind: "callback"}
+		d.signed = c.Method() != "GET"
 
 		// Handle GET request (callback redirect from Didit)
-		if c.Method() == "GET" {
-			sessionID = c.Query("verificationSessionId")
-			status = c.Query("status")
+		if !d.signed {
+			d.sessionID = c.Query("verificationSessionId")
 			
-			if sessionID == "" {
+			if d.sessionID == "" {
 				// Try alternative query param name
-				sessionID = c.Query("session_id")
+				d.sessionID = c.Query("session_id")
 			}
+			archived.Payload, _ = json.Marshal(fiber.Map{"query": string(c.Request().URI().QueryString())})
 		} else {
 			// Handle POST request (webhook event from Didit)
+			if h.cfg.DiditWebhookSecret == "" {
+				slog.Error("didit webhook secret not configured - rejecting request")
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_secret_not_configured"})
+			}
+			body := c.Body()
+			if err := verifyDiditSignature(body, c.Get("X-Signature"), c.Get("X-Timestamp"), h.cfg.DiditWebhookSecret, time.Now()); err != nil {
+				slog.Warn("didit webhook rejected", "error", err, "remote_ip", c.IP())
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+			}
 			var event WebhookEvent
-			if err := c.BodyParser(&event); err != nil {
+			if err := json.Unmarshal(body, &event); err != nil {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
 			}
-			sessionID = event.SessionID
-			status = event.Status
+			d.rawBody = append([]byte(nil), body...)
+			d.sessionID = event.SessionID
+			d.status = event.Status
+			archived.Kind = "webhook"
+			archived.EventID = kyc.WebhookEventID(event.EventID, body)
+			// X-Timestamp is not covered by the signature, so it cannot stand in for a missing event
+			// time; such deliveries have none (see process).
+			if event.Timestamp != 0 {
+				d.eventAt = time.Unix(event.Timestamp, 0)
+				archived.EventAt = &d.eventAt
+			}
+			archived.Payload = d.rawBody
 		}
 
-		if sessionID == "" {
+		if d.sessionID == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_session_id"})
 		}
+		archived.SessionID = d.sessionID
 
-		// Find user by session ID
-		var userID uuid.UUID
-		err := h.db.Pool.QueryRow(c.Context(), `
-SELECT id
-FROM users
-WHERE kyc_session_id = $1
-`, sessionID).Scan(&userID)
+		archiveID, err := kyc.ArchiveWebhook(c.Context(), h.db.Pool, h.cfg.KYCKeyB64(), archived)
 		if err != nil {
-			// Session not found - might be from another system or invalid
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session_not_found"})
+			slog.Error("failed to archive didit delivery", "error", err, "kind", archived.Kind, "session_id", d.sessionID)
+			if d.signed {
+				// Didit retries the delivery; nothing is applied without its archive row.
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_update_failed"})
+			}
+			return h.redirectAfterCallback(c, d.sessionID)
 		}
 
-		// Process status update
-		// Fetch latest decision from Didit API if available
-		var kycStatus string
-		var decisionData map[string]interface{}
-		
-		if h.didit != nil {
-			decision, err := h.didit.GetSessionDecision(c.Context(), sessionID)
+		if d.signed {
+			processed, err := kyc.WebhookEventProcessed(c.Context(), h.db.Pool, archived.EventID, archiveID)
 			if err != nil {
-				// If API call fails, use status from query/body
-				kycStatus = mapDiditStatus(status)
-			} else {
-				// Map Didit status to our KYC status
-				kycStatus = mapDiditStatus(decision.Status)
-				// Store both Decision and Data from Didit response
-				decisionData = map[string]interface{}{
-					"decision": decision.Decision,
-					"data":     decision.Data,
-				}
+				slog.Error("didit webhook dedupe check failed", "error", err, "event_id", archived.EventID)
+				h.setOutcome(c.Context(), archiveID, kyc.WebhookFailed, "", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_update_failed"})
+			}
+			if processed {
+				h.setOutcome(c.Context(), archiveID, kyc.WebhookDuplicate, "", nil)
+				return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "duplicate": true})
 			}
-		} else {
-			// If no Didit client, use status from query/body
-			kycStatus = mapDiditStatus(status)
 		}
 
-		// Store decision data as JSONB (includes both Decision and Data)
-		decisionJSON, _ := json.Marshal(decisionData)
-
-		// Update user KYC status
-		_, err = h.db.Pool.Exec(c.Context(), `
-UPDATE users
-SET kyc_status = $1,
-    kyc_data = $2,
-    kyc_verified_at = CASE WHEN $1 = 'verified' THEN now() ELSE kyc_verified_at END,
-    updated_at = now()
-WHERE id = $3
-`, kycStatus, decisionJSON, userID)
-		if err != nil {
+		outcome, kycStatus, err := h.process(c.Context(), d)
+		h.setOutcome(c.Context(), archiveID, outcome, kycStatus, err)
+
+		switch {
+		case outcome == kyc.WebhookSessionNotFound:
+			// Session not found - might be from another system or invalid
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session_not_found"})
+		case outcome == kyc.WebhookFailed:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_update_failed"})
+		case !d.signed:
+			// For GET requests (callback redirect), redirect to success page
+			return h.redirectAfterCallback(c, d.sessionID)
+		case outcome == kyc.WebhookStale:
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "stale": true})
 		}
+		// For POST requests (webhook), return JSON
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "status": kycStatus})
+	}
+}
 
-		// For GET requests (callback redirect), redirect to success page
-		if c.Method() == "GET" {
-			// Redirect to frontend with success message
-			successURL := h.cfg.GitHubOAuthSuccessRedirectURL
-			if successURL == "" && h.cfg.FrontendBaseURL != "" {
-				successURL = strings.TrimSuffix(h.cfg.FrontendBaseURL, "/")
-			}
-			if successURL != "" {
-				// Add query params to indicate success
-				redirectURL := fmt.Sprintf("%s?kyc=verified&session_id=%s", successURL, sessionID)
-				return c.Redirect(redirectURL, fiber.StatusFound)
-			}
+// diditDelivery is a webhook delivery or callback redirect to apply to its KYC session.
+type diditDelivery struct {
+	signed    bool // a webhook whose signature was verified
+	sessionID string
+	status    string    // Didit status from the webhook body; "" for callbacks
+	eventAt   time.Time // when Didit emitted the webhook; zero when the body does not say
+	rawBody   []byte
+}
+
+// process applies d: the session's latest decision is fetched from the Didit API (falling back to
+// the signed body's status for webhooks), screened and stored. It returns the outcome and the KYC
+// status the delivery resolved to.
+func (h *DiditWebhookHandler) process(ctx context.Context, d diditDelivery) (outcome string, kycStatus string, err error) {
+	// Find user by session ID
+	var userID uuid.UUID
+	var override *string
+	err = h.db.Pool.QueryRow(ctx, `
+SELECT user_id, screening_override
+FROM kyc_sessions
+WHERE session_id = $1
+`, d.sessionID).Scan(&userID, &override)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return kyc.WebhookSessionNotFound, "", nil
+	}
+	if err != nil {
+		return kyc.WebhookFailed, "", err
+	}
+
+	// Process status update
+	// Fetch latest decision from Didit API if available
+	var decisionData map[string]interface{}
+	// Without an event time the body's status can only fill in a session that has no decision yet:
+	// the zero time loses to any stored one. A decision fetched from the Didit API is dated below.
+	decidedAt := d.eventAt
+	
+	if h.didit != nil {
+		fetchedAt := time.Now()
+		decision, err := h.didit.GetSessionDecision(ctx, d.sessionID)
+		if err != nil {
+			// If API call fails, use status from the signed body
+			kycStatus = kyc.MapDiditStatus(d.status)
+		} else {
+			// Didit's current decision is at least as new as the event that announced it.
+			decidedAt = fetchedAt
+			// Map Didit status to our KYC status
+			kycStatus = kyc.MapDiditStatus(decision.Status)
+			// Store Decision, Data and extra fields from Didit response
+			decisionData = kyc.DecisionDocument(decision)
 		}
+	} else {
+		// If no Didit client, use status from the signed body
+		kycStatus = kyc.MapDiditStatus(d.status)
+	}
 
-		// For POST requests (webhook), return JSON
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "status": kycStatus})
+	if !d.signed && decisionData == nil {
+		// Unconfirmed redirect: leave the stored status alone; the signed webhook will update it.
+		return kyc.WebhookUnconfirmed, "", nil
+	}
+
+	// Verified sessions are screened; without a decision to screen, a configured screening blocks.
+	kycStatus, screening := h.screener.Apply(ctx, kycStatus, decisionData)
+	kycStatus = kyc.ApplyOverride(kycStatus, override)
+
+	// Store decision data as JSONB (includes both Decision and Data)
+	decisionJSON, _ := json.Marshal(decisionData)
+
+	// Update user KYC status
+	source, payload := "webhook", d.rawBody
+	if !d.signed {
+		source, payload = "callback", decisionJSON
+	}
+	saved, err := kyc.SaveDecision(ctx, h.db.Pool, h.cfg.KYCKeyB64(), userID, source, payload, kyc.Decision{
+		SessionID: d.sessionID,
+		Status:    kycStatus,
+		Data:      decisionJSON,
+		Screening: screening,
+		DecidedAt: decidedAt,
+	})
+	if err != nil {
+		slog.Error("failed to update kyc status", "error", err, "session_id", d.sessionID)
+		return kyc.WebhookFailed, kycStatus, err
+	}
+	if !saved {
+		// The session already holds a newer decision (e.g. a late delivery after a status poll).
+		slog.Info("stale didit decision ignored", "session_id", d.sessionID, "source", source, "status", kycStatus, "decided_at", decidedAt)
+		return kyc.WebhookStale, kycStatus, nil
+	}
+	return kyc.WebhookApplied, kycStatus, nil
+}
+
+// setOutcome records the outcome of an archived delivery; a failure to record it is only logged.
+func (h *DiditWebhookHandler) setOutcome(ctx context.Context, id uuid.UUID, outcome string, kycStatus string, procErr error) {
+	errMsg := ""
+	if procErr != nil {
+		errMsg = procErr.Error()
+	}
+	if err := kyc.SetWebhookOutcome(ctx, h.db.Pool, id, outcome, kycStatus, errMsg); err != nil {
+		slog.Warn("failed to record didit delivery outcome", "error", err, "archive_id", id, "outcome", outcome)
+	}
+}
+
+// redirectAfterCallback sends the user's browser back to the frontend after Didit's callback.
+func (h *DiditWebhookHandler) redirectAfterCallback(c *fiber.Ctx, sessionID string) error {
+	// Redirect to frontend with success message
+	successURL := h.cfg.GitHubOAuthSuccessRedirectURL
+	if successURL == "" && h.cfg.FrontendBaseURL != "" {
+		successURL = strings.TrimSuffix(h.cfg.FrontendBaseURL, "/")
 	}
+	if successURL != "" {
+		// Add query params to indicate success
+		redirectURL := fmt.Sprintf("%s?kyc=verified&session_id=%s", successURL, sessionID)
+		return c.Redirect(redirectURL, fiber.StatusFound)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 }
 
+// verifyDiditSignature checks a Didit delivery: X-Signature is the hex HMAC-SHA256 of the raw body
+// keyed with the webhook secret, and X-Timestamp (unix seconds) must be within diditWebhookMaxSkew
+// of now. The signature does not cover X-Timestamp, so it is only a freshness check.
+func verifyDiditSignature(body []byte, signature string, timestamp string, secret string, now time.Time) error {
+	signature, timestamp = strings.TrimSpace(signature), strings.TrimSpace(timestamp)
+	if signature == "" || timestamp == "" {
+		return fmt.Errorf("missing signature or timestamp")
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > diditWebhookMaxSkew || skew < -diditWebhookMaxSkew {
+		return fmt.Errorf("stale timestamp")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(want)) != 1 {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
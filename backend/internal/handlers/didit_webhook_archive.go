@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/kyc"
+)
+
+// ListDeliveries returns archived Didit webhook deliveries and callbacks, newest first, without
+// their payloads. Optional filters: session_id, outcome. Paged with limit (max 200) and offset.
+func (h *DiditWebhookHandler) ListDeliveries() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sessionID := strings.TrimSpace(c.Query("session_id"))
+		outcome := strings.TrimSpace(c.Query("outcome"))
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 200 {
+			limit = 50
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, kind, event_id, session_id, event_at, outcome, kyc_status, error, attempts, received_at, processed_at,
+       payload IS NOT NULL OR payload_enc IS NOT NULL
+FROM didit_webhook_events
+WHERE ($1 = '' OR session_id = $1)
+  AND ($2 = '' OR outcome = $2)
+ORDER BY received_at DESC
+LIMIT $3 OFFSET $4
+`, sessionID, outcome, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "deliveries_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var d kyc.WebhookDelivery
+			var eventID, session *string
+			var hasPayload bool
+			if err := rows.Scan(&d.ID, &d.Kind, &eventID, &session, &d.EventAt, &d.Outcome, &d.KYCStatus, &d.Error, &d.Attempts, &d.ReceivedAt, &d.ProcessedAt, &hasPayload); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "deliveries_list_failed"})
+			}
+			m := deliveryJSON(d)
+			m["event_id"] = eventID
+			m["session_id"] = session
+			m["has_payload"] = hasPayload
+			out = append(out, m)
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "deliveries_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"deliveries": out})
+	}
+}
+
+// GetDelivery returns one archived delivery with its decrypted payload.
+func (h *DiditWebhookHandler) GetDelivery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_delivery_id"})
+		}
+		d, err := kyc.LoadWebhook(c.Context(), h.db.Pool, h.cfg.KYCKeyB64(), id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "delivery_not_found"})
+		}
+		if err != nil {
+			slog.Error("failed to load didit delivery", "archive_id", id, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "delivery_fetch_failed"})
+		}
+		m := deliveryJSON(d)
+		m["event_id"] = d.EventID
+		m["session_id"] = d.SessionID
+		m["payload"] = json.RawMessage(d.Payload)
+		if len(d.Payload) == 0 {
+			m["payload"] = nil
+		}
+		adminID, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Info("admin viewed didit delivery", "admin_user_id", adminID, "archive_id", id)
+		return c.Status(fiber.StatusOK).JSON(m)
+	}
+}
+
+// Reprocess applies an archived delivery again, as if Didit had just sent it: the session's
+// decision is re-fetched from the Didit API, and the webhook's own status is only used when that
+// fails and no newer decision is stored. The new outcome replaces the recorded one.
+func (h *DiditWebhookHandler) Reprocess() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_delivery_id"})
+		}
+		archived, err := kyc.LoadWebhook(c.Context(), h.db.Pool, h.cfg.KYCKeyB64(), id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "delivery_not_found"})
+		}
+		if err != nil {
+			slog.Error("failed to load didit delivery", "archive_id", id, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reprocess_failed"})
+		}
+		if archived.SessionID == "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "delivery_has_no_session"})
+		}
+
+		d := diditDelivery{signed: archived.Kind == "webhook", sessionID: archived.SessionID}
+		if d.signed {
+			var event WebhookEvent
+			if err := json.Unmarshal(archived.Payload, &event); err != nil {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "delivery_payload_unreadable"})
+			}
+			d.status = event.Status
+			d.rawBody = archived.Payload
+			if archived.EventAt != nil {
+				d.eventAt = *archived.EventAt
+			}
+		}
+
+		outcome, kycStatus, procErr := h.process(c.Context(), d)
+		h.setOutcome(c.Context(), id, outcome, kycStatus, procErr)
+
+		adminID, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Info("admin reprocessed didit delivery", "admin_user_id", adminID, "archive_id", id, "outcome", outcome, "kyc_status", kycStatus)
+		if outcome == kyc.WebhookFailed {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reprocess_failed", "outcome": outcome})
+		}
+		var status any
+		if kycStatus != "" {
+			status = kycStatus
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "outcome": outcome, "kyc_status": status})
+	}
+}
+
+func deliveryJSON(d kyc.WebhookDelivery) fiber.Map {
+	var processedAt *string
+	if d.ProcessedAt != nil {
+		s := d.ProcessedAt.Format(time.RFC3339)
+		processedAt = &s
+	}
+	return fiber.Map{
+		"id":           d.ID,
+		"kind":         d.Kind,
+		"event_at":     d.EventAt,
+		"outcome":      d.Outcome,
+		"kyc_status":   d.KYCStatus,
+		"error":        d.Error,
+		"attempts":     d.Attempts,
+		"received_at":  d.ReceivedAt.Format(time.RFC3339),
+		"processed_at": processedAt,
+	}
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 )
@@ -18,7 +22,9 @@ func NewEcosystemsPublicHandler(d *db.DB) *EcosystemsPublicHandler {
 	return &EcosystemsPublicHandler{db: d}
 }
 
-// GetByID returns one ecosystem by ID with full detail (about, links, key_areas, technologies) and computed stats.
+// GetByID returns one ecosystem by ID with full detail (about, links, key_areas, technologies), its child
+// ecosystems, and computed stats rolled up over them.
+// Non-UUID params fall through to the slug route (Detail).
 func (h *EcosystemsPublicHandler) GetByID() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -26,7 +32,7 @@ func (h *EcosystemsPublicHandler) GetByID() fiber.Handler {
 		}
 		ecoID, err := uuid.Parse(c.Params("id"))
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+			return c.Next()
 		}
 
 		var id uuid.UUID
@@ -34,12 +40,13 @@ func (h *EcosystemsPublicHandler) GetByID() fiber.Handler {
 		var desc, website, logoURL, about *string
 		var linksJSON, keyAreasJSON, technologiesJSON []byte
 		var createdAt, updatedAt time.Time
+		var parentID *uuid.UUID
 		err = h.db.Pool.QueryRow(c.Context(), `
 SELECT e.id, e.slug, e.name, e.description, e.website_url, e.logo_url, e.status, e.created_at, e.updated_at,
-       e.about, e.links, e.key_areas, e.technologies
+       e.about, e.links, e.key_areas, e.technologies, e.parent_id
 FROM ecosystems e
 WHERE e.id = $1 AND e.status = 'active'
-`, ecoID).Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON)
+`, ecoID).Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON, &parentID)
 		if err != nil {
 			if err.Error() == "no rows in result set" {
 				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
@@ -58,25 +65,31 @@ WHERE e.id = $1 AND e.status = 'active'
 			_ = json.Unmarshal(technologiesJSON, &technologies)
 		}
 
-		// Count only verified projects (same as public projects list) so Overview matches Projects tab
+		// Count only verified projects (same as public projects list) so Overview matches Projects tab.
+		// Projects of child ecosystems roll up into the parent.
 		var projectCount int64
 		var contributorsCount int64
 		var openIssuesCount int64
 		var openPRsCount int64
 		_ = h.db.Pool.QueryRow(c.Context(), `
+WITH ep AS (
+  SELECT p.id FROM projects p
+  WHERE p.ecosystem_id IN (SELECT descendant_id FROM ecosystem_closure WHERE ancestor_id = $1)
+    AND p.deleted_at IS NULL AND p.status = 'verified' AND p.needs_metadata = false
+)
 SELECT
-  (SELECT COUNT(*) FROM projects p WHERE p.ecosystem_id = $1 AND p.deleted_at IS NULL AND p.status = 'verified' AND p.needs_metadata = false),
+  (SELECT COUNT(*) FROM ep),
   COALESCE((
     SELECT COUNT(DISTINCT a.author_login)
     FROM (
-      SELECT author_login FROM github_issues WHERE project_id IN (SELECT id FROM projects WHERE ecosystem_id = $1 AND deleted_at IS NULL AND status = 'verified' AND needs_metadata = false) AND author_login IS NOT NULL AND author_login != ''
+      SELECT author_login FROM github_issues WHERE project_id IN (SELECT id FROM ep) AND author_login IS NOT NULL AND author_login != ''
       UNION
-      SELECT author_login FROM github_pull_requests WHERE project_id IN (SELECT id FROM projects WHERE ecosystem_id = $1 AND deleted_at IS NULL AND status = 'verified' AND needs_metadata = false) AND author_login IS NOT NULL AND author_login != ''
+      SELECT author_login FROM github_pull_requests WHERE project_id IN (SELECT id FROM ep) AND author_login IS NOT NULL AND author_login != ''
     ) a
   ), 0),
-  COALESCE((SELECT COUNT(*) FROM github_issues gi INNER JOIN projects p ON p.id = gi.project_id WHERE p.ecosystem_id = $1 AND p.deleted_at IS NULL AND p.status = 'verified' AND p.needs_metadata = false AND gi.state = 'open'), 0),
-  COALESCE((SELECT COUNT(*) FROM github_pull_requests gpr INNER JOIN projects p ON p.id = gpr.project_id WHERE p.ecosystem_id = $1 AND p.deleted_at IS NULL AND p.status = 'verified' AND p.needs_metadata = false AND gpr.state = 'open'), 0)
-`, ecoID, ecoID, ecoID, ecoID).Scan(&projectCount, &contributorsCount, &openIssuesCount, &openPRsCount)
+  COALESCE((SELECT COUNT(*) FROM github_issues gi WHERE gi.project_id IN (SELECT id FROM ep) AND gi.state = 'open'), 0),
+  COALESCE((SELECT COUNT(*) FROM github_pull_requests gpr WHERE gpr.project_id IN (SELECT id FROM ep) AND gpr.state = 'open'), 0)
+`, ecoID).Scan(&projectCount, &contributorsCount, &openIssuesCount, &openPRsCount)
 
 		out := fiber.Map{
 			"id":                   id.String(),
@@ -92,6 +105,8 @@ SELECT
 			"links":                links,
 			"key_areas":            keyAreas,
 			"technologies":         technologies,
+			"parent_id":            parentID,
+			"children":             h.children(c.Context(), id),
 			"project_count":        projectCount,
 			"contributors_count":   contributorsCount,
 			"open_issues_count":    openIssuesCount,
@@ -101,9 +116,151 @@ SELECT
 	}
 }
 
-// ListActive returns active ecosystems with computed counts:
-// - project_count: number of projects assigned to the ecosystem
-// - user_count: number of distinct project owners in the ecosystem
+// Detail is the public ecosystem page, looked up by slug: the ecosystem's description and links, its
+// parent and child ecosystems, a preview of its most-starred verified projects, and the aggregates
+// (contributors, monthly contribution trend, top languages) precomputed by the sync worker into
+// ecosystem_stats. Projects and stats include child ecosystems. stats is null until the first
+// computation has run.
+func (h *EcosystemsPublicHandler) Detail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		slug := strings.ToLower(strings.TrimSpace(c.Params("slug")))
+		if slug == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_slug"})
+		}
+		previewLimit := c.QueryInt("projects_limit", 6)
+		if previewLimit <= 0 || previewLimit > 24 {
+			previewLimit = 6
+		}
+
+		var id uuid.UUID
+		var name, status string
+		var desc, website, logoURL, about *string
+		var linksJSON, keyAreasJSON, technologiesJSON []byte
+		var createdAt, updatedAt time.Time
+		var parentID *uuid.UUID
+		var parentSlug, parentName *string
+		err := h.db.Pool.QueryRow(c.Context(), `
+SELECT e.id, e.slug, e.name, e.description, e.website_url, e.logo_url, e.status, e.created_at, e.updated_at,
+       e.about, e.links, e.key_areas, e.technologies, pe.id, pe.slug, pe.name
+FROM ecosystems e
+LEFT JOIN ecosystems pe ON pe.id = e.parent_id AND pe.status = 'active'
+WHERE e.slug = $1 AND e.status = 'active'
+`, slug).Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &about, &linksJSON, &keyAreasJSON, &technologiesJSON,
+			&parentID, &parentSlug, &parentName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+
+		var links, keyAreas, technologies interface{}
+		if len(linksJSON) > 0 {
+			_ = json.Unmarshal(linksJSON, &links)
+		}
+		if len(keyAreasJSON) > 0 {
+			_ = json.Unmarshal(keyAreasJSON, &keyAreas)
+		}
+		if len(technologiesJSON) > 0 {
+			_ = json.Unmarshal(technologiesJSON, &technologies)
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT p.id, p.github_full_name, p.description, p.language, p.stars_count, p.forks_count
+FROM projects p
+WHERE p.ecosystem_id IN (SELECT descendant_id FROM ecosystem_closure WHERE ancestor_id = $1)
+  AND p.deleted_at IS NULL AND p.status = 'verified' AND p.needs_metadata = false
+ORDER BY p.stars_count DESC NULLS LAST, p.created_at DESC
+LIMIT $2
+`, id, previewLimit)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+		defer rows.Close()
+		projects := []fiber.Map{}
+		for rows.Next() {
+			var projectID uuid.UUID
+			var fullName string
+			var projectDesc, language *string
+			var stars, forks *int
+			if err := rows.Scan(&projectID, &fullName, &projectDesc, &language, &stars, &forks); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+			}
+			projects = append(projects, fiber.Map{
+				"id":               projectID.String(),
+				"github_full_name": fullName,
+				"description":      projectDesc,
+				"language":         language,
+				"stars_count":      stars,
+				"forks_count":      forks,
+			})
+		}
+		rows.Close()
+
+		var stats fiber.Map
+		var projectCount, contributorsCount, openIssuesCount, openPRsCount, mergedPRsCount int64
+		var topLanguagesJSON, trendJSON []byte
+		var computedAt time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT project_count, contributors_count, open_issues_count, open_prs_count, merged_prs_count,
+       top_languages, contribution_trend, computed_at
+FROM ecosystem_stats
+WHERE ecosystem_id = $1
+`, id).Scan(&projectCount, &contributorsCount, &openIssuesCount, &openPRsCount, &mergedPRsCount,
+			&topLanguagesJSON, &trendJSON, &computedAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+		if err == nil {
+			var topLanguages, trend interface{}
+			_ = json.Unmarshal(topLanguagesJSON, &topLanguages)
+			_ = json.Unmarshal(trendJSON, &trend)
+			stats = fiber.Map{
+				"project_count":      projectCount,
+				"contributors_count": contributorsCount,
+				"open_issues_count":  openIssuesCount,
+				"open_prs_count":     openPRsCount,
+				"merged_prs_count":   mergedPRsCount,
+				"top_languages":      topLanguages,
+				"contribution_trend": trend,
+				"computed_at":        computedAt,
+			}
+		}
+
+		var parent fiber.Map
+		if parentID != nil {
+			parent = fiber.Map{"id": parentID.String(), "slug": parentSlug, "name": parentName}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":           id.String(),
+			"slug":         slug,
+			"name":         name,
+			"description":  desc,
+			"website_url":  website,
+			"logo_url":     logoURL,
+			"status":       status,
+			"created_at":   createdAt,
+			"updated_at":   updatedAt,
+			"about":        about,
+			"links":        links,
+			"key_areas":    keyAreas,
+			"technologies": technologies,
+			"parent":       parent,
+			"children":     h.children(c.Context(), id),
+			"projects":     projects,
+			"stats":        stats,
+		})
+	}
+}
+
+// ListActive returns active ecosystems with computed counts, rolled up over child ecosystems:
+// - project_count: number of projects assigned to the ecosystem or its descendants
+// - user_count: number of distinct project owners among those projects
+// parent_id lets clients render the hierarchy.
 func (h *EcosystemsPublicHandler) ListActive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -121,10 +278,12 @@ SELECT
   e.status,
   e.created_at,
   e.updated_at,
-  COUNT(p.id) AS project_count,
+  e.parent_id,
+  COUNT(DISTINCT p.id) AS project_count,
   COUNT(DISTINCT p.owner_user_id) AS user_count
 FROM ecosystems e
-LEFT JOIN projects p ON p.ecosystem_id = e.id AND p.deleted_at IS NULL
+LEFT JOIN ecosystem_closure ec ON ec.ancestor_id = e.id
+LEFT JOIN projects p ON p.ecosystem_id = ec.descendant_id AND p.deleted_at IS NULL
 WHERE e.status = 'active'
 GROUP BY e.id
 ORDER BY e.created_at DESC
@@ -147,10 +306,11 @@ LIMIT 200
 				logoURL    *string
 				createdAt  time.Time
 				updatedAt  time.Time
+				parentID   *uuid.UUID
 				projectCnt int64
 				userCnt    int64
 			)
-			if err := rows.Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &projectCnt, &userCnt); err != nil {
+			if err := rows.Scan(&id, &slug, &name, &desc, &website, &logoURL, &status, &createdAt, &updatedAt, &parentID, &projectCnt, &userCnt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
 			}
 			out = append(out, fiber.Map{
@@ -163,6 +323,7 @@ LIMIT 200
 				"status":        status,
 				"created_at":    createdAt,
 				"updated_at":    updatedAt,
+				"parent_id":     parentID,
 				"project_count": projectCnt,
 				"user_count":    userCnt,
 			})
@@ -171,3 +332,29 @@ LIMIT 200
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ecosystems": out})
 	}
 }
+
+// children lists the active direct child ecosystems of id, for the detail responses. Best effort: a
+// failed lookup yields an empty list rather than failing the page.
+func (h *EcosystemsPublicHandler) children(ctx context.Context, id uuid.UUID) []fiber.Map {
+	out := []fiber.Map{}
+	rows, err := h.db.Pool.Query(ctx, `
+SELECT id, slug, name, logo_url
+FROM ecosystems
+WHERE parent_id = $1 AND status = 'active'
+ORDER BY name
+`, id)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var childID uuid.UUID
+		var slug, name string
+		var logoURL *string
+		if err := rows.Scan(&childID, &slug, &name, &logoURL); err != nil {
+			return out
+		}
+		out = append(out, fiber.Map{"id": childID.String(), "slug": slug, "name": name, "logo_url": logoURL})
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/mail"
+)
+
+// EmailLoginHandler implements passwordless magic-link login for users without a wallet or GitHub.
+type EmailLoginHandler struct {
+	cfg    config.Config
+	db     *db.DB
+	mailer mail.Mailer
+}
+
+func NewEmailLoginHandler(cfg config.Config, d *db.DB, mailer mail.Mailer) *EmailLoginHandler {
+	return &EmailLoginHandler{cfg: cfg, db: d, mailer: mailer}
+}
+
+type emailStartRequest struct {
+	Email string `json:"email"`
+	// Redirect is the frontend origin to return to after the link is clicked (validated like OAuth redirects).
+	Redirect string `json:"redirect,omitempty"`
+}
+
+// Start emails a single-use login link. The response is the same whether or not an account exists.
+func (h *EmailLoginHandler) Start() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.PublicBaseURL == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "public_base_url_not_configured"})
+		}
+
+		var req emailStartRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		email, err := auth.NormalizeEmail(req.Email)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_email"})
+		}
+		if req.Redirect != "" && !isAllowedRedirectURI(req.Redirect, h.cfg) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "redirect_uri_not_allowed"})
+		}
+
+		ttl := 15 * time.Minute
+		token, err := auth.CreateEmailLoginToken(c.Context(), h.db.Pool, email, req.Redirect, ttl)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_create_failed"})
+		}
+
+		link := strings.TrimSuffix(h.cfg.PublicBaseURL, "/") + "/auth/email/callback?token=" + url.QueryEscape(token)
+		err = h.mailer.Send(c.Context(), mail.Message{
+			To:      email,
+			Subject: "Your Grainlify sign-in link",
+			Text: "Click the link below to sign in to Grainlify:\n\n" + link + "\n\n" +
+				"The link expires in 15 minutes and can be used once. If you didn't request it, you can ignore this email.",
+		})
+		if err != nil {
+			slog.Error("magic link email send failed", "error", err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "email_send_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":         true,
+			"expires_in": int(ttl.Seconds()),
+		})
+	}
+}
+
+// Callback consumes the magic-link token, resolves (or creates) the user for the email identity,
+// and redirects to the frontend with a JWT like the OAuth login callbacks.
+func (h *EmailLoginHandler) Callback() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.JWTSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+		}
+		token := c.Query("token")
+		if token == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_token"})
+		}
+
+		email, redirectURI, err := auth.ConsumeEmailLoginToken(c.Context(), h.db.Pool, token)
+		if err != nil {
+			if errors.Is(err, auth.ErrEmailTokenInvalid) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_or_expired_token"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_lookup_failed"})
+		}
+
+		user, err := auth.ResolveOAuthIdentity(c.Context(), h.db.Pool, auth.EmailIdentity(email), nil)
+		if err != nil {
+			slog.Error("email identity resolve failed", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_upsert_failed"})
+		}
+
+		jwtToken, err := issueSessionJWT(c, h.cfg, h.db, user.ID, user.Role, "", "", h.cfg.TokenTTL(user.Role, h.cfg.JWTLoginTTL))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+
+		redirectBase := redirectURI
+		if redirectBase == "" {
+			redirectBase = h.cfg.FrontendBaseURL
+		}
+		if redirectBase != "" {
+			if ru, err := url.Parse(strings.TrimSuffix(redirectBase, "/") + "/auth/callback"); err == nil {
+				q := ru.Query()
+				q.Set("token", jwtToken)
+				q.Set("provider", auth.ProviderEmail)
+				ru.RawQuery = q.Encode()
+				return c.Redirect(ru.String(), fiber.StatusFound)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token": jwtToken,
+			"user": fiber.Map{
+				"id":   user.ID.String(),
+				"role": user.Role,
+			},
+			"provider": auth.ProviderEmail,
+		})
+	}
+}
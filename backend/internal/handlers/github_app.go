@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/url"
@@ -17,6 +16,7 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
 )
 
 type GitHubAppHandler struct {
@@ -175,12 +175,13 @@ func (h *GitHubAppHandler) HandleInstallationCallback() fiber.Handler {
 		if state != "" {
 			var storedUserID *uuid.UUID
 			var storedKind string
+			// Consume atomically so the same state cannot be replayed.
 			err := h.db.Pool.QueryRow(c.Context(), `
-SELECT user_id, kind
-FROM oauth_states
+DELETE FROM oauth_states
 WHERE state = $1
   AND expires_at > now()
   AND kind = 'github_app_install'
+RETURNING user_id, kind
 `, state).Scan(&storedUserID, &storedKind)
 			if errors.Is(err, pgx.ErrNoRows) {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_or_expired_state"})
@@ -192,9 +193,6 @@ WHERE state = $1
 			if storedUserID != nil {
 				userID = *storedUserID
 			}
-
-			// Clean up state
-			_, _ = h.db.Pool.Exec(c.Context(), `DELETE FROM oauth_states WHERE state = $1`, state)
 		}
 
 		// If we don't have userID, we can't create projects - just redirect
@@ -336,7 +334,7 @@ SELECT id, status FROM projects WHERE github_full_name = $1
 			_, _ = h.db.Pool.Exec(ctx, `
 UPDATE projects
 SET github_repo_id = $2,
-    status = 'verified',
+    `+verifiedStatusSQL(h.cfg.ProjectReviewRequired)+`,
     verified_at = COALESCE(verified_at, now()),
     verification_error = NULL,
     github_app_installation_id = $3,
@@ -355,7 +353,9 @@ WHERE id = $1
 			_, _ = h.db.Pool.Exec(ctx, `
 INSERT INTO sync_jobs (project_id, job_type, status, run_at)
 VALUES ($1, 'sync_issues', 'pending', now()),
-       ($1, 'sync_prs', 'pending', now())
+       ($1, 'sync_prs', 'pending', now()),
+       ($1, 'sync_commits', 'pending', now())
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
 `, projectID)
 			
 			slog.Info("enqueued sync jobs for existing project",
@@ -367,10 +367,11 @@ VALUES ($1, 'sync_issues', 'pending', now()),
 			continue
 		}
 
-		// Prepare tags from topics
-		var tagsJSON []byte = []byte("[]")
-		if len(repo.Topics) > 0 {
-			tagsJSON, _ = json.Marshal(repo.Topics)
+		// Prepare tags from topics, resolved to canonical tag slugs
+		tagsJSON, err := tags.CanonicalizeJSON(ctx, h.db.Pool, repo.Topics)
+		if err != nil {
+			slog.Warn("repo topics not resolved to tags", "repo", repo.FullName, "error", err)
+			tagsJSON = []byte("[]")
 		}
 
 		// Insert project
@@ -398,6 +399,12 @@ RETURNING id
 			)
 			continue
 		}
+		if err := syncProjectOwner(ctx, h.db.Pool, projectID, userID); err != nil {
+			slog.Warn("project owner membership not recorded", "project_id", projectID, "error", err)
+		}
+		if err := tags.SyncProject(ctx, h.db.Pool, projectID); err != nil {
+			slog.Warn("project tags index not updated", "project_id", projectID, "error", err)
+		}
 
 		createdCount++
 		slog.Info("created project from GitHub App installation",
@@ -410,7 +417,7 @@ RETURNING id
 		_, _ = h.db.Pool.Exec(ctx, `
 UPDATE projects
 SET github_repo_id = $2,
-    status = 'verified',
+    `+verifiedStatusSQL(h.cfg.ProjectReviewRequired)+`,
     verified_at = now(),
     verification_error = NULL,
     github_app_installation_id = $3,
@@ -423,7 +430,9 @@ WHERE id = $1
 		_, _ = h.db.Pool.Exec(ctx, `
 INSERT INTO sync_jobs (project_id, job_type, status, run_at)
 VALUES ($1, 'sync_issues', 'pending', now()),
-       ($1, 'sync_prs', 'pending', now())
+       ($1, 'sync_prs', 'pending', now()),
+       ($1, 'sync_commits', 'pending', now())
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
 `, projectID)
 
 		slog.Info("verified project and enqueued sync jobs",
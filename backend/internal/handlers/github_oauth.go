@@ -113,7 +113,12 @@ VALUES ($1, $2, 'github_link', $3)
 		// - repo: access private repos + read repo metadata
 		// - admin:repo_hook: create webhooks
 		// - read:org: helps when dealing with org-owned repos
-		authURL, err := github.AuthorizeURL(h.cfg.GitHubOAuthClientID, effectiveGitHubRedirect(h.cfg), state, []string{"read:user", "user:email", "repo", "admin:repo_hook", "read:org"})
+		// - admin:org_hook: only with ?org_hooks=true, to link an organization webhook
+		scopes := []string{"read:user", "user:email", "repo", "admin:repo_hook", "read:org"}
+		if c.QueryBool("org_hooks") {
+			scopes = append(scopes, "admin:org_hook")
+		}
+		authURL, err := github.AuthorizeURL(h.cfg.GitHubOAuthClientID, effectiveGitHubRedirect(h.cfg), state, scopes)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
 		}
@@ -234,15 +239,16 @@ func (h *GitHubOAuthHandler) CallbackUnified() fiber.Handler {
 			"encoded_state_length", len(encodedState),
 		)
 
-		// Validate CSRF token against database (OAuth 2.0 security requirement)
+		// Validate and consume the CSRF token in one statement (OAuth 2.0 security requirement).
+		// DELETE ... RETURNING makes the state single-use: a replayed callback finds no row.
 		var storedKind string
 		var stateUserID *uuid.UUID
 		var storedRedirectURI *string
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT kind, user_id, redirect_uri
-FROM oauth_states
+DELETE FROM oauth_states
 WHERE state = $1
   AND expires_at > now()
+RETURNING kind, user_id, redirect_uri
 `, csrfToken).Scan(&storedKind, &stateUserID, &storedRedirectURI)
 		if errors.Is(err, pgx.ErrNoRows) {
 			slog.Warn("OAuth callback - state not found or expired",
@@ -308,9 +314,6 @@ WHERE state = $1
 			)
 		}
 
-		// Delete used state to prevent replay attacks
-		_, _ = h.db.Pool.Exec(c.Context(), `DELETE FROM oauth_states WHERE state = $1`, csrfToken)
-
 		tr, err := github.ExchangeCode(c.Context(), code, github.OAuthConfig{
 			ClientID:     h.cfg.GitHubOAuthClientID,
 			ClientSecret: h.cfg.GitHubOAuthClientSecret,
@@ -328,6 +331,15 @@ WHERE state = $1
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_encrypt_failed"})
 		}
+		// Refresh tokens are only issued when the app has token expiration enabled.
+		var encRefresh []byte
+		if tr.RefreshToken != "" {
+			encRefresh, err = cryptox.EncryptAESGCM(encKey, []byte(tr.RefreshToken))
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_encrypt_failed"})
+			}
+		}
+		now := time.Now().UTC()
 
 		gh := github.NewClient()
 		u, err := gh.GetUser(c.Context(), tr.AccessToken)
@@ -368,8 +380,9 @@ RETURNING id, role
 		}
 
 		_, err = h.db.Pool.Exec(c.Context(), `
-INSERT INTO github_accounts (user_id, github_user_id, login, avatar_url, access_token, token_type, scope)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO github_accounts (user_id, github_user_id, login, avatar_url, access_token, token_type, scope,
+  refresh_token, token_expires_at, refresh_token_expires_at, token_status, token_checked_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'active', now())
 ON CONFLICT (user_id) DO UPDATE SET
   github_user_id = EXCLUDED.github_user_id,
   login = EXCLUDED.login,
@@ -377,20 +390,37 @@ ON CONFLICT (user_id) DO UPDATE SET
   access_token = EXCLUDED.access_token,
   token_type = EXCLUDED.token_type,
   scope = EXCLUDED.scope,
+  refresh_token = EXCLUDED.refresh_token,
+  token_expires_at = EXCLUDED.token_expires_at,
+  refresh_token_expires_at = EXCLUDED.refresh_token_expires_at,
+  token_status = 'active',
+  token_checked_at = now(),
   updated_at = now()
-`, userID, u.ID, u.Login, u.AvatarURL, encToken, tr.TokenType, tr.Scope)
+`, userID, u.ID, u.Login, u.AvatarURL, encToken, tr.TokenType, tr.Scope, encRefresh, tr.ExpiresAt(now), tr.RefreshTokenExpiresAt(now))
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "github_account_upsert_failed"})
 		}
+		if err := github.SaveAccountProfile(c.Context(), h.db.Pool, userID, u); err != nil {
+			slog.Warn("failed to store github profile", "error", err, "user_id", userID)
+		}
 
 		// Ensure users.github_user_id is set (idempotent).
 		_, _ = h.db.Pool.Exec(c.Context(), `
 UPDATE users SET github_user_id = $2, updated_at = now() WHERE id = $1
 `, userID, u.ID)
 
+		// Attribute contributions made before the account was linked; queued once per login.
+		if _, err := h.db.Pool.Exec(c.Context(), `
+INSERT INTO contribution_backfills (user_id, github_user_id, github_login)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`, userID, u.ID, u.Login); err != nil {
+			slog.Warn("failed to queue contribution backfill", "error", err, "user_id", userID)
+		}
+
 		// For login: issue JWT. For link: we can optionally redirect without token.
 		if storedKind == "github_login" {
-			jwtToken, err := auth.IssueJWT(h.cfg.JWTSecret, userID, role, "", "", 60*time.Minute)
+			jwtToken, err := issueSessionJWT(c, h.cfg, h.db, userID, role, "", "", h.cfg.TokenTTL(role, h.cfg.JWTLoginTTL))
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
 			}
@@ -554,14 +584,27 @@ func (h *GitHubOAuthHandler) Status() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
 		}
 
+		// ?check=true validates the token with GitHub now instead of reporting the last known status.
+		if c.QueryBool("check") {
+			if _, err := github.CheckLinkedToken(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64, github.OAuthConfig{
+				ClientID:     h.cfg.GitHubOAuthClientID,
+				ClientSecret: h.cfg.GitHubOAuthClientSecret,
+			}); err != nil && err.Error() != "github_not_linked" {
+				slog.Warn("github status: token check failed", "user_id", userID, "error", err)
+			}
+		}
+
 		var githubUserID int64
 		var login string
 		var avatarURL *string
+		var tokenStatus string
+		var checkedAt, expiresAt *time.Time
+		var refreshable bool
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT github_user_id, login, avatar_url
+SELECT github_user_id, login, avatar_url, token_status, token_checked_at, token_expires_at, refresh_token IS NOT NULL
 FROM github_accounts
 WHERE user_id = $1
-`, userID).Scan(&githubUserID, &login, &avatarURL)
+`, userID).Scan(&githubUserID, &login, &avatarURL, &tokenStatus, &checkedAt, &expiresAt, &refreshable)
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
 				"linked": false,
@@ -581,6 +624,14 @@ WHERE user_id = $1
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"linked": true,
 			"github": githubMap,
+			"token": fiber.Map{
+				"status":      tokenStatus,
+				"checked_at":  checkedAt,
+				"expires_at":  expiresAt,
+				"refreshable": refreshable,
+			},
+			// A revoked token keeps the account linked but sync and verification stop until re-linked.
+			"needs_relink": tokenStatus == github.TokenStatusRevoked,
 		})
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
 	"github.com/jagadeesh/grainlify/backend/internal/ingest"
 )
 
@@ -27,7 +28,7 @@ type GitHubWebhooksHandler struct {
 func NewGitHubWebhooksHandler(cfg config.Config, d *db.DB, b bus.Bus) *GitHubWebhooksHandler {
 	var ingestor *ingest.GitHubWebhookIngestor
 	if d != nil && d.Pool != nil {
-		ingestor = &ingest.GitHubWebhookIngestor{Pool: d.Pool}
+		ingestor = &ingest.GitHubWebhookIngestor{Pool: d.Pool, Bus: b}
 	}
 	return &GitHubWebhooksHandler{cfg: cfg, db: d, bus: b, ing: ingestor}
 }
@@ -104,7 +105,23 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 			sigPreview = sigPreview[:20] + "..."
 		}
 
-		if !verifyGitHubSignature(h.cfg.GitHubWebhookSecret, body, sig) {
+		// Repositories whose repo hook has a per-project secret only accept that secret (and the previous
+		// one during a rotation); everything else (org, app and legacy hooks) uses the global secret.
+		// The repository is read before verification only to pick the secret.
+		secrets := []string{h.cfg.GitHubWebhookSecret}
+		var target ghWebhookEnvelope
+		if err := json.Unmarshal(body, &target); err == nil && target.Repository != nil && target.Repository.ID != 0 && h.db != nil && h.db.Pool != nil {
+			projectSecrets, ok, err := github.WebhookSecretsForRepo(c.Context(), h.db.Pool, target.Repository.ID, h.cfg.TokenEncKeyB64, h.cfg.WebhookSecretGrace)
+			if err != nil {
+				slog.Error("GitHub webhook secret lookup failed", "delivery_id", delivery, "error", err)
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_secret_lookup_failed"})
+			}
+			if ok {
+				secrets = projectSecrets
+			}
+		}
+
+		if !verifyGitHubSignature(body, sig, secrets...) {
 			slog.Warn("GitHub webhook signature verification FAILED",
 				"delivery_id", delivery,
 				"event", event,
@@ -132,11 +149,12 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 		}
 
 		ev := events.GitHubWebhookReceived{
-			DeliveryID:   delivery,
-			Event:        event,
-			Action:       action,
-			RepoFullName: repoFullName,
-			Payload:      body,
+			DeliveryID:     delivery,
+			Event:          event,
+			Action:         action,
+			RepoFullName:   repoFullName,
+			HookTargetType: hookInstallationTargetType,
+			Payload:        body,
 		}
 
 		slog.Info("GitHub webhook event parsed",
@@ -214,17 +232,27 @@ func (h *GitHubWebhooksHandler) Receive() fiber.Handler {
 	}
 }
 
-func verifyGitHubSignature(secret string, body []byte, header string) bool {
+// verifyGitHubSignature reports whether header signs body with any of secrets. More than one secret
+// is accepted while a rotated project webhook secret is in its grace window.
+func verifyGitHubSignature(body []byte, header string, secrets ...string) bool {
 	// GitHub uses: X-Hub-Signature-256: sha256=<hex>
 	if !strings.HasPrefix(header, "sha256=") {
 		return false
 	}
 	gotHex := strings.ToLower(strings.TrimPrefix(header, "sha256="))
-	mac := hmac.New(sha256.New, []byte(secret))
-	_, _ = mac.Write(body)
-	want := mac.Sum(nil)
-	wantHex := hexEncodeLower(want)
-	return subtle.ConstantTimeCompare([]byte(gotHex), []byte(wantHex)) == 1
+	ok := false
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		wantHex := hexEncodeLower(mac.Sum(nil))
+		if subtle.ConstantTimeCompare([]byte(gotHex), []byte(wantHex)) == 1 {
+			ok = true
+		}
+	}
+	return ok
 }
 
 func hexEncodeLower(b []byte) string {
@@ -243,6 +271,7 @@ type ghWebhookEnvelope struct {
 }
 
 type ghRepoPayload struct {
+	ID       int64  `json:"id"`
 	FullName string `json:"full_name"`
 }
 
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// History returns the authenticated user's KYC status transitions, newest first.
+func (h *KYCHandler) History() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, old_status, new_status, source, session_id, payload IS NOT NULL OR payload_enc IS NOT NULL, created_at
+FROM kyc_events
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 200
+`, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_history_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id uuid.UUID
+			var oldStatus, newStatus, sessionID *string
+			var source string
+			var hasPayload bool
+			var createdAt time.Time
+			if err := rows.Scan(&id, &oldStatus, &newStatus, &source, &sessionID, &hasPayload, &createdAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_history_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":          id.String(),
+				"old_status":  oldStatus,
+				"new_status":  newStatus,
+				"source":      source,
+				"session_id":  sessionID,
+				"has_payload": hasPayload,
+				"created_at":  createdAt,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "kyc_history_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"events": out})
+	}
+}
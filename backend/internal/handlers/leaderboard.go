@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 )
@@ -17,7 +22,13 @@ func NewLeaderboardHandler(d *db.DB) *LeaderboardHandler {
 	return &LeaderboardHandler{db: d}
 }
 
-// Leaderboard returns top contributors ranked by contributions in verified projects
+// leaderboardPeriods are the periods GET /leaderboard accepts, as precomputed by the sync worker.
+var leaderboardPeriods = map[string]bool{"weekly": true, "monthly": true, "all": true}
+
+// Leaderboard returns top contributors ranked by merged PRs plus closed issues on verified projects,
+// globally or within one ecosystem (?ecosystem= slug or name, child ecosystems included), over
+// ?period= weekly (last 7 days), monthly (last 30 days) or all (default). Rankings are read from
+// leaderboard_snapshots, which the sync worker rebuilds every LEADERBOARD_REFRESH_INTERVAL.
 func (h *LeaderboardHandler) Leaderboard() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -36,86 +47,40 @@ func (h *LeaderboardHandler) Leaderboard() fiber.Handler {
 		if offset < 0 {
 			offset = 0
 		}
+		period := strings.ToLower(strings.TrimSpace(c.Query("period", "all")))
+		if !leaderboardPeriods[period] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_period"})
+		}
+
+		var ecosystemID *uuid.UUID
+		if eco := strings.TrimSpace(c.Query("ecosystem")); eco != "" {
+			var id uuid.UUID
+			err := h.db.Pool.QueryRow(c.Context(), `
+SELECT id FROM ecosystems
+WHERE status = 'active' AND (LOWER(slug) = LOWER($1) OR LOWER(TRIM(name)) = LOWER($1))
+ORDER BY (LOWER(slug) = LOWER($1)) DESC
+LIMIT 1
+`, eco).Scan(&id)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_fetch_failed"})
+			}
+			ecosystemID = &id
+		}
 
-		// Query top contributors by contribution count in verified projects
-		// This query:
-		// 1. Gets all unique author_logins from issues and PRs in verified projects
-		// 2. LEFT JOINs with github_accounts to get user info if they signed up
-		// 3. Shows ALL contributors, whether they signed up or not
-		// 4. Counts their contributions (issues + PRs) in verified projects
 		rows, err := h.db.Pool.Query(c.Context(), `
-WITH all_contributors AS (
-  -- Get all unique contributors from issues in verified projects
-  SELECT DISTINCT i.author_login as login
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login IS NOT NULL 
-    AND i.author_login != ''
-    AND p.status = 'verified'
-  
-  UNION
-  
-  -- Get all unique contributors from PRs in verified projects
-  SELECT DISTINCT pr.author_login as login
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login IS NOT NULL 
-    AND pr.author_login != ''
-    AND p.status = 'verified'
-)
-SELECT 
-  ac.login as username,
-  COALESCE(ga.avatar_url, '') as avatar_url,
-  COALESCE(u.id::text, '') as user_id,
-  (
-    SELECT COUNT(*) 
-    FROM github_issues i
-    INNER JOIN projects p ON i.project_id = p.id
-    WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-  ) +
-  (
-    SELECT COUNT(*) 
-    FROM github_pull_requests pr
-    INNER JOIN projects p ON pr.project_id = p.id
-    WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-  ) as contribution_count,
-  COALESCE(
-    (
-      SELECT ARRAY_AGG(DISTINCT e.name)
-      FROM (
-        SELECT DISTINCT p.ecosystem_id
-        FROM github_issues i
-        INNER JOIN projects p ON i.project_id = p.id
-        WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-        UNION
-        SELECT DISTINCT p.ecosystem_id
-        FROM github_pull_requests pr
-        INNER JOIN projects p ON pr.project_id = p.id
-        WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-      ) contrib_ecosystems
-      INNER JOIN ecosystems e ON contrib_ecosystems.ecosystem_id = e.id
-      WHERE e.status = 'active'
-    ),
-    ARRAY[]::TEXT[]
-  ) as ecosystems
-FROM all_contributors ac
-LEFT JOIN github_accounts ga ON LOWER(ga.login) = LOWER(ac.login)
+SELECT s.rank, s.login, COALESCE(ga.avatar_url, ''), COALESCE(u.id::text, ''),
+       s.merged_prs, s.closed_issues, s.score, s.ecosystems, s.computed_at
+FROM leaderboard_snapshots s
+LEFT JOIN github_accounts ga ON LOWER(ga.login) = LOWER(s.login)
 LEFT JOIN users u ON ga.user_id = u.id
-WHERE (
-  SELECT COUNT(*) 
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-) +
-(
-  SELECT COUNT(*) 
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-) > 0
-ORDER BY contribution_count DESC, ac.login ASC
-LIMIT $1 OFFSET $2
-`, limit, offset)
+WHERE s.period = $1 AND s.ecosystem_id IS NOT DISTINCT FROM $2
+  AND s.rank > $4
+ORDER BY s.rank ASC
+LIMIT $3
+`, period, ecosystemID, limit, offset)
 		if err != nil {
 			slog.Error("failed to fetch leaderboard",
 				"error", err,
@@ -125,27 +90,22 @@ LIMIT $1 OFFSET $2
 		defer rows.Close()
 
 		var leaderboard []fiber.Map
-		rank := offset + 1 // Start rank from offset + 1 for pagination
+		var computedAt time.Time
 		for rows.Next() {
-			var username string
-			var avatarURL *string
-			var userID string
-			var contributionCount int
+			var rank, mergedPRs, closedIssues, score int
+			var username, avatarURL, userID string
 			var ecosystems []string
 
-			if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems); err != nil {
+			if err := rows.Scan(&rank, &username, &avatarURL, &userID, &mergedPRs, &closedIssues, &score, &ecosystems, &computedAt); err != nil {
 				slog.Error("failed to scan leaderboard row",
 					"error", err,
 				)
 				continue
 			}
 
-			// Default avatar if not set - use GitHub avatar URL as fallback
-			avatar := ""
-			if avatarURL != nil && *avatarURL != "" {
-				avatar = *avatarURL
-			} else {
-				// Fallback to GitHub avatar URL if not in database
+			// Fallback to GitHub avatar URL if not in database
+			avatar := avatarURL
+			if avatar == "" {
 				avatar = fmt.Sprintf("https://github.com/%s.png?size=200", username)
 			}
 
@@ -164,20 +124,23 @@ LIMIT $1 OFFSET $2
 				"username":       username,
 				"avatar":         avatar,
 				"user_id":        userID,
-				"contributions":  contributionCount,
+				"contributions":  score,
+				"merged_prs":     mergedPRs,
+				"closed_issues":  closedIssues,
 				"ecosystems":     ecosystems,
-				// For now, set trend to 'same' and score to contribution count
-				// These can be enhanced later with historical data
-				"score":      contributionCount,
+				// For now, set trend to 'same'
+				// This can be enhanced later with historical data
+				"score":      score,
 				"trend":      "same",
 				"trendValue": 0,
 			})
-			rank++
 		}
 
 		// Always return an array, even if empty
 		if leaderboard == nil {
 			leaderboard = []fiber.Map{}
+		} else {
+			c.Set("X-Leaderboard-Computed-At", computedAt.UTC().Format(time.RFC3339))
 		}
 
 		return c.Status(fiber.StatusOK).JSON(leaderboard)
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/metrics"
+)
+
+// MetricsHandler serves Prometheus metrics: sync queue gauges read from the database at scrape
+// time, plus the histograms of jobs run by this process.
+type MetricsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewMetricsHandler(cfg config.Config, d *db.DB) *MetricsHandler {
+	return &MetricsHandler{cfg: cfg, db: d}
+}
+
+func (h *MetricsHandler) Scrape() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.cfg.MetricsToken == "" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not_found"})
+		}
+		got := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(h.cfg.MetricsToken)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_metrics_token"})
+		}
+
+		var buf bytes.Buffer
+		if h.db != nil && h.db.Pool != nil {
+			counts, err := syncJobStateCounts(c, h.db)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "metrics_failed"})
+			}
+			oldest, err := oldestDueJobAge(c, h.db)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "metrics_failed"})
+			}
+			gauge := map[string]float64{}
+			for jobType, byStatus := range counts {
+				for status, n := range byStatus {
+					gauge[metrics.Label("job_type", jobType, "status", status)] = float64(n)
+				}
+			}
+			metrics.WriteGauge(&buf, "grainlify_sync_jobs", "Sync jobs not yet completed, by type and status.", gauge)
+			metrics.WriteGauge(&buf, "grainlify_sync_jobs_oldest_due_seconds", "How long the longest-waiting due sync job has waited.", map[string]float64{"": oldest})
+		}
+		metrics.WriteRegistered(&buf)
+
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Status(fiber.StatusOK).Send(buf.Bytes())
+	}
+}
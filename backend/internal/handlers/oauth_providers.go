@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// OAuthProvidersHandler serves login/link flows for non-GitHub OAuth providers (Google, Discord).
+type OAuthProvidersHandler struct {
+	cfg       config.Config
+	db        *db.DB
+	providers map[string]auth.OAuthProvider
+}
+
+func NewOAuthProvidersHandler(cfg config.Config, d *db.DB) *OAuthProvidersHandler {
+	providers := map[string]auth.OAuthProvider{}
+	if cfg.GoogleOAuthClientID != "" {
+		providers[auth.ProviderGoogle] = auth.NewGoogleProvider(auth.OAuthProviderConfig{
+			ClientID:     cfg.GoogleOAuthClientID,
+			ClientSecret: cfg.GoogleOAuthClientSecret,
+			RedirectURL:  effectiveProviderRedirect(cfg, cfg.GoogleOAuthRedirectURL, auth.ProviderGoogle),
+		})
+	}
+	if cfg.DiscordOAuthClientID != "" {
+		providers[auth.ProviderDiscord] = auth.NewDiscordProvider(auth.OAuthProviderConfig{
+			ClientID:     cfg.DiscordOAuthClientID,
+			ClientSecret: cfg.DiscordOAuthClientSecret,
+			RedirectURL:  effectiveProviderRedirect(cfg, cfg.DiscordOAuthRedirectURL, auth.ProviderDiscord),
+		})
+	}
+	return &OAuthProvidersHandler{cfg: cfg, db: d, providers: providers}
+}
+
+func effectiveProviderRedirect(cfg config.Config, explicit string, provider string) string {
+	if strings.TrimSpace(explicit) != "" {
+		return strings.TrimSpace(explicit)
+	}
+	if cfg.PublicBaseURL != "" {
+		return strings.TrimSuffix(cfg.PublicBaseURL, "/") + "/auth/oauth/" + provider + "/callback"
+	}
+	return ""
+}
+
+// LoginStart redirects to the provider's consent page for login/signup.
+// Accepts optional 'redirect' query parameter (frontend origin), validated like the GitHub flow.
+func (h *OAuthProvidersHandler) LoginStart() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		p, ok := h.providers[c.Params("provider")]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "oauth_provider_not_configured"})
+		}
+
+		redirectURI := c.Query("redirect")
+		if redirectURI != "" {
+			parsedURL, err := url.Parse(redirectURI)
+			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_redirect_uri"})
+			}
+			if !isAllowedRedirectURI(redirectURI, h.cfg) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "redirect_uri_not_allowed"})
+			}
+		}
+
+		csrfToken := randomState(32)
+		_, err := h.db.Pool.Exec(c.Context(), `
+INSERT INTO oauth_states (state, user_id, kind, provider, expires_at, redirect_uri)
+VALUES ($1, NULL, 'oauth_login', $2, $3, $4)
+`, csrfToken, p.Name(), time.Now().UTC().Add(10*time.Minute), redirectURI)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+		}
+
+		authURL, err := p.AuthorizeURL(encodeStateWithRedirect(csrfToken, redirectURI))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
+		}
+		return c.Redirect(authURL, fiber.StatusFound)
+	}
+}
+
+// LinkStart returns the provider URL to link an additional identity to the current user.
+func (h *OAuthProvidersHandler) LinkStart() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		p, ok := h.providers[c.Params("provider")]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "oauth_provider_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		state := randomState(32)
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO oauth_states (state, user_id, kind, provider, expires_at)
+VALUES ($1, $2, 'oauth_link', $3, $4)
+`, state, userID, p.Name(), time.Now().UTC().Add(10*time.Minute))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_create_failed"})
+		}
+
+		authURL, err := p.AuthorizeURL(state)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "auth_url_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"url": authURL})
+	}
+}
+
+// Callback finishes either oauth_login (issues JWT, redirects to frontend) or oauth_link.
+func (h *OAuthProvidersHandler) Callback() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.JWTSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+		}
+		p, ok := h.providers[c.Params("provider")]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "oauth_provider_not_configured"})
+		}
+
+		code := c.Query("code")
+		encodedState := c.Query("state")
+		if code == "" || encodedState == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_code_or_state"})
+		}
+		csrfToken, redirectURI, err := decodeStateWithRedirect(encodedState)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_state_format"})
+		}
+		if redirectURI != "" && !isAllowedRedirectURI(redirectURI, h.cfg) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "redirect_uri_not_allowed"})
+		}
+
+		// Consume state (single use).
+		var kind string
+		var stateUserID *uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+DELETE FROM oauth_states
+WHERE state = $1 AND provider = $2 AND expires_at > now()
+RETURNING kind, user_id
+`, csrfToken, p.Name()).Scan(&kind, &stateUserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_or_expired_state"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "state_lookup_failed"})
+		}
+
+		identity, err := p.Exchange(c.Context(), code)
+		if err != nil {
+			slog.Warn("oauth provider exchange failed", "provider", p.Name(), "error", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token_exchange_failed"})
+		}
+
+		var linkUserID *uuid.UUID
+		switch kind {
+		case "oauth_login":
+		case "oauth_link":
+			if stateUserID == nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_state_user"})
+			}
+			linkUserID = stateUserID
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "wrong_state_kind"})
+		}
+
+		user, err := auth.ResolveOAuthIdentity(c.Context(), h.db.Pool, identity, linkUserID)
+		if err != nil {
+			if errors.Is(err, auth.ErrIdentityLinkedElsewhere) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "identity_linked_to_other_user"})
+			}
+			slog.Error("oauth identity resolve failed", "provider", p.Name(), "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_upsert_failed"})
+		}
+
+		if kind == "oauth_link" {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"ok":       true,
+				"provider": p.Name(),
+			})
+		}
+
+		jwtToken, err := issueSessionJWT(c, h.cfg, h.db, user.ID, user.Role, "", "", h.cfg.TokenTTL(user.Role, h.cfg.JWTLoginTTL))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+
+		redirectBase := redirectURI
+		if redirectBase == "" {
+			redirectBase = h.cfg.FrontendBaseURL
+		}
+		if redirectBase != "" {
+			if ru, err := url.Parse(strings.TrimSuffix(redirectBase, "/") + "/auth/callback"); err == nil {
+				q := ru.Query()
+				q.Set("token", jwtToken)
+				q.Set("provider", p.Name())
+				ru.RawQuery = q.Encode()
+				return c.Redirect(ru.String(), fiber.StatusFound)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"token": jwtToken,
+			"user": fiber.Map{
+				"id":   user.ID.String(),
+				"role": user.Role,
+			},
+			"provider": p.Name(),
+		})
+	}
+}
+
+// Identities lists the external OAuth identities linked to the current user.
+func (h *OAuthProvidersHandler) Identities() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		ids, err := auth.ListIdentities(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "identities_list_failed"})
+		}
+		out := []fiber.Map{}
+		for _, li := range ids {
+			out = append(out, fiber.Map{
+				"provider":     li.Provider,
+				"email":        li.Email,
+				"display_name": li.DisplayName,
+				"avatar_url":   li.AvatarURL,
+				"created_at":   li.CreatedAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"identities": out})
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+var githubLoginRe = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+var orgWebhookEvents = []string{"issues", "pull_request", "pull_request_review", "push", "issue_comment", "pull_request_review_comment", "discussion", "release", "repository"}
+
+// OrganizationsHandler links GitHub organizations with one org-level webhook that covers every repo.
+type OrganizationsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewOrganizationsHandler(cfg config.Config, d *db.DB) *OrganizationsHandler {
+	return &OrganizationsHandler{cfg: cfg, db: d}
+}
+
+type linkOrganizationRequest struct {
+	Login string `json:"login"`
+}
+
+// Link registers an org webhook using the caller's GitHub token (the caller must be an org admin
+// and have granted admin:org_hook) and attaches the org's existing projects to it.
+func (h *OrganizationsHandler) Link() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.PublicBaseURL == "" || h.cfg.GitHubWebhookSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req linkOrganizationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		login := strings.TrimSpace(req.Login)
+		if !githubLoginRe.MatchString(login) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_org_login"})
+		}
+
+		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+		}
+
+		gh := github.NewClient()
+		m, err := gh.GetOrgMembership(c.Context(), linked.AccessToken, login)
+		if err != nil || m.State != "active" || m.Role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "org_admin_required"})
+		}
+		org, err := gh.GetOrg(c.Context(), linked.AccessToken, login)
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "github_org_fetch_failed"})
+		}
+
+		var existingOwner uuid.UUID
+		var existingHook *int64
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id, webhook_id FROM organizations WHERE github_org_id = $1
+`, org.ID).Scan(&existingOwner, &existingHook)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organization_lookup_failed"})
+		}
+		if err == nil && existingHook != nil && existingOwner != userID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "organization_already_linked"})
+		}
+
+		webhookURL := strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/github"
+		hookID := int64(0)
+		if existingHook != nil {
+			hookID = *existingHook
+		} else {
+			wh, err := gh.CreateOrgWebhook(c.Context(), linked.AccessToken, org.Login, github.CreateWebhookRequest{
+				URL:    webhookURL,
+				Secret: h.cfg.GitHubWebhookSecret,
+				Events: orgWebhookEvents,
+				Active: true,
+			})
+			if err != nil {
+				var apiErr *github.GitHubAPIError
+				if errors.As(err, &apiErr) && (apiErr.StatusCode == fiber.StatusNotFound || apiErr.StatusCode == fiber.StatusForbidden) {
+					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+						"error":          "missing_scope",
+						"required_scope": "admin:org_hook",
+					})
+				}
+				slog.Error("org webhook create failed", "org", org.Login, "error", err)
+				return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "webhook_create_failed"})
+			}
+			hookID = wh.ID
+		}
+
+		var orgID uuid.UUID
+		err = h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO organizations (github_org_id, login, owner_user_id, webhook_id, webhook_url, webhook_created_at)
+VALUES ($1, $2, $3, $4, $5, now())
+ON CONFLICT (github_org_id) DO UPDATE SET
+  login = EXCLUDED.login,
+  owner_user_id = EXCLUDED.owner_user_id,
+  webhook_id = EXCLUDED.webhook_id,
+  webhook_url = EXCLUDED.webhook_url,
+  webhook_created_at = COALESCE(organizations.webhook_created_at, EXCLUDED.webhook_created_at),
+  updated_at = now()
+RETURNING id
+`, org.ID, org.Login, userID, hookID, webhookURL).Scan(&orgID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organization_upsert_failed"})
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `
+UPDATE projects SET organization_id = $1, updated_at = now()
+WHERE lower(split_part(github_full_name, '/', 1)) = lower($2)
+  AND deleted_at IS NULL
+`, orgID, org.Login)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_link_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":              orgID.String(),
+			"login":           org.Login,
+			"github_org_id":   org.ID,
+			"webhook_id":      hookID,
+			"projects_linked": ct.RowsAffected(),
+		})
+	}
+}
+
+func (h *OrganizationsHandler) Mine() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT o.id, o.login, o.github_org_id, o.webhook_id, o.webhook_created_at, o.created_at,
+       (SELECT COUNT(*) FROM projects p WHERE p.organization_id = o.id AND p.deleted_at IS NULL)
+FROM organizations o
+WHERE o.owner_user_id = $1
+ORDER BY o.login
+`, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organizations_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id uuid.UUID
+			var login string
+			var githubOrgID int64
+			var webhookID *int64
+			var webhookCreatedAt, createdAt any
+			var projects int64
+			if err := rows.Scan(&id, &login, &githubOrgID, &webhookID, &webhookCreatedAt, &createdAt, &projects); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organizations_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":                 id.String(),
+				"login":              login,
+				"github_org_id":      githubOrgID,
+				"webhook_id":         webhookID,
+				"webhook_created_at": webhookCreatedAt,
+				"created_at":         createdAt,
+				"projects":           projects,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"organizations": out})
+	}
+}
+
+// Unlink deletes the org webhook (best-effort) and detaches the org's projects; projects then
+// fall back to per-repo webhooks via verify or webhook repair.
+func (h *OrganizationsHandler) Unlink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		orgID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_organization_id"})
+		}
+
+		var owner uuid.UUID
+		var login string
+		var webhookID *int64
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id, login, webhook_id FROM organizations WHERE id = $1
+`, orgID).Scan(&owner, &login, &webhookID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "organization_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organization_lookup_failed"})
+		}
+		role, _ := c.Locals(auth.LocalRole).(string)
+		if owner != userID && role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		}
+
+		webhookDeleted := false
+		if webhookID != nil {
+			if linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, owner, h.cfg.TokenEncKeyB64); err == nil {
+				if err := github.NewClient().DeleteOrgWebhook(c.Context(), linked.AccessToken, login, *webhookID); err != nil {
+					slog.Warn("org webhook delete failed", "org", login, "hook_id", *webhookID, "error", err)
+				} else {
+					webhookDeleted = true
+				}
+			}
+		}
+
+		// projects.organization_id is ON DELETE SET NULL.
+		if _, err := h.db.Pool.Exec(c.Context(), `DELETE FROM organizations WHERE id = $1`, orgID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "organization_delete_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "webhook_deleted": webhookDeleted})
+	}
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/payoutaddress"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+// PayoutAddressesHandler manages the Stellar accounts a user is paid to. They are separate from
+// login wallets: a payout address only needs to prove it is controlled by the user (see package
+// payoutaddress), and bounties can only be claimed by users with a verified one.
+type PayoutAddressesHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewPayoutAddressesHandler(cfg config.Config, d *db.DB) *PayoutAddressesHandler {
+	return &PayoutAddressesHandler{cfg: cfg, db: d}
+}
+
+const payoutAddressColumns = `id, address, label, status, challenge_code, challenge_expires_at, verification_method,
+  verification_tx_hash, verified_at, is_primary, created_at`
+
+func (h *PayoutAddressesHandler) scanPayoutAddress(row pgx.Row) (fiber.Map, error) {
+	var id uuid.UUID
+	var address, status, code string
+	var label, method, txHash *string
+	var expiresAt, createdAt time.Time
+	var verifiedAt *time.Time
+	var primary bool
+	if err := row.Scan(&id, &address, &label, &status, &code, &expiresAt, &method, &txHash, &verifiedAt, &primary, &createdAt); err != nil {
+		return nil, err
+	}
+	out := fiber.Map{
+		"id":                   id.String(),
+		"address":              address,
+		"label":                label,
+		"status":               status,
+		"verification_method":  method,
+		"verification_tx_hash": txHash,
+		"verified_at":          verifiedAt,
+		"is_primary":           primary,
+		"created_at":           createdAt,
+	}
+	if status == payoutaddress.StatusPending {
+		challenge := fiber.Map{
+			"code":       code,
+			"message":    payoutaddress.Message(address, code),
+			"expires_at": expiresAt,
+			"expired":    time.Now().After(expiresAt),
+			"deposit":    nil,
+		}
+		if h.cfg.PayoutVerifyAccount != "" {
+			challenge["deposit"] = fiber.Map{"destination": h.cfg.PayoutVerifyAccount, "memo": code}
+		}
+		out["challenge"] = challenge
+	}
+	return out, nil
+}
+
+func payoutAddressUser(c *fiber.Ctx) (uuid.UUID, bool) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	return userID, err == nil
+}
+
+// List returns the current user's payout addresses, primary first.
+func (h *PayoutAddressesHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+payoutAddressColumns+` FROM payout_addresses
+WHERE user_id = $1
+ORDER BY is_primary DESC, created_at
+`, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_addresses_list_failed"})
+		}
+		defer rows.Close()
+		out := []fiber.Map{}
+		for rows.Next() {
+			a, err := h.scanPayoutAddress(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_addresses_list_failed"})
+			}
+			out = append(out, a)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"payout_addresses": out})
+	}
+}
+
+type addPayoutAddressRequest struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+// Add registers a Stellar account as a pending payout address and returns its challenge.
+func (h *PayoutAddressesHandler) Add() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		var req addPayoutAddressRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		address := strings.ToUpper(strings.TrimSpace(req.Address))
+		if !stellar.ValidAccount(address) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+		}
+		label := strings.TrimSpace(req.Label)
+		if len(label) > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "label_too_long"})
+		}
+		code, err := payoutaddress.NewCode()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_create_failed"})
+		}
+		out, err := h.scanPayoutAddress(h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO payout_addresses (user_id, address, label, challenge_code, challenge_expires_at)
+VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+RETURNING `+payoutAddressColumns, userID, address, label, code, time.Now().Add(payoutaddress.ChallengeTTL)))
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_address_exists"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_create_failed"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(out)
+	}
+}
+
+// RenewChallenge gives a pending address a new challenge code, e.g. after the old one expired.
+func (h *PayoutAddressesHandler) RenewChallenge() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payout_address_id"})
+		}
+		code, err := payoutaddress.NewCode()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		out, err := h.scanPayoutAddress(h.db.Pool.QueryRow(c.Context(), `
+UPDATE payout_addresses SET challenge_code = $3, challenge_expires_at = $4, updated_at = now()
+WHERE id = $1 AND user_id = $2 AND status = 'pending'
+RETURNING `+payoutAddressColumns, id, userID, code, time.Now().Add(payoutaddress.ChallengeTTL)))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "pending_payout_address_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+type verifyPayoutAddressRequest struct {
+	Signature string `json:"signature"`
+}
+
+// Verify checks the account key's signature of the challenge message (payoutaddress.Message) and
+// marks the address verified.
+func (h *PayoutAddressesHandler) Verify() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payout_address_id"})
+		}
+		var req verifyPayoutAddressRequest
+		if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Signature) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "signature_required"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_verify_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		var address, status, code string
+		var expiresAt time.Time
+		err = tx.QueryRow(c.Context(), `
+SELECT address, status, challenge_code, challenge_expires_at FROM payout_addresses
+WHERE id = $1 AND user_id = $2
+FOR UPDATE
+`, id, userID).Scan(&address, &status, &code, &expiresAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "payout_address_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_verify_failed"})
+		}
+		if status == payoutaddress.StatusVerified {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_address_verified"})
+		}
+		if time.Now().After(expiresAt) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "challenge_expired"})
+		}
+		if err := payoutaddress.VerifySignature(address, payoutaddress.Message(address, code), req.Signature); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_signature"})
+		}
+		if _, err := payoutaddress.MarkVerified(c.Context(), tx, id, payoutaddress.MethodSignature, nil, time.Now()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_verify_failed"})
+		}
+		out, err := h.scanPayoutAddress(tx.QueryRow(c.Context(), `SELECT `+payoutAddressColumns+` FROM payout_addresses WHERE id = $1`, id))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_verify_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_verify_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// SetPrimary makes a verified address the one bounty payouts default to.
+func (h *PayoutAddressesHandler) SetPrimary() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payout_address_id"})
+		}
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		var status string
+		err = tx.QueryRow(c.Context(), `SELECT status FROM payout_addresses WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "payout_address_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		if status != payoutaddress.StatusVerified {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_address_not_verified"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+UPDATE payout_addresses SET is_primary = false, updated_at = now() WHERE user_id = $1 AND is_primary AND id <> $2
+`, userID, id); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		if _, err := tx.Exec(c.Context(), `UPDATE payout_addresses SET is_primary = true, updated_at = now() WHERE id = $1`, id); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_update_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Remove deletes a payout address. Removing the primary one promotes the oldest other verified
+// address. Payouts already approved keep their destination.
+func (h *PayoutAddressesHandler) Remove() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		userID, ok := payoutAddressUser(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payout_address_id"})
+		}
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_delete_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		var wasPrimary bool
+		err = tx.QueryRow(c.Context(), `
+DELETE FROM payout_addresses WHERE id = $1 AND user_id = $2 RETURNING is_primary
+`, id, userID).Scan(&wasPrimary)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "payout_address_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_delete_failed"})
+		}
+		if wasPrimary {
+			if _, err := tx.Exec(c.Context(), `
+UPDATE payout_addresses SET is_primary = true, updated_at = now()
+WHERE id = (
+  SELECT id FROM payout_addresses WHERE user_id = $1 AND status = 'verified' ORDER BY verified_at LIMIT 1
+)
+`, userID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_delete_failed"})
+			}
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_address_delete_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
+)
+
+// approvalsRequired returns how many admins must approve a payout of amount in asset: none when it
+// is worth at most PAYOUT_APPROVAL_THRESHOLD_USD, otherwise PAYOUT_APPROVAL_QUORUM. Without a current
+// USD price for the asset the payout counts as above the threshold.
+func (h *BountiesHandler) approvalsRequired(c *fiber.Ctx, amount, asset string) (int, error) {
+	raw := h.cfg.PayoutApprovalThresholdUSD
+	if raw == "" {
+		return 0, nil
+	}
+	quorum := max(h.cfg.PayoutApprovalQuorum, 1)
+	threshold, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		slog.Warn("invalid PAYOUT_APPROVAL_THRESHOLD_USD; every payout needs approvals", "value", raw)
+		return quorum, nil
+	}
+	var meta *assets.Asset
+	a, err := assets.Scan(h.db.Pool.QueryRow(c.Context(), `SELECT `+assets.Columns+` FROM assets WHERE code = $1`, asset))
+	switch {
+	case err == nil:
+		meta = &a
+	case !errors.Is(err, pgx.ErrNoRows):
+		return 0, err
+	}
+	if v := assets.NewValue(amount, asset, meta, time.Now()); v.USD != nil {
+		if usd, ok := new(big.Rat).SetString(*v.USD); ok && usd.Cmp(threshold) <= 0 {
+			return 0, nil
+		}
+	}
+	return quorum, nil
+}
+
+// PayoutApprovalsHandler lets admins approve or reject payouts above the approval threshold. A
+// payout awaiting approval is sent once approvals_required admins approved it; one rejection
+// rejects it, and a maintainer can then approve a new payout.
+type PayoutApprovalsHandler struct {
+	db *db.DB
+}
+
+func NewPayoutApprovalsHandler(d *db.DB) *PayoutApprovalsHandler {
+	return &PayoutApprovalsHandler{db: d}
+}
+
+// decisions returns the admin decisions on each payout in ids, oldest first.
+func (h *PayoutApprovalsHandler) decisions(c *fiber.Ctx, ids []uuid.UUID) (map[uuid.UUID][]fiber.Map, error) {
+	out := map[uuid.UUID][]fiber.Map{}
+	for _, id := range ids {
+		out[id] = []fiber.Map{}
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := h.db.Pool.Query(c.Context(), `
+SELECT pa.payout_id, pa.approver_id, ga.login, pa.decision, pa.note, pa.created_at
+FROM payout_approvals pa
+LEFT JOIN github_accounts ga ON ga.user_id = pa.approver_id
+WHERE pa.payout_id = ANY($1)
+ORDER BY pa.created_at, pa.id
+`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var payoutID, approverID uuid.UUID
+		var login, note *string
+		var decision string
+		var at time.Time
+		if err := rows.Scan(&payoutID, &approverID, &login, &decision, &note, &at); err != nil {
+			return nil, err
+		}
+		out[payoutID] = append(out[payoutID], fiber.Map{
+			"approver_id":    approverID.String(),
+			"approver_login": login,
+			"decision":       decision,
+			"note":           note,
+			"created_at":     at,
+		})
+	}
+	return out, rows.Err()
+}
+
+// List returns payouts with their admin decisions, newest first. ?status= (default
+// awaiting_approval; "all" for every status) filters; ?limit= and ?cursor= page.
+func (h *PayoutApprovalsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+		status := strings.ToLower(strings.TrimSpace(c.Query("status", "awaiting_approval")))
+		switch status {
+		case "awaiting_approval", "rejected", "pending", "submitted", "confirmed", "failed":
+		case "all":
+			status = ""
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		var afterAt *time.Time
+		var afterID *uuid.UUID
+		if cursor != nil {
+			id, err := uuid.Parse(cursor.ID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			afterAt, afterID = &cursor.At, &id
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+payoutColumns+` FROM payouts
+WHERE ($2 = '' OR status = $2) AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4))
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`, limit+1, status, afterAt, afterID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+		}
+		defer rows.Close()
+		out := []fiber.Map{}
+		var ids []uuid.UUID
+		var last pagination.Cursor
+		n := 0
+		for rows.Next() {
+			p, at, id, err := scanPayout(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+			}
+			if n++; n > limit {
+				continue
+			}
+			out = append(out, p)
+			ids = append(ids, id)
+			last = pagination.Cursor{At: at, ID: id.String()}
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+		}
+		rows.Close()
+
+		decisions, err := h.decisions(c, ids)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payouts_list_failed"})
+		}
+		for i, p := range out {
+			p["approvals"] = decisions[ids[i]]
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"payouts": out, "next_cursor": pagination.Next(n, limit, last)})
+	}
+}
+
+type payoutDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// Approve records the caller's approval of a payout awaiting approval. The approval that reaches
+// approvals_required moves the payout to pending, and the sync worker sends it.
+func (h *PayoutApprovalsHandler) Approve() fiber.Handler {
+	return h.decide("approve")
+}
+
+// Reject rejects a payout awaiting approval. The bounty keeps its claimant, and a maintainer can
+// approve a new payout.
+func (h *PayoutApprovalsHandler) Reject() fiber.Handler {
+	return h.decide("reject")
+}
+
+func (h *PayoutApprovalsHandler) decide(decision string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		payoutID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_payout_id"})
+		}
+		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		var req payoutDecisionRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+			}
+		}
+		note := strings.TrimSpace(req.Note)
+		if len(note) > 2000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "note_too_long"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		var status string
+		var requestedBy *uuid.UUID
+		err = tx.QueryRow(c.Context(), `SELECT status, approved_by FROM payouts WHERE id = $1 FOR UPDATE`, payoutID).
+			Scan(&status, &requestedBy)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "payout_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_update_failed"})
+		}
+		if status != "awaiting_approval" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "payout_not_awaiting_approval", "status": status})
+		}
+		if requestedBy != nil && *requestedBy == userID {
+			// Whoever asked for the payout cannot also count towards its quorum.
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot_approve_own_payout"})
+		}
+
+		var notePtr *string
+		if note != "" {
+			notePtr = &note
+		}
+		_, err = tx.Exec(c.Context(), `
+INSERT INTO payout_approvals (payout_id, approver_id, decision, note) VALUES ($1, $2, $3, $4)
+`, payoutID, userID, decision, notePtr)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already_decided"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_update_failed"})
+		}
+
+		if decision == "reject" {
+			_, err = tx.Exec(c.Context(), `UPDATE payouts SET status = 'rejected', updated_at = now() WHERE id = $1`, payoutID)
+		} else {
+			_, err = tx.Exec(c.Context(), `
+UPDATE payouts SET status = 'pending', run_at = now(), updated_at = now()
+WHERE id = $1 AND approvals_required <= (
+  SELECT COUNT(*) FROM payout_approvals WHERE payout_id = $1 AND decision = 'approve'
+)
+`, payoutID)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_update_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_update_failed"})
+		}
+
+		out, _, _, err := scanPayout(h.db.Pool.QueryRow(c.Context(), `SELECT `+payoutColumns+` FROM payouts WHERE id = $1`, payoutID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_lookup_failed"})
+		}
+		decisions, err := h.decisions(c, []uuid.UUID{payoutID})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payout_lookup_failed"})
+		}
+		out["approvals"] = decisions[payoutID]
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+const (
+	displayNameMaxLen     = 64
+	avatarOverrideMaxLen  = 2048
+	profileSettingsColumn = `u.public_profile, up.display_name, up.avatar_url,
+       COALESCE(up.show_bio, true), COALESCE(up.show_website, true), COALESCE(up.show_social_links, true),
+       COALESCE(up.show_kyc_status, true), COALESCE(up.show_badges, true)`
+)
+
+// profileSettings controls what public pages (GET /profile/public, GET /contributors/:login) show
+// about a user. Users without a user_profiles row get the defaults: everything visible.
+type profileSettings struct {
+	DisplayName     *string `json:"display_name"`
+	AvatarURL       *string `json:"avatar_url"`
+	PublicProfile   bool    `json:"public_profile"`
+	ShowBio         bool    `json:"show_bio"`
+	ShowWebsite     bool    `json:"show_website"`
+	ShowSocialLinks bool    `json:"show_social_links"`
+	ShowKYCStatus   bool    `json:"show_kyc_status"`
+	ShowBadges      bool    `json:"show_badges"`
+}
+
+func (s *profileSettings) scanArgs() []any {
+	return []any{&s.PublicProfile, &s.DisplayName, &s.AvatarURL,
+		&s.ShowBio, &s.ShowWebsite, &s.ShowSocialLinks, &s.ShowKYCStatus, &s.ShowBadges}
+}
+
+func loadProfileSettings(ctx context.Context, pool *pgxpool.Pool, userID uuid.UUID) (profileSettings, error) {
+	var s profileSettings
+	err := pool.QueryRow(ctx, `
+SELECT `+profileSettingsColumn+`
+FROM users u
+LEFT JOIN user_profiles up ON up.user_id = u.id
+WHERE u.id = $1
+`, userID).Scan(s.scanArgs()...)
+	return s, err
+}
+
+// ProfileSettings returns the authenticated user's public profile settings.
+func (h *UserProfileHandler) ProfileSettings() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		s, err := loadProfileSettings(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			slog.Error("failed to load profile settings", "error", err, "user_id", userID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_settings_fetch_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(s)
+	}
+}
+
+// UpdateProfileSettings changes the fields present in the body and returns the resulting settings.
+// An empty display_name or avatar_url clears the override.
+func (h *UserProfileHandler) UpdateProfileSettings() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req struct {
+			DisplayName     *string `json:"display_name"`
+			AvatarURL       *string `json:"avatar_url"`
+			PublicProfile   *bool   `json:"public_profile"`
+			ShowBio         *bool   `json:"show_bio"`
+			ShowWebsite     *bool   `json:"show_website"`
+			ShowSocialLinks *bool   `json:"show_social_links"`
+			ShowKYCStatus   *bool   `json:"show_kyc_status"`
+			ShowBadges      *bool   `json:"show_badges"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+
+		var updates []string
+		var args []interface{}
+		set := func(column string, value any) {
+			args = append(args, value)
+			updates = append(updates, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+		if req.DisplayName != nil {
+			name := strings.TrimSpace(*req.DisplayName)
+			if utf8.RuneCountInString(name) > displayNameMaxLen || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_display_name", "message": "display_name must be at most 64 characters without control characters"})
+			}
+			set("display_name", nullIfEmpty(name))
+		}
+		if req.AvatarURL != nil {
+			avatar := strings.TrimSpace(*req.AvatarURL)
+			if avatar != "" && (len(avatar) > avatarOverrideMaxLen ||
+				!(strings.HasPrefix(avatar, "https://") || strings.HasPrefix(avatar, "http://"))) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_avatar_url_format"})
+			}
+			set("avatar_url", nullIfEmpty(avatar))
+		}
+		for _, f := range []struct {
+			column string
+			value  *bool
+		}{
+			{"show_bio", req.ShowBio},
+			{"show_website", req.ShowWebsite},
+			{"show_social_links", req.ShowSocialLinks},
+			{"show_kyc_status", req.ShowKYCStatus},
+			{"show_badges", req.ShowBadges},
+		} {
+			if f.value != nil {
+				set(f.column, *f.value)
+			}
+		}
+		if len(updates) == 0 && req.PublicProfile == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no_fields_to_update"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_settings_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		if len(updates) > 0 {
+			_, err = tx.Exec(c.Context(), `
+INSERT INTO user_profiles (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING
+`, userID)
+			if err == nil {
+				args = append(args, userID)
+				_, err = tx.Exec(c.Context(), fmt.Sprintf(`
+UPDATE user_profiles
+SET %s, updated_at = now()
+WHERE user_id = $%d
+`, strings.Join(updates, ", "), len(args)), args...)
+			}
+		}
+		if err == nil && req.PublicProfile != nil {
+			_, err = tx.Exec(c.Context(), `
+UPDATE users SET public_profile = $2, updated_at = now() WHERE id = $1
+`, userID, *req.PublicProfile)
+		}
+		if err == nil {
+			err = tx.Commit(c.Context())
+		}
+		if err != nil {
+			slog.Error("failed to update profile settings", "error", err, "user_id", userID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_settings_update_failed"})
+		}
+
+		s, err := loadProfileSettings(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_settings_fetch_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(s)
+	}
+}
+
+// nullIfEmpty stores an empty override as NULL.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,608 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/programs"
+)
+
+// programStandingsLimit caps the standings returned with a program.
+const programStandingsLimit = 100
+
+// programDepositsLimit caps the deposits returned with a program.
+const programDepositsLimit = 50
+
+type ProgramsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewProgramsHandler(cfg config.Config, d *db.DB) *ProgramsHandler {
+	return &ProgramsHandler{cfg: cfg, db: d}
+}
+
+const programColumns = `id, name, description, budget::text, asset, starts_at, ends_at, scoring, status, escrow_program_id,
+  escrow_balance_units::text, escrow_ledger, deposit_memo, deposited::text, closed_at, created_at, updated_at`
+
+// program is a programs row.
+type program struct {
+	id                 uuid.UUID
+	name               string
+	description        *string
+	budget, asset      string
+	startsAt, endsAt   time.Time
+	scoring            programs.Scoring
+	status             string
+	escrowProgramID    *string
+	escrowBalanceUnits *string
+	escrowLedger       *int64
+	depositMemo        string
+	deposited          string
+	closedAt           *time.Time
+	createdAt          time.Time
+	updatedAt          time.Time
+}
+
+func scanProgram(row pgx.Row) (program, error) {
+	var p program
+	var scoring []byte
+	if err := row.Scan(&p.id, &p.name, &p.description, &p.budget, &p.asset, &p.startsAt, &p.endsAt, &scoring, &p.status,
+		&p.escrowProgramID, &p.escrowBalanceUnits, &p.escrowLedger, &p.depositMemo, &p.deposited, &p.closedAt, &p.createdAt, &p.updatedAt); err != nil {
+		return program{}, err
+	}
+	if err := json.Unmarshal(scoring, &p.scoring); err != nil {
+		return program{}, err
+	}
+	return p, nil
+}
+
+func (p program) json() fiber.Map {
+	var balance *string
+	if p.escrowBalanceUnits != nil {
+		if units, ok := new(big.Int).SetString(*p.escrowBalanceUnits, 10); ok {
+			s := programs.FormatUnits(units)
+			balance = &s
+		}
+	}
+	return fiber.Map{
+		"id":                p.id.String(),
+		"name":              p.name,
+		"description":       p.description,
+		"budget":            p.budget,
+		"asset":             p.asset,
+		"starts_at":         p.startsAt,
+		"ends_at":           p.endsAt,
+		"scoring":           p.scoring,
+		"status":            p.status,
+		"escrow_program_id": p.escrowProgramID,
+		"escrow_balance":    balance,
+		"escrow_ledger":     p.escrowLedger,
+		"deposit_memo":      p.depositMemo,
+		"deposited":         p.deposited,
+		"closed_at":         p.closedAt,
+		"created_at":        p.createdAt,
+		"updated_at":        p.updatedAt,
+	}
+}
+
+// List returns the published (non-draft) programs, latest window first.
+// Query params:
+//   - status: active, closed or cancelled
+func (h *ProgramsHandler) List() fiber.Handler {
+	return h.list(false)
+}
+
+// AdminList is List including drafts.
+func (h *ProgramsHandler) AdminList() fiber.Handler {
+	return h.list(true)
+}
+
+func (h *ProgramsHandler) list(withDrafts bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		status := strings.TrimSpace(c.Query("status"))
+		switch status {
+		case "", programs.StatusActive, programs.StatusClosed, programs.StatusCancelled:
+		case programs.StatusDraft:
+			if !withDrafts {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+			}
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+programColumns+`
+FROM programs
+WHERE ($1 OR status <> 'draft') AND ($2 = '' OR status = $2)
+ORDER BY starts_at DESC, id DESC
+LIMIT 200
+`, withDrafts, status)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "programs_list_failed"})
+		}
+		defer rows.Close()
+		out := []fiber.Map{}
+		for rows.Next() {
+			p, err := scanProgram(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "programs_list_failed"})
+			}
+			out = append(out, p.json())
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "programs_list_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"programs": out})
+	}
+}
+
+// Get returns a published program with its eligible projects and ecosystems and its standings: the
+// final allocations once it is closed, otherwise the live scores of its contributors so far with
+// the share of the budget each would get if it closed now.
+func (h *ProgramsHandler) Get() fiber.Handler {
+	return h.get(false)
+}
+
+// AdminGet is Get for any program, drafts included.
+func (h *ProgramsHandler) AdminGet() fiber.Handler {
+	return h.get(true)
+}
+
+func (h *ProgramsHandler) get(withDrafts bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		programID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_program_id"})
+		}
+		p, err := scanProgram(h.db.Pool.QueryRow(c.Context(), `
+SELECT `+programColumns+` FROM programs WHERE id = $1 AND ($2 OR status <> 'draft')
+`, programID, withDrafts))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "program_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_lookup_failed"})
+		}
+		out := p.json()
+		if out["projects"], out["ecosystems"], err = h.eligibility(c, programID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_lookup_failed"})
+		}
+		if out["deposits"], err = h.deposits(c, programID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_lookup_failed"})
+		}
+		accounts := []string{}
+		if p.status == programs.StatusDraft || p.status == programs.StatusActive {
+			accounts = append(accounts, h.cfg.ProgramDepositAccountList()...)
+		}
+		out["deposit_accounts"] = accounts
+		if p.status == programs.StatusClosed {
+			out["standings"], err = h.allocations(c, programID)
+		} else {
+			out["standings"], err = h.liveStandings(c, p)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_standings_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+// deposits returns the program's latest deposits, credited or not, newest first.
+func (h *ProgramsHandler) deposits(c *fiber.Ctx, programID uuid.UUID) ([]fiber.Map, error) {
+	rows, err := h.db.Pool.Query(c.Context(), `
+SELECT id, account, tx_hash, from_account, amount::text, asset, credited, reason, received_at
+FROM program_deposits
+WHERE program_id = $1
+ORDER BY received_at DESC, id DESC
+LIMIT $2
+`, programID, programDepositsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []fiber.Map{}
+	for rows.Next() {
+		var id uuid.UUID
+		var account, txHash, from, amount, asset string
+		var credited bool
+		var reason *string
+		var at time.Time
+		if err := rows.Scan(&id, &account, &txHash, &from, &amount, &asset, &credited, &reason, &at); err != nil {
+			return nil, err
+		}
+		out = append(out, fiber.Map{
+			"id":          id.String(),
+			"account":     account,
+			"tx_hash":     txHash,
+			"from":        from,
+			"amount":      amount,
+			"asset":       asset,
+			"credited":    credited,
+			"reason":      reason,
+			"received_at": at,
+		})
+	}
+	return out, rows.Err()
+}
+
+func (h *ProgramsHandler) eligibility(c *fiber.Ctx, programID uuid.UUID) ([]fiber.Map, []fiber.Map, error) {
+	projects := []fiber.Map{}
+	rows, err := h.db.Pool.Query(c.Context(), `
+SELECT p.id, p.github_full_name
+FROM program_projects pp JOIN projects p ON p.id = pp.project_id
+WHERE pp.program_id = $1 AND p.deleted_at IS NULL
+ORDER BY p.github_full_name
+`, programID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		var fullName string
+		if err := rows.Scan(&id, &fullName); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		projects = append(projects, fiber.Map{"id": id.String(), "github_full_name": fullName})
+	}
+	rows.Close()
+
+	ecosystems := []fiber.Map{}
+	rows, err = h.db.Pool.Query(c.Context(), `
+SELECT e.id, e.slug, e.name
+FROM program_ecosystems pe JOIN ecosystems e ON e.id = pe.ecosystem_id
+WHERE pe.program_id = $1
+ORDER BY e.name
+`, programID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var slug, name string
+		if err := rows.Scan(&id, &slug, &name); err != nil {
+			return nil, nil, err
+		}
+		ecosystems = append(ecosystems, fiber.Map{"id": id.String(), "slug": slug, "name": name})
+	}
+	return projects, ecosystems, rows.Err()
+}
+
+func (h *ProgramsHandler) allocations(c *fiber.Ctx, programID uuid.UUID) ([]fiber.Map, error) {
+	rows, err := h.db.Pool.Query(c.Context(), `
+SELECT rank, login, pull_requests, merged_pull_requests, issues, commits, score, amount::text, amount_units::text
+FROM program_allocations
+WHERE program_id = $1
+ORDER BY rank
+LIMIT $2
+`, programID, programStandingsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []fiber.Map{}
+	for rows.Next() {
+		var s programs.Score
+		var rank int
+		var amount, units string
+		if err := rows.Scan(&rank, &s.Login, &s.PullRequests, &s.MergedPullRequests, &s.Issues, &s.Commits, &s.Score, &amount, &units); err != nil {
+			return nil, err
+		}
+		out = append(out, standing(rank, s, amount, units))
+	}
+	return out, rows.Err()
+}
+
+// liveStandings scores the program's contributions so far. Amounts are what the budget would give
+// if the program closed now; drafts and programs that have not started have none.
+func (h *ProgramsHandler) liveStandings(c *fiber.Ctx, p program) ([]fiber.Map, error) {
+	out := []fiber.Map{}
+	if p.status == programs.StatusDraft || p.startsAt.After(time.Now()) {
+		return out, nil
+	}
+	scores, err := programs.Scores(c.Context(), h.db.Pool, p.id, p.scoring)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := programs.ToUnits(p.budget)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range programs.Allocate(budget, scores, p.scoring.MinScore) {
+		if len(out) == programStandingsLimit {
+			break
+		}
+		out = append(out, standing(a.Rank, a.Score, programs.FormatUnits(a.Units), a.Units.String()))
+	}
+	return out, nil
+}
+
+func standing(rank int, s programs.Score, amount, units string) fiber.Map {
+	return fiber.Map{
+		"rank":                 rank,
+		"login":                s.Login,
+		"pull_requests":        s.PullRequests,
+		"merged_pull_requests": s.MergedPullRequests,
+		"issues":               s.Issues,
+		"commits":              s.Commits,
+		"score":                s.Score,
+		"amount":               amount,
+		"amount_units":         units,
+	}
+}
+
+type programRequest struct {
+	Name            *string           `json:"name"`
+	Description     *string           `json:"description"`
+	Budget          *json.Number      `json:"budget"`
+	Asset           *string           `json:"asset"`
+	StartsAt        *time.Time        `json:"starts_at"`
+	EndsAt          *time.Time        `json:"ends_at"`
+	Scoring         *programs.Scoring `json:"scoring"`
+	Status          *string           `json:"status"`
+	EscrowProgramID *string           `json:"escrow_program_id"`
+	ProjectIDs      *[]uuid.UUID      `json:"project_ids"`
+	EcosystemIDs    *[]uuid.UUID      `json:"ecosystem_ids"`
+}
+
+// apply copies the request's fields onto p and validates the result. It returns the error code
+// when the program would be invalid.
+func (req programRequest) apply(p *program) string {
+	if req.Name != nil {
+		p.name = strings.TrimSpace(*req.Name)
+	}
+	if p.name == "" || len(p.name) > 200 {
+		return "invalid_name"
+	}
+	if req.Description != nil {
+		d := strings.TrimSpace(*req.Description)
+		p.description = &d
+		if d == "" {
+			p.description = nil
+		}
+	}
+	if req.Budget != nil {
+		amount, err := bounties.ParseAmount(req.Budget.String())
+		if err != nil {
+			return "invalid_budget"
+		}
+		p.budget = amount
+	}
+	if req.Asset != nil {
+		asset, ok := bounties.NormalizeAsset(*req.Asset)
+		if !ok {
+			return "invalid_asset"
+		}
+		p.asset = asset
+	}
+	if req.StartsAt != nil {
+		p.startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		p.endsAt = *req.EndsAt
+	}
+	if !p.endsAt.After(p.startsAt) {
+		return "ends_at_must_be_after_starts_at"
+	}
+	if req.Scoring != nil {
+		p.scoring = *req.Scoring
+	}
+	if p.scoring.Validate() != nil {
+		return "invalid_scoring"
+	}
+	if req.EscrowProgramID != nil {
+		id := strings.TrimSpace(*req.EscrowProgramID)
+		p.escrowProgramID = &id
+		if id == "" {
+			p.escrowProgramID = nil
+		} else if len(id) > 64 {
+			return "invalid_escrow_program_id"
+		}
+	}
+	return ""
+}
+
+// Create adds a program, as a draft unless status is active. Budget, asset, starts_at and ends_at
+// are required; scoring defaults to programs.DefaultScoring.
+func (h *ProgramsHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		var req programRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		if req.Budget == nil || req.Asset == nil || req.StartsAt == nil || req.EndsAt == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "budget_asset_and_window_required"})
+		}
+		p := program{status: programs.StatusDraft, scoring: programs.DefaultScoring}
+		if req.Status != nil {
+			p.status = strings.TrimSpace(*req.Status)
+		}
+		if p.status != programs.StatusDraft && p.status != programs.StatusActive {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		if code := req.apply(&p); code != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+		}
+		var createdBy *uuid.UUID
+		if userIDStr, _ := c.Locals(auth.LocalUserID).(string); userIDStr != "" {
+			if id, err := uuid.Parse(userIDStr); err == nil {
+				createdBy = &id
+			}
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_create_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		scoring, _ := json.Marshal(p.scoring)
+		err = tx.QueryRow(c.Context(), `
+INSERT INTO programs (name, description, budget, asset, starts_at, ends_at, scoring, status, escrow_program_id, created_by)
+VALUES ($1, $2, $3::numeric, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id
+`, p.name, p.description, p.budget, p.asset, p.startsAt, p.endsAt, scoring, p.status, p.escrowProgramID, createdBy).Scan(&p.id)
+		if code := programWriteError(err); code != "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": code})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_create_failed"})
+		}
+		if code, err := setProgramEligibility(c, tx, p.id, req); code != "" || err != nil {
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_create_failed"})
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_create_failed"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": p.id.String()})
+	}
+}
+
+// Update edits a draft or active program; fields left out are kept, and project_ids and
+// ecosystem_ids replace the lists when given. status may publish a draft (active) or cancel it;
+// closed and cancelled programs are final.
+func (h *ProgramsHandler) Update() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		programID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_program_id"})
+		}
+		var req programRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_update_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+		// FOR UPDATE: the close-out job must not close the program halfway through an edit.
+		p, err := scanProgram(tx.QueryRow(c.Context(), `SELECT `+programColumns+` FROM programs WHERE id = $1 FOR UPDATE`, programID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "program_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_update_failed"})
+		}
+		if p.status == programs.StatusClosed || p.status == programs.StatusCancelled {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "program_final", "status": p.status})
+		}
+		if req.Status != nil {
+			to := strings.TrimSpace(*req.Status)
+			allowed := to == p.status || to == programs.StatusCancelled ||
+				(p.status == programs.StatusDraft && to == programs.StatusActive)
+			if !allowed {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "invalid_transition", "status": p.status})
+			}
+			p.status = to
+		}
+		if code := req.apply(&p); code != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+		}
+
+		scoring, _ := json.Marshal(p.scoring)
+		_, err = tx.Exec(c.Context(), `
+UPDATE programs
+SET name = $2, description = $3, budget = $4::numeric, asset = $5, starts_at = $6, ends_at = $7, scoring = $8,
+    status = $9, escrow_program_id = $10, updated_at = now()
+WHERE id = $1
+`, programID, p.name, p.description, p.budget, p.asset, p.startsAt, p.endsAt, scoring, p.status, p.escrowProgramID)
+		if code := programWriteError(err); code != "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": code})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_update_failed"})
+		}
+		if code, err := setProgramEligibility(c, tx, programID, req); code != "" || err != nil {
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_update_failed"})
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": code})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "program_update_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// programWriteError maps a unique violation on escrow_program_id to its error code.
+func programWriteError(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return "escrow_program_id_taken"
+	}
+	return ""
+}
+
+// setProgramEligibility replaces the program's project and ecosystem lists given in req. It returns
+// an error code when one of the ids does not exist.
+func setProgramEligibility(c *fiber.Ctx, tx pgx.Tx, programID uuid.UUID, req programRequest) (string, error) {
+	if req.ProjectIDs != nil {
+		if _, err := tx.Exec(c.Context(), `DELETE FROM program_projects WHERE program_id = $1`, programID); err != nil {
+			return "", err
+		}
+		ct, err := tx.Exec(c.Context(), `
+INSERT INTO program_projects (program_id, project_id)
+SELECT $1, id FROM projects WHERE id = ANY($2) AND deleted_at IS NULL
+`, programID, *req.ProjectIDs)
+		if err != nil {
+			return "", err
+		}
+		if int(ct.RowsAffected()) != len(uniqueUUIDs(*req.ProjectIDs)) {
+			return "unknown_project", nil
+		}
+	}
+	if req.EcosystemIDs != nil {
+		if _, err := tx.Exec(c.Context(), `DELETE FROM program_ecosystems WHERE program_id = $1`, programID); err != nil {
+			return "", err
+		}
+		ct, err := tx.Exec(c.Context(), `
+INSERT INTO program_ecosystems (program_id, ecosystem_id)
+SELECT $1, id FROM ecosystems WHERE id = ANY($2)
+`, programID, *req.EcosystemIDs)
+		if err != nil {
+			return "", err
+		}
+		if int(ct.RowsAffected()) != len(uniqueUUIDs(*req.EcosystemIDs)) {
+			return "unknown_ecosystem", nil
+		}
+	}
+	return "", nil
+}
+
+func uniqueUUIDs(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	out := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		out[id] = struct{}{}
+	}
+	return out
+}
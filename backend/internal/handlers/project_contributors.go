@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// contributorAnalyticsTop caps the top contributors list.
+	contributorAnalyticsTop = 20
+	// contributorAnalyticsMonths is how many months of first-time contributor counts are returned.
+	contributorAnalyticsMonths = 12
+)
+
+// Contributors returns contributor analytics computed from the project's synced PRs: the top
+// contributors, how many of the period's PR authors are new to the project versus returning, how
+// many first-time contributors each of the last 12 months brought, and the time PRs take to merge.
+// ?days= (default 90, max 365) sets the period for the breakdown and the merge times. Bot accounts
+// ("...[bot]") are left out. Any project member or admin.
+func (h *ProjectsHandler) Contributors() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleViewer)
+		if !ok {
+			return err
+		}
+		days := c.QueryInt("days", 90)
+		if days < 1 || days > 365 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_days", "message": "days must be between 1 and 365"})
+		}
+		now := time.Now().UTC()
+		since := now.AddDate(0, 0, -days)
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT author_login,
+       COUNT(*) AS prs,
+       COUNT(*) FILTER (WHERE merged IS TRUE) AS merged_prs,
+       MIN(created_at_github) AS first_pr_at,
+       MAX(created_at_github) AS last_pr_at
+FROM github_pull_requests
+WHERE project_id = $1 AND author_login IS NOT NULL AND author_login <> '' AND author_login NOT LIKE '%[bot]'
+GROUP BY author_login
+ORDER BY merged_prs DESC, prs DESC, author_login ASC
+LIMIT $2
+`, a.projectID, contributorAnalyticsTop)
+		if err != nil {
+			slog.Error("project contributors: top contributors failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+		}
+		top := []fiber.Map{}
+		for rows.Next() {
+			var login string
+			var prs, merged int
+			var firstAt, lastAt *time.Time
+			if err := rows.Scan(&login, &prs, &merged, &firstAt, &lastAt); err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+			}
+			top = append(top, fiber.Map{
+				"login":       login,
+				"prs":         prs,
+				"merged_prs":  merged,
+				"first_pr_at": firstAt,
+				"last_pr_at":  lastAt,
+				"avatar_url":  "https://github.com/" + login + ".png?size=80",
+				"profile_url": "https://github.com/" + login,
+			})
+		}
+		rows.Close()
+
+		// A PR author is new when their first PR to the project was opened within the period.
+		var newAuthors, returningAuthors int
+		err = h.db.Pool.QueryRow(c.Context(), `
+WITH authors AS (
+  SELECT author_login,
+         MIN(created_at_github) AS first_pr_at,
+         BOOL_OR(created_at_github >= $2) AS active
+  FROM github_pull_requests
+  WHERE project_id = $1 AND author_login IS NOT NULL AND author_login <> '' AND author_login NOT LIKE '%[bot]'
+  GROUP BY author_login
+)
+SELECT COUNT(*) FILTER (WHERE active AND first_pr_at >= $2),
+       COUNT(*) FILTER (WHERE active AND first_pr_at < $2)
+FROM authors
+`, a.projectID, since).Scan(&newAuthors, &returningAuthors)
+		if err != nil {
+			slog.Error("project contributors: new vs returning failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+		}
+
+		firstMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(contributorAnalyticsMonths - 1), 0)
+		firstTimers := map[string]int{}
+		rows, err = h.db.Pool.Query(c.Context(), `
+SELECT to_char(date_trunc('month', first_pr_at AT TIME ZONE 'UTC'), 'YYYY-MM') AS month, COUNT(*)
+FROM (
+  SELECT MIN(created_at_github) AS first_pr_at
+  FROM github_pull_requests
+  WHERE project_id = $1 AND author_login IS NOT NULL AND author_login <> '' AND author_login NOT LIKE '%[bot]'
+  GROUP BY author_login
+) firsts
+WHERE first_pr_at >= $2
+GROUP BY 1
+`, a.projectID, firstMonth)
+		if err != nil {
+			slog.Error("project contributors: first-time contributors failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+		}
+		for rows.Next() {
+			var month string
+			var n int
+			if err := rows.Scan(&month, &n); err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+			}
+			firstTimers[month] = n
+		}
+		rows.Close()
+		monthly := make([]fiber.Map, 0, contributorAnalyticsMonths)
+		for m := firstMonth; !m.After(now); m = m.AddDate(0, 1, 0) {
+			key := m.Format("2006-01")
+			monthly = append(monthly, fiber.Map{"month": key, "first_time_contributors": firstTimers[key]})
+		}
+
+		var mergedCount int
+		var avgHours, medianHours *float64
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT COUNT(*),
+       AVG(EXTRACT(EPOCH FROM merged_at_github - created_at_github) / 3600),
+       PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM merged_at_github - created_at_github) / 3600)
+FROM github_pull_requests
+WHERE project_id = $1 AND merged IS TRUE
+  AND merged_at_github >= $2 AND created_at_github IS NOT NULL
+  AND (author_login IS NULL OR author_login NOT LIKE '%[bot]')
+`, a.projectID, since).Scan(&mergedCount, &avgHours, &medianHours)
+		if err != nil {
+			slog.Error("project contributors: time to merge failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributors_fetch_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"project_id":       a.projectID.String(),
+			"days":             days,
+			"top_contributors": top,
+			"authors": fiber.Map{
+				"new":       newAuthors,
+				"returning": returningAuthors,
+			},
+			"first_time_contributors_by_month": monthly,
+			"time_to_merge": fiber.Map{
+				"merged_prs":   mergedCount,
+				"avg_hours":    avgHours,
+				"median_hours": medianHours,
+			},
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
 )
 
 type ProjectDataHandler struct {
@@ -44,26 +46,68 @@ SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND status = 'verified' AND d
 	return projectID, nil
 }
 
+// pageParams reads ?limit= (default 50, max 200) and the optional ?cursor= of the project data
+// lists, writing the error response for a bad cursor (ok is false then).
+func pageParams(c *fiber.Ctx) (limit int, cursor *pagination.Cursor, ok bool, err error) {
+	limit = 50
+	if l := c.QueryInt("limit", 50); l > 0 && l <= 200 {
+		limit = l
+	}
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := pagination.Decode(raw)
+		if err != nil {
+			return 0, nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+		}
+		cursor = &cur
+	}
+	return limit, cursor, true, nil
+}
+
+// numericCursor splits a cursor over a GitHub numeric id into query args; both are nil without one.
+func numericCursor(cursor *pagination.Cursor) (at *time.Time, id *int64, err error) {
+	if cursor == nil {
+		return nil, nil, nil
+	}
+	n, err := strconv.ParseInt(cursor.ID, 10, 64)
+	if err != nil {
+		return nil, nil, pagination.ErrInvalidCursor
+	}
+	return &cursor.At, &n, nil
+}
+
+// Issues lists the project's synced issues, most recently updated first, 50 per page by default.
+// Pass next_cursor back as ?cursor= for the following page.
 func (h *ProjectDataHandler) Issues() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		projectID, err := h.projectIDForRead(c)
 		if err != nil {
 			return err
 		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+		afterAt, afterID, err := numericCursor(cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
 SELECT github_issue_id, number, state, title, body, author_login, url, assignees, labels, comments_count, comments, updated_at_github, last_seen_at
 FROM github_issues
 WHERE project_id = $1
-ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
-LIMIT 50
-`, projectID)
+  AND ($3::timestamptz IS NULL OR (COALESCE(updated_at_github, last_seen_at), github_issue_id) < ($3, $4))
+ORDER BY COALESCE(updated_at_github, last_seen_at) DESC, github_issue_id DESC
+LIMIT $2
+`, projectID, limit+1, afterAt, afterID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
 		}
 		defer rows.Close()
 
 		var out []fiber.Map
+		var last pagination.Cursor
+		n := 0
 		for rows.Next() {
 			var gid int64
 			var number int
@@ -76,6 +120,13 @@ LIMIT 50
 			if err := rows.Scan(&gid, &number, &state, &title, &body, &author, &url, &assigneesJSON, &labelsJSON, &commentsCount, &commentsJSON, &updated, &lastSeen); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "issues_list_failed"})
 			}
+			if n++; n > limit {
+				continue
+			}
+			last = pagination.Cursor{At: lastSeen, ID: strconv.FormatInt(gid, 10)}
+			if updated != nil {
+				last.At = *updated
+			}
 			
 			// Parse JSONB fields
 			var assignees []any
@@ -107,31 +158,43 @@ LIMIT 50
 				"last_seen_at":    lastSeen,
 			})
 		}
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"issues": out})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"issues": out, "next_cursor": pagination.Next(n, limit, last)})
 	}
 }
 
+// PRs lists the project's synced pull requests, most recently updated first, paged like Issues.
 func (h *ProjectDataHandler) PRs() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		projectID, err := h.projectIDForRead(c)
 		if err != nil {
 			return err
 		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+		afterAt, afterID, err := numericCursor(cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
 SELECT github_pr_id, number, state, title, author_login, url, merged, 
        created_at_github, updated_at_github, closed_at_github, merged_at_github, last_seen_at
 FROM github_pull_requests
 WHERE project_id = $1
-ORDER BY COALESCE(updated_at_github, last_seen_at) DESC
-LIMIT 50
-`, projectID)
+  AND ($3::timestamptz IS NULL OR (COALESCE(updated_at_github, last_seen_at), github_pr_id) < ($3, $4))
+ORDER BY COALESCE(updated_at_github, last_seen_at) DESC, github_pr_id DESC
+LIMIT $2
+`, projectID, limit+1, afterAt, afterID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
 		}
 		defer rows.Close()
 
 		var out []fiber.Map
+		var last pagination.Cursor
+		n := 0
 		for rows.Next() {
 			var gid int64
 			var number int
@@ -142,6 +205,13 @@ LIMIT 50
 			if err := rows.Scan(&gid, &number, &state, &title, &author, &url, &merged, &createdAt, &updated, &closedAt, &mergedAt, &lastSeen); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "prs_list_failed"})
 			}
+			if n++; n > limit {
+				continue
+			}
+			last = pagination.Cursor{At: lastSeen, ID: strconv.FormatInt(gid, 10)}
+			if updated != nil {
+				last.At = *updated
+			}
 			out = append(out, fiber.Map{
 				"github_pr_id":    gid,
 				"number":          number,
@@ -157,30 +227,43 @@ LIMIT 50
 				"last_seen_at":    lastSeen,
 			})
 		}
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"prs": out})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"prs": out, "next_cursor": pagination.Next(n, limit, last)})
 	}
 }
 
+// Events lists the project's received webhook deliveries, newest first, paged like Issues.
 func (h *ProjectDataHandler) Events() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		projectID, err := h.projectIDForRead(c)
 		if err != nil {
 			return err
 		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+		var afterAt *time.Time
+		var afterID *string
+		if cursor != nil {
+			afterAt, afterID = &cursor.At, &cursor.ID
+		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
 SELECT delivery_id, event, action, received_at
 FROM github_events
 WHERE project_id = $1
-ORDER BY received_at DESC
-LIMIT 50
-`, projectID)
+  AND ($3::timestamptz IS NULL OR (received_at, delivery_id) < ($3, $4::text))
+ORDER BY received_at DESC, delivery_id DESC
+LIMIT $2
+`, projectID, limit+1, afterAt, afterID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "events_list_failed"})
 		}
 		defer rows.Close()
 
 		var out []fiber.Map
+		var last pagination.Cursor
+		n := 0
 		for rows.Next() {
 			var deliveryID string
 			var event string
@@ -189,6 +272,10 @@ LIMIT 50
 			if err := rows.Scan(&deliveryID, &event, &action, &receivedAt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "events_list_failed"})
 			}
+			if n++; n > limit {
+				continue
+			}
+			last = pagination.Cursor{At: receivedAt, ID: deliveryID}
 			out = append(out, fiber.Map{
 				"delivery_id":  deliveryID,
 				"event":        event,
@@ -196,7 +283,7 @@ LIMIT 50
 				"received_at":  receivedAt,
 			})
 		}
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"events": out})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"events": out, "next_cursor": pagination.Next(n, limit, last)})
 	}
 }
 
@@ -224,8 +311,14 @@ func (h *ProjectDataHandler) authorizeProject(c *fiber.Ctx) (uuid.UUID, bool, er
 	}
 
 	role, _ := c.Locals(auth.LocalRole).(string)
-	ownerOK := owner == userID || role == "admin"
-	return projectID, ownerOK, nil
+	if role == "admin" {
+		return projectID, true, nil
+	}
+	memberOK, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, projectRoleViewer)
+	if err != nil {
+		return uuid.Nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	return projectID, memberOK, nil
 }
 
 
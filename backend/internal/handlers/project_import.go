@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
+)
+
+// importMaxRepos caps how many repos one org import creates; orgImportListLimit caps how many repos
+// are read from GitHub to pick them from.
+const (
+	importMaxRepos     = 100
+	orgImportListLimit = 1000
+)
+
+type importOrgRequest struct {
+	Org           string   `json:"org"`
+	EcosystemName string   `json:"ecosystem_name"`
+	Category      *string  `json:"category,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	// Repos selects repos by name ("repo" or "org/repo"). Leave empty and set All to import every
+	// repo matching the filters.
+	Repos []string `json:"repos,omitempty"`
+	All   bool     `json:"all,omitempty"`
+	// DryRun lists the matching repos without importing anything.
+	DryRun          bool   `json:"dry_run,omitempty"`
+	IncludeForks    bool   `json:"include_forks,omitempty"`
+	IncludeArchived bool   `json:"include_archived,omitempty"`
+	IncludePrivate  bool   `json:"include_private,omitempty"`
+	Language        string `json:"language,omitempty"`
+}
+
+// matches reports whether repo passes the request's filters. Repos the caller cannot push to are
+// never imported since verification would fail on them.
+func (r importOrgRequest) matches(repo github.Repo) (bool, string) {
+	switch {
+	case !repo.Permissions.Admin && !repo.Permissions.Push:
+		return false, "insufficient_repo_permissions"
+	case repo.Fork && !r.IncludeForks:
+		return false, "fork"
+	case repo.Archived && !r.IncludeArchived:
+		return false, "archived"
+	case repo.Private && !r.IncludePrivate:
+		return false, "private"
+	case r.Language != "" && !strings.EqualFold(repo.Language, r.Language):
+		return false, "language_mismatch"
+	}
+	return true, ""
+}
+
+// ImportOrg registers several repos of a GitHub org as projects in one request. The org's repos are
+// listed with the caller's linked GitHub token; the caller picks repos by name or imports all that
+// match the filters. Each created project is then verified in the background, as POST
+// /projects/:id/verify would. Repos already registered are reported, never taken over.
+func (h *ProjectsHandler) ImportOrg() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req importOrgRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		org := strings.TrimSpace(req.Org)
+		if !githubLoginRe.MatchString(org) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_org_login"})
+		}
+		req.Language = strings.TrimSpace(req.Language)
+
+		selected := map[string]bool{}
+		for _, name := range req.Repos {
+			name = strings.TrimSpace(name)
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				if !strings.EqualFold(name[:i], org) {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "repo_not_in_org", "repo": name})
+				}
+				name = name[i+1:]
+			}
+			if name != "" {
+				selected[strings.ToLower(name)] = true
+			}
+		}
+		if !req.DryRun && !req.All && len(selected) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "repos_required", "message": "Select repos or set all to import every matching repo"})
+		}
+		if req.All && len(selected) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "repos_and_all_exclusive"})
+		}
+
+		var ecosystemID uuid.UUID
+		ecosystemName := strings.TrimSpace(req.EcosystemName)
+		if !req.DryRun {
+			if ecosystemName == "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_required", "message": "Ecosystem name is required"})
+			}
+			err = h.db.Pool.QueryRow(c.Context(), `
+SELECT id
+FROM ecosystems
+WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
+  AND status = 'active'
+`, ecosystemName).Scan(&ecosystemID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_not_found", "message": "No active ecosystem found with that name. Please select from available ecosystems."})
+			}
+		}
+
+		linked, err := github.GetLinkedAccount(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+		}
+		repos, err := github.NewClient().ListOrgRepos(c.Context(), linked.AccessToken, org, orgImportListLimit)
+		if err != nil {
+			var apiErr *github.GitHubAPIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == fiber.StatusNotFound {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "github_org_not_found"})
+			}
+			slog.Warn("org import: repo listing failed", "org", org, "user_id", userID, "error", err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "github_repos_fetch_failed"})
+		}
+
+		registered, err := h.registeredRepos(c.Context(), repos)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+
+		// Selected repos the org listing did not return are reported as not found.
+		found := map[string]bool{}
+		var candidates []github.Repo
+		results := []fiber.Map{}
+		for _, repo := range repos {
+			name := strings.ToLower(repo.FullName[strings.LastIndex(repo.FullName, "/")+1:])
+			if len(selected) > 0 {
+				if !selected[name] {
+					continue
+				}
+				found[name] = true
+			}
+			if ok, reason := req.matches(repo); !ok {
+				if len(selected) > 0 || req.DryRun {
+					results = append(results, fiber.Map{"github_full_name": repo.FullName, "result": "skipped", "reason": reason})
+				}
+				continue
+			}
+			if _, ok := registered[repo.FullName]; ok {
+				results = append(results, fiber.Map{"github_full_name": repo.FullName, "result": "already_registered", "project_id": registered[repo.FullName]})
+				continue
+			}
+			candidates = append(candidates, repo)
+		}
+		for name := range selected {
+			if !found[name] {
+				results = append(results, fiber.Map{"github_full_name": org + "/" + name, "result": "not_found"})
+			}
+		}
+
+		if req.DryRun {
+			for _, repo := range candidates {
+				results = append(results, fiber.Map{
+					"github_full_name": repo.FullName,
+					"result":           "importable",
+					"language":         repo.Language,
+					"private":          repo.Private,
+					"stars_count":      repo.StargazersCount,
+					"description":      repo.Description,
+				})
+			}
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{"org": org, "dry_run": true, "results": results})
+		}
+		if len(candidates) > importMaxRepos {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too_many_repos", "max": importMaxRepos, "matching": len(candidates)})
+		}
+
+		tagsJSON, err := tags.CanonicalizeJSON(c.Context(), h.db.Pool, req.Tags)
+		if err != nil {
+			slog.Error("org import: project tags not resolved", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_import_failed"})
+		}
+
+		type created struct {
+			id       uuid.UUID
+			fullName string
+		}
+		var toVerify []created
+		for _, repo := range candidates {
+			var language *string
+			if repo.Language != "" {
+				language = &repo.Language
+			}
+			var projectID uuid.UUID
+			err := h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO projects (owner_user_id, github_full_name, ecosystem_id, language, tags, category, status, organization_id, provider)
+VALUES ($1, $2, $3, $4, $5, $6, 'pending_verification',
+        (SELECT o.id FROM organizations o WHERE lower(o.login) = lower(split_part($2, '/', 1))),
+        'github')
+ON CONFLICT (github_full_name) DO NOTHING
+RETURNING id
+`, userID, repo.FullName, ecosystemID, language, tagsJSON, req.Category).Scan(&projectID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Registered by someone else since the lookup above.
+				results = append(results, fiber.Map{"github_full_name": repo.FullName, "result": "already_registered"})
+				continue
+			}
+			if err != nil {
+				slog.Warn("org import: project not created", "repo", repo.FullName, "error", err)
+				results = append(results, fiber.Map{"github_full_name": repo.FullName, "result": "failed", "error": "project_create_failed"})
+				continue
+			}
+			if err := syncProjectOwner(c.Context(), h.db.Pool, projectID, userID); err != nil {
+				slog.Warn("project owner membership not recorded", "project_id", projectID, "error", err)
+			}
+			if err := tags.SyncProject(c.Context(), h.db.Pool, projectID); err != nil {
+				slog.Warn("project tags index not updated", "project_id", projectID, "error", err)
+			}
+			toVerify = append(toVerify, created{id: projectID, fullName: repo.FullName})
+			results = append(results, fiber.Map{
+				"github_full_name": repo.FullName,
+				"result":           "created",
+				"project_id":       projectID.String(),
+				"status":           "pending_verification",
+			})
+		}
+
+		// One at a time so a large import does not open dozens of GitHub calls at once. Outcomes land
+		// on each project (status / verification_error) like a single verify.
+		go func() {
+			for _, p := range toVerify {
+				h.verifyAndWebhook(context.Background(), p.id, userID, p.fullName, nil)
+			}
+		}()
+
+		slog.Info("org repos imported",
+			"org", org,
+			"user_id", userID,
+			"ecosystem", ecosystemName,
+			"created", len(toVerify),
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"org": org, "created": len(toVerify), "results": results})
+	}
+}
+
+// registeredRepos maps the full names of repos that already have a project to the project id.
+func (h *ProjectsHandler) registeredRepos(ctx context.Context, repos []github.Repo) (map[string]string, error) {
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		names = append(names, r.FullName)
+	}
+	rows, err := h.db.Pool.Query(ctx, `
+SELECT github_full_name, id FROM projects WHERE github_full_name = ANY($1)
+`, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var name string
+		var id uuid.UUID
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		out[name] = id.String()
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/repohost"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
+)
+
+// patchProjectRequest holds the fields an owner may edit after creation. Omitted fields are left
+// alone; an empty description, language or category clears it, and tags replace the stored list.
+type patchProjectRequest struct {
+	Description        *string   `json:"description,omitempty"`
+	EcosystemName      *string   `json:"ecosystem_name,omitempty"`
+	Language           *string   `json:"language,omitempty"`
+	Tags               *[]string `json:"tags,omitempty"`
+	Category           *string   `json:"category,omitempty"`
+	BotCommentsEnabled *bool     `json:"bot_comments_enabled,omitempty"`
+}
+
+// Patch edits a project's listing details (description, ecosystem, language, tags, category, bot
+// comments). Maintainers, the owner or an admin. Responds with the updated fields.
+func (h *ProjectsHandler) Patch() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleMaintainer)
+		if !ok {
+			return err
+		}
+		projectID := a.projectID
+
+		var req patchProjectRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+
+		var ecosystemID *uuid.UUID
+		if req.EcosystemName != nil {
+			var ecoID uuid.UUID
+			err := h.db.Pool.QueryRow(c.Context(), `
+SELECT id FROM ecosystems WHERE LOWER(TRIM(name)) = LOWER(TRIM($1)) AND status = 'active'
+`, *req.EcosystemName).Scan(&ecoID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_not_found", "message": "No active ecosystem found with that name."})
+			}
+			ecosystemID = &ecoID
+		}
+
+		var tagsJSON []byte
+		if req.Tags != nil {
+			tagsJSON, err = tags.CanonicalizeJSON(c.Context(), h.db.Pool, *req.Tags)
+			if err != nil {
+				slog.Error("project tags not resolved", "project_id", projectID, "error", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_update_failed"})
+			}
+		}
+
+		var fullName string
+		var description, ecosystemName, language, category *string
+		var savedTags []byte
+		var botComments bool
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE projects
+SET description = CASE WHEN $2::text IS NULL THEN description ELSE NULLIF(TRIM($2), '') END,
+    ecosystem_id = COALESCE($3, ecosystem_id),
+    language = CASE WHEN $4::text IS NULL THEN language ELSE NULLIF(TRIM($4), '') END,
+    tags = COALESCE($5, tags),
+    category = CASE WHEN $6::text IS NULL THEN category ELSE NULLIF(TRIM($6), '') END,
+    bot_comments_enabled = COALESCE($7, bot_comments_enabled),
+    updated_at = now()
+WHERE id = $1
+RETURNING github_full_name, description, (SELECT name FROM ecosystems WHERE id = projects.ecosystem_id),
+          language, tags, category, bot_comments_enabled
+`, projectID, req.Description, ecosystemID, req.Language, tagsJSON, req.Category, req.BotCommentsEnabled).Scan(
+			&fullName, &description, &ecosystemName, &language, &savedTags, &category, &botComments)
+		if err != nil {
+			slog.Error("project update failed", "project_id", projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_update_failed"})
+		}
+		if tagsJSON != nil {
+			if err := tags.SyncProject(c.Context(), h.db.Pool, projectID); err != nil {
+				slog.Warn("project tags index not updated", "project_id", projectID, "error", err)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":                   projectID.String(),
+			"github_full_name":     fullName,
+			"description":          description,
+			"ecosystem_name":       ecosystemName,
+			"language":             language,
+			"tags":                 json.RawMessage(savedTags),
+			"category":             category,
+			"bot_comments_enabled": botComments,
+		})
+	}
+}
+
+// Delete removes a project: its repository webhook is deleted on the host (best effort, as on
+// account deletion), then the project row, which cascades to its synced issues, PRs, sync jobs and
+// tokens. The raw webhook log (github_events) is kept for audit, detached from the project. Owner
+// or admin only.
+func (h *ProjectsHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleOwner)
+		if !ok {
+			return err
+		}
+		projectID := a.projectID
+
+		var ownerUserID uuid.UUID
+		var fullName, provider string
+		var webhookID *int64
+		var providerWebhookID *string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id, github_full_name, provider, webhook_id, provider_webhook_id
+FROM projects
+WHERE id = $1
+`, projectID).Scan(&ownerUserID, &fullName, &provider, &webhookID, &providerWebhookID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		hookID := ""
+		switch {
+		case provider == repohost.ProviderGitHub && webhookID != nil && *webhookID != 0:
+			hookID = strconv.FormatInt(*webhookID, 10)
+		case provider != repohost.ProviderGitHub && providerWebhookID != nil:
+			hookID = *providerWebhookID
+		}
+
+		// The token is resolved through the project, so the hook goes before the row.
+		webhookRemoved := false
+		if hookID != "" {
+			webhookRemoved = h.deleteProjectWebhook(c.Context(), provider, projectID, ownerUserID, fullName, hookID)
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM projects WHERE id = $1`, projectID)
+		if err != nil {
+			slog.Error("project delete failed", "project_id", projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_delete_failed"})
+		}
+		if ct.RowsAffected() == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+		}
+
+		actor, _ := c.Locals(auth.LocalUserID).(string)
+		slog.Info("project deleted",
+			"project_id", projectID,
+			"repo", fullName,
+			"provider", provider,
+			"deleted_by", actor,
+			"webhook_removed", webhookRemoved,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":              true,
+			"webhook_removed": webhookRemoved,
+		})
+	}
+}
+
+// Archive takes a verified project out of public listings and stops its syncs and webhook
+// processing. Its synced issues, PRs and commits are kept and still count toward contributor
+// profiles; queued sync jobs are dropped. Owner or admin only.
+func (h *ProjectsHandler) Archive() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleOwner)
+		if !ok {
+			return err
+		}
+
+		var fullName string
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE projects
+SET status = 'archived', archived_at = now(), archived_by = $2, updated_at = now()
+WHERE id = $1 AND status = 'verified' AND deleted_at IS NULL
+RETURNING github_full_name
+`, a.projectID, a.userID).Scan(&fullName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return h.projectStateConflict(c, a.projectID, "project_not_archivable")
+		}
+		if err != nil {
+			slog.Error("project archive failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_archive_failed"})
+		}
+
+		ct, err := h.db.Pool.Exec(c.Context(), `
+UPDATE sync_jobs
+SET status = 'dead', last_error = 'project_archived', locked_at = NULL, locked_by = NULL, finished_at = now(), updated_at = now()
+WHERE project_id = $1 AND status IN ('pending', 'retrying')
+`, a.projectID)
+		if err != nil {
+			slog.Warn("project archive: queued sync jobs not cancelled", "project_id", a.projectID, "error", err)
+		}
+
+		slog.Info("project archived",
+			"project_id", a.projectID,
+			"repo", fullName,
+			"archived_by", a.userID,
+			"jobs_cancelled", ct.RowsAffected(),
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "status": "archived"})
+	}
+}
+
+// Unarchive lists an archived project again and queues a sync to catch up on what changed while it
+// was archived. Owner or admin only.
+func (h *ProjectsHandler) Unarchive() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleOwner)
+		if !ok {
+			return err
+		}
+
+		var fullName string
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE projects
+SET status = 'verified', archived_at = NULL, archived_by = NULL, updated_at = now()
+WHERE id = $1 AND status = 'archived' AND deleted_at IS NULL
+RETURNING github_full_name
+`, a.projectID).Scan(&fullName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return h.projectStateConflict(c, a.projectID, "project_not_archived")
+		}
+		if err != nil {
+			slog.Error("project unarchive failed", "project_id", a.projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_unarchive_failed"})
+		}
+
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+VALUES ($1, 'sync_issues', 'pending', now()),
+       ($1, 'sync_prs', 'pending', now()),
+       ($1, 'sync_commits', 'pending', now())
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, a.projectID)
+		if err != nil {
+			slog.Warn("project unarchive: sync jobs not enqueued", "project_id", a.projectID, "error", err)
+		}
+
+		slog.Info("project unarchived", "project_id", a.projectID, "repo", fullName, "unarchived_by", a.userID)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "status": "verified"})
+	}
+}
+
+// projectStateConflict answers a state transition that matched no row: 404 when the project is
+// gone, otherwise 409 with the given code and the project's current status.
+func (h *ProjectsHandler) projectStateConflict(c *fiber.Ctx, projectID uuid.UUID, code string) error {
+	var status string
+	err := h.db.Pool.QueryRow(c.Context(), `
+SELECT status FROM projects WHERE id = $1 AND deleted_at IS NULL
+`, projectID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": code, "status": status})
+}
+
+// deleteProjectWebhook removes the project's repository hook, reporting whether it is gone. Failures
+// are logged: the project is deleted regardless, and deliveries for unknown repos are ignored.
+func (h *ProjectsHandler) deleteProjectWebhook(ctx context.Context, provider string, projectID uuid.UUID, ownerUserID uuid.UUID, fullName string, hookID string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	host, ok := h.hosts.Get(provider)
+	if !ok {
+		slog.Warn("project delete: webhook cleanup skipped, provider not configured", "project_id", projectID, "provider", provider)
+		return false
+	}
+	token, err := host.Token(ctx, projectID, ownerUserID)
+	if err == nil {
+		err = host.DeleteWebhook(ctx, token, fullName, hookID)
+	}
+	if err != nil {
+		slog.Warn("project delete: webhook cleanup failed",
+			"project_id", projectID,
+			"repo", fullName,
+			"hook_id", hookID,
+			"error", err,
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,458 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// Project member roles, from most to least privileged. Maintainers manage the project day to day
+// (metadata, syncs, webhooks, issue assignment); viewers only read its data.
+const (
+	projectRoleOwner      = "owner"
+	projectRoleMaintainer = "maintainer"
+	projectRoleViewer     = "viewer"
+)
+
+func projectRoleRank(role string) int {
+	switch role {
+	case projectRoleOwner:
+		return 3
+	case projectRoleMaintainer:
+		return 2
+	case projectRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// hasProjectRole reports whether userID holds at least minRole on the project. projects.owner_user_id
+// always counts as owner; anyone else needs an accepted project_members row.
+func hasProjectRole(ctx context.Context, pool *pgxpool.Pool, projectID, ownerID, userID uuid.UUID, minRole string) (bool, error) {
+	if userID == ownerID {
+		return true, nil
+	}
+	var role string
+	err := pool.QueryRow(ctx, `
+SELECT role FROM project_members
+WHERE project_id = $1 AND user_id = $2 AND accepted_at IS NOT NULL
+`, projectID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return projectRoleRank(role) >= projectRoleRank(minRole), nil
+}
+
+// syncProjectOwner makes ownerID the project's owner member after a create or reinstall changed
+// projects.owner_user_id. A previous owner's row is dropped: they lose access as before.
+func syncProjectOwner(ctx context.Context, pool *pgxpool.Pool, projectID, ownerID uuid.UUID) error {
+	_, err := pool.Exec(ctx, `
+WITH dropped AS (
+  DELETE FROM project_members WHERE project_id = $1 AND role = 'owner' AND user_id <> $2
+)
+INSERT INTO project_members (project_id, user_id, role, accepted_at)
+VALUES ($1, $2, 'owner', now())
+ON CONFLICT (project_id, user_id) DO UPDATE SET
+  role = 'owner',
+  accepted_at = COALESCE(project_members.accepted_at, now()),
+  updated_at = now()
+`, projectID, ownerID)
+	return err
+}
+
+// projectAccess is the caller's standing on the project named by :id.
+type projectAccess struct {
+	projectID uuid.UUID
+	userID    uuid.UUID
+	ownerID   uuid.UUID
+	admin     bool
+}
+
+// memberProject loads the project for an action needing minRole (admins always pass), writing the
+// error response when the caller may not act on it (ok is false then).
+func (h *ProjectsHandler) memberProject(c *fiber.Ctx, minRole string) (a projectAccess, ok bool, err error) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	a.userID, err = uuid.Parse(sub)
+	if err != nil {
+		return a, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+	}
+	role, _ := c.Locals(auth.LocalRole).(string)
+	a.admin = role == "admin"
+	a.projectID, err = uuid.Parse(c.Params("id"))
+	if err != nil {
+		return a, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+	}
+
+	err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id FROM projects WHERE id = $1 AND deleted_at IS NULL
+`, a.projectID).Scan(&a.ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return a, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+	}
+	if err != nil {
+		return a, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	if a.admin {
+		return a, true, nil
+	}
+	allowed, err := hasProjectRole(c.Context(), h.db.Pool, a.projectID, a.ownerID, a.userID, minRole)
+	if err != nil {
+		return a, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	if !allowed {
+		return a, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+	}
+	return a, true, nil
+}
+
+type inviteMemberRequest struct {
+	UserID string `json:"user_id"`
+	Login  string `json:"login"`
+	Role   string `json:"role"`
+}
+
+// Members lists the project's members and pending invitations. Any member or admin.
+func (h *ProjectsHandler) Members() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleViewer)
+		if !ok {
+			return err
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT m.user_id, ga.login, m.role, m.invited_by, m.accepted_at, m.created_at
+FROM project_members m
+LEFT JOIN github_accounts ga ON ga.user_id = m.user_id
+WHERE m.project_id = $1
+ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'maintainer' THEN 1 ELSE 2 END, m.created_at
+`, a.projectID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "members_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var userID uuid.UUID
+			var login *string
+			var role string
+			var invitedBy *uuid.UUID
+			var acceptedAt *time.Time
+			var createdAt time.Time
+			if err := rows.Scan(&userID, &login, &role, &invitedBy, &acceptedAt, &createdAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "members_list_failed"})
+			}
+			status := "active"
+			if acceptedAt == nil {
+				status = "pending"
+			}
+			out = append(out, fiber.Map{
+				"user_id":     userID.String(),
+				"login":       login,
+				"role":        role,
+				"status":      status,
+				"invited_by":  invitedBy,
+				"accepted_at": acceptedAt,
+				"created_at":  createdAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"members": out})
+	}
+}
+
+// InviteMember invites a user (by user_id or GitHub login) as maintainer or viewer. Inviting someone
+// who is already a member changes their role instead. Owner or admin only.
+func (h *ProjectsHandler) InviteMember() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleOwner)
+		if !ok {
+			return err
+		}
+
+		var req inviteMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+		if req.Role != projectRoleMaintainer && req.Role != projectRoleViewer {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_role"})
+		}
+
+		var inviteeID uuid.UUID
+		switch login := strings.TrimSpace(req.Login); {
+		case strings.TrimSpace(req.UserID) != "":
+			id, err := uuid.Parse(strings.TrimSpace(req.UserID))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+			}
+			err = h.db.Pool.QueryRow(c.Context(), `SELECT id FROM users WHERE id = $1`, id).Scan(&inviteeID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_lookup_failed"})
+			}
+		case login != "":
+			err := h.db.Pool.QueryRow(c.Context(), `
+SELECT user_id FROM github_accounts WHERE lower(login) = lower($1)
+`, login).Scan(&inviteeID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_lookup_failed"})
+			}
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user_id_or_login_required"})
+		}
+		if inviteeID == a.ownerID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "user_is_owner"})
+		}
+
+		var acceptedAt *time.Time
+		err = h.db.Pool.QueryRow(c.Context(), `
+INSERT INTO project_members (project_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, user_id) DO UPDATE SET
+  role = EXCLUDED.role,
+  updated_at = now()
+RETURNING accepted_at
+`, a.projectID, inviteeID, req.Role, a.userID).Scan(&acceptedAt)
+		if err != nil {
+			slog.Error("project member invite failed", "project_id", a.projectID, "user_id", inviteeID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "member_invite_failed"})
+		}
+
+		status := "active"
+		if acceptedAt == nil {
+			status = "pending"
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"user_id": inviteeID.String(),
+			"role":    req.Role,
+			"status":  status,
+		})
+	}
+}
+
+// AcceptInvitation accepts the caller's pending invitation to the project.
+func (h *ProjectsHandler) AcceptInvitation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+
+		var role string
+		err = h.db.Pool.QueryRow(c.Context(), `
+UPDATE project_members m
+SET accepted_at = now(), updated_at = now()
+FROM projects p
+WHERE m.project_id = $1 AND m.user_id = $2 AND m.accepted_at IS NULL
+  AND p.id = m.project_id AND p.deleted_at IS NULL
+RETURNING m.role
+`, projectID, userID).Scan(&role)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "invitation_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "invitation_accept_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "role": role})
+	}
+}
+
+// RemoveMember removes a member or withdraws an invitation. The owner or an admin may remove
+// anyone but the owner; members may remove themselves (leave, or decline an invitation).
+func (h *ProjectsHandler) RemoveMember() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		if c.Params("user_id") == sub {
+			// Leaving needs no role: a pending invitee has none yet.
+			return h.deleteMember(c)
+		}
+		if _, ok, err := h.memberProject(c, projectRoleOwner); !ok {
+			return err
+		}
+		return h.deleteMember(c)
+	}
+}
+
+func (h *ProjectsHandler) deleteMember(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+	}
+	memberID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+	}
+
+	var ownerID uuid.UUID
+	err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	if memberID == ownerID {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "owner_cannot_be_removed"})
+	}
+
+	ct, err := h.db.Pool.Exec(c.Context(), `
+DELETE FROM project_members WHERE project_id = $1 AND user_id = $2
+`, projectID, memberID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "member_remove_failed"})
+	}
+	if ct.RowsAffected() == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "member_not_found"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+}
+
+type transferOwnershipRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// TransferOwnership hands the project to an existing member; the previous owner stays on as a
+// maintainer. Syncs and webhooks use the new owner's token from then on. Owner or admin only.
+func (h *ProjectsHandler) TransferOwnership() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		a, ok, err := h.memberProject(c, projectRoleOwner)
+		if !ok {
+			return err
+		}
+
+		var req transferOwnershipRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		newOwner, err := uuid.Parse(strings.TrimSpace(req.UserID))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+		}
+		if newOwner == a.ownerID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "user_is_owner"})
+		}
+
+		tx, err := h.db.Pool.Begin(c.Context())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ownership_transfer_failed"})
+		}
+		defer func() { _ = tx.Rollback(c.Context()) }()
+
+		ct, err := tx.Exec(c.Context(), `
+UPDATE project_members SET role = 'owner', updated_at = now()
+WHERE project_id = $1 AND user_id = $2 AND accepted_at IS NOT NULL
+`, a.projectID, newOwner)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ownership_transfer_failed"})
+		}
+		if ct.RowsAffected() == 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "transfer_target_not_member"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+INSERT INTO project_members (project_id, user_id, role, accepted_at)
+VALUES ($1, $2, 'maintainer', now())
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'maintainer', updated_at = now()
+`, a.projectID, a.ownerID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ownership_transfer_failed"})
+		}
+		if _, err := tx.Exec(c.Context(), `
+UPDATE projects SET owner_user_id = $2, updated_at = now() WHERE id = $1
+`, a.projectID, newOwner); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ownership_transfer_failed"})
+		}
+		if err := tx.Commit(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ownership_transfer_failed"})
+		}
+
+		slog.Info("project ownership transferred",
+			"project_id", a.projectID,
+			"from_user_id", a.ownerID,
+			"to_user_id", newOwner,
+			"by_user_id", a.userID,
+		)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true, "owner_user_id": newOwner.String()})
+	}
+}
+
+// MyInvitations lists the caller's pending project invitations.
+func (h *ProjectsHandler) MyInvitations() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT p.id, p.github_full_name, m.role, m.invited_by, m.created_at
+FROM project_members m
+JOIN projects p ON p.id = m.project_id
+WHERE m.user_id = $1 AND m.accepted_at IS NULL AND p.deleted_at IS NULL
+ORDER BY m.created_at DESC
+`, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "invitations_list_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var projectID uuid.UUID
+			var fullName, role string
+			var invitedBy *uuid.UUID
+			var createdAt time.Time
+			if err := rows.Scan(&projectID, &fullName, &role, &invitedBy, &createdAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "invitations_list_failed"})
+			}
+			out = append(out, fiber.Map{
+				"project_id":       projectID.String(),
+				"github_full_name": fullName,
+				"role":             role,
+				"invited_by":       invitedBy,
+				"created_at":       createdAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"invitations": out})
+	}
+}
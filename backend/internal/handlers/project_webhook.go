@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+var projectWebhookEvents = []string{"issues", "pull_request", "pull_request_review", "push", "issue_comment", "pull_request_review_comment", "discussion", "release"}
+
+// RepairWebhook reconciles the project's repository webhook with GitHub: duplicates pointing at
+// this backend are deleted, the remaining hook is rewritten with the current secret and events
+// (or re-created if it was removed), and a ping is sent. Runs async like Verify.
+func (h *ProjectsHandler) RepairWebhook() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		if h.cfg.PublicBaseURL == "" || h.cfg.GitHubWebhookSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "webhook_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		role, _ := c.Locals(auth.LocalRole).(string)
+
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+
+		var ownerUserID uuid.UUID
+		var fullName string
+		var webhookID *int64
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT owner_user_id, github_full_name, webhook_id
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+`, projectID).Scan(&ownerUserID, &fullName, &webhookID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		if role != "admin" {
+			allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, ownerUserID, userID, projectRoleMaintainer)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			}
+			if !allowed {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			}
+		}
+
+		go h.repairWebhook(context.Background(), projectID, fullName, webhookID)
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true})
+	}
+}
+
+func (h *ProjectsHandler) repairWebhook(ctx context.Context, projectID uuid.UUID, fullName string, storedID *int64) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ghToken, err := github.GetProjectToken(ctx, h.db.Pool, h.apps, projectID, h.cfg.TokenEncKeyB64)
+	if err != nil {
+		h.recordWebhookError(ctx, projectID, "github_not_linked")
+		return
+	}
+	if ghToken.Mode == github.AuthModeInstallation {
+		// Events come through the GitHub App's own webhook; a repo hook would only duplicate them.
+		slog.Info("webhook repair skipped: project uses github app installation",
+			"project_id", projectID,
+			"repo", fullName,
+		)
+		return
+	}
+
+	gh := github.NewClient()
+	webhookURL := strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/github"
+	hooks, err := gh.ListWebhooks(ctx, ghToken.Token, fullName)
+	if err != nil {
+		h.recordWebhookError(ctx, projectID, fmt.Sprintf("webhook_list_failed: %v", err))
+		return
+	}
+
+	// Keep the stored hook if it still exists, else the first hook pointing at us.
+	var keep *github.Webhook
+	var ours []github.Webhook
+	for _, wh := range hooks {
+		if strings.TrimRight(wh.Config.URL, "/") == webhookURL {
+			ours = append(ours, wh)
+		}
+	}
+	for i := range ours {
+		if storedID != nil && ours[i].ID == *storedID {
+			keep = &ours[i]
+		}
+	}
+	if keep == nil && len(ours) > 0 {
+		keep = &ours[0]
+	}
+
+	deleted := 0
+	for _, wh := range ours {
+		if keep != nil && wh.ID == keep.ID {
+			continue
+		}
+		if err := gh.DeleteWebhook(ctx, ghToken.Token, fullName, wh.ID); err != nil {
+			slog.Warn("webhook repair: duplicate delete failed", "project_id", projectID, "hook_id", wh.ID, "error", err)
+			continue
+		}
+		deleted++
+	}
+
+	whReq := github.CreateWebhookRequest{
+		URL:    webhookURL,
+		Secret: h.webhookSecret(ctx, projectID),
+		Events: projectWebhookEvents,
+		Active: true,
+	}
+	action := "updated"
+	var hookID int64
+	if keep != nil {
+		hookID = keep.ID
+		if err := gh.UpdateWebhook(ctx, ghToken.Token, fullName, hookID, whReq); err != nil {
+			h.recordWebhookError(ctx, projectID, fmt.Sprintf("webhook_update_failed: %v", err))
+			return
+		}
+	} else {
+		action = "created"
+		wh, err := gh.CreateWebhook(ctx, ghToken.Token, fullName, whReq)
+		if err != nil {
+			h.recordWebhookError(ctx, projectID, fmt.Sprintf("webhook_create_failed: %v", err))
+			return
+		}
+		hookID = wh.ID
+	}
+
+	_, _ = h.db.Pool.Exec(ctx, `
+UPDATE projects
+SET webhook_id = $2,
+    webhook_url = $3,
+    webhook_created_at = CASE WHEN webhook_id IS DISTINCT FROM $2 THEN now() ELSE webhook_created_at END,
+    verification_error = NULL,
+    updated_at = now()
+WHERE id = $1
+`, projectID, hookID, webhookURL)
+
+	pingErr := gh.PingWebhook(ctx, ghToken.Token, fullName, hookID)
+	slog.Info("webhook repaired",
+		"project_id", projectID,
+		"repo", fullName,
+		"hook_id", hookID,
+		"action", action,
+		"duplicates_deleted", deleted,
+		"ping_error", pingErr,
+	)
+}
+
+// webhookSecret is the secret the project's repo hook should be signed with: its own rotated secret
+// when it has one, the global secret otherwise.
+func (h *ProjectsHandler) webhookSecret(ctx context.Context, projectID uuid.UUID) string {
+	secret, err := github.ProjectWebhookSecret(ctx, h.db.Pool, projectID, h.cfg.TokenEncKeyB64)
+	if err != nil {
+		slog.Warn("project webhook secret lookup failed, using global secret", "project_id", projectID, "error", err)
+	}
+	if secret == "" {
+		return h.cfg.GitHubWebhookSecret
+	}
+	return secret
+}
+
+// recordWebhookError stores the failure without touching the project's verification status.
+func (h *ProjectsHandler) recordWebhookError(ctx context.Context, projectID uuid.UUID, msg string) {
+	_, _ = h.db.Pool.Exec(ctx, `
+UPDATE projects SET verification_error = $2, updated_at = now() WHERE id = $1
+`, projectID, msg)
+}
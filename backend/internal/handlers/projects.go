@@ -12,20 +12,33 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/repohost"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
 )
 
 type ProjectsHandler struct {
-	cfg config.Config
-	db  *db.DB
+	cfg   config.Config
+	db    *db.DB
+	apps  *github.InstallationTokenSource
+	hosts repohost.Registry
 }
 
 func NewProjectsHandler(cfg config.Config, d *db.DB) *ProjectsHandler {
-	return &ProjectsHandler{cfg: cfg, db: d}
+	apps, err := github.NewInstallationTokenSource(cfg.GitHubAppID, cfg.GitHubAppPrivateKey)
+	if err != nil {
+		slog.Warn("failed to init github app client (verification will use owner oauth tokens)", "error", err)
+	}
+	var pool *pgxpool.Pool
+	if d != nil {
+		pool = d.Pool
+	}
+	return &ProjectsHandler{cfg: cfg, db: d, apps: apps, hosts: repohost.NewRegistry(cfg, pool, github.NewClient(), apps)}
 }
 
 type createProjectRequest struct {
@@ -34,6 +47,10 @@ type createProjectRequest struct {
 	Language       *string  `json:"language,omitempty"`
 	Tags           []string `json:"tags,omitempty"`
 	Category       *string  `json:"category,omitempty"`
+	// Provider is the repository host: "github" (default) or "bitbucket" when configured.
+	Provider string `json:"provider,omitempty"`
+	// AllowFork registers a fork even though its upstream repo is already a project.
+	AllowFork bool `json:"allow_fork,omitempty"`
 }
 
 func (h *ProjectsHandler) Create() fiber.Handler {
@@ -53,6 +70,14 @@ func (h *ProjectsHandler) Create() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
 		}
 
+		provider := strings.ToLower(strings.TrimSpace(req.Provider))
+		if provider == "" {
+			provider = repohost.ProviderGitHub
+		}
+		if _, ok := h.hosts.Get(provider); !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unsupported_provider"})
+		}
+
 		fullName := normalizeRepoFullName(req.GitHubFullName)
 		if fullName == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_github_full_name"})
@@ -76,33 +101,56 @@ WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_not_found", "message": "No active ecosystem found with that name. Please select from available ecosystems."})
 		}
 
-		// Prepare tags as JSONB
-		var tagsJSON []byte = []byte("[]")
-		if len(req.Tags) > 0 {
-			tagsJSON, _ = json.Marshal(req.Tags)
+		if dup, err := h.findDuplicateProject(c.Context(), userID, provider, fullName, req.AllowFork); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		} else if dup != nil {
+			return c.Status(fiber.StatusConflict).JSON(dup)
+		}
+
+		// Prepare tags as JSONB, resolved to canonical tag slugs
+		tagsJSON, err := tags.CanonicalizeJSON(c.Context(), h.db.Pool, req.Tags)
+		if err != nil {
+			slog.Error("project tags not resolved", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_create_failed"})
 		}
 
 		var projectID uuid.UUID
 		var status string
 		err = h.db.Pool.QueryRow(c.Context(), `
-INSERT INTO projects (owner_user_id, github_full_name, ecosystem_id, language, tags, category, status)
-VALUES ($1, $2, $3, $4, $5, $6, 'pending_verification')
+INSERT INTO projects (owner_user_id, github_full_name, ecosystem_id, language, tags, category, status, organization_id, provider)
+VALUES ($1, $2, $3, $4, $5, $6, 'pending_verification',
+        CASE WHEN $7 = 'github' THEN (SELECT o.id FROM organizations o WHERE lower(o.login) = lower(split_part($2, '/', 1))) END,
+        $7)
 ON CONFLICT (github_full_name) DO UPDATE SET
   owner_user_id = EXCLUDED.owner_user_id,
+  provider = EXCLUDED.provider,
   ecosystem_id = EXCLUDED.ecosystem_id,
   language = EXCLUDED.language,
   tags = EXCLUDED.tags,
   category = EXCLUDED.category,
+  organization_id = EXCLUDED.organization_id,
   updated_at = now()
+WHERE projects.owner_user_id = EXCLUDED.owner_user_id
 RETURNING id, status
-`, userID, fullName, ecosystemID, req.Language, tagsJSON, req.Category).Scan(&projectID, &status)
+`, userID, fullName, ecosystemID, req.Language, tagsJSON, req.Category, provider).Scan(&projectID, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Registered by another user between the duplicate check and the insert.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "project_already_registered", "match": "full_name"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_create_failed"})
 		}
+		if err := syncProjectOwner(c.Context(), h.db.Pool, projectID, userID); err != nil {
+			slog.Warn("project owner membership not recorded", "project_id", projectID, "error", err)
+		}
+		if err := tags.SyncProject(c.Context(), h.db.Pool, projectID); err != nil {
+			slog.Warn("project tags index not updated", "project_id", projectID, "error", err)
+		}
 
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 			"id":               projectID.String(),
 			"github_full_name": fullName,
+			"provider":         provider,
 			"ecosystem_name":   ecosystemName,
 			"status":           status,
 		})
@@ -168,7 +216,9 @@ SELECT
   p.tags,
   p.category,
   p.description,
-  p.needs_metadata
+  p.needs_metadata,
+  p.bot_comments_enabled,
+  p.review_reason
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE p.owner_user_id = $1
@@ -210,8 +260,10 @@ ORDER BY p.created_at DESC
 			var category *string
 			var description *string
 			var needsMetadata bool
+			var botCommentsEnabled bool
+			var reviewReason *string
 
-			if err := rows.Scan(&id, &fullName, &status, &repoID, &verifiedAt, &verErr, &webhookID, &webhookURL, &webhookCreatedAt, &createdAt, &updatedAt, &ecosystemName, &language, &tagsJSON, &category, &description, &needsMetadata); err != nil {
+			if err := rows.Scan(&id, &fullName, &status, &repoID, &verifiedAt, &verErr, &webhookID, &webhookURL, &webhookCreatedAt, &createdAt, &updatedAt, &ecosystemName, &language, &tagsJSON, &category, &description, &needsMetadata, &botCommentsEnabled, &reviewReason); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
 			}
 
@@ -249,23 +301,25 @@ WHERE id = $1
 			}
 
 			projectMap := fiber.Map{
-				"id":                 id.String(),
-				"github_full_name":   fullName,
-				"status":             status,
-				"github_repo_id":     repoID,
-				"verified_at":        verifiedAt,
-				"verification_error": verErr,
-				"webhook_id":         webhookID,
-				"webhook_url":        webhookURL,
-				"webhook_created_at": webhookCreatedAt,
-				"created_at":         createdAt,
-				"updated_at":         updatedAt,
-				"ecosystem_name":     ecosystemName,
-				"language":           language,
-				"tags":               tags,
-				"category":           category,
-				"description":        description,
-				"needs_metadata":     needsMetadata,
+				"id":                   id.String(),
+				"github_full_name":     fullName,
+				"status":               status,
+				"github_repo_id":       repoID,
+				"verified_at":          verifiedAt,
+				"verification_error":   verErr,
+				"webhook_id":           webhookID,
+				"webhook_url":          webhookURL,
+				"webhook_created_at":   webhookCreatedAt,
+				"created_at":           createdAt,
+				"updated_at":           updatedAt,
+				"ecosystem_name":       ecosystemName,
+				"language":             language,
+				"tags":                 tags,
+				"category":             category,
+				"description":          description,
+				"needs_metadata":       needsMetadata,
+				"bot_comments_enabled": botCommentsEnabled,
+				"review_reason":        reviewReason,
 			}
 
 			// Add owner avatar if available
@@ -372,6 +426,8 @@ type updateMetadataRequest struct {
 	Language      *string  `json:"language,omitempty"`
 	Tags          []string `json:"tags,omitempty"`
 	Category      *string  `json:"category,omitempty"`
+	// BotCommentsEnabled opts the project into Grainlify bot comments on its issues.
+	BotCommentsEnabled *bool `json:"bot_comments_enabled,omitempty"`
 }
 
 // UpdateMetadata updates project metadata and sets needs_metadata = false.
@@ -407,7 +463,11 @@ SELECT owner_user_id FROM projects WHERE id = $1 AND deleted_at IS NULL
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
 		}
-		if ownerUserID != userID {
+		allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, ownerUserID, userID, projectRoleMaintainer)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		if !allowed {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
 		}
 
@@ -424,9 +484,10 @@ SELECT id FROM ecosystems WHERE LOWER(TRIM(name)) = LOWER(TRIM($1)) AND status =
 			ecosystemID = &ecoID
 		}
 
-		var tagsJSON []byte = []byte("[]")
-		if len(req.Tags) > 0 {
-			tagsJSON, _ = json.Marshal(req.Tags)
+		tagsJSON, err := tags.CanonicalizeJSON(c.Context(), h.db.Pool, req.Tags)
+		if err != nil {
+			slog.Error("project tags not resolved", "project_id", projectID, "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "metadata_update_failed"})
 		}
 
 		// Build dynamic update: set needs_metadata = false and provided fields
@@ -437,13 +498,17 @@ SET description = COALESCE($2, description),
     language = COALESCE($4, language),
     tags = COALESCE($5, tags),
     category = COALESCE($6, category),
+    bot_comments_enabled = COALESCE($7, bot_comments_enabled),
     needs_metadata = false,
     updated_at = now()
 WHERE id = $1
-`, projectID, req.Description, ecosystemID, req.Language, tagsJSON, req.Category)
+`, projectID, req.Description, ecosystemID, req.Language, tagsJSON, req.Category, req.BotCommentsEnabled)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "metadata_update_failed"})
 		}
+		if err := tags.SyncProject(c.Context(), h.db.Pool, projectID); err != nil {
+			slog.Warn("project tags index not updated", "project_id", projectID, "error", err)
+		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
@@ -471,11 +536,12 @@ func (h *ProjectsHandler) Verify() fiber.Handler {
 		var ownerUserID uuid.UUID
 		var fullName string
 		var webhookID *int64
+		var provider, status string
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT owner_user_id, github_full_name, webhook_id
+SELECT owner_user_id, github_full_name, webhook_id, provider, status
 FROM projects
 WHERE id = $1
-`, projectID).Scan(&ownerUserID, &fullName, &webhookID)
+`, projectID).Scan(&ownerUserID, &fullName, &webhookID, &provider, &status)
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
 		}
@@ -483,8 +549,17 @@ WHERE id = $1
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
 		}
 
-		if ownerUserID != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		if role != "admin" {
+			allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, ownerUserID, userID, projectRoleMaintainer)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			}
+			if !allowed {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			}
+		}
+		if status == "archived" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "project_archived"})
 		}
 
 		_, _ = h.db.Pool.Exec(c.Context(), `
@@ -494,7 +569,11 @@ WHERE id = $1
 `, projectID)
 
 		// Async job (in-process for now): return immediately per architecture rule.
-		go h.verifyAndWebhook(context.Background(), projectID, ownerUserID, fullName, webhookID)
+		if provider != repohost.ProviderGitHub {
+			go h.verifyOnHost(context.Background(), provider, projectID, ownerUserID, fullName)
+		} else {
+			go h.verifyAndWebhook(context.Background(), projectID, ownerUserID, fullName, webhookID)
+		}
 
 		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true})
 	}
@@ -509,38 +588,58 @@ func (h *ProjectsHandler) verifyAndWebhook(ctx context.Context, projectID uuid.U
 		return
 	}
 
-	linked, err := github.GetLinkedAccount(ctx, h.db.Pool, ownerUserID, h.cfg.TokenEncKeyB64)
+	ghToken, err := github.GetProjectToken(ctx, h.db.Pool, h.apps, projectID, h.cfg.TokenEncKeyB64)
 	if err != nil {
 		h.recordProjectError(ctx, projectID, "github_not_linked")
 		return
 	}
 
 	gh := github.NewClient()
-	repo, err := gh.GetRepo(ctx, linked.AccessToken, fullName)
+	repo, err := gh.GetRepo(ctx, ghToken.Token, fullName)
 	if err != nil {
 		h.recordProjectError(ctx, projectID, fmt.Sprintf("repo_fetch_failed: %v", err))
 		return
 	}
 
-	// Ownership/permission check: allow if the token has admin or push perms.
-	if !repo.Permissions.Admin && !repo.Permissions.Push {
+	// Ownership/permission check: allow if the token has admin or push perms. An installation token
+	// that can read the repo already proves an org admin granted the app access to it.
+	if ghToken.Mode == github.AuthModeOAuth && !repo.Permissions.Admin && !repo.Permissions.Push {
 		h.recordProjectError(ctx, projectID, "insufficient_repo_permissions (need admin or push)")
 		return
 	}
 
-	// If webhook already exists, just mark verified.
-	if existingWebhookID != nil && *existingWebhookID != 0 {
+	if err := github.SaveRepoMetadata(ctx, h.db.Pool, projectID, repo); err != nil {
+		slog.Warn("repo metadata save failed", "project_id", projectID, "error", err)
+	}
+	if langs, err := gh.GetRepoLanguages(ctx, ghToken.Token, repo.FullName); err == nil {
+		if err := github.SaveRepoLanguages(ctx, h.db.Pool, projectID, langs); err != nil {
+			slog.Warn("repo languages save failed", "project_id", projectID, "error", err)
+		}
+	}
+	if err := github.RefreshReadme(ctx, h.db.Pool, gh, projectID, ghToken.Token, repo.FullName); err != nil {
+		slog.Warn("repo readme save failed", "project_id", projectID, "error", err)
+	}
+
+	// Repos in a linked organization are covered by the org webhook.
+	var orgHooked bool
+	_ = h.db.Pool.QueryRow(ctx, `
+SELECT EXISTS(
+  SELECT 1 FROM projects p JOIN organizations o ON o.id = p.organization_id
+  WHERE p.id = $1 AND o.webhook_id IS NOT NULL
+)
+`, projectID).Scan(&orgHooked)
+
+	// If webhook already exists, or events arrive through the GitHub App's or org's webhook, just mark verified.
+	if (existingWebhookID != nil && *existingWebhookID != 0) || ghToken.Mode == github.AuthModeInstallation || orgHooked {
 		_, _ = h.db.Pool.Exec(ctx, `
 UPDATE projects
 SET github_repo_id = $2,
-    status = 'verified',
+    `+verifiedStatusSQL(h.cfg.ProjectReviewRequired)+`,
     verified_at = now(),
     verification_error = NULL,
-    stars_count = $3,
-    forks_count = $4,
     updated_at = now()
 WHERE id = $1
-`, projectID, repo.ID, repo.StargazersCount, repo.ForksCount)
+`, projectID, repo.ID)
 		return
 	}
 
@@ -551,10 +650,10 @@ WHERE id = $1
 
 	webhookURL := strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/github"
 
-	wh, err := gh.CreateWebhook(ctx, linked.AccessToken, fullName, github.CreateWebhookRequest{
+	wh, err := gh.CreateWebhook(ctx, ghToken.Token, fullName, github.CreateWebhookRequest{
 		URL:    webhookURL,
-		Secret: h.cfg.GitHubWebhookSecret,
-		Events: []string{"issues", "pull_request", "pull_request_review", "push"},
+		Secret: h.webhookSecret(ctx, projectID),
+		Events: projectWebhookEvents,
 		Active: true,
 	})
 	if err != nil {
@@ -565,17 +664,169 @@ WHERE id = $1
 	_, _ = h.db.Pool.Exec(ctx, `
 UPDATE projects
 SET github_repo_id = $2,
-    status = 'verified',
+    `+verifiedStatusSQL(h.cfg.ProjectReviewRequired)+`,
     verified_at = now(),
     verification_error = NULL,
     webhook_id = $3,
     webhook_url = $4,
     webhook_created_at = now(),
-    stars_count = $5,
-    forks_count = $6,
     updated_at = now()
 WHERE id = $1
-`, projectID, repo.ID, wh.ID, webhookURL, repo.StargazersCount, repo.ForksCount)
+`, projectID, repo.ID, wh.ID, webhookURL)
+}
+
+// verifyOnHost verifies a project on a non-GitHub host through the repohost interface: the owner's
+// token must be able to administer the repo, and the repo gets a webhook pointing at
+// /webhooks/<provider>.
+func (h *ProjectsHandler) verifyOnHost(ctx context.Context, provider string, projectID uuid.UUID, ownerUserID uuid.UUID, fullName string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if h.db == nil || h.db.Pool == nil {
+		return
+	}
+	host, ok := h.hosts.Get(provider)
+	if !ok {
+		h.recordProjectError(ctx, projectID, provider+"_not_configured")
+		return
+	}
+
+	token, err := host.Token(ctx, projectID, ownerUserID)
+	if err != nil {
+		h.recordProjectError(ctx, projectID, provider+"_not_linked")
+		return
+	}
+	repo, err := host.GetRepo(ctx, token, fullName)
+	if err != nil {
+		h.recordProjectError(ctx, projectID, fmt.Sprintf("repo_fetch_failed: %v", err))
+		return
+	}
+	if !repo.CanAdmin {
+		h.recordProjectError(ctx, projectID, "insufficient_repo_permissions (need admin or write)")
+		return
+	}
+
+	secret := ""
+	if provider == repohost.ProviderBitbucket {
+		secret = h.cfg.BitbucketWebhookSecret
+	}
+	if h.cfg.PublicBaseURL == "" || secret == "" {
+		h.recordProjectError(ctx, projectID, "webhook_not_configured (PUBLIC_BASE_URL and the provider webhook secret required)")
+		return
+	}
+	webhookURL := strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/webhooks/" + provider
+	hookID, err := host.CreateWebhook(ctx, token, repo.FullName, repohost.WebhookConfig{URL: webhookURL, Secret: secret})
+	if err != nil {
+		h.recordProjectError(ctx, projectID, fmt.Sprintf("webhook_create_failed: %v", err))
+		return
+	}
+
+	_, _ = h.db.Pool.Exec(ctx, `
+UPDATE projects
+SET `+verifiedStatusSQL(h.cfg.ProjectReviewRequired)+`,
+    verified_at = now(),
+    verification_error = NULL,
+    description = COALESCE(NULLIF(description, ''), NULLIF($2, '')),
+    language = COALESCE(NULLIF(language, ''), NULLIF($3, '')),
+    default_branch = NULLIF($4, ''),
+    provider_webhook_id = $5,
+    webhook_url = $6,
+    webhook_created_at = now(),
+    updated_at = now()
+WHERE id = $1
+`, projectID, repo.Description, repo.Language, repo.DefaultBranch, hookID, webhookURL)
+}
+
+// findDuplicateProject looks for a project another user already registered for the same repo: by
+// full name, by GitHub repo ID (the repo was renamed since), or as the upstream of a fork. It returns
+// the 409 body, or nil when the repo is free. Re-submitting one's own project keeps updating it.
+// The GitHub checks are best effort: without a linked account or when the repo cannot be fetched,
+// only the name is checked and verification catches the rest.
+func (h *ProjectsHandler) findDuplicateProject(ctx context.Context, userID uuid.UUID, provider, fullName string, allowFork bool) (fiber.Map, error) {
+	conflict := func(match string, id uuid.UUID, name string) fiber.Map {
+		return fiber.Map{
+			"error":                     "project_already_registered",
+			"match":                     match,
+			"existing_project_id":       id.String(),
+			"existing_github_full_name": name,
+		}
+	}
+
+	var id, ownerID uuid.UUID
+	var name string
+	err := h.db.Pool.QueryRow(ctx, `
+SELECT id, owner_user_id, github_full_name FROM projects
+WHERE lower(github_full_name) = lower($1) AND deleted_at IS NULL
+`, fullName).Scan(&id, &ownerID, &name)
+	if err == nil && ownerID != userID {
+		return conflict("full_name", id, name), nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if provider != repohost.ProviderGitHub {
+		return nil, nil
+	}
+
+	linked, err := github.GetLinkedAccount(ctx, h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+	if err != nil {
+		return nil, nil
+	}
+	repo, err := github.NewClient().GetRepo(ctx, linked.AccessToken, fullName)
+	if err != nil {
+		slog.Debug("project duplicate check: repo not fetched", "repo", fullName, "error", err)
+		return nil, nil
+	}
+
+	err = h.db.Pool.QueryRow(ctx, `
+SELECT id, github_full_name FROM projects
+WHERE github_repo_id = $1 AND lower(github_full_name) <> lower($2) AND deleted_at IS NULL
+LIMIT 1
+`, repo.ID, fullName).Scan(&id, &name)
+	if err == nil {
+		return conflict("repo_id", id, name), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	if !repo.Fork || allowFork {
+		return nil, nil
+	}
+	var upstreamIDs []int64
+	var upstreamNames []string
+	for _, ref := range []*github.RepoRef{repo.Parent, repo.Source} {
+		if ref != nil {
+			upstreamIDs = append(upstreamIDs, ref.ID)
+			upstreamNames = append(upstreamNames, strings.ToLower(ref.FullName))
+		}
+	}
+	if len(upstreamIDs) == 0 {
+		return nil, nil
+	}
+	err = h.db.Pool.QueryRow(ctx, `
+SELECT id, github_full_name FROM projects
+WHERE (github_repo_id = ANY($1) OR lower(github_full_name) = ANY($2)) AND deleted_at IS NULL
+LIMIT 1
+`, upstreamIDs, upstreamNames).Scan(&id, &name)
+	if err == nil {
+		return conflict("fork_of_registered", id, name), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// verifiedStatusSQL is the SET clause for a project whose verification just succeeded. When review
+// is required, a project no admin has approved yet goes to pending_review; otherwise it is verified
+// and counts as approved, so switching review on later does not queue it. An archived project stays
+// archived until its owner unarchives it.
+func verifiedStatusSQL(reviewRequired bool) string {
+	if reviewRequired {
+		return `status = CASE WHEN status = 'archived' THEN status WHEN approved_at IS NULL THEN 'pending_review' ELSE 'verified' END`
+	}
+	return `status = CASE WHEN status = 'archived' THEN status ELSE 'verified' END, approved_at = COALESCE(approved_at, now())`
 }
 
 func (h *ProjectsHandler) recordProjectError(ctx context.Context, projectID uuid.UUID, msg string) {
@@ -592,6 +843,7 @@ func normalizeRepoFullName(v string) string {
 	s := strings.TrimSpace(v)
 	s = strings.TrimPrefix(s, "https://github.com/")
 	s = strings.TrimPrefix(s, "http://github.com/")
+	s = strings.TrimPrefix(s, "https://bitbucket.org/")
 	s = strings.TrimSuffix(s, "/")
 	parts := strings.Split(s, "/")
 	if len(parts) != 2 {
@@ -16,6 +16,8 @@ import (
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
+	"github.com/jagadeesh/grainlify/backend/internal/tags"
 )
 
 type ProjectsPublicHandler struct {
@@ -85,6 +87,35 @@ func (h *ProjectsPublicHandler) installationToken(ctx context.Context, installat
 	return tok
 }
 
+// projectLanguages returns the project's stored language breakdown, largest share first.
+func (h *ProjectsPublicHandler) projectLanguages(ctx context.Context, projectID uuid.UUID) []fiber.Map {
+	rows, err := h.db.Pool.Query(ctx, `
+SELECT language, bytes, percentage::float8
+FROM project_languages
+WHERE project_id = $1
+ORDER BY bytes DESC, language
+`, projectID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	var out []fiber.Map
+	for rows.Next() {
+		var name string
+		var bytes int64
+		var pct float64
+		if err := rows.Scan(&name, &bytes, &pct); err != nil {
+			return nil
+		}
+		out = append(out, fiber.Map{
+			"name":       name,
+			"bytes":      bytes,
+			"percentage": pct,
+		})
+	}
+	return out
+}
+
 // Get returns a single verified project by id, enriched with GitHub repo metadata and language breakdown.
 func (h *ProjectsPublicHandler) Get() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -120,6 +151,8 @@ func (h *ProjectsPublicHandler) Get() fiber.Handler {
 		var openIssuesCount, openPRsCount, contributorsCount int
 		var createdAt, updatedAt time.Time
 		var ecosystemName, ecosystemSlug *string
+		var description, readmeMarkdown, readmeHTML, readmePath *string
+		var readmeFetchedAt *time.Time
 
 		err = h.db.Pool.QueryRow(c.Context(), `
 SELECT 
@@ -152,7 +185,12 @@ SELECT
   p.created_at,
   p.updated_at,
   e.name AS ecosystem_name,
-  e.slug AS ecosystem_slug
+  e.slug AS ecosystem_slug,
+  p.description,
+  p.readme_markdown,
+  p.readme_html,
+  p.readme_path,
+  p.readme_fetched_at
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
@@ -160,6 +198,7 @@ WHERE p.id = $1 AND p.status = 'verified' AND p.deleted_at IS NULL
 			&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount,
 			&openIssuesCount, &openPRsCount, &contributorsCount,
 			&createdAt, &updatedAt, &ecosystemName, &ecosystemSlug,
+			&description, &readmeMarkdown, &readmeHTML, &readmePath, &readmeFetchedAt,
 		)
 		if err == pgx.ErrNoRows {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
@@ -233,34 +272,35 @@ WHERE id=$1
 `, projectID, stars, forks)
 		}
 
-		// GitHub language breakdown (best effort)
-		var langsOut []fiber.Map
-		if m, err := gh.GetRepoLanguages(ctx, token, fullName); err == nil && len(m) > 0 {
-			var total int64
-			for _, v := range m {
-				total += v
-			}
-			if total > 0 {
-				for name, v := range m {
-					pct := float64(v) * 100.0 / float64(total)
-					langsOut = append(langsOut, fiber.Map{
-						"name":       name,
-						"percentage": pct,
-					})
+		// Language breakdown: stored by verification/metadata refresh; projects not refreshed since
+		// fall back to GitHub (best effort) and get it stored for next time.
+		langsOut := h.projectLanguages(c.Context(), projectID)
+		if len(langsOut) == 0 {
+			if m, err := gh.GetRepoLanguages(ctx, token, fullName); err == nil && len(m) > 0 {
+				if err := github.SaveRepoLanguages(c.Context(), h.db.Pool, projectID, m); err == nil {
+					langsOut = h.projectLanguages(c.Context(), projectID)
 				}
 			}
 		}
 
-		// Fetch README content (best effort)
-		var readmeContent string
-		if readme, err := gh.GetReadme(ctx, token, fullName); err == nil {
-			readmeContent = readme
-		} else {
-			slog.Warn("failed to fetch README for project",
-				"project_id", projectID,
-				"github_full_name", fullName,
-				"error", err,
-			)
+		// README: cached by verification/metadata refresh; projects not refreshed since fetch it
+		// once here (best effort) and keep it for next time.
+		if readmeFetchedAt == nil {
+			if err := github.RefreshReadme(ctx, h.db.Pool, gh, projectID, token, fullName); err == nil {
+				_ = h.db.Pool.QueryRow(c.Context(), `
+SELECT readme_markdown, readme_html, readme_path, readme_fetched_at FROM projects WHERE id = $1
+`, projectID).Scan(&readmeMarkdown, &readmeHTML, &readmePath, &readmeFetchedAt)
+			} else {
+				slog.Warn("failed to fetch README for project",
+					"project_id", projectID,
+					"github_full_name", fullName,
+					"error", err,
+				)
+			}
+		}
+		readmeContent := ""
+		if readmeMarkdown != nil {
+			readmeContent = *readmeMarkdown
 		}
 
 		resp := fiber.Map{
@@ -279,7 +319,11 @@ WHERE id=$1
 			"created_at":         createdAt,
 			"updated_at":         updatedAt,
 			"languages":          langsOut,
+			"description":        description,
 			"readme":             readmeContent,
+			"readme_html":        readmeHTML,
+			"readme_path":        readmePath,
+			"readme_updated_at":  readmeFetchedAt,
 		}
 
 		if repoOK {
@@ -434,12 +478,13 @@ LIMIT 50
 
 // List returns a filtered list of verified projects.
 // Query parameters:
-//   - ecosystem: filter by ecosystem name (case-insensitive)
+//   - ecosystem: filter by ecosystem name (case-insensitive), including its child ecosystems
 //   - language: filter by programming language
 //   - category: filter by category
 //   - tags: comma-separated list of tags (project must have ALL tags)
 //   - limit: max results (default 50, max 200)
-//   - offset: pagination offset (default 0)
+//   - cursor: next_cursor from the previous page (keyset pagination; takes precedence over offset)
+//   - offset: pagination offset (default 0), kept for older clients
 func (h *ProjectsPublicHandler) List() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -460,6 +505,14 @@ func (h *ProjectsPublicHandler) List() fiber.Handler {
 		if offset < 0 {
 			offset = 0
 		}
+		var cursor *pagination.Cursor
+		if raw := c.Query("cursor"); raw != "" {
+			cur, err := pagination.Decode(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			cursor, offset = &cur, 0
+		}
 
 		// Build WHERE clause and args
 		var conditions []string
@@ -471,14 +524,19 @@ func (h *ProjectsPublicHandler) List() fiber.Handler {
 		conditions = append(conditions, "p.needs_metadata = false")
 		// Never show private repos (they are soft-deleted)
 		conditions = append(conditions, "p.deleted_at IS NULL")
+		conditions = append(conditions, "p.repo_unavailable_at IS NULL")
 
 		// Exclude special GitHub repositories (owner/.github)
 		conditions = append(conditions, "split_part(p.github_full_name, '/', 2) != '.github'")
 
 
-		// Filter by ecosystem
+		// Filter by ecosystem; a parent ecosystem also matches the projects of its children.
 		if ecosystem != "" {
-			conditions = append(conditions, fmt.Sprintf("LOWER(TRIM(e.name)) = LOWER($%d)", argPos))
+			conditions = append(conditions, fmt.Sprintf(`p.ecosystem_id IN (
+  SELECT ec.descendant_id FROM ecosystem_closure ec
+  INNER JOIN ecosystems ea ON ea.id = ec.ancestor_id
+  WHERE LOWER(TRIM(ea.name)) = LOWER($%d)
+)`, argPos))
 			args = append(args, ecosystem)
 			argPos++
 		}
@@ -497,25 +555,33 @@ func (h *ProjectsPublicHandler) List() fiber.Handler {
 			argPos++
 		}
 
-		// Filter by tags (must have ALL specified tags)
-		var tags []string
+		// Filter by tags (must have ALL specified tags). Aliases resolve to the canonical slugs the
+		// tags JSONB holds, so "Rust" and "rustlang" match projects tagged "rust".
+		var tagFilter []string
 		if tagsParam != "" {
-			for _, tag := range strings.Split(tagsParam, ",") {
-				tag = strings.TrimSpace(tag)
-				if tag != "" {
-					tags = append(tags, tag)
-				}
+			resolved, err := tags.Resolve(c.Context(), h.db.Pool, strings.Split(tagsParam, ","))
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed"})
 			}
+			tagFilter = resolved
 		}
-		if len(tags) > 0 {
+		if len(tagFilter) > 0 {
 			// Use JSONB containment operator @> to check if tags array contains all specified tags
 			conditions = append(conditions, fmt.Sprintf("p.tags @> $%d::jsonb", argPos))
-			tagsJSON, _ := json.Marshal(tags)
+			tagsJSON, _ := json.Marshal(tagFilter)
 			args = append(args, string(tagsJSON))
 			argPos++
 		}
 
 		whereClause := strings.Join(conditions, " AND ")
+		countArgs := args
+
+		pageWhere := whereClause
+		if cursor != nil {
+			pageWhere += fmt.Sprintf(" AND (p.created_at, p.id) < ($%d, $%d::uuid)", argPos, argPos+1)
+			args = append(args, cursor.At, cursor.ID)
+			argPos += 2
+		}
 
 		// Build query
 		query := fmt.Sprintf(`
@@ -550,14 +616,23 @@ SELECT
   p.updated_at,
   e.name AS ecosystem_name,
   e.slug AS ecosystem_slug,
-  p.description
+  p.description,
+  p.default_branch,
+  p.topics,
+  p.license_spdx_id,
+  p.archived,
+  (
+    SELECT COALESCE(jsonb_agg(jsonb_build_object('name', pl.language, 'percentage', pl.percentage) ORDER BY pl.bytes DESC), '[]'::jsonb)
+    FROM project_languages pl
+    WHERE pl.project_id = p.id
+  ) AS languages
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE %s
-ORDER BY p.created_at DESC
+ORDER BY p.created_at DESC, p.id DESC
 LIMIT $%d OFFSET $%d
-`, whereClause, argPos, argPos+1)
-		args = append(args, limit, offset)
+`, pageWhere, argPos, argPos+1)
+		args = append(args, limit+1, offset)
 
 		rows, err := h.db.Pool.Query(c.Context(), query, args...)
 		if err != nil {
@@ -566,6 +641,8 @@ LIMIT $%d OFFSET $%d
 		defer rows.Close()
 
 		var out []fiber.Map
+		var last pagination.Cursor
+		n := 0
 		for rows.Next() {
 			var id uuid.UUID
 			var fullName string
@@ -576,11 +653,19 @@ LIMIT $%d OFFSET $%d
 			var openIssuesCount, openPRsCount, contributorsCount int
 			var createdAt, updatedAt time.Time
 			var ecosystemName, ecosystemSlug *string
-			var description *string
+			var description, defaultBranch, license *string
+			var topics []string
+			var archived bool
+			var languagesJSON []byte
 
-			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug, &description); err != nil {
+			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug, &description, &defaultBranch, &topics, &license, &archived, &languagesJSON); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "projects_list_failed", "details": err.Error()})
 			}
+			// The extra row fetched beyond limit only tells whether another page exists.
+			if n++; n > limit {
+				continue
+			}
+			last = pagination.Cursor{At: createdAt, ID: id.String()}
 
 			// Parse tags JSONB
 			var tags []string
@@ -617,6 +702,11 @@ LIMIT $%d OFFSET $%d
 				"ecosystem_name":     ecosystemName,
 				"ecosystem_slug":     ecosystemSlug,
 				"description":        descVal,
+				"default_branch":     defaultBranch,
+				"topics":             topics,
+				"license":            license,
+				"archived":           archived,
+				"languages":          json.RawMessage(languagesJSON),
 				"created_at":         createdAt,
 				"updated_at":         updatedAt,
 			})
@@ -629,7 +719,6 @@ FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
 WHERE %s
 `, whereClause)
-		countArgs := args[:len(args)-2] // Remove limit and offset
 
 		var total int
 		if err := h.db.Pool.QueryRow(c.Context(), countQuery, countArgs...).Scan(&total); err != nil {
@@ -638,10 +727,11 @@ WHERE %s
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"projects": out,
-			"total":    total,
-			"limit":    limit,
-			"offset":   offset,
+			"projects":    out,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+			"next_cursor": pagination.Next(n, limit, last),
 		})
 	}
 }
@@ -692,10 +782,11 @@ SELECT
   p.created_at,
   p.updated_at,
   e.name AS ecosystem_name,
-  e.slug AS ecosystem_slug
+  e.slug AS ecosystem_slug,
+  p.description
 FROM projects p
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.status = 'verified' AND p.deleted_at IS NULL AND p.needs_metadata = false AND split_part(p.github_full_name, '/', 2) != '.github'
+WHERE p.status = 'verified' AND p.deleted_at IS NULL AND p.repo_unavailable_at IS NULL AND NOT p.archived AND p.needs_metadata = false AND split_part(p.github_full_name, '/', 2) != '.github'
 ORDER BY contributors_count DESC, p.stars_count DESC, p.created_at DESC
 LIMIT $1
 `
@@ -716,8 +807,9 @@ LIMIT $1
 			var openIssuesCount, openPRsCount, contributorsCount int
 			var createdAt, updatedAt time.Time
 			var ecosystemName, ecosystemSlug *string
+			var descriptionCol *string
 
-			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug); err != nil {
+			if err := rows.Scan(&id, &fullName, &installationID, &language, &tagsJSON, &category, &starsCount, &forksCount, &openIssuesCount, &openPRsCount, &contributorsCount, &createdAt, &updatedAt, &ecosystemName, &ecosystemSlug, &descriptionCol); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "recommended_projects_scan_failed"})
 			}
 
@@ -738,9 +830,11 @@ LIMIT $1
 			}
 
 			// Skip per-project GitHub enrichment here to keep /projects/recommended fast.
-			// Description and live star/fork counts can be refreshed via background jobs
-			// or on the project detail endpoint instead.
+			// Description and star/fork counts are captured at verification time instead.
 			description := ""
+			if descriptionCol != nil {
+				description = *descriptionCol
+			}
 
 			out = append(out, fiber.Map{
 				"id":                 id.String(),
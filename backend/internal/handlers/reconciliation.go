@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
+)
+
+// ReconciliationHandler exposes the mismatches the sync worker's reconciliation pass finds between
+// payouts and the chain (reconciliation_issues) to admins.
+type ReconciliationHandler struct {
+	db *db.DB
+}
+
+func NewReconciliationHandler(d *db.DB) *ReconciliationHandler {
+	return &ReconciliationHandler{db: d}
+}
+
+const reconciliationIssueColumns = `ri.id, ri.kind, ri.subject, ri.payout_id, ri.escrow_id, COALESCE(p.bounty_id, be.bounty_id),
+  ri.tx_hash, ri.expected, ri.actual, ri.detail, ri.status, ri.first_seen_at, ri.last_seen_at, ri.resolved_at,
+  ri.resolved_by, ri.resolution_note`
+
+const reconciliationIssueFrom = `reconciliation_issues ri
+LEFT JOIN payouts p ON p.id = ri.payout_id
+LEFT JOIN bounty_escrows be ON be.id = ri.escrow_id`
+
+func scanReconciliationIssue(row pgx.Row) (fiber.Map, time.Time, uuid.UUID, error) {
+	var id uuid.UUID
+	var kind, subject, detail, status string
+	var payoutID, escrowID, bountyID, resolvedBy *uuid.UUID
+	var txHash, expected, actual, note *string
+	var firstSeen, lastSeen time.Time
+	var resolvedAt *time.Time
+	if err := row.Scan(&id, &kind, &subject, &payoutID, &escrowID, &bountyID, &txHash, &expected, &actual, &detail,
+		&status, &firstSeen, &lastSeen, &resolvedAt, &resolvedBy, &note); err != nil {
+		return nil, time.Time{}, uuid.Nil, err
+	}
+	return fiber.Map{
+		"id":              id.String(),
+		"kind":            kind,
+		"subject":         subject,
+		"payout_id":       payoutID,
+		"escrow_id":       escrowID,
+		"bounty_id":       bountyID,
+		"tx_hash":         txHash,
+		"expected":        expected,
+		"actual":          actual,
+		"detail":          detail,
+		"status":          status,
+		"first_seen_at":   firstSeen,
+		"last_seen_at":    lastSeen,
+		"resolved_at":     resolvedAt,
+		"resolved_by":     resolvedBy,
+		"resolution_note": note,
+	}, firstSeen, id, nil
+}
+
+// List returns reconciliation issues, newest first, with the number of open issues of each kind.
+// ?status= (default open; "all" for every status) and ?kind= filter; ?limit= and ?cursor= page.
+func (h *ReconciliationHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		limit, cursor, ok, err := pageParams(c)
+		if !ok {
+			return err
+		}
+		status := strings.ToLower(strings.TrimSpace(c.Query("status", "open")))
+		switch status {
+		case "open", "resolved", "dismissed":
+		case "all":
+			status = ""
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		kind := strings.ToLower(strings.TrimSpace(c.Query("kind")))
+		var afterAt *time.Time
+		var afterID *uuid.UUID
+		if cursor != nil {
+			id, err := uuid.Parse(cursor.ID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			afterAt, afterID = &cursor.At, &id
+		}
+
+		rows, err := h.db.Pool.Query(c.Context(), `
+SELECT `+reconciliationIssueColumns+`
+FROM `+reconciliationIssueFrom+`
+WHERE ($2 = '' OR ri.status = $2) AND ($3 = '' OR ri.kind = $3)
+  AND ($4::timestamptz IS NULL OR (ri.first_seen_at, ri.id) < ($4, $5))
+ORDER BY ri.first_seen_at DESC, ri.id DESC
+LIMIT $1
+`, limit+1, status, kind, afterAt, afterID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_list_failed"})
+		}
+		defer rows.Close()
+		out := []fiber.Map{}
+		var last pagination.Cursor
+		n := 0
+		for rows.Next() {
+			issue, at, id, err := scanReconciliationIssue(rows)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_list_failed"})
+			}
+			if n++; n > limit {
+				continue
+			}
+			out = append(out, issue)
+			last = pagination.Cursor{At: at, ID: id.String()}
+		}
+		if rows.Err() != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_list_failed"})
+		}
+
+		open := map[string]int{}
+		countRows, err := h.db.Pool.Query(c.Context(), `
+SELECT kind, COUNT(*) FROM reconciliation_issues WHERE status = 'open' GROUP BY kind
+`)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_list_failed"})
+		}
+		defer countRows.Close()
+		for countRows.Next() {
+			var k string
+			var count int
+			if err := countRows.Scan(&k, &count); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_list_failed"})
+			}
+			open[k] = count
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"issues":      out,
+			"open_counts": open,
+			"next_cursor": pagination.Next(n, limit, last),
+		})
+	}
+}
+
+type resolveReconciliationRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// Resolve closes an open issue as resolved (the mismatch was fixed) or dismissed (it is expected).
+// Either way the row stops being re-checked until something new is found.
+func (h *ReconciliationHandler) Resolve() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_issue_id"})
+		}
+		var req resolveReconciliationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_body"})
+		}
+		status := strings.ToLower(strings.TrimSpace(req.Status))
+		if status == "" {
+			status = "resolved"
+		}
+		if status != "resolved" && status != "dismissed" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		note := strings.TrimSpace(req.Note)
+		if len(note) > 2000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "note_too_long"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		var actor *uuid.UUID
+		if u, err := uuid.Parse(sub); err == nil {
+			actor = &u
+		}
+
+		out, _, _, err := scanReconciliationIssue(h.db.Pool.QueryRow(c.Context(), `
+WITH ri AS (
+  UPDATE reconciliation_issues
+  SET status = $2, resolved_at = now(), resolved_by = $3, resolution_note = NULLIF($4, '')
+  WHERE id = $1 AND status = 'open'
+  RETURNING *
+)
+SELECT `+reconciliationIssueColumns+`
+FROM ri
+LEFT JOIN payouts p ON p.id = ri.payout_id
+LEFT JOIN bounty_escrows be ON be.id = ri.escrow_id
+`, id, status, actor, note))
+		if errors.Is(err, pgx.ErrNoRows) {
+			var current string
+			if err := h.db.Pool.QueryRow(c.Context(), `SELECT status FROM reconciliation_issues WHERE id = $1`, id).Scan(&current); err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "issue_not_found"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "issue_not_open", "status": current})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "reconciliation_update_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
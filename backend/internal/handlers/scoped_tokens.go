@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+const maxScopedTokenTTLDays = 365
+
+// ScopedTokensHandler lets project owners mint read-only tokens for dashboards and widgets.
+type ScopedTokensHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewScopedTokensHandler(cfg config.Config, d *db.DB) *ScopedTokensHandler {
+	return &ScopedTokensHandler{cfg: cfg, db: d}
+}
+
+type createScopedTokenRequest struct {
+	Name    string   `json:"name"`
+	Scopes  []string `json:"scopes"`
+	TTLDays int      `json:"ttl_days"`
+}
+
+// ownedProject resolves :id and checks the caller maintains it (admins may list and revoke, not mint).
+func (h *ScopedTokensHandler) ownedProject(c *fiber.Ctx, allowAdmin bool) (uuid.UUID, uuid.UUID, error) {
+	if h.db == nil || h.db.Pool == nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+	}
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+	}
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+	}
+
+	var owner uuid.UUID
+	err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1 AND deleted_at IS NULL`, projectID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+	}
+	if err != nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+	}
+	role, _ := c.Locals(auth.LocalRole).(string)
+	if !(allowAdmin && role == "admin") {
+		allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, projectRoleMaintainer)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+		}
+		if !allowed {
+			return uuid.Nil, uuid.Nil, c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		}
+	}
+	return userID, projectID, nil
+}
+
+// Create mints a scoped token bound to the project. The raw token is only returned here.
+func (h *ScopedTokensHandler) Create() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.cfg.JWTSecret == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
+		}
+		if _, ok := c.Locals(auth.LocalActorID).(string); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not_allowed_while_impersonating"})
+		}
+		userID, projectID, err := h.ownedProject(c, false)
+		if err != nil {
+			return err
+		}
+
+		var req createScopedTokenRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_name"})
+		}
+		if len(req.Scopes) == 0 {
+			req.Scopes = []string{auth.ScopeProjectsRead}
+		}
+		seen := map[string]bool{}
+		scopes := make([]string, 0, len(req.Scopes))
+		for _, s := range req.Scopes {
+			if !auth.KnownScope(s) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_scope", "scope": s})
+			}
+			if !seen[s] {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+		if req.TTLDays == 0 {
+			req.TTLDays = 90
+		}
+		if req.TTLDays < 1 || req.TTLDays > maxScopedTokenTTLDays {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ttl"})
+		}
+
+		token, t, err := auth.IssueScopedJWT(c.Context(), h.db.Pool, h.cfg.JWTSecret, userID, projectID, req.Name, scopes, time.Duration(req.TTLDays)*24*time.Hour)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+
+		out := scopedTokenJSON(t)
+		out["token"] = token
+		return c.Status(fiber.StatusCreated).JSON(out)
+	}
+}
+
+func (h *ScopedTokensHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		_, projectID, err := h.ownedProject(c, true)
+		if err != nil {
+			return err
+		}
+		tokens, err := auth.ListScopedTokens(c.Context(), h.db.Pool, projectID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tokens_list_failed"})
+		}
+		out := []fiber.Map{}
+		for _, t := range tokens {
+			out = append(out, scopedTokenJSON(t))
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"tokens": out})
+	}
+}
+
+func (h *ScopedTokensHandler) Revoke() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		_, projectID, err := h.ownedProject(c, true)
+		if err != nil {
+			return err
+		}
+		tokenID, err := uuid.Parse(c.Params("token_id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_token_id"})
+		}
+		if err := auth.RevokeScopedToken(c.Context(), h.db.Pool, projectID, tokenID); err != nil {
+			if errors.Is(err, auth.ErrScopedTokenNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "token_not_found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_revoke_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+func scopedTokenJSON(t auth.ScopedToken) fiber.Map {
+	return fiber.Map{
+		"id":           t.ID.String(),
+		"project_id":   t.ProjectID.String(),
+		"created_by":   t.UserID.String(),
+		"name":         t.Name,
+		"scopes":       t.Scopes,
+		"created_at":   t.CreatedAt,
+		"expires_at":   t.ExpiresAt,
+		"revoked_at":   t.RevokedAt,
+		"last_used_at": t.LastUsedAt,
+	}
+}
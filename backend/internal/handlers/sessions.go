@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+type SessionsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewSessionsHandler(cfg config.Config, d *db.DB) *SessionsHandler {
+	return &SessionsHandler{cfg: cfg, db: d}
+}
+
+// issueSessionJWT records a server-side session for the request and issues a JWT whose jti is the session ID.
+func issueSessionJWT(c *fiber.Ctx, cfg config.Config, d *db.DB, userID uuid.UUID, role string, walletType auth.WalletType, address string, ttl time.Duration) (string, error) {
+	if d == nil || d.Pool == nil {
+		return "", errors.New("db not configured")
+	}
+	tv, err := auth.TokenVersion(c.Context(), d.Pool, userID)
+	if err != nil {
+		return "", err
+	}
+	s, err := auth.CreateSession(c.Context(), d.Pool, userID, c.Get("User-Agent"), c.IP(), ttl)
+	if err != nil {
+		return "", err
+	}
+	return auth.IssueJWT(cfg.JWTSecret, s.ID, userID, role, tv, walletType, address, ttl)
+}
+
+// List returns active sessions for the current user.
+// Admins may pass ?user_id= to list another user's sessions.
+func (h *SessionsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		role, _ := c.Locals(auth.LocalRole).(string)
+		currentSessionID, _ := c.Locals(auth.LocalSessionID).(string)
+
+		targetID := userID
+		if q := c.Query("user_id"); q != "" {
+			if role != "admin" {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "insufficient_role"})
+			}
+			targetID, err = uuid.Parse(q)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_user_id"})
+			}
+		}
+
+		sessions, err := auth.ListActiveSessions(c.Context(), h.db.Pool, targetID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "sessions_list_failed"})
+		}
+
+		out := []fiber.Map{}
+		for _, s := range sessions {
+			out = append(out, fiber.Map{
+				"id":         s.ID.String(),
+				"user_agent": s.UserAgent,
+				"ip":         s.IP,
+				"created_at": s.CreatedAt,
+				"expires_at": s.ExpiresAt,
+				"current":    s.ID.String() == currentSessionID,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"sessions": out})
+	}
+}
+
+// Revoke revokes a session. Users may revoke their own sessions; admins may revoke any session.
+func (h *SessionsHandler) Revoke() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		role, _ := c.Locals(auth.LocalRole).(string)
+
+		sessionID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_session_id"})
+		}
+
+		s, err := auth.GetSession(c.Context(), h.db.Pool, sessionID)
+		if err != nil {
+			if errors.Is(err, auth.ErrSessionNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session_not_found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "session_lookup_failed"})
+		}
+		// Don't leak the existence of other users' sessions to non-admins.
+		if s.UserID != userID && role != "admin" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session_not_found"})
+		}
+
+		if err := auth.RevokeSession(c.Context(), h.db.Pool, sessionID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "session_revoke_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -9,17 +10,37 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
 )
 
 type SyncHandler struct {
-	db *db.DB
+	db  *db.DB
+	bus bus.Bus
 }
 
-func NewSyncHandler(d *db.DB) *SyncHandler {
-	return &SyncHandler{db: d}
+func NewSyncHandler(d *db.DB, b bus.Bus) *SyncHandler {
+	return &SyncHandler{db: d, bus: b}
 }
 
+// syncResources maps the resources a manual sync can request to their job types.
+var syncResources = map[string]string{
+	"issues":   "sync_issues",
+	"prs":      "sync_prs",
+	"comments": "sync_comments",
+	"commits":  "sync_commits",
+}
+
+type enqueueSyncRequest struct {
+	// Resources to sync: issues, prs, comments, commits. Empty means issues, prs and commits.
+	Resources []string `json:"resources"`
+	// Since overrides the project's sync cursor for issues, prs and comments (RFC 3339).
+	Since *time.Time `json:"since"`
+}
+
+// EnqueueFullSync queues a manual sync of the project. The optional body selects the resources and
+// a since override; without one, issues, PRs and commits are synced from the cursor.
 func (h *SyncHandler) EnqueueFullSync() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -37,7 +58,8 @@ func (h *SyncHandler) EnqueueFullSync() fiber.Handler {
 		}
 
 		var owner uuid.UUID
-		err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1`, projectID).Scan(&owner)
+		var status string
+		err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id, status FROM projects WHERE id = $1`, projectID).Scan(&owner, &status)
 		if errors.Is(err, pgx.ErrNoRows) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
 		}
@@ -46,17 +68,57 @@ func (h *SyncHandler) EnqueueFullSync() fiber.Handler {
 		}
 
 		role, _ := c.Locals(auth.LocalRole).(string)
-		if owner != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		if role != "admin" {
+			allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, projectRoleMaintainer)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			}
+			if !allowed {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			}
+		}
+		if status == "archived" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "project_archived"})
 		}
 
-		_, _ = h.db.Pool.Exec(c.Context(), `
-INSERT INTO sync_jobs (project_id, job_type, status, run_at)
-VALUES ($1, 'sync_issues', 'pending', now()),
-       ($1, 'sync_prs', 'pending', now())
-`, projectID)
+		var req enqueueSyncRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+			}
+		}
+		if len(req.Resources) == 0 {
+			req.Resources = []string{"issues", "prs", "commits"}
+		}
+		jobTypes := []string{}
+		seen := map[string]bool{}
+		for _, r := range req.Resources {
+			jt, ok := syncResources[r]
+			if !ok {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_resource", "resource": r})
+			}
+			if !seen[jt] {
+				seen[jt] = true
+				jobTypes = append(jobTypes, jt)
+			}
+		}
+		if req.Since != nil && req.Since.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_since"})
+		}
 
-		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true})
+		// A pending job of the same type takes this request's since: the latest request wins.
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at, priority, since_override)
+SELECT $1, t, 'pending', now(), 10, $3 FROM unnest($2::text[]) AS t
+ON CONFLICT (project_id, job_type) WHERE status = 'pending'
+DO UPDATE SET priority = GREATEST(sync_jobs.priority, EXCLUDED.priority), since_override = EXCLUDED.since_override, updated_at = now()
+`, projectID, jobTypes, req.Since)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "enqueue_failed"})
+		}
+		syncjobs.PublishEnqueued(c.Context(), h.bus, projectID.String(), jobTypes...)
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"queued": true, "job_types": jobTypes})
 	}
 }
 
@@ -86,12 +148,18 @@ func (h *SyncHandler) JobsForProject() fiber.Handler {
 		}
 
 		role, _ := c.Locals(auth.LocalRole).(string)
-		if owner != userID && role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+		if role != "admin" {
+			allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, projectRoleViewer)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_lookup_failed"})
+			}
+			if !allowed {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+			}
 		}
 
 		rows, err := h.db.Pool.Query(c.Context(), `
-SELECT id, job_type, status, run_at, attempts, last_error, created_at, updated_at
+SELECT id, job_type, status, run_at, attempts, last_error, progress, created_at, updated_at
 FROM sync_jobs
 WHERE project_id = $1
 ORDER BY created_at DESC
@@ -109,7 +177,8 @@ LIMIT 50
 			var runAt, createdAt, updatedAt time.Time
 			var attempts int
 			var lastErr *string
-			if err := rows.Scan(&id, &jobType, &status, &runAt, &attempts, &lastErr, &createdAt, &updatedAt); err != nil {
+			var progress []byte
+			if err := rows.Scan(&id, &jobType, &status, &runAt, &attempts, &lastErr, &progress, &createdAt, &updatedAt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "jobs_list_failed"})
 			}
 			out = append(out, fiber.Map{
@@ -119,6 +188,7 @@ LIMIT 50
 				"run_at":     runAt,
 				"attempts":   attempts,
 				"last_error": lastErr,
+				"progress":   json.RawMessage(progress),
 				"created_at": createdAt,
 				"updated_at": updatedAt,
 			})
@@ -128,23 +198,153 @@ LIMIT 50
 	}
 }
 
+// Sync schedule bounds: more often than every 15 minutes is what webhooks are for.
+const (
+	minSyncScheduleInterval = 15 * time.Minute
+	maxSyncScheduleInterval = 30 * 24 * time.Hour
+)
 
+type syncScheduleRequest struct {
+	Interval string `json:"interval"` // Go duration, e.g. "6h"
+	FullSync *bool  `json:"full_sync,omitempty"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+}
 
+// authorizeProject resolves :id and checks the caller holds at least minRole on the project or is an
+// admin. On failure it returns the status and error code to respond with.
+func (h *SyncHandler) authorizeProject(c *fiber.Ctx, minRole string) (uuid.UUID, int, string) {
+	sub, _ := c.Locals(auth.LocalUserID).(string)
+	userID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.Nil, fiber.StatusUnauthorized, "invalid_user"
+	}
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, fiber.StatusBadRequest, "invalid_project_id"
+	}
+	var owner uuid.UUID
+	err = h.db.Pool.QueryRow(c.Context(), `SELECT owner_user_id FROM projects WHERE id = $1 AND deleted_at IS NULL`, projectID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, fiber.StatusNotFound, "project_not_found"
+	}
+	if err != nil {
+		return uuid.Nil, fiber.StatusInternalServerError, "project_lookup_failed"
+	}
+	role, _ := c.Locals(auth.LocalRole).(string)
+	if role != "admin" {
+		allowed, err := hasProjectRole(c.Context(), h.db.Pool, projectID, owner, userID, minRole)
+		if err != nil {
+			return uuid.Nil, fiber.StatusInternalServerError, "project_lookup_failed"
+		}
+		if !allowed {
+			return uuid.Nil, fiber.StatusForbidden, "forbidden"
+		}
+	}
+	return projectID, 0, ""
+}
 
+// GetSchedule returns the project's recurring sync schedule.
+func (h *SyncHandler) GetSchedule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, status, code := h.authorizeProject(c, projectRoleViewer)
+		if code != "" {
+			return c.Status(status).JSON(fiber.Map{"error": code})
+		}
+		return h.writeSchedule(c, projectID, fiber.StatusOK)
+	}
+}
 
+// PutSchedule creates or updates the project's recurring sync schedule. A new or changed interval
+// starts counting from now.
+func (h *SyncHandler) PutSchedule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, status, code := h.authorizeProject(c, projectRoleMaintainer)
+		if code != "" {
+			return c.Status(status).JSON(fiber.Map{"error": code})
+		}
+		var req syncScheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		interval, err := time.ParseDuration(req.Interval)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_interval"})
+		}
+		if interval < minSyncScheduleInterval || interval > maxSyncScheduleInterval {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "interval_out_of_range",
+				"min":   minSyncScheduleInterval.String(),
+				"max":   maxSyncScheduleInterval.String(),
+			})
+		}
+		userID, _ := uuid.Parse(c.Locals(auth.LocalUserID).(string))
+
+		_, err = h.db.Pool.Exec(c.Context(), `
+INSERT INTO sync_schedules (project_id, interval_seconds, full_sync, enabled, next_run_at, created_by)
+VALUES ($1, $2, COALESCE($3, true), COALESCE($4, true), now() + $2 * interval '1 second', $5)
+ON CONFLICT (project_id) DO UPDATE SET
+  interval_seconds = EXCLUDED.interval_seconds,
+  full_sync = COALESCE($3, sync_schedules.full_sync),
+  enabled = COALESCE($4, sync_schedules.enabled),
+  next_run_at = CASE
+    WHEN sync_schedules.interval_seconds = EXCLUDED.interval_seconds THEN sync_schedules.next_run_at
+    ELSE EXCLUDED.next_run_at
+  END,
+  updated_at = now()
+`, projectID, int64(interval.Seconds()), req.FullSync, req.Enabled, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "schedule_update_failed"})
+		}
+		return h.writeSchedule(c, projectID, fiber.StatusOK)
+	}
+}
 
+// DeleteSchedule removes the project's recurring sync schedule.
+func (h *SyncHandler) DeleteSchedule() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, status, code := h.authorizeProject(c, projectRoleMaintainer)
+		if code != "" {
+			return c.Status(status).JSON(fiber.Map{"error": code})
+		}
+		if _, err := h.db.Pool.Exec(c.Context(), `DELETE FROM sync_schedules WHERE project_id = $1`, projectID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "schedule_delete_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
 
-
-
-
-
-
-
-
-
-
-
-
-
-
-
+func (h *SyncHandler) writeSchedule(c *fiber.Ctx, projectID uuid.UUID, status int) error {
+	var intervalSeconds int64
+	var fullSync, enabled bool
+	var nextRunAt time.Time
+	var lastRunAt *time.Time
+	err := h.db.Pool.QueryRow(c.Context(), `
+SELECT interval_seconds, full_sync, enabled, next_run_at, last_run_at
+FROM sync_schedules
+WHERE project_id = $1
+`, projectID).Scan(&intervalSeconds, &fullSync, &enabled, &nextRunAt, &lastRunAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "schedule_not_found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "schedule_lookup_failed"})
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"schedule": fiber.Map{
+			"interval":    (time.Duration(intervalSeconds) * time.Second).String(),
+			"full_sync":   fullSync,
+			"enabled":     enabled,
+			"next_run_at": nextRunAt,
+			"last_run_at": lastRunAt,
+		},
+	})
+}
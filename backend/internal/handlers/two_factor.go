@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// TwoFactorHandler implements TOTP enrollment and step-up verification.
+type TwoFactorHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewTwoFactorHandler(cfg config.Config, d *db.DB) *TwoFactorHandler {
+	return &TwoFactorHandler{cfg: cfg, db: d}
+}
+
+// Enroll creates a pending TOTP secret and returns it with an otpauth:// provisioning URI.
+func (h *TwoFactorHandler) Enroll() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		secret, err := auth.StartTOTPEnrollment(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64)
+		if err != nil {
+			if err.Error() == "totp_already_enabled" {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "totp_already_enabled"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "totp_enroll_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"secret":         secret,
+			"otpauth_url":    auth.TOTPProvisioningURI("Grainlify", userID.String(), secret),
+			"period_seconds": 30,
+			"digits":         6,
+		})
+	}
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// Verify checks a TOTP code (enabling a pending enrollment) and issues a new token carrying the mfa claim.
+func (h *TwoFactorHandler) Verify() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req totpCodeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+
+		if err := auth.VerifyTOTP(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64, req.Code); err != nil {
+			switch {
+			case errors.Is(err, auth.ErrTOTPNotEnrolled):
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "totp_not_enrolled"})
+			case errors.Is(err, auth.ErrTOTPInvalidCode):
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_totp_code"})
+			default:
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "totp_verify_failed"})
+			}
+		}
+
+		var role string
+		var tokenVersion int
+		if err := h.db.Pool.QueryRow(c.Context(), `SELECT role, token_version FROM users WHERE id = $1`, userID).Scan(&role, &tokenVersion); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user_lookup_failed"})
+		}
+
+		ttl := h.cfg.TokenTTL(role, h.cfg.JWTLoginTTL)
+		s, err := auth.CreateSession(c.Context(), h.db.Pool, userID, c.Get("User-Agent"), c.IP(), ttl)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+		claims := auth.Claims{Role: role, MFA: true, TokenVersion: tokenVersion}
+		claims.Subject = userID.String()
+		claims.ID = s.ID.String()
+		token, err := auth.SignJWT(h.cfg.JWTSecret, claims, ttl)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "token_issue_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok":    true,
+			"token": token,
+		})
+	}
+}
+
+// Disable removes TOTP after confirming a current code.
+func (h *TwoFactorHandler) Disable() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req totpCodeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		if err := auth.VerifyTOTP(c.Context(), h.db.Pool, userID, h.cfg.TokenEncKeyB64, req.Code); err != nil {
+			if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "totp_not_enrolled"})
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_totp_code"})
+		}
+		if err := auth.DisableTOTP(c.Context(), h.db.Pool, userID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "totp_disable_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -8,11 +9,15 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/jagadeesh/grainlify/backend/internal/achievements"
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/digest"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/pagination"
 )
 
 type UserProfileHandler struct {
@@ -54,6 +59,8 @@ WHERE user_id = $1
 				"contributions_count": 0,
 				"languages":           []fiber.Map{},
 				"ecosystems":          []fiber.Map{},
+				"current_streak":      0,
+				"longest_streak":      0,
 			})
 		}
 
@@ -62,20 +69,18 @@ WHERE user_id = $1
 				"contributions_count": 0,
 				"languages":           []fiber.Map{},
 				"ecosystems":          []fiber.Map{},
+				"current_streak":      0,
+				"longest_streak":      0,
 			})
 		}
 
 		// Count total contributions (issues + PRs) for verified projects only
 		var contributionsCount int
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT 
-  (SELECT COUNT(*) FROM github_issues i
-   INNER JOIN projects p ON i.project_id = p.id
-   WHERE i.author_login = $1 AND p.status = 'verified')
-  +
-  (SELECT COUNT(*) FROM github_pull_requests pr
-   INNER JOIN projects p ON pr.project_id = p.id
-   WHERE pr.author_login = $1 AND p.status = 'verified')
+SELECT COUNT(*)
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
 `, *githubLogin).Scan(&contributionsCount)
 		if err != nil {
 			slog.Error("failed to count contributions", "error", err, "user_id", userID, "github_login", *githubLogin)
@@ -88,13 +93,10 @@ SELECT
 SELECT 
   p.language,
   COUNT(*) as contribution_count
-FROM (
-  SELECT project_id FROM github_issues WHERE author_login = $1
-  UNION ALL
-  SELECT project_id FROM github_pull_requests WHERE author_login = $1
-) contributions
-INNER JOIN projects p ON contributions.project_id = p.id
-WHERE p.status = 'verified' AND p.language IS NOT NULL
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND p.language IS NOT NULL
 GROUP BY p.language
 ORDER BY contribution_count DESC, p.language ASC
 LIMIT 10
@@ -120,19 +122,18 @@ LIMIT 10
 		}
 
 		// Get most active ecosystems (top 10)
-		// Count contributions per ecosystem, only for verified projects
+		// Count contributions per ecosystem, only for verified projects; contributions to a child
+		// ecosystem also count toward its ancestors.
 		ecoRows, err := h.db.Pool.Query(c.Context(), `
 SELECT 
   e.name as ecosystem_name,
   COUNT(*) as contribution_count
-FROM (
-  SELECT project_id FROM github_issues WHERE author_login = $1
-  UNION ALL
-  SELECT project_id FROM github_pull_requests WHERE author_login = $1
-) contributions
-INNER JOIN projects p ON contributions.project_id = p.id
-INNER JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.status = 'verified' AND e.status = 'active'
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+INNER JOIN ecosystem_closure ec ON ec.descendant_id = p.ecosystem_id
+INNER JOIN ecosystems e ON ec.ancestor_id = e.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND e.status = 'active'
 GROUP BY e.id, e.name
 ORDER BY contribution_count DESC, e.name ASC
 LIMIT 10
@@ -164,32 +165,12 @@ LIMIT 10
 WITH contribution_counts AS (
   SELECT 
     ga.login,
-    (
-      SELECT COUNT(*) 
-      FROM github_issues i
-      INNER JOIN projects p ON i.project_id = p.id
-      WHERE i.author_login = ga.login AND p.status = 'verified'
-    ) +
-    (
-      SELECT COUNT(*) 
-      FROM github_pull_requests pr
-      INNER JOIN projects p ON pr.project_id = p.id
-      WHERE pr.author_login = ga.login AND p.status = 'verified'
-    ) as contribution_count
+    COUNT(*) as contribution_count
   FROM github_accounts ga
   INNER JOIN users u ON ga.user_id = u.id
-  WHERE (
-    SELECT COUNT(*) 
-    FROM github_issues i
-    INNER JOIN projects p ON i.project_id = p.id
-    WHERE i.author_login = ga.login AND p.status = 'verified'
-  ) +
-  (
-    SELECT COUNT(*) 
-    FROM github_pull_requests pr
-    INNER JOIN projects p ON pr.project_id = p.id
-    WHERE pr.author_login = ga.login AND p.status = 'verified'
-  ) > 0
+  INNER JOIN contributions c ON c.author_login = ga.login AND c.kind IN ('issue', 'pull_request')
+  INNER JOIN projects p ON c.project_id = p.id AND p.status IN ('verified', 'archived')
+  GROUP BY ga.login
 ),
 ranked_users AS (
   SELECT 
@@ -222,23 +203,23 @@ WHERE login = $1
 		// Get user profile fields (bio, website, social links, kyc) from users table
 		var bio, website, telegram, linkedin, whatsapp, twitter, discord *string
 		var kycStatus *string
+		var publicProfile bool
+		digestFrequency := "off"
 		_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord, kyc_status
+SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord,
+       (SELECT status FROM user_kyc WHERE user_id = users.id),
+       public_profile, digest_frequency
 FROM users
 WHERE id = $1
-`, userID).Scan(&bio, &website, &telegram, &linkedin, &whatsapp, &twitter, &discord, &kycStatus)
+`, userID).Scan(&bio, &website, &telegram, &linkedin, &whatsapp, &twitter, &discord, &kycStatus, &publicProfile, &digestFrequency)
 
 		// Count distinct projects user has contributed to (via issues or PRs)
 		var projectsContributedToCount int
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT COUNT(DISTINCT project_id)
-FROM (
-  SELECT project_id FROM github_issues WHERE author_login = $1
-  UNION
-  SELECT project_id FROM github_pull_requests WHERE author_login = $1
-) contributions
-INNER JOIN projects p ON contributions.project_id = p.id
-WHERE p.status = 'verified'
+SELECT COUNT(DISTINCT c.project_id)
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
 `, *githubLogin).Scan(&projectsContributedToCount)
 		if err != nil {
 			slog.Warn("failed to count projects contributed to", "error", err, "user_id", userID, "github_login", *githubLogin)
@@ -260,6 +241,11 @@ WHERE p.status = 'verified'
 			projectsLedCount = 0
 		}
 
+		currentStreak, longestStreak, err := contributionStreaks(c.Context(), h.db.Pool, *githubLogin)
+		if err != nil {
+			slog.Warn("failed to compute contribution streaks", "error", err, "user_id", userID, "github_login", *githubLogin)
+		}
+
 		response := fiber.Map{
 			"contributions_count":           contributionsCount,
 			"projects_contributed_to_count": projectsContributedToCount,
@@ -267,6 +253,10 @@ WHERE p.status = 'verified'
 			"rewards_count":                 0, // TODO: Implement rewards system
 			"languages":                     languages,
 			"ecosystems":                    ecosystems,
+			"public_profile":                publicProfile,
+			"digest_frequency":              digestFrequency,
+			"current_streak":                currentStreak,
+			"longest_streak":                longestStreak,
 			"kyc_verified": func() bool {
 				return kycStatus != nil && *kycStatus == "verified"
 			}(),
@@ -319,6 +309,15 @@ func (h *UserProfileHandler) ContributionCalendar() fiber.Handler {
 		var githubLogin *string
 		var err error
 
+		// Days are bucketed in ?tz= (an IANA name such as "America/New_York"), UTC by default.
+		loc := time.UTC
+		if tz := strings.TrimSpace(c.Query("tz")); tz != "" {
+			loc, err = time.LoadLocation(tz)
+			if err != nil || tz == "Local" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_timezone"})
+			}
+		}
+
 		// Check if user_id or login is provided in query params (for viewing other users)
 		userIDParam := c.Query("user_id")
 		loginParam := c.Query("login")
@@ -356,101 +355,138 @@ WHERE user_id = $1
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
 				"calendar": []fiber.Map{},
 				"total":    0,
+				"timezone": loc.String(),
 			})
 		}
 
-		// Calculate date range: last 365 days from today
-		now := time.Now().UTC()
-		startDate := now.AddDate(0, 0, -365)
-
-		// Query daily contribution counts (issues + PRs) for verified projects
-		// Use DATE_TRUNC to group by day
-		rows, err := h.db.Pool.Query(c.Context(), `
-SELECT 
-  DATE(contribution_date) as date,
-  COUNT(*) as count
-FROM (
-  SELECT created_at_github as contribution_date
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login = $1 
-    AND i.created_at_github >= $2 
-    AND i.created_at_github <= $3
-    AND p.status = 'verified'
-  
-  UNION ALL
-  
-  SELECT created_at_github as contribution_date
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login = $1 
-    AND pr.created_at_github >= $2 
-    AND pr.created_at_github <= $3
-    AND p.status = 'verified'
-) contributions
-GROUP BY DATE(contribution_date)
-ORDER BY date ASC
-`, *githubLogin, startDate, now)
+		calendar, totalContributions, err := loadContributionCalendar(c.Context(), h.db.Pool, *githubLogin, loc)
 		if err != nil {
 			slog.Error("failed to fetch contribution calendar", "error", err, "github_login", *githubLogin)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "calendar_fetch_failed"})
 		}
-		defer rows.Close()
 
-		// Build a map of date -> count for quick lookup
-		dateCounts := make(map[string]int)
-		totalContributions := 0
-		for rows.Next() {
-			var date time.Time
-			var count int
-			if err := rows.Scan(&date, &count); err != nil {
-				slog.Error("failed to scan calendar row", "error", err)
-				continue
-			}
-			dateStr := date.Format("2006-01-02")
-			dateCounts[dateStr] = count
-			totalContributions += count
-		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"calendar": calendar,
+			"total":    totalContributions,
+			"timezone": loc.String(),
+		})
+	}
+}
 
-		// Find max count for color level calculation
-		maxCount := 0
-		for _, count := range dateCounts {
-			if count > maxCount {
-				maxCount = count
-			}
-		}
+// loadContributionCalendar returns one entry per day of the last 365 days with the login's
+// contribution count (issues, PRs and default-branch commits on listed projects) and its color
+// level, plus the total. Days are calendar days in loc.
+func loadContributionCalendar(ctx context.Context, pool *pgxpool.Pool, login string, loc *time.Location) ([]fiber.Map, int, error) {
+	// Calculate date range: last 365 days from today
+	now := time.Now().In(loc)
+	startDate := now.AddDate(0, 0, -365)
+
+	dateCounts, totalContributions, err := loadContributionCounts(ctx, pool, login, startDate, now, loc)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		// Generate calendar data for all 365 days
-		// Color levels: 0 = none, 1 = low, 2 = medium, 3 = high, 4 = very high
-		// Using GitHub's algorithm: levels are based on quartiles
-		var calendar []fiber.Map
-		currentDate := startDate
-		for currentDate.Before(now) || currentDate.Equal(now.Truncate(24*time.Hour)) {
-			dateStr := currentDate.Format("2006-01-02")
-			count := dateCounts[dateStr]
+	// Find max count for color level calculation
+	maxCount := 0
+	for _, count := range dateCounts {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
 
-			// Calculate level (0-4) based on count
-			level := calculateContributionLevel(count, maxCount)
+	// Generate calendar data for all 365 days
+	// Color levels: 0 = none, 1 = low, 2 = medium, 3 = high, 4 = very high
+	// Using GitHub's algorithm: levels are based on quartiles
+	var calendar []fiber.Map
+	// Iterate over whole days in loc so today is included wherever the caller is.
+	currentDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	for !currentDate.After(today) {
+		dateStr := currentDate.Format("2006-01-02")
+		count := dateCounts[dateStr]
+
+		// Calculate level (0-4) based on count
+		level := calculateContributionLevel(count, maxCount)
+
+		calendar = append(calendar, fiber.Map{
+			"date":  dateStr,
+			"count": count,
+			"level": level,
+		})
 
-			calendar = append(calendar, fiber.Map{
-				"date":  dateStr,
-				"count": count,
-				"level": level,
-			})
+		currentDate = currentDate.AddDate(0, 0, 1)
+	}
+	return calendar, totalContributions, nil
+}
 
-			currentDate = currentDate.AddDate(0, 0, 1)
+// contributionStreaks returns the login's current and longest streaks of consecutive days with a
+// contribution, from the same daily counts as the calendar (so the longest streak is within the
+// last 365 days).
+func contributionStreaks(ctx context.Context, pool *pgxpool.Pool, login string) (current, longest int, err error) {
+	now := time.Now().UTC()
+	dateCounts, _, err := loadContributionCounts(ctx, pool, login, now.AddDate(0, 0, -365), now, time.UTC)
+	if err != nil {
+		return 0, 0, err
+	}
+	days := make([]time.Time, 0, len(dateCounts))
+	for date, count := range dateCounts {
+		if count == 0 {
+			continue
+		}
+		if t, err := time.Parse("2006-01-02", date); err == nil {
+			days = append(days, t)
 		}
+	}
+	return achievements.CurrentStreak(days, now), achievements.LongestStreak(days), nil
+}
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"calendar": calendar,
-			"total":    totalContributions,
-		})
+// loadContributionCounts returns the login's contribution count per day (YYYY-MM-DD) between
+// startDate and now, plus the total. Days are bucketed in loc, in SQL.
+func loadContributionCounts(ctx context.Context, pool *pgxpool.Pool, login string, startDate, now time.Time, loc *time.Location) (map[string]int, int, error) {
+	// Query daily contribution counts (issues + PRs + default-branch commits) for verified projects
+	// Use DATE_TRUNC to group by day
+	rows, err := pool.Query(ctx, `
+SELECT 
+  DATE(contribution_date AT TIME ZONE $4) as date,
+  COUNT(*) as count
+FROM (
+  SELECT c.occurred_at as contribution_date
+  FROM contributions c
+  INNER JOIN projects p ON c.project_id = p.id
+  WHERE c.author_login = $1
+    AND c.occurred_at >= $2
+    AND c.occurred_at <= $3
+    AND p.status IN ('verified', 'archived')
+) contributions
+GROUP BY 1
+ORDER BY date ASC
+`, login, startDate, now, loc.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// Build a map of date -> count for quick lookup
+	dateCounts := make(map[string]int)
+	totalContributions := 0
+	for rows.Next() {
+		var date time.Time
+		var count int
+		if err := rows.Scan(&date, &count); err != nil {
+			slog.Error("failed to scan calendar row", "error", err)
+			continue
+		}
+		dateStr := date.Format("2006-01-02")
+		dateCounts[dateStr] = count
+		totalContributions += count
 	}
+	return dateCounts, totalContributions, rows.Err()
 }
 
 // ContributionActivity returns a paginated list of individual contributions (issues and PRs)
 // Grouped by month, showing contribution type, project, title, and date
 // Accepts optional user_id or login query parameters for viewing other users' profiles
+// Pages by ?cursor= (next_cursor of the previous page) or, for older clients, ?offset=
 func (h *UserProfileHandler) ContributionActivity() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -463,6 +499,18 @@ func (h *UserProfileHandler) ContributionActivity() fiber.Handler {
 			limit = 100 // Cap at 100 for performance
 		}
 		offset := c.QueryInt("offset", 0)
+		var afterAt *time.Time
+		var afterID *string
+		if raw := c.Query("cursor"); raw != "" {
+			cur, err := pagination.Decode(raw)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			if _, err := uuid.Parse(cur.ID); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_cursor"})
+			}
+			afterAt, afterID, offset = &cur.At, &cur.ID, 0
+		}
 
 		var githubLogin *string
 		var err error
@@ -501,10 +549,11 @@ WHERE user_id = $1
 
 		if githubLogin == nil || *githubLogin == "" {
 			return c.Status(fiber.StatusOK).JSON(fiber.Map{
-				"activities": []fiber.Map{},
-				"total":      0,
-				"limit":      limit,
-				"offset":     offset,
+				"activities":  []fiber.Map{},
+				"total":       0,
+				"limit":       limit,
+				"offset":      offset,
+				"next_cursor": nil,
 			})
 		}
 
@@ -512,38 +561,24 @@ WHERE user_id = $1
 		// Order by date descending (most recent first)
 		rows, err := h.db.Pool.Query(c.Context(), `
 SELECT 
-  'issue' as contribution_type,
-  i.id,
-  i.number,
-  i.title,
-  i.url,
-  i.created_at_github,
-  i.state,
-  p.github_full_name as project_name,
-  p.id as project_id
-FROM github_issues i
-INNER JOIN projects p ON i.project_id = p.id
-WHERE i.author_login = $1 AND p.status = 'verified' AND i.created_at_github IS NOT NULL
-
-UNION ALL
-
-SELECT 
-  'pull_request' as contribution_type,
-  pr.id,
-  pr.number,
-  pr.title,
-  pr.url,
-  pr.created_at_github,
-  pr.state,
+  c.kind as contribution_type,
+  c.source_id,
+  COALESCE(i.number, pr.number),
+  COALESCE(i.title, pr.title),
+  COALESCE(i.url, pr.url),
+  c.occurred_at,
+  COALESCE(i.state, pr.state),
   p.github_full_name as project_name,
   p.id as project_id
-FROM github_pull_requests pr
-INNER JOIN projects p ON pr.project_id = p.id
-WHERE pr.author_login = $1 AND p.status = 'verified' AND pr.created_at_github IS NOT NULL
-
-ORDER BY created_at_github DESC
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+LEFT JOIN github_issues i ON c.kind = 'issue' AND i.id = c.source_id
+LEFT JOIN github_pull_requests pr ON c.kind = 'pull_request' AND pr.id = c.source_id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
+  AND ($4::timestamptz IS NULL OR (c.occurred_at, c.source_id) < ($4, $5::uuid))
+ORDER BY c.occurred_at DESC, c.source_id DESC
 LIMIT $2 OFFSET $3
-`, *githubLogin, limit, offset)
+`, *githubLogin, limit+1, offset, afterAt, afterID)
 		if err != nil {
 			slog.Error("failed to fetch contribution activity", "error", err, "github_login", *githubLogin)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "activity_fetch_failed"})
@@ -551,6 +586,8 @@ LIMIT $2 OFFSET $3
 		defer rows.Close()
 
 		var activities []fiber.Map
+		var last pagination.Cursor
+		n := 0
 		for rows.Next() {
 			var contribType string
 			var id uuid.UUID
@@ -563,6 +600,12 @@ LIMIT $2 OFFSET $3
 				slog.Error("failed to scan activity row", "error", err)
 				continue
 			}
+			if n++; n > limit {
+				continue
+			}
+			if createdAt != nil {
+				last = pagination.Cursor{At: *createdAt, ID: id.String()}
+			}
 
 			// Format date for display
 			var dateStr string
@@ -589,14 +632,10 @@ LIMIT $2 OFFSET $3
 		// Get total count for pagination
 		var total int
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT 
-  (SELECT COUNT(*) FROM github_issues i
-   INNER JOIN projects p ON i.project_id = p.id
-   WHERE i.author_login = $1 AND p.status = 'verified' AND i.created_at_github IS NOT NULL)
-  +
-  (SELECT COUNT(*) FROM github_pull_requests pr
-   INNER JOIN projects p ON pr.project_id = p.id
-   WHERE pr.author_login = $1 AND p.status = 'verified' AND pr.created_at_github IS NOT NULL)
+SELECT COUNT(*)
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
 `, *githubLogin).Scan(&total)
 		if err != nil {
 			slog.Error("failed to count total activities", "error", err)
@@ -604,10 +643,11 @@ SELECT
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"activities": activities,
-			"total":      total,
-			"limit":      limit,
-			"offset":     offset,
+			"activities":  activities,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+			"next_cursor": pagination.Next(n, limit, last),
 		})
 	}
 }
@@ -675,20 +715,12 @@ SELECT DISTINCT
   p.owner_user_id
 FROM (
   SELECT DISTINCT project_id
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login = $1 AND p.status = 'verified'
-  
-  UNION
-  
-  SELECT DISTINCT project_id
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login = $1 AND p.status = 'verified'
+  FROM contributions
+  WHERE author_login = $1 AND kind IN ('issue', 'pull_request')
 ) contrib_projects
 INNER JOIN projects p ON contrib_projects.project_id = p.id
 LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.status = 'verified' AND p.deleted_at IS NULL
+WHERE p.status IN ('verified', 'archived') AND p.deleted_at IS NULL
 ORDER BY p.github_full_name ASC
 LIMIT 10
 `, *githubLogin)
@@ -881,7 +913,8 @@ WHERE user_id = $1
 
 			// Get profile fields
 			_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord, kyc_status
+SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord,
+       (SELECT status FROM user_kyc WHERE user_id = users.id)
 FROM users
 WHERE id = $1
 `, parsedUserID).Scan(&bio, &website, &telegram, &linkedin, &whatsapp, &twitter, &discord, &kycStatus)
@@ -918,7 +951,8 @@ WHERE LOWER(ga.login) = $1
 
 			// Get profile fields
 			_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord, kyc_status
+SELECT bio, website, telegram, linkedin, whatsapp, twitter, discord,
+       (SELECT status FROM user_kyc WHERE user_id = users.id)
 FROM users
 WHERE id = $1
 `, foundUserID).Scan(&bio, &website, &telegram, &linkedin, &whatsapp, &twitter, &discord, &kycStatus)
@@ -928,17 +962,20 @@ WHERE id = $1
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
 		}
 
+		// What the user chose to show (GET/PATCH /profile/settings)
+		settings, err := loadProfileSettings(c.Context(), h.db.Pool, *userID)
+		if err != nil {
+			slog.Error("failed to load profile settings", "error", err, "user_id", *userID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "profile_settings_fetch_failed"})
+		}
+
 		// Count total contributions (issues + PRs) for verified projects only
 		var contributionsCount int
-		err := h.db.Pool.QueryRow(c.Context(), `
-SELECT 
-  (SELECT COUNT(*) FROM github_issues i
-   INNER JOIN projects p ON i.project_id = p.id
-   WHERE i.author_login = $1 AND p.status = 'verified')
-  +
-  (SELECT COUNT(*) FROM github_pull_requests pr
-   INNER JOIN projects p ON pr.project_id = p.id
-   WHERE pr.author_login = $1 AND p.status = 'verified')
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT COUNT(*)
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
 `, *githubLogin).Scan(&contributionsCount)
 		if err != nil {
 			slog.Error("failed to count contributions", "error", err, "github_login", *githubLogin)
@@ -950,19 +987,10 @@ SELECT
 SELECT 
   p.language,
   COUNT(*) as contribution_count
-FROM (
-  SELECT project_id, language FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login = $1 AND p.status = 'verified' AND p.language IS NOT NULL
-  
-  UNION ALL
-  
-  SELECT project_id, language FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login = $1 AND p.status = 'verified' AND p.language IS NOT NULL
-) contribs
-INNER JOIN projects p ON contribs.project_id = p.id
-WHERE p.language IS NOT NULL
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+  AND p.status IN ('verified', 'archived') AND p.language IS NOT NULL
 GROUP BY p.language
 ORDER BY contribution_count DESC
 LIMIT 10
@@ -985,25 +1013,20 @@ LIMIT 10
 			})
 		}
 
-		// Get most active ecosystems (top 10)
+		// Get most active ecosystems (top 10); a child ecosystem also counts toward its ancestors
 		ecoRows, err := h.db.Pool.Query(c.Context(), `
 SELECT 
   e.name as ecosystem_name,
   COUNT(*) as contribution_count
 FROM (
   SELECT DISTINCT p.ecosystem_id
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login = $1 AND p.status = 'verified' AND p.ecosystem_id IS NOT NULL
-  
-  UNION
-  
-  SELECT DISTINCT p.ecosystem_id
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login = $1 AND p.status = 'verified' AND p.ecosystem_id IS NOT NULL
+  FROM contributions c
+  INNER JOIN projects p ON c.project_id = p.id
+  WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request')
+    AND p.status IN ('verified', 'archived') AND p.ecosystem_id IS NOT NULL
 ) contrib_ecosystems
-INNER JOIN ecosystems e ON contrib_ecosystems.ecosystem_id = e.id
+INNER JOIN ecosystem_closure ec ON ec.descendant_id = contrib_ecosystems.ecosystem_id
+INNER JOIN ecosystems e ON ec.ancestor_id = e.id
 WHERE e.status = 'active'
 GROUP BY e.name
 ORDER BY contribution_count DESC
@@ -1033,30 +1056,12 @@ LIMIT 10
 		err = h.db.Pool.QueryRow(c.Context(), `
 WITH ranked_contributors AS (
   SELECT 
-    ac.login,
-    (
-      SELECT COUNT(*) 
-      FROM github_issues i
-      INNER JOIN projects p ON i.project_id = p.id
-      WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-    ) +
-    (
-      SELECT COUNT(*) 
-      FROM github_pull_requests pr
-      INNER JOIN projects p ON pr.project_id = p.id
-      WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-    ) as contribution_count
-  FROM (
-    SELECT DISTINCT i.author_login as login
-    FROM github_issues i
-    INNER JOIN projects p ON i.project_id = p.id
-    WHERE i.author_login IS NOT NULL AND i.author_login != '' AND p.status = 'verified'
-    UNION
-    SELECT DISTINCT pr.author_login as login
-    FROM github_pull_requests pr
-    INNER JOIN projects p ON pr.project_id = p.id
-    WHERE pr.author_login IS NOT NULL AND pr.author_login != '' AND p.status = 'verified'
-  ) ac
+    MIN(c.author_login) as login,
+    COUNT(*) as contribution_count
+  FROM contributions c
+  INNER JOIN projects p ON c.project_id = p.id
+  WHERE c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
+  GROUP BY LOWER(c.author_login)
 ),
 ranked AS (
   SELECT login, ROW_NUMBER() OVER (ORDER BY contribution_count DESC, login ASC) as rank_position
@@ -1082,14 +1087,10 @@ SELECT rank_position FROM ranked WHERE LOWER(login) = LOWER($1)
 		// Get projects contributed to and projects led counts
 		var projectsContributedToCount int
 		err = h.db.Pool.QueryRow(c.Context(), `
-SELECT COUNT(DISTINCT p.id)
-FROM (
-  SELECT project_id FROM github_issues WHERE author_login = $1
-  UNION
-  SELECT project_id FROM github_pull_requests WHERE author_login = $1
-) contribs
-INNER JOIN projects p ON contribs.project_id = p.id
-WHERE p.status = 'verified'
+SELECT COUNT(DISTINCT c.project_id)
+FROM contributions c
+INNER JOIN projects p ON c.project_id = p.id
+WHERE c.author_login = $1 AND c.kind IN ('issue', 'pull_request') AND p.status IN ('verified', 'archived')
 `, *githubLogin).Scan(&projectsContributedToCount)
 		if err != nil {
 			projectsContributedToCount = 0
@@ -1107,15 +1108,28 @@ WHERE owner_user_id = $1 AND status = 'verified' AND deleted_at IS NULL
 			}
 		}
 
+		badges := []achievements.Earned{}
+		if settings.ShowBadges {
+			badges, err = achievements.ForLogin(c.Context(), h.db.Pool, *githubLogin)
+			if err != nil {
+				slog.Warn("failed to fetch badges", "error", err, "github_login", *githubLogin)
+				badges = []achievements.Earned{}
+			}
+		}
+
 		// Get avatar URL - try database first, then GitHub
 		var avatarURL *string
+		var ghName, ghBio, ghCompany, ghProfileURL *string
 		if userID != nil {
 			_ = h.db.Pool.QueryRow(c.Context(), `
-SELECT COALESCE(u.avatar_url, ga.avatar_url, '')
+SELECT COALESCE(u.avatar_url, ga.avatar_url, ''), ga.name, ga.bio, ga.company, ga.profile_url
 FROM users u
 LEFT JOIN github_accounts ga ON u.id = ga.user_id
 WHERE u.id = $1
-`, *userID).Scan(&avatarURL)
+`, *userID).Scan(&avatarURL, &ghName, &ghBio, &ghCompany, &ghProfileURL)
+		}
+		if settings.AvatarURL != nil {
+			avatarURL = settings.AvatarURL
 		}
 		// If no avatar in database, use GitHub avatar URL as fallback
 		if (avatarURL == nil || *avatarURL == "") && githubLogin != nil {
@@ -1142,9 +1156,7 @@ WHERE u.id = $1
 			"projects_led_count":            projectsLedCount,
 			"languages":                     languages,
 			"ecosystems":                    ecosystems,
-			"kyc_verified": func() bool {
-				return kycStatus != nil && *kycStatus == "verified"
-			}(),
+			"badges":                        badges,
 			"rank": fiber.Map{
 				"position":   rankPosition,
 				"tier":       string(rankTier),
@@ -1153,8 +1165,38 @@ WHERE u.id = $1
 			},
 		}
 
+		// Hidden fields are left out, as if they were never set.
+		if settings.ShowKYCStatus {
+			response["kyc_verified"] = kycStatus != nil && *kycStatus == "verified"
+		}
+		if !settings.ShowBio {
+			bio, ghBio = nil, nil
+		}
+		if !settings.ShowWebsite {
+			website = nil
+		}
+		if !settings.ShowSocialLinks {
+			telegram, linkedin, whatsapp, twitter, discord = nil, nil, nil, nil, nil
+		}
+		if settings.DisplayName != nil {
+			ghName = settings.DisplayName
+		}
+
 		if bio != nil && *bio != "" {
 			response["bio"] = *bio
+		} else if ghBio != nil && *ghBio != "" {
+			response["bio"] = *ghBio
+		}
+		if ghName != nil && *ghName != "" {
+			response["name"] = *ghName
+		}
+		if ghCompany != nil && *ghCompany != "" {
+			response["company"] = *ghCompany
+		}
+		if ghProfileURL != nil && *ghProfileURL != "" {
+			response["profile_url"] = *ghProfileURL
+		} else {
+			response["profile_url"] = "https://github.com/" + *githubLogin
 		}
 		if website != nil && *website != "" {
 			response["website"] = *website
@@ -1179,6 +1221,81 @@ WHERE u.id = $1
 	}
 }
 
+// Badges returns the badges the authenticated user has earned, plus the full catalog so locked
+// badges can be shown too. Users without a linked GitHub account have none.
+func (h *UserProfileHandler) Badges() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		badges := []achievements.Earned{}
+		var githubLogin string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT login
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&githubLogin)
+		if err == nil {
+			badges, err = achievements.ForLogin(c.Context(), h.db.Pool, githubLogin)
+			if err != nil {
+				slog.Error("failed to fetch badges", "error", err, "github_login", githubLogin)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "badges_fetch_failed"})
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"badges":    badges,
+			"available": achievements.Catalog,
+		})
+	}
+}
+
+// Digest returns the authenticated user's contribution digest for the last complete week or month
+// (?period=weekly|monthly, default weekly): contributions made, the change in global leaderboard
+// rank since their previous emailed digest, and projects listed in the ecosystems they contribute to.
+// The same summary is emailed to users who set digest_frequency.
+func (h *UserProfileHandler) Digest() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+		period := c.Query("period", digest.PeriodWeekly)
+		if !digest.ValidPeriod(period) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_period", "message": "period must be weekly or monthly"})
+		}
+
+		var githubLogin string
+		err = h.db.Pool.QueryRow(c.Context(), `
+SELECT login
+FROM github_accounts
+WHERE user_id = $1
+`, userID).Scan(&githubLogin)
+		if err != nil || githubLogin == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "github_not_linked"})
+		}
+
+		d, err := digest.Build(c.Context(), h.db.Pool, userID, githubLogin, period, time.Now())
+		if err != nil {
+			slog.Error("failed to build digest", "error", err, "user_id", userID, "github_login", githubLogin)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "digest_fetch_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(d)
+	}
+}
+
 // calculateContributionLevel determines the color level (0-4) based on contribution count
 // Uses GitHub's algorithm: levels are based on quartiles of the max count
 func calculateContributionLevel(count int, maxCount int) int {
@@ -1230,6 +1347,10 @@ func (h *UserProfileHandler) UpdateProfile() fiber.Handler {
 			WhatsApp  *string `json:"whatsapp,omitempty"`
 			Twitter   *string `json:"twitter,omitempty"`
 			Discord   *string `json:"discord,omitempty"`
+			// PublicProfile opts in to (or out of) the public page at /contributors/:login.
+			PublicProfile *bool `json:"public_profile,omitempty"`
+			// DigestFrequency is how often the contribution digest is emailed: off, weekly or monthly.
+			DigestFrequency *string `json:"digest_frequency,omitempty"`
 		}
 
 		if err := c.BodyParser(&req); err != nil {
@@ -1291,6 +1412,20 @@ func (h *UserProfileHandler) UpdateProfile() fiber.Handler {
 			args = append(args, strings.TrimSpace(*req.Discord))
 			argPos++
 		}
+		if req.PublicProfile != nil {
+			updates = append(updates, fmt.Sprintf("public_profile = $%d", argPos))
+			args = append(args, *req.PublicProfile)
+			argPos++
+		}
+		if req.DigestFrequency != nil {
+			freq := strings.TrimSpace(*req.DigestFrequency)
+			if freq != "off" && !digest.ValidPeriod(freq) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_digest_frequency", "message": "digest_frequency must be off, weekly or monthly"})
+			}
+			updates = append(updates, fmt.Sprintf("digest_frequency = $%d", argPos))
+			args = append(args, freq)
+			argPos++
+		}
 
 		if len(updates) == 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no_fields_to_update"})
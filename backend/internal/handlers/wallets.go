@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+// WalletsHandler manages additional wallets linked to an existing account.
+type WalletsHandler struct {
+	cfg config.Config
+	db  *db.DB
+}
+
+func NewWalletsHandler(cfg config.Config, d *db.DB) *WalletsHandler {
+	return &WalletsHandler{cfg: cfg, db: d}
+}
+
+// LinkNonce issues a nonce for proving ownership of a wallet to be linked.
+func (h *WalletsHandler) LinkNonce() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		var req nonceRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		wType, err := auth.NormalizeWalletType(req.WalletType)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+		}
+		addr, err := auth.NormalizeAddress(wType, req.Address)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+		}
+
+		n, err := auth.CreateNonce(c.Context(), h.db.Pool, wType, addr, 10*time.Minute)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "nonce_create_failed"})
+		}
+
+		resp, ok := signInChallenge(c, h.cfg, wType, addr, req.ChainID, auth.LinkWalletStatement, n)
+		if !ok {
+			return nil
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+// Link verifies the signed link message and attaches the wallet to the current user.
+func (h *WalletsHandler) Link() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var req verifyRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		wType, err := auth.NormalizeWalletType(req.WalletType)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+		}
+		addr, err := auth.NormalizeAddress(wType, req.Address)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+		}
+		if req.Nonce == "" || req.Signature == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_nonce_or_signature"})
+		}
+
+		if !checkSignInMessage(c, h.cfg, wType, addr, req, auth.LinkWalletStatement) {
+			return nil
+		}
+
+		w, err := auth.ConsumeNonceAndLinkWallet(c.Context(), h.db.Pool, userID, wType, addr, req.Nonce, req.PublicKey)
+		if err != nil {
+			if errors.Is(err, auth.ErrWalletLinkedElsewhere) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "wallet_linked_to_other_user"})
+			}
+			if err.Error() == "invalid_or_expired_nonce" {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_or_expired_nonce"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "wallet_link_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ok": true,
+			"wallet": fiber.Map{
+				"wallet_type": w.WalletType,
+				"address":     w.Address,
+			},
+		})
+	}
+}
+
+// List returns all wallets linked to the current user.
+func (h *WalletsHandler) List() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		wallets, err := auth.ListWallets(c.Context(), h.db.Pool, userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "wallets_list_failed"})
+		}
+
+		out := []fiber.Map{}
+		for _, w := range wallets {
+			out = append(out, fiber.Map{
+				"wallet_type": w.WalletType,
+				"address":     w.Address,
+				"public_key":  w.PublicKey,
+				"created_at":  w.CreatedAt,
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"wallets": out})
+	}
+}
+
+// Unlink removes a wallet from the current user. Optional ?wallet_type= disambiguates
+// when the same address is linked under multiple wallet types.
+func (h *WalletsHandler) Unlink() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sub, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(sub)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		address := c.Params("address")
+		if address == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+		}
+		var wType auth.WalletType
+		if q := c.Query("wallet_type"); q != "" {
+			wType, err = auth.NormalizeWalletType(q)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			}
+		}
+
+		if err := auth.UnlinkWallet(c.Context(), h.db.Pool, userID, wType, address); err != nil {
+			switch {
+			case errors.Is(err, auth.ErrWalletNotFound):
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "wallet_not_found"})
+			case errors.Is(err, auth.ErrLastLoginMethod):
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "last_login_method"})
+			default:
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "wallet_unlink_failed"})
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
@@ -0,0 +1,43 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+)
+
+// queueListedComment records an issue_listed bot comment for a newly opened issue and queues the
+// job that posts it. Only projects that opted in and have a GitHub App installation get one; the
+// unique key keeps redelivered events from commenting twice.
+func (i *GitHubWebhookIngestor) queueListedComment(ctx context.Context, projectID string, issueNumber int) {
+	ct, err := i.Pool.Exec(ctx, `
+INSERT INTO github_bot_comments (project_id, issue_number, kind)
+SELECT p.id, $2, 'issue_listed'
+FROM projects p
+WHERE p.id = $1::uuid
+  AND p.bot_comments_enabled
+  AND p.status = 'verified'
+  AND COALESCE(p.github_app_installation_id, '') != ''
+ON CONFLICT (project_id, issue_number, kind) DO NOTHING
+`, projectID, issueNumber)
+	if err != nil {
+		slog.Warn("bot comment queue failed", "project_id", projectID, "issue_number", issueNumber, "error", err)
+		return
+	}
+	if ct.RowsAffected() == 0 {
+		return
+	}
+	ct, err = i.Pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at, priority)
+SELECT $1::uuid, 'post_bot_comments', 'pending', now(), 20
+WHERE NOT EXISTS (
+  SELECT 1 FROM sync_jobs
+  WHERE project_id = $1::uuid AND job_type = 'post_bot_comments' AND status IN ('pending', 'retrying')
+)
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, projectID)
+	if err == nil && ct.RowsAffected() > 0 {
+		syncjobs.PublishEnqueued(ctx, i.Bus, projectID, "post_bot_comments")
+	}
+}
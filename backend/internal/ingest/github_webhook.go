@@ -7,19 +7,30 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/jagadeesh/grainlify/backend/internal/achievements"
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/contributions"
 	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
 )
 
 type GitHubWebhookIngestor struct {
 	Pool *pgxpool.Pool
+	// Bus, when set, gets a sync.job.enqueued event for jobs queued by deliveries.
+	Bus bus.Bus
 }
 
 func (i *GitHubWebhookIngestor) Ingest(ctx context.Context, e events.GitHubWebhookReceived) error {
 	if i == nil || i.Pool == nil {
 		return nil
 	}
+	// Snapshot rows upserted below get last_seen_at past this; the minute of slack covers clock
+	// skew with the database.
+	seenSince := time.Now().Add(-time.Minute)
 
 	// Parse minimal envelope for mapping to project and snapshot upserts.
 	var env ghWebhookEnvelope
@@ -35,20 +46,54 @@ func (i *GitHubWebhookIngestor) Ingest(ctx context.Context, e events.GitHubWebho
 	}
 
 	var projectID *string
+	var projectStatus string
 	if repoFullName != "" {
 		var pid string
-		if err := i.Pool.QueryRow(ctx, `SELECT id FROM projects WHERE github_full_name = $1`, repoFullName).Scan(&pid); err == nil {
+		// Case-insensitive: org webhooks deliver for every repo in the org and GitHub names are case-insensitive.
+		if err := i.Pool.QueryRow(ctx, `
+SELECT id, status FROM projects WHERE lower(github_full_name) = lower($1) AND deleted_at IS NULL
+`, repoFullName).Scan(&pid, &projectStatus); err == nil {
 			projectID = &pid
+		} else if env.Repository != nil && env.Repository.ID != 0 {
+			// Unknown name but known repo ID: the repo was renamed or transferred and we missed (or haven't
+			// yet processed) the repository event. Follow the ID and adopt the new name.
+			if err := i.Pool.QueryRow(ctx, `
+SELECT id, status FROM projects WHERE github_repo_id = $1 AND deleted_at IS NULL
+`, env.Repository.ID).Scan(&pid, &projectStatus); err == nil {
+				projectID = &pid
+				if _, err := github.UpdateRepoFullName(ctx, i.Pool, env.Repository.ID, repoFullName); err != nil {
+					slog.Warn("failed to adopt renamed repository", "project_id", pid, "repo", repoFullName, "error", err)
+				}
+			}
 		}
 	}
 
-	// Auditable event record (idempotent via delivery_id primary key).
-	if e.DeliveryID != "" {
-		_, _ = i.Pool.Exec(ctx, `
+	// Archived projects keep their history but take no new activity: the delivery is dropped.
+	if projectID != nil && projectStatus == "archived" {
+		slog.Debug("github webhook ignored: project archived",
+			"project_id", *projectID,
+			"repo", repoFullName,
+			"event", e.Event,
+			"delivery_id", e.DeliveryID,
+		)
+		return nil
+	}
+
+	// An org webhook fires for every repo in the org; only keep deliveries for registered projects.
+	orgDeliveryForUnknownRepo := e.HookTargetType == "organization" && projectID == nil
+
+	// Auditable event record (idempotent via delivery_id primary key). A redelivery (GitHub retry,
+	// NATS redelivery) of a delivery whose follow-up work already ran only refreshes the snapshots.
+	recorded, alreadyProcessed := false, false
+	if e.DeliveryID != "" && !orgDeliveryForUnknownRepo {
+		var processedAt *time.Time
+		err := i.Pool.QueryRow(ctx, `
 INSERT INTO github_events (delivery_id, project_id, repo_full_name, event, action, payload)
 VALUES ($1, $2::uuid, $3, $4, $5, $6::jsonb)
-ON CONFLICT (delivery_id) DO NOTHING
-`, e.DeliveryID, projectID, repoFullName, e.Event, nullIfEmpty(action), string(e.Payload))
+ON CONFLICT (delivery_id) DO UPDATE SET delivery_id = EXCLUDED.delivery_id
+RETURNING processed_at
+`, e.DeliveryID, projectID, repoFullName, e.Event, nullIfEmpty(action), string(e.Payload)).Scan(&processedAt)
+		recorded, alreadyProcessed = err == nil, err == nil && processedAt != nil
 	}
 
 	// Snapshot upserts (idempotent).
@@ -94,13 +139,69 @@ ON CONFLICT (project_id, github_pr_id) DO UPDATE SET
 		}
 	}
 
+	if projectID != nil {
+		switch e.Event {
+		case "push":
+			i.ingestPushCommits(ctx, *projectID, e.Payload)
+		case "issues":
+			if action == "opened" && env.Issue != nil && env.Issue.Number > 0 && !alreadyProcessed {
+				i.queueListedComment(ctx, *projectID, env.Issue.Number)
+			}
+		case "issue_comment":
+			i.ingestIssueComment(ctx, *projectID, action, e.Payload)
+		case "pull_request_review_comment":
+			i.ingestReviewComment(ctx, *projectID, action, e.Payload)
+		case "discussion":
+			i.ingestDiscussion(ctx, *projectID, action, e.Payload)
+		case "release":
+			i.ingestRelease(ctx, *projectID, action, e.Payload)
+		}
+	}
+
+	// Copy the snapshot rows this delivery touched into contributions before badges read them.
+	if projectID != nil {
+		if pid, err := uuid.Parse(*projectID); err == nil {
+			if err := contributions.SyncProject(ctx, i.Pool, pid, seenSince); err != nil {
+				slog.Warn("contributions refresh failed", "project_id", *projectID, "error", err)
+			}
+		}
+	}
+
+	// Award badges the delivery's author has now earned (best-effort).
+	if projectID != nil && !alreadyProcessed {
+		var author string
+		switch {
+		case e.Event == "issues" && env.Issue != nil:
+			author = env.Issue.User.Login
+		case e.Event == "pull_request" && env.PullRequest != nil:
+			author = env.PullRequest.User.Login
+		}
+		if author != "" {
+			if _, err := achievements.Evaluate(ctx, i.Pool, author); err != nil {
+				slog.Warn("badge evaluation failed", "login", author, "project_id", *projectID, "error", err)
+			}
+		}
+	}
+
 	// Enqueue follow-up sync jobs (best-effort).
-	if projectID != nil && (e.Event == "issues" || e.Event == "pull_request" || e.Event == "push") {
-		_, _ = i.Pool.Exec(ctx, `
-INSERT INTO sync_jobs (project_id, job_type, status, run_at)
-VALUES ($1::uuid, 'sync_issues', 'pending', now()),
-       ($1::uuid, 'sync_prs', 'pending', now())
+	if projectID != nil && !alreadyProcessed && (e.Event == "issues" || e.Event == "pull_request" || e.Event == "push") {
+		ct, err := i.Pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at, priority)
+VALUES ($1::uuid, 'sync_issues', 'pending', now(), 20),
+       ($1::uuid, 'sync_prs', 'pending', now(), 20)
+ON CONFLICT (project_id, job_type) WHERE status = 'pending'
+DO UPDATE SET priority = GREATEST(sync_jobs.priority, EXCLUDED.priority), updated_at = now()
 `, *projectID)
+		if err == nil && ct.RowsAffected() > 0 {
+			syncjobs.PublishEnqueued(ctx, i.Bus, *projectID, "sync_issues", "sync_prs")
+		}
+	}
+	if recorded && !alreadyProcessed {
+		_, _ = i.Pool.Exec(ctx, `UPDATE github_events SET processed_at = now() WHERE delivery_id = $1`, e.DeliveryID)
+	}
+
+	if e.Event == "repository" && env.Repository != nil && env.Repository.ID != 0 {
+		i.handleRepositoryEvent(ctx, action, *env.Repository)
 	}
 
 	// Handle GitHub App installation events
@@ -191,7 +292,7 @@ WHERE github_full_name = $1
 			}
 		}
 	} else if action == "added" && e.Event == "installation_repositories" {
-		// Repositories were added back to installation - restore them
+		// Repositories were added back to installation - restore them (unapproved ones go back to review)
 		if installationPayload.RepositoriesAdded != nil {
 			for _, repo := range installationPayload.RepositoriesAdded {
 				repoFullName := strings.TrimSpace(repo.FullName)
@@ -199,7 +300,7 @@ WHERE github_full_name = $1
 					_, _ = i.Pool.Exec(ctx, `
 UPDATE projects
 SET deleted_at = NULL,
-    status = 'verified',
+    status = CASE WHEN approved_at IS NULL THEN 'pending_review' ELSE 'verified' END,
     updated_at = now()
 WHERE github_full_name = $1
   AND github_app_installation_id = $2
@@ -211,6 +312,68 @@ WHERE github_full_name = $1
 	}
 }
 
+// ingestPushCommits stores the commits of a push to the default branch. Pushes to other branches are
+// skipped so the contribution counts match what sync_commits sees.
+func (i *GitHubWebhookIngestor) ingestPushCommits(ctx context.Context, projectID string, payload json.RawMessage) {
+	var push ghPushPayload
+	if err := json.Unmarshal(payload, &push); err != nil {
+		return
+	}
+	if push.Repository.DefaultBranch == "" || push.Ref != "refs/heads/"+push.Repository.DefaultBranch {
+		return
+	}
+	for _, c := range push.Commits {
+		if !c.Distinct || c.ID == "" {
+			continue
+		}
+		_, err := i.Pool.Exec(ctx, `
+INSERT INTO github_commits (project_id, sha, author_login, author_name, author_email, message, url, committed_at, last_seen_at)
+VALUES ($1::uuid, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, now())
+ON CONFLICT (project_id, sha) DO UPDATE SET
+  author_login = COALESCE(EXCLUDED.author_login, github_commits.author_login),
+  last_seen_at = now()
+`, projectID, c.ID, c.Author.Username, c.Author.Name, c.Author.Email, c.Message, c.URL, c.Timestamp)
+		if err != nil {
+			slog.Warn("push commit upsert failed", "project_id", projectID, "sha", c.ID, "error", err)
+		}
+	}
+}
+
+// handleRepositoryEvent keeps projects pointed at the right repo when it is renamed, transferred or deleted.
+// Matching is by github_repo_id, which survives renames; without this, webhook routing by full name breaks.
+func (i *GitHubWebhookIngestor) handleRepositoryEvent(ctx context.Context, action string, repo ghRepoPayload) {
+	switch action {
+	case "renamed", "transferred":
+		fullName := strings.TrimSpace(repo.FullName)
+		if fullName == "" {
+			return
+		}
+		n, err := github.UpdateRepoFullName(ctx, i.Pool, repo.ID, fullName)
+		if err != nil {
+			slog.Error("failed to update project after repository rename", "github_repo_id", repo.ID, "repo", fullName, "error", err)
+			return
+		}
+		if n > 0 {
+			slog.Info("project repository renamed", "github_repo_id", repo.ID, "repo", fullName, "action", action)
+		}
+	case "deleted":
+		ct, err := i.Pool.Exec(ctx, `
+UPDATE projects
+SET deleted_at = now(),
+    updated_at = now()
+WHERE github_repo_id = $1
+  AND deleted_at IS NULL
+`, repo.ID)
+		if err != nil {
+			slog.Error("failed to delete project for deleted repository", "github_repo_id", repo.ID, "error", err)
+			return
+		}
+		if ct.RowsAffected() > 0 {
+			slog.Info("marked project as deleted after repository deletion", "github_repo_id", repo.ID)
+		}
+	}
+}
+
 type ghWebhookEnvelope struct {
 	Action      string               `json:"action"`
 	Repository  *ghRepoPayload       `json:"repository"`
@@ -219,9 +382,31 @@ type ghWebhookEnvelope struct {
 }
 
 type ghRepoPayload struct {
+	ID       int64  `json:"id"`
 	FullName string `json:"full_name"`
 }
 
+type ghPushPayload struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	Commits []ghPushCommit `json:"commits"`
+}
+
+type ghPushCommit struct {
+	ID        string     `json:"id"`
+	Distinct  bool       `json:"distinct"`
+	Message   string     `json:"message"`
+	URL       string     `json:"url"`
+	Timestamp *time.Time `json:"timestamp"`
+	Author    struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
 type ghUserPayload struct {
 	Login string `json:"login"`
 }
@@ -0,0 +1,239 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// ingestIssueComment stores issue_comment deliveries. For issues (not PRs) the comments snapshot on
+// github_issues is patched too, so the sync worker doesn't have to refetch comments through REST.
+func (i *GitHubWebhookIngestor) ingestIssueComment(ctx context.Context, projectID string, action string, payload json.RawMessage) {
+	var p struct {
+		Issue struct {
+			Number      int             `json:"number"`
+			PullRequest json.RawMessage `json:"pull_request"`
+		} `json:"issue"`
+		Comment *ghCommentPayload `json:"comment"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.Comment == nil || p.Comment.ID == 0 {
+		return
+	}
+	c := p.Comment
+	isPR := len(p.Issue.PullRequest) > 0 && string(p.Issue.PullRequest) != "null"
+
+	if action == "deleted" {
+		_, err := i.Pool.Exec(ctx, `
+UPDATE github_issue_comments SET deleted_at = now(), last_seen_at = now()
+WHERE project_id = $1::uuid AND github_comment_id = $2
+`, projectID, c.ID)
+		if err != nil {
+			slog.Warn("issue comment delete failed", "project_id", projectID, "comment_id", c.ID, "error", err)
+		}
+		if !isPR {
+			_, _ = i.Pool.Exec(ctx, `
+UPDATE github_issues
+SET comments = (
+  SELECT COALESCE(jsonb_agg(elem), '[]'::jsonb)
+  FROM jsonb_array_elements(comments) AS elem
+  WHERE (elem->>'id')::bigint != $3
+),
+comments_count = GREATEST(0, COALESCE(comments_count, 0) - 1),
+last_seen_at = now()
+WHERE project_id = $1::uuid AND number = $2
+  AND comments @> jsonb_build_array(jsonb_build_object('id', $3::bigint))
+`, projectID, p.Issue.Number, c.ID)
+		}
+		return
+	}
+
+	_, err := i.Pool.Exec(ctx, `
+INSERT INTO github_issue_comments (project_id, github_comment_id, issue_number, is_pull_request, author_login, body, url, created_at_github, updated_at_github, last_seen_at)
+VALUES ($1::uuid, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, now())
+ON CONFLICT (project_id, github_comment_id) DO UPDATE SET
+  body = EXCLUDED.body,
+  updated_at_github = EXCLUDED.updated_at_github,
+  last_seen_at = now()
+WHERE github_issue_comments.deleted_at IS NULL
+`, projectID, c.ID, p.Issue.Number, isPR, c.User.Login, c.Body, c.HTMLURL, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		slog.Warn("issue comment upsert failed", "project_id", projectID, "comment_id", c.ID, "error", err)
+		return
+	}
+	if isPR {
+		return
+	}
+
+	// Same shape the sync worker stores (github.IssueComment).
+	var snap github.IssueComment
+	snap.ID, snap.Body, snap.User.Login = c.ID, c.Body, c.User.Login
+	if c.CreatedAt != nil {
+		snap.CreatedAt = c.CreatedAt.Format(time.RFC3339)
+	}
+	if c.UpdatedAt != nil {
+		snap.UpdatedAt = c.UpdatedAt.Format(time.RFC3339)
+	}
+	snapJSON, _ := json.Marshal(snap)
+
+	// Replace the comment if present, append it otherwise.
+	_, _ = i.Pool.Exec(ctx, `
+UPDATE github_issues
+SET comments = CASE
+      WHEN COALESCE(comments, '[]'::jsonb) @> jsonb_build_array(jsonb_build_object('id', $4::bigint)) THEN (
+        SELECT jsonb_agg(CASE WHEN (elem->>'id')::bigint = $4 THEN $3::jsonb ELSE elem END ORDER BY ord)
+        FROM jsonb_array_elements(comments) WITH ORDINALITY AS t(elem, ord)
+      )
+      ELSE COALESCE(comments, '[]'::jsonb) || jsonb_build_array($3::jsonb)
+    END,
+    comments_count = CASE
+      WHEN COALESCE(comments, '[]'::jsonb) @> jsonb_build_array(jsonb_build_object('id', $4::bigint)) THEN comments_count
+      ELSE COALESCE(comments_count, 0) + 1
+    END,
+    last_seen_at = now()
+WHERE project_id = $1::uuid AND number = $2
+`, projectID, p.Issue.Number, string(snapJSON), c.ID)
+}
+
+func (i *GitHubWebhookIngestor) ingestReviewComment(ctx context.Context, projectID string, action string, payload json.RawMessage) {
+	var p struct {
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+		Comment *ghCommentPayload `json:"comment"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.Comment == nil || p.Comment.ID == 0 {
+		return
+	}
+	c := p.Comment
+	if action == "deleted" {
+		_, _ = i.Pool.Exec(ctx, `
+UPDATE github_pr_review_comments SET deleted_at = now(), last_seen_at = now()
+WHERE project_id = $1::uuid AND github_comment_id = $2
+`, projectID, c.ID)
+		return
+	}
+	_, err := i.Pool.Exec(ctx, `
+INSERT INTO github_pr_review_comments (project_id, github_comment_id, pr_number, review_id, in_reply_to_id, author_login, body, path, commit_sha, url, created_at_github, updated_at_github, last_seen_at)
+VALUES ($1::uuid, $2, $3, NULLIF($4::bigint, 0), NULLIF($5::bigint, 0), NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12, now())
+ON CONFLICT (project_id, github_comment_id) DO UPDATE SET
+  body = EXCLUDED.body,
+  updated_at_github = EXCLUDED.updated_at_github,
+  last_seen_at = now()
+WHERE github_pr_review_comments.deleted_at IS NULL
+`, projectID, c.ID, p.PullRequest.Number, c.ReviewID, c.InReplyToID, c.User.Login, c.Body, c.Path, c.CommitID, c.HTMLURL, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		slog.Warn("review comment upsert failed", "project_id", projectID, "comment_id", c.ID, "error", err)
+	}
+}
+
+func (i *GitHubWebhookIngestor) ingestDiscussion(ctx context.Context, projectID string, action string, payload json.RawMessage) {
+	var p struct {
+		Discussion *struct {
+			ID             int64         `json:"id"`
+			Number         int           `json:"number"`
+			Title          string        `json:"title"`
+			Body           string        `json:"body"`
+			State          string        `json:"state"`
+			HTMLURL        string        `json:"html_url"`
+			User           ghUserPayload `json:"user"`
+			Comments       int           `json:"comments"`
+			AnswerChosenAt *time.Time    `json:"answer_chosen_at"`
+			CreatedAt      *time.Time    `json:"created_at"`
+			UpdatedAt      *time.Time    `json:"updated_at"`
+			Category       struct {
+				Name string `json:"name"`
+			} `json:"category"`
+		} `json:"discussion"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.Discussion == nil || p.Discussion.ID == 0 {
+		return
+	}
+	d := p.Discussion
+	if action == "deleted" || action == "transferred" {
+		_, _ = i.Pool.Exec(ctx, `
+UPDATE github_discussions SET deleted_at = now(), last_seen_at = now()
+WHERE project_id = $1::uuid AND github_discussion_id = $2
+`, projectID, d.ID)
+		return
+	}
+	_, err := i.Pool.Exec(ctx, `
+INSERT INTO github_discussions (project_id, github_discussion_id, number, title, body, category, state, author_login, url, comments_count, answered_at, created_at_github, updated_at_github, last_seen_at)
+VALUES ($1::uuid, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11, $12, $13, now())
+ON CONFLICT (project_id, github_discussion_id) DO UPDATE SET
+  number = EXCLUDED.number,
+  title = EXCLUDED.title,
+  body = EXCLUDED.body,
+  category = EXCLUDED.category,
+  state = EXCLUDED.state,
+  url = EXCLUDED.url,
+  comments_count = EXCLUDED.comments_count,
+  answered_at = EXCLUDED.answered_at,
+  updated_at_github = EXCLUDED.updated_at_github,
+  last_seen_at = now()
+WHERE github_discussions.deleted_at IS NULL
+`, projectID, d.ID, d.Number, d.Title, d.Body, d.Category.Name, d.State, d.User.Login, d.HTMLURL, d.Comments, d.AnswerChosenAt, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		slog.Warn("discussion upsert failed", "project_id", projectID, "discussion_id", d.ID, "error", err)
+	}
+}
+
+func (i *GitHubWebhookIngestor) ingestRelease(ctx context.Context, projectID string, action string, payload json.RawMessage) {
+	var p struct {
+		Release *struct {
+			ID          int64         `json:"id"`
+			TagName     string        `json:"tag_name"`
+			Name        string        `json:"name"`
+			Body        string        `json:"body"`
+			Draft       bool          `json:"draft"`
+			Prerelease  bool          `json:"prerelease"`
+			HTMLURL     string        `json:"html_url"`
+			Author      ghUserPayload `json:"author"`
+			CreatedAt   *time.Time    `json:"created_at"`
+			PublishedAt *time.Time    `json:"published_at"`
+		} `json:"release"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil || p.Release == nil || p.Release.ID == 0 {
+		return
+	}
+	r := p.Release
+	if action == "deleted" {
+		_, _ = i.Pool.Exec(ctx, `
+UPDATE github_releases SET deleted_at = now(), last_seen_at = now()
+WHERE project_id = $1::uuid AND github_release_id = $2
+`, projectID, r.ID)
+		return
+	}
+	_, err := i.Pool.Exec(ctx, `
+INSERT INTO github_releases (project_id, github_release_id, tag_name, name, body, draft, prerelease, author_login, url, created_at_github, published_at_github, last_seen_at)
+VALUES ($1::uuid, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''), $9, $10, $11, now())
+ON CONFLICT (project_id, github_release_id) DO UPDATE SET
+  tag_name = EXCLUDED.tag_name,
+  name = EXCLUDED.name,
+  body = EXCLUDED.body,
+  draft = EXCLUDED.draft,
+  prerelease = EXCLUDED.prerelease,
+  url = EXCLUDED.url,
+  published_at_github = EXCLUDED.published_at_github,
+  last_seen_at = now()
+WHERE github_releases.deleted_at IS NULL
+`, projectID, r.ID, r.TagName, r.Name, r.Body, r.Draft, r.Prerelease, r.Author.Login, r.HTMLURL, r.CreatedAt, r.PublishedAt)
+	if err != nil {
+		slog.Warn("release upsert failed", "project_id", projectID, "release_id", r.ID, "error", err)
+	}
+}
+
+type ghCommentPayload struct {
+	ID          int64         `json:"id"`
+	Body        string        `json:"body"`
+	HTMLURL     string        `json:"html_url"`
+	User        ghUserPayload `json:"user"`
+	ReviewID    int64         `json:"pull_request_review_id"`
+	InReplyToID int64         `json:"in_reply_to_id"`
+	Path        string        `json:"path"`
+	CommitID    string        `json:"commit_id"`
+	CreatedAt   *time.Time    `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,213 @@
+// Package kyc encrypts the personal data in stored KYC results at rest.
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/cryptox"
+)
+
+// plainKeys are the top-level kyc_sessions.data fields that carry no personal data and stay
+// readable in the database. Everything else Didit returns (decision, data, extracted, ...) is sealed.
+var plainKeys = map[string]bool{
+	"session_url": true,
+}
+
+// Seal splits a KYC data document into its plaintext part and the AES-GCM encrypted rest. With no
+// key configured (local development) the document is returned unchanged and enc is nil.
+func Seal(data []byte, keyB64 string) (plain []byte, enc []byte, err error) {
+	if keyB64 == "" || len(data) == 0 {
+		return data, nil, nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		// Not an object (e.g. null): nothing to split, seal it whole.
+		enc, err := SealAll(data, keyB64)
+		return []byte("{}"), enc, err
+	}
+	public := map[string]json.RawMessage{}
+	private := map[string]json.RawMessage{}
+	for k, v := range doc {
+		if plainKeys[k] {
+			public[k] = v
+		} else {
+			private[k] = v
+		}
+	}
+	plain, _ = json.Marshal(public)
+	if len(private) == 0 {
+		return plain, nil, nil
+	}
+	b, _ := json.Marshal(private)
+	enc, err = SealAll(b, keyB64)
+	return plain, enc, err
+}
+
+// Open reverses Seal, merging the decrypted fields back into the plaintext document.
+func Open(plain []byte, enc []byte, keyB64 string) ([]byte, error) {
+	if len(enc) == 0 {
+		return plain, nil
+	}
+	b, err := OpenAll(enc, keyB64)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]json.RawMessage{}
+	if len(plain) > 0 {
+		_ = json.Unmarshal(plain, &doc)
+	}
+	var private map[string]json.RawMessage
+	if err := json.Unmarshal(b, &private); err != nil {
+		// Sealed whole (not an object).
+		return b, nil
+	}
+	if doc == nil {
+		doc = map[string]json.RawMessage{}
+	}
+	for k, v := range private {
+		doc[k] = v
+	}
+	return json.Marshal(doc)
+}
+
+// SealAll encrypts b as a whole, e.g. a raw webhook payload.
+func SealAll(b []byte, keyB64 string) ([]byte, error) {
+	key, err := cryptox.KeyFromB64(keyB64)
+	if err != nil {
+		return nil, err
+	}
+	return cryptox.EncryptAESGCM(key, b)
+}
+
+// OpenAll decrypts a value sealed with SealAll.
+func OpenAll(enc []byte, keyB64 string) ([]byte, error) {
+	key, err := cryptox.KeyFromB64(keyB64)
+	if err != nil {
+		return nil, err
+	}
+	return cryptox.DecryptAESGCM(key, enc)
+}
+
+// EncryptExisting seals the personal data of KYC rows written before encryption was enabled (or
+// while no key was configured): kyc_sessions.data and kyc_events.payload. It works in batches and
+// is safe to run on every start.
+func EncryptExisting(ctx context.Context, pool *pgxpool.Pool, keyB64 string) {
+	if pool == nil || keyB64 == "" {
+		return
+	}
+	if _, err := cryptox.KeyFromB64(keyB64); err != nil {
+		slog.Error("kyc encryption backfill skipped: invalid key", "error", err)
+		return
+	}
+	sessions, events := 0, 0
+	for {
+		n, err := encryptSessions(ctx, pool, keyB64)
+		sessions += n
+		if err != nil {
+			slog.Error("kyc encryption backfill failed", "table", "kyc_sessions", "error", err)
+			return
+		}
+		if n == 0 {
+			break
+		}
+	}
+	for {
+		n, err := encryptEvents(ctx, pool, keyB64)
+		events += n
+		if err != nil {
+			slog.Error("kyc encryption backfill failed", "table", "kyc_events", "error", err)
+			return
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if sessions > 0 || events > 0 {
+		slog.Info("kyc personal data encrypted", "sessions", sessions, "events", events)
+	}
+}
+
+const backfillBatch = 200
+
+func encryptSessions(ctx context.Context, pool *pgxpool.Pool, keyB64 string) (int, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, data FROM kyc_sessions
+WHERE pii_enc IS NULL AND jsonb_typeof(data) = 'object' AND data - 'session_url' <> '{}'::jsonb
+LIMIT $1
+`, backfillBatch)
+	if err != nil {
+		return 0, err
+	}
+	type row struct {
+		id   uuid.UUID
+		data []byte
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, r := range batch {
+		plain, enc, err := Seal(r.data, keyB64)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := pool.Exec(ctx, `
+UPDATE kyc_sessions SET data = $2, pii_enc = $3 WHERE id = $1 AND pii_enc IS NULL
+`, r.id, plain, enc); err != nil {
+			return 0, err
+		}
+	}
+	return len(batch), nil
+}
+
+func encryptEvents(ctx context.Context, pool *pgxpool.Pool, keyB64 string) (int, error) {
+	rows, err := pool.Query(ctx, `
+SELECT id, payload FROM kyc_events WHERE payload IS NOT NULL LIMIT $1
+`, backfillBatch)
+	if err != nil {
+		return 0, err
+	}
+	type row struct {
+		id      uuid.UUID
+		payload []byte
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.payload); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, r := range batch {
+		enc, err := SealAll(r.payload, keyB64)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := pool.Exec(ctx, `
+UPDATE kyc_events SET payload = NULL, payload_enc = $2 WHERE id = $1
+`, r.id, enc); err != nil {
+			return 0, err
+		}
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,43 @@
+package kyc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	data := []byte(`{"session_url":"https://verify.didit.me/session/abc","extracted":{"full_name":"Jane Doe","document_number":"X123"}}`)
+
+	plain, enc, err := Seal(data, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(plain), "Jane") || enc == nil {
+		t.Fatalf("personal data left in plaintext: %s", plain)
+	}
+	if !strings.Contains(string(plain), "session_url") {
+		t.Fatalf("session_url should stay readable: %s", plain)
+	}
+
+	opened, err := Open(plain, enc, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want map[string]any
+	_ = json.Unmarshal(opened, &got)
+	_ = json.Unmarshal(data, &want)
+	gb, _ := json.Marshal(got)
+	wb, _ := json.Marshal(want)
+	if !bytes.Equal(gb, wb) {
+		t.Fatalf("round trip: got %s, want %s", gb, wb)
+	}
+
+	// Without a key the document is stored as is.
+	if plain, enc, _ := Seal(data, ""); !bytes.Equal(plain, data) || enc != nil {
+		t.Fatalf("no key: got %s, %v", plain, enc)
+	}
+}
@@ -0,0 +1,202 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reasons a screening blocks a verified session.
+const (
+	ScreenBlockedCountry = "blocked_country"
+	ScreenSanctionsMatch = "sanctions_match"
+	// The person could not be screened: no country or name in the decision, or the sanctions
+	// provider failed. The next status check screens again.
+	ScreenUnavailable = "screening_unavailable"
+)
+
+// Screening is the outcome of screening a verified session, stored in kyc_sessions.screening.
+type Screening struct {
+	Blocked   bool      `json:"blocked"`
+	Reason    string    `json:"reason,omitempty"`
+	Country   string    `json:"country,omitempty"`   // the blocked country that matched
+	Reference string    `json:"reference,omitempty"` // the sanctions provider's reference for a match
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Subject is the person a sanctions provider is asked about.
+type Subject struct {
+	FullName    string   `json:"full_name"`
+	DateOfBirth string   `json:"date_of_birth,omitempty"`
+	Countries   []string `json:"countries,omitempty"`
+}
+
+// SanctionsChecker looks a verified person up on sanctions lists.
+type SanctionsChecker interface {
+	Check(ctx context.Context, subject Subject) (match bool, reference string, err error)
+}
+
+// Screener is the screening stage run on sessions Didit verified: the person's nationality and
+// document country are checked against the blocked countries, then the sanctions provider (when one
+// is configured) is asked about them. A nil Screener passes everyone.
+type Screener struct {
+	blocked   map[string]bool
+	sanctions SanctionsChecker
+}
+
+// NewScreener returns a Screener blocking the given country codes (as Didit reports them, ISO 3166-1
+// alpha-3); sanctions may be nil.
+func NewScreener(blockedCountries []string, sanctions SanctionsChecker) *Screener {
+	s := &Screener{blocked: map[string]bool{}, sanctions: sanctions}
+	for _, code := range blockedCountries {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			s.blocked[code] = true
+		}
+	}
+	return s
+}
+
+func (s *Screener) enabled() bool {
+	return s != nil && (len(s.blocked) > 0 || s.sanctions != nil)
+}
+
+// Apply screens doc, a stored Didit decision document, when status is "verified". It returns the
+// status to store ("blocked" when the screening fails) and the screening as JSON, nil when nothing
+// was screened.
+func (s *Screener) Apply(ctx context.Context, status string, doc map[string]interface{}) (string, []byte) {
+	if status != "verified" || !s.enabled() {
+		return status, nil
+	}
+	res := s.Screen(ctx, doc)
+	b, _ := json.Marshal(res)
+	if res.Blocked {
+		return "blocked", b
+	}
+	return status, b
+}
+
+// Screen runs the checks on doc.
+func (s *Screener) Screen(ctx context.Context, doc map[string]interface{}) Screening {
+	res := Screening{CheckedAt: time.Now().UTC()}
+	info, _ := doc["extracted"].(map[string]interface{})
+	if info == nil {
+		info = ExtractInfo(doc)
+	}
+	countries := subjectCountries(info)
+
+	if len(s.blocked) > 0 {
+		if len(countries) == 0 {
+			res.Blocked, res.Reason = true, ScreenUnavailable
+			return res
+		}
+		for _, c := range countries {
+			if s.blocked[c] {
+				res.Blocked, res.Reason, res.Country = true, ScreenBlockedCountry, c
+				return res
+			}
+		}
+	}
+
+	if s.sanctions == nil {
+		return res
+	}
+	subject := Subject{Countries: countries}
+	subject.FullName, _ = info["full_name"].(string)
+	if subject.FullName == "" {
+		first, _ := info["first_name"].(string)
+		last, _ := info["last_name"].(string)
+		subject.FullName = strings.TrimSpace(first + " " + last)
+	}
+	subject.DateOfBirth, _ = info["date_of_birth"].(string)
+	if subject.FullName == "" {
+		res.Blocked, res.Reason = true, ScreenUnavailable
+		return res
+	}
+	match, ref, err := s.sanctions.Check(ctx, subject)
+	if err != nil {
+		slog.Warn("sanctions screening failed", "error", err)
+		res.Blocked, res.Reason = true, ScreenUnavailable
+		return res
+	}
+	if match {
+		res.Blocked, res.Reason, res.Reference = true, ScreenSanctionsMatch, ref
+	}
+	return res
+}
+
+// subjectCountries returns the upper-cased nationality and document country from extracted info,
+// without duplicates.
+func subjectCountries(info map[string]interface{}) []string {
+	var out []string
+	for _, key := range []string{"nationality", "issuing_state"} {
+		c, _ := info[key].(string)
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c != "" && (len(out) == 0 || out[0] != c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ApplyOverride returns the status to store for a session given an admin override of its
+// screening: an approval lets a blocked session through as verified, and a rejection keeps the
+// session rejected however Didit and the screening see it later.
+func ApplyOverride(status string, override *string) string {
+	if override == nil {
+		return status
+	}
+	switch {
+	case *override == "approved" && status == "blocked":
+		return "verified"
+	case *override == "rejected" && (status == "verified" || status == "blocked"):
+		return "rejected"
+	}
+	return status
+}
+
+// HTTPSanctions is a SanctionsChecker backed by an HTTP screening endpoint: the Subject is POSTed as
+// JSON and the provider answers {"match": bool, "reference": "..."}.
+type HTTPSanctions struct {
+	URL    string
+	APIKey string // sent as a bearer token when set
+	HTTP   *http.Client
+}
+
+func NewHTTPSanctions(url string, apiKey string) *HTTPSanctions {
+	return &HTTPSanctions{URL: url, APIKey: apiKey, HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (h *HTTPSanctions) Check(ctx context.Context, subject Subject) (bool, string, error) {
+	b, _ := json.Marshal(subject)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(b))
+	if err != nil {
+		return false, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	resp, err := h.HTTP.Do(req)
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, "", fmt.Errorf("sanctions check failed: status %d", resp.StatusCode)
+	}
+	var out struct {
+		Match     bool   `json:"match"`
+		Reference string `json:"reference"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return false, "", fmt.Errorf("sanctions check: invalid response: %w", err)
+	}
+	return out.Match, out.Reference, nil
+}
@@ -0,0 +1,96 @@
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type fakeSanctions struct {
+	match bool
+	err   error
+	asked []Subject
+}
+
+func (f *fakeSanctions) Check(_ context.Context, s Subject) (bool, string, error) {
+	f.asked = append(f.asked, s)
+	return f.match, "ref-1", f.err
+}
+
+func decisionDoc(nationality, issuingState string) map[string]interface{} {
+	return map[string]interface{}{
+		"id_verification": map[string]interface{}{
+			"full_name":     "Jane Doe",
+			"nationality":   nationality,
+			"issuing_state": issuingState,
+		},
+	}
+}
+
+func TestScreenerApply(t *testing.T) {
+	cases := []struct {
+		name       string
+		sanctions  *fakeSanctions
+		status     string
+		doc        map[string]interface{}
+		wantStatus string
+		wantReason string
+	}{
+		{"clear", nil, "verified", decisionDoc("ESP", "ESP"), "verified", ""},
+		{"blocked nationality", nil, "verified", decisionDoc("prk", "ESP"), "blocked", ScreenBlockedCountry},
+		{"blocked document country", nil, "verified", decisionDoc("ESP", "IRN"), "blocked", ScreenBlockedCountry},
+		{"no country", nil, "verified", nil, "blocked", ScreenUnavailable},
+		{"not verified", nil, "rejected", decisionDoc("PRK", "PRK"), "rejected", ""},
+		{"sanctions match", &fakeSanctions{match: true}, "verified", decisionDoc("ESP", "ESP"), "blocked", ScreenSanctionsMatch},
+		{"sanctions down", &fakeSanctions{err: errors.New("timeout")}, "verified", decisionDoc("ESP", "ESP"), "blocked", ScreenUnavailable},
+		{"sanctions clear", &fakeSanctions{}, "verified", decisionDoc("ESP", "ESP"), "verified", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var checker SanctionsChecker
+			if tc.sanctions != nil {
+				checker = tc.sanctions
+			}
+			status, raw := NewScreener([]string{"PRK", " irn"}, checker).Apply(context.Background(), tc.status, tc.doc)
+			if status != tc.wantStatus {
+				t.Fatalf("status = %q, want %q", status, tc.wantStatus)
+			}
+			var sc Screening
+			_ = json.Unmarshal(raw, &sc)
+			if sc.Reason != tc.wantReason {
+				t.Errorf("reason = %q, want %q", sc.Reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestScreenerDisabled(t *testing.T) {
+	var nilScreener *Screener
+	for _, s := range []*Screener{nilScreener, NewScreener(nil, nil)} {
+		if status, raw := s.Apply(context.Background(), "verified", nil); status != "verified" || raw != nil {
+			t.Errorf("Apply = %q, %s; want verified, nil", status, raw)
+		}
+	}
+}
+
+func TestApplyOverride(t *testing.T) {
+	approved, rejected := "approved", "rejected"
+	cases := []struct {
+		status   string
+		override *string
+		want     string
+	}{
+		{"blocked", nil, "blocked"},
+		{"blocked", &approved, "verified"},
+		{"rejected", &approved, "rejected"},
+		{"verified", &rejected, "rejected"},
+		{"blocked", &rejected, "rejected"},
+		{"pending", &rejected, "pending"},
+	}
+	for _, tc := range cases {
+		if got := ApplyOverride(tc.status, tc.override); got != tc.want {
+			t.Errorf("ApplyOverride(%q, %v) = %q, want %q", tc.status, tc.override, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/didit"
+)
+
+// DecisionSource fetches session decisions from Didit; *didit.Client implements it.
+type DecisionSource interface {
+	GetSessionDecision(ctx context.Context, sessionID string) (didit.SessionDecisionResponse, error)
+}
+
+// decisionTimeout caps the Didit lookup (retries included) on the status request path; past it the
+// stored state is served.
+const decisionTimeout = 10 * time.Second
+
+// Service keeps users' KYC state in step with Didit.
+type Service struct {
+	store    Store
+	didit    DecisionSource // nil when Didit is not configured
+	screener *Screener      // nil when verified sessions are not screened
+}
+
+// NewService returns a Service backed by Postgres. client may be nil, in which case stored state is
+// reported as is; screener may be nil.
+func NewService(pool *pgxpool.Pool, keyB64 string, client *didit.Client, screener *Screener) *Service {
+	s := &Service{store: pgStore{pool: pool, keyB64: keyB64}, screener: screener}
+	if client != nil {
+		s.didit = client
+	}
+	return s
+}
+
+// StatusResult is a user's KYC state as reported by the status endpoint.
+type StatusResult struct {
+	Status     *string
+	SessionID  *string
+	VerifiedAt *time.Time
+	// Data is the stored Didit decision document (nil when there is none).
+	Data map[string]interface{}
+	// Extracted holds the structured fields from Data (see ExtractInfo) and, for rejected users, the
+	// rejection_reasons.
+	Extracted map[string]interface{}
+	// RejectionReason is set for rejected users: the Didit warnings joined, or a generic message.
+	RejectionReason *string
+	// BlockedReason is set for blocked users: why the screening failed (see ScreenBlockedCountry).
+	BlockedReason *string
+}
+
+// RefreshStatus returns the user's KYC state after checking their open session with Didit: the
+// latest decision is stored (recording a status change), and a session Didit no longer knows is
+// marked expired. Didit errors other than a missing session leave the stored state in place.
+func (s *Service) RefreshStatus(ctx context.Context, userID uuid.UUID) (StatusResult, error) {
+	rec, err := s.store.Current(ctx, userID)
+	if err != nil {
+		return StatusResult{}, err
+	}
+
+	// If we have a session ID, always fetch latest status from Didit API
+	// This ensures we detect if the session was deleted in Didit dashboard
+	// and get accurate status updates (including not_started -> pending transitions)
+	if rec.SessionID != nil && *rec.SessionID != "" && s.didit != nil {
+		rec = s.refresh(ctx, userID, rec)
+	}
+
+	res := StatusResult{Status: rec.Status, SessionID: rec.SessionID, VerifiedAt: rec.VerifiedAt}
+	if res.Status != nil && *res.Status == "blocked" {
+		var sc Screening
+		_ = json.Unmarshal(rec.Screening, &sc)
+		reason := sc.Reason
+		if reason == "" {
+			reason = ScreenUnavailable
+		}
+		res.BlockedReason = &reason
+	}
+	if len(rec.Data) > 0 {
+		_ = json.Unmarshal(rec.Data, &res.Data)
+	}
+	if res.Data == nil {
+		return res, nil
+	}
+
+	// Get extracted info if it exists, otherwise extract it now and store it
+	if extracted, ok := res.Data["extracted"].(map[string]interface{}); ok {
+		res.Extracted = extracted
+	} else if extracted := ExtractInfo(res.Data); len(extracted) > 0 {
+		res.Extracted = extracted
+		merged := make(map[string]interface{}, len(res.Data)+1)
+		for k, v := range res.Data {
+			merged[k] = v
+		}
+		merged["extracted"] = extracted
+		b, _ := json.Marshal(merged)
+		if err := s.store.SaveCurrentData(ctx, userID, b); err != nil {
+			slog.Warn("failed to store extracted kyc info", "user_id", userID, "error", err)
+		}
+	}
+
+	if res.Status != nil && *res.Status == "rejected" {
+		reason := "Verification declined"
+		if reasons := rejectionReasons(res.Data); len(reasons) > 0 {
+			reason = strings.Join(reasons, "; ")
+			if res.Extracted == nil {
+				res.Extracted = make(map[string]interface{})
+			}
+			res.Extracted["rejection_reasons"] = reasons
+		}
+		res.RejectionReason = &reason
+	}
+	return res, nil
+}
+
+// refresh applies Didit's view of rec's session and returns the resulting state.
+func (s *Service) refresh(ctx context.Context, userID uuid.UUID, rec Record) Record {
+	sessionID := *rec.SessionID
+	current := "nil"
+	if rec.Status != nil {
+		current = *rec.Status
+	}
+
+	fetchedAt := time.Now()
+	dctx, cancel := context.WithTimeout(ctx, decisionTimeout)
+	decision, err := s.didit.GetSessionDecision(dctx, sessionID)
+	cancel()
+	if err != nil {
+		if errors.Is(err, didit.ErrUnavailable) {
+			slog.Warn("didit unavailable, serving stored kyc status", "session_id", sessionID, "current_status", current)
+			return rec
+		}
+		if !SessionDeleted(err) {
+			// For other errors (network, timeout, etc.), log but keep existing status
+			slog.Warn("didit api error but session may still exist",
+				"session_id", sessionID,
+				"error", err.Error(),
+				"current_status", current)
+			return rec
+		}
+		// Session was deleted in Didit dashboard - mark as expired
+		if err := s.store.MarkExpired(ctx, userID, sessionID); err != nil {
+			slog.Error("failed to mark session as expired in database",
+				"error", err,
+				"user_id", userID,
+				"session_id", sessionID)
+			return rec
+		}
+		slog.Info("marked session as expired - deleted in didit dashboard",
+			"session_id", sessionID,
+			"user_id", userID,
+			"previous_status", current)
+		expired := "expired"
+		rec.Status = &expired
+		rec.SessionID = nil
+		return rec
+	}
+
+	mapped := MapDiditStatus(decision.Status)
+	slog.Info("fetched didit status",
+		"session_id", sessionID,
+		"didit_status", decision.Status,
+		"mapped_status", mapped,
+		"current_db_status", current)
+
+	// Store Decision, Data, and any extra fields (like session_url) from Didit response
+	combined := DecisionDocument(decision)
+	newStatus, screening := s.screener.Apply(ctx, mapped, combined)
+	newStatus = ApplyOverride(newStatus, rec.Override)
+	data, _ := json.Marshal(combined)
+
+	// A rejected or blocked session is rewritten even when unchanged, so its latest reasons are kept.
+	statusChanged := rec.Status == nil || *rec.Status != newStatus
+	if !statusChanged && *rec.Status != "rejected" && *rec.Status != "blocked" {
+		if err := s.store.SaveData(ctx, sessionID, data, fetchedAt); err != nil {
+			slog.Warn("failed to update kyc data", "user_id", userID, "error", err)
+		}
+		rec.Data = data
+		return rec
+	}
+	saved, err := s.store.SaveDecision(ctx, userID, Decision{
+		SessionID: sessionID,
+		Status:    newStatus,
+		Data:      data,
+		Screening: screening,
+		DecidedAt: fetchedAt,
+	})
+	if err != nil {
+		slog.Error("failed to update kyc status", "error", err, "user_id", userID, "old_status", current, "new_status", newStatus)
+		return rec
+	}
+	if !saved {
+		// A webhook stored a newer decision while Didit was being asked; report that one.
+		slog.Info("kyc decision superseded by a newer one", "user_id", userID, "session_id", sessionID)
+		if latest, err := s.store.Current(ctx, userID); err == nil {
+			return latest
+		}
+		return rec
+	}
+	if statusChanged {
+		slog.Info("kyc status changed", "user_id", userID, "old_status", current, "new_status", newStatus, "didit_status", decision.Status)
+	}
+	rec.Status = &newStatus
+	rec.Data = data
+	if screening != nil {
+		rec.Screening = screening
+	}
+	return rec
+}
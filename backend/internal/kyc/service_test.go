@@ -0,0 +1,176 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/didit"
+)
+
+func TestMapDiditStatus(t *testing.T) {
+	cases := map[string]string{
+		"Approved":    "verified",
+		"Declined":    "rejected",
+		"In Review":   "in_review",
+		"in_progress": "pending",
+		"Not Started": "not_started",
+		" expired ":   "expired",
+		"":            "not_started",
+	}
+	for in, want := range cases {
+		if got := MapDiditStatus(in); got != want {
+			t.Errorf("MapDiditStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSessionDeleted(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("didit get decision failed: status 404, error: Not Found, body: {}"), true},
+		{errors.New("session does not exist"), true},
+		{errors.New("http request: dial tcp: i/o timeout"), false},
+		{errors.New("didit get decision failed: status 500, error: , body: "), false},
+	}
+	for _, tc := range cases {
+		if got := SessionDeleted(tc.err); got != tc.want {
+			t.Errorf("SessionDeleted(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+type fakeStore struct {
+	rec      Record
+	expired  []string
+	saved    []string // statuses passed to SaveDecision
+	dataOnly int
+}
+
+func (f *fakeStore) Current(context.Context, uuid.UUID) (Record, error) { return f.rec, nil }
+func (f *fakeStore) MarkExpired(_ context.Context, _ uuid.UUID, sessionID string) error {
+	f.expired = append(f.expired, sessionID)
+	return nil
+}
+func (f *fakeStore) SaveDecision(_ context.Context, _ uuid.UUID, d Decision) (bool, error) {
+	f.saved = append(f.saved, d.Status)
+	return true, nil
+}
+func (f *fakeStore) SaveData(context.Context, string, []byte, time.Time) error {
+	f.dataOnly++
+	return nil
+}
+func (f *fakeStore) SaveCurrentData(context.Context, uuid.UUID, []byte) error { return nil }
+
+type fakeDidit struct {
+	resp didit.SessionDecisionResponse
+	err  error
+}
+
+func (f fakeDidit) GetSessionDecision(context.Context, string) (didit.SessionDecisionResponse, error) {
+	return f.resp, f.err
+}
+
+func strp(s string) *string { return &s }
+
+func TestRefreshStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		stored      string
+		didit       fakeDidit
+		wantStatus  string
+		wantSession bool
+		wantExpired bool
+		wantSaved   []string
+		wantReason  bool
+	}{
+		{
+			name:        "session deleted at didit",
+			stored:      "pending",
+			didit:       fakeDidit{err: errors.New("didit get decision failed: status 404, error: Not Found, body: ")},
+			wantStatus:  "expired",
+			wantExpired: true,
+		},
+		{
+			name:        "transient didit error keeps stored state",
+			stored:      "pending",
+			didit:       fakeDidit{err: errors.New("http request: context deadline exceeded")},
+			wantStatus:  "pending",
+			wantSession: true,
+		},
+		{
+			name:        "approved decision",
+			stored:      "in_review",
+			didit:       fakeDidit{resp: didit.SessionDecisionResponse{Status: "Approved"}},
+			wantStatus:  "verified",
+			wantSession: true,
+			wantSaved:   []string{"verified"},
+		},
+		{
+			name:        "unchanged status only refreshes data",
+			stored:      "pending",
+			didit:       fakeDidit{resp: didit.SessionDecisionResponse{Status: "Pending"}},
+			wantStatus:  "pending",
+			wantSession: true,
+		},
+		{
+			name:   "rejected decision is rewritten and explained",
+			stored: "rejected",
+			didit: fakeDidit{resp: didit.SessionDecisionResponse{
+				Status: "Declined",
+				ExtraFields: map[string]interface{}{
+					"face_match": map[string]interface{}{
+						"warnings": []interface{}{map[string]interface{}{"short_description": "Face mismatch"}},
+					},
+				},
+			}},
+			wantStatus:  "rejected",
+			wantSession: true,
+			wantSaved:   []string{"rejected"},
+			wantReason:  true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeStore{rec: Record{Status: strp(tc.stored), SessionID: strp("sess-1")}}
+			svc := &Service{store: store, didit: tc.didit}
+
+			res, err := svc.RefreshStatus(context.Background(), uuid.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status == nil || *res.Status != tc.wantStatus {
+				t.Fatalf("status = %v, want %q", res.Status, tc.wantStatus)
+			}
+			if (res.SessionID != nil) != tc.wantSession {
+				t.Errorf("session_id = %v, want present %v", res.SessionID, tc.wantSession)
+			}
+			if (len(store.expired) > 0) != tc.wantExpired {
+				t.Errorf("expired = %v, want %v", store.expired, tc.wantExpired)
+			}
+			if len(store.saved) != len(tc.wantSaved) || (len(tc.wantSaved) > 0 && store.saved[0] != tc.wantSaved[0]) {
+				t.Errorf("saved = %v, want %v", store.saved, tc.wantSaved)
+			}
+			if (res.RejectionReason != nil) != tc.wantReason {
+				t.Errorf("rejection_reason = %v, want present %v", res.RejectionReason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestRefreshStatusWithoutDidit(t *testing.T) {
+	store := &fakeStore{rec: Record{Status: strp("pending"), SessionID: strp("sess-1")}}
+	res, err := (&Service{store: store}).RefreshStatus(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res.Status != "pending" || len(store.saved)+len(store.expired)+store.dataOnly != 0 {
+		t.Fatalf("stored state should be reported untouched: %+v, %+v", res, store)
+	}
+}
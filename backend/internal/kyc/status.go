@@ -0,0 +1,152 @@
+package kyc
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/didit"
+)
+
+// MapDiditStatus maps Didit status to our internal KYC status
+// Production-ready mapping that preserves accurate status representation
+// Status flow: not_started -> pending -> in_review -> verified/rejected/expired
+func MapDiditStatus(diditStatus string) string {
+	status := strings.ToLower(strings.TrimSpace(diditStatus))
+	switch status {
+	case "approved", "verified":
+		return "verified"
+	case "rejected", "declined":
+		return "rejected"
+	case "in review", "inreview":
+		// Didit is actively reviewing the verification
+		return "in_review"
+	case "pending", "in_progress", "inprogress":
+		// User has started verification process (clicked the link, submitted documents, etc.)
+		// but Didit hasn't started reviewing yet
+		return "pending"
+	case "expired":
+		return "expired"
+	case "not started", "notstarted", "not_started":
+		// Session exists but user hasn't clicked the verification link yet
+		// This is distinct from "pending" - user hasn't begun verification
+		return "not_started"
+	default:
+		// Unknown status - log as error for production monitoring
+		slog.Error("unknown didit status - defaulting to not_started", "status", diditStatus, "original", diditStatus)
+		return "not_started"
+	}
+}
+
+// ExtractInfo extracts structured information from Didit response data
+func ExtractInfo(data map[string]interface{}) map[string]interface{} {
+	extracted := make(map[string]interface{})
+
+	// Extract personal information from id_verification
+	if idVerification, ok := data["id_verification"].(map[string]interface{}); ok {
+		if firstName, ok := idVerification["first_name"].(string); ok && firstName != "" {
+			extracted["first_name"] = firstName
+		}
+		if lastName, ok := idVerification["last_name"].(string); ok && lastName != "" {
+			extracted["last_name"] = lastName
+		}
+		if fullName, ok := idVerification["full_name"].(string); ok && fullName != "" {
+			extracted["full_name"] = fullName
+		}
+		if address, ok := idVerification["address"].(string); ok && address != "" {
+			extracted["address"] = address
+		}
+		if dob, ok := idVerification["date_of_birth"].(string); ok && dob != "" {
+			extracted["date_of_birth"] = dob
+		}
+		if age, ok := idVerification["age"].(float64); ok {
+			extracted["age"] = int(age)
+		}
+		if documentType, ok := idVerification["document_type"].(string); ok && documentType != "" {
+			extracted["document_type"] = documentType
+		}
+		if documentNumber, ok := idVerification["document_number"].(string); ok && documentNumber != "" {
+			extracted["document_number"] = documentNumber
+		}
+		if nationality, ok := idVerification["nationality"].(string); ok && nationality != "" {
+			extracted["nationality"] = nationality
+		}
+		if issuingState, ok := idVerification["issuing_state"].(string); ok && issuingState != "" {
+			extracted["issuing_state"] = issuingState
+		}
+		if status, ok := idVerification["status"].(string); ok && status != "" {
+			extracted["id_verification_status"] = status
+		}
+	}
+
+	// Extract face match information
+	if faceMatch, ok := data["face_match"].(map[string]interface{}); ok {
+		if score, ok := faceMatch["score"].(float64); ok {
+			extracted["face_match_score"] = score
+		}
+		if status, ok := faceMatch["status"].(string); ok && status != "" {
+			extracted["face_match_status"] = status
+		}
+	}
+
+	return extracted
+}
+
+// DecisionDocument is what is stored for a Didit decision: its decision and data, any extra fields
+// (like session_url), and the extracted info.
+func DecisionDocument(decision didit.SessionDecisionResponse) map[string]interface{} {
+	doc := map[string]interface{}{
+		"decision": decision.Decision,
+		"data":     decision.Data,
+	}
+	for k, v := range decision.ExtraFields {
+		doc[k] = v
+	}
+	if extracted := ExtractInfo(doc); len(extracted) > 0 {
+		doc["extracted"] = extracted
+	}
+	return doc
+}
+
+// SessionDeleted reports whether a Didit API error means the session no longer exists (e.g. it was
+// deleted in the Didit dashboard), as opposed to a transient failure. The Didit client reports
+// errors as "didit get decision failed: status 404, error: ..., body: ...".
+func SessionDeleted(err error) bool {
+	if err == nil || errors.Is(err, didit.ErrUnavailable) {
+		return false
+	}
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "status 404") ||
+		strings.Contains(errMsg, "status: 404") ||
+		strings.Contains(errMsg, "404") ||
+		strings.Contains(errMsg, "not found") ||
+		strings.Contains(errMsg, "not_found") ||
+		strings.Contains(errMsg, "invalid") ||
+		strings.Contains(errMsg, "deleted") ||
+		strings.Contains(errMsg, "does not exist") ||
+		strings.Contains(errMsg, "doesn't exist") ||
+		strings.Contains(errMsg, "no such") ||
+		strings.Contains(errMsg, "not available")
+}
+
+// rejectionReasons collects the warning descriptions of the checked Didit features, face match first.
+func rejectionReasons(data map[string]interface{}) []string {
+	var reasons []string
+	for _, featureName := range []string{"face_match", "id_verification", "liveness", "ip_analysis"} {
+		feature, ok := data[featureName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		warnings, _ := feature["warnings"].([]interface{})
+		for _, warning := range warnings {
+			if w, ok := warning.(map[string]interface{}); ok {
+				if longDesc, ok := w["long_description"].(string); ok && longDesc != "" {
+					reasons = append(reasons, longDesc)
+				} else if shortDesc, ok := w["short_description"].(string); ok && shortDesc != "" {
+					reasons = append(reasons, shortDesc)
+				}
+			}
+		}
+	}
+	return reasons
+}
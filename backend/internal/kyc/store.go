@@ -0,0 +1,211 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// Record is a user's current KYC state, with data decrypted.
+type Record struct {
+	Status     *string
+	SessionID  *string // nil once the session expired
+	VerifiedAt *time.Time
+	Data       []byte
+	Screening  []byte  // latest screening result (JSON Screening), nil when never screened
+	Override   *string // admin screening override: "approved" or "rejected"
+}
+
+// Store persists KYC sessions for Service.
+type Store interface {
+	// Current returns the user's current KYC state.
+	Current(ctx context.Context, userID uuid.UUID) (Record, error)
+	// MarkExpired records that the session no longer exists at Didit.
+	MarkExpired(ctx context.Context, userID uuid.UUID, sessionID string) error
+	// SaveDecision stores Didit's latest decision for the session, unless the session already holds
+	// a newer one; it reports whether d was stored.
+	SaveDecision(ctx context.Context, userID uuid.UUID, d Decision) (bool, error)
+	// SaveData replaces the session's data without touching its status; decidedAt is when Didit's
+	// unchanged decision was fetched.
+	SaveData(ctx context.Context, sessionID string, data []byte, decidedAt time.Time) error
+	// SaveCurrentData replaces the data of the user's current session.
+	SaveCurrentData(ctx context.Context, userID uuid.UUID, data []byte) error
+}
+
+// UpdateStatus runs update, a kyc_sessions write that may change userID's current KYC status, and
+// records the transition in kyc_events when the status actually changed. payload (raw JSON, may be
+// nil) is the webhook body or Didit decision behind the change; it holds personal data, so it is
+// stored encrypted with keyB64 when a key is configured.
+func UpdateStatus(ctx context.Context, pool *pgxpool.Pool, keyB64 string, userID uuid.UUID, source string, payload []byte, update string, args ...any) (pgconn.CommandTag, error) {
+	var payloadEnc []byte
+	if len(payload) > 0 && keyB64 != "" {
+		enc, err := SealAll(payload, keyB64)
+		if err != nil {
+			return pgconn.CommandTag{}, err
+		}
+		payload, payloadEnc = nil, enc
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The user row lock serializes concurrent changes (webhook vs. status poll) to the same user.
+	var oldStatus, oldSession *string
+	if err := tx.QueryRow(ctx, `
+SELECT k.status, k.session_id
+FROM users u
+LEFT JOIN user_kyc k ON k.user_id = u.id
+WHERE u.id = $1
+FOR UPDATE OF u
+`, userID).Scan(&oldStatus, &oldSession); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	ct, err := tx.Exec(ctx, update, args...)
+	if err != nil {
+		return ct, err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO kyc_events (user_id, old_status, new_status, source, session_id, payload, payload_enc)
+SELECT user_id, $2, status, $3, COALESCE(session_id, $4), $5, $6
+FROM user_kyc
+WHERE user_id = $1 AND status IS DISTINCT FROM $2
+`, userID, oldStatus, source, oldSession, payload, payloadEnc)
+	if err != nil {
+		return ct, err
+	}
+	var newStatus *string
+	err = tx.QueryRow(ctx, `SELECT status FROM user_kyc WHERE user_id = $1`, userID).Scan(&newStatus)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ct, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ct, err
+	}
+	// RequireKYC sees the new status on the user's next request without loading it.
+	status := ""
+	if newStatus != nil {
+		status = *newStatus
+	}
+	auth.CacheKYCStatus(userID, status)
+	return ct, nil
+}
+
+// Decision is a Didit decision to store for a session.
+type Decision struct {
+	SessionID string
+	Status    string // after screening and any admin override
+	Data      []byte // the decision document (see DecisionDocument)
+	Screening []byte // nil when the session was not screened; the previous screening is kept
+	// DecidedAt is when Didit's decision was current: the time it was fetched from the Didit API, or
+	// the webhook's event time when only the delivery's status is known.
+	DecidedAt time.Time
+}
+
+// SaveDecision stores d unless the session already holds a decision newer than d.DecidedAt, which
+// a late webhook or a slow status poll must not overwrite; it reports whether d was stored. A status
+// change is recorded as coming from source with payload (see UpdateStatus).
+func SaveDecision(ctx context.Context, pool *pgxpool.Pool, keyB64 string, userID uuid.UUID, source string, payload []byte, d Decision) (bool, error) {
+	plain, enc, err := Seal(d.Data, keyB64)
+	if err != nil {
+		return false, err
+	}
+	ct, err := UpdateStatus(ctx, pool, keyB64, userID, source, payload, `
+UPDATE kyc_sessions
+SET status = $1,
+    data = $2,
+    pii_enc = $4,
+    screening = COALESCE($5, screening),
+    decided_at = $6,
+    verified_at = CASE WHEN $1 = 'verified' THEN now() ELSE verified_at END,
+    updated_at = now()
+WHERE session_id = $3
+  AND (decided_at IS NULL OR decided_at <= $6)
+`, d.Status, plain, d.SessionID, enc, d.Screening, d.DecidedAt)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// pgStore is the Postgres Store: kyc_sessions through the user_kyc view, personal data sealed with key.
+type pgStore struct {
+	pool   *pgxpool.Pool
+	keyB64 string
+}
+
+func (s pgStore) Current(ctx context.Context, userID uuid.UUID) (Record, error) {
+	var r Record
+	var pii []byte
+	// An expired session is kept as history but no longer reported (or polled) as the user's session.
+	err := s.pool.QueryRow(ctx, `
+SELECT k.status, CASE WHEN k.status <> 'expired' THEN k.session_id END, k.verified_at, k.data, k.pii_enc,
+       k.screening, k.screening_override
+FROM users u
+LEFT JOIN user_kyc k ON k.user_id = u.id
+WHERE u.id = $1
+`, userID).Scan(&r.Status, &r.SessionID, &r.VerifiedAt, &r.Data, &pii, &r.Screening, &r.Override)
+	if err != nil {
+		return Record{}, err
+	}
+	if opened, err := Open(r.Data, pii, s.keyB64); err != nil {
+		slog.Error("failed to decrypt kyc data", "user_id", userID, "error", err)
+	} else {
+		r.Data = opened
+	}
+	return r, nil
+}
+
+func (s pgStore) MarkExpired(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	_, err := UpdateStatus(ctx, s.pool, s.keyB64, userID, "status_poll", nil, `
+UPDATE kyc_sessions
+SET status = 'expired',
+    updated_at = now()
+WHERE session_id = $1
+`, sessionID)
+	return err
+}
+
+func (s pgStore) SaveDecision(ctx context.Context, userID uuid.UUID, d Decision) (bool, error) {
+	return SaveDecision(ctx, s.pool, s.keyB64, userID, "status_poll", d.Data, d)
+}
+
+func (s pgStore) SaveData(ctx context.Context, sessionID string, data []byte, decidedAt time.Time) error {
+	plain, enc, err := Seal(data, s.keyB64)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `
+UPDATE kyc_sessions
+SET data = $1,
+    pii_enc = $3,
+    decided_at = GREATEST(decided_at, $4),
+    updated_at = now()
+WHERE session_id = $2
+`, plain, sessionID, enc, decidedAt)
+	return err
+}
+
+func (s pgStore) SaveCurrentData(ctx context.Context, userID uuid.UUID, data []byte) error {
+	plain, enc, err := Seal(data, s.keyB64)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `
+UPDATE kyc_sessions
+SET data = $1,
+    pii_enc = $3,
+    updated_at = now()
+WHERE id = (SELECT id FROM user_kyc WHERE user_id = $2)
+`, plain, userID, enc)
+	return err
+}
@@ -0,0 +1,131 @@
+package kyc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Outcomes of processing an archived Didit delivery (didit_webhook_events.outcome).
+const (
+	WebhookReceived        = "received" // archived, not processed (yet)
+	WebhookApplied         = "applied"
+	WebhookStale           = "stale" // the session already held a newer decision
+	WebhookDuplicate       = "duplicate"
+	WebhookUnconfirmed     = "unconfirmed" // a callback the Didit API could not confirm
+	WebhookSessionNotFound = "session_not_found"
+	WebhookFailed          = "failed"
+)
+
+// WebhookDelivery is an archived Didit webhook delivery or callback redirect.
+type WebhookDelivery struct {
+	ID        uuid.UUID
+	Kind      string // "webhook" (signed POST) or "callback" (browser redirect)
+	EventID   string // webhooks only
+	SessionID string
+	EventAt   *time.Time
+	Payload   []byte // raw body, or {"query": ...} for callbacks; decrypted
+	Outcome   string
+	KYCStatus *string
+	Error     *string
+	Attempts  int
+	// ReceivedAt and ProcessedAt are set when loaded.
+	ReceivedAt  time.Time
+	ProcessedAt *time.Time
+}
+
+// WebhookEventID identifies a Didit delivery: the event id Didit sent, or a hash of the raw body,
+// which a redelivery repeats byte for byte.
+func WebhookEventID(eventID string, body []byte) string {
+	if eventID != "" {
+		return eventID
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ArchiveWebhook stores d as received, before it is processed, and returns its id. The payload may
+// hold personal data, so it is stored encrypted with keyB64 when a key is configured.
+func ArchiveWebhook(ctx context.Context, pool *pgxpool.Pool, keyB64 string, d WebhookDelivery) (uuid.UUID, error) {
+	payload, payloadEnc := d.Payload, []byte(nil)
+	if len(payload) > 0 && keyB64 != "" {
+		enc, err := SealAll(payload, keyB64)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		payload, payloadEnc = nil, enc
+	}
+	var eventID, sessionID *string
+	if d.EventID != "" {
+		eventID = &d.EventID
+	}
+	if d.SessionID != "" {
+		sessionID = &d.SessionID
+	}
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+INSERT INTO didit_webhook_events (kind, event_id, session_id, event_at, payload, payload_enc)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`, d.Kind, eventID, sessionID, d.EventAt, payload, payloadEnc).Scan(&id)
+	return id, err
+}
+
+// LoadWebhook returns the archived delivery id with its payload decrypted.
+func LoadWebhook(ctx context.Context, pool *pgxpool.Pool, keyB64 string, id uuid.UUID) (WebhookDelivery, error) {
+	d := WebhookDelivery{ID: id}
+	var eventID, sessionID *string
+	var payloadEnc []byte
+	err := pool.QueryRow(ctx, `
+SELECT kind, event_id, session_id, event_at, payload, payload_enc, outcome, kyc_status, error, attempts, received_at, processed_at
+FROM didit_webhook_events
+WHERE id = $1
+`, id).Scan(&d.Kind, &eventID, &sessionID, &d.EventAt, &d.Payload, &payloadEnc, &d.Outcome, &d.KYCStatus, &d.Error, &d.Attempts, &d.ReceivedAt, &d.ProcessedAt)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	if eventID != nil {
+		d.EventID = *eventID
+	}
+	if sessionID != nil {
+		d.SessionID = *sessionID
+	}
+	if payloadEnc != nil {
+		if d.Payload, err = OpenAll(payloadEnc, keyB64); err != nil {
+			return WebhookDelivery{}, err
+		}
+	}
+	return d, nil
+}
+
+// WebhookEventProcessed reports whether another delivery of eventID than id was already applied
+// (or found stale).
+func WebhookEventProcessed(ctx context.Context, pool *pgxpool.Pool, eventID string, id uuid.UUID) (bool, error) {
+	var seen bool
+	err := pool.QueryRow(ctx, `
+SELECT EXISTS(
+  SELECT 1 FROM didit_webhook_events
+  WHERE event_id = $1 AND id <> $2 AND outcome IN ('applied', 'stale')
+)
+`, eventID, id).Scan(&seen)
+	return seen, err
+}
+
+// SetWebhookOutcome records the result of processing the archived delivery id. kycStatus and errMsg
+// may be empty.
+func SetWebhookOutcome(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, outcome string, kycStatus string, errMsg string) error {
+	_, err := pool.Exec(ctx, `
+UPDATE didit_webhook_events
+SET outcome = $2,
+    kyc_status = NULLIF($3, ''),
+    error = NULLIF($4, ''),
+    attempts = attempts + 1,
+    processed_at = now()
+WHERE id = $1
+`, id, outcome, kycStatus, errMsg)
+	return err
+}
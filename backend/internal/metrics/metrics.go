@@ -0,0 +1,134 @@
+// Package metrics is a minimal Prometheus registry: labelled histograms kept in process and written
+// in the text exposition format, without pulling in the full client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets suit durations in seconds, from sub-second API calls to hour-long syncs.
+var DefBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900, 3600}
+
+// HistogramVec is a histogram partitioned by the value of a single label.
+type HistogramVec struct {
+	name    string
+	help    string
+	label   string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []*HistogramVec
+)
+
+// NewHistogramVec creates and registers a histogram. buckets must be sorted ascending.
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{name: name, help: help, label: label, buckets: buckets, series: map[string]*histogram{}}
+	registryMu.Lock()
+	registry = append(registry, h)
+	registryMu.Unlock()
+	return h
+}
+
+// Observe records v for the series with the given label value.
+func (h *HistogramVec) Observe(labelValue string, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[labelValue]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[labelValue] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	values := make([]string, 0, len(h.series))
+	for v := range h.series {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	for _, v := range values {
+		s := h.series[v]
+		lv := Label(h.label, v)
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", h.name, lv, formatFloat(b), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", h.name, lv, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", h.name, lv, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", h.name, lv, s.count)
+	}
+}
+
+// WriteRegistered writes every registered histogram.
+func WriteRegistered(w io.Writer) {
+	registryMu.Lock()
+	hs := append([]*HistogramVec(nil), registry...)
+	registryMu.Unlock()
+	for _, h := range hs {
+		h.write(w)
+	}
+}
+
+// WriteGauge writes a gauge family. Keys of values are pre-rendered label sets (see Label).
+func WriteGauge(w io.Writer, name, help string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "" {
+			fmt.Fprintf(w, "%s %s\n", name, formatFloat(values[k]))
+		} else {
+			fmt.Fprintf(w, "%s{%s} %s\n", name, k, formatFloat(values[k]))
+		}
+	}
+}
+
+// Label renders name="value" pairs (alternating arguments) with the value escaped.
+func Label(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
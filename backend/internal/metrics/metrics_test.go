@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHistogramVecWrite(t *testing.T) {
+	h := &HistogramVec{name: "job_seconds", help: "Job runtime.", label: "job_type", buckets: []float64{1, 10}, series: map[string]*histogram{}}
+	h.Observe("sync_issues", 0.5)
+	h.Observe("sync_issues", 5)
+	h.Observe("sync_issues", 50)
+
+	var b strings.Builder
+	h.write(&b)
+	want := `# HELP job_seconds Job runtime.
+# TYPE job_seconds histogram
+job_seconds_bucket{job_type="sync_issues",le="1"} 1
+job_seconds_bucket{job_type="sync_issues",le="10"} 2
+job_seconds_bucket{job_type="sync_issues",le="+Inf"} 3
+job_seconds_sum{job_type="sync_issues"} 55.5
+job_seconds_count{job_type="sync_issues"} 3
+`
+	if b.String() != want {
+		t.Fatalf("unexpected exposition:\n%s", b.String())
+	}
+}
+
+func TestLabelEscapes(t *testing.T) {
+	if got := Label("a", `x"y`, "b", `c\d`); got != `a="x\"y",b="c\\d"` {
+		t.Fatalf("Label = %s", got)
+	}
+}
@@ -0,0 +1,49 @@
+// Package pagination implements the opaque ?cursor= tokens used for keyset pagination of list
+// endpoints. Unlike offsets, a keyset page does not shift when rows are inserted ahead of it.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not produced by Encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position after the last row of a page: that row's sort timestamp and its id, which
+// breaks ties between rows sharing a timestamp. Lists using it order by (timestamp, id) DESC.
+type Cursor struct {
+	At time.Time `json:"t"`
+	ID string    `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a token from Encode.
+func Decode(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.At.IsZero() || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Next returns the token for the page after one of n rows fetched with limit+1, or nil when n shows
+// there is no further page.
+func Next(n, limit int, last Cursor) *string {
+	if n <= limit {
+		return nil
+	}
+	s := last.Encode()
+	return &s
+}
@@ -0,0 +1,35 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := Cursor{At: time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: "79caaf9a-f1e6-4da0-be79-52c5bee169e1"}
+	out, err := Decode(in.Encode())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !out.At.Equal(in.At) || out.ID != in.ID {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not base64!", "e30", Cursor{ID: "x"}.Encode()} {
+		if _, err := Decode(s); err != ErrInvalidCursor {
+			t.Errorf("Decode(%q) err = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	last := Cursor{At: time.Unix(1700000000, 0).UTC(), ID: "42"}
+	if got := Next(50, 50, last); got != nil {
+		t.Errorf("Next on a short page = %q, want nil", *got)
+	}
+	if got := Next(51, 50, last); got == nil || *got != last.Encode() {
+		t.Errorf("Next on a full page = %v, want %q", got, last.Encode())
+	}
+}
@@ -0,0 +1,161 @@
+// Package payoutaddress proves control of the Stellar accounts users register to be paid to. Each
+// address gets a challenge code; the user either signs the challenge message with the account key
+// or sends a transaction from the account to the verification account with the code as memo, which
+// cmd/indexer detects. The handlers and the deposit watcher share the rules here.
+package payoutaddress
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stellar/go/keypair"
+)
+
+// Address statuses and verification methods.
+const (
+	StatusPending   = "pending"
+	StatusVerified  = "verified"
+	MethodSignature = "signature"
+	MethodDeposit   = "deposit"
+)
+
+// ChallengeTTL is how long a challenge code stays valid; a deposit can take a while to arrange.
+const ChallengeTTL = 24 * time.Hour
+
+// codePrefix marks challenge codes; with 10 random characters a code fits a Stellar text memo.
+const codePrefix = "GL-"
+
+// NewCode returns a random challenge code.
+func NewCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return codePrefix + base32.StdEncoding.EncodeToString(b)[:10], nil
+}
+
+// Message is the text the account key signs to verify address with code.
+func Message(address, code string) string {
+	return fmt.Sprintf("Grainlify payout address verification\nAddress: %s\nCode: %s", address, code)
+}
+
+// sep53Prefix is prepended to messages signed per SEP-53, as wallets such as Freighter do.
+const sep53Prefix = "Stellar Signed Message:\n"
+
+// ErrBadSignature is returned by VerifySignature.
+var ErrBadSignature = errors.New("signature does not match the address")
+
+// VerifySignature checks an ed25519 signature (base64 or hex) of message by the account's key,
+// either over the message itself or over its SEP-53 hash.
+func VerifySignature(address, message, signature string) error {
+	kp, err := keypair.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+	signature = strings.TrimSpace(signature)
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		if sig, err = base64.StdEncoding.DecodeString(signature); err != nil {
+			return ErrBadSignature
+		}
+	}
+	if kp.Verify([]byte(message), sig) == nil {
+		return nil
+	}
+	hash := sha256.Sum256([]byte(sep53Prefix + message))
+	if kp.Verify(hash[:], sig) == nil {
+		return nil
+	}
+	return ErrBadSignature
+}
+
+// MarkVerified marks a pending address verified and makes it the user's primary address when they
+// have none. It reports false when the address was not pending.
+func MarkVerified(ctx context.Context, tx pgx.Tx, id uuid.UUID, method string, txHash *string, at time.Time) (bool, error) {
+	var userID uuid.UUID
+	err := tx.QueryRow(ctx, `
+UPDATE payout_addresses
+SET status = 'verified', verification_method = $2, verification_tx_hash = $3, verified_at = $4, updated_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING user_id
+`, id, method, txHash, at).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(ctx, `
+UPDATE payout_addresses SET is_primary = true, updated_at = now()
+WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM payout_addresses WHERE user_id = $2 AND is_primary)
+`, id, userID)
+	return err == nil, err
+}
+
+// Querier is a pool or transaction.
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ForLogin returns the verified payout address of the user with the GitHub login: their primary
+// one, else the oldest. It returns "" when they have none.
+func ForLogin(ctx context.Context, q Querier, login string) (string, error) {
+	var addr string
+	err := q.QueryRow(ctx, `
+SELECT pa.address
+FROM payout_addresses pa
+JOIN github_accounts ga ON ga.user_id = pa.user_id
+WHERE LOWER(ga.login) = LOWER($1) AND pa.status = 'verified'
+ORDER BY pa.is_primary DESC, pa.verified_at
+LIMIT 1
+`, login).Scan(&addr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return addr, err
+}
+
+// Errors returned by Destination.
+var (
+	ErrNoVerifiedAddress     = errors.New("claimant has no verified payout address")
+	ErrUnverifiedDestination = errors.New("destination is not a verified payout address of the claimant")
+)
+
+// Destination returns the account a payout to the user with the GitHub login goes to: requested
+// when it is one of their verified payout addresses, or their ForLogin address when requested is
+// empty. Money only ever goes to an address its recipient proved they control.
+func Destination(ctx context.Context, q Querier, login, requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		addr, err := ForLogin(ctx, q, login)
+		if err == nil && addr == "" {
+			err = ErrNoVerifiedAddress
+		}
+		return addr, err
+	}
+	var verified bool
+	if err := q.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1
+  FROM payout_addresses pa
+  JOIN github_accounts ga ON ga.user_id = pa.user_id
+  WHERE LOWER(ga.login) = LOWER($1) AND pa.address = $2 AND pa.status = 'verified'
+)
+`, login, requested).Scan(&verified); err != nil {
+		return "", err
+	}
+	if !verified {
+		return "", ErrUnverifiedDestination
+	}
+	return requested, nil
+}
@@ -0,0 +1,101 @@
+package payoutaddress
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stellar/go/keypair"
+)
+
+func TestVerifySignature(t *testing.T) {
+	kp := keypair.MustRandom()
+	code, err := NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(code, codePrefix) || len(code) > 28 {
+		t.Fatalf("code %q does not fit a text memo", code)
+	}
+	msg := Message(kp.Address(), code)
+
+	raw, _ := kp.Sign([]byte(msg))
+	if err := VerifySignature(kp.Address(), msg, hex.EncodeToString(raw)); err != nil {
+		t.Errorf("raw hex signature: %v", err)
+	}
+	hash := sha256.Sum256([]byte(sep53Prefix + msg))
+	sep53, _ := kp.Sign(hash[:])
+	if err := VerifySignature(kp.Address(), msg, base64.StdEncoding.EncodeToString(sep53)); err != nil {
+		t.Errorf("SEP-53 base64 signature: %v", err)
+	}
+
+	other := keypair.MustRandom()
+	if err := VerifySignature(other.Address(), msg, hex.EncodeToString(raw)); err == nil {
+		t.Error("signature accepted for another address")
+	}
+	if err := VerifySignature(kp.Address(), Message(kp.Address(), "GL-OTHERCODE"), hex.EncodeToString(raw)); err == nil {
+		t.Error("signature accepted for another code")
+	}
+}
+
+// verifiedAddresses answers the payout address queries from a login's verified addresses, primary
+// first.
+type verifiedAddresses map[string][]string
+
+func (v verifiedAddresses) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	addrs := v[strings.ToLower(args[0].(string))]
+	if strings.Contains(sql, "EXISTS") {
+		found := false
+		for _, a := range addrs {
+			found = found || a == args[1].(string)
+		}
+		return fakeRow{found}
+	}
+	if len(addrs) == 0 {
+		return fakeRow{nil}
+	}
+	return fakeRow{addrs[0]}
+}
+
+type fakeRow struct{ v any }
+
+func (r fakeRow) Scan(dest ...any) error {
+	switch v := r.v.(type) {
+	case nil:
+		return pgx.ErrNoRows
+	case bool:
+		*dest[0].(*bool) = v
+	case string:
+		*dest[0].(*string) = v
+	}
+	return nil
+}
+
+func TestDestination(t *testing.T) {
+	primary, other, stranger := keypair.MustRandom().Address(), keypair.MustRandom().Address(), keypair.MustRandom().Address()
+	q := verifiedAddresses{"alice": {primary, other}}
+	ctx := context.Background()
+
+	cases := []struct {
+		login, requested string
+		want             string
+		err              error
+	}{
+		{"Alice", "", primary, nil},
+		{"alice", other, other, nil},
+		{"alice", stranger, "", ErrUnverifiedDestination},
+		{"bob", "", "", ErrNoVerifiedAddress},
+		{"bob", primary, "", ErrUnverifiedDestination},
+	}
+	for _, tc := range cases {
+		got, err := Destination(ctx, q, tc.login, tc.requested)
+		if got != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("Destination(%q, %q) = %q, %v; want %q, %v", tc.login, tc.requested, got, err, tc.want, tc.err)
+		}
+	}
+}
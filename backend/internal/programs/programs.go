@@ -0,0 +1,221 @@
+// Package programs holds the rules of reward programs (hackathons, grant rounds): how the
+// contributions made to a program's eligible projects during its window are scored, and how its
+// budget is split among the contributors when it closes. The handlers and the close-out job live in
+// handlers and syncjobs.
+package programs
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+)
+
+// Program statuses. Drafts are only visible to admins; the sync worker closes active programs.
+const (
+	StatusDraft     = "draft"
+	StatusActive    = "active"
+	StatusClosed    = "closed"
+	StatusCancelled = "cancelled"
+)
+
+// maxWeight caps each scoring weight.
+const maxWeight = 1000
+
+// Scoring is a program's scoring rules: points per contribution in the window, and the score a
+// contributor needs to share in the budget. A merged pull request earns PullRequest plus
+// MergedPullRequest.
+type Scoring struct {
+	PullRequest       int64 `json:"pull_request"`
+	MergedPullRequest int64 `json:"merged_pull_request"`
+	Issue             int64 `json:"issue"`
+	Commit            int64 `json:"commit"`
+	MinScore          int64 `json:"min_score"`
+}
+
+// DefaultScoring is used when a program is created without scoring rules.
+var DefaultScoring = Scoring{MergedPullRequest: 5, Issue: 1, MinScore: 1}
+
+// ErrInvalidScoring is returned by Scoring.Validate.
+var ErrInvalidScoring = errors.New("scoring weights must be between 0 and 1000, with at least one above 0")
+
+// Validate checks the weights and the minimum score.
+func (s Scoring) Validate() error {
+	weights := []int64{s.PullRequest, s.MergedPullRequest, s.Issue, s.Commit}
+	positive := false
+	for _, w := range weights {
+		if w < 0 || w > maxWeight {
+			return ErrInvalidScoring
+		}
+		positive = positive || w > 0
+	}
+	if !positive || s.MinScore < 0 {
+		return ErrInvalidScoring
+	}
+	return nil
+}
+
+// Score is a contributor's standing in a program.
+type Score struct {
+	Login              string `json:"login"`
+	PullRequests       int    `json:"pull_requests"`
+	MergedPullRequests int    `json:"merged_pull_requests"`
+	Issues             int    `json:"issues"`
+	Commits            int    `json:"commits"`
+	Score              int64  `json:"score"`
+}
+
+// Points returns the score of counts under the rules.
+func (s Scoring) Points(c Score) int64 {
+	return int64(c.PullRequests)*s.PullRequest + int64(c.MergedPullRequests)*s.MergedPullRequest +
+		int64(c.Issues)*s.Issue + int64(c.Commits)*s.Commit
+}
+
+// Querier is a pool or transaction.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Scores returns the standings of the program's contributors, best first: their contributions to
+// eligible verified (or archived) projects between starts_at and ends_at, scored under the rules.
+// Contributors scoring 0 are left out.
+func Scores(ctx context.Context, q Querier, programID uuid.UUID, rules Scoring) ([]Score, error) {
+	rows, err := q.Query(ctx, `
+WITH program AS (
+  SELECT starts_at, ends_at,
+    NOT EXISTS (SELECT 1 FROM program_projects WHERE program_id = $1)
+      AND NOT EXISTS (SELECT 1 FROM program_ecosystems WHERE program_id = $1) AS everywhere
+  FROM programs WHERE id = $1
+),
+eligible AS (
+  SELECT p.id FROM projects p, program pg
+  WHERE p.status IN ('verified', 'archived') AND p.deleted_at IS NULL
+    AND (
+      pg.everywhere
+      OR p.id IN (SELECT project_id FROM program_projects WHERE program_id = $1)
+      OR p.ecosystem_id IN (
+        SELECT ec.descendant_id FROM program_ecosystems pe
+        JOIN ecosystem_closure ec ON ec.ancestor_id = pe.ecosystem_id
+        WHERE pe.program_id = $1
+      )
+    )
+)
+SELECT MIN(c.author_login),
+  COUNT(*) FILTER (WHERE c.kind = 'pull_request')::int,
+  COUNT(*) FILTER (WHERE c.kind = 'pull_request' AND pr.merged IS TRUE)::int,
+  COUNT(*) FILTER (WHERE c.kind = 'issue')::int,
+  COUNT(*) FILTER (WHERE c.kind = 'commit')::int
+FROM contributions c
+JOIN program pg ON c.occurred_at >= pg.starts_at AND c.occurred_at < pg.ends_at
+LEFT JOIN github_pull_requests pr ON c.kind = 'pull_request' AND pr.id = c.source_id
+WHERE c.project_id IN (SELECT id FROM eligible)
+GROUP BY LOWER(c.author_login)
+`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Score
+	for rows.Next() {
+		var s Score
+		if err := rows.Scan(&s.Login, &s.PullRequests, &s.MergedPullRequests, &s.Issues, &s.Commits); err != nil {
+			return nil, err
+		}
+		if s.Score = rules.Points(s); s.Score > 0 {
+			out = append(out, s)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	Rank(out)
+	return out, nil
+}
+
+// Rank sorts scores best first: by score, then merged pull requests, then login.
+func Rank(scores []Score) {
+	sort.SliceStable(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.MergedPullRequests != b.MergedPullRequests {
+			return a.MergedPullRequests > b.MergedPullRequests
+		}
+		return strings.ToLower(a.Login) < strings.ToLower(b.Login)
+	})
+}
+
+// Allocation is a contributor's share of a closed program's budget, in token base units.
+type Allocation struct {
+	Score
+	Rank  int
+	Units *big.Int
+}
+
+// Allocate splits budgetUnits among the ranked scores that reach minScore, in proportion to their
+// scores. Shares are rounded down and the units left over go one each to the largest remainders
+// (ties to the better rank), so the allocations add up to the whole budget.
+func Allocate(budgetUnits *big.Int, ranked []Score, minScore int64) []Allocation {
+	var out []Allocation
+	total := new(big.Int)
+	for _, s := range ranked {
+		if s.Score > 0 && s.Score >= minScore {
+			out = append(out, Allocation{Score: s, Rank: len(out) + 1})
+			total.Add(total, big.NewInt(s.Score))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	remainders := make([]*big.Int, len(out))
+	left := new(big.Int).Set(budgetUnits)
+	for i := range out {
+		share := new(big.Int).Mul(budgetUnits, big.NewInt(out[i].Score.Score))
+		out[i].Units, remainders[i] = new(big.Int).QuoRem(share, total, new(big.Int))
+		left.Sub(left, out[i].Units)
+	}
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].Cmp(remainders[order[b]]) > 0 })
+	one := big.NewInt(1)
+	for i := 0; left.Sign() > 0; i++ {
+		out[order[i]].Units.Add(out[order[i]].Units, one)
+		left.Sub(left, one)
+	}
+	return out
+}
+
+// ToUnits converts a decimal amount to token base units.
+func ToUnits(amount string) (*big.Int, error) {
+	s, err := bounties.ToUnits(amount)
+	if err != nil {
+		return nil, err
+	}
+	units, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, bounties.ErrInvalidAmount
+	}
+	return units, nil
+}
+
+// FormatUnits converts token base units back to a decimal amount.
+func FormatUnits(units *big.Int) string {
+	s := units.String()
+	if len(s) <= bounties.Decimals {
+		s = strings.Repeat("0", bounties.Decimals-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-bounties.Decimals], strings.TrimRight(s[len(s)-bounties.Decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
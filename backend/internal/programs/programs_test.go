@@ -0,0 +1,73 @@
+package programs
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestScoringValidate(t *testing.T) {
+	if err := DefaultScoring.Validate(); err != nil {
+		t.Fatalf("default scoring: %v", err)
+	}
+	for _, bad := range []Scoring{
+		{},
+		{Issue: -1, Commit: 1},
+		{PullRequest: maxWeight + 1},
+		{Issue: 1, MinScore: -1},
+	} {
+		if bad.Validate() == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
+
+func TestAllocateSplitsWholeBudget(t *testing.T) {
+	ranked := []Score{
+		{Login: "alice", Score: 2},
+		{Login: "bob", Score: 1},
+		{Login: "carol", Score: 1},
+		{Login: "dave", Score: 1},
+	}
+	// minScore 2 leaves alice alone with the budget.
+	if got := Allocate(big.NewInt(100), ranked, 2); len(got) != 1 || got[0].Units.Int64() != 100 {
+		t.Fatalf("min score: %+v", got)
+	}
+
+	got := Allocate(big.NewInt(100), ranked, 1)
+	want := []int64{40, 20, 20, 20}
+	sum := int64(0)
+	for i, a := range got {
+		if a.Rank != i+1 || a.Units.Int64() != want[i] {
+			t.Errorf("allocation %d = rank %d, %s units; want rank %d, %d", i, a.Rank, a.Units, i+1, want[i])
+		}
+		sum += a.Units.Int64()
+	}
+	if sum != 100 {
+		t.Errorf("allocated %d of 100", sum)
+	}
+
+	// 10 units over scores 1,1,1: remainders are equal, so the better ranks get the extra unit.
+	got = Allocate(big.NewInt(10), ranked[1:], 1)
+	if got[0].Units.Int64() != 4 || got[1].Units.Int64() != 3 || got[2].Units.Int64() != 3 {
+		t.Errorf("remainder split: %s %s %s", got[0].Units, got[1].Units, got[2].Units)
+	}
+	if Allocate(big.NewInt(10), nil, 1) != nil {
+		t.Error("allocations without contributors")
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	for units, want := range map[int64]string{
+		0:           "0",
+		1:           "0.0000001",
+		25000000:    "2.5",
+		10000000000: "1000",
+	} {
+		if got := FormatUnits(big.NewInt(units)); got != want {
+			t.Errorf("FormatUnits(%d) = %q, want %q", units, got, want)
+		}
+	}
+	if u, err := ToUnits("12.34"); err != nil || FormatUnits(u) != "12.34" {
+		t.Errorf("round trip = %v, %v", u, err)
+	}
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Rule limits requests sharing the same key to Limit per Window.
+// Requests for which Key returns "" are not counted by this rule.
+type Rule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    func(c *fiber.Ctx) string
+}
+
+// ByIP keys requests by client IP.
+func ByIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// ByLocal keys requests by a string stored in c.Locals (e.g. the authenticated user's ID), so the
+// rule must run after the middleware that sets it.
+func ByLocal(key string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		v, _ := c.Locals(key).(string)
+		return v
+	}
+}
+
+// ByBodyField keys requests by a top-level string field of the JSON body (e.g. "address"),
+// lowercased so case variants of the same address share a bucket.
+func ByBodyField(field string) func(c *fiber.Ctx) string {
+	return func(c *fiber.Ctx) string {
+		var body map[string]any
+		if err := json.Unmarshal(c.Body(), &body); err != nil {
+			return ""
+		}
+		v, _ := body[field].(string)
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+}
+
+// New returns middleware enforcing every rule; the first exceeded rule rejects with 429.
+// Store errors fail open (the request is allowed and the error logged) so an outage of the
+// counter backend doesn't lock everyone out of login.
+func New(store Store, rules ...Rule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, r := range rules {
+			k := r.Key(c)
+			if k == "" {
+				continue
+			}
+			count, resetAt, err := store.Hit(c.Context(), r.Name+":"+k, r.Window)
+			if err != nil {
+				slog.Warn("rate limit store error", "rule", r.Name, "error", err)
+				continue
+			}
+			if count > r.Limit {
+				retry := int(math.Ceil(time.Until(resetAt).Seconds()))
+				if retry < 1 {
+					retry = 1
+				}
+				slog.Warn("rate limit exceeded",
+					"rule", r.Name,
+					"path", c.Path(),
+					"remote_ip", c.IP(),
+				)
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retry))
+				return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+					"error":               "rate_limited",
+					"retry_after_seconds": retry,
+				})
+			}
+		}
+		return c.Next()
+	}
+}
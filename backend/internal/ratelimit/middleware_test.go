@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMiddlewareLimitsByBodyField(t *testing.T) {
+	app := fiber.New()
+	app.Post("/verify", New(NewMemoryStore(),
+		Rule{Name: "addr", Limit: 2, Window: time.Minute, Key: ByBodyField("address")},
+	), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	do := func(addr string) int {
+		req := httptest.NewRequest("POST", "/verify", strings.NewReader(`{"address":"`+addr+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := do("0xABC"); got != fiber.StatusOK {
+			t.Fatalf("request %d: got %d, want 200", i, got)
+		}
+	}
+	if got := do("0xabc"); got != fiber.StatusTooManyRequests {
+		t.Fatalf("third request: got %d, want 429", got)
+	}
+	if got := do("0xdef"); got != fiber.StatusOK {
+		t.Fatalf("other address: got %d, want 200", got)
+	}
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store counts hits per key in fixed windows. Implementations must be safe for concurrent use.
+// Hit increments the counter for key in the current window and returns the new count and
+// the time the window resets.
+type Store interface {
+	Hit(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+}
+
+// MemoryStore keeps counters in process memory. Suitable for a single API instance;
+// use PostgresStore (or another shared Store) when running several replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]memoryBucket{}, lastSweep: time.Now()}
+}
+
+func (s *MemoryStore) Hit(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired buckets once a minute so the map doesn't grow without bound.
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, b := range s.buckets {
+			if !now.Before(b.resetAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.resetAt) {
+		b = memoryBucket{resetAt: now.Add(window)}
+	}
+	b.count++
+	s.buckets[key] = b
+	return b.count, b.resetAt, nil
+}
+
+// PostgresStore keeps counters in the auth_rate_limits table so limits are shared across instances.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool, lastCleanup: time.Now()}
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.maybeCleanup()
+
+	var count int
+	var resetAt time.Time
+	err := s.pool.QueryRow(ctx, `
+INSERT INTO auth_rate_limits (key, count, reset_at)
+VALUES ($1, 1, now() + make_interval(secs => $2))
+ON CONFLICT (key) DO UPDATE SET
+  count = CASE WHEN auth_rate_limits.reset_at <= now() THEN 1 ELSE auth_rate_limits.count + 1 END,
+  reset_at = CASE WHEN auth_rate_limits.reset_at <= now() THEN EXCLUDED.reset_at ELSE auth_rate_limits.reset_at END
+RETURNING count, reset_at
+`, key, window.Seconds()).Scan(&count, &resetAt)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, resetAt, nil
+}
+
+// maybeCleanup purges expired rows in the background at most every five minutes.
+func (s *PostgresStore) maybeCleanup() {
+	s.mu.Lock()
+	if time.Since(s.lastCleanup) < 5*time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = time.Now()
+	s.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := s.Cleanup(ctx); err != nil {
+			slog.Warn("rate limit cleanup failed", "error", err)
+		}
+	}()
+}
+
+// Cleanup deletes expired counters. Safe to call periodically.
+func (s *PostgresStore) Cleanup(ctx context.Context) (int64, error) {
+	ct, err := s.pool.Exec(ctx, `DELETE FROM auth_rate_limits WHERE reset_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
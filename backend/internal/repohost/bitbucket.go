@@ -0,0 +1,94 @@
+package repohost
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bitbucket"
+)
+
+var bitbucketWebhookEvents = []string{
+	"repo:push",
+	"issue:created", "issue:updated", "issue:comment_created",
+	"pullrequest:created", "pullrequest:updated", "pullrequest:fulfilled", "pullrequest:rejected",
+}
+
+type bitbucketHost struct {
+	bb *bitbucket.Client
+}
+
+func NewBitbucketHost(bb *bitbucket.Client) Host {
+	return &bitbucketHost{bb: bb}
+}
+
+func (h *bitbucketHost) Name() string { return ProviderBitbucket }
+
+func (h *bitbucketHost) GetRepo(ctx context.Context, token string, fullName string) (Repo, error) {
+	r, err := h.bb.GetRepo(ctx, token, fullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	perm, err := h.bb.RepoPermission(ctx, token, r.FullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	out := Repo{
+		ID:          r.UUID,
+		FullName:    r.FullName,
+		HTMLURL:     r.Links.HTML.Href,
+		Description: r.Description,
+		Language:    r.Language,
+		Private:     r.IsPrivate,
+		CanAdmin:    perm == "admin" || perm == "write",
+	}
+	if r.MainBranch != nil {
+		out.DefaultBranch = r.MainBranch.Name
+	}
+	return out, nil
+}
+
+func (h *bitbucketHost) CreateWebhook(ctx context.Context, token string, fullName string, hook WebhookConfig) (string, error) {
+	wh, err := h.bb.CreateWebhook(ctx, token, fullName, bitbucket.CreateWebhookRequest{
+		Description: "Grainlify",
+		URL:         hook.URL,
+		Active:      true,
+		Secret:      hook.Secret,
+		Events:      bitbucketWebhookEvents,
+	})
+	if err != nil {
+		return "", err
+	}
+	return wh.UUID, nil
+}
+
+func (h *bitbucketHost) DeleteWebhook(ctx context.Context, token string, fullName string, hookID string) error {
+	return h.bb.DeleteWebhook(ctx, token, fullName, hookID)
+}
+
+func (h *bitbucketHost) ListIssuesPage(ctx context.Context, token string, fullName string, cursor string) ([]Issue, string, error) {
+	items, next, err := h.bb.ListIssuesPage(ctx, token, fullName, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	out := make([]Issue, 0, len(items))
+	for _, it := range items {
+		is := Issue{
+			ID:        it.ID,
+			Number:    int(it.ID), // Bitbucket issue IDs are per-repo numbers
+			State:     "closed",
+			Title:     it.Title,
+			Body:      it.Content.Raw,
+			URL:       it.Links.HTML.Href,
+			CreatedAt: parseTime(it.CreatedOn),
+			UpdatedAt: parseTime(it.UpdatedOn),
+		}
+		if it.IsOpen() {
+			is.State = "open"
+		}
+		if it.Reporter != nil {
+			is.AuthorLogin = strings.TrimSpace(it.Reporter.Nickname)
+		}
+		out = append(out, is)
+	}
+	return out, next, nil
+}
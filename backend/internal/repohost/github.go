@@ -0,0 +1,93 @@
+package repohost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+var githubWebhookEvents = []string{"issues", "pull_request", "pull_request_review", "push", "issue_comment", "pull_request_review_comment", "discussion", "release", "repository"}
+
+type githubHost struct {
+	gh *github.Client
+}
+
+func NewGitHubHost(gh *github.Client) Host {
+	return &githubHost{gh: gh}
+}
+
+func (h *githubHost) Name() string { return ProviderGitHub }
+
+func (h *githubHost) GetRepo(ctx context.Context, token string, fullName string) (Repo, error) {
+	r, err := h.gh.GetRepo(ctx, token, fullName)
+	if err != nil {
+		return Repo{}, err
+	}
+	return Repo{
+		ID:            strconv.FormatInt(r.ID, 10),
+		FullName:      r.FullName,
+		HTMLURL:       r.HTMLURL,
+		Description:   r.Description,
+		DefaultBranch: r.DefaultBranch,
+		Language:      r.Language,
+		Private:       r.Private,
+		CanAdmin:      r.Permissions.Admin || r.Permissions.Push,
+	}, nil
+}
+
+func (h *githubHost) CreateWebhook(ctx context.Context, token string, fullName string, hook WebhookConfig) (string, error) {
+	wh, err := h.gh.CreateWebhook(ctx, token, fullName, github.CreateWebhookRequest{
+		URL:    hook.URL,
+		Secret: hook.Secret,
+		Events: githubWebhookEvents,
+		Active: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(wh.ID, 10), nil
+}
+
+func (h *githubHost) DeleteWebhook(ctx context.Context, token string, fullName string, hookID string) error {
+	id, err := strconv.ParseInt(hookID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook id")
+	}
+	return h.gh.DeleteWebhook(ctx, token, fullName, id)
+}
+
+func (h *githubHost) ListIssuesPage(ctx context.Context, token string, fullName string, cursor string) ([]Issue, string, error) {
+	items, next, _, err := h.gh.ListIssuesPage(ctx, token, fullName, time.Time{}, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	out := make([]Issue, 0, len(items))
+	for _, it := range items {
+		if it.PullRequest != nil {
+			continue
+		}
+		out = append(out, Issue{
+			ID:          it.ID,
+			Number:      it.Number,
+			State:       it.State,
+			Title:       it.Title,
+			Body:        it.Body,
+			AuthorLogin: it.User.Login,
+			URL:         it.HTMLURL,
+			CreatedAt:   parseTimePtr(it.CreatedAt),
+			UpdatedAt:   parseTimePtr(it.UpdatedAt),
+			ClosedAt:    parseTimePtr(it.ClosedAt),
+		})
+	}
+	return out, next, nil
+}
+
+func parseTimePtr(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	return parseTime(*s)
+}
@@ -0,0 +1,37 @@
+package repohost
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bitbucket"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// NewRegistry returns the providers available under cfg. GitHub is always present; Bitbucket only
+// when its OAuth consumer is configured.
+func NewRegistry(cfg config.Config, pool *pgxpool.Pool, gh *github.Client, apps *github.InstallationTokenSource) Registry {
+	r := Registry{
+		ProviderGitHub: {
+			Host: NewGitHubHost(gh),
+			Token: func(ctx context.Context, projectID uuid.UUID, _ uuid.UUID) (string, error) {
+				t, err := github.GetProjectToken(ctx, pool, apps, projectID, cfg.TokenEncKeyB64)
+				return t.Token, err
+			},
+		},
+	}
+	if cfg.BitbucketOAuthClientID != "" && cfg.BitbucketOAuthClientSecret != "" {
+		oauth := bitbucket.OAuthConfig{ClientID: cfg.BitbucketOAuthClientID, ClientSecret: cfg.BitbucketOAuthClientSecret}
+		r[ProviderBitbucket] = Provider{
+			Host: NewBitbucketHost(bitbucket.NewClient()),
+			Token: func(ctx context.Context, _ uuid.UUID, ownerUserID uuid.UUID) (string, error) {
+				acct, err := bitbucket.GetLinkedAccount(ctx, pool, ownerUserID, cfg.TokenEncKeyB64, oauth)
+				return acct.AccessToken, err
+			},
+		}
+	}
+	return r
+}
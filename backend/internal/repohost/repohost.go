@@ -0,0 +1,87 @@
+// Package repohost is the provider-neutral view of a code host (GitHub, Bitbucket) used where
+// handlers and workers only need the basics: verifying a repository, registering its webhook and
+// listing its issues. GitHub-specific features (App installations, GraphQL sync, org webhooks)
+// stay in the github package.
+package repohost
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProviderGitHub    = "github"
+	ProviderBitbucket = "bitbucket"
+)
+
+type Repo struct {
+	ID            string
+	FullName      string
+	HTMLURL       string
+	Description   string
+	DefaultBranch string
+	Language      string
+	Private       bool
+	// CanAdmin reports whether the token may manage the repo (admin or write access).
+	CanAdmin bool
+}
+
+type Issue struct {
+	ID          int64
+	Number      int
+	State       string // "open" or "closed"
+	Title       string
+	Body        string
+	AuthorLogin string
+	URL         string
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+	ClosedAt    *time.Time
+}
+
+type WebhookConfig struct {
+	URL    string
+	Secret string
+}
+
+type Host interface {
+	Name() string
+	GetRepo(ctx context.Context, token string, fullName string) (Repo, error)
+	// CreateWebhook registers a hook for the events the ingestor understands and returns its ID.
+	CreateWebhook(ctx context.Context, token string, fullName string, hook WebhookConfig) (string, error)
+	// DeleteWebhook removes a hook created by CreateWebhook; one that is already gone is not an error.
+	DeleteWebhook(ctx context.Context, token string, fullName string, hookID string) error
+	// ListIssuesPage returns one page of issues; pass "" for the first page and then the returned
+	// cursor until it is "".
+	ListIssuesPage(ctx context.Context, token string, fullName string, cursor string) ([]Issue, string, error)
+}
+
+// Provider is a Host plus how to obtain a token for a project's repository on it.
+type Provider struct {
+	Host
+	Token func(ctx context.Context, projectID uuid.UUID, ownerUserID uuid.UUID) (string, error)
+}
+
+// Registry maps provider names to configured providers.
+type Registry map[string]Provider
+
+func (r Registry) Get(name string) (Provider, bool) {
+	if name == "" {
+		name = ProviderGitHub
+	}
+	p, ok := r[name]
+	return p, ok
+}
+
+func parseTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -21,10 +21,11 @@ type Client struct {
 
 // Config holds configuration for Soroban client
 type Config struct {
-	RPCURL           string // Soroban RPC endpoint
-	NetworkPassphrase string // Network passphrase
-	Network         Network // "testnet" or "mainnet"
-	HTTPTimeout     time.Duration
+	RPCURL            string  // Soroban RPC endpoint
+	NetworkPassphrase string  // Network passphrase
+	Network           Network // "testnet" or "mainnet"
+	HorizonURL        string  // Horizon endpoint; defaults to the network's public Horizon
+	HTTPTimeout       time.Duration
 }
 
 // NewClient creates a new Soroban client
@@ -47,9 +48,12 @@ func NewClient(cfg Config) (*Client, error) {
 	}
 
 	// Create Horizon client
-	horizonURL := "https://horizon-testnet.stellar.org"
-	if cfg.Network == NetworkMainnet {
-		horizonURL = "https://horizon.stellar.org"
+	horizonURL := cfg.HorizonURL
+	if horizonURL == "" {
+		horizonURL = "https://horizon-testnet.stellar.org"
+		if cfg.Network == NetworkMainnet {
+			horizonURL = "https://horizon.stellar.org"
+		}
 	}
 
 	horizonClient := &horizonclient.Client{
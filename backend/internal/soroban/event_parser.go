@@ -6,7 +6,8 @@ import (
 )
 
 // EventCompatPayload is a minimal normalized representation used by indexers/SDK code.
-// It intentionally keeps only required cross-version fields.
+// It intentionally keeps only required cross-version fields. Events with a registered type are
+// decoded by Events instead; this remains the fallback for the rest.
 type EventCompatPayload struct {
 	Version uint32 `json:"version"`
 	Amount  int64  `json:"amount,omitempty"`
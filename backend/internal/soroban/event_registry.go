@@ -0,0 +1,329 @@
+package soroban
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/stellar/go/strkey"
+)
+
+// Event type names, as stored in soroban_events.event_name.
+const (
+	EventBountyCreated       = "bounty_created"
+	EventEscrowReleased      = "escrow_released"
+	EventEscrowRefunded      = "escrow_refunded"
+	EventProgramFunded       = "program_funded"
+	EventPayoutExecuted      = "payout_executed"
+	EventBatchPayoutExecuted = "batch_payout_executed"
+)
+
+// BountyCreated is the bounty escrow's f_lock event: funds locked for a bounty.
+type BountyCreated struct {
+	BountyID  uint64   `json:"bounty_id"`
+	Amount    *big.Int `json:"amount"`
+	Depositor string   `json:"depositor"`
+	Deadline  uint64   `json:"deadline"`
+}
+
+// EscrowReleased is the bounty escrow's f_rel event.
+type EscrowReleased struct {
+	BountyID  uint64   `json:"bounty_id"`
+	Amount    *big.Int `json:"amount"`
+	Recipient string   `json:"recipient"`
+	Timestamp uint64   `json:"timestamp"`
+}
+
+// EscrowRefunded is the bounty escrow's f_ref event.
+type EscrowRefunded struct {
+	BountyID  uint64   `json:"bounty_id"`
+	Amount    *big.Int `json:"amount"`
+	RefundTo  string   `json:"refund_to"`
+	Timestamp uint64   `json:"timestamp"`
+}
+
+// ProgramFunded is the program escrow's FndsLock event.
+type ProgramFunded struct {
+	ProgramID        string   `json:"program_id"`
+	Amount           *big.Int `json:"amount"`
+	RemainingBalance *big.Int `json:"remaining_balance"`
+}
+
+// PayoutExecuted is the program escrow's Payout event: one prize paid.
+type PayoutExecuted struct {
+	ProgramID        string   `json:"program_id"`
+	Recipient        string   `json:"recipient"`
+	Amount           *big.Int `json:"amount"`
+	RemainingBalance *big.Int `json:"remaining_balance"`
+}
+
+// BatchPayoutExecuted is the program escrow's BatchPay event.
+type BatchPayoutExecuted struct {
+	ProgramID        string   `json:"program_id"`
+	RecipientCount   uint32   `json:"recipient_count"`
+	TotalAmount      *big.Int `json:"total_amount"`
+	RemainingBalance *big.Int `json:"remaining_balance"`
+}
+
+// EventDecoder decodes one payload version of an event type into its typed form.
+type EventDecoder func(f *EventFields) any
+
+// EventType describes a contract event: the first topic symbol that identifies it and a decoder for
+// each payload version. KeyField, when set, must match the event's second topic.
+type EventType struct {
+	Name     string
+	Topic    string
+	KeyField string
+	Versions map[uint32]EventDecoder
+}
+
+// latest returns the highest version with a decoder.
+func (t EventType) latest() uint32 {
+	var v uint32
+	for k := range t.Versions {
+		if k > v {
+			v = k
+		}
+	}
+	return v
+}
+
+// TypedEvent is a decoded, validated contract event. Forward is set when the payload's version is
+// newer than any decoder and it was read with the latest one, ignoring fields it does not know.
+type TypedEvent struct {
+	Type    string `json:"type"`
+	Version uint32 `json:"version"`
+	Forward bool   `json:"forward,omitempty"`
+	Data    any    `json:"data"`
+}
+
+// Errors returned by EventRegistry.Decode.
+var (
+	ErrUnregisteredEvent  = errors.New("unregistered event")
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+)
+
+// EventRegistry maps event topics to their types.
+type EventRegistry struct {
+	byTopic map[string]EventType
+}
+
+// NewEventRegistry returns a registry of types. Topics must be unique and every type needs a decoder.
+func NewEventRegistry(types ...EventType) (*EventRegistry, error) {
+	r := &EventRegistry{byTopic: map[string]EventType{}}
+	for _, t := range types {
+		if t.Name == "" || t.Topic == "" || len(t.Versions) == 0 {
+			return nil, fmt.Errorf("event type %q: name, topic and a decoder are required", t.Name)
+		}
+		if other, ok := r.byTopic[t.Topic]; ok {
+			return nil, fmt.Errorf("event types %q and %q share topic %q", other.Name, t.Name, t.Topic)
+		}
+		r.byTopic[t.Topic] = t
+	}
+	return r, nil
+}
+
+// TypeOf returns the registered type of an event, by its first topic.
+func (r *EventRegistry) TypeOf(d *DecodedEvent) (EventType, bool) {
+	if len(d.Topics) == 0 {
+		return EventType{}, false
+	}
+	topic, _ := d.Topics[0].(string)
+	t, ok := r.byTopic[topic]
+	return t, ok
+}
+
+// Decode validates an event against its type. The payload's version field selects the decoder, and
+// a payload without one is version 1. A known version must carry exactly its fields, with the
+// right types; a newer version must carry at least the latest version's fields.
+func (r *EventRegistry) Decode(d *DecodedEvent) (*TypedEvent, error) {
+	t, ok := r.TypeOf(d)
+	if !ok {
+		return nil, ErrUnregisteredEvent
+	}
+	m, ok := d.Value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: value is not a map", t.Name)
+	}
+
+	f := &EventFields{values: m, seen: map[string]bool{"version": true}}
+	version := uint32(1)
+	if raw, ok := m["version"]; ok {
+		v, ok := raw.(uint32)
+		if !ok {
+			return nil, fmt.Errorf("%s: version is %T, not u32", t.Name, raw)
+		}
+		version = v
+	}
+	out := &TypedEvent{Type: t.Name, Version: version}
+	decode, ok := t.Versions[version]
+	if !ok {
+		if latest := t.latest(); version > latest {
+			decode, out.Forward = t.Versions[latest], true
+		} else {
+			return nil, fmt.Errorf("%s v%d: %w", t.Name, version, ErrUnsupportedVersion)
+		}
+	}
+
+	out.Data = decode(f)
+	if f.err == nil && !out.Forward {
+		f.rejectUnknown()
+	}
+	if f.err == nil && t.KeyField != "" && len(d.Topics) > 1 {
+		if key, ok := d.Topics[1].(uint64); !ok || m[t.KeyField] != key {
+			f.err = fmt.Errorf("topic %v does not match %s", d.Topics[1], t.KeyField)
+		}
+	}
+	if f.err != nil {
+		return nil, fmt.Errorf("%s v%d: %w", t.Name, version, f.err)
+	}
+	return out, nil
+}
+
+// EventFields reads the fields of an event payload for an EventDecoder. The first missing or
+// ill-typed field is kept as the decode error; later reads return zero values.
+type EventFields struct {
+	values map[string]any
+	seen   map[string]bool
+	err    error
+}
+
+func (f *EventFields) get(name string) (any, bool) {
+	if f.err != nil {
+		return nil, false
+	}
+	f.seen[name] = true
+	v, ok := f.values[name]
+	if !ok {
+		f.err = fmt.Errorf("missing field %s", name)
+	}
+	return v, ok
+}
+
+func (f *EventFields) fail(name string, v any, want string) {
+	f.err = fmt.Errorf("field %s is %T, not %s", name, v, want)
+}
+
+// U32 reads a u32 field.
+func (f *EventFields) U32(name string) uint32 {
+	v, ok := f.get(name)
+	if !ok {
+		return 0
+	}
+	n, ok := v.(uint32)
+	if !ok {
+		f.fail(name, v, "u32")
+	}
+	return n
+}
+
+// U64 reads a u64 field.
+func (f *EventFields) U64(name string) uint64 {
+	v, ok := f.get(name)
+	if !ok {
+		return 0
+	}
+	n, ok := v.(uint64)
+	if !ok {
+		f.fail(name, v, "u64")
+	}
+	return n
+}
+
+// Amount reads a non-negative i128 field, in the token's base units.
+func (f *EventFields) Amount(name string) *big.Int {
+	v, ok := f.get(name)
+	if !ok {
+		return nil
+	}
+	num, ok := v.(json.Number)
+	if !ok {
+		f.fail(name, v, "i128")
+		return nil
+	}
+	n, ok := new(big.Int).SetString(string(num), 10)
+	if !ok || n.Sign() < 0 {
+		f.err = fmt.Errorf("field %s: invalid amount %s", name, num)
+		return nil
+	}
+	return n
+}
+
+// Address reads an account (G...) or contract (C...) address field.
+func (f *EventFields) Address(name string) string {
+	v, ok := f.get(name)
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	if !strkey.IsValidEd25519PublicKey(s) && !strkey.IsValidContractAddress(s) {
+		f.fail(name, v, "an address")
+	}
+	return s
+}
+
+// String reads a non-empty string field.
+func (f *EventFields) String(name string) string {
+	v, ok := f.get(name)
+	if !ok {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok || s == "" {
+		f.fail(name, v, "a non-empty string")
+	}
+	return s
+}
+
+// rejectUnknown fails on fields no read asked for: a contract that adds a field must bump the
+// payload version.
+func (f *EventFields) rejectUnknown() {
+	var unknown []string
+	for k := range f.values {
+		if !f.seen[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		f.err = fmt.Errorf("unexpected fields %v", unknown)
+	}
+}
+
+// Events is the registry of Grainlify's bounty and program escrow contract events. Version 1 is the
+// legacy unversioned payload; version 2 adds the version field (see contracts/EVENT_VERSIONING.md).
+var Events = mustEventRegistry(
+	EventType{Name: EventBountyCreated, Topic: "f_lock", KeyField: "bounty_id", Versions: bothVersions(func(f *EventFields) any {
+		return &BountyCreated{BountyID: f.U64("bounty_id"), Amount: f.Amount("amount"), Depositor: f.Address("depositor"), Deadline: f.U64("deadline")}
+	})},
+	EventType{Name: EventEscrowReleased, Topic: "f_rel", KeyField: "bounty_id", Versions: bothVersions(func(f *EventFields) any {
+		return &EscrowReleased{BountyID: f.U64("bounty_id"), Amount: f.Amount("amount"), Recipient: f.Address("recipient"), Timestamp: f.U64("timestamp")}
+	})},
+	EventType{Name: EventEscrowRefunded, Topic: "f_ref", KeyField: "bounty_id", Versions: bothVersions(func(f *EventFields) any {
+		return &EscrowRefunded{BountyID: f.U64("bounty_id"), Amount: f.Amount("amount"), RefundTo: f.Address("refund_to"), Timestamp: f.U64("timestamp")}
+	})},
+	EventType{Name: EventProgramFunded, Topic: "FndsLock", Versions: bothVersions(func(f *EventFields) any {
+		return &ProgramFunded{ProgramID: f.String("program_id"), Amount: f.Amount("amount"), RemainingBalance: f.Amount("remaining_balance")}
+	})},
+	EventType{Name: EventPayoutExecuted, Topic: "Payout", Versions: bothVersions(func(f *EventFields) any {
+		return &PayoutExecuted{ProgramID: f.String("program_id"), Recipient: f.Address("recipient"), Amount: f.Amount("amount"), RemainingBalance: f.Amount("remaining_balance")}
+	})},
+	EventType{Name: EventBatchPayoutExecuted, Topic: "BatchPay", Versions: bothVersions(func(f *EventFields) any {
+		return &BatchPayoutExecuted{ProgramID: f.String("program_id"), RecipientCount: f.U32("recipient_count"), TotalAmount: f.Amount("total_amount"), RemainingBalance: f.Amount("remaining_balance")}
+	})},
+)
+
+// bothVersions registers a decoder for v1 and v2, which differ only in the version field.
+func bothVersions(d EventDecoder) map[uint32]EventDecoder {
+	return map[uint32]EventDecoder{1: d, 2: d}
+}
+
+func mustEventRegistry(types ...EventType) *EventRegistry {
+	r, err := NewEventRegistry(types...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
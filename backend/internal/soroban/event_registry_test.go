@@ -0,0 +1,76 @@
+package soroban
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/events/*.golden")
+
+// TestEventRegistryGolden decodes each getEvents record in testdata/events and compares the typed
+// event, or the decode error, with its .golden file. Add a record there for each new contract event
+// version; run with -update to write the golden files and review them.
+func TestEventRegistryGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/events/*.json")
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no golden inputs: %v", err)
+	}
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), ".json")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var e Event
+			if err := json.Unmarshal(raw, &e); err != nil {
+				t.Fatal(err)
+			}
+			d, err := DecodeEvent(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got any
+			if typed, err := Events.Decode(d); err != nil {
+				got = map[string]string{"error": err.Error()}
+			} else {
+				got = typed
+			}
+			b, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			b = append(b, '\n')
+
+			golden := strings.TrimSuffix(in, ".json") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, b, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(b, want) {
+				t.Errorf("decoded %s:\n%s\nwant:\n%s", name, b, want)
+			}
+		})
+	}
+}
+
+func TestEventRegistryUnregistered(t *testing.T) {
+	d := &DecodedEvent{Topics: []any{"fee"}, Value: map[string]any{"amount": json.Number("1")}}
+	if _, err := Events.Decode(d); err != ErrUnregisteredEvent {
+		t.Fatalf("Decode(fee) = %v, want ErrUnregisteredEvent", err)
+	}
+	if _, err := NewEventRegistry(EventType{Name: "a", Topic: "x", Versions: bothVersions(nil)}, EventType{Name: "b", Topic: "x", Versions: bothVersions(nil)}); err == nil {
+		t.Fatal("duplicate topic registered")
+	}
+}
@@ -0,0 +1,187 @@
+package soroban
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// EventFilter selects contract events in a getEvents request
+type EventFilter struct {
+	Type        string   `json:"type,omitempty"`
+	ContractIDs []string `json:"contractIds,omitempty"`
+}
+
+// EventPagination continues a getEvents scan from a cursor
+type EventPagination struct {
+	Cursor string `json:"cursor,omitempty"`
+	Limit  uint   `json:"limit,omitempty"`
+}
+
+// GetEventsRequest is the params of a getEvents call. StartLedger and a pagination cursor are
+// mutually exclusive.
+type GetEventsRequest struct {
+	StartLedger uint32           `json:"startLedger,omitempty"`
+	Filters     []EventFilter    `json:"filters"`
+	Pagination  *EventPagination `json:"pagination,omitempty"`
+}
+
+// Event is a contract event as returned by getEvents; Topic and Value are base64 ScVal XDR
+type Event struct {
+	ID                       string   `json:"id"`
+	Type                     string   `json:"type"`
+	Ledger                   uint32   `json:"ledger"`
+	LedgerClosedAt           string   `json:"ledgerClosedAt"`
+	ContractID               string   `json:"contractId"`
+	TxHash                   string   `json:"txHash"`
+	Topic                    []string `json:"topic"`
+	Value                    string   `json:"value"`
+	InSuccessfulContractCall bool     `json:"inSuccessfulContractCall"`
+}
+
+// GetEventsResponse is the result of a getEvents call. Cursor resumes after the last ledger scanned,
+// even when no events matched.
+type GetEventsResponse struct {
+	Events       []Event `json:"events"`
+	LatestLedger uint32  `json:"latestLedger"`
+	Cursor       string  `json:"cursor"`
+}
+
+// GetEvents fetches contract events using Soroban RPC
+func (c *Client) GetEvents(ctx context.Context, req GetEventsRequest) (*GetEventsResponse, error) {
+	resp, err := c.Call(ctx, "getEvents", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result GetEventsResponse
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DecodedEvent is an event's topics and value converted from XDR to plain JSON values
+type DecodedEvent struct {
+	Topics []any
+	Value  any
+	// ValueJSON is Value encoded as JSON, the input of ParseEventCompatPayload
+	ValueJSON []byte
+}
+
+// DecodeEvent decodes the topics and value of an event
+func DecodeEvent(e Event) (*DecodedEvent, error) {
+	out := &DecodedEvent{Topics: make([]any, 0, len(e.Topic))}
+	for i, raw := range e.Topic {
+		v, err := decodeScValB64(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode topic %d: %w", i, err)
+		}
+		out.Topics = append(out.Topics, v)
+	}
+	v, err := decodeScValB64(e.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decode value: %w", err)
+	}
+	out.Value = v
+	if out.ValueJSON, err = json.Marshal(v); err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	return out, nil
+}
+
+func decodeScValB64(raw string) (any, error) {
+	var v xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(raw, &v); err != nil {
+		return nil, err
+	}
+	return ScValToNative(v)
+}
+
+// ScValToNative converts an ScVal to a value that encodes naturally as JSON: numbers up to 64 bits
+// as integers, 128/256-bit numbers as json.Number, bytes as hex, addresses as strkeys, maps as
+// objects keyed by the key's string form.
+func ScValToNative(v xdr.ScVal) (any, error) {
+	switch v.Type {
+	case xdr.ScValTypeScvBool:
+		return *v.B, nil
+	case xdr.ScValTypeScvVoid:
+		return nil, nil
+	case xdr.ScValTypeScvU32:
+		return uint32(*v.U32), nil
+	case xdr.ScValTypeScvI32:
+		return int32(*v.I32), nil
+	case xdr.ScValTypeScvU64:
+		return uint64(*v.U64), nil
+	case xdr.ScValTypeScvI64:
+		return int64(*v.I64), nil
+	case xdr.ScValTypeScvTimepoint:
+		return uint64(*v.Timepoint), nil
+	case xdr.ScValTypeScvDuration:
+		return uint64(*v.Duration), nil
+	case xdr.ScValTypeScvU128, xdr.ScValTypeScvI128, xdr.ScValTypeScvU256, xdr.ScValTypeScvI256:
+		return json.Number(v.String()), nil
+	case xdr.ScValTypeScvBytes:
+		return hex.EncodeToString(*v.Bytes), nil
+	case xdr.ScValTypeScvString:
+		return string(*v.Str), nil
+	case xdr.ScValTypeScvSymbol:
+		return string(*v.Sym), nil
+	case xdr.ScValTypeScvAddress:
+		return v.Address.String()
+	case xdr.ScValTypeScvVec:
+		if v.Vec == nil || *v.Vec == nil {
+			return []any{}, nil
+		}
+		out := make([]any, 0, len(**v.Vec))
+		for _, item := range **v.Vec {
+			n, err := ScValToNative(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, n)
+		}
+		return out, nil
+	case xdr.ScValTypeScvMap:
+		out := map[string]any{}
+		if v.Map == nil || *v.Map == nil {
+			return out, nil
+		}
+		for _, entry := range **v.Map {
+			key, err := ScValToNative(entry.Key)
+			if err != nil {
+				return nil, err
+			}
+			val, err := ScValToNative(entry.Val)
+			if err != nil {
+				return nil, err
+			}
+			ks, ok := key.(string)
+			if !ok {
+				ks = entry.Key.String()
+			}
+			out[ks] = val
+		}
+		return out, nil
+	default:
+		return v.String(), nil
+	}
+}
+
+// ContractStrkey returns a contract ID in the C... form getEvents reports, accepting the strkey
+// itself or the hex/base64 forms EncodeContractAddress takes.
+func ContractStrkey(id string) (string, error) {
+	if _, err := strkey.Decode(strkey.VersionByteContract, id); err == nil {
+		return id, nil
+	}
+	addr, err := EncodeContractAddress(id)
+	if err != nil {
+		return "", err
+	}
+	return strkey.Encode(strkey.VersionByteContract, addr.ContractId[:])
+}
@@ -0,0 +1,56 @@
+package soroban
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+)
+
+func mustB64(t *testing.T, v xdr.ScVal) string {
+	t.Helper()
+	s, err := xdr.MarshalBase64(v)
+	if err != nil {
+		t.Fatalf("MarshalBase64 failed: %v", err)
+	}
+	return s
+}
+
+func TestDecodeEvent_MapValueFeedsCompatParser(t *testing.T) {
+	sym := xdr.ScSymbol("funds_locked")
+	topic := xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+
+	amountKey := xdr.ScSymbol("amount")
+	programKey := xdr.ScSymbol("program_id")
+	program, _ := EncodeScValString("hack-2026")
+	amount := xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Hi: 0, Lo: 1500}}
+	m := &xdr.ScMap{
+		{Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &amountKey}, Val: amount},
+		{Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &programKey}, Val: program},
+	}
+	value := xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &m}
+
+	decoded, err := DecodeEvent(Event{Topic: []string{mustB64(t, topic)}, Value: mustB64(t, value)})
+	if err != nil {
+		t.Fatalf("DecodeEvent failed: %v", err)
+	}
+	if len(decoded.Topics) != 1 || decoded.Topics[0] != "funds_locked" {
+		t.Fatalf("expected topic funds_locked, got %v", decoded.Topics)
+	}
+	if string(decoded.ValueJSON) != `{"amount":1500,"program_id":"hack-2026"}` {
+		t.Fatalf("unexpected value JSON: %s", decoded.ValueJSON)
+	}
+
+	parsed, err := ParseEventCompatPayload(decoded.ValueJSON)
+	if err != nil {
+		t.Fatalf("ParseEventCompatPayload failed: %v", err)
+	}
+	if parsed.Version != 1 || parsed.Amount != 1500 {
+		t.Fatalf("expected v1 amount 1500, got %+v", parsed)
+	}
+}
+
+func TestDecodeEvent_InvalidXDR(t *testing.T) {
+	if _, err := DecodeEvent(Event{Value: "not-xdr"}); err == nil {
+		t.Fatal("expected an error for invalid XDR")
+	}
+}
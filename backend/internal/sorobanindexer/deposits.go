@@ -0,0 +1,134 @@
+package sorobanindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+
+	"github.com/jagadeesh/grainlify/backend/internal/payoutaddress"
+)
+
+// DepositWatcher verifies payout addresses by deposit: it pages through the transactions of the
+// verification account on Horizon, and a successful transaction sent from a pending address with
+// that address's challenge code as text memo verifies it. Its Horizon paging token is stored in
+// soroban_indexer_cursors like the event cursors, so it resumes where it stopped.
+type DepositWatcher struct {
+	horizon      *horizonclient.Client
+	pool         *pgxpool.Pool
+	account      string
+	pollInterval time.Duration
+}
+
+// NewDepositWatcher returns a watcher of account's transactions.
+func NewDepositWatcher(horizonURL, account string, pool *pgxpool.Pool, pollInterval time.Duration) *DepositWatcher {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	return &DepositWatcher{
+		horizon:      &horizonclient.Client{HorizonURL: horizonURL, HTTP: &http.Client{Timeout: 30 * time.Second}},
+		pool:         pool,
+		account:      account,
+		pollInterval: pollInterval,
+	}
+}
+
+func (w *DepositWatcher) cursorName() string { return "deposits:" + w.account }
+
+// Run polls until ctx is done, like Indexer.Run.
+func (w *DepositWatcher) Run(ctx context.Context) error {
+	slog.Info("payout deposit watcher started", "account", w.account)
+	for {
+		n, err := w.Step(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("payout deposit poll failed", "account", w.account, "error", err)
+		}
+		if err == nil && n >= defaultBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// Step reads one page of transactions after the stored paging token and returns how many it saw.
+// The first run starts at the account's oldest transaction.
+func (w *DepositWatcher) Step(ctx context.Context) (int, error) {
+	var cursor *string
+	err := w.pool.QueryRow(ctx, `SELECT cursor FROM soroban_indexer_cursors WHERE name = $1`, w.cursorName()).Scan(&cursor)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+	req := horizonclient.TransactionRequest{ForAccount: w.account, Order: horizonclient.OrderAsc, Limit: defaultBatchSize}
+	if cursor != nil {
+		req.Cursor = *cursor
+	}
+	page, err := w.horizon.Transactions(req)
+	if err != nil {
+		return 0, err
+	}
+	txs := page.Embedded.Records
+	if len(txs) == 0 {
+		return 0, nil
+	}
+
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	for _, t := range txs {
+		if err := applyDeposit(ctx, tx, t); err != nil {
+			return 0, fmt.Errorf("apply transaction %s: %w", t.Hash, err)
+		}
+	}
+	last := txs[len(txs)-1]
+	if _, err := tx.Exec(ctx, `
+INSERT INTO soroban_indexer_cursors (name, start_ledger, cursor, last_ledger) VALUES ($1, 0, $2, $3)
+ON CONFLICT (name) DO UPDATE SET cursor = EXCLUDED.cursor, last_ledger = EXCLUDED.last_ledger, updated_at = now()
+`, w.cursorName(), last.PagingToken(), int64(last.Ledger)); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(txs), nil
+}
+
+// applyDeposit verifies the pending address the transaction's source and memo point at, if any.
+func applyDeposit(ctx context.Context, tx pgx.Tx, t horizon.Transaction) error {
+	if !t.Successful || t.MemoType != "text" || t.Memo == "" {
+		return nil
+	}
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `
+SELECT id FROM payout_addresses
+WHERE challenge_code = $1 AND address = $2 AND status = 'pending' AND challenge_expires_at >= $3
+`, t.Memo, t.Account, t.LedgerCloseTime).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	hash := t.Hash
+	ok, err := payoutaddress.MarkVerified(ctx, tx, id, payoutaddress.MethodDeposit, &hash, t.LedgerCloseTime)
+	if ok {
+		slog.Info("payout address verified by deposit", "payout_address_id", id, "tx_hash", t.Hash)
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package sorobanindexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// escrowEvent is a funds event of the bounty escrow contract. Account is the depositor, the
+// recipient or the refund address, depending on the event.
+type escrowEvent struct {
+	kind      string
+	onchainID int64
+	amount    string
+	account   string
+}
+
+func parseEscrowEvent(t *soroban.TypedEvent) (escrowEvent, bool) {
+	if t == nil {
+		return escrowEvent{}, false
+	}
+	var ev escrowEvent
+	var id uint64
+	switch data := t.Data.(type) {
+	case *soroban.BountyCreated:
+		id, ev.amount, ev.account = data.BountyID, data.Amount.String(), data.Depositor
+	case *soroban.EscrowReleased:
+		id, ev.amount, ev.account = data.BountyID, data.Amount.String(), data.Recipient
+	case *soroban.EscrowRefunded:
+		id, ev.amount, ev.account = data.BountyID, data.Amount.String(), data.RefundTo
+	default:
+		return escrowEvent{}, false
+	}
+	if id > math.MaxInt64 {
+		return escrowEvent{}, false
+	}
+	ev.kind, ev.onchainID = t.Type, int64(id)
+	return ev, true
+}
+
+// applyEscrowEvent brings bounty_escrows (and the bounty) in line with an escrow contract event.
+// Events for escrows Grainlify did not initiate change nothing; each update only applies from the
+// state before the event, so replays are harmless.
+func applyEscrowEvent(ctx context.Context, tx pgx.Tx, e soroban.Event, t *soroban.TypedEvent, closedAt *time.Time) error {
+	ev, ok := parseEscrowEvent(t)
+	if !ok || !e.InSuccessfulContractCall {
+		return nil
+	}
+	at := time.Now()
+	if closedAt != nil {
+		at = *closedAt
+	}
+
+	switch ev.kind {
+	case soroban.EventBountyCreated:
+		var escrowID, bountyID uuid.UUID
+		var enough, otherLocked bool
+		err := tx.QueryRow(ctx, `
+SELECT id, bounty_id, amount_units <= $3::numeric,
+  EXISTS(SELECT 1 FROM bounty_escrows o WHERE o.bounty_id = be.bounty_id AND o.status = 'locked')
+FROM bounty_escrows be
+WHERE contract_id = $1 AND onchain_id = $2 AND status IN ('awaiting_deposit', 'abandoned')
+`, e.ContractID, ev.onchainID, ev.amount).Scan(&escrowID, &bountyID, &enough, &otherLocked)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !enough {
+			slog.Warn("escrow deposit below the bounty amount; not marking funded",
+				"contract_id", e.ContractID, "onchain_id", ev.onchainID, "amount", ev.amount, "tx_hash", e.TxHash)
+			return nil
+		}
+		if otherLocked {
+			slog.Warn("escrow deposit for a bounty that is already funded; refund it on-chain",
+				"contract_id", e.ContractID, "onchain_id", ev.onchainID, "tx_hash", e.TxHash)
+			return nil
+		}
+		// A deposit to an abandoned escrow still locked funds: it wins over a newer initiation.
+		if _, err := tx.Exec(ctx, `
+UPDATE bounty_escrows SET status = 'abandoned', updated_at = now()
+WHERE bounty_id = $1 AND status = 'awaiting_deposit' AND id <> $2
+`, bountyID, escrowID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+UPDATE bounty_escrows
+SET status = 'locked', depositor = COALESCE(NULLIF($2, ''), depositor), lock_tx_hash = $3, lock_ledger = $4,
+    locked_at = $5, updated_at = now()
+WHERE id = $1
+`, escrowID, ev.account, e.TxHash, int64(e.Ledger), at); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE bounties SET funded_at = $2, updated_at = now() WHERE id = $1`, bountyID, at)
+		return err
+
+	case soroban.EventEscrowReleased:
+		var bountyID uuid.UUID
+		err := tx.QueryRow(ctx, `
+UPDATE bounty_escrows
+SET status = 'released', release_to = COALESCE(NULLIF($3, ''), release_to), release_tx_hash = $4,
+    released_at = $5, submitting_action = NULL, submitting_at = NULL, updated_at = now()
+WHERE contract_id = $1 AND onchain_id = $2 AND status = 'locked'
+RETURNING bounty_id
+`, e.ContractID, ev.onchainID, ev.account, e.TxHash, at).Scan(&bountyID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var from string
+		err = tx.QueryRow(ctx, `
+UPDATE bounties b SET status = 'paid', paid_at = $2, payment_tx_hash = $3, updated_at = now()
+FROM (SELECT id, status FROM bounties WHERE id = $1 FOR UPDATE) old
+WHERE b.id = old.id AND old.status IN ('claimed', 'pending_payout')
+RETURNING old.status
+`, bountyID, at, e.TxHash).Scan(&from)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+INSERT INTO bounty_events (bounty_id, from_status, to_status, note) VALUES ($1, $2, 'paid', $3)
+`, bountyID, from, fmt.Sprintf("Escrow released in ledger %d", e.Ledger))
+		return err
+
+	case soroban.EventEscrowRefunded:
+		var bountyID uuid.UUID
+		err := tx.QueryRow(ctx, `
+UPDATE bounty_escrows
+SET status = 'refunded', refund_tx_hash = $3, refunded_at = $4, submitting_action = NULL, submitting_at = NULL,
+    updated_at = now()
+WHERE contract_id = $1 AND onchain_id = $2 AND status = 'locked'
+RETURNING bounty_id
+`, e.ContractID, ev.onchainID, e.TxHash, at).Scan(&bountyID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE bounties SET funded_at = NULL, updated_at = now() WHERE id = $1`, bountyID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package sorobanindexer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// typed decodes d with the event registry, or returns nil when it does not validate.
+func typed(d *soroban.DecodedEvent) *soroban.TypedEvent {
+	te, err := soroban.Events.Decode(d)
+	if err != nil {
+		return nil
+	}
+	return te
+}
+
+func TestParseEscrowEvent(t *testing.T) {
+	d := &soroban.DecodedEvent{
+		Topics: []any{"f_lock", uint64(42)},
+		Value: map[string]any{
+			"version":   uint32(2),
+			"bounty_id": uint64(42),
+			"amount":    json.Number("2500000000"),
+			"depositor": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+			"deadline":  uint64(1790000000),
+		},
+	}
+	ev, ok := parseEscrowEvent(typed(d))
+	if !ok {
+		t.Fatal("f_lock event not parsed")
+	}
+	if ev.onchainID != 42 || ev.amount != "2500000000" || ev.account != "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H" {
+		t.Errorf("parsed %+v", ev)
+	}
+
+	if _, ok := parseEscrowEvent(typed(&soroban.DecodedEvent{Topics: []any{"fee"}, Value: d.Value})); ok {
+		t.Error("fee event parsed as an escrow event")
+	}
+	bad := map[string]any{"bounty_id": uint64(1), "amount": json.Number("-5")}
+	if _, ok := parseEscrowEvent(typed(&soroban.DecodedEvent{Topics: []any{"f_ref"}, Value: bad})); ok {
+		t.Error("negative amount accepted")
+	}
+}
+
+func TestParseProgramEvent(t *testing.T) {
+	d := &soroban.DecodedEvent{
+		Topics: []any{"BatchPay"},
+		Value: map[string]any{
+			"version":           uint32(2),
+			"program_id":        "hackathon-2026",
+			"recipient_count":   uint32(3),
+			"total_amount":      json.Number("300"),
+			"remaining_balance": json.Number("700"),
+		},
+	}
+	id, balance, ok := parseProgramEvent(typed(d))
+	if !ok || id != "hackathon-2026" || balance != "700" {
+		t.Fatalf("parseProgramEvent = %q, %q, %v", id, balance, ok)
+	}
+	if _, _, ok := parseProgramEvent(typed(&soroban.DecodedEvent{Topics: []any{"PauseSt"}, Value: d.Value})); ok {
+		t.Error("pause event parsed as a balance event")
+	}
+}
@@ -0,0 +1,276 @@
+// Package sorobanindexer streams Soroban contract events from RPC into soroban_events. Each poll
+// reads one page of getEvents after the stored cursor and writes the events and the new cursor in
+// one transaction, so a crash never skips or half-records a page. Events are keyed by their RPC id:
+// a replay (Replay, or cmd/indexer -replay-from) rewinds the cursor and re-parses what it sees.
+// DepositWatcher runs alongside on Horizon, verifying payout addresses by memo-tagged deposit, and
+// ProgramDepositWatcher credits memo-tagged payments to reward program budgets.
+package sorobanindexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+const (
+	// DefaultName is the cursor name used when Options.Name is empty.
+	DefaultName = "default"
+	// maxContractsPerFilter is the Soroban RPC limit on contract IDs in one event filter.
+	maxContractsPerFilter = 5
+	defaultBatchSize      = 200
+	defaultPollInterval   = 5 * time.Second
+)
+
+type Options struct {
+	// Name keys the stored cursor, so indexers watching different contracts resume independently.
+	Name        string
+	ContractIDs []string
+	// StartLedger is where the first run begins; 0 starts at the latest ledger.
+	StartLedger  uint32
+	PollInterval time.Duration
+	BatchSize    uint
+}
+
+type Indexer struct {
+	client *soroban.Client
+	pool   *pgxpool.Pool
+	opts   Options
+}
+
+func New(client *soroban.Client, pool *pgxpool.Pool, opts Options) *Indexer {
+	if opts.Name == "" {
+		opts.Name = DefaultName
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	return &Indexer{client: client, pool: pool, opts: opts}
+}
+
+// Run polls until ctx is done. Full pages are followed immediately; otherwise the indexer waits
+// PollInterval, as it does after an error.
+func (ix *Indexer) Run(ctx context.Context) error {
+	if len(ix.opts.ContractIDs) == 0 {
+		return fmt.Errorf("no contract ids to index")
+	}
+	slog.Info("soroban indexer started", "name", ix.opts.Name, "contracts", len(ix.opts.ContractIDs))
+	for {
+		n, err := ix.Step(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("soroban indexer poll failed", "name", ix.opts.Name, "error", err)
+		}
+		if err == nil && uint(n) >= ix.opts.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ix.opts.PollInterval):
+		}
+	}
+}
+
+// Step indexes one page of events and returns how many it stored.
+func (ix *Indexer) Step(ctx context.Context) (int, error) {
+	startLedger, cursor, err := ix.checkpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	req := soroban.GetEventsRequest{Pagination: &soroban.EventPagination{Cursor: cursor, Limit: ix.opts.BatchSize}}
+	if cursor == "" {
+		req.StartLedger = startLedger
+	}
+	for i := 0; i < len(ix.opts.ContractIDs); i += maxContractsPerFilter {
+		end := min(i+maxContractsPerFilter, len(ix.opts.ContractIDs))
+		req.Filters = append(req.Filters, soroban.EventFilter{Type: "contract", ContractIDs: ix.opts.ContractIDs[i:end]})
+	}
+	resp, err := ix.client.GetEvents(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	next := resp.Cursor
+	if next == "" && len(resp.Events) > 0 {
+		// Older RPC versions only page by event id.
+		next = resp.Events[len(resp.Events)-1].ID
+	}
+	if next == "" {
+		return 0, nil
+	}
+
+	tx, err := ix.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var lastLedger *int64
+	for _, e := range resp.Events {
+		if err := storeEvent(ctx, tx, e); err != nil {
+			return 0, fmt.Errorf("store event %s: %w", e.ID, err)
+		}
+		l := int64(e.Ledger)
+		lastLedger = &l
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE soroban_indexer_cursors
+SET cursor = $2, last_ledger = COALESCE($3, last_ledger), updated_at = now()
+WHERE name = $1
+`, ix.opts.Name, next, lastLedger); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	if len(resp.Events) > 0 {
+		slog.Info("soroban events indexed", "name", ix.opts.Name, "events", len(resp.Events), "last_ledger", *lastLedger, "latest_ledger", resp.LatestLedger)
+	}
+	return len(resp.Events), nil
+}
+
+// checkpoint returns where the next poll starts, creating the cursor on first run.
+func (ix *Indexer) checkpoint(ctx context.Context) (uint32, string, error) {
+	var start int64
+	var cursor *string
+	err := ix.pool.QueryRow(ctx, `SELECT start_ledger, cursor FROM soroban_indexer_cursors WHERE name = $1`, ix.opts.Name).Scan(&start, &cursor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		first := ix.opts.StartLedger
+		if first == 0 {
+			if first, err = ix.latestLedger(ctx); err != nil {
+				return 0, "", err
+			}
+		}
+		if _, err := ix.pool.Exec(ctx, `
+INSERT INTO soroban_indexer_cursors (name, start_ledger) VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING
+`, ix.opts.Name, int64(first)); err != nil {
+			return 0, "", err
+		}
+		return ix.checkpoint(ctx)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if cursor != nil {
+		return uint32(start), *cursor, nil
+	}
+	return uint32(start), "", nil
+}
+
+func (ix *Indexer) latestLedger(ctx context.Context) (uint32, error) {
+	res, err := ix.client.GetLatestLedger(ctx)
+	if err != nil {
+		return 0, err
+	}
+	seq, ok := res["sequence"].(float64)
+	if !ok || seq <= 0 {
+		return 0, fmt.Errorf("getLatestLedger returned no sequence")
+	}
+	return uint32(seq), nil
+}
+
+// storeEvent decodes and upserts one event, then applies escrow contract events to
+// bounty_escrows and program escrow balances to programs. Events of registered types are validated
+// by their versioned decoder (soroban.Events); other events go through the compat parser. Events
+// that fail either are still stored with parse_error so a replay after a parser fix can pick them up.
+func storeEvent(ctx context.Context, tx pgx.Tx, e soroban.Event) error {
+	topics := []byte("[]")
+	var payload *string
+	var version *int32
+	var amount *int64
+	var parseErr *string
+	var name *string
+	var typed *soroban.TypedEvent
+
+	decoded, decodeErr := soroban.DecodeEvent(e)
+	if decodeErr == nil {
+		var err error
+		if topics, err = json.Marshal(decoded.Topics); err != nil {
+			return err
+		}
+		p := string(decoded.ValueJSON)
+		payload = &p
+		compat, cerr := soroban.ParseEventCompatPayload(decoded.ValueJSON)
+		if cerr == nil {
+			amount = &compat.Amount
+		}
+		if t, ok := soroban.Events.TypeOf(decoded); ok {
+			name = &t.Name
+			var terr error
+			if typed, terr = soroban.Events.Decode(decoded); terr == nil {
+				v := int32(typed.Version)
+				version = &v
+			} else {
+				msg := terr.Error()
+				parseErr = &msg
+			}
+		} else if cerr == nil {
+			v := int32(compat.Version)
+			version = &v
+		} else {
+			msg := cerr.Error()
+			parseErr = &msg
+		}
+	} else {
+		msg := decodeErr.Error()
+		parseErr = &msg
+	}
+
+	var closedAt *time.Time
+	if t, err := time.Parse(time.RFC3339, e.LedgerClosedAt); err == nil {
+		closedAt = &t
+	}
+	topicXDR := e.Topic
+	if topicXDR == nil {
+		topicXDR = []string{}
+	}
+
+	_, err := tx.Exec(ctx, `
+INSERT INTO soroban_events (id, contract_id, event_type, ledger, ledger_closed_at, tx_hash,
+  in_successful_contract_call, topics, topic_xdr, value_xdr, payload, payload_version, amount, parse_error, event_name)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+ON CONFLICT (id) DO UPDATE SET
+  topics = EXCLUDED.topics,
+  event_name = EXCLUDED.event_name,
+  payload = EXCLUDED.payload,
+  payload_version = EXCLUDED.payload_version,
+  amount = EXCLUDED.amount,
+  parse_error = EXCLUDED.parse_error,
+  indexed_at = now()
+`, e.ID, e.ContractID, e.Type, int64(e.Ledger), closedAt, e.TxHash,
+		e.InSuccessfulContractCall, string(topics), topicXDR, e.Value, payload, version, amount, parseErr, name)
+	if err != nil || typed == nil {
+		return err
+	}
+	if err := applyEscrowEvent(ctx, tx, e, typed, closedAt); err != nil {
+		return err
+	}
+	return applyProgramEvent(ctx, tx, e, typed)
+}
+
+// Replay rewinds the named indexer to fromLedger; its next poll re-reads events from there. Soroban
+// RPC only serves its retention window, so older ledgers fail until the cursor is moved forward.
+func Replay(ctx context.Context, pool *pgxpool.Pool, name string, fromLedger uint32) error {
+	if name == "" {
+		name = DefaultName
+	}
+	_, err := pool.Exec(ctx, `
+INSERT INTO soroban_indexer_cursors (name, start_ledger) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET start_ledger = EXCLUDED.start_ledger, cursor = NULL, updated_at = now()
+`, name, int64(fromLedger))
+	return err
+}
@@ -0,0 +1,40 @@
+package sorobanindexer
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// parseProgramEvent returns the program_id and remaining balance (base units) of a program escrow
+// funds event.
+func parseProgramEvent(t *soroban.TypedEvent) (programID, balance string, ok bool) {
+	if t == nil {
+		return "", "", false
+	}
+	switch data := t.Data.(type) {
+	case *soroban.ProgramFunded:
+		return data.ProgramID, data.RemainingBalance.String(), true
+	case *soroban.PayoutExecuted:
+		return data.ProgramID, data.RemainingBalance.String(), true
+	case *soroban.BatchPayoutExecuted:
+		return data.ProgramID, data.RemainingBalance.String(), true
+	}
+	return "", "", false
+}
+
+// applyProgramEvent records the escrow balance of the reward program tied to the event's
+// program_id. A replayed older event does not overwrite a newer balance.
+func applyProgramEvent(ctx context.Context, tx pgx.Tx, e soroban.Event, t *soroban.TypedEvent) error {
+	programID, balance, ok := parseProgramEvent(t)
+	if !ok || !e.InSuccessfulContractCall {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+UPDATE programs SET escrow_balance_units = $2::numeric, escrow_ledger = $3, updated_at = now()
+WHERE escrow_program_id = $1 AND (escrow_ledger IS NULL OR escrow_ledger <= $3)
+`, programID, balance, int64(e.Ledger))
+	return err
+}
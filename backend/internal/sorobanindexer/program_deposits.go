@@ -0,0 +1,216 @@
+package sorobanindexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/programs"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+// Reasons a program deposit was recorded without crediting the budget.
+const (
+	DepositProgramClosed   = "program_closed"
+	DepositAssetMismatch   = "asset_mismatch"
+	DepositUntrustedIssuer = "untrusted_issuer"
+)
+
+// ProgramDepositWatcher funds reward programs by deposit: it pages through the payments a program
+// deposit account received on Horizon, and a payment whose text memo is a program's deposit_memo is
+// recorded in program_deposits and, when it can be trusted, added to the program's budget. Every
+// recorded deposit is announced on the bus. Its paging token is kept in soroban_indexer_cursors.
+type ProgramDepositWatcher struct {
+	history      *stellar.History
+	pool         *pgxpool.Pool
+	bus          bus.Bus
+	account      string
+	pollInterval time.Duration
+}
+
+// NewProgramDepositWatcher returns a watcher of the payments to account. b may be nil.
+func NewProgramDepositWatcher(horizonURL, account string, pool *pgxpool.Pool, b bus.Bus, pollInterval time.Duration) *ProgramDepositWatcher {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	return &ProgramDepositWatcher{
+		history:      stellar.NewHistory(horizonURL),
+		pool:         pool,
+		bus:          b,
+		account:      account,
+		pollInterval: pollInterval,
+	}
+}
+
+func (w *ProgramDepositWatcher) cursorName() string { return "program-deposits:" + w.account }
+
+// Run polls until ctx is done, like Indexer.Run.
+func (w *ProgramDepositWatcher) Run(ctx context.Context) error {
+	slog.Info("program deposit watcher started", "account", w.account)
+	for {
+		advanced, err := w.Step(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("program deposit poll failed", "account", w.account, "error", err)
+		}
+		if err == nil && advanced {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// Step reads one page of payments after the stored paging token and reports whether there was
+// anything new to move past. The first run starts at the account's oldest payment.
+func (w *ProgramDepositWatcher) Step(ctx context.Context) (bool, error) {
+	var cursor *string
+	err := w.pool.QueryRow(ctx, `SELECT cursor FROM soroban_indexer_cursors WHERE name = $1`, w.cursorName()).Scan(&cursor)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+	after := ""
+	if cursor != nil {
+		after = *cursor
+	}
+	deposits, next, err := w.history.Deposits(ctx, w.account, after, defaultBatchSize)
+	if err != nil {
+		return false, err
+	}
+	if next == "" {
+		return false, nil
+	}
+
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var recorded []events.ProgramDeposit
+	var lastLedger int32
+	for _, d := range deposits {
+		e, ok, err := applyProgramDeposit(ctx, tx, w.account, d)
+		if err != nil {
+			return false, fmt.Errorf("apply payment %s: %w", d.OperationID, err)
+		}
+		if ok {
+			recorded = append(recorded, e)
+		}
+		lastLedger = max(lastLedger, d.Ledger)
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO soroban_indexer_cursors (name, start_ledger, cursor, last_ledger) VALUES ($1, 0, $2, NULLIF($3, 0))
+ON CONFLICT (name) DO UPDATE
+SET cursor = EXCLUDED.cursor, last_ledger = COALESCE(EXCLUDED.last_ledger, soroban_indexer_cursors.last_ledger), updated_at = now()
+`, w.cursorName(), next, int64(lastLedger)); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	for _, e := range recorded {
+		w.publish(ctx, e)
+	}
+	return true, nil
+}
+
+// publish announces a recorded deposit. Best effort: program_deposits already has it.
+func (w *ProgramDepositWatcher) publish(ctx context.Context, e events.ProgramDeposit) {
+	if w.bus == nil {
+		return
+	}
+	data, _ := json.Marshal(e)
+	if err := w.bus.Publish(ctx, events.SubjectProgramDeposit, data); err != nil {
+		slog.Warn("program deposit publish failed", "deposit_id", e.DepositID, "error", err)
+	}
+}
+
+// applyProgramDeposit records d when its memo names a program. It is credited to the program's
+// budget only while the program is draft or active and d is in the program's asset from the issuer
+// the assets table trusts for that code, so a look-alike token cannot inflate a budget. ok is false
+// when d is not a program deposit or was already recorded.
+func applyProgramDeposit(ctx context.Context, tx pgx.Tx, account string, d stellar.Deposit) (e events.ProgramDeposit, ok bool, err error) {
+	memo := strings.ToUpper(strings.TrimSpace(d.Memo))
+	if d.MemoType != "text" || memo == "" {
+		return e, false, nil
+	}
+	var programID uuid.UUID
+	var status, asset string
+	err = tx.QueryRow(ctx, `SELECT id, status, asset FROM programs WHERE deposit_memo = $1 FOR UPDATE`, memo).
+		Scan(&programID, &status, &asset)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, false, nil
+	}
+	if err != nil {
+		return e, false, err
+	}
+
+	reason := ""
+	switch {
+	case status != programs.StatusDraft && status != programs.StatusActive:
+		reason = DepositProgramClosed
+	case d.AssetCode != asset:
+		reason = DepositAssetMismatch
+	case d.AssetCode == stellar.NativeAsset:
+		if d.AssetIssuer != "" {
+			reason = DepositUntrustedIssuer
+		}
+	default:
+		var issuer *string
+		err := tx.QueryRow(ctx, `SELECT issuer FROM assets WHERE code = $1`, asset).Scan(&issuer)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return e, false, err
+		}
+		if issuer == nil || *issuer != d.AssetIssuer {
+			reason = DepositUntrustedIssuer
+		}
+	}
+
+	var depositID uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO program_deposits
+  (program_id, account, operation_id, tx_hash, from_account, amount, asset, asset_issuer, ledger, credited, reason, received_at)
+VALUES ($1, $2, $3, $4, $5, $6::numeric, $7, NULLIF($8, ''), NULLIF($9, 0), $10, NULLIF($11, ''), $12)
+ON CONFLICT (operation_id) DO NOTHING
+RETURNING id
+`, programID, account, d.OperationID, d.TxHash, d.From, d.Amount, d.AssetCode, d.AssetIssuer, int64(d.Ledger),
+		reason == "", reason, d.At).Scan(&depositID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, false, nil // already recorded on an earlier pass
+	}
+	if err != nil {
+		return e, false, err
+	}
+	e = events.ProgramDeposit{
+		ProgramID: programID.String(), DepositID: depositID.String(), TxHash: d.TxHash, From: d.From,
+		Amount: d.Amount, Asset: d.AssetCode, Credited: reason == "", Reason: reason,
+	}
+	if reason != "" {
+		slog.Warn("program deposit not credited", "program_id", programID, "tx_hash", d.TxHash, "reason", reason)
+		return e, true, nil
+	}
+	if err := tx.QueryRow(ctx, `
+UPDATE programs SET budget = budget + $2::numeric, deposited = deposited + $2::numeric, updated_at = now()
+WHERE id = $1
+RETURNING budget::text
+`, programID, d.Amount).Scan(&e.Budget); err != nil {
+		return e, false, err
+	}
+	slog.Info("program budget credited by deposit", "program_id", programID, "tx_hash", d.TxHash, "amount", d.Amount, "budget", e.Budget)
+	return e, true, nil
+}
@@ -0,0 +1,144 @@
+package stellar
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon/operations"
+)
+
+// Transfer is a payment operation as Horizon reports it: Amount of the asset (AssetIssuer empty for
+// XLM) from From to To. Path payments report what the destination received.
+type Transfer struct {
+	TxHash      string
+	From        string
+	To          string
+	Amount      string
+	AssetCode   string
+	AssetIssuer string
+	PagingToken string
+}
+
+// TxRecord is a transaction and the payments in it.
+type TxRecord struct {
+	TxStatus
+	Transfers []Transfer
+}
+
+// History reads settled transactions from Horizon, for reconciling payouts after the fact.
+type History struct {
+	horizon *horizonclient.Client
+}
+
+// NewHistory returns a History reading from horizonURL.
+func NewHistory(horizonURL string) *History {
+	return &History{horizon: &horizonclient.Client{HorizonURL: horizonURL, HTTP: &http.Client{Timeout: 30 * time.Second}}}
+}
+
+// Transaction looks up a transaction hash and its payments. Found is false when Horizon does not
+// know the hash.
+func (h *History) Transaction(_ context.Context, hash string) (TxRecord, error) {
+	tx, err := h.horizon.TransactionDetail(hash)
+	if horizonclient.IsNotFoundError(err) {
+		return TxRecord{}, nil
+	}
+	if err != nil {
+		return TxRecord{}, err
+	}
+	out := TxRecord{TxStatus: TxStatus{Found: true, Successful: tx.Successful, Ledger: tx.Ledger}}
+	page, err := h.horizon.Operations(horizonclient.OperationRequest{ForTransaction: hash, Limit: 200, IncludeFailed: true})
+	if err != nil {
+		return TxRecord{}, err
+	}
+	for _, op := range page.Embedded.Records {
+		if t, ok := transferOf(op); ok {
+			out.Transfers = append(out.Transfers, t)
+		}
+	}
+	return out, nil
+}
+
+// Payments returns up to limit successful payments sent from account after cursor, oldest first.
+// Payments the account received are skipped but still advance the returned cursor, which is ""
+// when there was nothing new.
+func (h *History) Payments(_ context.Context, account, cursor string, limit int) ([]Transfer, string, error) {
+	page, err := h.horizon.Payments(horizonclient.OperationRequest{
+		ForAccount: account, Cursor: cursor, Order: horizonclient.OrderAsc, Limit: uint(limit),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var out []Transfer
+	next := ""
+	for _, op := range page.Embedded.Records {
+		next = op.PagingToken()
+		t, ok := transferOf(op)
+		if ok && t.From == account && op.IsTransactionSuccessful() {
+			out = append(out, t)
+		}
+	}
+	return out, next, nil
+}
+
+func transferOf(op operations.Operation) (Transfer, bool) {
+	var p operations.Payment
+	switch o := op.(type) {
+	case operations.Payment:
+		p = o
+	case operations.PathPayment:
+		p = o.Payment
+	case operations.PathPaymentStrictSend:
+		p = o.Payment
+	case operations.CreateAccount:
+		return Transfer{TxHash: o.TransactionHash, From: o.Funder, To: o.Account, Amount: o.StartingBalance,
+			AssetCode: NativeAsset, PagingToken: o.PT}, true
+	default:
+		return Transfer{}, false
+	}
+	code := p.Asset.Code
+	if p.Asset.Type == "native" {
+		code = NativeAsset
+	}
+	return Transfer{TxHash: p.TransactionHash, From: p.From, To: p.To, Amount: p.Amount,
+		AssetCode: code, AssetIssuer: p.Asset.Issuer, PagingToken: p.PT}, true
+}
+
+// Deposit is a payment an account received, with the memo of its transaction.
+type Deposit struct {
+	Transfer
+	OperationID string
+	MemoType    string
+	Memo        string
+	Ledger      int32
+	At          time.Time
+}
+
+// Deposits returns up to limit successful payments sent to account after cursor, oldest first.
+// Like Payments, skipped operations still advance the returned cursor, which is "" when there was
+// nothing new.
+func (h *History) Deposits(_ context.Context, account, cursor string, limit int) ([]Deposit, string, error) {
+	page, err := h.horizon.Payments(horizonclient.OperationRequest{
+		ForAccount: account, Cursor: cursor, Order: horizonclient.OrderAsc, Limit: uint(limit), Join: "transactions",
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var out []Deposit
+	next := ""
+	for _, op := range page.Embedded.Records {
+		next = op.PagingToken()
+		t, ok := transferOf(op)
+		if !ok || t.To != account || !op.IsTransactionSuccessful() {
+			continue
+		}
+		base := op.GetBase()
+		d := Deposit{Transfer: t, OperationID: base.ID, At: base.LedgerCloseTime}
+		if tx := base.Transaction; tx != nil {
+			d.MemoType, d.Memo, d.Ledger = tx.MemoType, tx.Memo, tx.Ledger
+		}
+		out = append(out, d)
+	}
+	return out, next, nil
+}
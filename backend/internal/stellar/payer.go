@@ -0,0 +1,239 @@
+// Package stellar builds, signs, submits and confirms the Stellar payment transactions that settle
+// bounty payouts. A Payer pays from the custody account of its Signer; the sync worker drives it
+// over the payouts table.
+//
+// Each transaction is built with a time bound. Until the bound passes, a transaction whose fate is
+// unknown (the submit timed out) may still land, so it is re-submitted or looked up but never
+// rebuilt; after the bound it can no longer land and a new one is safe to build.
+package stellar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// NativeAsset is the code payouts use for lumens; it has no issuer.
+const NativeAsset = "XLM"
+
+const defaultTxTimeout = 5 * time.Minute
+
+// HorizonURL returns the public Horizon server of network ("testnet" or "mainnet").
+func HorizonURL(net string) string {
+	if net == "mainnet" {
+		return "https://horizon.stellar.org"
+	}
+	return "https://horizon-testnet.stellar.org"
+}
+
+// Passphrase returns the network passphrase of network ("testnet" or "mainnet").
+func Passphrase(net string) string {
+	if net == "mainnet" {
+		return network.PublicNetworkPassphrase
+	}
+	return network.TestNetworkPassphrase
+}
+
+// ParseIssuers reads a comma-separated list of CODE:ISSUER pairs, the assets other than XLM that
+// payouts may use.
+func ParseIssuers(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, issuer, ok := strings.Cut(pair, ":")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		issuer = strings.TrimSpace(issuer)
+		if !ok || code == "" || code == NativeAsset {
+			return nil, fmt.Errorf("invalid asset %q (want CODE:ISSUER)", pair)
+		}
+		if _, err := keypair.ParseAddress(issuer); err != nil {
+			return nil, fmt.Errorf("invalid issuer for %s: %w", code, err)
+		}
+		out[code] = issuer
+	}
+	return out, nil
+}
+
+// ValidAccount reports whether s is a Stellar account address (G...).
+func ValidAccount(s string) bool {
+	_, err := keypair.ParseAddress(s)
+	return err == nil && strings.HasPrefix(s, "G")
+}
+
+// Payment is one payout: Amount of the asset (AssetIssuer empty for XLM) to Destination.
+type Payment struct {
+	Destination string
+	Amount      string
+	AssetCode   string
+	AssetIssuer string
+	Memo        string
+}
+
+// Signed is a signed transaction ready to submit.
+type Signed struct {
+	Hash        string
+	EnvelopeXDR string
+	Sequence    int64
+	ValidUntil  time.Time
+}
+
+// TxStatus is what Horizon knows about a transaction hash.
+type TxStatus struct {
+	Found      bool
+	Successful bool
+	Ledger     int32
+}
+
+// SubmitError is a submission Horizon answered with result codes: the transaction did not succeed,
+// either rejected before a ledger or applied and failed. Permanent codes need a person (a missing
+// destination account or trustline, a bad signature); the rest may pass on a rebuilt transaction.
+type SubmitError struct {
+	Codes     []string
+	Permanent bool
+}
+
+func (e *SubmitError) Error() string {
+	return "transaction failed: " + strings.Join(e.Codes, ", ")
+}
+
+var permanentCodes = map[string]bool{
+	"tx_bad_auth":           true,
+	"tx_bad_auth_extra":     true,
+	"tx_no_source_account":  true,
+	"tx_malformed":          true,
+	"op_malformed":          true,
+	"op_no_destination":     true,
+	"op_no_trust":           true,
+	"op_not_authorized":     true,
+	"op_line_full":          true,
+	"op_no_issuer":          true,
+	"op_src_not_authorized": true,
+}
+
+// Payer pays from its signer's account through Horizon.
+type Payer struct {
+	horizon    *horizonclient.Client
+	passphrase string
+	signer     Signer
+	txTimeout  time.Duration
+}
+
+// NewPayer returns a Payer. txTimeout is how long each transaction stays valid (5 minutes when 0).
+func NewPayer(horizonURL, passphrase string, signer Signer, txTimeout time.Duration) *Payer {
+	if txTimeout <= 0 {
+		txTimeout = defaultTxTimeout
+	}
+	return &Payer{
+		horizon:    &horizonclient.Client{HorizonURL: horizonURL, HTTP: &http.Client{Timeout: 30 * time.Second}},
+		passphrase: passphrase,
+		signer:     signer,
+		txTimeout:  txTimeout,
+	}
+}
+
+// Source is the custody account payouts are paid from.
+func (p *Payer) Source() string { return p.signer.Address() }
+
+// Build loads the custody account's sequence and returns the signed payment transaction.
+func (p *Payer) Build(ctx context.Context, pay Payment, now time.Time) (Signed, error) {
+	account, err := p.horizon.AccountDetail(horizonclient.AccountRequest{AccountID: p.signer.Address()})
+	if err != nil {
+		return Signed{}, fmt.Errorf("load source account: %w", err)
+	}
+	return p.build(ctx, &account, pay, now)
+}
+
+func (p *Payer) build(ctx context.Context, source txnbuild.Account, pay Payment, now time.Time) (Signed, error) {
+	var asset txnbuild.Asset = txnbuild.NativeAsset{}
+	if pay.AssetIssuer != "" {
+		asset = txnbuild.CreditAsset{Code: pay.AssetCode, Issuer: pay.AssetIssuer}
+	} else if pay.AssetCode != NativeAsset {
+		return Signed{}, fmt.Errorf("asset %s has no issuer", pay.AssetCode)
+	}
+	validUntil := now.Add(p.txTimeout).Truncate(time.Second)
+	params := txnbuild.TransactionParams{
+		SourceAccount:        source,
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination: pay.Destination,
+			Amount:      pay.Amount,
+			Asset:       asset,
+		}},
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewTimebounds(0, validUntil.Unix())},
+	}
+	if pay.Memo != "" {
+		params.Memo = txnbuild.MemoText(pay.Memo)
+	}
+	tx, err := txnbuild.NewTransaction(params)
+	if err != nil {
+		return Signed{}, fmt.Errorf("build transaction: %w", err)
+	}
+	tx, err = p.signer.Sign(ctx, tx, p.passphrase)
+	if err != nil {
+		return Signed{}, fmt.Errorf("sign transaction: %w", err)
+	}
+	hash, err := tx.HashHex(p.passphrase)
+	if err != nil {
+		return Signed{}, err
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		return Signed{}, err
+	}
+	return Signed{Hash: hash, EnvelopeXDR: envelope, Sequence: tx.SequenceNumber(), ValidUntil: validUntil}, nil
+}
+
+// Submit sends a signed envelope and returns the ledger it was applied in. A *SubmitError means
+// the transaction did not succeed; any other error leaves its fate unknown.
+func (p *Payer) Submit(_ context.Context, envelopeXDR string) (int32, error) {
+	tx, err := p.horizon.SubmitTransactionXDR(envelopeXDR)
+	if err == nil {
+		return tx.Ledger, nil
+	}
+	herr := horizonclient.GetError(err)
+	if herr == nil {
+		return 0, err
+	}
+	codes, cerr := herr.ResultCodes()
+	if cerr != nil || codes == nil || codes.TransactionCode == "" {
+		return 0, err
+	}
+	se := &SubmitError{Codes: append([]string{codes.TransactionCode}, codes.OperationCodes...)}
+	for _, c := range se.Codes {
+		if permanentCodes[c] {
+			se.Permanent = true
+		}
+	}
+	return 0, se
+}
+
+// Status looks up a transaction hash on Horizon.
+func (p *Payer) Status(_ context.Context, hash string) (TxStatus, error) {
+	tx, err := p.horizon.TransactionDetail(hash)
+	if horizonclient.IsNotFoundError(err) {
+		return TxStatus{}, nil
+	}
+	if err != nil {
+		return TxStatus{}, err
+	}
+	return TxStatus{Found: true, Successful: tx.Successful, Ledger: tx.Ledger}, nil
+}
+
+// IsSubmitError reports whether err is a *SubmitError and returns it.
+func IsSubmitError(err error) (*SubmitError, bool) {
+	var se *SubmitError
+	ok := errors.As(err, &se)
+	return se, ok
+}
@@ -0,0 +1,115 @@
+package stellar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+func TestBuildSignsTimeBoundPayment(t *testing.T) {
+	custody := keypair.MustRandom()
+	dest := keypair.MustRandom()
+	signer, err := NewKeypairSigner(custody.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPayer("", network.TestNetworkPassphrase, signer, time.Minute)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	source := &txnbuild.SimpleAccount{AccountID: custody.Address(), Sequence: 41}
+
+	signed, err := p.build(context.Background(), source, Payment{Destination: dest.Address(), Amount: "250.5", AssetCode: NativeAsset}, now)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if signed.Sequence != 42 {
+		t.Errorf("sequence = %d, want 42", signed.Sequence)
+	}
+	if !signed.ValidUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("valid until = %s", signed.ValidUntil)
+	}
+	generic, err := txnbuild.TransactionFromXDR(signed.EnvelopeXDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, _ := generic.Transaction()
+	if hash, _ := tx.HashHex(network.TestNetworkPassphrase); hash != signed.Hash {
+		t.Errorf("hash mismatch: %s vs %s", hash, signed.Hash)
+	}
+	if len(tx.Signatures()) != 1 {
+		t.Errorf("signatures = %d, want 1", len(tx.Signatures()))
+	}
+	if tx.Timebounds().MaxTime != now.Add(time.Minute).Unix() {
+		t.Errorf("max time = %d", tx.Timebounds().MaxTime)
+	}
+
+	if _, err := p.build(context.Background(), source, Payment{Destination: dest.Address(), Amount: "1", AssetCode: "USDC"}, now); err == nil {
+		t.Error("credit asset without issuer accepted")
+	}
+}
+
+func TestRemoteSignerRejectsAlteredTransaction(t *testing.T) {
+	custody := keypair.MustRandom()
+	other := keypair.MustRandom()
+	tamper := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Passphrase  string `json:"network_passphrase"`
+			EnvelopeXDR string `json:"envelope_xdr"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		generic, _ := txnbuild.TransactionFromXDR(req.EnvelopeXDR)
+		tx, _ := generic.Transaction()
+		if tamper {
+			source := &txnbuild.SimpleAccount{AccountID: custody.Address(), Sequence: 99}
+			tx, _ = txnbuild.NewTransaction(txnbuild.TransactionParams{
+				SourceAccount: source, IncrementSequenceNum: true, BaseFee: txnbuild.MinBaseFee,
+				Operations:    []txnbuild.Operation{&txnbuild.Payment{Destination: other.Address(), Amount: "1000", Asset: txnbuild.NativeAsset{}}},
+				Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+			})
+		}
+		tx, _ = tx.Sign(req.Passphrase, custody)
+		env, _ := tx.Base64()
+		_ = json.NewEncoder(w).Encode(map[string]string{"envelope_xdr": env})
+	}))
+	defer srv.Close()
+
+	signer, err := NewRemoteSigner(srv.URL, "s3cret", custody.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPayer("", network.TestNetworkPassphrase, signer, 0)
+	pay := Payment{Destination: other.Address(), Amount: "10", AssetCode: NativeAsset}
+	source := &txnbuild.SimpleAccount{AccountID: custody.Address(), Sequence: 1}
+	if _, err := p.build(context.Background(), source, pay, time.Now()); err != nil {
+		t.Fatalf("remote sign: %v", err)
+	}
+	tamper = true
+	source = &txnbuild.SimpleAccount{AccountID: custody.Address(), Sequence: 1}
+	if _, err := p.build(context.Background(), source, pay, time.Now()); err == nil {
+		t.Fatal("altered transaction accepted")
+	}
+}
+
+func TestParseIssuers(t *testing.T) {
+	issuer := keypair.MustRandom().Address()
+	got, err := ParseIssuers(" usdc:" + issuer + " ,")
+	if err != nil || got["USDC"] != issuer || len(got) != 1 {
+		t.Fatalf("ParseIssuers = %v, %v", got, err)
+	}
+	for _, bad := range []string{"USDC", "XLM:" + issuer, "USDC:nope"} {
+		if _, err := ParseIssuers(bad); err == nil {
+			t.Errorf("ParseIssuers(%q) accepted", bad)
+		}
+	}
+}
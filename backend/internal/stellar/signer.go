@@ -0,0 +1,160 @@
+package stellar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// Signer kinds accepted by NewSigner.
+const (
+	SignerKeypair = "keypair"
+	SignerRemote  = "remote"
+)
+
+// Signer signs payout transactions for the custody account at Address. The engine never needs the
+// secret itself, so custody can live in a separate signing service.
+type Signer interface {
+	Address() string
+	Sign(ctx context.Context, tx *txnbuild.Transaction, passphrase string) (*txnbuild.Transaction, error)
+}
+
+// SignerConfig selects and configures a Signer.
+type SignerConfig struct {
+	// Kind is SignerKeypair (Secret holds the custody secret seed) or SignerRemote (URL, Token,
+	// Account).
+	Kind    string
+	Secret  string
+	URL     string
+	Token   string
+	Account string
+}
+
+// NewSigner builds the signer described by cfg. It returns nil, nil when Kind is empty: payouts are
+// off.
+func NewSigner(cfg SignerConfig) (Signer, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Kind)) {
+	case "":
+		return nil, nil
+	case SignerKeypair:
+		return NewKeypairSigner(cfg.Secret)
+	case SignerRemote:
+		return NewRemoteSigner(cfg.URL, cfg.Token, cfg.Account)
+	default:
+		return nil, fmt.Errorf("unknown payout signer %q", cfg.Kind)
+	}
+}
+
+// KeypairSigner signs with a secret seed held by this process.
+type KeypairSigner struct {
+	kp *keypair.Full
+}
+
+func NewKeypairSigner(secret string) (*KeypairSigner, error) {
+	kp, err := keypair.ParseFull(strings.TrimSpace(secret))
+	if err != nil {
+		return nil, fmt.Errorf("invalid payout source secret: %w", err)
+	}
+	return &KeypairSigner{kp: kp}, nil
+}
+
+func (s *KeypairSigner) Address() string { return s.kp.Address() }
+
+func (s *KeypairSigner) Sign(_ context.Context, tx *txnbuild.Transaction, passphrase string) (*txnbuild.Transaction, error) {
+	return tx.Sign(passphrase, s.kp)
+}
+
+// RemoteSigner sends each transaction to a custody service, which answers with the same
+// transaction signed:
+//
+//	POST <URL>  {"network_passphrase": "...", "envelope_xdr": "..."}
+//	200         {"envelope_xdr": "..."}
+//
+// The service authenticates requests with the bearer Token. A response whose transaction hash
+// differs from the request's is rejected.
+type RemoteSigner struct {
+	url     string
+	token   string
+	account string
+	http    *http.Client
+}
+
+func NewRemoteSigner(url, token, account string) (*RemoteSigner, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil, fmt.Errorf("payout signer url is required")
+	}
+	if _, err := keypair.ParseAddress(strings.TrimSpace(account)); err != nil {
+		return nil, fmt.Errorf("invalid payout source account: %w", err)
+	}
+	return &RemoteSigner{
+		url:     url,
+		token:   strings.TrimSpace(token),
+		account: strings.TrimSpace(account),
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *RemoteSigner) Address() string { return s.account }
+
+func (s *RemoteSigner) Sign(ctx context.Context, tx *txnbuild.Transaction, passphrase string) (*txnbuild.Transaction, error) {
+	envelope, err := tx.Base64()
+	if err != nil {
+		return nil, err
+	}
+	wantHash, err := tx.HashHex(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := json.Marshal(map[string]string{"network_passphrase": passphrase, "envelope_xdr": envelope})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("payout signer: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("payout signer: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	var out struct {
+		EnvelopeXDR string `json:"envelope_xdr"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil || out.EnvelopeXDR == "" {
+		return nil, fmt.Errorf("payout signer: invalid response")
+	}
+	generic, err := txnbuild.TransactionFromXDR(out.EnvelopeXDR)
+	if err != nil {
+		return nil, fmt.Errorf("payout signer: %w", err)
+	}
+	signed, ok := generic.Transaction()
+	if !ok {
+		return nil, fmt.Errorf("payout signer: fee bump transactions are not supported")
+	}
+	gotHash, err := signed.HashHex(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if gotHash != wantHash {
+		return nil, fmt.Errorf("payout signer returned a different transaction")
+	}
+	if len(signed.Signatures()) == 0 {
+		return nil, fmt.Errorf("payout signer returned no signature")
+	}
+	return signed, nil
+}
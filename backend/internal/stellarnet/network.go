@@ -0,0 +1,167 @@
+// Package stellarnet resolves the Stellar network a deployment runs on (SOROBAN_NETWORK, testnet or
+// mainnet) and keeps the rest of the chain configuration consistent with it: the passphrase, the
+// Horizon and Soroban RPC endpoints and the contract IDs. Validate rejects configurations that mix
+// networks or run mainnet outside production, and Bind pins a database to one network, so a staging
+// deployment on testnet can share the codebase but never the chain data of production.
+package stellarnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stellar/go/strkey"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+const (
+	Testnet = "testnet"
+	Mainnet = "mainnet"
+)
+
+// Network is the resolved chain configuration of a deployment.
+type Network struct {
+	Name       string
+	Passphrase string
+	HorizonURL string
+	// RPCURL is SOROBAN_RPC_URL; empty when nothing talks to Soroban.
+	RPCURL string
+	// ContractIDs are the escrow contracts and the contracts the indexer watches.
+	ContractIDs []string
+}
+
+// FromConfig resolves the network in cfg. The passphrase defaults to Name's, and Horizon to
+// PAYOUT_HORIZON_URL or else Name's public Horizon.
+func FromConfig(cfg config.Config) Network {
+	name := strings.ToLower(strings.TrimSpace(cfg.SorobanNetwork))
+	if name == "" {
+		name = Testnet
+	}
+	n := Network{
+		Name:       name,
+		Passphrase: strings.TrimSpace(cfg.SorobanNetworkPassphrase),
+		HorizonURL: strings.TrimSpace(cfg.PayoutHorizonURL),
+		RPCURL:     strings.TrimSpace(cfg.SorobanRPCURL),
+	}
+	if n.Passphrase == "" {
+		n.Passphrase = stellar.Passphrase(name)
+	}
+	if n.HorizonURL == "" {
+		n.HorizonURL = stellar.HorizonURL(name)
+	}
+	seen := map[string]bool{}
+	for _, id := range append(cfg.IndexedContractIDs(), cfg.EscrowContractID, cfg.ProgramEscrowContractID) {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			n.ContractIDs = append(n.ContractIDs, id)
+		}
+	}
+	return n
+}
+
+// Production reports whether env (APP_ENV) is a production environment.
+func Production(env string) bool {
+	env = strings.ToLower(strings.TrimSpace(env))
+	return env == "production" || env == "prod"
+}
+
+// Validate checks that the configuration is one network throughout and allowed in env: mainnet
+// runs only in production, the passphrase and endpoints must not belong to the other network, and
+// contract IDs must be contract addresses. It reports every problem at once.
+func (n Network) Validate(env string) error {
+	var errs []error
+	switch n.Name {
+	case Testnet, Mainnet:
+	default:
+		return fmt.Errorf("SOROBAN_NETWORK %q: want %s or %s", n.Name, Testnet, Mainnet)
+	}
+	if n.Name == Mainnet && !Production(env) {
+		errs = append(errs, fmt.Errorf("SOROBAN_NETWORK=mainnet needs APP_ENV=production (APP_ENV is %q); other environments run on testnet", env))
+	}
+	if n.Passphrase != stellar.Passphrase(n.Name) {
+		errs = append(errs, fmt.Errorf("SOROBAN_NETWORK_PASSPHRASE is not the %s passphrase", n.Name))
+	}
+	for _, e := range []struct{ name, url string }{{"PAYOUT_HORIZON_URL", n.HorizonURL}, {"SOROBAN_RPC_URL", n.RPCURL}} {
+		if other := EndpointNetwork(e.url); other != "" && other != n.Name {
+			errs = append(errs, fmt.Errorf("%s %s is a %s endpoint, but SOROBAN_NETWORK is %s", e.name, e.url, other, n.Name))
+		}
+	}
+	for _, id := range n.ContractIDs {
+		if _, err := strkey.Decode(strkey.VersionByteContract, id); err != nil {
+			errs = append(errs, fmt.Errorf("contract id %q is not a contract address", id))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mainnetHosts are public mainnet endpoints whose names do not say so.
+var mainnetHosts = map[string]bool{
+	"horizon.stellar.org": true,
+}
+
+// EndpointNetwork guesses the network of a Horizon or RPC URL from its host name: testnet for hosts
+// naming testnet (or futurenet, which is never mainnet), mainnet for the well-known public mainnet
+// hosts and hosts naming mainnet or pubnet. It returns "" when the host does not tell.
+func EndpointNetwork(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.Contains(host, "testnet") || strings.Contains(host, "futurenet"):
+		return Testnet
+	case mainnetHosts[host] || strings.Contains(host, "mainnet") || strings.Contains(host, "pubnet"):
+		return Mainnet
+	}
+	return ""
+}
+
+// taggedTables hold chain data. Their network column defaults to the database's bound network.
+var taggedTables = []string{
+	"soroban_events", "soroban_indexer_cursors", "bounty_escrows", "payouts", "payout_addresses",
+	"reconciliation_issues", "program_deposits", "assets",
+}
+
+// ErrNetworkMismatch is returned by Bind when the database holds another network's chain data.
+var ErrNetworkMismatch = errors.New("database is bound to another stellar network")
+
+// Bind pins the database to n. The first binary started on a database records n in stellar_network
+// and tags the chain rows written before networks were tracked; afterwards a binary configured for
+// another network gets ErrNetworkMismatch and must not start.
+func (n Network) Bind(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	tag, err := tx.Exec(ctx, `
+INSERT INTO stellar_network (network, passphrase) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING
+`, n.Name, n.Passphrase)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 1 {
+		for _, table := range taggedTables {
+			if _, err := tx.Exec(ctx, `UPDATE `+table+` SET network = $1 WHERE network IS NULL`, n.Name); err != nil {
+				return fmt.Errorf("tag %s: %w", table, err)
+			}
+		}
+		slog.Info("database bound to stellar network", "network", n.Name)
+		return tx.Commit(ctx)
+	}
+	var bound string
+	if err := tx.QueryRow(ctx, `SELECT network FROM stellar_network`).Scan(&bound); err != nil {
+		return err
+	}
+	if bound != n.Name {
+		return fmt.Errorf("%w: database holds %s data, configured for %s", ErrNetworkMismatch, bound, n.Name)
+	}
+	return nil
+}
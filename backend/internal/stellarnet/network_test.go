@@ -0,0 +1,66 @@
+package stellarnet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+func TestFromConfigDefaults(t *testing.T) {
+	n := FromConfig(config.Config{SorobanNetwork: "Mainnet"})
+	if n.Name != Mainnet || n.Passphrase != network.PublicNetworkPassphrase || n.HorizonURL != "https://horizon.stellar.org" {
+		t.Fatalf("FromConfig = %+v", n)
+	}
+	if n := FromConfig(config.Config{}); n.Name != Testnet || n.Passphrase != network.TestNetworkPassphrase {
+		t.Fatalf("FromConfig default = %+v", n)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	contract, err := strkey.Encode(strkey.VersionByteContract, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	staging := config.Config{
+		SorobanNetwork:   Testnet,
+		SorobanRPCURL:    "https://soroban-testnet.stellar.org",
+		EscrowContractID: contract,
+	}
+	if err := FromConfig(staging).Validate("staging"); err != nil {
+		t.Fatalf("testnet staging: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		env  string
+		edit func(*config.Config)
+		want string
+	}{
+		{"mainnet outside production", "staging", func(c *config.Config) {
+			c.SorobanNetwork, c.SorobanRPCURL = Mainnet, "https://rpc.example.com"
+		}, "APP_ENV=production"},
+		{"passphrase of other network", "dev", func(c *config.Config) { c.SorobanNetworkPassphrase = network.PublicNetworkPassphrase }, "passphrase"},
+		{"mainnet horizon on testnet", "dev", func(c *config.Config) { c.PayoutHorizonURL = "https://horizon.stellar.org" }, "PAYOUT_HORIZON_URL"},
+		{"testnet rpc on mainnet", "production", func(c *config.Config) { c.SorobanNetwork = Mainnet }, "SOROBAN_RPC_URL"},
+		{"bad contract id", "dev", func(c *config.Config) { c.ProgramEscrowContractID = "GABC" }, "contract id"},
+		{"unknown network", "dev", func(c *config.Config) { c.SorobanNetwork = "futurenet" }, "SOROBAN_NETWORK"},
+	}
+	for _, tc := range cases {
+		cfg := staging
+		tc.edit(&cfg)
+		err := FromConfig(cfg).Validate(tc.env)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate = %v, want error mentioning %q", tc.name, err, tc.want)
+		}
+	}
+
+	prod := staging
+	prod.SorobanNetwork, prod.SorobanRPCURL = Mainnet, "https://mainnet.sorobanrpc.com"
+	if err := FromConfig(prod).Validate("production"); err != nil {
+		t.Fatalf("mainnet production: %v", err)
+	}
+}
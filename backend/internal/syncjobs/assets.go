@@ -0,0 +1,42 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+// refreshAssets adds the assets the configuration names (XLM, PAYOUT_ASSET_ISSUERS, ESCROW_ASSET)
+// to the assets table and, when ASSET_PRICE_SOURCE is set, refreshes their USD prices.
+func (w *Worker) refreshAssets(ctx context.Context) {
+	codes := map[string]string{stellar.NativeAsset: ""}
+	issuers, err := stellar.ParseIssuers(w.cfg.PayoutAssetIssuers)
+	if err != nil {
+		slog.Warn("assets: invalid PAYOUT_ASSET_ISSUERS", "error", err)
+	}
+	for code, issuer := range issuers {
+		codes[code] = issuer
+	}
+	if code := w.cfg.EscrowAsset; code != "" {
+		if _, ok := codes[code]; !ok {
+			codes[code] = ""
+		}
+	}
+	if err := assets.Sync(ctx, w.pool, codes); err != nil {
+		slog.Warn("assets: sync failed", "error", err)
+		return
+	}
+
+	if w.prices == nil {
+		return
+	}
+	n, err := assets.RefreshPrices(ctx, w.pool, w.prices, w.cfg.AssetPriceMaxAge, time.Now().UTC())
+	if err != nil {
+		slog.Warn("assets: price refresh failed", "source", w.prices.Name(), "error", err)
+		return
+	}
+	slog.Debug("assets: prices refreshed", "source", w.prices.Name(), "assets", n)
+}
@@ -0,0 +1,54 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// pageWriter collects the upserts for one page of a list sync and writes them in a single
+// round-trip, instead of one Exec per item.
+type pageWriter struct {
+	batch  pgx.Batch
+	labels []string // per queued statement, e.g. "issue #12", for failure logs
+}
+
+func (pw *pageWriter) queue(label string, sql string, args ...any) {
+	pw.batch.Queue(sql, args...)
+	pw.labels = append(pw.labels, label)
+}
+
+// flush sends the queued statements. A pipelined batch runs as one implicit transaction, so a
+// single bad row would roll back the whole page: when the batch fails, the statements are replayed
+// one by one and only the failing rows are logged and skipped, as the per-item upserts did.
+func (pw *pageWriter) flush(ctx context.Context, w *Worker, projectID uuid.UUID) {
+	n := pw.batch.Len()
+	if n == 0 {
+		return
+	}
+	start := time.Now()
+	err := w.pool.SendBatch(ctx, &pw.batch).Close()
+	if err != nil {
+		slog.Warn("sync page batch failed, retrying rows individually",
+			"project_id", projectID,
+			"rows", n,
+			"error", err,
+		)
+		for i, q := range pw.batch.QueuedQueries {
+			if _, err := w.pool.Exec(ctx, q.SQL, q.Arguments...); err != nil {
+				slog.Warn("sync upsert failed", "project_id", projectID, "item", pw.labels[i], "error", err)
+			}
+		}
+	}
+	slog.Debug("sync page written",
+		"project_id", projectID,
+		"rows", n,
+		"batched", err == nil,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	pw.batch = pgx.Batch{}
+	pw.labels = pw.labels[:0]
+}
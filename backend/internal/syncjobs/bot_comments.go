@@ -0,0 +1,183 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// postBotComments posts the project's pending bot comments as the GitHub App. Comments GitHub
+// refuses outright (issue gone, locked, app lacks access) are marked failed; anything else fails
+// the job so it is retried.
+//
+// A comment is marked posting before the request goes out and carries a hidden marker. A comment
+// still posting when a job picks it up may already be on GitHub (the worker died, or the request
+// timed out after GitHub created it), so the issue is searched for the marker before posting again.
+func (w *Worker) postBotComments(ctx context.Context, projectID uuid.UUID, fullName string) error {
+	var enabled bool
+	var installationID string
+	if err := w.pool.QueryRow(ctx, `
+SELECT bot_comments_enabled, COALESCE(github_app_installation_id, '') FROM projects WHERE id = $1
+`, projectID).Scan(&enabled, &installationID); err != nil {
+		return err
+	}
+	if !enabled || installationID == "" {
+		// Opted out (or uninstalled) since the comments were queued.
+		_, err := w.pool.Exec(ctx, `
+UPDATE github_bot_comments SET status = 'failed', last_error = 'bot_comments_disabled'
+WHERE project_id = $1 AND status IN ('pending', 'posting')
+`, projectID)
+		return err
+	}
+
+	rows, err := w.pool.Query(ctx, `
+SELECT c.id, c.issue_number, c.kind, c.status, gi.github_issue_id
+FROM github_bot_comments c
+LEFT JOIN github_issues gi ON gi.project_id = c.project_id AND gi.number = c.issue_number
+WHERE c.project_id = $1 AND c.status IN ('pending', 'posting')
+ORDER BY c.created_at
+LIMIT 50
+`, projectID)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		id            uuid.UUID
+		number        int
+		kind          string
+		status        string
+		githubIssueID *int64
+	}
+	var items []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.number, &p.kind, &p.status, &p.githubIssueID); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, p := range items {
+		body, ok := w.botCommentBody(projectID, p.kind, p.githubIssueID)
+		if !ok {
+			_, _ = w.pool.Exec(ctx, `UPDATE github_bot_comments SET status = 'failed', last_error = 'unknown_kind' WHERE id = $1`, p.id)
+			continue
+		}
+		marker := botCommentMarker(p.id)
+		if p.status == "posting" {
+			if err := w.limiter.Wait(ctx); err != nil {
+				return err
+			}
+			commentID, found, err := w.findBotComment(ctx, installationID, fullName, p.number, marker)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if found {
+				w.markBotCommentPosted(ctx, p.id, commentID)
+				slog.Info("bot comment already posted by an earlier attempt", "project_id", projectID, "issue_number", p.number, "kind", p.kind)
+				continue
+			}
+		}
+		if _, err := w.pool.Exec(ctx, `
+UPDATE github_bot_comments SET status = 'posting', job_key = $2 WHERE id = $1
+`, p.id, jobKey(ctx)); err != nil {
+			return err
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		comment, err := w.gh.PostIssueComment(ctx, w.apps, installationID, fullName, p.number, body+"\n\n"+marker)
+		if err != nil {
+			// Left posting unless GitHub definitely refused it: the comment may exist anyway.
+			status := "posting"
+			if githubRefused(err) {
+				status = "failed"
+			} else {
+				lastErr = err
+			}
+			_, _ = w.pool.Exec(ctx, `UPDATE github_bot_comments SET status = $2, last_error = $3 WHERE id = $1`, p.id, status, err.Error())
+			slog.Warn("bot comment post failed",
+				"project_id", projectID,
+				"repo", fullName,
+				"issue_number", p.number,
+				"kind", p.kind,
+				"error", err,
+			)
+			continue
+		}
+		w.markBotCommentPosted(ctx, p.id, comment.ID)
+	}
+	return lastErr
+}
+
+func (w *Worker) markBotCommentPosted(ctx context.Context, id uuid.UUID, githubCommentID int64) {
+	_, _ = w.pool.Exec(ctx, `
+UPDATE github_bot_comments
+SET status = 'posted', github_comment_id = $2, last_error = NULL, posted_at = now()
+WHERE id = $1
+`, id, githubCommentID)
+}
+
+// botCommentMarker is appended to every bot comment (invisible when rendered) so a comment whose
+// post outcome is unknown can be found on the issue.
+func botCommentMarker(id uuid.UUID) string {
+	return "<!-- grainlify-bot-comment:" + id.String() + " -->"
+}
+
+// findBotComment looks for a comment carrying marker on the issue.
+func (w *Worker) findBotComment(ctx context.Context, installationID string, fullName string, number int, marker string) (int64, bool, error) {
+	token, err := w.apps.Token(ctx, installationID)
+	if err != nil {
+		return 0, false, err
+	}
+	comments, err := w.gh.ListIssueComments(ctx, token, fullName, number)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, c := range comments {
+		if strings.Contains(c.Body, marker) {
+			return c.ID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// botCommentBody renders the comment text for kind.
+func (w *Worker) botCommentBody(projectID uuid.UUID, kind string, githubIssueID *int64) (string, bool) {
+	switch kind {
+	case "issue_listed":
+		return "This issue is listed on [Grainlify](" + w.issueLink(projectID, githubIssueID) + "). Contributors can apply to work on it there.", true
+	}
+	return "", false
+}
+
+// issueLink is the issue's page on the frontend.
+func (w *Worker) issueLink(projectID uuid.UUID, githubIssueID *int64) string {
+	link := strings.TrimRight(strings.TrimSpace(w.cfg.FrontendBaseURL), "/") + "/dashboard?tab=browse&project=" + projectID.String()
+	if githubIssueID != nil {
+		link += fmt.Sprintf("&issue=%d", *githubIssueID)
+	}
+	return link
+}
+
+// githubRefused reports whether GitHub definitely rejected the request (issue gone, locked, app
+// lacks access), so retrying it would not help.
+func githubRefused(err error) bool {
+	var apiErr *github.GitHubAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		apiErr.StatusCode != http.StatusUnauthorized && apiErr.StatusCode != http.StatusTooManyRequests
+}
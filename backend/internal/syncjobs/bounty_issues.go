@@ -0,0 +1,287 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// Labels kept on a bounty's GitHub issue: bountyLabel while the bounty can still be earned,
+// bountyPaidLabel once it was paid.
+const (
+	bountyLabel     = "bounty"
+	bountyPaidLabel = "bounty: paid"
+)
+
+// bountySyncLease is how long a worker keeps a bounty to itself while updating its issue. An
+// attempt that dies leaves the lease behind; the bounty is taken again once it runs out.
+const bountySyncLease = 5 * time.Minute
+
+// bountyIssue is what SyncBountyIssue needs to know about a bounty.
+type bountyIssue struct {
+	id             uuid.UUID
+	status         string
+	amount         string
+	asset          string
+	claimant       *string
+	prURL          *string
+	commentID      *int64
+	projectID      uuid.UUID
+	fullName       string
+	installationID string
+	number         int
+	githubIssueID  *int64
+	leasedAt       time.Time
+}
+
+// SyncBountyIssue mirrors a bounty's status onto its GitHub issue as the GitHub App: one comment,
+// posted once and then edited, describing the bounty, and the bounty labels. Only projects with bot
+// comments enabled are touched, and nothing is done when the issue already shows the status or
+// another worker holds the bounty's lease. GitHub refusing the update outright is recorded in
+// github_sync_error and not retried until the status changes again; other errors are returned and
+// the sweep retries the bounty.
+func (w *Worker) SyncBountyIssue(ctx context.Context, bountyID uuid.UUID) error {
+	if w.apps == nil {
+		return nil
+	}
+
+	b := bountyIssue{id: bountyID}
+	// Taking the lease keeps a second worker (the bus consumer and the sweep) from posting the
+	// comment twice, without holding the row while GitHub is called.
+	err := w.pool.QueryRow(ctx, `
+UPDATE bounties b
+SET github_sync_started_at = now()
+FROM projects p, github_issues gi
+WHERE b.id = $1 AND p.id = b.project_id AND gi.id = b.issue_id
+  AND b.github_synced_status IS DISTINCT FROM b.status
+  AND (b.github_sync_started_at IS NULL OR b.github_sync_started_at < now() - make_interval(secs => $2))
+  AND p.bot_comments_enabled AND p.github_app_installation_id IS NOT NULL AND p.deleted_at IS NULL
+RETURNING b.status, b.amount::text, b.asset, b.claimant_login, b.claim_pr_url, b.github_comment_id,
+          p.id, p.github_full_name, p.github_app_installation_id, gi.number, gi.github_issue_id,
+          b.github_sync_started_at
+`, bountyID, bountySyncLease.Seconds()).Scan(&b.status, &b.amount, &b.asset, &b.claimant, &b.prURL, &b.commentID,
+		&b.projectID, &b.fullName, &b.installationID, &b.number, &b.githubIssueID, &b.leasedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	commentID, syncErr := w.syncBountyIssueLeased(ctx, b)
+	var apiErr *github.GitHubAPIError
+	if errors.As(syncErr, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		w.apps.Forget(b.installationID)
+	}
+	refused := syncErr != nil && githubRefused(syncErr)
+	var lastError *string
+	if syncErr != nil {
+		msg := syncErr.Error()
+		lastError = &msg
+	}
+	// The issue now shows the status read with the lease; when the bounty moved on since, it still
+	// differs from status and is synced again. The comment ID is kept even when the labels failed,
+	// so a retry edits the comment. A lease that ran out and was taken by another worker is left to
+	// that worker.
+	var syncedStatus *string
+	if syncErr == nil || refused {
+		syncedStatus = &b.status
+	}
+	// A cancelled ctx must not keep the lease until it runs out.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if _, err := w.pool.Exec(writeCtx, `
+UPDATE bounties
+SET github_comment_id = COALESCE($2, github_comment_id), github_sync_error = $3,
+    github_synced_status = COALESCE($4, github_synced_status), github_synced_at = now(),
+    github_sync_started_at = NULL
+WHERE id = $1 AND github_sync_started_at = $5
+`, bountyID, commentID, lastError, syncedStatus, b.leasedAt); err != nil {
+		return err
+	}
+	if refused {
+		slog.Warn("bounty issue update refused by github",
+			"bounty_id", bountyID,
+			"repo", b.fullName,
+			"issue_number", b.number,
+			"status", b.status,
+			"error", syncErr,
+		)
+		return nil
+	}
+	return syncErr
+}
+
+// syncBountyIssueLeased renders b's comment and writes it to the issue. It runs outside any
+// transaction, under the lease SyncBountyIssue took.
+func (w *Worker) syncBountyIssueLeased(ctx context.Context, b bountyIssue) (*int64, error) {
+	var meta *assets.Asset
+	a, err := assets.Scan(w.pool.QueryRow(ctx, `SELECT `+assets.Columns+` FROM assets WHERE code = $1`, b.asset))
+	switch {
+	case err == nil:
+		meta = &a
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+	value := assets.NewValue(b.amount, b.asset, meta, time.Now())
+	return w.writeBountyIssue(ctx, b, w.bountyIssueComment(b, value))
+}
+
+// writeBountyIssue puts body on the issue and sets the labels for b's status. It returns the
+// comment's ID when the comment is new to the bounty row (nil otherwise).
+func (w *Worker) writeBountyIssue(ctx context.Context, b bountyIssue, body string) (*int64, error) {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	token, err := w.apps.Token(ctx, b.installationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *int64
+	marker := bountyCommentMarker(b.id)
+	body += "\n\n" + marker
+	edited := false
+	if b.commentID != nil {
+		err := w.gh.UpdateIssueComment(ctx, token, b.fullName, *b.commentID, body)
+		var apiErr *github.GitHubAPIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+			return nil, err
+		}
+		// A comment someone deleted is posted again.
+		edited = err == nil
+	} else {
+		// A comment posted by an attempt that died before recording it carries the marker.
+		id, found, err := w.findBotComment(ctx, b.installationID, b.fullName, b.number, marker)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if err := w.gh.UpdateIssueComment(ctx, token, b.fullName, id, body); err != nil {
+				return &id, err
+			}
+			created, edited = &id, true
+		}
+	}
+	if !edited {
+		comment, err := w.gh.CreateIssueComment(ctx, token, b.fullName, b.number, body)
+		if err != nil {
+			return nil, err
+		}
+		created = &comment.ID
+	}
+
+	add, remove := bountyLabels(b.status)
+	if len(add) > 0 {
+		if err := w.gh.AddIssueLabels(ctx, token, b.fullName, b.number, add); err != nil {
+			return created, err
+		}
+	}
+	for _, label := range remove {
+		if err := w.gh.RemoveIssueLabel(ctx, token, b.fullName, b.number, label); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// bountyLabels returns the labels an issue gains and loses when its bounty reaches status.
+func bountyLabels(status string) (add, remove []string) {
+	switch status {
+	case bounties.StatusOpen, bounties.StatusClaimed, bounties.StatusPendingPayout:
+		return []string{bountyLabel}, nil
+	case bounties.StatusPaid:
+		return []string{bountyPaidLabel}, []string{bountyLabel}
+	case bounties.StatusCancelled:
+		return nil, []string{bountyLabel}
+	}
+	return nil, nil
+}
+
+// bountyCommentMarker is appended to the bounty comment (invisible when rendered) so a comment
+// whose ID was never recorded can be found on the issue.
+func bountyCommentMarker(id uuid.UUID) string {
+	return "<!-- grainlify-bounty:" + id.String() + " -->"
+}
+
+// bountyIssueComment renders the bounty comment for b's status.
+func (w *Worker) bountyIssueComment(b bountyIssue, value assets.Value) string {
+	link := w.issueLink(b.projectID, b.githubIssueID)
+	claimant := "a contributor"
+	if b.claimant != nil && *b.claimant != "" {
+		claimant = "@" + *b.claimant
+	}
+	var status string
+	switch b.status {
+	case bounties.StatusOpen:
+		status = "Open. Fix this issue in a pull request that references it, then claim the bounty on [Grainlify](" + link + ") once the pull request is merged."
+	case bounties.StatusClaimed:
+		status = "Claimed by " + claimant + ". Follow it on [Grainlify](" + link + ")."
+	case bounties.StatusPendingPayout:
+		status = "Claimed by " + claimant
+		if b.prURL != nil && *b.prURL != "" {
+			status += " with " + *b.prURL
+		}
+		status += ". The payout is waiting for the maintainers."
+	case bounties.StatusPaid:
+		status = "Paid to " + claimant + ". Thank you!"
+	case bounties.StatusCancelled:
+		status = "Cancelled by the maintainers."
+	default:
+		status = b.status
+	}
+	return fmt.Sprintf("**Bounty: %s**\n\nStatus: %s", value.Display, status)
+}
+
+// syncBountyIssues updates the GitHub issues of bounties whose status moved without the issue
+// following: bus events that were missed or never sent (no bus, or a change made by the worker or
+// the indexer), and earlier attempts that failed. Every attempt sets github_synced_at, so bounties
+// that keep failing go to the back and the rest still get their turn.
+func (w *Worker) syncBountyIssues(ctx context.Context) {
+	if w.apps == nil {
+		return
+	}
+	rows, err := w.pool.Query(ctx, `
+SELECT b.id
+FROM bounties b
+JOIN projects p ON p.id = b.project_id
+WHERE b.github_synced_status IS DISTINCT FROM b.status
+  AND (b.github_sync_started_at IS NULL OR b.github_sync_started_at < now() - make_interval(secs => $1))
+  AND p.bot_comments_enabled AND p.github_app_installation_id IS NOT NULL AND p.deleted_at IS NULL
+ORDER BY b.github_synced_at NULLS FIRST, b.updated_at
+LIMIT 20
+`, bountySyncLease.Seconds())
+	if err != nil {
+		slog.Warn("bounty issue sweep failed", "error", err)
+		return
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			slog.Warn("bounty issue sweep failed", "error", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	for _, id := range ids {
+		if err := w.SyncBountyIssue(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("bounty issue sync failed", "bounty_id", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package syncjobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// checkpoint is a list sync's progress, saved on the job after every page. Cursor is the page to
+// fetch next ("" once the walk is done). It is only resumed by a run with the same resource and
+// since, i.e. a retry of the same pass.
+type checkpoint struct {
+	Resource      string    `json:"resource"`
+	Since         time.Time `json:"since"`
+	Cursor        string    `json:"cursor"`
+	PagesDone     int       `json:"pages_done"`
+	ItemsUpserted int       `json:"items_upserted"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	jobID uuid.UUID
+}
+
+// resumable reports whether the checkpoint still has pages to fetch from a previous attempt.
+func (cp *checkpoint) resumable() bool {
+	return cp.PagesDone > 0 && cp.Cursor != ""
+}
+
+// finished reports whether a previous attempt already walked every page.
+func (cp *checkpoint) finished() bool {
+	return cp.PagesDone > 0 && cp.Cursor == ""
+}
+
+type runningJobKey struct{}
+
+// runningJob identifies the job a context runs for: its row, and the idempotency key that stays
+// the same across its retries.
+type runningJob struct {
+	id  uuid.UUID
+	key uuid.UUID
+}
+
+// withJob tags ctx with the running job, so list syncs can checkpoint and side effects can be
+// attributed without every sync function taking the job.
+func withJob(ctx context.Context, jobID uuid.UUID, idempotencyKey uuid.UUID) context.Context {
+	return context.WithValue(ctx, runningJobKey{}, runningJob{id: jobID, key: idempotencyKey})
+}
+
+// jobKey returns the idempotency key of the job ctx runs for, or nil outside a job.
+func jobKey(ctx context.Context) *uuid.UUID {
+	job, ok := ctx.Value(runningJobKey{}).(runningJob)
+	if !ok {
+		return nil
+	}
+	return &job.key
+}
+
+// loadCheckpoint returns the running job's checkpoint for resource and since, or a fresh one.
+func (w *Worker) loadCheckpoint(ctx context.Context, resource string, since time.Time) *checkpoint {
+	fresh := &checkpoint{Resource: resource, Since: since}
+	job, ok := ctx.Value(runningJobKey{}).(runningJob)
+	if !ok {
+		return fresh
+	}
+	jobID := job.id
+	fresh.jobID = jobID
+
+	var raw []byte
+	if err := w.pool.QueryRow(ctx, `SELECT progress FROM sync_jobs WHERE id = $1`, jobID).Scan(&raw); err != nil || raw == nil {
+		return fresh
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(raw, &cp); err != nil || cp.Resource != resource || !cp.Since.Equal(since) {
+		return fresh
+	}
+	cp.jobID = jobID
+	if cp.resumable() {
+		slog.Info("resuming sync from checkpoint",
+			"job_id", jobID,
+			"resource", resource,
+			"pages_done", cp.PagesDone,
+			"items_upserted", cp.ItemsUpserted,
+		)
+	}
+	return &cp
+}
+
+// save records that a page finished, with next as the cursor of the page after it ("" when the
+// walk stops there) and itemsUpserted the running total.
+func (cp *checkpoint) save(ctx context.Context, w *Worker, next string, itemsUpserted int) {
+	cp.Cursor = next
+	cp.PagesDone++
+	cp.ItemsUpserted = itemsUpserted
+	cp.UpdatedAt = time.Now().UTC()
+	if cp.jobID == uuid.Nil {
+		return
+	}
+	b, _ := json.Marshal(cp)
+	if _, err := w.pool.Exec(ctx, `UPDATE sync_jobs SET progress = $2 WHERE id = $1`, cp.jobID, b); err != nil {
+		slog.Warn("sync checkpoint save failed", "job_id", cp.jobID, "error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package syncjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// syncIssueComments walks the repository-wide comments listing from since (every comment on a
+// full pass) and files each comment under its issue or PR: one request per 100 comments instead
+// of one per issue.
+//
+// Comments are never urgent, so the job yields to other work on the token: once the quota is in
+// its last fifth the job is rescheduled for the reset and resumes from its checkpoint.
+func (w *Worker) syncIssueComments(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	start := time.Now()
+	cp := w.loadCheckpoint(ctx, "comments", since)
+	if cp.finished() {
+		return nil
+	}
+	total := cp.ItemsUpserted
+	cursor, more := cp.Cursor, true
+	for page := cp.PagesDone + 1; more; page++ {
+		if page > maxListPages {
+			slog.Warn("page limit reached, stopping", "project_id", projectID, "repo", fullName, "pages", maxListPages)
+			break
+		}
+		if rl, ok := w.gh.RateLimit(token, "core"); ok && rl.Limit > 0 && rl.Remaining < rl.Limit/5 && time.Until(rl.Reset) > 0 {
+			return &github.RateLimitError{Reset: rl.Reset}
+		}
+		if err := w.wait(ctx, token, "core"); err != nil {
+			return err
+		}
+		items, next, notModified, err := w.gh.ListRepoIssueCommentsPage(ctx, token, fullName, since, cursor)
+		if err != nil {
+			return err
+		}
+		cursor, more = next, next != ""
+		if notModified {
+			cp.save(ctx, w, cursor, total)
+			continue
+		}
+
+		var pw pageWriter
+		for _, c := range items {
+			number := c.IssueNumber()
+			if number == 0 {
+				continue
+			}
+			total++
+			queueCommentUpsert(&pw, projectID, number, c)
+		}
+		pw.flush(ctx, w, projectID)
+		cp.save(ctx, w, cursor, total)
+	}
+
+	slog.Info("sync comments completed",
+		"project_id", projectID,
+		"repo", fullName,
+		"total_comments", total,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return nil
+}
+
+// queueCommentUpsert stores a comment the way issue_comment webhooks do: a github_issue_comments
+// row, and for issues the comment replaced or appended in the github_issues snapshot.
+func queueCommentUpsert(pw *pageWriter, projectID uuid.UUID, number int, c github.RepoIssueComment) {
+	label := fmt.Sprintf("comment %d on #%d", c.ID, number)
+	pw.queue(label, `
+INSERT INTO github_issue_comments (project_id, github_comment_id, issue_number, is_pull_request, author_login, body, url, created_at_github, updated_at_github, last_seen_at)
+VALUES ($1, $2, $3,
+  EXISTS (SELECT 1 FROM github_pull_requests WHERE project_id = $1 AND number = $3),
+  NULLIF($4, ''), $5, $6, $7, $8, now())
+ON CONFLICT (project_id, github_comment_id) DO UPDATE SET
+  body = EXCLUDED.body,
+  updated_at_github = EXCLUDED.updated_at_github,
+  last_seen_at = now()
+WHERE github_issue_comments.deleted_at IS NULL
+`, projectID, c.ID, number, c.User.Login, c.Body, c.HTMLURL, parseGitHubTime(&c.CreatedAt), parseGitHubTime(&c.UpdatedAt))
+
+	snap, _ := json.Marshal(c.IssueComment)
+	pw.queue(label+" snapshot", `
+UPDATE github_issues
+SET comments = CASE
+      WHEN COALESCE(comments, '[]'::jsonb) @> jsonb_build_array(jsonb_build_object('id', $4::bigint)) THEN (
+        SELECT jsonb_agg(CASE WHEN (elem->>'id')::bigint = $4 THEN $3::jsonb ELSE elem END ORDER BY ord)
+        FROM jsonb_array_elements(comments) WITH ORDINALITY AS t(elem, ord)
+      )
+      ELSE COALESCE(comments, '[]'::jsonb) || jsonb_build_array($3::jsonb)
+    END,
+    comments_count = CASE
+      WHEN COALESCE(comments, '[]'::jsonb) @> jsonb_build_array(jsonb_build_object('id', $4::bigint)) THEN comments_count
+      ELSE GREATEST(COALESCE(comments_count, 0), jsonb_array_length(COALESCE(comments, '[]'::jsonb)) + 1)
+    END,
+    last_seen_at = now()
+WHERE project_id = $1 AND number = $2
+`, projectID, number, string(snap), c.ID)
+}
+
+// enqueueCommentSync queues sync_comments for the project, e.g. after an issues sync saw comment
+// counts change. A retried caller cannot queue it twice: there is at most one pending job per type.
+func (w *Worker) enqueueCommentSync(ctx context.Context, projectID uuid.UUID) {
+	_, err := w.pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+VALUES ($1, 'sync_comments', 'pending', now())
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, projectID)
+	if err != nil {
+		slog.Warn("sync_comments enqueue failed", "project_id", projectID, "error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// commitsBackfill bounds the first commit sync to the contribution calendar's window.
+const commitsBackfill = 365 * 24 * time.Hour
+
+// syncCommits pages default-branch commits newer than the latest stored one (minus a day of overlap
+// for late-arriving pushes with older author dates), or the last year on the first run.
+func (w *Worker) syncCommits(ctx context.Context, projectID uuid.UUID, fullName string, token string) error {
+	var latest *time.Time
+	if err := w.pool.QueryRow(ctx, `
+SELECT max(committed_at) FROM github_commits WHERE project_id = $1
+`, projectID).Scan(&latest); err != nil {
+		return err
+	}
+	since := time.Now().UTC().Add(-commitsBackfill)
+	if latest != nil {
+		since = latest.Add(-24 * time.Hour)
+	}
+
+	total := 0
+	cursor, more := "", true
+	for page := 1; more; page++ {
+		if page > maxListPages {
+			slog.Warn("page limit reached, stopping", "project_id", projectID, "repo", fullName, "pages", maxListPages)
+			break
+		}
+		if err := w.wait(ctx, token, "core"); err != nil {
+			return err
+		}
+		items, next, notModified, err := w.gh.ListCommitsPage(ctx, token, fullName, since, cursor)
+		if err != nil {
+			return err
+		}
+		cursor, more = next, next != ""
+		if notModified {
+			continue
+		}
+		for _, it := range items {
+			w.upsertCommit(ctx, projectID, it)
+		}
+		total += len(items)
+	}
+
+	slog.Info("commits synced",
+		"project_id", projectID,
+		"repo", fullName,
+		"since", since,
+		"commits", total,
+	)
+	return nil
+}
+
+func (w *Worker) upsertCommit(ctx context.Context, projectID uuid.UUID, it github.CommitListItem) {
+	authorLogin := ""
+	if it.Author != nil {
+		authorLogin = it.Author.Login
+	}
+	_, err := w.pool.Exec(ctx, `
+INSERT INTO github_commits (project_id, sha, author_login, author_name, author_email, message, url, committed_at, last_seen_at)
+VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, now())
+ON CONFLICT (project_id, sha) DO UPDATE SET
+  author_login = COALESCE(EXCLUDED.author_login, github_commits.author_login),
+  author_name = EXCLUDED.author_name,
+  author_email = EXCLUDED.author_email,
+  message = EXCLUDED.message,
+  url = EXCLUDED.url,
+  committed_at = EXCLUDED.committed_at,
+  last_seen_at = now()
+`, projectID, it.SHA, authorLogin, it.Commit.Author.Name, it.Commit.Author.Email, it.Commit.Message, it.HTMLURL, parseGitHubTime(&it.Commit.Author.Date))
+	if err != nil {
+		slog.Warn("commit upsert failed", "project_id", projectID, "sha", it.SHA, "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/achievements"
+	"github.com/jagadeesh/grainlify/backend/internal/contributions"
+)
+
+const (
+	// backfillBatch caps how many contribution backfills one tick runs.
+	backfillBatch = 20
+	// backfillMaxAttempts is how many times a backfill is tried before it is marked failed.
+	backfillMaxAttempts = 5
+)
+
+// runContributionBackfills runs the contribution backfills queued when users linked GitHub. Claiming
+// pushes run_at out by ten minutes, so a backfill whose instance died is retried by the next tick
+// after that.
+func (w *Worker) runContributionBackfills(ctx context.Context) {
+	rows, err := w.pool.Query(ctx, `
+UPDATE contribution_backfills
+SET attempts = attempts + 1, run_at = now() + interval '10 minutes'
+WHERE id IN (
+  SELECT id FROM contribution_backfills
+  WHERE status = 'pending' AND run_at <= now()
+  ORDER BY run_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, github_user_id, github_login, attempts
+`, backfillBatch)
+	if err != nil {
+		slog.Warn("contribution backfills: claim failed", "error", err)
+		return
+	}
+	type backfill struct {
+		id, userID   uuid.UUID
+		githubUserID int64
+		login        string
+		attempts     int
+	}
+	var claimed []backfill
+	for rows.Next() {
+		var b backfill
+		if err := rows.Scan(&b.id, &b.userID, &b.githubUserID, &b.login, &b.attempts); err != nil {
+			rows.Close()
+			slog.Warn("contribution backfills: claim failed", "error", err)
+			return
+		}
+		claimed = append(claimed, b)
+	}
+	rows.Close()
+
+	for _, b := range claimed {
+		commits, err := contributions.BackfillLogin(ctx, w.pool, b.githubUserID, b.login)
+		var badges int
+		if err == nil {
+			badges, err = achievements.Evaluate(ctx, w.pool, b.login)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("contribution backfill failed", "user_id", b.userID, "login", b.login, "attempt", b.attempts, "error", err)
+			_, _ = w.pool.Exec(ctx, `
+UPDATE contribution_backfills
+SET status = CASE WHEN attempts >= $2 THEN 'failed' ELSE 'pending' END,
+    last_error = $3
+WHERE id = $1
+`, b.id, backfillMaxAttempts, err.Error())
+			continue
+		}
+		_, _ = w.pool.Exec(ctx, `
+UPDATE contribution_backfills SET status = 'completed', last_error = NULL, completed_at = now() WHERE id = $1
+`, b.id)
+		slog.Info("contribution backfill completed",
+			"user_id", b.userID,
+			"login", b.login,
+			"commits_attributed", commits,
+			"badges_awarded", badges,
+		)
+	}
+}
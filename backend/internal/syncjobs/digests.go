@@ -0,0 +1,128 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/digest"
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/mail"
+)
+
+// digestBatch caps how many digests of one period a tick generates.
+const digestBatch = 200
+
+// sendDigests emails the digest of the period that just ended to users who opted in with
+// digest_frequency. Storing the digest claims it, so each user gets one per period even with
+// several workers; a digest with nothing in it is stored (its rank is the next one's baseline)
+// but not emailed.
+func (w *Worker) sendDigests(ctx context.Context) {
+	if !w.cfg.DigestEmailsEnabled {
+		return
+	}
+	now := time.Now()
+	for _, period := range []string{digest.PeriodWeekly, digest.PeriodMonthly} {
+		start, _ := digest.Window(period, now)
+		rows, err := w.pool.Query(ctx, `
+SELECT u.id, ga.login
+FROM users u
+JOIN github_accounts ga ON ga.user_id = u.id
+WHERE u.digest_frequency = $1 AND ga.login <> ''
+  AND NOT EXISTS (
+    SELECT 1 FROM user_digests d
+    WHERE d.user_id = u.id AND d.period = $1 AND d.period_start = $2
+  )
+ORDER BY u.id
+LIMIT $3
+`, period, start, digestBatch)
+		if err != nil {
+			slog.Warn("digests: listing recipients failed", "period", period, "error", err)
+			return
+		}
+		type recipient struct {
+			userID uuid.UUID
+			login  string
+		}
+		var due []recipient
+		for rows.Next() {
+			var r recipient
+			if err := rows.Scan(&r.userID, &r.login); err != nil {
+				rows.Close()
+				slog.Warn("digests: listing recipients failed", "period", period, "error", err)
+				return
+			}
+			due = append(due, r)
+		}
+		rows.Close()
+
+		sent := 0
+		for _, r := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			ok, err := w.sendDigest(ctx, r.userID, r.login, period, now)
+			if err != nil {
+				slog.Warn("digest failed", "user_id", r.userID, "period", period, "error", err)
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+		if len(due) > 0 {
+			slog.Info("digests processed", "period", period, "period_start", start, "users", len(due), "emailed", sent)
+		}
+	}
+}
+
+// sendDigest builds, stores and emails one user's digest. It reports whether an email went out.
+func (w *Worker) sendDigest(ctx context.Context, userID uuid.UUID, login, period string, now time.Time) (bool, error) {
+	d, err := digest.Build(ctx, w.pool, userID, login, period, now)
+	if err != nil {
+		return false, err
+	}
+	id, stored, err := digest.Store(ctx, w.pool, userID, d)
+	if err != nil || !stored || d.Empty() {
+		return false, err
+	}
+
+	to, err := w.digestEmail(ctx, userID)
+	if err == nil {
+		subject, text := d.Text(w.cfg.FrontendBaseURL)
+		err = w.mailer.Send(ctx, mail.Message{To: to, Subject: subject, Text: text})
+	}
+	if err != nil {
+		_, _ = w.pool.Exec(ctx, `UPDATE user_digests SET send_error = $2 WHERE id = $1`, id, err.Error())
+		return false, err
+	}
+	_, _ = w.pool.Exec(ctx, `UPDATE user_digests SET sent_at = now(), send_error = NULL WHERE id = $1`, id)
+	return true, nil
+}
+
+// digestEmail picks the address a digest goes to: a verified address from a login provider, else the
+// primary email of the linked GitHub account.
+func (w *Worker) digestEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email string
+	err := w.pool.QueryRow(ctx, `
+SELECT email FROM oauth_identities
+WHERE user_id = $1 AND email_verified AND email IS NOT NULL AND email <> ''
+ORDER BY updated_at DESC
+LIMIT 1
+`, userID).Scan(&email)
+	if err == nil {
+		return email, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	account, err := github.GetLinkedAccount(ctx, w.pool, userID, w.cfg.TokenEncKeyB64)
+	if err != nil {
+		return "", err
+	}
+	return github.NewClient().GetPrimaryEmail(ctx, account.AccessToken)
+}
@@ -0,0 +1,126 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// refreshEcosystemStats rebuilds the ecosystem_stats row of every active ecosystem whose stats are
+// missing or older than the configured interval. The aggregates scan every issue and PR of the
+// ecosystem, which is why the public detail page reads them from here rather than computing them.
+func (w *Worker) refreshEcosystemStats(ctx context.Context) {
+	rows, err := w.pool.Query(ctx, `
+SELECT e.id
+FROM ecosystems e
+LEFT JOIN ecosystem_stats s ON s.ecosystem_id = e.id
+WHERE e.status = 'active'
+  AND (s.computed_at IS NULL OR s.computed_at < now() - $1 * interval '1 second')
+ORDER BY s.computed_at NULLS FIRST
+LIMIT 100
+`, int64(w.cfg.EcosystemStatsInterval.Seconds()))
+	if err != nil {
+		slog.Warn("ecosystem stats lookup failed", "error", err)
+		return
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			slog.Warn("ecosystem stats lookup failed", "error", err)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if err := w.computeEcosystemStats(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("ecosystem stats refresh failed", "ecosystem_id", id, "error", err)
+		}
+	}
+	if len(ids) > 0 {
+		slog.Info("ecosystem stats refreshed", "count", len(ids))
+	}
+}
+
+// computeEcosystemStats upserts one ecosystem's aggregates, including the projects of its child
+// ecosystems. Only verified, listed projects count, as on the public projects list; the trend covers
+// the current and previous 11 calendar months.
+func (w *Worker) computeEcosystemStats(ctx context.Context, ecosystemID uuid.UUID) error {
+	_, err := w.pool.Exec(ctx, `
+WITH ep AS (
+  SELECT id FROM projects
+  WHERE ecosystem_id IN (SELECT descendant_id FROM ecosystem_closure WHERE ancestor_id = $1)
+    AND deleted_at IS NULL AND status = 'verified' AND needs_metadata = false
+),
+contributions AS (
+  SELECT author_login, created_at_github FROM github_issues
+  WHERE project_id IN (SELECT id FROM ep) AND author_login IS NOT NULL AND author_login <> ''
+  UNION ALL
+  SELECT author_login, created_at_github FROM github_pull_requests
+  WHERE project_id IN (SELECT id FROM ep) AND author_login IS NOT NULL AND author_login <> ''
+),
+languages AS (
+  SELECT pl.language, SUM(pl.bytes) AS bytes, SUM(SUM(pl.bytes)) OVER () AS total
+  FROM project_languages pl
+  WHERE pl.project_id IN (SELECT id FROM ep)
+  GROUP BY pl.language
+  ORDER BY bytes DESC
+  LIMIT 10
+),
+months AS (
+  SELECT m::timestamptz AS month
+  FROM generate_series(date_trunc('month', now()) - interval '11 months', date_trunc('month', now()), interval '1 month') AS m
+)
+INSERT INTO ecosystem_stats (
+  ecosystem_id, project_count, contributors_count, open_issues_count, open_prs_count, merged_prs_count,
+  top_languages, contribution_trend, computed_at
+)
+SELECT
+  $1,
+  (SELECT COUNT(*) FROM ep),
+  (SELECT COUNT(DISTINCT author_login) FROM contributions),
+  (SELECT COUNT(*) FROM github_issues WHERE project_id IN (SELECT id FROM ep) AND state = 'open'),
+  (SELECT COUNT(*) FROM github_pull_requests WHERE project_id IN (SELECT id FROM ep) AND state = 'open'),
+  (SELECT COUNT(*) FROM github_pull_requests WHERE project_id IN (SELECT id FROM ep) AND merged IS TRUE),
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+      'name', l.language,
+      'bytes', l.bytes,
+      'percentage', ROUND(100.0 * l.bytes / NULLIF(l.total, 0), 1)
+    ) ORDER BY l.bytes DESC)
+    FROM languages l
+  ), '[]'::jsonb),
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+      'month', to_char(m.month, 'YYYY-MM'),
+      'issues', (SELECT COUNT(*) FROM github_issues gi
+                 WHERE gi.project_id IN (SELECT id FROM ep)
+                   AND gi.created_at_github >= m.month AND gi.created_at_github < m.month + interval '1 month'),
+      'prs', (SELECT COUNT(*) FROM github_pull_requests gp
+              WHERE gp.project_id IN (SELECT id FROM ep)
+                AND gp.created_at_github >= m.month AND gp.created_at_github < m.month + interval '1 month'),
+      'contributors', (SELECT COUNT(DISTINCT c.author_login) FROM contributions c
+                       WHERE c.created_at_github >= m.month AND c.created_at_github < m.month + interval '1 month')
+    ) ORDER BY m.month)
+    FROM months m
+  ), '[]'::jsonb),
+  now()
+ON CONFLICT (ecosystem_id) DO UPDATE SET
+  project_count = EXCLUDED.project_count,
+  contributors_count = EXCLUDED.contributors_count,
+  open_issues_count = EXCLUDED.open_issues_count,
+  open_prs_count = EXCLUDED.open_prs_count,
+  merged_prs_count = EXCLUDED.merged_prs_count,
+  top_languages = EXCLUDED.top_languages,
+  contribution_trend = EXCLUDED.contribution_trend,
+  computed_at = EXCLUDED.computed_at
+`, ecosystemID)
+	return err
+}
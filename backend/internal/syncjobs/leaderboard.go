@@ -0,0 +1,148 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// leaderboardTop caps how many contributors each board keeps.
+const leaderboardTop = 1000
+
+// leaderboardPeriods lists each period with how far back it counts (0 for all time).
+var leaderboardPeriods = []struct {
+	name   string
+	window time.Duration
+}{
+	{"weekly", 7 * 24 * time.Hour},
+	{"monthly", 30 * 24 * time.Hour},
+	{"all", 0},
+}
+
+// refreshLeaderboards rebuilds the boards of every period whose snapshot is missing or older than
+// the configured interval.
+func (w *Worker) refreshLeaderboards(ctx context.Context) {
+	for _, p := range leaderboardPeriods {
+		var stale bool
+		err := w.pool.QueryRow(ctx, `
+SELECT COALESCE(MAX(computed_at) < now() - $2 * interval '1 second', true)
+FROM leaderboard_snapshots
+WHERE period = $1
+`, p.name, int64(w.cfg.LeaderboardRefreshInterval.Seconds())).Scan(&stale)
+		if err != nil {
+			slog.Warn("leaderboard lookup failed", "period", p.name, "error", err)
+			return
+		}
+		if !stale {
+			continue
+		}
+		n, err := w.computeLeaderboards(ctx, p.name, p.window)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("leaderboard refresh failed", "period", p.name, "error", err)
+			continue
+		}
+		if n >= 0 {
+			slog.Info("leaderboards refreshed", "period", p.name, "rows", n)
+		}
+	}
+}
+
+// computeLeaderboards replaces the period's global and per-ecosystem boards. Contributors are ranked
+// by merged PRs plus closed issues they authored on verified (or archived) projects, within the
+// window when it is non-zero. Returns -1 when another instance holds the period's lock.
+func (w *Worker) computeLeaderboards(ctx context.Context, period string, window time.Duration) (int64, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended('leaderboard:' || $1, 0))`, period).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return -1, nil
+	}
+
+	var since *time.Time
+	if window > 0 {
+		t := time.Now().UTC().Add(-window)
+		since = &t
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM leaderboard_snapshots WHERE period = $1`, period); err != nil {
+		return 0, err
+	}
+	ct, err := tx.Exec(ctx, `
+WITH listed AS (
+  SELECT id, ecosystem_id FROM projects
+  WHERE status IN ('verified', 'archived') AND deleted_at IS NULL
+),
+contributions AS (
+  SELECT pr.project_id, pr.author_login AS login, 1 AS merged_prs, 0 AS closed_issues
+  FROM github_pull_requests pr
+  WHERE pr.merged IS TRUE AND pr.author_login IS NOT NULL AND pr.author_login <> ''
+    AND pr.project_id IN (SELECT id FROM listed)
+    AND ($2::timestamptz IS NULL OR pr.merged_at_github >= $2)
+  UNION ALL
+  SELECT i.project_id, i.author_login, 0, 1
+  FROM github_issues i
+  WHERE i.state = 'closed' AND i.author_login IS NOT NULL AND i.author_login <> ''
+    AND i.project_id IN (SELECT id FROM listed)
+    AND ($2::timestamptz IS NULL OR i.closed_at_github >= $2)
+),
+per_ecosystem AS (
+  SELECT ec.ancestor_id AS ecosystem_id, LOWER(c.login) AS login_key, MIN(c.login) AS login,
+         SUM(c.merged_prs)::int AS merged_prs, SUM(c.closed_issues)::int AS closed_issues
+  FROM contributions c
+  JOIN listed l ON l.id = c.project_id
+  JOIN ecosystem_closure ec ON ec.descendant_id = l.ecosystem_id
+  JOIN ecosystems e ON e.id = ec.ancestor_id AND e.status = 'active'
+  GROUP BY ec.ancestor_id, LOWER(c.login)
+),
+ecosystem_names AS (
+  SELECT pe.login_key, array_agg(e.name ORDER BY e.name) AS names
+  FROM per_ecosystem pe JOIN ecosystems e ON e.id = pe.ecosystem_id
+  GROUP BY pe.login_key
+),
+global AS (
+  SELECT NULL::uuid AS ecosystem_id, g.login, g.merged_prs, g.closed_issues,
+         COALESCE(n.names, ARRAY[]::TEXT[]) AS ecosystems
+  FROM (
+    SELECT LOWER(c.login) AS login_key, MIN(c.login) AS login,
+           SUM(c.merged_prs)::int AS merged_prs, SUM(c.closed_issues)::int AS closed_issues
+    FROM contributions c
+    GROUP BY LOWER(c.login)
+  ) g
+  LEFT JOIN ecosystem_names n ON n.login_key = g.login_key
+),
+boards AS (
+  SELECT ecosystem_id, login, merged_prs, closed_issues, ARRAY[]::TEXT[] AS ecosystems FROM per_ecosystem
+  UNION ALL
+  SELECT ecosystem_id, login, merged_prs, closed_issues, ecosystems FROM global
+),
+ranked AS (
+  SELECT b.*, b.merged_prs + b.closed_issues AS score,
+         ROW_NUMBER() OVER (
+           PARTITION BY b.ecosystem_id
+           ORDER BY b.merged_prs + b.closed_issues DESC, b.merged_prs DESC, LOWER(b.login) ASC
+         ) AS rank
+  FROM boards b
+)
+INSERT INTO leaderboard_snapshots (ecosystem_id, period, rank, login, merged_prs, closed_issues, score, ecosystems, computed_at)
+SELECT ecosystem_id, $1, rank, login, merged_prs, closed_issues, score, ecosystems, now()
+FROM ranked
+WHERE rank <= $3
+`, period, since, leaderboardTop)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
@@ -0,0 +1,44 @@
+package syncjobs
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// typeLimits returns the configured per-type caps (SYNC_LIMITS) as parallel arrays for the claim query.
+func (w *Worker) typeLimits() ([]string, []int32) {
+	types := make([]string, 0, len(w.cfg.SyncJobLimits))
+	counts := make([]int32, 0, len(w.cfg.SyncJobLimits))
+	for t, n := range w.cfg.SyncJobLimits {
+		types = append(types, t)
+		counts = append(counts, int32(n))
+	}
+	return types, counts
+}
+
+// typeAtLimit reports whether jobType already has its cap of running jobs. The claim query filters
+// on the same count, but two workers can both see a free slot; claims of a capped type are
+// serialized on an advisory lock and the count repeated under it, like the per-project check.
+func (w *Worker) typeAtLimit(ctx context.Context, tx pgx.Tx, jobType string) (bool, error) {
+	limit, ok := w.cfg.SyncJobLimits[jobType]
+	if !ok {
+		return false, nil
+	}
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended('sync_jobs:' || $1, 0))`, jobType).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return true, nil
+	}
+	var running int
+	err := tx.QueryRow(ctx, `
+SELECT count(*) FROM sync_jobs
+WHERE job_type = $1 AND status = 'running' AND locked_at > now() - $2 * interval '1 second'
+`, jobType, int64(w.cfg.SyncJobVisibilityTimeout.Seconds())).Scan(&running)
+	if err != nil {
+		return false, err
+	}
+	return running >= limit, nil
+}
@@ -0,0 +1,12 @@
+package syncjobs
+
+import "github.com/jagadeesh/grainlify/backend/internal/metrics"
+
+// Histograms for jobs run by this process, exposed on /metrics. Queue latency is how long a job
+// waited past its run_at before a worker claimed it.
+var (
+	jobDuration = metrics.NewHistogramVec("grainlify_sync_job_duration_seconds",
+		"Time spent running a sync job attempt.", "job_type", metrics.DefBuckets)
+	jobQueueLatency = metrics.NewHistogramVec("grainlify_sync_job_queue_latency_seconds",
+		"Time a due sync job waited before a worker claimed it.", "job_type", metrics.DefBuckets)
+)
@@ -0,0 +1,45 @@
+package syncjobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+)
+
+// PublishEnqueued announces on the bus that jobTypes were queued for projectID, so subscribed
+// workers pick them up immediately. Best effort: without a bus, or if publishing fails, the jobs
+// are still found by the workers' poll.
+func PublishEnqueued(ctx context.Context, b bus.Bus, projectID string, jobTypes ...string) {
+	if b == nil {
+		return
+	}
+	data, _ := json.Marshal(events.SyncJobEnqueued{ProjectID: projectID, JobTypes: jobTypes})
+	if err := b.Publish(ctx, events.SubjectSyncJobEnqueued, data); err != nil {
+		slog.Warn("sync job enqueued publish failed", "project_id", projectID, "error", err)
+	}
+}
+
+// PublishBounty announces a bounty change on subject (events.SubjectBountyCreated,
+// SubjectBountyClaimed or SubjectBountyPaid), so the bounty issue consumer updates its GitHub issue now. Best effort: the
+// worker's sweep catches bounties whose issue is behind.
+func PublishBounty(ctx context.Context, b bus.Bus, subject, bountyID, status string) {
+	if b == nil {
+		return
+	}
+	data, _ := json.Marshal(events.BountyChanged{BountyID: bountyID, Status: status})
+	if err := b.Publish(ctx, subject, data); err != nil {
+		slog.Warn("bounty event publish failed", "subject", subject, "bounty_id", bountyID, "error", err)
+	}
+}
+
+// Wake makes an idle runLoop claim jobs now rather than at its next poll. It never blocks: wakes
+// beyond one per loop are dropped, since a woken loop drains every due job anyway.
+func (w *Worker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,105 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// reverifyOwnership re-checks that the project owner can still administer the repo, the same test
+// Verify applies, and sends the project back to pending_verification with the reason when not.
+// Installation tokens prove access through the app itself, so those projects only get stamped.
+func (w *Worker) reverifyOwnership(ctx context.Context, projectID uuid.UUID, fullName string, ghToken github.ProjectToken) error {
+	if ghToken.Mode == github.AuthModeInstallation {
+		return w.stampOwnershipChecked(ctx, projectID)
+	}
+	if err := w.wait(ctx, ghToken.Token, "core"); err != nil {
+		return err
+	}
+
+	var repoID *int64
+	if err := w.pool.QueryRow(ctx, `SELECT github_repo_id FROM projects WHERE id = $1`, projectID).Scan(&repoID); err != nil {
+		return err
+	}
+	var repo github.Repo
+	var err error
+	if repoID != nil && *repoID != 0 {
+		repo, err = w.gh.GetRepoByID(ctx, ghToken.Token, *repoID)
+	} else {
+		repo, err = w.gh.GetRepo(ctx, ghToken.Token, fullName)
+	}
+
+	reason := ""
+	var apiErr *github.GitHubAPIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		reason = "ownership_lost: repository is no longer accessible to the owner"
+	case err != nil:
+		return err
+	case !repo.Permissions.Admin && !repo.Permissions.Push:
+		reason = "ownership_lost: owner no longer has admin or push access"
+	}
+	if reason == "" {
+		return w.stampOwnershipChecked(ctx, projectID)
+	}
+
+	ct, err := w.pool.Exec(ctx, `
+UPDATE projects
+SET status = 'pending_verification',
+    verification_error = $2,
+    ownership_checked_at = now(),
+    updated_at = now()
+WHERE id = $1 AND status = 'verified'
+`, projectID, reason)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() > 0 {
+		slog.Warn("project ownership lost, verification revoked",
+			"project_id", projectID,
+			"repo", fullName,
+			"reason", reason,
+		)
+	}
+	return nil
+}
+
+func (w *Worker) stampOwnershipChecked(ctx context.Context, projectID uuid.UUID) error {
+	_, err := w.pool.Exec(ctx, `UPDATE projects SET ownership_checked_at = now() WHERE id = $1`, projectID)
+	return err
+}
+
+// enqueueOwnershipReverify queues reverify_ownership for verified projects not checked within the
+// configured interval (never-checked projects count from verification).
+func (w *Worker) enqueueOwnershipReverify(ctx context.Context) {
+	ct, err := w.pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+SELECT p.id, 'reverify_ownership', 'pending', now()
+FROM projects p
+WHERE p.status = 'verified'
+  AND p.provider = 'github'
+  AND p.deleted_at IS NULL
+  AND COALESCE(p.ownership_checked_at, p.verified_at, p.created_at) < now() - $1 * interval '1 second'
+  AND NOT EXISTS (
+    SELECT 1 FROM sync_jobs j
+    WHERE j.project_id = p.id
+      AND j.job_type = 'reverify_ownership'
+      AND j.status IN ('pending', 'running', 'retrying')
+  )
+ORDER BY p.ownership_checked_at NULLS FIRST
+LIMIT 500
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, int64(w.cfg.OwnershipReverifyInterval.Seconds()))
+	if err != nil {
+		slog.Warn("ownership reverify enqueue failed", "error", err)
+		return
+	}
+	if n := ct.RowsAffected(); n > 0 {
+		slog.Info("ownership reverify jobs enqueued", "count", n)
+	}
+}
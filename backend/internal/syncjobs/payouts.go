@@ -0,0 +1,265 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/bounties"
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
+)
+
+const (
+	// payoutBatch caps how many payouts one tick works on.
+	payoutBatch = 20
+	// payoutExpiryGrace is how long past valid_until a missing transaction is still looked up
+	// before it is treated as expired: ledger close times trail the wall clock slightly.
+	payoutExpiryGrace = time.Minute
+)
+
+// newPayer returns the payout engine configured by PAYOUT_*, or nil when payouts are off.
+func newPayer(cfg config.Config) (*stellar.Payer, error) {
+	signer, err := stellar.NewSigner(stellar.SignerConfig{
+		Kind:    cfg.PayoutSigner,
+		Secret:  cfg.PayoutSourceSecret,
+		URL:     cfg.PayoutSignerURL,
+		Token:   cfg.PayoutSignerToken,
+		Account: cfg.PayoutSourceAccount,
+	})
+	if err != nil || signer == nil {
+		return nil, err
+	}
+	network := stellarnet.FromConfig(cfg)
+	return stellar.NewPayer(network.HorizonURL, network.Passphrase, signer, cfg.PayoutTxTimeout), nil
+}
+
+type payout struct {
+	id, bountyID     uuid.UUID
+	status           string
+	pay              stellar.Payment
+	txHash, envelope string
+	validUntil       *time.Time
+	attempts         int
+	approvedBy       *uuid.UUID
+}
+
+// runPayouts moves due payouts along: pending ones get a transaction built, signed and submitted;
+// submitted ones are confirmed, re-submitted, or sent back to pending once their transaction has
+// expired without landing. Payouts awaiting admin approval are left alone until they reach quorum
+// (see PAYOUT_APPROVAL_THRESHOLD_USD). Claiming pushes run_at out, so a payout whose worker died is
+// picked up again two minutes later.
+func (w *Worker) runPayouts(ctx context.Context) {
+	if w.payer == nil {
+		return
+	}
+	rows, err := w.pool.Query(ctx, `
+UPDATE payouts
+SET run_at = now() + interval '2 minutes', updated_at = now()
+WHERE id IN (
+  SELECT id FROM payouts
+  WHERE status IN ('pending', 'submitted') AND run_at <= now()
+    -- Never send a payout that still lacks its admin approvals.
+    AND (status = 'submitted' OR approvals_required <= (
+      SELECT COUNT(*) FROM payout_approvals pa WHERE pa.payout_id = payouts.id AND pa.decision = 'approve'
+    ))
+  ORDER BY run_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, bounty_id, status, destination, amount::text, asset, COALESCE(asset_issuer, ''),
+  COALESCE(tx_hash, ''), COALESCE(envelope_xdr, ''), valid_until, attempts, approved_by
+`, payoutBatch)
+	if err != nil {
+		slog.Warn("payouts: claim failed", "error", err)
+		return
+	}
+	var claimed []payout
+	for rows.Next() {
+		var p payout
+		if err := rows.Scan(&p.id, &p.bountyID, &p.status, &p.pay.Destination, &p.pay.Amount, &p.pay.AssetCode, &p.pay.AssetIssuer,
+			&p.txHash, &p.envelope, &p.validUntil, &p.attempts, &p.approvedBy); err != nil {
+			rows.Close()
+			slog.Warn("payouts: claim failed", "error", err)
+			return
+		}
+		claimed = append(claimed, p)
+	}
+	rows.Close()
+
+	for _, p := range claimed {
+		if p.status == "pending" {
+			w.sendPayout(ctx, p)
+		} else {
+			w.checkPayout(ctx, p)
+		}
+	}
+}
+
+// sendPayout builds and signs a new transaction for a pending payout, stores it, then submits it.
+func (w *Worker) sendPayout(ctx context.Context, p payout) {
+	if p.attempts >= max(w.cfg.PayoutMaxAttempts, 1) {
+		w.failPayout(ctx, p, fmt.Sprintf("gave up after %d transactions", p.attempts))
+		return
+	}
+	signed, err := w.payer.Build(ctx, p.pay, time.Now())
+	if err != nil {
+		// Horizon or the signer is unreachable; nothing was signed, so try again later.
+		slog.Warn("payouts: build failed", "payout_id", p.id, "error", err)
+		w.deferPayout(ctx, p, err.Error(), time.Minute)
+		return
+	}
+	tag, err := w.pool.Exec(ctx, `
+UPDATE payouts
+SET status = 'submitted', source_account = $2, tx_hash = $3, envelope_xdr = $4, valid_until = $5,
+    attempts = attempts + 1, submitted_at = now(), updated_at = now()
+WHERE id = $1 AND status = 'pending'
+`, p.id, w.payer.Source(), signed.Hash, signed.EnvelopeXDR, signed.ValidUntil)
+	if err != nil || tag.RowsAffected() == 0 {
+		slog.Warn("payouts: store transaction failed", "payout_id", p.id, "error", err)
+		return
+	}
+	p.attempts++
+	p.txHash, p.envelope, p.validUntil = signed.Hash, signed.EnvelopeXDR, &signed.ValidUntil
+	slog.Info("payout submitted", "payout_id", p.id, "bounty_id", p.bountyID, "tx_hash", p.txHash, "attempt", p.attempts)
+	w.submitPayout(ctx, p)
+}
+
+// checkPayout settles a submitted payout from what Horizon knows about its transaction.
+func (w *Worker) checkPayout(ctx context.Context, p payout) {
+	st, err := w.payer.Status(ctx, p.txHash)
+	if err != nil {
+		slog.Warn("payouts: status lookup failed", "payout_id", p.id, "tx_hash", p.txHash, "error", err)
+		return
+	}
+	switch {
+	case st.Found && st.Successful:
+		w.confirmPayout(ctx, p, st.Ledger)
+	case st.Found:
+		w.retryPayout(ctx, p, "transaction failed in ledger")
+	case p.validUntil != nil && time.Now().After(p.validUntil.Add(payoutExpiryGrace)):
+		w.retryPayout(ctx, p, "transaction expired before it was applied")
+	default:
+		// Still valid and not seen: re-submitting the same envelope cannot pay twice.
+		w.submitPayout(ctx, p)
+	}
+}
+
+func (w *Worker) submitPayout(ctx context.Context, p payout) {
+	ledger, err := w.payer.Submit(ctx, p.envelope)
+	if err == nil {
+		w.confirmPayout(ctx, p, ledger)
+		return
+	}
+	se, ok := stellar.IsSubmitError(err)
+	if !ok {
+		// Timeouts and server errors leave the outcome unknown; look it up on the next tick.
+		slog.Warn("payouts: submit outcome unknown", "payout_id", p.id, "tx_hash", p.txHash, "error", err)
+		w.deferPayout(ctx, p, err.Error(), 30*time.Second)
+		return
+	}
+	// A resubmission of an already applied transaction can be rejected (tx_bad_seq).
+	if st, serr := w.payer.Status(ctx, p.txHash); serr == nil && st.Found && st.Successful {
+		w.confirmPayout(ctx, p, st.Ledger)
+		return
+	}
+	slog.Warn("payouts: transaction rejected", "payout_id", p.id, "tx_hash", p.txHash, "codes", se.Codes)
+	if se.Permanent {
+		w.failPayout(ctx, p, se.Error())
+		return
+	}
+	w.retryPayout(ctx, p, se.Error())
+}
+
+// confirmPayout records the payout's ledger and marks its bounty paid.
+func (w *Worker) confirmPayout(ctx context.Context, p payout, ledger int32) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		slog.Warn("payouts: confirm failed", "payout_id", p.id, "error", err)
+		return
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `
+UPDATE payouts
+SET status = 'confirmed', ledger = $2, confirmed_at = now(), last_error = NULL, updated_at = now()
+WHERE id = $1
+`, p.id, ledger); err != nil {
+		slog.Warn("payouts: confirm failed", "payout_id", p.id, "error", err)
+		return
+	}
+	var from string
+	err = tx.QueryRow(ctx, `
+UPDATE bounties b
+SET status = 'paid', paid_at = now(), payment_tx_hash = $2, updated_at = now()
+FROM (SELECT id, status FROM bounties WHERE id = $1 FOR UPDATE) old
+WHERE b.id = old.id AND old.status IN ('claimed', 'pending_payout')
+RETURNING old.status
+`, p.bountyID, p.txHash).Scan(&from)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		slog.Warn("payouts: confirm failed", "payout_id", p.id, "error", err)
+		return
+	}
+	if err == nil {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO bounty_events (bounty_id, from_status, to_status, actor_user_id, note)
+VALUES ($1, $2, 'paid', $3, $4)
+`, p.bountyID, from, p.approvedBy, fmt.Sprintf("Payout confirmed in ledger %d", ledger)); err != nil {
+			slog.Warn("payouts: confirm failed", "payout_id", p.id, "error", err)
+			return
+		}
+	} else {
+		slog.Warn("payouts: bounty was no longer claimed when its payout confirmed", "payout_id", p.id, "bounty_id", p.bountyID)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Warn("payouts: confirm failed", "payout_id", p.id, "error", err)
+		return
+	}
+	slog.Info("payout confirmed", "payout_id", p.id, "bounty_id", p.bountyID, "tx_hash", p.txHash, "ledger", ledger)
+	if from != "" {
+		PublishBounty(ctx, w.bus, events.SubjectBountyPaid, p.bountyID.String(), bounties.StatusPaid)
+	}
+}
+
+// retryPayout sends a payout whose transaction did not succeed back to pending, to be rebuilt after
+// a backoff, or fails it once it has used its attempts.
+func (w *Worker) retryPayout(ctx context.Context, p payout, reason string) {
+	if p.attempts >= max(w.cfg.PayoutMaxAttempts, 1) {
+		w.failPayout(ctx, p, reason)
+		return
+	}
+	delay := retryDelay(p.attempts, time.Minute, time.Hour)
+	if _, err := w.pool.Exec(ctx, `
+UPDATE payouts
+SET status = 'pending', last_error = $2, run_at = now() + $3 * interval '1 second', updated_at = now()
+WHERE id = $1
+`, p.id, reason, delay.Seconds()); err != nil {
+		slog.Warn("payouts: retry failed", "payout_id", p.id, "error", err)
+	}
+}
+
+// deferPayout keeps the payout's status and looks at it again after delay.
+func (w *Worker) deferPayout(ctx context.Context, p payout, reason string, delay time.Duration) {
+	if _, err := w.pool.Exec(ctx, `
+UPDATE payouts SET last_error = $2, run_at = now() + $3 * interval '1 second', updated_at = now() WHERE id = $1
+`, p.id, reason, delay.Seconds()); err != nil {
+		slog.Warn("payouts: defer failed", "payout_id", p.id, "error", err)
+	}
+}
+
+// failPayout gives up on a payout. The bounty stays claimed, so a maintainer can approve a new one.
+func (w *Worker) failPayout(ctx context.Context, p payout, reason string) {
+	if _, err := w.pool.Exec(ctx, `
+UPDATE payouts SET status = 'failed', last_error = $2, failed_at = now(), updated_at = now() WHERE id = $1
+`, p.id, reason); err != nil {
+		slog.Warn("payouts: fail failed", "payout_id", p.id, "error", err)
+		return
+	}
+	slog.Warn("payout failed", "payout_id", p.id, "bounty_id", p.bountyID, "reason", reason)
+}
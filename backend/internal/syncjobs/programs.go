@@ -0,0 +1,89 @@
+package syncjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/programs"
+)
+
+// closePrograms closes out active programs whose window ended PROGRAM_CLOSE_DELAY ago: each one's
+// final standings are scored and its budget allocated into program_allocations, in the transaction
+// that marks it closed.
+func (w *Worker) closePrograms(ctx context.Context) {
+	for {
+		closed, err := w.closeNextProgram(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("programs: close-out failed", "error", err)
+			}
+			return
+		}
+		if !closed {
+			return
+		}
+	}
+}
+
+// closeNextProgram closes one due program. It reports false when none is due.
+func (w *Worker) closeNextProgram(ctx context.Context) (bool, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	var budget string
+	var rawScoring []byte
+	err = tx.QueryRow(ctx, `
+SELECT id, budget::text, scoring FROM programs
+WHERE status = 'active' AND ends_at <= now() - $1 * interval '1 second'
+ORDER BY ends_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`, int64(w.cfg.ProgramCloseDelay.Seconds())).Scan(&id, &budget, &rawScoring)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	var rules programs.Scoring
+	if err := json.Unmarshal(rawScoring, &rules); err != nil {
+		return false, err
+	}
+	budgetUnits, err := programs.ToUnits(budget)
+	if err != nil {
+		return false, err
+	}
+	scores, err := programs.Scores(ctx, tx, id, rules)
+	if err != nil {
+		return false, err
+	}
+	allocations := programs.Allocate(budgetUnits, scores, rules.MinScore)
+	for _, a := range allocations {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO program_allocations (program_id, rank, login, pull_requests, merged_pull_requests, issues, commits, score, amount, amount_units)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::numeric, $10::numeric)
+`, id, a.Rank, a.Login, a.PullRequests, a.MergedPullRequests, a.Issues, a.Commits, a.Score.Score,
+			programs.FormatUnits(a.Units), a.Units.String()); err != nil {
+			return false, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE programs SET status = 'closed', closed_at = now(), updated_at = now() WHERE id = $1
+`, id); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	slog.Info("program closed", "program_id", id, "contributors", len(scores), "allocations", len(allocations))
+	return true, nil
+}
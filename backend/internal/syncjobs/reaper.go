@@ -0,0 +1,78 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// heartbeat keeps a running job's lock fresh so the reaper can tell a long job from an abandoned
+// one. The returned func stops it.
+func (w *Worker) heartbeat(ctx context.Context, jobID uuid.UUID) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	interval := w.cfg.SyncJobVisibilityTimeout / 3
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				_, err := w.pool.Exec(ctx, `
+UPDATE sync_jobs SET locked_at = now() WHERE id = $1 AND status = 'running' AND locked_by = $2
+`, jobID, w.workerID)
+				if err != nil && ctx.Err() == nil {
+					slog.Warn("sync job heartbeat failed", "job_id", jobID, "error", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// reapStuckJobs puts running jobs whose worker stopped heartbeating (crashed, killed, lost its DB
+// connection) back in the queue. The abandoned run counts as an attempt, so a job that keeps
+// killing its worker ends up dead instead of looping.
+func (w *Worker) reapStuckJobs(ctx context.Context) {
+	rows, err := w.pool.Query(ctx, `
+UPDATE sync_jobs
+SET status = CASE WHEN attempts + 1 >= $2 THEN 'dead' ELSE 'retrying' END,
+    attempts = attempts + 1,
+    last_error = 'abandoned by worker ' || COALESCE(locked_by, 'unknown'),
+    run_at = now(),
+    locked_at = NULL,
+    locked_by = NULL,
+    updated_at = now()
+WHERE status = 'running'
+  AND locked_at < now() - $1 * interval '1 second'
+RETURNING id, project_id, job_type, status, attempts, last_error
+`, int64(w.cfg.SyncJobVisibilityTimeout.Seconds()), w.cfg.SyncJobMaxAttempts)
+	if err != nil {
+		slog.Warn("stuck job reaper failed", "error", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, projectID uuid.UUID
+		var jobType, status, reason string
+		var attempts int
+		if err := rows.Scan(&id, &projectID, &jobType, &status, &attempts, &reason); err != nil {
+			slog.Warn("stuck job reaper failed", "error", err)
+			return
+		}
+		slog.Warn("reaped stuck sync job",
+			"job_id", id,
+			"job_type", jobType,
+			"project_id", projectID,
+			"status", status,
+			"attempts", attempts,
+			"reason", reason,
+		)
+	}
+}
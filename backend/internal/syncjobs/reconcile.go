@@ -0,0 +1,357 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+)
+
+const (
+	// reconcileBatch caps how many payouts and escrows one pass checks.
+	reconcileBatch = 50
+	// reconcilePaymentPages caps how many pages of custody account payments one pass reads.
+	reconcilePaymentPages = 5
+)
+
+// Reconciliation issue kinds (reconciliation_issues.kind).
+const (
+	issueMissingTx           = "missing_tx"
+	issueTxFailed            = "tx_failed"
+	issueAmountDrift         = "amount_drift"
+	issueDestinationMismatch = "destination_mismatch"
+	issueAssetMismatch       = "asset_mismatch"
+	issueMissingEvent        = "missing_event"
+	issueUnknownPayment      = "unknown_payment"
+)
+
+// reconIssue is a mismatch one check found.
+type reconIssue struct {
+	kind, expected, actual, detail string
+}
+
+// reconSubject is what a check looked at, and the issue kinds the check can raise for it.
+type reconSubject struct {
+	key      string
+	payoutID *uuid.UUID
+	escrowID *uuid.UUID
+	txHash   *string
+	kinds    []string
+}
+
+// reconcile cross-checks settled payouts with the chain: confirmed payouts against their Horizon
+// transaction, released escrows against the escrow_released event the indexer stored, and payments
+// sent from the custody account against the payouts they should settle. Each row is checked once
+// it settles, and again on every pass while it has open issues. Passes are serialized across
+// workers.
+func (w *Worker) reconcile(ctx context.Context) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		slog.Warn("reconciliation: begin failed", "error", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended('reconciliation', 0))`).Scan(&locked); err != nil || !locked {
+		return
+	}
+
+	if err := w.reconcilePayouts(ctx, tx); err != nil {
+		slog.Warn("reconciliation: payouts failed", "error", err)
+		return
+	}
+	if err := reconcileEscrows(ctx, tx); err != nil {
+		slog.Warn("reconciliation: escrows failed", "error", err)
+		return
+	}
+	if err := w.reconcileCustody(ctx, tx); err != nil {
+		slog.Warn("reconciliation: custody payments failed", "error", err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Warn("reconciliation: commit failed", "error", err)
+	}
+}
+
+type reconPayout struct {
+	id                             uuid.UUID
+	destination, amount            string
+	assetCode, assetIssuer, txHash string
+}
+
+// reconcilePayouts checks confirmed payouts against Horizon. A payout whose lookup fails is left for
+// the next pass.
+func (w *Worker) reconcilePayouts(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `
+SELECT p.id, p.destination, p.amount::text, p.asset, COALESCE(p.asset_issuer, ''), p.tx_hash
+FROM payouts p
+WHERE p.status = 'confirmed' AND p.tx_hash IS NOT NULL
+  AND (p.reconciled_at IS NULL OR EXISTS (
+    SELECT 1 FROM reconciliation_issues ri WHERE ri.payout_id = p.id AND ri.status = 'open'
+  ))
+ORDER BY p.reconciled_at NULLS FIRST, p.confirmed_at
+LIMIT $1
+`, reconcileBatch)
+	if err != nil {
+		return err
+	}
+	payouts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (reconPayout, error) {
+		var p reconPayout
+		err := row.Scan(&p.id, &p.destination, &p.amount, &p.assetCode, &p.assetIssuer, &p.txHash)
+		return p, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range payouts {
+		rec, err := w.history.Transaction(ctx, p.txHash)
+		if err != nil {
+			slog.Warn("reconciliation: transaction lookup failed", "payout_id", p.id, "tx_hash", p.txHash, "error", err)
+			continue
+		}
+		id, hash := p.id, p.txHash
+		subject := reconSubject{
+			key: "payout:" + p.id.String(), payoutID: &id, txHash: &hash,
+			kinds: []string{issueMissingTx, issueTxFailed, issueDestinationMismatch, issueAssetMismatch, issueAmountDrift},
+		}
+		if err := recordReconciliation(ctx, tx, subject, checkPayout(p, rec)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE payouts SET reconciled_at = now() WHERE id = $1`, p.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPayout compares a confirmed payout with its transaction on Horizon.
+func checkPayout(p reconPayout, rec stellar.TxRecord) []reconIssue {
+	switch {
+	case !rec.Found:
+		return []reconIssue{{kind: issueMissingTx, expected: p.txHash, detail: "Confirmed payout transaction is not on the network"}}
+	case !rec.Successful:
+		return []reconIssue{{kind: issueTxFailed, expected: p.txHash, detail: fmt.Sprintf("Confirmed payout transaction failed in ledger %d", rec.Ledger)}}
+	}
+	var paid *stellar.Transfer
+	var others []string
+	for i, t := range rec.Transfers {
+		if t.To == p.destination {
+			paid = &rec.Transfers[i]
+			break
+		}
+		others = append(others, t.To)
+	}
+	if paid == nil {
+		return []reconIssue{{kind: issueDestinationMismatch, expected: p.destination, actual: strings.Join(others, ", "),
+			detail: "Payout transaction pays no operation to the payout destination"}}
+	}
+	var issues []reconIssue
+	if want, got := assetString(p.assetCode, p.assetIssuer), assetString(paid.AssetCode, paid.AssetIssuer); want != got {
+		issues = append(issues, reconIssue{kind: issueAssetMismatch, expected: want, actual: got, detail: "Payout was paid in another asset"})
+	}
+	if !sameAmount(p.amount, paid.Amount) {
+		issues = append(issues, reconIssue{kind: issueAmountDrift, expected: p.amount, actual: paid.Amount, detail: "Paid amount differs from the payout amount"})
+	}
+	return issues
+}
+
+type reconEscrow struct {
+	id                                      uuid.UUID
+	contractID                              string
+	onchainID                               int64
+	amountUnits, txHash                     string
+	approvedTo, eventAmount, eventRecipient *string
+	hasEvent                                bool
+}
+
+// reconcileEscrows checks released escrows against the escrow_released event indexed for their
+// release transaction: it must exist, release the escrowed amount, and pay the approved recipient.
+func reconcileEscrows(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `
+SELECT be.id, be.contract_id, be.onchain_id, be.amount_units::text, be.release_tx_hash,
+  (SELECT a.recipient FROM bounty_escrow_approvals a
+   WHERE a.escrow_id = be.id AND a.action = 'release' AND a.recipient IS NOT NULL
+   ORDER BY a.created_at LIMIT 1),
+  ev.id IS NOT NULL, ev.payload->>'amount', ev.payload->>'recipient'
+FROM bounty_escrows be
+LEFT JOIN LATERAL (
+  SELECT se.id, se.payload FROM soroban_events se
+  WHERE se.event_name = 'escrow_released' AND se.parse_error IS NULL AND se.in_successful_contract_call
+    AND se.contract_id = be.contract_id AND se.tx_hash = be.release_tx_hash
+    AND se.payload->>'bounty_id' = be.onchain_id::text
+  LIMIT 1
+) ev ON true
+WHERE be.status = 'released' AND be.release_tx_hash IS NOT NULL
+  AND (be.reconciled_at IS NULL OR EXISTS (
+    SELECT 1 FROM reconciliation_issues ri WHERE ri.escrow_id = be.id AND ri.status = 'open'
+  ))
+ORDER BY be.reconciled_at NULLS FIRST, be.released_at
+LIMIT $1
+`, reconcileBatch)
+	if err != nil {
+		return err
+	}
+	escrows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (reconEscrow, error) {
+		var e reconEscrow
+		err := row.Scan(&e.id, &e.contractID, &e.onchainID, &e.amountUnits, &e.txHash, &e.approvedTo,
+			&e.hasEvent, &e.eventAmount, &e.eventRecipient)
+		return e, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range escrows {
+		id, hash := e.id, e.txHash
+		subject := reconSubject{
+			key: "escrow:" + e.id.String(), escrowID: &id, txHash: &hash,
+			kinds: []string{issueMissingEvent, issueAmountDrift, issueDestinationMismatch},
+		}
+		if err := recordReconciliation(ctx, tx, subject, checkEscrow(e)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE bounty_escrows SET reconciled_at = now() WHERE id = $1`, e.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEscrow compares a released escrow with its indexed escrow_released event.
+func checkEscrow(e reconEscrow) []reconIssue {
+	if !e.hasEvent {
+		return []reconIssue{{kind: issueMissingEvent, expected: fmt.Sprintf("escrow_released for bounty %d", e.onchainID),
+			detail: "No valid escrow_released event is indexed for the release transaction"}}
+	}
+	var issues []reconIssue
+	if e.eventAmount == nil || *e.eventAmount != e.amountUnits {
+		issues = append(issues, reconIssue{kind: issueAmountDrift, expected: e.amountUnits, actual: deref(e.eventAmount),
+			detail: "Released amount (base units) differs from the escrowed amount"})
+	}
+	if e.approvedTo != nil && deref(e.eventRecipient) != *e.approvedTo {
+		issues = append(issues, reconIssue{kind: issueDestinationMismatch, expected: *e.approvedTo, actual: deref(e.eventRecipient),
+			detail: "Escrow was released to another account than the approved recipient"})
+	}
+	return issues
+}
+
+// reconcileCustody reads the payments sent from the payout custody account since the last pass and
+// flags the ones that settle no submitted or confirmed payout. Its Horizon paging token is kept in
+// soroban_indexer_cursors, like the indexer's deposit watcher.
+func (w *Worker) reconcileCustody(ctx context.Context, tx pgx.Tx) error {
+	if w.payer == nil {
+		return nil
+	}
+	account := w.payer.Source()
+	name := "reconcile:" + account
+	var cursor *string
+	err := tx.QueryRow(ctx, `SELECT cursor FROM soroban_indexer_cursors WHERE name = $1`, name).Scan(&cursor)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	from := ""
+	if cursor != nil {
+		from = *cursor
+	}
+
+	for page := 0; page < reconcilePaymentPages; page++ {
+		transfers, next, err := w.history.Payments(ctx, account, from, 200)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			break
+		}
+		for _, t := range transfers {
+			if err := checkCustodyPayment(ctx, tx, t); err != nil {
+				return err
+			}
+		}
+		from = next
+	}
+
+	if from == "" || cursor != nil && from == *cursor {
+		return nil
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO soroban_indexer_cursors (name, start_ledger, cursor) VALUES ($1, 0, $2)
+ON CONFLICT (name) DO UPDATE SET cursor = EXCLUDED.cursor, updated_at = now()
+`, name, from)
+	return err
+}
+
+// checkCustodyPayment flags a custody account payment that no live payout accounts for.
+func checkCustodyPayment(ctx context.Context, tx pgx.Tx, t stellar.Transfer) error {
+	var payoutID *uuid.UUID
+	var status string
+	err := tx.QueryRow(ctx, `SELECT id, status FROM payouts WHERE tx_hash = $1`, t.TxHash).Scan(&payoutID, &status)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if status == "submitted" || status == "confirmed" {
+		return nil
+	}
+	detail := "Custody account payment matches no payout"
+	if payoutID != nil {
+		detail = fmt.Sprintf("Custody account payment belongs to a payout that is %s", status)
+	}
+	hash := t.TxHash
+	subject := reconSubject{key: "tx:" + t.TxHash, payoutID: payoutID, txHash: &hash, kinds: []string{issueUnknownPayment}}
+	return recordReconciliation(ctx, tx, subject, []reconIssue{{
+		kind: issueUnknownPayment, actual: fmt.Sprintf("%s %s to %s", t.Amount, assetString(t.AssetCode, t.AssetIssuer), t.To), detail: detail,
+	}})
+}
+
+// recordReconciliation opens (or refreshes) an issue for each mismatch found and resolves the
+// subject's open issues of the checked kinds that were not found again.
+func recordReconciliation(ctx context.Context, tx pgx.Tx, s reconSubject, found []reconIssue) error {
+	still := make([]string, 0, len(found))
+	for _, is := range found {
+		still = append(still, is.kind)
+		if _, err := tx.Exec(ctx, `
+INSERT INTO reconciliation_issues (kind, subject, payout_id, escrow_id, tx_hash, expected, actual, detail)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8)
+ON CONFLICT (kind, subject) WHERE status = 'open' DO UPDATE SET
+  expected = EXCLUDED.expected, actual = EXCLUDED.actual, detail = EXCLUDED.detail, last_seen_at = now()
+`, is.kind, s.key, s.payoutID, s.escrowID, s.txHash, is.expected, is.actual, is.detail); err != nil {
+			return err
+		}
+		slog.Warn("reconciliation issue", "kind", is.kind, "subject", s.key, "expected", is.expected, "actual", is.actual)
+	}
+	_, err := tx.Exec(ctx, `
+UPDATE reconciliation_issues
+SET status = 'resolved', resolved_at = now(), resolution_note = 'No longer detected'
+WHERE subject = $1 AND status = 'open' AND kind = ANY($2) AND NOT (kind = ANY($3))
+`, s.key, s.kinds, still)
+	return err
+}
+
+// assetString formats an asset as CODE or CODE:ISSUER.
+func assetString(code, issuer string) string {
+	if issuer == "" {
+		return code
+	}
+	return code + ":" + issuer
+}
+
+// sameAmount compares two decimal amounts ("250.5" and "250.5000000" are the same).
+func sameAmount(a, b string) bool {
+	x, ok1 := new(big.Rat).SetString(a)
+	y, ok2 := new(big.Rat).SetString(b)
+	return ok1 && ok2 && x.Cmp(y) == 0
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,120 @@
+package syncjobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// registryHeartbeat is how often a worker refreshes its row in workers. /admin/workers reports a
+// worker as dead after a few missed beats.
+const registryHeartbeat = 15 * time.Second
+
+// activity is what this process is doing, published in its workers row.
+type activity struct {
+	mu        sync.Mutex
+	running   map[uuid.UUID]activeJob
+	completed atomic.Int64
+	failed    atomic.Int64
+}
+
+type activeJob struct {
+	JobID     uuid.UUID `json:"job_id"`
+	JobType   string    `json:"job_type"`
+	ProjectID uuid.UUID `json:"project_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (a *activity) start(j activeJob) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running == nil {
+		a.running = map[uuid.UUID]activeJob{}
+	}
+	a.running[j.JobID] = j
+}
+
+func (a *activity) finish(jobID uuid.UUID, failed bool) {
+	a.mu.Lock()
+	delete(a.running, jobID)
+	a.mu.Unlock()
+	if failed {
+		a.failed.Add(1)
+	} else {
+		a.completed.Add(1)
+	}
+}
+
+func (a *activity) current() []activeJob {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]activeJob, 0, len(a.running))
+	for _, j := range a.running {
+		out = append(out, j)
+	}
+	return out
+}
+
+// registerHeartbeat upserts this worker's row with its current jobs and counts.
+func (w *Worker) registerHeartbeat(ctx context.Context) {
+	current, _ := json.Marshal(w.activity.current())
+	_, err := w.pool.Exec(ctx, `
+INSERT INTO workers (id, hostname, version, concurrency, current_jobs, jobs_completed, jobs_failed, started_at, last_heartbeat_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+ON CONFLICT (id) DO UPDATE SET
+  version = EXCLUDED.version,
+  concurrency = EXCLUDED.concurrency,
+  current_jobs = EXCLUDED.current_jobs,
+  jobs_completed = EXCLUDED.jobs_completed,
+  jobs_failed = EXCLUDED.jobs_failed,
+  started_at = EXCLUDED.started_at,
+  last_heartbeat_at = now(),
+  stopped_at = NULL
+`, w.workerID, hostname(), buildVersion(), w.concurrency(), current, w.activity.completed.Load(), w.activity.failed.Load(), w.startedAt)
+	if err != nil {
+		slog.Warn("worker registry heartbeat failed", "worker_id", w.workerID, "error", err)
+	}
+}
+
+// deregister marks the worker stopped on a clean shutdown, so it isn't reported as dead.
+func (w *Worker) deregister() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = w.pool.Exec(ctx, `
+UPDATE workers SET stopped_at = now(), current_jobs = '[]'::jsonb WHERE id = $1
+`, w.workerID)
+}
+
+// purgeWorkers drops rows of workers not seen for a day.
+func (w *Worker) purgeWorkers(ctx context.Context) {
+	if _, err := w.pool.Exec(ctx, `DELETE FROM workers WHERE last_heartbeat_at < now() - interval '1 day'`); err != nil {
+		slog.Warn("worker registry purge failed", "error", err)
+	}
+}
+
+func (w *Worker) concurrency() int {
+	return max(w.cfg.SyncWorkerConcurrency, 1)
+}
+
+// buildVersion is the VCS revision the binary was built from, or "dev".
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			if len(s.Value) > 12 {
+				return s.Value[:12]
+			}
+			return s.Value
+		}
+	}
+	return "dev"
+}
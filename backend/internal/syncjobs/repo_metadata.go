@@ -0,0 +1,109 @@
+package syncjobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// refreshRepoMetadata re-fetches the repo and updates the listing fields, reconciling
+// github_full_name if the repo was renamed or transferred. A 404 means the repo was deleted or is no
+// longer visible to the token, so the project is hidden rather than failing the job.
+func (w *Worker) refreshRepoMetadata(ctx context.Context, projectID uuid.UUID, fullName string, token string) error {
+	if err := w.wait(ctx, token, "core"); err != nil {
+		return err
+	}
+	// Look the repo up by ID when known so renames and transfers are followed rather than 404ing.
+	var repoID *int64
+	if err := w.pool.QueryRow(ctx, `SELECT github_repo_id FROM projects WHERE id = $1`, projectID).Scan(&repoID); err != nil {
+		return err
+	}
+	var repo github.Repo
+	var err error
+	if repoID != nil && *repoID != 0 {
+		repo, err = w.gh.GetRepoByID(ctx, token, *repoID)
+	} else {
+		repo, err = w.gh.GetRepo(ctx, token, fullName)
+	}
+	if err != nil {
+		var apiErr *github.GitHubAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			slog.Warn("repo no longer available, hiding project",
+				"project_id", projectID,
+				"repo", fullName,
+			)
+			return github.MarkRepoUnavailable(ctx, w.pool, projectID)
+		}
+		return err
+	}
+	if repo.FullName != fullName {
+		if _, err := github.UpdateRepoFullName(ctx, w.pool, repo.ID, repo.FullName); err != nil {
+			return err
+		}
+		slog.Info("project repository renamed",
+			"project_id", projectID,
+			"from", fullName,
+			"to", repo.FullName,
+		)
+	}
+	if err := github.SaveRepoMetadata(ctx, w.pool, projectID, repo); err != nil {
+		return err
+	}
+
+	// Language breakdown is secondary; a failure here shouldn't fail the refresh.
+	if err := w.wait(ctx, token, "core"); err != nil {
+		return err
+	}
+	langs, err := w.gh.GetRepoLanguages(ctx, token, repo.FullName)
+	if err == nil {
+		err = github.SaveRepoLanguages(ctx, w.pool, projectID, langs)
+	}
+	if err != nil {
+		slog.Warn("repo languages refresh failed", "project_id", projectID, "repo", repo.FullName, "error", err)
+	}
+
+	// Same for the README shown on the project page.
+	if err := w.wait(ctx, token, "core"); err != nil {
+		return err
+	}
+	if err := github.RefreshReadme(ctx, w.pool, w.gh, projectID, token, repo.FullName); err != nil {
+		slog.Warn("repo readme refresh failed", "project_id", projectID, "repo", repo.FullName, "error", err)
+	}
+	return nil
+}
+
+// enqueueMetadataRefresh queues a refresh_repo_metadata job for verified projects whose metadata is
+// older than the configured interval. Projects with a job already pending or running are skipped,
+// so running this on every instance is safe.
+func (w *Worker) enqueueMetadataRefresh(ctx context.Context) {
+	ct, err := w.pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+SELECT p.id, 'refresh_repo_metadata', 'pending', now()
+FROM projects p
+WHERE p.status = 'verified'
+  AND p.provider = 'github'
+  AND p.deleted_at IS NULL
+  AND (p.repo_metadata_updated_at IS NULL OR p.repo_metadata_updated_at < now() - $1 * interval '1 second')
+  AND NOT EXISTS (
+    SELECT 1 FROM sync_jobs j
+    WHERE j.project_id = p.id
+      AND j.job_type = 'refresh_repo_metadata'
+      AND j.status IN ('pending', 'running', 'retrying')
+  )
+ORDER BY p.repo_metadata_updated_at NULLS FIRST
+LIMIT 500
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, int64(w.cfg.RepoMetadataRefreshInterval.Seconds()))
+	if err != nil {
+		slog.Warn("repo metadata refresh enqueue failed", "error", err)
+		return
+	}
+	if n := ct.RowsAffected(); n > 0 {
+		slog.Info("repo metadata refresh jobs enqueued", "count", n)
+	}
+}
@@ -0,0 +1,107 @@
+package syncjobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/repohost"
+)
+
+// runHostJob runs a job for a project on a non-GitHub host. Only issue sync goes through the
+// repohost interface; other job types depend on GitHub-only APIs and complete as no-ops.
+func (w *Worker) runHostJob(ctx context.Context, jobID uuid.UUID, projectID uuid.UUID, ownerUserID uuid.UUID, provider string, fullName string, jobType string) error {
+	host, ok := w.hosts.Get(provider)
+	if !ok {
+		return fmt.Errorf("%s_not_configured", provider)
+	}
+	if jobType != "sync_issues" {
+		slog.Info("sync job not supported for provider, skipping",
+			"job_id", jobID,
+			"job_type", jobType,
+			"project_id", projectID,
+			"provider", provider,
+		)
+		return nil
+	}
+
+	token, err := host.Token(ctx, projectID, ownerUserID)
+	if err != nil {
+		return fmt.Errorf("%s_not_linked: %w", provider, err)
+	}
+	started := time.Now().Add(-time.Minute)
+	err = w.withCursor(ctx, projectID, "issues", func(since time.Time) error {
+		return w.syncHostIssues(ctx, host, projectID, fullName, token, since)
+	})
+	if err != nil {
+		slog.Error("sync job failed",
+			"job_id", jobID,
+			"job_type", jobType,
+			"project_id", projectID,
+			"provider", provider,
+			"repo", fullName,
+			"error", err,
+		)
+		return err
+	}
+	w.afterContributionSync(ctx, jobID, projectID, started)
+	return nil
+}
+
+// syncHostIssues stores a host's issues in github_issues, keyed by the host's issue ID. Hosts list
+// issues most recently updated first, so the walk stops at the sync cursor.
+func (w *Worker) syncHostIssues(ctx context.Context, host repohost.Host, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	total := 0
+	cursor, more := "", true
+	for page := 1; more; page++ {
+		if page > maxListPages {
+			slog.Warn("page limit reached, stopping", "project_id", projectID, "repo", fullName, "pages", maxListPages)
+			break
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		items, next, err := host.ListIssuesPage(ctx, token, fullName, cursor)
+		if err != nil {
+			return err
+		}
+		cursor, more = next, next != ""
+
+		for _, it := range items {
+			if !since.IsZero() && it.UpdatedAt != nil && it.UpdatedAt.Before(since) {
+				more = false
+				break
+			}
+			total++
+			_, err := w.pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, title, body, author_login, url, created_at_github, updated_at_github, closed_at_github, last_seen_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now())
+ON CONFLICT (project_id, github_issue_id) DO UPDATE SET
+  number = EXCLUDED.number,
+  state = EXCLUDED.state,
+  title = EXCLUDED.title,
+  body = EXCLUDED.body,
+  author_login = EXCLUDED.author_login,
+  url = EXCLUDED.url,
+  created_at_github = COALESCE(EXCLUDED.created_at_github, github_issues.created_at_github),
+  updated_at_github = COALESCE(EXCLUDED.updated_at_github, github_issues.updated_at_github),
+  closed_at_github = COALESCE(EXCLUDED.closed_at_github, github_issues.closed_at_github),
+  last_seen_at = now()
+`, projectID, it.ID, it.Number, it.State, it.Title, it.Body, it.AuthorLogin, it.URL, it.CreatedAt, it.UpdatedAt, it.ClosedAt)
+			if err != nil {
+				slog.Warn("issue upsert failed", "project_id", projectID, "issue_number", it.Number, "error", err)
+			}
+		}
+	}
+
+	slog.Info("sync issues completed",
+		"project_id", projectID,
+		"provider", host.Name(),
+		"repo", fullName,
+		"total_issues", total,
+	)
+	return nil
+}
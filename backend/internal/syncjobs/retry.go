@@ -0,0 +1,24 @@
+package syncjobs
+
+import (
+	"math/rand"
+	"time"
+)
+
+// retryDelay is the backoff before retry number attempt (1 for the first retry): base doubled per
+// attempt, capped at maxDelay, with the upper half jittered so jobs that failed together (e.g. during a
+// GitHub outage) don't all come back at once.
+func retryDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	if d > maxDelay {
+		d = maxDelay
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
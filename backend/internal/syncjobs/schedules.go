@@ -0,0 +1,93 @@
+package syncjobs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// runSchedules enqueues issue and PR syncs for projects whose sync schedule is due. Full-sync
+// schedules clear the incremental cursors first so the jobs re-list everything. next_run_at
+// advances by whole intervals past now, keeping runs on their original grid after a missed tick.
+func (w *Worker) runSchedules(ctx context.Context) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		slog.Warn("sync schedules failed", "error", err)
+		return
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+SELECT s.project_id, s.full_sync
+FROM sync_schedules s
+JOIN projects p ON p.id = s.project_id
+WHERE s.enabled
+  AND s.next_run_at <= now()
+  AND p.status = 'verified'
+  AND p.deleted_at IS NULL
+ORDER BY s.next_run_at
+FOR UPDATE OF s SKIP LOCKED
+LIMIT 100
+`)
+	if err != nil {
+		slog.Warn("sync schedules failed", "error", err)
+		return
+	}
+	type due struct {
+		projectID uuid.UUID
+		full      bool
+	}
+	var dues []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.projectID, &d.full); err != nil {
+			rows.Close()
+			slog.Warn("sync schedules failed", "error", err)
+			return
+		}
+		dues = append(dues, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		slog.Warn("sync schedules failed", "error", err)
+		return
+	}
+
+	for _, d := range dues {
+		if d.full {
+			if _, err := tx.Exec(ctx, `
+UPDATE project_sync_cursors SET last_full_sync_at = NULL, updated_at = now() WHERE project_id = $1
+`, d.projectID); err != nil {
+				slog.Warn("sync schedules failed", "project_id", d.projectID, "error", err)
+				return
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+VALUES ($1, 'sync_issues', 'pending', now()),
+       ($1, 'sync_prs', 'pending', now())
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, d.projectID); err != nil {
+			slog.Warn("sync schedules failed", "project_id", d.projectID, "error", err)
+			return
+		}
+		if _, err := tx.Exec(ctx, `
+UPDATE sync_schedules
+SET last_run_at = now(),
+    next_run_at = next_run_at + (floor(extract(epoch FROM now() - next_run_at) / interval_seconds) + 1) * interval_seconds * interval '1 second',
+    updated_at = now()
+WHERE project_id = $1
+`, d.projectID); err != nil {
+			slog.Warn("sync schedules failed", "project_id", d.projectID, "error", err)
+			return
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.Warn("sync schedules failed", "error", err)
+		return
+	}
+	if len(dues) > 0 {
+		slog.Info("scheduled syncs enqueued", "count", len(dues))
+	}
+}
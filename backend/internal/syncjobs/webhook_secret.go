@@ -0,0 +1,97 @@
+package syncjobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jagadeesh/grainlify/backend/internal/github"
+)
+
+// rotateWebhookSecret gives the project's repo hook a fresh secret. The new secret is stored before
+// GitHub is told about it, with the old one kept as previous, so deliveries signed with either keep
+// validating until the grace window ends. Installation-mode projects have no repo hook and are skipped.
+func (w *Worker) rotateWebhookSecret(ctx context.Context, projectID uuid.UUID, fullName string, ghToken github.ProjectToken) error {
+	if ghToken.Mode == github.AuthModeInstallation {
+		return nil
+	}
+	var hookID *int64
+	var hookURL *string
+	if err := w.pool.QueryRow(ctx, `
+SELECT webhook_id, webhook_url FROM projects WHERE id = $1
+`, projectID).Scan(&hookID, &hookURL); err != nil {
+		return err
+	}
+	if hookID == nil || *hookID == 0 {
+		return nil
+	}
+	target := strings.TrimRight(w.cfg.PublicBaseURL, "/") + "/webhooks/github"
+	if hookURL != nil && *hookURL != "" {
+		target = *hookURL
+	}
+
+	old, err := github.ProjectWebhookSecret(ctx, w.pool, projectID, w.cfg.TokenEncKeyB64)
+	if err != nil {
+		return err
+	}
+	if old == "" {
+		old = w.cfg.GitHubWebhookSecret
+	}
+	secret, err := github.NewWebhookSecret()
+	if err != nil {
+		return err
+	}
+	if err := w.wait(ctx, ghToken.Token, "core"); err != nil {
+		return err
+	}
+	if err := github.SaveWebhookSecret(ctx, w.pool, projectID, secret, old, w.cfg.TokenEncKeyB64); err != nil {
+		return err
+	}
+
+	if err := w.gh.UpdateWebhookConfig(ctx, ghToken.Token, fullName, *hookID, target, secret); err != nil {
+		// GitHub still signs with the old secret: make it current again, keeping the new one as previous.
+		if rbErr := github.SaveWebhookSecret(ctx, w.pool, projectID, old, secret, w.cfg.TokenEncKeyB64); rbErr != nil {
+			slog.Error("webhook secret rollback failed", "project_id", projectID, "error", rbErr)
+		}
+		return fmt.Errorf("webhook_update_failed: %w", err)
+	}
+
+	slog.Info("webhook secret rotated", "project_id", projectID, "repo", fullName, "hook_id", *hookID)
+	return nil
+}
+
+// enqueueWebhookSecretRotation queues rotate_webhook_secret for projects with a repo hook whose
+// secret is older than the rotation interval (hooks that were never rotated count from creation).
+func (w *Worker) enqueueWebhookSecretRotation(ctx context.Context) {
+	if w.cfg.GitHubWebhookSecret == "" || w.cfg.TokenEncKeyB64 == "" {
+		return
+	}
+	ct, err := w.pool.Exec(ctx, `
+INSERT INTO sync_jobs (project_id, job_type, status, run_at)
+SELECT p.id, 'rotate_webhook_secret', 'pending', now()
+FROM projects p
+WHERE p.status = 'verified'
+  AND p.provider = 'github'
+  AND p.deleted_at IS NULL
+  AND p.webhook_id IS NOT NULL
+  AND COALESCE(p.webhook_secret_rotated_at, p.webhook_created_at, p.created_at) < now() - $1 * interval '1 second'
+  AND NOT EXISTS (
+    SELECT 1 FROM sync_jobs j
+    WHERE j.project_id = p.id
+      AND j.job_type = 'rotate_webhook_secret'
+      AND j.status IN ('pending', 'running', 'retrying')
+  )
+LIMIT 500
+ON CONFLICT (project_id, job_type) WHERE status = 'pending' DO NOTHING
+`, int64(w.cfg.WebhookSecretRotationInterval.Seconds()))
+	if err != nil {
+		slog.Warn("webhook secret rotation enqueue failed", "error", err)
+		return
+	}
+	if n := ct.RowsAffected(); n > 0 {
+		slog.Info("webhook secret rotation jobs enqueued", "count", n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,8 +15,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/time/rate"
 
+	"github.com/jagadeesh/grainlify/backend/internal/achievements"
+	"github.com/jagadeesh/grainlify/backend/internal/assets"
+	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
+	"github.com/jagadeesh/grainlify/backend/internal/contributions"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
+	"github.com/jagadeesh/grainlify/backend/internal/mail"
+	"github.com/jagadeesh/grainlify/backend/internal/repohost"
+	"github.com/jagadeesh/grainlify/backend/internal/stellar"
+	"github.com/jagadeesh/grainlify/backend/internal/stellarnet"
 )
 
 type Worker struct {
@@ -23,36 +33,234 @@ type Worker struct {
 	pool    *pgxpool.Pool
 	limiter *rate.Limiter
 	gh      *github.Client
+	apps    *github.InstallationTokenSource
+	hosts   repohost.Registry
+	mailer  mail.Mailer
+	payer   *stellar.Payer
+	history *stellar.History
+	prices  assets.PriceSource
+	bus     bus.Bus
 	workerID string
+	wake     chan struct{}
+
+	startedAt time.Time
+	activity  activity
 }
 
 func New(cfg config.Config, pool *pgxpool.Pool) *Worker {
+	apps, err := github.NewInstallationTokenSource(cfg.GitHubAppID, cfg.GitHubAppPrivateKey)
+	if err != nil {
+		slog.Warn("failed to init github app client (sync will use owner oauth tokens)", "error", err)
+	}
+	payer, err := newPayer(cfg)
+	if err != nil {
+		slog.Warn("bounty payouts disabled: invalid payout signer", "error", err)
+	}
+	prices, err := assets.NewPriceSource(cfg.AssetPriceSource, cfg.AssetPriceIDs)
+	if err != nil {
+		slog.Warn("asset prices disabled: invalid price source", "error", err)
+	}
 	return &Worker{
 		cfg:      cfg,
 		pool:     pool,
 		limiter:  rate.NewLimiter(rate.Every(250*time.Millisecond), 2), // ~4 req/s, burst 2
-		gh:       github.NewClient(),
+		gh:       newGitHubClient(pool),
+		apps:     apps,
+		hosts:    repohost.NewRegistry(cfg, pool, github.NewClient(), apps),
+		mailer: mail.New(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}),
+		payer:    payer,
+		history:  stellar.NewHistory(stellarnet.FromConfig(cfg).HorizonURL),
+		prices:   prices,
 		workerID: fmt.Sprintf("%s:%d", hostname(), os.Getpid()),
+		wake:     make(chan struct{}, max(cfg.SyncWorkerConcurrency, 1)),
 	}
 }
 
+// SetBus makes the worker announce the bounties its payouts pay on b. Without a bus the bounty
+// issue sweep picks them up.
+func (w *Worker) SetBus(b bus.Bus) {
+	w.bus = b
+}
+
+func newGitHubClient(pool *pgxpool.Pool) *github.Client {
+	gh := github.NewClient()
+	if pool != nil {
+		gh.Cache = github.NewPostgresHTTPCache(pool)
+		gh.Budget = github.NewRateBudget(pool)
+	}
+	return gh
+}
+
 func (w *Worker) Run(ctx context.Context) error {
 	if w.pool == nil {
 		return fmt.Errorf("db not configured")
 	}
-	t := time.NewTicker(1 * time.Second)
-	defer t.Stop()
+	n := w.concurrency()
+	w.startedAt = time.Now().UTC()
+	w.registerHeartbeat(ctx)
+	// Runs after the loops below have finished their jobs.
+	defer w.deregister()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runLoop(ctx)
+		}()
+	}
+	defer wg.Wait()
+
+	purge := time.NewTicker(6 * time.Hour)
+	defer purge.Stop()
+	refresh := time.NewTicker(15 * time.Minute)
+	defer refresh.Stop()
+	// Stuck-job reaping and sync schedules.
+	minute := time.NewTicker(1 * time.Minute)
+	defer minute.Stop()
+	beat := time.NewTicker(registryHeartbeat)
+	defer beat.Stop()
+
+	// Leaderboards are served only from snapshots; build missing or stale ones right away.
+	w.refreshLeaderboards(ctx)
+	w.refreshAssets(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-purge.C:
+			// Drop cached pages for repos that are no longer synced.
+			if n, err := github.NewPostgresHTTPCache(w.pool).PurgeOlderThan(ctx, 30*24*time.Hour); err != nil {
+				slog.Warn("github http cache purge failed", "error", err)
+			} else if n > 0 {
+				slog.Info("github http cache purged", "entries", n)
+			}
+			if w.gh.Budget != nil {
+				if _, err := w.gh.Budget.PurgeExpired(ctx, 24*time.Hour); err != nil {
+					slog.Warn("github rate budget purge failed", "error", err)
+				}
+			}
+			w.purgeWorkers(ctx)
+		case <-beat.C:
+			w.registerHeartbeat(ctx)
+		case <-minute.C:
+			w.reapStuckJobs(ctx)
+			w.runSchedules(ctx)
+			w.runContributionBackfills(ctx)
+			w.runPayouts(ctx)
+			w.syncBountyIssues(ctx)
+		case <-refresh.C:
+			w.enqueueMetadataRefresh(ctx)
+			w.enqueueWebhookSecretRotation(ctx)
+			w.enqueueOwnershipReverify(ctx)
+			w.refreshEcosystemStats(ctx)
+			w.refreshLeaderboards(ctx)
+			w.refreshAssets(ctx)
+			w.closePrograms(ctx)
+			w.reconcile(ctx)
+			w.sendDigests(ctx)
+		}
+	}
+}
+
+// runLoop is one worker slot: every second it drains the queue until no job is claimable.
+func (w *Worker) runLoop(ctx context.Context) {
+	poll := w.cfg.SyncWorkerPollInterval
+	if poll <= 0 {
+		poll = 1 * time.Second
+	}
+	t := time.NewTicker(poll)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
 		case <-t.C:
-			if err := w.processOne(ctx); err != nil && !errors.Is(err, pgx.ErrNoRows) {
-				slog.Error("sync worker error", "error", err)
+		case <-w.wake:
+		}
+		for ctx.Err() == nil {
+			if err := w.processOne(ctx); err != nil {
+				if !errors.Is(err, pgx.ErrNoRows) {
+					slog.Error("sync worker error", "error", err)
+				}
+				break
+			}
+		}
+	}
+}
+
+// maxListPages guards against a runaway Link-header walk (500k items at 100 per page).
+const maxListPages = 5000
+
+// Quota handling: below rateLimitReserve remaining requests the worker waits for the reset when it
+// is near, otherwise the job is rescheduled for the reset time. Below a fifth of the quota,
+// requests are spread evenly over the time left in the window.
+const (
+	rateLimitReserve  = 50
+	maxRateLimitSleep = 2 * time.Minute
+)
+
+// wait paces a request made with token against resource ("core" or "graphql").
+func (w *Worker) wait(ctx context.Context, token string, resource string) error {
+	if rl, ok := w.quota(ctx, token, resource); ok {
+		untilReset := time.Until(rl.Reset)
+		switch {
+		case untilReset <= 0:
+			// Window already reset; the next response refreshes the numbers.
+		case rl.Remaining < rateLimitReserve:
+			if untilReset > maxRateLimitSleep {
+				return &github.RateLimitError{Reset: rl.Reset}
+			}
+			slog.Info("github quota nearly exhausted, waiting for reset",
+				"resource", resource,
+				"remaining", rl.Remaining,
+				"reset", rl.Reset,
+			)
+			if err := sleepCtx(ctx, untilReset); err != nil {
+				return err
+			}
+		case rl.Limit > 0 && rl.Remaining < rl.Limit/5:
+			delay := untilReset / time.Duration(rl.Remaining-rateLimitReserve+1)
+			if delay > maxRateLimitSleep {
+				delay = maxRateLimitSleep
+			}
+			if err := sleepCtx(ctx, delay); err != nil {
+				return err
 			}
 		}
 	}
+	return w.limiter.Wait(ctx)
+}
+
+// quota returns the token's remaining quota. With a shared budget the request is reserved from it,
+// so workers on other processes see it spent; otherwise it is the last quota this process saw.
+func (w *Worker) quota(ctx context.Context, token string, resource string) (github.RateLimit, bool) {
+	if w.gh.Budget != nil {
+		rl, ok, err := w.gh.Budget.Reserve(ctx, token, resource)
+		if err == nil {
+			return rl, ok
+		}
+		slog.Warn("github rate budget reserve failed", "resource", resource, "error", err)
+	}
+	return w.gh.RateLimit(token, resource)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
 func (w *Worker) processOne(ctx context.Context) error {
@@ -65,22 +273,72 @@ func (w *Worker) processOne(ctx context.Context) error {
 	var jobID uuid.UUID
 	var projectID uuid.UUID
 	var jobType string
+	var attempts int
+	var waited float64
+	var idempotencyKey uuid.UUID
+	limitTypes, limitCounts := w.typeLimits()
 	err = tx.QueryRow(ctx, `
-SELECT id, project_id, job_type
-FROM sync_jobs
-WHERE status = 'pending'
-  AND run_at <= now()
-ORDER BY run_at ASC
-FOR UPDATE SKIP LOCKED
+SELECT j.id, j.project_id, j.job_type, j.attempts, EXTRACT(EPOCH FROM now() - j.run_at)::float8, j.idempotency_key
+FROM sync_jobs j
+JOIN projects p ON p.id = j.project_id
+WHERE j.status IN ('pending', 'retrying')
+  AND j.run_at <= now()
+  AND NOT EXISTS (
+    SELECT 1 FROM sync_jobs r
+    WHERE r.project_id = j.project_id AND r.status = 'running' AND r.locked_at > now() - $1 * interval '1 second'
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest($2::text[], $3::int[]) AS l(job_type, max_running)
+    WHERE l.job_type = j.job_type
+      AND (
+        SELECT count(*) FROM sync_jobs r
+        WHERE r.job_type = j.job_type AND r.status = 'running' AND r.locked_at > now() - $1 * interval '1 second'
+      ) >= l.max_running
+  )
+ORDER BY j.priority DESC, p.sync_claimed_at ASC NULLS FIRST, j.run_at ASC
+FOR UPDATE OF j SKIP LOCKED
 LIMIT 1
-`).Scan(&jobID, &projectID, &jobType)
+`, int64(w.cfg.SyncJobVisibilityTimeout.Seconds()), limitTypes, limitCounts).Scan(&jobID, &projectID, &jobType, &attempts, &waited, &idempotencyKey)
+	if err != nil {
+		return err
+	}
+
+	// One job per project at a time. Two workers can pick jobs of the same project before either
+	// is marked running, so claims are serialized per project and the check repeated under the lock.
+	var locked, busy bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended($1::text, 0))`, projectID).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return pgx.ErrNoRows
+	}
+	err = tx.QueryRow(ctx, `
+SELECT EXISTS(
+  SELECT 1 FROM sync_jobs
+  WHERE project_id = $1 AND status = 'running' AND locked_at > now() - $2 * interval '1 second'
+)
+`, projectID, int64(w.cfg.SyncJobVisibilityTimeout.Seconds())).Scan(&busy)
 	if err != nil {
 		return err
 	}
+	if busy {
+		return pgx.ErrNoRows
+	}
+	if full, err := w.typeAtLimit(ctx, tx, jobType); err != nil || full {
+		if err == nil {
+			err = pgx.ErrNoRows
+		}
+		return err
+	}
+	// Round-robin: this project now queues behind the others at the same priority.
+	if _, err := tx.Exec(ctx, `UPDATE projects SET sync_claimed_at = now() WHERE id = $1`, projectID); err != nil {
+		return err
+	}
 
 	_, err = tx.Exec(ctx, `
 UPDATE sync_jobs
-SET status = 'running', locked_at = now(), locked_by = $2, updated_at = now()
+SET status = 'running', locked_at = now(), locked_by = $2, started_at = now(), finished_at = NULL, updated_at = now()
 WHERE id = $1
 `, jobID, w.workerID)
 	if err != nil {
@@ -90,34 +348,92 @@ WHERE id = $1
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
+	jobQueueLatency.Observe(jobType, waited)
 
-	runErr := w.runJob(ctx, jobID, projectID, jobType)
+	started := time.Now()
+	w.activity.start(activeJob{JobID: jobID, JobType: jobType, ProjectID: projectID, StartedAt: started.UTC()})
+	stopHeartbeat := w.heartbeat(ctx, jobID)
+	runErr := w.runJob(withJob(ctx, jobID, idempotencyKey), jobID, projectID, jobType)
+	stopHeartbeat()
+	jobDuration.Observe(jobType, time.Since(started).Seconds())
 
-	status := "completed"
-	lastErr := ""
-	if runErr != nil {
-		status = "failed"
-		lastErr = runErr.Error()
+	var rle *github.RateLimitError
+	rateLimited := errors.As(runErr, &rle)
+	w.activity.finish(jobID, runErr != nil && !rateLimited)
+	if rateLimited {
+		// Not the job's fault: put it back for when the quota resets, without counting an attempt.
+		slog.Warn("sync job rescheduled: github rate limit",
+			"job_id", jobID,
+			"project_id", projectID,
+			"run_at", rle.Reset,
+		)
+		_, err := w.pool.Exec(ctx, `
+UPDATE sync_jobs
+SET status = 'pending', run_at = $2, locked_at = NULL, locked_by = NULL, last_error = 'rate_limited', updated_at = now()
+WHERE id = $1
+`, jobID, rle.Reset.Add(30*time.Second))
+		if db.IsUniqueViolation(err) {
+			// The same job was enqueued again while this one ran; that pending job will do the work.
+			_, _ = w.pool.Exec(ctx, `DELETE FROM sync_jobs WHERE id = $1`, jobID)
+		}
+		return nil
 	}
 
+	if runErr == nil {
+		_, _ = w.pool.Exec(ctx, `
+UPDATE sync_jobs
+SET status = 'completed', attempts = attempts + 1, last_error = NULL, locked_at = NULL, locked_by = NULL, finished_at = now(), updated_at = now()
+WHERE id = $1
+`, jobID)
+		return nil
+	}
+
+	// Failed: retry with backoff until the job has used up its attempts, then it's dead.
+	attempts++
+	if attempts >= w.cfg.SyncJobMaxAttempts {
+		slog.Error("sync job dead after max attempts",
+			"job_id", jobID,
+			"job_type", jobType,
+			"project_id", projectID,
+			"attempts", attempts,
+			"error", runErr,
+		)
+		_, _ = w.pool.Exec(ctx, `
+UPDATE sync_jobs
+SET status = 'dead', attempts = $2, last_error = $3, locked_at = NULL, locked_by = NULL, finished_at = now(), updated_at = now()
+WHERE id = $1
+`, jobID, attempts, runErr.Error())
+		return nil
+	}
+	delay := retryDelay(attempts, w.cfg.SyncJobRetryBaseDelay, w.cfg.SyncJobRetryMaxDelay)
+	slog.Warn("sync job failed, retrying",
+		"job_id", jobID,
+		"job_type", jobType,
+		"project_id", projectID,
+		"attempts", attempts,
+		"retry_in", delay,
+	)
 	_, _ = w.pool.Exec(ctx, `
 UPDATE sync_jobs
-SET status = $2, attempts = attempts + 1, last_error = NULLIF($3, ''), updated_at = now()
+SET status = 'retrying', attempts = $2, last_error = $3, run_at = now() + $4 * interval '1 millisecond',
+    locked_at = NULL, locked_by = NULL, finished_at = now(), updated_at = now()
 WHERE id = $1
-`, jobID, status, lastErr)
+`, jobID, attempts, runErr.Error(), delay.Milliseconds())
 
 	return nil
 }
 
 func (w *Worker) runJob(ctx context.Context, jobID uuid.UUID, projectID uuid.UUID, jobType string) error {
-	// Load project + owner to get GitHub token.
+	// Load project + owner; the token comes from the project's GitHub App installation when linked,
+	// otherwise from the owner's OAuth account.
 	var fullName string
 	var ownerUserID uuid.UUID
+	var provider, status string
 	err := w.pool.QueryRow(ctx, `
-SELECT github_full_name, owner_user_id
+SELECT github_full_name, owner_user_id, provider, status
 FROM projects
 WHERE id = $1
-`, projectID).Scan(&fullName, &ownerUserID)
+`, projectID).Scan(&fullName, &ownerUserID, &provider, &status)
 	if err != nil {
 		slog.Error("sync job failed: project not found",
 			"job_id", jobID,
@@ -126,8 +442,17 @@ WHERE id = $1
 		)
 		return err
 	}
+	// Archived projects keep their data but are not synced; a job claimed before the archive, or
+	// queued by a path that doesn't check the status, ends here.
+	if status == "archived" {
+		slog.Info("sync job skipped: project archived", "job_id", jobID, "project_id", projectID, "job_type", jobType)
+		return nil
+	}
+	if provider != repohost.ProviderGitHub {
+		return w.runHostJob(ctx, jobID, projectID, ownerUserID, provider, fullName, jobType)
+	}
 
-	linked, err := github.GetLinkedAccount(ctx, w.pool, ownerUserID, w.cfg.TokenEncKeyB64)
+	ghToken, err := github.GetProjectToken(ctx, w.pool, w.apps, projectID, w.cfg.TokenEncKeyB64)
 	if err != nil {
 		slog.Error("sync job failed: GitHub account not linked",
 			"job_id", jobID,
@@ -135,7 +460,7 @@ WHERE id = $1
 			"user_id", ownerUserID,
 			"repo", fullName,
 			"error", err,
-			"hint", "User needs to link their GitHub account via OAuth",
+			"hint", "Link the project to a GitHub App installation or have the owner link GitHub via OAuth",
 		)
 		return fmt.Errorf("github_not_linked: %w", err)
 	}
@@ -146,18 +471,46 @@ WHERE id = $1
 		"project_id", projectID,
 		"repo", fullName,
 		"user_id", ownerUserID,
+		"auth_mode", ghToken.Mode,
 	)
 
+	// Rows upserted by this job have last_seen_at past started; the minute of slack covers clock skew
+	// with the database.
+	started := time.Now().Add(-time.Minute)
 	var syncErr error
 	switch jobType {
 	case "sync_issues":
-		syncErr = w.syncIssues(ctx, projectID, fullName, linked.AccessToken)
+		syncErr = w.withCursor(ctx, projectID, "issues", func(since time.Time) error {
+			return w.syncIssues(ctx, projectID, fullName, ghToken.Token, since)
+		})
 	case "sync_prs":
-		syncErr = w.syncPRs(ctx, projectID, fullName, linked.AccessToken)
+		syncErr = w.withCursor(ctx, projectID, "prs", func(since time.Time) error {
+			return w.syncPRs(ctx, projectID, fullName, ghToken.Token, since)
+		})
+	case "sync_comments":
+		syncErr = w.withCursor(ctx, projectID, "comments", func(since time.Time) error {
+			return w.syncIssueComments(ctx, projectID, fullName, ghToken.Token, since)
+		})
+	case "sync_commits":
+		syncErr = w.syncCommits(ctx, projectID, fullName, ghToken.Token)
+	case "refresh_repo_metadata":
+		syncErr = w.refreshRepoMetadata(ctx, projectID, fullName, ghToken.Token)
+	case "rotate_webhook_secret":
+		syncErr = w.rotateWebhookSecret(ctx, projectID, fullName, ghToken)
+	case "reverify_ownership":
+		syncErr = w.reverifyOwnership(ctx, projectID, fullName, ghToken)
+	case "post_bot_comments":
+		syncErr = w.postBotComments(ctx, projectID, fullName)
 	default:
 		syncErr = fmt.Errorf("unknown job_type: %s", jobType)
 	}
 
+	if syncErr != nil && ghToken.Mode == github.AuthModeOAuth && github.IsUnauthorized(syncErr) {
+		// Stop retrying with a dead token; the owner sees needs_relink in /auth/github/status.
+		if err := github.MarkTokenRevoked(ctx, w.pool, ownerUserID); err != nil {
+			slog.Warn("failed to mark github token revoked", "user_id", ownerUserID, "error", err)
+		}
+	}
 	if syncErr != nil {
 		slog.Error("sync job failed",
 			"job_id", jobID,
@@ -175,34 +528,61 @@ WHERE id = $1
 		"project_id", projectID,
 		"repo", fullName,
 	)
+
+	switch jobType {
+	case "sync_issues", "sync_prs", "sync_commits":
+		w.afterContributionSync(ctx, jobID, projectID, started)
+	}
 	return nil
 }
 
-func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName string, token string) error {
-	totalIssues := 0
-	for page := 1; page <= 50; page++ { // safety cap
-		if err := w.limiter.Wait(ctx); err != nil {
+// afterContributionSync refreshes the contributions table and badges from the rows a sync job
+// upserted since started.
+func (w *Worker) afterContributionSync(ctx context.Context, jobID, projectID uuid.UUID, started time.Time) {
+	if err := contributions.SyncProject(ctx, w.pool, projectID, started); err != nil {
+		slog.Warn("contributions refresh failed", "job_id", jobID, "project_id", projectID, "error", err)
+	}
+	if err := achievements.EvaluateProject(ctx, w.pool, projectID, started); err != nil {
+		slog.Warn("badge evaluation failed", "job_id", jobID, "project_id", projectID, "error", err)
+	}
+}
+
+func (w *Worker) syncIssuesREST(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	start := time.Now()
+	cp := w.loadCheckpoint(ctx, "issues", since)
+	if cp.finished() {
+		return nil
+	}
+	totalIssues := cp.ItemsUpserted
+	commentsStale := false
+	cursor, more := cp.Cursor, true
+	for page := cp.PagesDone + 1; more; page++ {
+		if page > maxListPages {
+			slog.Warn("page limit reached, stopping", "project_id", projectID, "repo", fullName, "pages", maxListPages)
+			break
+		}
+		if err := w.wait(ctx, token, "core"); err != nil {
 			return err
 		}
-		items, err := w.gh.ListIssuesPage(ctx, token, fullName, page)
+		items, next, notModified, err := w.gh.ListIssuesPage(ctx, token, fullName, since, cursor)
 		if err != nil {
 			return err
 		}
-		if len(items) == 0 {
-			return nil
+		cursor, more = next, next != ""
+		if notModified {
+			// Page unchanged since the last sync (304); its issues and comments are already stored.
+			cp.save(ctx, w, cursor, totalIssues)
+			continue
 		}
 
+		var pw pageWriter
+		stored := w.storedCommentCounts(ctx, projectID, items)
 		for _, it := range items {
 			// Skip PRs from the issues endpoint.
 			if it.PullRequest != nil {
 				continue
 			}
 			totalIssues++
-			// Convert assignees to JSONB (array of login strings)
-			assigneesJSON, _ := json.Marshal(it.Assignees)
-			// Convert labels to JSONB (array of {name, color} objects)
-			labelsJSON, _ := json.Marshal(it.Labels)
-			
 			// Parse date strings from GitHub API
 			var createdAt, updatedAt, closedAt *time.Time
 			if it.CreatedAt != nil && *it.CreatedAt != "" {
@@ -245,54 +625,52 @@ func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName s
 				}
 			}
 			
-			// Fetch comments for this issue (if comments_count > 0)
+			// Comments are not fetched per issue: issue_comment webhooks keep the stored comments
+			// current, and when a count has drifted the sync_comments job queued below picks the
+			// changes up through the repository-wide listing. A nil commentsJSON leaves them untouched.
 			var commentsJSON []byte = []byte("[]")
 			if it.Comments > 0 {
-				if err := w.limiter.Wait(ctx); err == nil {
-					comments, err := w.gh.ListIssueComments(ctx, token, fullName, it.Number)
-					if err == nil {
-						commentsJSON, _ = json.Marshal(comments)
-					}
+				commentsJSON = nil
+				if n, ok := stored[it.ID]; !ok || n != it.Comments {
+					commentsStale = true
 				}
 			}
 			
-			_, _ = w.pool.Exec(ctx, `
-INSERT INTO github_issues (project_id, github_issue_id, number, state, title, body, author_login, url, assignees, labels, comments_count, comments, created_at_github, updated_at_github, closed_at_github, last_seen_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now())
-ON CONFLICT (project_id, github_issue_id) DO UPDATE SET
-  number = EXCLUDED.number,
-  state = EXCLUDED.state,
-  title = EXCLUDED.title,
-  body = EXCLUDED.body,
-  author_login = EXCLUDED.author_login,
-  url = EXCLUDED.url,
-  assignees = EXCLUDED.assignees,
-  labels = EXCLUDED.labels,
-  comments_count = EXCLUDED.comments_count,
-  comments = EXCLUDED.comments,
-  created_at_github = COALESCE(EXCLUDED.created_at_github, github_issues.created_at_github),
-  updated_at_github = COALESCE(EXCLUDED.updated_at_github, github_issues.updated_at_github),
-  closed_at_github = COALESCE(EXCLUDED.closed_at_github, github_issues.closed_at_github),
-  last_seen_at = now()
-`, projectID, it.ID, it.Number, it.State, it.Title, it.Body, it.User.Login, it.HTMLURL, assigneesJSON, labelsJSON, it.Comments, commentsJSON, createdAt, updatedAt, closedAt)
+			queueIssueUpsert(&pw, projectID, it, commentsJSON, createdAt, updatedAt, closedAt)
 		}
+		pw.flush(ctx, w, projectID)
+		cp.save(ctx, w, cursor, totalIssues)
+	}
+	if commentsStale {
+		w.enqueueCommentSync(ctx, projectID)
 	}
 	
 	slog.Info("sync issues completed",
 		"project_id", projectID,
 		"repo", fullName,
 		"total_issues", totalIssues,
+		"duration_ms", time.Since(start).Milliseconds(),
 	)
 	return nil
 }
 
-func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName string, token string) error {
-	totalPRs := 0
-	for page := 1; page <= 50; page++ { // safety cap
-		if err := w.limiter.Wait(ctx); err != nil {
+func (w *Worker) syncPRsREST(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	start := time.Now()
+	cp := w.loadCheckpoint(ctx, "prs", since)
+	if cp.finished() {
+		return nil
+	}
+	totalPRs := cp.ItemsUpserted
+	cursor, more := cp.Cursor, true
+	for page := cp.PagesDone + 1; more; page++ {
+		if page > maxListPages {
+			slog.Warn("page limit reached, stopping", "project_id", projectID, "repo", fullName, "pages", maxListPages)
+			break
+		}
+		if err := w.wait(ctx, token, "core"); err != nil {
 			return err
 		}
-		items, err := w.gh.ListPRsPage(ctx, token, fullName, page)
+		items, next, notModified, err := w.gh.ListPRsPage(ctx, token, fullName, cursor)
 		if err != nil {
 			slog.Error("failed to fetch PRs page",
 				"project_id", projectID,
@@ -302,15 +680,16 @@ func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName stri
 			)
 			return err
 		}
-		if len(items) == 0 {
-			slog.Info("sync PRs completed",
-				"project_id", projectID,
-				"repo", fullName,
-				"total_prs", totalPRs,
-			)
-			return nil
+		cursor, more = next, next != ""
+		if len(items) > 0 && reachedCursor(items[len(items)-1].UpdatedAt, since) {
+			cursor, more = "", false
+		}
+		if notModified {
+			cp.save(ctx, w, cursor, totalPRs)
+			continue
 		}
 
+		var pw pageWriter
 		for _, it := range items {
 			totalPRs++
 			
@@ -337,7 +716,210 @@ func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName stri
 				}
 			}
 			
-			_, _ = w.pool.Exec(ctx, `
+			queuePRUpsert(&pw, projectID, it, createdAt, updatedAt, closedAt, mergedAt)
+		}
+		pw.flush(ctx, w, projectID)
+		cp.save(ctx, w, cursor, totalPRs)
+	}
+
+	slog.Info("sync PRs completed",
+		"project_id", projectID,
+		"repo", fullName,
+		"total_prs", totalPRs,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return nil
+}
+
+func (w *Worker) syncIssues(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	if w.cfg.GitHubSyncGraphQL {
+		return w.syncIssuesGraphQL(ctx, projectID, fullName, token, since)
+	}
+	return w.syncIssuesREST(ctx, projectID, fullName, token, since)
+}
+
+func (w *Worker) syncPRs(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	if w.cfg.GitHubSyncGraphQL {
+		return w.syncPRsGraphQL(ctx, projectID, fullName, token, since)
+	}
+	return w.syncPRsREST(ctx, projectID, fullName, token, since)
+}
+
+// syncIssuesGraphQL fetches issues with their comments in one request per 50 issues, instead of
+// a REST page per 100 issues plus a comments request per issue.
+func (w *Worker) syncIssuesGraphQL(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	start := time.Now()
+	cp := w.loadCheckpoint(ctx, "issues_graphql", since)
+	if cp.finished() {
+		return nil
+	}
+	totalIssues, requests := cp.ItemsUpserted, 0
+	cursor := cp.Cursor
+	for page := cp.PagesDone + 1; page <= 100; page++ { // safety cap (5000 issues, same as REST)
+		if err := w.wait(ctx, token, "graphql"); err != nil {
+			return err
+		}
+		items, next, err := w.gh.ListIssuesGraphQL(ctx, token, fullName, cursor)
+		if err != nil {
+			return err
+		}
+		requests++
+
+		var pw pageWriter
+		for _, it := range items {
+			totalIssues++
+			createdAt := parseGitHubTime(it.CreatedAt)
+			updatedAt := parseGitHubTime(it.UpdatedAt)
+			closedAt := parseGitHubTime(it.ClosedAt)
+
+			comments := it.CommentList
+			// Issues with more comments than the nested page fall back to the REST comments list.
+			if it.Comments > len(comments) {
+				if err := w.wait(ctx, token, "core"); err == nil {
+					if all, err := w.gh.ListIssueComments(ctx, token, fullName, it.Number); err == nil {
+						comments = all
+					}
+					requests++
+				}
+			}
+			commentsJSON, _ := json.Marshal(comments)
+
+			queueIssueUpsert(&pw, projectID, it.IssueListItem, commentsJSON, createdAt, updatedAt, closedAt)
+		}
+		pw.flush(ctx, w, projectID)
+		if next == "" || (len(items) > 0 && reachedCursor(items[len(items)-1].UpdatedAt, since)) {
+			cp.save(ctx, w, "", totalIssues)
+			break
+		}
+		cursor = next
+		cp.save(ctx, w, cursor, totalIssues)
+	}
+
+	slog.Info("sync issues completed",
+		"project_id", projectID,
+		"repo", fullName,
+		"total_issues", totalIssues,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"api", "graphql",
+		"requests", requests,
+	)
+	return nil
+}
+
+// syncPRsGraphQL fetches pull requests with their reviews in one request per 50 PRs.
+func (w *Worker) syncPRsGraphQL(ctx context.Context, projectID uuid.UUID, fullName string, token string, since time.Time) error {
+	start := time.Now()
+	cp := w.loadCheckpoint(ctx, "prs_graphql", since)
+	if cp.finished() {
+		return nil
+	}
+	totalPRs, requests := cp.ItemsUpserted, 0
+	cursor := cp.Cursor
+	for page := cp.PagesDone + 1; page <= 100; page++ { // safety cap
+		if err := w.wait(ctx, token, "graphql"); err != nil {
+			return err
+		}
+		items, next, err := w.gh.ListPRsGraphQL(ctx, token, fullName, cursor)
+		if err != nil {
+			slog.Error("failed to fetch PRs page",
+				"project_id", projectID,
+				"repo", fullName,
+				"page", page,
+				"error", err,
+			)
+			return err
+		}
+		requests++
+
+		var pw pageWriter
+		for _, it := range items {
+			totalPRs++
+			queuePRUpsert(&pw, projectID, it.PRListItem,
+				parseGitHubTime(it.CreatedAt), parseGitHubTime(it.UpdatedAt), parseGitHubTime(it.ClosedAt), parseGitHubTime(it.MergedAt))
+
+			reviewsJSON, _ := json.Marshal(it.Reviews)
+			pw.queue(fmt.Sprintf("pr #%d reviews", it.Number), `
+UPDATE github_pull_requests SET reviews = $3, reviews_count = $4
+WHERE project_id = $1 AND github_pr_id = $2
+`, projectID, it.ID, reviewsJSON, it.ReviewsTotal)
+		}
+		pw.flush(ctx, w, projectID)
+		if next == "" || (len(items) > 0 && reachedCursor(items[len(items)-1].UpdatedAt, since)) {
+			cp.save(ctx, w, "", totalPRs)
+			break
+		}
+		cursor = next
+		cp.save(ctx, w, cursor, totalPRs)
+	}
+
+	slog.Info("sync PRs completed",
+		"project_id", projectID,
+		"repo", fullName,
+		"total_prs", totalPRs,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"api", "graphql",
+		"requests", requests,
+	)
+	return nil
+}
+
+// queueIssueUpsert adds an issue upsert to the page's batch.
+func queueIssueUpsert(pw *pageWriter, projectID uuid.UUID, it github.IssueListItem, commentsJSON []byte, createdAt, updatedAt, closedAt *time.Time) {
+	// Convert assignees to JSONB (array of login strings)
+	assigneesJSON, _ := json.Marshal(it.Assignees)
+	// Convert labels to JSONB (array of {name, color} objects)
+	labelsJSON, _ := json.Marshal(it.Labels)
+
+	pw.queue(fmt.Sprintf("issue #%d", it.Number), `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, title, body, author_login, url, assignees, labels, comments_count, comments, created_at_github, updated_at_github, closed_at_github, last_seen_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now())
+ON CONFLICT (project_id, github_issue_id) DO UPDATE SET
+  number = EXCLUDED.number,
+  state = EXCLUDED.state,
+  title = EXCLUDED.title,
+  body = EXCLUDED.body,
+  author_login = EXCLUDED.author_login,
+  url = EXCLUDED.url,
+  assignees = EXCLUDED.assignees,
+  labels = EXCLUDED.labels,
+  comments_count = EXCLUDED.comments_count,
+  comments = COALESCE(EXCLUDED.comments, github_issues.comments),
+  created_at_github = COALESCE(EXCLUDED.created_at_github, github_issues.created_at_github),
+  updated_at_github = COALESCE(EXCLUDED.updated_at_github, github_issues.updated_at_github),
+  closed_at_github = COALESCE(EXCLUDED.closed_at_github, github_issues.closed_at_github),
+  last_seen_at = now()
+`, projectID, it.ID, it.Number, it.State, it.Title, it.Body, it.User.Login, it.HTMLURL, assigneesJSON, labelsJSON, it.Comments, commentsJSON, createdAt, updatedAt, closedAt)
+}
+
+// storedCommentCounts returns the stored comments_count of a page's issues, keyed by GitHub issue
+// ID, for issues whose comments are already stored. One query per page rather than per issue.
+func (w *Worker) storedCommentCounts(ctx context.Context, projectID uuid.UUID, items []github.IssueListItem) map[int64]int {
+	ids := make([]int64, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ID)
+	}
+	out := make(map[int64]int, len(ids))
+	rows, err := w.pool.Query(ctx, `
+SELECT github_issue_id, comments_count FROM github_issues
+WHERE project_id = $1 AND github_issue_id = ANY($2) AND comments IS NOT NULL AND comments_count IS NOT NULL
+`, projectID, ids)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err == nil {
+			out[id] = n
+		}
+	}
+	return out
+}
+
+// queuePRUpsert adds a pull request upsert to the page's batch.
+func queuePRUpsert(pw *pageWriter, projectID uuid.UUID, it github.PRListItem, createdAt, updatedAt, closedAt, mergedAt *time.Time) {
+	pw.queue(fmt.Sprintf("pr #%d", it.Number), `
 INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, title, body, author_login, url, merged, created_at_github, updated_at_github, closed_at_github, merged_at_github, last_seen_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, now())
 ON CONFLICT (project_id, github_pr_id) DO UPDATE SET
@@ -354,9 +936,17 @@ ON CONFLICT (project_id, github_pr_id) DO UPDATE SET
   merged_at_github = EXCLUDED.merged_at_github,
   last_seen_at = now()
 `, projectID, it.ID, it.Number, it.State, it.Title, it.Body, it.User.Login, it.HTMLURL, it.Merged, createdAt, updatedAt, closedAt, mergedAt)
-		}
+}
+
+func parseGitHubTime(s *string) *time.Time {
+	if s == nil || *s == "" {
+		return nil
 	}
-	return nil
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil
+	}
+	return &t
 }
 
 func hostname() string {
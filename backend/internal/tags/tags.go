@@ -0,0 +1,254 @@
+// Package tags resolves free-form project tags to canonical tag slugs. projects.tags (JSONB) holds
+// the canonical slugs and remains what the API reads; the tags, tag_aliases and project_tags tables
+// hold the catalog, the accepted spellings and the per-project index.
+package tags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxPerProject caps how many tags a project keeps; extra ones are dropped.
+const MaxPerProject = 20
+
+var ErrTagNotFound = errors.New("tag_not_found")
+
+// Normalize turns a tag as typed into its slug form: lowercased, with runs of spaces, underscores
+// and dashes collapsed to one dash, and anything other than a-z, 0-9, '+', '#' and '.' dropped
+// ("Smart Contracts" -> "smart-contracts", "C++" -> "c++"). Migration 000077 applies the same rule.
+func Normalize(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '+', r == '#', r == '.':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case r == ' ', r == '\t', r == '\n', r == '\r', r == '\f', r == '\v', r == '_', r == '-':
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// normalizeAll normalizes raw, dropping empty and repeated slugs and keeping first-seen order. The
+// display spelling of each slug (first one seen) is returned alongside for new tags.
+func normalizeAll(raw []string) (slugs []string, names map[string]string) {
+	names = map[string]string{}
+	for _, r := range raw {
+		s := Normalize(r)
+		if s == "" {
+			continue
+		}
+		if _, ok := names[s]; ok {
+			continue
+		}
+		names[s] = strings.TrimSpace(r)
+		slugs = append(slugs, s)
+	}
+	return slugs, names
+}
+
+// Resolve maps raw tags to canonical slugs through the alias table, without creating anything.
+// Unknown tags come back normalized. Use it for filters.
+func Resolve(ctx context.Context, pool *pgxpool.Pool, raw []string) ([]string, error) {
+	keys, _ := normalizeAll(raw)
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+	known, err := lookup(ctx, pool, keys)
+	if err != nil {
+		return nil, err
+	}
+	return dedupe(keys, known), nil
+}
+
+// Canonicalize maps raw tags to canonical slugs, creating a tag for each one no alias resolves.
+// The result is deduplicated and capped at MaxPerProject. Use it when saving a project's tags.
+func Canonicalize(ctx context.Context, pool *pgxpool.Pool, raw []string) ([]string, error) {
+	keys, names := normalizeAll(raw)
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+	known, err := lookup(ctx, pool, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if _, ok := known[k]; ok {
+			continue
+		}
+		if _, err := pool.Exec(ctx, `
+WITH t AS (
+  INSERT INTO tags (slug, name) VALUES ($1, $2)
+  ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug
+  RETURNING id
+)
+INSERT INTO tag_aliases (alias, tag_id)
+SELECT $1, id FROM t
+ON CONFLICT (alias) DO NOTHING
+`, k, names[k]); err != nil {
+			return nil, err
+		}
+	}
+	// Re-read: a concurrent writer may have claimed one of the new aliases for another tag.
+	known, err = lookup(ctx, pool, keys)
+	if err != nil {
+		return nil, err
+	}
+	out := dedupe(keys, known)
+	if len(out) > MaxPerProject {
+		out = out[:MaxPerProject]
+	}
+	return out, nil
+}
+
+// CanonicalizeJSON is Canonicalize returning the JSONB value for projects.tags.
+func CanonicalizeJSON(ctx context.Context, pool *pgxpool.Pool, raw []string) ([]byte, error) {
+	slugs, err := Canonicalize(ctx, pool, raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(slugs)
+}
+
+func lookup(ctx context.Context, pool *pgxpool.Pool, keys []string) (map[string]string, error) {
+	rows, err := pool.Query(ctx, `
+SELECT a.alias, t.slug
+FROM tag_aliases a
+JOIN tags t ON t.id = a.tag_id
+WHERE a.alias = ANY($1)
+`, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	known := map[string]string{}
+	for rows.Next() {
+		var alias, slug string
+		if err := rows.Scan(&alias, &slug); err != nil {
+			return nil, err
+		}
+		known[alias] = slug
+	}
+	return known, rows.Err()
+}
+
+func dedupe(keys []string, known map[string]string) []string {
+	out := make([]string, 0, len(keys))
+	seen := map[string]bool{}
+	for _, k := range keys {
+		s := k
+		if v, ok := known[k]; ok {
+			s = v
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// SyncProject rebuilds the project's project_tags rows from its tags JSONB. Call it after writing
+// canonical slugs to projects.tags.
+func SyncProject(ctx context.Context, pool *pgxpool.Pool, projectID uuid.UUID) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM project_tags WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO project_tags (project_id, tag_id)
+SELECT p.id, t.id
+FROM projects p
+CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(p.tags) = 'array' THEN p.tags ELSE '[]'::jsonb END) AS e(slug)
+JOIN tags t ON t.slug = e.slug
+WHERE p.id = $1
+ON CONFLICT DO NOTHING
+`, projectID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Merge folds source into target: source's aliases (its own slug included) now resolve to target,
+// its projects are retagged, and the source tag is deleted. Returns the number of projects retagged.
+func Merge(ctx context.Context, pool *pgxpool.Pool, sourceID, targetID uuid.UUID) (int64, error) {
+	if sourceID == targetID {
+		return 0, fmt.Errorf("cannot merge a tag into itself")
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var sourceSlug, targetSlug string
+	if err := tx.QueryRow(ctx, `SELECT slug FROM tags WHERE id = $1 FOR UPDATE`, sourceID).Scan(&sourceSlug); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTagNotFound
+		}
+		return 0, err
+	}
+	if err := tx.QueryRow(ctx, `SELECT slug FROM tags WHERE id = $1 FOR UPDATE`, targetID).Scan(&targetSlug); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTagNotFound
+		}
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1`, sourceID, targetID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO project_tags (project_id, tag_id)
+SELECT project_id, $2 FROM project_tags WHERE tag_id = $1
+ON CONFLICT DO NOTHING
+`, sourceID, targetID); err != nil {
+		return 0, err
+	}
+	// Swap the slug in the JSONB read path, dropping the duplicate when a project had both tags.
+	ct, err := tx.Exec(ctx, `
+UPDATE projects p
+SET tags = (
+  SELECT COALESCE(jsonb_agg(d.slug ORDER BY d.pos), '[]'::jsonb)
+  FROM (
+    SELECT DISTINCT ON (slug) slug, pos
+    FROM (
+      SELECT CASE WHEN e.value = $1 THEN $2 ELSE e.value END AS slug, e.pos
+      FROM jsonb_array_elements_text(p.tags) WITH ORDINALITY AS e(value, pos)
+    ) s
+    ORDER BY slug, pos
+  ) d
+),
+    updated_at = now()
+WHERE jsonb_typeof(p.tags) = 'array' AND p.tags ? $1
+`, sourceSlug, targetSlug)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE tags SET updated_at = now() WHERE id = $1`, targetID); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
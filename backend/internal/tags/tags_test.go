@@ -0,0 +1,43 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"Rust":               "rust",
+		"  Smart Contracts ": "smart-contracts",
+		"smart_contracts":    "smart-contracts",
+		"web3 -- dapps":      "web3-dapps",
+		"C++":                "c++",
+		"C#":                 "c#",
+		"node.js":            "node.js",
+		"-zk-":               "zk",
+		"DeFi 🚀":             "defi",
+		"!!!":                "",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeAllKeepsFirstSpelling(t *testing.T) {
+	slugs, names := normalizeAll([]string{"Rust", "rust", " ", "Smart Contracts", "smart-contracts"})
+	if want := []string{"rust", "smart-contracts"}; !reflect.DeepEqual(slugs, want) {
+		t.Fatalf("slugs = %v, want %v", slugs, want)
+	}
+	if names["rust"] != "Rust" || names["smart-contracts"] != "Smart Contracts" {
+		t.Fatalf("names = %v", names)
+	}
+}
+
+func TestDedupeResolvesAliases(t *testing.T) {
+	got := dedupe([]string{"rustlang", "rust", "wasm"}, map[string]string{"rustlang": "rust", "rust": "rust"})
+	if want := []string{"rust", "wasm"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("dedupe = %v, want %v", got, want)
+	}
+}
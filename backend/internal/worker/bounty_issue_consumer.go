@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+)
+
+// BountyIssueConsumer updates a bounty's GitHub issue (comment and labels) as soon as the bounty is
+// created or paid. A queue group, so one worker handles each event; failures are left to the sync
+// worker's bounty issue sweep.
+type BountyIssueConsumer struct {
+	Sub    *nats.Subscription
+	Worker *syncjobs.Worker
+}
+
+func (c *BountyIssueConsumer) Subscribe(ctx context.Context, nc *nats.Conn, queue string) error {
+	if nc == nil || c.Worker == nil {
+		return nil
+	}
+	if queue == "" {
+		queue = "patchwork-workers"
+	}
+
+	sub, err := nc.QueueSubscribe(events.SubjectBountyAll, queue, func(msg *nats.Msg) {
+		var e events.BountyChanged
+		if err := json.Unmarshal(msg.Data, &e); err != nil {
+			slog.Error("bad bounty event", "subject", msg.Subject, "error", err)
+			return
+		}
+		id, err := uuid.Parse(e.BountyID)
+		if err != nil {
+			slog.Error("bad bounty event", "subject", msg.Subject, "bounty_id", e.BountyID, "error", err)
+			return
+		}
+		if err := c.Worker.SyncBountyIssue(ctx, id); err != nil {
+			slog.Warn("bounty issue sync failed", "bounty_id", id, "status", e.Status, "error", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.Sub = sub
+
+	go func() {
+		<-ctx.Done()
+		_ = sub.Unsubscribe()
+	}()
+
+	return nil
+}
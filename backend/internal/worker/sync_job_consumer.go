@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/jagadeesh/grainlify/backend/internal/events"
+	"github.com/jagadeesh/grainlify/backend/internal/syncjobs"
+)
+
+// SyncJobConsumer wakes the sync worker when jobs are enqueued. It is a plain subscription, not a
+// queue group: every worker process wakes and they compete for the jobs through SKIP LOCKED claims.
+type SyncJobConsumer struct {
+	Sub    *nats.Subscription
+	Worker *syncjobs.Worker
+}
+
+func (c *SyncJobConsumer) Subscribe(ctx context.Context, nc *nats.Conn) error {
+	if nc == nil || c.Worker == nil {
+		return nil
+	}
+
+	sub, err := nc.Subscribe(events.SubjectSyncJobEnqueued, func(msg *nats.Msg) {
+		c.Worker.Wake()
+	})
+	if err != nil {
+		return err
+	}
+	c.Sub = sub
+
+	go func() {
+		<-ctx.Done()
+		_ = sub.Unsubscribe()
+	}()
+
+	return nil
+}
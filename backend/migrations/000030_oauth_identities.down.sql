@@ -0,0 +1,11 @@
+DELETE FROM oauth_states WHERE kind IN ('oauth_login', 'oauth_link');
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install'));
+
+ALTER TABLE oauth_states DROP COLUMN IF EXISTS provider;
+
+DROP TABLE IF EXISTS oauth_identities;
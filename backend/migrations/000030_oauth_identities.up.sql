@@ -0,0 +1,26 @@
+-- External OAuth identities (Google, Discord, ...) linked to users, so any linked identity can sign in.
+CREATE TABLE IF NOT EXISTS oauth_identities (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider TEXT NOT NULL CHECK (provider IN ('google', 'discord')),
+  subject TEXT NOT NULL,
+  email TEXT,
+  email_verified BOOLEAN NOT NULL DEFAULT false,
+  display_name TEXT,
+  avatar_url TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_identities_user_id ON oauth_identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_identities_verified_email ON oauth_identities(LOWER(email)) WHERE email_verified;
+
+-- OAuth states for non-GitHub providers.
+ALTER TABLE oauth_states ADD COLUMN IF NOT EXISTS provider TEXT;
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'oauth_login', 'oauth_link'));
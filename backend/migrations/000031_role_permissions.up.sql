@@ -0,0 +1,52 @@
+-- Role/permission matrix so capabilities can be granted to roles without code changes.
+CREATE TABLE IF NOT EXISTS roles (
+  name TEXT PRIMARY KEY,
+  description TEXT,
+  builtin BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO roles (name, description, builtin) VALUES
+  ('contributor', 'Default role for new users', true),
+  ('maintainer', 'Project maintainers', true),
+  ('admin', 'Platform administrators (implicitly granted every permission)', true)
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS permissions (
+  key TEXT PRIMARY KEY,
+  description TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO permissions (key, description) VALUES
+  ('projects:create', 'Register a repository as a project'),
+  ('projects:verify', 'Verify project ownership and install webhooks'),
+  ('projects:sync', 'Enqueue GitHub sync jobs for a project'),
+  ('users:read', 'List users'),
+  ('users:manage_roles', 'Change user roles'),
+  ('roles:manage', 'Create roles and edit the permission matrix'),
+  ('ecosystems:manage', 'Create, update and delete ecosystems'),
+  ('open_source_week:manage', 'Manage Open Source Week events')
+ON CONFLICT (key) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role TEXT NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+  permission TEXT NOT NULL REFERENCES permissions(key) ON UPDATE CASCADE ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (role, permission)
+);
+
+-- Preserve current behaviour: any signed-in user can create/verify/sync their own projects.
+INSERT INTO role_permissions (role, permission)
+SELECT r.name, p.key
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.key IN ('projects:create', 'projects:verify', 'projects:sync')
+   OR r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- users.role now references roles instead of a hardcoded CHECK.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users
+  ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
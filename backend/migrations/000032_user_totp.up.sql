@@ -0,0 +1,8 @@
+-- TOTP second factor (secret encrypted with TOKEN_ENC_KEY_B64). enabled_at is set after the first valid code.
+CREATE TABLE IF NOT EXISTS user_totp (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  secret BYTEA NOT NULL,
+  enabled_at TIMESTAMPTZ,
+  last_used_step BIGINT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
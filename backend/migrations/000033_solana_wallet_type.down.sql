@@ -0,0 +1,10 @@
+DELETE FROM wallets WHERE wallet_type = 'solana';
+DELETE FROM auth_nonces WHERE wallet_type = 'solana';
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_wallet_type_check;
+ALTER TABLE wallets
+  ADD CONSTRAINT wallets_wallet_type_check CHECK (wallet_type IN ('evm', 'stellar_ed25519', 'stellar_secp256k1'));
+
+ALTER TABLE auth_nonces DROP CONSTRAINT IF EXISTS auth_nonces_wallet_type_check;
+ALTER TABLE auth_nonces
+  ADD CONSTRAINT auth_nonces_wallet_type_check CHECK (wallet_type IN ('evm', 'stellar_ed25519', 'stellar_secp256k1'));
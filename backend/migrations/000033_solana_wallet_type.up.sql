@@ -0,0 +1,8 @@
+-- Allow Solana (ed25519, base58 address) wallets.
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_wallet_type_check;
+ALTER TABLE wallets
+  ADD CONSTRAINT wallets_wallet_type_check CHECK (wallet_type IN ('evm', 'stellar_ed25519', 'stellar_secp256k1', 'solana'));
+
+ALTER TABLE auth_nonces DROP CONSTRAINT IF EXISTS auth_nonces_wallet_type_check;
+ALTER TABLE auth_nonces
+  ADD CONSTRAINT auth_nonces_wallet_type_check CHECK (wallet_type IN ('evm', 'stellar_ed25519', 'stellar_secp256k1', 'solana'));
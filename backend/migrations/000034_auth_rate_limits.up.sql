@@ -0,0 +1,8 @@
+-- Fixed-window counters for auth rate limiting (used when AUTH_RATE_LIMIT_STORE=postgres).
+CREATE TABLE IF NOT EXISTS auth_rate_limits (
+  key TEXT PRIMARY KEY,
+  count INT NOT NULL,
+  reset_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_rate_limits_reset_at ON auth_rate_limits(reset_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS impersonation_audit;
+DELETE FROM permissions WHERE key = 'users:impersonate';
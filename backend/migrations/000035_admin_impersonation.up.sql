@@ -0,0 +1,22 @@
+INSERT INTO permissions (key, description) VALUES
+  ('users:impersonate', 'Issue short-lived tokens acting as another user (support debugging)')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'users:impersonate')
+ON CONFLICT DO NOTHING;
+
+-- Every request made with an impersonation token. No FKs: audit rows outlive the users involved.
+CREATE TABLE IF NOT EXISTS impersonation_audit (
+  id BIGSERIAL PRIMARY KEY,
+  session_id UUID,
+  admin_user_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  method TEXT NOT NULL,
+  path TEXT NOT NULL,
+  status INT NOT NULL,
+  ip TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_admin ON impersonation_audit(admin_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_user ON impersonation_audit(user_id, created_at DESC);
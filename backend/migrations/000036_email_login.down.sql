@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS email_login_tokens;
+
+DELETE FROM oauth_identities WHERE provider = 'email';
+ALTER TABLE oauth_identities DROP CONSTRAINT IF EXISTS oauth_identities_provider_check;
+ALTER TABLE oauth_identities
+  ADD CONSTRAINT oauth_identities_provider_check CHECK (provider IN ('google', 'discord'));
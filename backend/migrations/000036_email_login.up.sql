@@ -0,0 +1,15 @@
+-- Email becomes an identity provider (magic-link login); the subject is the lowercased address.
+ALTER TABLE oauth_identities DROP CONSTRAINT IF EXISTS oauth_identities_provider_check;
+ALTER TABLE oauth_identities
+  ADD CONSTRAINT oauth_identities_provider_check CHECK (provider IN ('google', 'discord', 'email'));
+
+-- Single-use magic-link tokens. Only the SHA-256 of the token is stored.
+CREATE TABLE IF NOT EXISTS email_login_tokens (
+  token_hash TEXT PRIMARY KEY,
+  email TEXT NOT NULL,
+  redirect_uri TEXT,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_login_tokens_expires ON email_login_tokens(expires_at);
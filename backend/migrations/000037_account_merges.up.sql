@@ -0,0 +1,13 @@
+-- Audit trail of account merges (source folded into target, source row deleted).
+-- No FKs: the source user no longer exists after a merge.
+CREATE TABLE IF NOT EXISTS account_merges (
+  id BIGSERIAL PRIMARY KEY,
+  target_user_id UUID NOT NULL,
+  source_user_id UUID NOT NULL,
+  kyc_strategy TEXT NOT NULL,
+  details JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_merges_target ON account_merges(target_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_account_merges_source ON account_merges(source_user_id);
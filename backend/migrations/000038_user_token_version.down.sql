@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE key = 'users:force_logout';
+
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
@@ -0,0 +1,9 @@
+-- Bumping token_version invalidates every outstanding JWT for the user (tokens carry it as the "tv" claim).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+
+INSERT INTO permissions (key, description) VALUES
+  ('users:force_logout', 'Invalidate all outstanding tokens for a user')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'users:force_logout')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+-- Narrowly-scoped, long-lived tokens minted by maintainers for dashboards/widgets (jti = scoped_tokens.id).
+CREATE TABLE IF NOT EXISTS scoped_tokens (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  scopes TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scoped_tokens_project ON scoped_tokens(project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scoped_tokens_user ON scoped_tokens(user_id);
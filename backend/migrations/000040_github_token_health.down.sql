@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_github_accounts_token_checked;
+
+ALTER TABLE github_accounts
+  DROP COLUMN IF EXISTS refresh_token_expires_at,
+  DROP COLUMN IF EXISTS refresh_token,
+  DROP COLUMN IF EXISTS token_expires_at,
+  DROP COLUMN IF EXISTS token_checked_at,
+  DROP COLUMN IF EXISTS token_status;
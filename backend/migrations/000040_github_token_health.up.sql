@@ -0,0 +1,10 @@
+-- Track GitHub OAuth token health so sync stops using revoked tokens, and store refresh tokens
+-- for GitHub Apps with user-to-server token expiration enabled.
+ALTER TABLE github_accounts
+  ADD COLUMN IF NOT EXISTS token_status TEXT NOT NULL DEFAULT 'active' CHECK (token_status IN ('active', 'revoked')),
+  ADD COLUMN IF NOT EXISTS token_checked_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS token_expires_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS refresh_token BYTEA,
+  ADD COLUMN IF NOT EXISTS refresh_token_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_github_accounts_token_checked ON github_accounts(token_checked_at NULLS FIRST) WHERE token_status = 'active';
@@ -0,0 +1,3 @@
+ALTER TABLE github_pull_requests
+  DROP COLUMN IF EXISTS reviews_count,
+  DROP COLUMN IF EXISTS reviews;
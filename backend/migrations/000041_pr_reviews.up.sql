@@ -0,0 +1,4 @@
+-- PR reviews fetched by the GraphQL sync (REST sync leaves these untouched).
+ALTER TABLE github_pull_requests
+  ADD COLUMN IF NOT EXISTS reviews JSONB NOT NULL DEFAULT '[]'::jsonb,
+  ADD COLUMN IF NOT EXISTS reviews_count INT NOT NULL DEFAULT 0;
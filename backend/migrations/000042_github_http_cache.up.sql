@@ -0,0 +1,11 @@
+-- Conditional-request cache for GitHub REST GETs (keyed by method + URL). A 304 reply does not
+-- count against the rate limit, and the cached body is reused.
+CREATE TABLE IF NOT EXISTS github_http_cache (
+  cache_key TEXT PRIMARY KEY,
+  etag TEXT,
+  last_modified TEXT,
+  body BYTEA NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_http_cache_updated ON github_http_cache(updated_at);
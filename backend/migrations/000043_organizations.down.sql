@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_projects_full_name_lower;
+DROP INDEX IF EXISTS idx_projects_organization;
+ALTER TABLE projects DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,21 @@
+-- GitHub organizations linked with a single org-level webhook, so repos in the org don't need
+-- one hook each. Deliveries are routed to projects by repository full name.
+CREATE TABLE IF NOT EXISTS organizations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  github_org_id BIGINT NOT NULL UNIQUE,
+  login TEXT NOT NULL,
+  owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  webhook_id BIGINT,
+  webhook_url TEXT,
+  webhook_created_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_login ON organizations(lower(login));
+
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_projects_organization ON projects(organization_id) WHERE organization_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_projects_full_name_lower ON projects(lower(github_full_name));
@@ -0,0 +1,7 @@
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS repo_metadata_updated_at,
+  DROP COLUMN IF EXISTS github_language,
+  DROP COLUMN IF EXISTS license_name,
+  DROP COLUMN IF EXISTS license_spdx_id,
+  DROP COLUMN IF EXISTS topics,
+  DROP COLUMN IF EXISTS default_branch;
@@ -0,0 +1,8 @@
+-- Repository metadata captured from GitHub during verification so public listings don't need live calls.
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS default_branch TEXT,
+  ADD COLUMN IF NOT EXISTS topics TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS license_spdx_id TEXT,
+  ADD COLUMN IF NOT EXISTS license_name TEXT,
+  ADD COLUMN IF NOT EXISTS github_language TEXT,
+  ADD COLUMN IF NOT EXISTS repo_metadata_updated_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_projects_repo_metadata_updated;
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS repo_unavailable_at,
+  DROP COLUMN IF EXISTS archived;
+
+DELETE FROM sync_jobs WHERE job_type = 'refresh_repo_metadata';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs'));
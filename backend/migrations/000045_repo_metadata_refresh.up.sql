@@ -0,0 +1,12 @@
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata'));
+
+-- archived mirrors GitHub; repo_unavailable_at is set when the repo 404s on refresh (deleted or made
+-- private) and hides the project from public listings until a later refresh finds it again.
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS repo_unavailable_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_projects_repo_metadata_updated ON projects(repo_metadata_updated_at NULLS FIRST)
+  WHERE status = 'verified' AND deleted_at IS NULL;
@@ -0,0 +1,6 @@
+DELETE FROM sync_jobs WHERE job_type = 'sync_commits';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata'));
+
+DROP TABLE IF EXISTS github_commits;
@@ -0,0 +1,20 @@
+-- Default-branch commits, synced via the commits API and push webhooks, feeding contribution stats.
+CREATE TABLE IF NOT EXISTS github_commits (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  sha TEXT NOT NULL,
+  author_login TEXT,
+  author_name TEXT,
+  author_email TEXT,
+  message TEXT NOT NULL DEFAULT '',
+  url TEXT,
+  committed_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, sha)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_commits_author ON github_commits(author_login, committed_at DESC) WHERE author_login IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_github_commits_project ON github_commits(project_id, committed_at DESC);
+
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits'));
@@ -0,0 +1 @@
+ALTER TABLE github_http_cache DROP COLUMN IF EXISTS link;
@@ -0,0 +1,2 @@
+-- Link header of the cached page, so a 304 still yields the next-page cursor.
+ALTER TABLE github_http_cache ADD COLUMN IF NOT EXISTS link TEXT;
@@ -0,0 +1,11 @@
+-- Per-project incremental sync cursors. last_synced_at is when the last successful sync started;
+-- the next one only fetches items updated since then. last_full_sync_at drives the periodic full
+-- reconciliation that catches anything an incremental pass missed.
+CREATE TABLE IF NOT EXISTS project_sync_cursors (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  resource TEXT NOT NULL CHECK (resource IN ('issues', 'prs')),
+  last_synced_at TIMESTAMPTZ NOT NULL,
+  last_full_sync_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, resource)
+);
@@ -0,0 +1,15 @@
+DELETE FROM oauth_states WHERE kind = 'bitbucket_link';
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'oauth_login', 'oauth_link'));
+
+DELETE FROM projects WHERE provider = 'bitbucket';
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS provider_webhook_id,
+  DROP COLUMN IF EXISTS provider;
+
+DROP TABLE IF EXISTS bitbucket_accounts;
@@ -0,0 +1,26 @@
+-- Bitbucket Cloud as a repository host alongside GitHub. Projects record their provider; for
+-- Bitbucket the workspace/slug is stored in github_full_name, which stays unique across providers.
+CREATE TABLE IF NOT EXISTS bitbucket_accounts (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  bitbucket_uuid TEXT NOT NULL UNIQUE,
+  username TEXT NOT NULL,
+  display_name TEXT,
+  avatar_url TEXT,
+  access_token BYTEA NOT NULL,
+  refresh_token BYTEA NOT NULL,
+  token_expires_at TIMESTAMPTZ NOT NULL,
+  scopes TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'github' CHECK (provider IN ('github', 'bitbucket')),
+  -- Webhook identifier on providers whose IDs aren't numeric (Bitbucket hook UUIDs).
+  ADD COLUMN IF NOT EXISTS provider_webhook_id TEXT;
+
+ALTER TABLE oauth_states
+  DROP CONSTRAINT IF EXISTS oauth_states_kind_check;
+
+ALTER TABLE oauth_states
+  ADD CONSTRAINT oauth_states_kind_check CHECK (kind IN ('github_link', 'github_login', 'github_app_install', 'oauth_login', 'oauth_link', 'bitbucket_link'));
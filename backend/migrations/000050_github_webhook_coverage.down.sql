@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS github_releases;
+DROP TABLE IF EXISTS github_discussions;
+DROP TABLE IF EXISTS github_pr_review_comments;
+DROP TABLE IF EXISTS github_issue_comments;
@@ -0,0 +1,79 @@
+-- Rows kept current by issue_comment, pull_request_review_comment, discussion and release webhooks.
+-- Deletions are soft (deleted_at) so a late "edited" delivery can't resurrect a removed comment.
+CREATE TABLE IF NOT EXISTS github_issue_comments (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_comment_id BIGINT NOT NULL,
+  issue_number INT NOT NULL,
+  is_pull_request BOOLEAN NOT NULL DEFAULT false,
+  author_login TEXT,
+  body TEXT NOT NULL DEFAULT '',
+  url TEXT,
+  created_at_github TIMESTAMPTZ,
+  updated_at_github TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, github_comment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_issue_comments_issue ON github_issue_comments(project_id, issue_number, created_at_github);
+CREATE INDEX IF NOT EXISTS idx_github_issue_comments_author ON github_issue_comments(author_login, created_at_github DESC) WHERE author_login IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS github_pr_review_comments (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_comment_id BIGINT NOT NULL,
+  pr_number INT NOT NULL,
+  review_id BIGINT,
+  in_reply_to_id BIGINT,
+  author_login TEXT,
+  body TEXT NOT NULL DEFAULT '',
+  path TEXT,
+  commit_sha TEXT,
+  url TEXT,
+  created_at_github TIMESTAMPTZ,
+  updated_at_github TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, github_comment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_pr_review_comments_pr ON github_pr_review_comments(project_id, pr_number, created_at_github);
+
+CREATE TABLE IF NOT EXISTS github_discussions (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_discussion_id BIGINT NOT NULL,
+  number INT NOT NULL,
+  title TEXT NOT NULL DEFAULT '',
+  body TEXT NOT NULL DEFAULT '',
+  category TEXT,
+  state TEXT,
+  author_login TEXT,
+  url TEXT,
+  comments_count INT NOT NULL DEFAULT 0,
+  answered_at TIMESTAMPTZ,
+  created_at_github TIMESTAMPTZ,
+  updated_at_github TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, github_discussion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_discussions_project ON github_discussions(project_id, updated_at_github DESC);
+
+CREATE TABLE IF NOT EXISTS github_releases (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  github_release_id BIGINT NOT NULL,
+  tag_name TEXT NOT NULL,
+  name TEXT,
+  body TEXT NOT NULL DEFAULT '',
+  draft BOOLEAN NOT NULL DEFAULT false,
+  prerelease BOOLEAN NOT NULL DEFAULT false,
+  author_login TEXT,
+  url TEXT,
+  created_at_github TIMESTAMPTZ,
+  published_at_github TIMESTAMPTZ,
+  deleted_at TIMESTAMPTZ,
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, github_release_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_releases_project ON github_releases(project_id, published_at_github DESC);
@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE key = 'webhooks:rotate_secret';
+
+DELETE FROM sync_jobs WHERE job_type = 'rotate_webhook_secret';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits'));
+
+DROP INDEX IF EXISTS idx_projects_github_repo_id;
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS webhook_secret_rotated_at,
+  DROP COLUMN IF EXISTS webhook_secret_previous,
+  DROP COLUMN IF EXISTS webhook_secret;
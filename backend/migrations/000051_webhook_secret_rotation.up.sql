@@ -0,0 +1,21 @@
+-- Per-project repo webhook secrets (AES-GCM encrypted like OAuth tokens). NULL means the hook still
+-- uses the global GITHUB_WEBHOOK_SECRET. The previous secret keeps validating for a grace window
+-- after each rotation so in-flight deliveries aren't rejected.
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS webhook_secret BYTEA,
+  ADD COLUMN IF NOT EXISTS webhook_secret_previous BYTEA,
+  ADD COLUMN IF NOT EXISTS webhook_secret_rotated_at TIMESTAMPTZ;
+
+-- Deliveries are matched to their project's secret by repository ID before the signature check.
+CREATE INDEX IF NOT EXISTS idx_projects_github_repo_id ON projects(github_repo_id) WHERE github_repo_id IS NOT NULL;
+
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret'));
+
+INSERT INTO permissions (key, description) VALUES
+  ('webhooks:rotate_secret', 'Rotate a project''s repository webhook secret')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'webhooks:rotate_secret')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+DELETE FROM sync_jobs WHERE job_type = 'reverify_ownership';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret'));
+
+ALTER TABLE projects DROP COLUMN IF EXISTS ownership_checked_at;
@@ -0,0 +1,6 @@
+-- Verified projects are periodically re-checked against the owner's repo permissions.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS ownership_checked_at TIMESTAMPTZ;
+
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret', 'reverify_ownership'));
@@ -0,0 +1,7 @@
+DELETE FROM sync_jobs WHERE job_type = 'post_bot_comments';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret', 'reverify_ownership'));
+
+DROP TABLE IF EXISTS github_bot_comments;
+ALTER TABLE projects DROP COLUMN IF EXISTS bot_comments_enabled;
@@ -0,0 +1,22 @@
+-- Opt-in: post Grainlify bot comments (as the GitHub App) on the project's issues.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS bot_comments_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- One row per automated comment, so each kind is posted at most once per issue.
+CREATE TABLE IF NOT EXISTS github_bot_comments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  issue_number INT NOT NULL,
+  kind TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'posted', 'failed')),
+  github_comment_id BIGINT,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  posted_at TIMESTAMPTZ,
+  UNIQUE (project_id, issue_number, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_bot_comments_pending ON github_bot_comments(project_id) WHERE status = 'pending';
+
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret', 'reverify_ownership', 'post_bot_comments'));
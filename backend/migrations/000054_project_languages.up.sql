@@ -0,0 +1,10 @@
+-- Per-language byte counts from GitHub's /languages endpoint; projects.language stays the single
+-- headline language used for filtering.
+CREATE TABLE IF NOT EXISTS project_languages (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  language TEXT NOT NULL,
+  bytes BIGINT NOT NULL,
+  percentage NUMERIC(5,2) NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, language)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE github_accounts
+  DROP COLUMN IF EXISTS profile_synced_at,
+  DROP COLUMN IF EXISTS profile_url,
+  DROP COLUMN IF EXISTS company,
+  DROP COLUMN IF EXISTS bio,
+  DROP COLUMN IF EXISTS name;
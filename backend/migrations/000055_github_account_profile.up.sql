@@ -0,0 +1,7 @@
+-- GitHub profile fields, refreshed on login/link and whenever /me or a resync fetches the user.
+ALTER TABLE github_accounts
+  ADD COLUMN IF NOT EXISTS name TEXT,
+  ADD COLUMN IF NOT EXISTS bio TEXT,
+  ADD COLUMN IF NOT EXISTS company TEXT,
+  ADD COLUMN IF NOT EXISTS profile_url TEXT,
+  ADD COLUMN IF NOT EXISTS profile_synced_at TIMESTAMPTZ;
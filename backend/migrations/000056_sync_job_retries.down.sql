@@ -0,0 +1,5 @@
+UPDATE sync_jobs SET status = 'pending' WHERE status = 'retrying';
+UPDATE sync_jobs SET status = 'failed' WHERE status = 'dead';
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_status_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_status_check
+  CHECK (status IN ('pending', 'running', 'completed', 'failed'));
@@ -0,0 +1,5 @@
+-- Failed jobs are retried with backoff ('retrying', picked up again at run_at) until they run out
+-- of attempts and become 'dead'. 'failed' is kept for rows from before retries existed.
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_status_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_status_check
+  CHECK (status IN ('pending', 'running', 'retrying', 'completed', 'failed', 'dead'));
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE key = 'jobs:manage';
+DROP INDEX IF EXISTS idx_sync_jobs_dead;
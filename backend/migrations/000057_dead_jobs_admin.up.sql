@@ -0,0 +1,9 @@
+-- Dead (and legacy failed) jobs are listed and requeued from the admin API.
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_dead ON sync_jobs(updated_at DESC) WHERE status IN ('dead', 'failed');
+
+INSERT INTO permissions (key, description) VALUES
+  ('jobs:manage', 'Inspect and requeue dead sync jobs')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'jobs:manage')
+ON CONFLICT DO NOTHING;
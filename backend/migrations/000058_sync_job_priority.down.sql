@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sync_jobs_claim;
+ALTER TABLE projects DROP COLUMN IF EXISTS sync_claimed_at;
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,9 @@
+-- Higher priority jobs are claimed first: 20 for webhook-triggered syncs, 10 for syncs a user or
+-- operator asked for, 0 for backfills and scheduled maintenance.
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+-- Within a priority, the project served longest ago goes first so one large repo can't starve
+-- the rest of the queue.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS sync_claimed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_claim ON sync_jobs(priority DESC, run_at) WHERE status IN ('pending', 'retrying');
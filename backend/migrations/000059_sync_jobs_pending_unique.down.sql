@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uq_sync_jobs_pending;
@@ -0,0 +1,11 @@
+-- At most one pending job per project and job type; enqueues that find one already waiting are
+-- folded into it (ON CONFLICT ... WHERE status = 'pending').
+DELETE FROM sync_jobs j
+USING sync_jobs k
+WHERE j.status = 'pending'
+  AND k.status = 'pending'
+  AND k.project_id = j.project_id
+  AND k.job_type = j.job_type
+  AND (k.created_at, k.id) < (j.created_at, j.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sync_jobs_pending ON sync_jobs(project_id, job_type) WHERE status = 'pending';
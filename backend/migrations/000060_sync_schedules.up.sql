@@ -0,0 +1,15 @@
+-- Recurring syncs configured per project ("every 6 hours"). Runs stay on the grid anchored at the
+-- first next_run_at, so a late tick doesn't push every later run back.
+CREATE TABLE IF NOT EXISTS sync_schedules (
+  project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+  interval_seconds INT NOT NULL CHECK (interval_seconds >= 900),
+  full_sync BOOLEAN NOT NULL DEFAULT true,
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_run_at TIMESTAMPTZ,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_schedules_due ON sync_schedules(next_run_at) WHERE enabled;
@@ -0,0 +1 @@
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS progress;
@@ -0,0 +1,2 @@
+-- Per-page progress of list syncs. A retried job resumes from the saved page cursor.
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS progress JSONB;
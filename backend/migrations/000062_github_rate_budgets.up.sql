@@ -0,0 +1,13 @@
+-- GitHub quota per token and resource, shared by every sync worker. Workers reserve a request
+-- before calling GitHub; response headers write back the authoritative numbers.
+CREATE TABLE IF NOT EXISTS github_rate_budgets (
+  token_key TEXT NOT NULL,
+  resource TEXT NOT NULL,
+  limit_total INT NOT NULL,
+  remaining INT NOT NULL,
+  reset_at TIMESTAMPTZ NOT NULL,
+  observed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (token_key, resource)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_rate_budgets_reset_at ON github_rate_budgets(reset_at);
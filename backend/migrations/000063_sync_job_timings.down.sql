@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_sync_jobs_finished;
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS finished_at;
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,5 @@
+-- When the current (or last) attempt was claimed and when it ended, for runtime and queue latency stats.
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_finished ON sync_jobs(finished_at) WHERE finished_at IS NOT NULL;
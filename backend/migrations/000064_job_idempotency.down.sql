@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_github_bot_comments_pending;
+UPDATE github_bot_comments SET status = 'pending' WHERE status = 'posting';
+ALTER TABLE github_bot_comments DROP COLUMN IF EXISTS job_key;
+ALTER TABLE github_bot_comments DROP CONSTRAINT IF EXISTS github_bot_comments_status_check;
+ALTER TABLE github_bot_comments ADD CONSTRAINT github_bot_comments_status_check
+  CHECK (status IN ('pending', 'posted', 'failed'));
+CREATE INDEX IF NOT EXISTS idx_github_bot_comments_pending ON github_bot_comments(project_id) WHERE status = 'pending';
+
+ALTER TABLE github_events DROP COLUMN IF EXISTS processed_at;
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,15 @@
+-- Stable identity of a job across its retries, recorded on the side effects it performs.
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS idempotency_key UUID NOT NULL DEFAULT gen_random_uuid();
+
+-- Deliveries whose follow-up work (job enqueues, bot comments) has been done; redeliveries skip it.
+ALTER TABLE github_events ADD COLUMN IF NOT EXISTS processed_at TIMESTAMPTZ;
+
+-- 'posting': a job started posting the comment and may have crashed before recording the result.
+-- The next attempt looks for the comment on GitHub before posting again.
+ALTER TABLE github_bot_comments DROP CONSTRAINT IF EXISTS github_bot_comments_status_check;
+ALTER TABLE github_bot_comments ADD CONSTRAINT github_bot_comments_status_check
+  CHECK (status IN ('pending', 'posting', 'posted', 'failed'));
+ALTER TABLE github_bot_comments ADD COLUMN IF NOT EXISTS job_key UUID;
+
+DROP INDEX IF EXISTS idx_github_bot_comments_pending;
+CREATE INDEX IF NOT EXISTS idx_github_bot_comments_pending ON github_bot_comments(project_id) WHERE status IN ('pending', 'posting');
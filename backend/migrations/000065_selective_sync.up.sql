@@ -0,0 +1,6 @@
+-- Manual syncs may start from a given time instead of the project's cursor.
+ALTER TABLE sync_jobs ADD COLUMN IF NOT EXISTS since_override TIMESTAMPTZ;
+
+ALTER TABLE sync_jobs DROP CONSTRAINT IF EXISTS sync_jobs_job_type_check;
+ALTER TABLE sync_jobs ADD CONSTRAINT sync_jobs_job_type_check
+  CHECK (job_type IN ('sync_issues', 'sync_prs', 'sync_comments', 'refresh_repo_metadata', 'sync_commits', 'rotate_webhook_secret', 'reverify_ownership', 'post_bot_comments'));
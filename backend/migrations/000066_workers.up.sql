@@ -0,0 +1,15 @@
+-- One row per sync worker process, refreshed by its heartbeat.
+CREATE TABLE IF NOT EXISTS workers (
+  id TEXT PRIMARY KEY, -- host:pid
+  hostname TEXT NOT NULL,
+  version TEXT NOT NULL,
+  concurrency INT NOT NULL,
+  current_jobs JSONB NOT NULL DEFAULT '[]'::jsonb,
+  jobs_completed BIGINT NOT NULL DEFAULT 0,
+  jobs_failed BIGINT NOT NULL DEFAULT 0,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  stopped_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_workers_heartbeat ON workers(last_heartbeat_at DESC);
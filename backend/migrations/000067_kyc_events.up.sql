@@ -0,0 +1,14 @@
+-- Every users.kyc_status transition, oldest first per user. payload is the raw Didit webhook body or
+-- decision that caused it, when there was one.
+CREATE TABLE IF NOT EXISTS kyc_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  old_status TEXT,
+  new_status TEXT,
+  source TEXT NOT NULL CHECK (source IN ('webhook', 'callback', 'status_poll', 'session_start', 'account_merge', 'admin')),
+  session_id TEXT,
+  payload JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_events_user ON kyc_events(user_id, created_at DESC);
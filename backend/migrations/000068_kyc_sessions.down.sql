@@ -0,0 +1,19 @@
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS kyc_status TEXT CHECK (kyc_status IN ('not_started', 'pending', 'in_review', 'verified', 'rejected', 'expired') OR kyc_status IS NULL),
+  ADD COLUMN IF NOT EXISTS kyc_session_id TEXT UNIQUE,
+  ADD COLUMN IF NOT EXISTS kyc_verified_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS kyc_data JSONB DEFAULT '{}'::jsonb;
+
+UPDATE users u
+SET kyc_status = k.status,
+    kyc_session_id = CASE WHEN k.status = 'expired' THEN NULL ELSE k.session_id END,
+    kyc_verified_at = k.verified_at,
+    kyc_data = k.data
+FROM user_kyc k
+WHERE k.user_id = u.id;
+
+CREATE INDEX IF NOT EXISTS idx_users_kyc_status ON users(kyc_status) WHERE kyc_status IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_kyc_session_id ON users(kyc_session_id) WHERE kyc_session_id IS NOT NULL;
+
+DROP VIEW IF EXISTS user_kyc;
+DROP TABLE IF EXISTS kyc_sessions;
@@ -0,0 +1,36 @@
+-- One row per Didit verification session, replacing the kyc_* columns on users. A user's current
+-- KYC state is their most recently activated session (user_kyc); older rows are kept as history.
+CREATE TABLE IF NOT EXISTS kyc_sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  session_id TEXT UNIQUE, -- Didit session id
+  status TEXT NOT NULL CHECK (status IN ('not_started', 'pending', 'in_review', 'verified', 'rejected', 'expired')),
+  data JSONB NOT NULL DEFAULT '{}'::jsonb,
+  verified_at TIMESTAMPTZ,
+  -- When the session became the user's current one: creation, or an account merge that took it over.
+  activated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_sessions_user ON kyc_sessions(user_id, activated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_kyc_sessions_status ON kyc_sessions(status);
+
+INSERT INTO kyc_sessions (user_id, session_id, status, data, verified_at, activated_at, created_at, updated_at)
+SELECT id, kyc_session_id, kyc_status, COALESCE(kyc_data, '{}'::jsonb), kyc_verified_at, updated_at, updated_at, updated_at
+FROM users
+WHERE kyc_status IS NOT NULL;
+
+CREATE OR REPLACE VIEW user_kyc AS
+SELECT DISTINCT ON (user_id)
+  id, user_id, session_id, status, data, verified_at, activated_at, created_at, updated_at
+FROM kyc_sessions
+ORDER BY user_id, activated_at DESC, created_at DESC;
+
+DROP INDEX IF EXISTS idx_users_kyc_status;
+DROP INDEX IF EXISTS idx_users_kyc_session_id;
+ALTER TABLE users
+  DROP COLUMN IF EXISTS kyc_status,
+  DROP COLUMN IF EXISTS kyc_session_id,
+  DROP COLUMN IF EXISTS kyc_verified_at,
+  DROP COLUMN IF EXISTS kyc_data;
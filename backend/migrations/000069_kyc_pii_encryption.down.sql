@@ -0,0 +1,6 @@
+-- Encrypted data cannot be restored by SQL; decrypt before rolling back.
+ALTER TABLE kyc_events
+  DROP COLUMN IF EXISTS payload_enc;
+
+ALTER TABLE kyc_sessions
+  DROP COLUMN IF EXISTS pii_enc;
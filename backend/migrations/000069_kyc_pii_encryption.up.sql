@@ -0,0 +1,8 @@
+-- Personal data from Didit results is kept AES-GCM encrypted (see internal/kyc); only non-personal
+-- fields such as session_url stay in kyc_sessions.data. Existing rows are encrypted by the API at
+-- startup, since the key is not available to SQL.
+ALTER TABLE kyc_sessions
+  ADD COLUMN IF NOT EXISTS pii_enc BYTEA;
+
+ALTER TABLE kyc_events
+  ADD COLUMN IF NOT EXISTS payload_enc BYTEA;
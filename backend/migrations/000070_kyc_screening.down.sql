@@ -0,0 +1,20 @@
+DELETE FROM permissions WHERE key = 'kyc:manage';
+
+DROP VIEW IF EXISTS user_kyc;
+CREATE VIEW user_kyc AS
+SELECT DISTINCT ON (user_id)
+  id, user_id, session_id, status, data, verified_at, activated_at, created_at, updated_at
+FROM kyc_sessions
+ORDER BY user_id, activated_at DESC, created_at DESC;
+
+ALTER TABLE kyc_sessions
+  DROP COLUMN IF EXISTS screening_override_at,
+  DROP COLUMN IF EXISTS screening_override_reason,
+  DROP COLUMN IF EXISTS screening_override_by,
+  DROP COLUMN IF EXISTS screening_override,
+  DROP COLUMN IF EXISTS screening;
+
+UPDATE kyc_sessions SET status = 'rejected' WHERE status = 'blocked';
+ALTER TABLE kyc_sessions DROP CONSTRAINT IF EXISTS kyc_sessions_status_check;
+ALTER TABLE kyc_sessions ADD CONSTRAINT kyc_sessions_status_check
+  CHECK (status IN ('not_started', 'pending', 'in_review', 'verified', 'rejected', 'expired'));
@@ -0,0 +1,27 @@
+-- Verified sessions are screened (blocked countries, optional sanctions provider) before they count
+-- as verified; a failed screening leaves the session 'blocked' until an admin overrides it.
+ALTER TABLE kyc_sessions DROP CONSTRAINT IF EXISTS kyc_sessions_status_check;
+ALTER TABLE kyc_sessions ADD CONSTRAINT kyc_sessions_status_check
+  CHECK (status IN ('not_started', 'pending', 'in_review', 'verified', 'rejected', 'expired', 'blocked'));
+
+ALTER TABLE kyc_sessions
+  ADD COLUMN IF NOT EXISTS screening JSONB, -- latest screening result, NULL when never screened
+  -- An admin decision on a blocked session; it sticks across later Didit updates of the session.
+  ADD COLUMN IF NOT EXISTS screening_override TEXT CHECK (screening_override IN ('approved', 'rejected')),
+  ADD COLUMN IF NOT EXISTS screening_override_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS screening_override_reason TEXT,
+  ADD COLUMN IF NOT EXISTS screening_override_at TIMESTAMPTZ;
+
+-- The view also gains pii_enc, which readers of the current session need to decrypt its data.
+CREATE OR REPLACE VIEW user_kyc AS
+SELECT DISTINCT ON (user_id)
+  id, user_id, session_id, status, data, verified_at, activated_at, created_at, updated_at, pii_enc, screening, screening_override
+FROM kyc_sessions
+ORDER BY user_id, activated_at DESC, created_at DESC;
+
+INSERT INTO permissions (key, description) VALUES
+  ('kyc:manage', 'Review and override blocked KYC screenings')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'kyc:manage')
+ON CONFLICT DO NOTHING;
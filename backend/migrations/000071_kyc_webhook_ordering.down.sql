@@ -0,0 +1,4 @@
+ALTER TABLE kyc_sessions
+  DROP COLUMN IF EXISTS decided_at;
+
+DROP TABLE IF EXISTS kyc_webhook_events;
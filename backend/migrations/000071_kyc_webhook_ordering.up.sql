@@ -0,0 +1,13 @@
+-- Didit webhooks can be redelivered and arrive out of order with the status poll. Processed
+-- deliveries are remembered so redeliveries are skipped, and each session keeps the time of the
+-- decision it holds so an older one cannot overwrite it.
+CREATE TABLE IF NOT EXISTS kyc_webhook_events (
+  event_id TEXT PRIMARY KEY, -- Didit's event id, or a hash of the delivery body when it has none
+  session_id TEXT NOT NULL,
+  processed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_webhook_events_processed ON kyc_webhook_events(processed_at);
+
+ALTER TABLE kyc_sessions
+  ADD COLUMN IF NOT EXISTS decided_at TIMESTAMPTZ;
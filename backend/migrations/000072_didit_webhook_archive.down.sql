@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS kyc_webhook_events (
+  event_id TEXT PRIMARY KEY,
+  session_id TEXT NOT NULL,
+  processed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_webhook_events_processed ON kyc_webhook_events(processed_at);
+
+INSERT INTO kyc_webhook_events (event_id, session_id, processed_at)
+SELECT DISTINCT ON (event_id) event_id, COALESCE(session_id, ''), COALESCE(processed_at, received_at)
+FROM didit_webhook_events
+WHERE kind = 'webhook' AND event_id IS NOT NULL AND outcome IN ('applied', 'stale')
+ORDER BY event_id, received_at DESC;
+
+DROP TABLE IF EXISTS didit_webhook_events;
@@ -0,0 +1,30 @@
+-- Every Didit webhook delivery and callback redirect, stored before it is processed together with
+-- the outcome, so verification disputes can be audited and deliveries replayed. Replaces
+-- kyc_webhook_events: redeliveries are now detected from the archive.
+CREATE TABLE IF NOT EXISTS didit_webhook_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind TEXT NOT NULL CHECK (kind IN ('webhook', 'callback')),
+  event_id TEXT, -- webhooks: Didit's event id, or a hash of the body when it has none
+  session_id TEXT,
+  event_at TIMESTAMPTZ, -- webhooks: when Didit emitted the event
+  -- The raw body (webhooks) or query string (callbacks); encrypted into payload_enc when a KYC key is set.
+  payload JSONB,
+  payload_enc BYTEA,
+  outcome TEXT NOT NULL DEFAULT 'received'
+    CHECK (outcome IN ('received', 'applied', 'stale', 'duplicate', 'unconfirmed', 'session_not_found', 'failed')),
+  kyc_status TEXT, -- the status the delivery resolved to, when it got that far
+  error TEXT,
+  attempts INT NOT NULL DEFAULT 0,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_didit_webhook_events_event ON didit_webhook_events(event_id) WHERE event_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_didit_webhook_events_session ON didit_webhook_events(session_id, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_didit_webhook_events_received ON didit_webhook_events(received_at DESC);
+
+INSERT INTO didit_webhook_events (kind, event_id, session_id, outcome, attempts, received_at, processed_at)
+SELECT 'webhook', event_id, session_id, 'applied', 1, processed_at, processed_at
+FROM kyc_webhook_events;
+
+DROP TABLE IF EXISTS kyc_webhook_events;
@@ -0,0 +1,20 @@
+-- Project members: the owner plus co-maintainers and viewers. projects.owner_user_id stays the
+-- canonical owner (syncs and webhooks act with their token); the owner also gets a member row so
+-- the list is complete. Invitations are rows with accepted_at still NULL.
+CREATE TABLE IF NOT EXISTS project_members (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role TEXT NOT NULL CHECK (role IN ('owner', 'maintainer', 'viewer')),
+  invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  accepted_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user ON project_members(user_id);
+
+INSERT INTO project_members (project_id, user_id, role, accepted_at)
+SELECT id, owner_user_id, 'owner', now()
+FROM projects
+ON CONFLICT (project_id, user_id) DO NOTHING;
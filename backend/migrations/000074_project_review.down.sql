@@ -0,0 +1,15 @@
+DELETE FROM permissions WHERE key = 'projects:review';
+
+DROP INDEX IF EXISTS idx_projects_pending_review;
+
+UPDATE projects SET status = 'pending_verification' WHERE status = 'pending_review';
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+  CHECK (status IN ('pending_verification', 'verified', 'rejected'));
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS review_reason,
+  DROP COLUMN IF EXISTS reviewed_at,
+  DROP COLUMN IF EXISTS reviewed_by,
+  DROP COLUMN IF EXISTS approved_at;
@@ -0,0 +1,24 @@
+-- Admin review of project submissions. With PROJECT_REVIEW_REQUIRED, a project that passes
+-- verification waits in pending_review until an admin approves it; approved_at records that, so
+-- later re-verifications (ownership loss, app reinstall) do not queue it again. A rejected project
+-- keeps the reason for its owner and goes back to the queue when re-verified.
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+  CHECK (status IN ('pending_verification', 'pending_review', 'verified', 'rejected'));
+
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS review_reason TEXT;
+
+UPDATE projects SET approved_at = COALESCE(verified_at, created_at) WHERE status = 'verified';
+
+CREATE INDEX IF NOT EXISTS idx_projects_pending_review ON projects(verified_at) WHERE status = 'pending_review';
+
+INSERT INTO permissions (key, description) VALUES
+  ('projects:review', 'Approve or reject project submissions')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'projects:review')
+ON CONFLICT DO NOTHING;
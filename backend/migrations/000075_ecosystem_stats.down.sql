@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ecosystem_stats;
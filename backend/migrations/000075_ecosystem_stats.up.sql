@@ -0,0 +1,17 @@
+-- Precomputed aggregates for the public ecosystem detail page. Rows are rebuilt periodically by the
+-- sync worker; the counts cover verified, listed projects only, matching the public projects list.
+CREATE TABLE IF NOT EXISTS ecosystem_stats (
+  ecosystem_id UUID PRIMARY KEY REFERENCES ecosystems(id) ON DELETE CASCADE,
+  project_count INT NOT NULL DEFAULT 0,
+  contributors_count INT NOT NULL DEFAULT 0,
+  open_issues_count INT NOT NULL DEFAULT 0,
+  open_prs_count INT NOT NULL DEFAULT 0,
+  merged_prs_count INT NOT NULL DEFAULT 0,
+  -- [{"name": "Rust", "bytes": 123, "percentage": 45.6}], largest first
+  top_languages JSONB NOT NULL DEFAULT '[]'::jsonb,
+  -- [{"month": "2026-01", "issues": 1, "prs": 2, "contributors": 3}], oldest first, last 12 months
+  contribution_trend JSONB NOT NULL DEFAULT '[]'::jsonb,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ecosystem_stats_computed_at ON ecosystem_stats(computed_at);
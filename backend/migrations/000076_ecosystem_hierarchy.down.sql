@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS ecosystem_closure;
+DROP INDEX IF EXISTS idx_ecosystems_parent_id;
+ALTER TABLE ecosystems DROP CONSTRAINT IF EXISTS ecosystems_parent_not_self;
+ALTER TABLE ecosystems DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,22 @@
+-- Ecosystems can nest (e.g. Stellar -> Soroban). Cycles are rejected by the admin API; the closure
+-- view also stops at a fixed depth so a bad row can't make it recurse forever.
+ALTER TABLE ecosystems
+  ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES ecosystems(id) ON DELETE SET NULL;
+
+ALTER TABLE ecosystems DROP CONSTRAINT IF EXISTS ecosystems_parent_not_self;
+ALTER TABLE ecosystems ADD CONSTRAINT ecosystems_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_ecosystems_parent_id ON ecosystems(parent_id) WHERE parent_id IS NOT NULL;
+
+-- One row per (ancestor, descendant) pair, including each ecosystem paired with itself at depth 0,
+-- so roll-ups join projects on descendant_id and group by ancestor_id.
+CREATE OR REPLACE VIEW ecosystem_closure AS
+WITH RECURSIVE tree (ancestor_id, descendant_id, depth) AS (
+  SELECT id, id, 0 FROM ecosystems
+  UNION ALL
+  SELECT t.ancestor_id, e.id, t.depth + 1
+  FROM tree t
+  JOIN ecosystems e ON e.parent_id = t.descendant_id
+  WHERE t.depth < 16
+)
+SELECT ancestor_id, descendant_id, depth FROM tree;
@@ -0,0 +1,6 @@
+-- projects.tags keeps the canonical slugs; the original spellings are not restored.
+DELETE FROM permissions WHERE key = 'tags:manage';
+
+DROP TABLE IF EXISTS project_tags;
+DROP TABLE IF EXISTS tag_aliases;
+DROP TABLE IF EXISTS tags;
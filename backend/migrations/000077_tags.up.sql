@@ -0,0 +1,84 @@
+-- Canonical project tags. projects.tags (JSONB) stays the read path and now holds canonical slugs;
+-- tags/tag_aliases resolve free-form input ("Rust", "rust lang") to a slug, and project_tags indexes
+-- which projects carry which tag. Slug normalization matches tags.Normalize in Go.
+CREATE TABLE IF NOT EXISTS tags (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  slug TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Normalized spellings that resolve to a tag. Every tag is an alias of itself.
+CREATE TABLE IF NOT EXISTS tag_aliases (
+  alias TEXT PRIMARY KEY,
+  tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_id ON tag_aliases(tag_id);
+
+CREATE TABLE IF NOT EXISTS project_tags (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_tags_tag_id ON project_tags(tag_id);
+
+-- Backfill from the existing free-form tags.
+CREATE TEMP TABLE tag_backfill AS
+SELECT p.id AS project_id, trim(e.value) AS name, e.pos,
+       trim(both '-' from regexp_replace(
+         regexp_replace(lower(trim(e.value)), '[^a-z0-9+#.\s_-]', '', 'g'),
+         '[\s_-]+', '-', 'g')) AS slug
+FROM projects p,
+     jsonb_array_elements_text(CASE WHEN jsonb_typeof(p.tags) = 'array' THEN p.tags ELSE '[]'::jsonb END)
+       WITH ORDINALITY AS e(value, pos);
+
+DELETE FROM tag_backfill WHERE slug = '';
+
+-- Display name: the most common original spelling of each slug.
+INSERT INTO tags (slug, name)
+SELECT DISTINCT ON (slug) slug, name
+FROM (SELECT slug, name, COUNT(*) AS uses FROM tag_backfill GROUP BY slug, name) s
+ORDER BY slug, uses DESC, name
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO tag_aliases (alias, tag_id)
+SELECT slug, id FROM tags
+ON CONFLICT (alias) DO NOTHING;
+
+INSERT INTO project_tags (project_id, tag_id)
+SELECT DISTINCT b.project_id, t.id
+FROM tag_backfill b
+JOIN tags t ON t.slug = b.slug
+ON CONFLICT DO NOTHING;
+
+-- Rewrite the JSONB to canonical slugs, deduplicated, in first-seen order.
+UPDATE projects p
+SET tags = '[]'::jsonb
+WHERE jsonb_typeof(p.tags) = 'array' AND jsonb_array_length(p.tags) > 0;
+
+UPDATE projects p
+SET tags = x.tags
+FROM (
+  SELECT project_id, jsonb_agg(slug ORDER BY pos) AS tags
+  FROM (
+    SELECT DISTINCT ON (project_id, slug) project_id, slug, pos
+    FROM tag_backfill
+    ORDER BY project_id, slug, pos
+  ) d
+  GROUP BY project_id
+) x
+WHERE x.project_id = p.id;
+
+DROP TABLE tag_backfill;
+
+INSERT INTO permissions (key, description) VALUES
+  ('tags:manage', 'Manage project tags, aliases and merges')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'tags:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+UPDATE projects SET status = 'verified' WHERE status = 'archived';
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS archived_by,
+  DROP COLUMN IF EXISTS archived_at;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+  CHECK (status IN ('pending_verification', 'pending_review', 'verified', 'rejected'));
@@ -0,0 +1,10 @@
+-- Owners can archive a verified project: it leaves public listings and is no longer synced or fed by
+-- webhooks, but its issues, PRs and commits stay and keep counting toward contributor profiles.
+-- Unlike projects.archived (which mirrors GitHub's repo flag), this is a Grainlify state.
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+ALTER TABLE projects ADD CONSTRAINT projects_status_check
+  CHECK (status IN ('pending_verification', 'pending_review', 'verified', 'rejected', 'archived'));
+
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES users(id) ON DELETE SET NULL;
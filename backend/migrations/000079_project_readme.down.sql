@@ -0,0 +1,6 @@
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS readme_fetched_at,
+  DROP COLUMN IF EXISTS readme_sha,
+  DROP COLUMN IF EXISTS readme_path,
+  DROP COLUMN IF EXISTS readme_html,
+  DROP COLUMN IF EXISTS readme_markdown;
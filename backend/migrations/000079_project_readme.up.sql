@@ -0,0 +1,8 @@
+-- Cached README for the public project page, refreshed with the repo metadata. readme_html is
+-- GitHub's own rendering (sanitized by GitHub); readme_sha lets a refresh skip unchanged READMEs.
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS readme_markdown TEXT,
+  ADD COLUMN IF NOT EXISTS readme_html TEXT,
+  ADD COLUMN IF NOT EXISTS readme_path TEXT,
+  ADD COLUMN IF NOT EXISTS readme_sha TEXT,
+  ADD COLUMN IF NOT EXISTS readme_fetched_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- Opt-in for the public contributor page (GET /contributors/:login). Off by default: a user's
+-- contribution stats are only shown to others once they choose to.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS public_profile BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,19 @@
+-- Precomputed contributor rankings served by GET /leaderboard, rebuilt by the sync worker so the
+-- endpoint never aggregates the issue and PR tables itself. One board per period (rolling 7 days,
+-- rolling 30 days, all time) globally (ecosystem_id NULL) and per active ecosystem, child
+-- ecosystems rolled up into their parents.
+CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+  ecosystem_id UUID REFERENCES ecosystems(id) ON DELETE CASCADE,
+  period TEXT NOT NULL CHECK (period IN ('weekly', 'monthly', 'all')),
+  rank INT NOT NULL,
+  login TEXT NOT NULL,
+  merged_prs INT NOT NULL DEFAULT 0,
+  closed_issues INT NOT NULL DEFAULT 0,
+  score INT NOT NULL DEFAULT 0,
+  -- Names of the ecosystems the contributor was active in during the period (global boards only).
+  ecosystems TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leaderboard_snapshots_board
+  ON leaderboard_snapshots (period, COALESCE(ecosystem_id, '00000000-0000-0000-0000-000000000000'::uuid), rank);
@@ -0,0 +1,13 @@
+-- Badges earned by contributors, awarded by the achievements engine as issues, PRs and commits are
+-- ingested. Keyed by GitHub login so contributors earn badges before they sign up. ecosystem_id is
+-- set for per-ecosystem badges and NULL otherwise; a badge is awarded once per login (and ecosystem).
+CREATE TABLE IF NOT EXISTS user_badges (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  github_login TEXT NOT NULL,
+  badge TEXT NOT NULL,
+  ecosystem_id UUID REFERENCES ecosystems(id) ON DELETE CASCADE,
+  awarded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_badges_unique
+  ON user_badges (LOWER(github_login), badge, COALESCE(ecosystem_id, '00000000-0000-0000-0000-000000000000'::uuid));
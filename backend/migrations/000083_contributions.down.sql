@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_github_commits_last_seen;
+DROP INDEX IF EXISTS idx_github_prs_last_seen;
+DROP INDEX IF EXISTS idx_github_issues_last_seen;
+DROP TABLE IF EXISTS contributions;
@@ -0,0 +1,41 @@
+-- One row per issue, PR or default-branch commit with a known author, so profile, calendar and
+-- activity queries read a single indexed table instead of a UNION over the snapshot tables. Kept in
+-- step by the webhook ingestor and the sync worker (see internal/contributions); issues and PRs are
+-- keyed by their snapshot row id, commits by project and SHA.
+CREATE TABLE IF NOT EXISTS contributions (
+  kind TEXT NOT NULL CHECK (kind IN ('issue', 'pull_request', 'commit')),
+  source_id UUID,
+  sha TEXT,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  author_login TEXT NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  CHECK ((kind = 'commit') = (sha IS NOT NULL) AND (kind = 'commit') = (source_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contributions_source ON contributions (kind, source_id) WHERE source_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contributions_commit ON contributions (project_id, sha) WHERE sha IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contributions_author ON contributions (author_login, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_contributions_project ON contributions (project_id);
+
+-- Incremental refresh reads the snapshot rows a delivery or sync touched.
+CREATE INDEX IF NOT EXISTS idx_github_issues_last_seen ON github_issues (project_id, last_seen_at);
+CREATE INDEX IF NOT EXISTS idx_github_prs_last_seen ON github_pull_requests (project_id, last_seen_at);
+CREATE INDEX IF NOT EXISTS idx_github_commits_last_seen ON github_commits (project_id, last_seen_at);
+
+INSERT INTO contributions (kind, source_id, project_id, author_login, occurred_at)
+SELECT 'issue', id, project_id, author_login, created_at_github
+FROM github_issues
+WHERE author_login IS NOT NULL AND author_login <> '' AND created_at_github IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO contributions (kind, source_id, project_id, author_login, occurred_at)
+SELECT 'pull_request', id, project_id, author_login, created_at_github
+FROM github_pull_requests
+WHERE author_login IS NOT NULL AND author_login <> '' AND created_at_github IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO contributions (kind, sha, project_id, author_login, occurred_at)
+SELECT 'commit', sha, project_id, author_login, committed_at
+FROM github_commits
+WHERE author_login IS NOT NULL AND author_login <> '' AND committed_at IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,20 @@
+-- One backfill per linked GitHub login: attributes contributions recorded before the user joined
+-- (commits with no author login, contributions rows missing for the login) and recomputes badges.
+-- Queued when a GitHub account is linked; drained by the sync worker.
+CREATE TABLE IF NOT EXISTS contribution_backfills (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  github_user_id BIGINT NOT NULL,
+  github_login TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contribution_backfills_login
+  ON contribution_backfills (user_id, LOWER(github_login));
+CREATE INDEX IF NOT EXISTS idx_contribution_backfills_pending
+  ON contribution_backfills (run_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_digests;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_frequency;
@@ -0,0 +1,20 @@
+-- Weekly or monthly contribution digests. users.digest_frequency opts a user into digest emails;
+-- every digest generated for a user (emailed or not) is kept in user_digests, and its global_rank
+-- is what the next digest's rank change is measured against.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS digest_frequency TEXT NOT NULL DEFAULT 'off'
+    CHECK (digest_frequency IN ('off', 'weekly', 'monthly'));
+
+CREATE TABLE IF NOT EXISTS user_digests (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  period TEXT NOT NULL CHECK (period IN ('weekly', 'monthly')),
+  period_start TIMESTAMPTZ NOT NULL,
+  period_end TIMESTAMPTZ NOT NULL,
+  global_rank INT,
+  body JSONB NOT NULL,
+  sent_at TIMESTAMPTZ,
+  send_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, period, period_start)
+);
@@ -0,0 +1,17 @@
+-- Public profile settings, edited through GET/PATCH /profile/settings. A user without a row gets
+-- the defaults: GitHub name and avatar, everything visible. users.public_profile still gates the
+-- contributor page at /contributors/:login; these columns decide what public pages show.
+CREATE TABLE IF NOT EXISTS user_profiles (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  -- Shown instead of the GitHub name on public pages.
+  display_name TEXT,
+  -- Shown instead of the uploaded or GitHub avatar on public pages.
+  avatar_url TEXT,
+  show_bio BOOLEAN NOT NULL DEFAULT true,
+  show_website BOOLEAN NOT NULL DEFAULT true,
+  show_social_links BOOLEAN NOT NULL DEFAULT true,
+  show_kyc_status BOOLEAN NOT NULL DEFAULT true,
+  show_badges BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS soroban_indexer_cursors;
+DROP TABLE IF EXISTS soroban_events;
@@ -0,0 +1,33 @@
+-- Contract events streamed from Soroban RPC by the indexer (cmd/indexer). id is the RPC event id,
+-- so replaying a ledger range upserts the same rows. payload is the decoded event value; amount and
+-- payload_version come from the compat parser, which leaves parse_error set for events it rejects.
+CREATE TABLE IF NOT EXISTS soroban_events (
+  id TEXT PRIMARY KEY,
+  contract_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  ledger BIGINT NOT NULL,
+  ledger_closed_at TIMESTAMPTZ,
+  tx_hash TEXT NOT NULL,
+  in_successful_contract_call BOOLEAN NOT NULL DEFAULT true,
+  topics JSONB NOT NULL DEFAULT '[]'::jsonb,
+  topic_xdr TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+  value_xdr TEXT NOT NULL,
+  payload JSONB,
+  payload_version INT,
+  amount BIGINT,
+  parse_error TEXT,
+  indexed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_soroban_events_contract_ledger ON soroban_events (contract_id, ledger);
+CREATE INDEX IF NOT EXISTS idx_soroban_events_tx_hash ON soroban_events (tx_hash);
+
+-- Where each indexer resumes. cursor is the RPC pagination cursor; when it is NULL the next poll
+-- starts at start_ledger (set on first run, or by a replay).
+CREATE TABLE IF NOT EXISTS soroban_indexer_cursors (
+  name TEXT PRIMARY KEY,
+  start_ledger BIGINT NOT NULL,
+  cursor TEXT,
+  last_ledger BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bounty_events;
+DROP TABLE IF EXISTS bounties;
@@ -0,0 +1,40 @@
+-- Bounties: a reward a project's maintainers attach to one of its synced issues. A bounty moves
+-- open -> claimed (a contributor is assigned) -> paid; claimed can go back to open, and open or
+-- claimed can be cancelled. Every transition is recorded in bounty_events.
+CREATE TABLE IF NOT EXISTS bounties (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  issue_id UUID NOT NULL REFERENCES github_issues(id) ON DELETE CASCADE,
+  -- Stellar amounts have 7 decimal places.
+  amount NUMERIC(38, 7) NOT NULL CHECK (amount > 0),
+  asset TEXT NOT NULL,
+  deadline TIMESTAMPTZ,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'paid', 'cancelled')),
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  claimant_login TEXT,
+  claimed_at TIMESTAMPTZ,
+  paid_at TIMESTAMPTZ,
+  payment_tx_hash TEXT,
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- At most one live bounty per issue.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_issue_active
+  ON bounties (issue_id) WHERE status IN ('open', 'claimed');
+CREATE INDEX IF NOT EXISTS idx_bounties_status_created ON bounties (status, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_bounties_project ON bounties (project_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bounties_claimant ON bounties (LOWER(claimant_login)) WHERE claimant_login IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS bounty_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  from_status TEXT,
+  to_status TEXT NOT NULL,
+  actor_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bounty_events_bounty ON bounty_events (bounty_id, created_at);
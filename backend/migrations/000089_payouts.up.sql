@@ -0,0 +1,36 @@
+-- Payouts: the Stellar payment that settles a claimed bounty. A maintainer approves the payout and
+-- the sync worker builds, signs, submits and confirms the transaction:
+--   pending   -> waiting for a transaction to be built (first attempt, or a retry)
+--   submitted -> tx_hash/envelope_xdr are signed and sent; the outcome is being confirmed
+--   confirmed -> the transaction succeeded in ledger; the bounty is paid
+--   failed    -> gave up (see last_error); a maintainer can approve a new payout
+-- The envelope is stored before it is submitted, so after a crash the same transaction is
+-- re-submitted or looked up instead of paying twice. valid_until is its upper time bound.
+CREATE TABLE IF NOT EXISTS payouts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  destination TEXT NOT NULL,
+  amount NUMERIC(38, 7) NOT NULL CHECK (amount > 0),
+  asset TEXT NOT NULL,
+  asset_issuer TEXT,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'submitted', 'confirmed', 'failed')),
+  source_account TEXT,
+  tx_hash TEXT,
+  envelope_xdr TEXT,
+  valid_until TIMESTAMPTZ,
+  ledger BIGINT,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  submitted_at TIMESTAMPTZ,
+  confirmed_at TIMESTAMPTZ,
+  failed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One payout in flight (or done) per bounty.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_bounty_live ON payouts (bounty_id) WHERE status <> 'failed';
+CREATE INDEX IF NOT EXISTS idx_payouts_due ON payouts (run_at) WHERE status IN ('pending', 'submitted');
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_tx_hash ON payouts (tx_hash) WHERE tx_hash IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bounty_escrow_approvals;
+DROP TABLE IF EXISTS bounty_escrows;
+DROP SEQUENCE IF EXISTS bounty_escrow_onchain_id_seq;
+ALTER TABLE bounties DROP COLUMN IF EXISTS funded_at;
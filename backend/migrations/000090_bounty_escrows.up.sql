@@ -0,0 +1,49 @@
+-- Escrowed bounty funds in the Soroban bounty escrow contract (ESCROW_CONTRACT_ID). A maintainer
+-- initiates a deposit, which reserves the on-chain bounty id; their wallet then calls lock_funds and
+-- the Soroban indexer confirms the f_lock event, marking the bounty funded. Release (to the
+-- claimant) and refund (to the depositor) run once a project maintainer and an admin have both
+-- approved them, and are likewise confirmed from the f_rel / f_ref events.
+ALTER TABLE bounties ADD COLUMN IF NOT EXISTS funded_at TIMESTAMPTZ;
+
+CREATE SEQUENCE IF NOT EXISTS bounty_escrow_onchain_id_seq;
+
+CREATE TABLE IF NOT EXISTS bounty_escrows (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bounty_id UUID NOT NULL REFERENCES bounties(id) ON DELETE CASCADE,
+  contract_id TEXT NOT NULL,
+  -- The contract's u64 bounty_id.
+  onchain_id BIGINT NOT NULL DEFAULT nextval('bounty_escrow_onchain_id_seq'),
+  depositor TEXT NOT NULL,
+  -- Token base units (7 decimals), the contract's i128 amount.
+  amount_units NUMERIC(39, 0) NOT NULL CHECK (amount_units > 0),
+  deadline TIMESTAMPTZ NOT NULL,
+  status TEXT NOT NULL DEFAULT 'awaiting_deposit'
+    CHECK (status IN ('awaiting_deposit', 'abandoned', 'locked', 'released', 'refunded')),
+  lock_tx_hash TEXT,
+  lock_ledger BIGINT,
+  locked_at TIMESTAMPTZ,
+  release_to TEXT,
+  release_tx_hash TEXT,
+  released_at TIMESTAMPTZ,
+  refund_tx_hash TEXT,
+  refunded_at TIMESTAMPTZ,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (contract_id, onchain_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounty_escrows_bounty_active
+  ON bounty_escrows (bounty_id) WHERE status IN ('awaiting_deposit', 'locked');
+
+-- One approval per escrow, action and approver role; both roles are needed to act.
+CREATE TABLE IF NOT EXISTS bounty_escrow_approvals (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  escrow_id UUID NOT NULL REFERENCES bounty_escrows(id) ON DELETE CASCADE,
+  action TEXT NOT NULL CHECK (action IN ('release', 'refund')),
+  approver_role TEXT NOT NULL CHECK (approver_role IN ('maintainer', 'admin')),
+  approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  recipient TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (escrow_id, action, approver_role)
+);
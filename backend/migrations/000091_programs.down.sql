@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE key = 'programs:manage';
+
+DROP TABLE IF EXISTS program_allocations;
+DROP TABLE IF EXISTS program_ecosystems;
+DROP TABLE IF EXISTS program_projects;
+DROP TABLE IF EXISTS programs;
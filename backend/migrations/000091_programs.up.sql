@@ -0,0 +1,63 @@
+-- Reward programs (hackathons, grant rounds): a budget shared among the contributors to eligible
+-- projects during a time window, by the program's scoring rules. A program that lists neither
+-- projects nor ecosystems covers every verified project. Active programs are closed out by the sync
+-- worker after ends_at (plus PROGRAM_CLOSE_DELAY), which writes program_allocations.
+CREATE TABLE IF NOT EXISTS programs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  description TEXT,
+  budget NUMERIC(38, 7) NOT NULL CHECK (budget > 0),
+  asset TEXT NOT NULL,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+  -- Points per contribution kind and the minimum score to share in the budget (programs.Scoring).
+  scoring JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'active', 'closed', 'cancelled')),
+  -- program_id of the program on the program escrow contract; the indexer tracks its balance.
+  escrow_program_id TEXT UNIQUE,
+  escrow_balance_units NUMERIC(39, 0),
+  escrow_ledger BIGINT,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  closed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_programs_status_ends ON programs (status, ends_at);
+
+CREATE TABLE IF NOT EXISTS program_projects (
+  program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  PRIMARY KEY (program_id, project_id)
+);
+
+-- Listing an ecosystem makes the projects of its child ecosystems eligible too.
+CREATE TABLE IF NOT EXISTS program_ecosystems (
+  program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+  ecosystem_id UUID NOT NULL REFERENCES ecosystems(id) ON DELETE CASCADE,
+  PRIMARY KEY (program_id, ecosystem_id)
+);
+
+CREATE TABLE IF NOT EXISTS program_allocations (
+  program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+  rank INT NOT NULL,
+  login TEXT NOT NULL,
+  pull_requests INT NOT NULL DEFAULT 0,
+  merged_pull_requests INT NOT NULL DEFAULT 0,
+  issues INT NOT NULL DEFAULT 0,
+  commits INT NOT NULL DEFAULT 0,
+  score BIGINT NOT NULL,
+  amount NUMERIC(38, 7) NOT NULL,
+  -- amount in token base units, as a program escrow payout takes it.
+  amount_units NUMERIC(39, 0) NOT NULL,
+  PRIMARY KEY (program_id, rank)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_program_allocations_login ON program_allocations (program_id, LOWER(login));
+
+INSERT INTO permissions (key, description) VALUES
+  ('programs:manage', 'Create, edit and cancel reward programs')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'programs:manage')
+ON CONFLICT DO NOTHING;
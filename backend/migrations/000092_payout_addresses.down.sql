@@ -0,0 +1 @@
+DROP TABLE IF EXISTS payout_addresses;
@@ -0,0 +1,23 @@
+-- Stellar accounts users get paid to, kept apart from the wallets they log in with. An address is
+-- pending until the user proves control of it, by signing the challenge message with the account
+-- key or by sending a transaction from it to PAYOUT_VERIFY_ACCOUNT with the challenge code as memo
+-- (detected by cmd/indexer). Bounties can only be claimed by users with a verified address.
+CREATE TABLE IF NOT EXISTS payout_addresses (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  address TEXT NOT NULL,
+  label TEXT,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified')),
+  challenge_code TEXT NOT NULL,
+  challenge_expires_at TIMESTAMPTZ NOT NULL,
+  verification_method TEXT CHECK (verification_method IN ('signature', 'deposit')),
+  verification_tx_hash TEXT,
+  verified_at TIMESTAMPTZ,
+  is_primary BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, address)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payout_addresses_primary ON payout_addresses (user_id) WHERE is_primary;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payout_addresses_challenge ON payout_addresses (challenge_code) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_soroban_events_name_ledger;
+ALTER TABLE soroban_events DROP COLUMN IF EXISTS event_name;
@@ -0,0 +1,7 @@
+-- The registered type of each indexed event (soroban.Events), e.g. bounty_created. NULL for events of
+-- unregistered topics, which only go through the compat parser. A registered event that fails
+-- validation keeps its name and has parse_error set.
+ALTER TABLE soroban_events ADD COLUMN IF NOT EXISTS event_name TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_soroban_events_name_ledger ON soroban_events (event_name, ledger)
+  WHERE event_name IS NOT NULL;
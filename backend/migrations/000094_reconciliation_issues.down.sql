@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE key = 'reconciliation:manage';
+
+ALTER TABLE bounty_escrows DROP COLUMN IF EXISTS reconciled_at;
+ALTER TABLE payouts DROP COLUMN IF EXISTS reconciled_at;
+
+DROP TABLE IF EXISTS reconciliation_issues;
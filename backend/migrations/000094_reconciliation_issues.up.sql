@@ -0,0 +1,44 @@
+-- Mismatches between what Grainlify recorded and what happened on-chain, found by the sync worker's
+-- reconciliation pass:
+--   payouts        confirmed payouts against their transaction on Horizon
+--   bounty escrows released escrows against the escrow_released event the indexer stored
+--   custody        payments sent from the payout custody account against the payouts they settle
+-- subject names what was checked (payout:<id>, escrow:<id> or tx:<hash>). An issue stays open while
+-- the check keeps failing and is resolved automatically once it passes; admins can also resolve or
+-- dismiss it by hand.
+CREATE TABLE IF NOT EXISTS reconciliation_issues (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind TEXT NOT NULL CHECK (kind IN (
+    'missing_tx', 'tx_failed', 'amount_drift', 'destination_mismatch', 'asset_mismatch',
+    'missing_event', 'unknown_payment'
+  )),
+  subject TEXT NOT NULL,
+  payout_id UUID REFERENCES payouts(id) ON DELETE CASCADE,
+  escrow_id UUID REFERENCES bounty_escrows(id) ON DELETE CASCADE,
+  tx_hash TEXT,
+  expected TEXT,
+  actual TEXT,
+  detail TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_at TIMESTAMPTZ,
+  resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  resolution_note TEXT
+);
+
+-- One open issue per kind and subject; a recurrence after resolution opens a new one.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliation_issues_open
+  ON reconciliation_issues (kind, subject) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_issues_status ON reconciliation_issues (status, last_seen_at DESC);
+
+-- When each row was last reconciled; NULL until the first pass.
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMPTZ;
+ALTER TABLE bounty_escrows ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMPTZ;
+
+INSERT INTO permissions (key, description) VALUES
+  ('reconciliation:manage', 'View and resolve payout reconciliation issues')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'reconciliation:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE key = 'assets:manage';
+
+DROP TABLE IF EXISTS assets;
@@ -0,0 +1,27 @@
+-- Assets Grainlify supports, for showing amounts: display metadata and an optional USD price. The
+-- sync worker adds the assets the configuration names (XLM, PAYOUT_ASSET_ISSUERS, ESCROW_ASSET) and
+-- refreshes prices from ASSET_PRICE_SOURCE, each valid until price_expires_at. Admins edit the
+-- metadata and can set a price by hand (price_source 'manual', no expiry).
+CREATE TABLE IF NOT EXISTS assets (
+  code TEXT PRIMARY KEY,
+  issuer TEXT,
+  name TEXT,
+  symbol TEXT NOT NULL,
+  decimals INT NOT NULL DEFAULT 7 CHECK (decimals BETWEEN 0 AND 18),
+  usd_price NUMERIC(38, 12) CHECK (usd_price >= 0),
+  price_source TEXT,
+  price_updated_at TIMESTAMPTZ,
+  price_expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO assets (code, name, symbol, decimals) VALUES ('XLM', 'Stellar Lumens', 'XLM', 7)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO permissions (key, description) VALUES
+  ('assets:manage', 'Edit supported assets and their prices')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'assets:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_bounties_claim_pr;
+
+UPDATE bounties SET status = 'claimed' WHERE status = 'pending_payout';
+
+DROP INDEX IF EXISTS idx_bounties_issue_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_issue_active
+  ON bounties (issue_id) WHERE status IN ('open', 'claimed');
+
+ALTER TABLE bounties
+  DROP COLUMN IF EXISTS claim_submitted_at,
+  DROP COLUMN IF EXISTS claim_pr_url,
+  DROP COLUMN IF EXISTS claim_pr_number;
+
+ALTER TABLE bounties DROP CONSTRAINT IF EXISTS bounties_status_check;
+ALTER TABLE bounties ADD CONSTRAINT bounties_status_check
+  CHECK (status IN ('open', 'claimed', 'paid', 'cancelled'));
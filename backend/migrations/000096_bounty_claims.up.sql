@@ -0,0 +1,18 @@
+-- Bounty claims: a contributor links their merged pull request (POST /bounties/:id/claim) and the
+-- bounty waits in pending_payout for a maintainer to approve the payout, or to send it back.
+ALTER TABLE bounties DROP CONSTRAINT IF EXISTS bounties_status_check;
+ALTER TABLE bounties ADD CONSTRAINT bounties_status_check
+  CHECK (status IN ('open', 'claimed', 'pending_payout', 'paid', 'cancelled'));
+
+ALTER TABLE bounties
+  ADD COLUMN IF NOT EXISTS claim_pr_number INT,
+  ADD COLUMN IF NOT EXISTS claim_pr_url TEXT,
+  ADD COLUMN IF NOT EXISTS claim_submitted_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_bounties_issue_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_issue_active
+  ON bounties (issue_id) WHERE status IN ('open', 'claimed', 'pending_payout');
+
+-- A pull request settles at most one live claim.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bounties_claim_pr
+  ON bounties (project_id, claim_pr_number) WHERE status IN ('pending_payout', 'paid');
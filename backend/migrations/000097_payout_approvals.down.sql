@@ -0,0 +1,16 @@
+DELETE FROM permissions WHERE key = 'payouts:approve';
+
+DROP TABLE IF EXISTS payout_approvals;
+
+UPDATE payouts SET status = 'failed', failed_at = COALESCE(failed_at, now())
+WHERE status IN ('awaiting_approval', 'rejected');
+
+DROP INDEX IF EXISTS idx_payouts_awaiting_approval;
+DROP INDEX IF EXISTS idx_payouts_bounty_live;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_bounty_live ON payouts (bounty_id) WHERE status <> 'failed';
+
+ALTER TABLE payouts DROP COLUMN IF EXISTS approvals_required;
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('pending', 'submitted', 'confirmed', 'failed'));
@@ -0,0 +1,31 @@
+-- Large payouts need several admins. A payout worth more than PAYOUT_APPROVAL_THRESHOLD_USD is
+-- created awaiting_approval with approvals_required set from PAYOUT_APPROVAL_QUORUM; it becomes
+-- pending (and the sync worker sends it) once that many admins approved it, or rejected when one
+-- rejects it. approvals_required is 0 for payouts that need no approvals.
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_check;
+ALTER TABLE payouts ADD CONSTRAINT payouts_status_check
+  CHECK (status IN ('awaiting_approval', 'rejected', 'pending', 'submitted', 'confirmed', 'failed'));
+
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS approvals_required INT NOT NULL DEFAULT 0 CHECK (approvals_required >= 0);
+
+DROP INDEX IF EXISTS idx_payouts_bounty_live;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payouts_bounty_live ON payouts (bounty_id) WHERE status NOT IN ('failed', 'rejected');
+CREATE INDEX IF NOT EXISTS idx_payouts_awaiting_approval ON payouts (created_at) WHERE status = 'awaiting_approval';
+
+-- One decision per payout and admin.
+CREATE TABLE IF NOT EXISTS payout_approvals (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  payout_id UUID NOT NULL REFERENCES payouts(id) ON DELETE CASCADE,
+  approver_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  decision TEXT NOT NULL CHECK (decision IN ('approve', 'reject')),
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (payout_id, approver_id)
+);
+
+INSERT INTO permissions (key, description) VALUES
+  ('payouts:approve', 'Approve or reject payouts above the approval threshold')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'payouts:approve')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS program_deposits;
+
+ALTER TABLE programs DROP COLUMN IF EXISTS deposited;
+
+DROP INDEX IF EXISTS idx_programs_deposit_memo;
+ALTER TABLE programs DROP COLUMN IF EXISTS deposit_memo;
@@ -0,0 +1,34 @@
+-- Program funding by deposit. Each program has a deposit_memo; cmd/indexer watches the accounts in
+-- PROGRAM_DEPOSIT_ACCOUNTS on Horizon, and a payment to one of them with a program's memo is recorded
+-- in program_deposits and, when it is in the program's asset (from its known issuer) and the program
+-- is draft or active, added to the program's budget.
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS deposit_memo TEXT;
+UPDATE programs SET deposit_memo = 'GRL' || upper(substr(md5(gen_random_uuid()::text), 1, 12)) WHERE deposit_memo IS NULL;
+ALTER TABLE programs
+  ALTER COLUMN deposit_memo SET DEFAULT ('GRL' || upper(substr(md5(gen_random_uuid()::text), 1, 12))),
+  ALTER COLUMN deposit_memo SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_programs_deposit_memo ON programs (deposit_memo);
+
+-- Total credited from deposits (already included in budget).
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS deposited NUMERIC(38, 7) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS program_deposits (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+  account TEXT NOT NULL,
+  -- Horizon operation id of the payment; a replayed page cannot credit it twice.
+  operation_id TEXT NOT NULL UNIQUE,
+  tx_hash TEXT NOT NULL,
+  from_account TEXT NOT NULL,
+  amount NUMERIC(38, 7) NOT NULL,
+  asset TEXT NOT NULL,
+  asset_issuer TEXT,
+  ledger BIGINT,
+  credited BOOLEAN NOT NULL,
+  -- Why an uncredited deposit was not added to the budget.
+  reason TEXT,
+  received_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_program_deposits_program ON program_deposits (program_id, received_at DESC);
@@ -0,0 +1,11 @@
+ALTER TABLE assets DROP COLUMN IF EXISTS network;
+ALTER TABLE program_deposits DROP COLUMN IF EXISTS network;
+ALTER TABLE reconciliation_issues DROP COLUMN IF EXISTS network;
+ALTER TABLE payout_addresses DROP COLUMN IF EXISTS network;
+ALTER TABLE payouts DROP COLUMN IF EXISTS network;
+ALTER TABLE bounty_escrows DROP COLUMN IF EXISTS network;
+ALTER TABLE soroban_indexer_cursors DROP COLUMN IF EXISTS network;
+ALTER TABLE soroban_events DROP COLUMN IF EXISTS network;
+
+DROP FUNCTION IF EXISTS bound_stellar_network();
+DROP TABLE IF EXISTS stellar_network;
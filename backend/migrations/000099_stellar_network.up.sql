@@ -0,0 +1,23 @@
+-- The Stellar network (testnet or mainnet) the database holds chain data for. The binaries bind it
+-- at startup (stellarnet.Bind): the first records its SOROBAN_NETWORK here, and a binary configured
+-- for the other network refuses to start, so staging on testnet never touches production's data.
+CREATE TABLE IF NOT EXISTS stellar_network (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  network TEXT NOT NULL CHECK (network IN ('testnet', 'mainnet')),
+  passphrase TEXT NOT NULL,
+  bound_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION bound_stellar_network() RETURNS TEXT
+LANGUAGE sql STABLE AS $$ SELECT network FROM stellar_network $$;
+
+-- Every chain-related row is tagged with its network. New rows take the bound network; rows from
+-- before this migration are tagged when the database is first bound.
+ALTER TABLE soroban_events ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE soroban_indexer_cursors ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE bounty_escrows ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE payout_addresses ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE reconciliation_issues ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE program_deposits ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS network TEXT DEFAULT bound_stellar_network();
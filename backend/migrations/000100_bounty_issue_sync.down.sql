@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_bounties_issue_sync;
+
+ALTER TABLE bounties
+  DROP COLUMN IF EXISTS github_sync_started_at,
+  DROP COLUMN IF EXISTS github_synced_at,
+  DROP COLUMN IF EXISTS github_sync_error,
+  DROP COLUMN IF EXISTS github_synced_status,
+  DROP COLUMN IF EXISTS github_comment_id;
@@ -0,0 +1,19 @@
+-- The sync worker mirrors each bounty's status onto its GitHub issue (a comment it posts once and
+-- then edits, and the bounty labels) for projects with bot comments enabled. github_synced_status is
+-- the status the issue shows; the worker picks up bounties whose status moved past it.
+-- github_sync_started_at is set while a worker updates the issue, so no other worker takes the bounty
+-- until the attempt ends or the lease runs out.
+ALTER TABLE bounties
+  ADD COLUMN IF NOT EXISTS github_comment_id BIGINT,
+  ADD COLUMN IF NOT EXISTS github_synced_status TEXT,
+  ADD COLUMN IF NOT EXISTS github_sync_error TEXT,
+  ADD COLUMN IF NOT EXISTS github_synced_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS github_sync_started_at TIMESTAMPTZ;
+
+-- Bounties that were already settled are not announced after the fact.
+UPDATE bounties SET github_synced_status = status WHERE status IN ('paid', 'cancelled') AND github_synced_status IS NULL;
+
+-- The sweep takes the bounties synced longest ago first, so ones that keep failing do not starve
+-- the rest.
+CREATE INDEX IF NOT EXISTS idx_bounties_issue_sync ON bounties (github_synced_at NULLS FIRST)
+  WHERE github_synced_status IS DISTINCT FROM status;
@@ -0,0 +1,3 @@
+ALTER TABLE bounty_escrows
+  DROP COLUMN IF EXISTS submitting_at,
+  DROP COLUMN IF EXISTS submitting_action;
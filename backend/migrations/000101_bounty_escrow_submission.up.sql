@@ -0,0 +1,7 @@
+-- The escrow action (release or refund) whose contract call is in flight. It is set under the
+-- escrow's row lock once both approvals are in, before the contract is called, so a second approver
+-- cannot call it again; a failed call clears it. An escrow left submitting (the call's transaction
+-- hash could not be recorded) is settled by the indexer when the contract event lands.
+ALTER TABLE bounty_escrows
+  ADD COLUMN IF NOT EXISTS submitting_action TEXT CHECK (submitting_action IN ('release', 'refund')),
+  ADD COLUMN IF NOT EXISTS submitting_at TIMESTAMPTZ;
@@ -230,6 +230,8 @@ export const getUserProfile = () =>
     languages: Array<{ language: string; contribution_count: number }>;
     ecosystems: Array<{ ecosystem_name: string; contribution_count: number }>;
     kyc_verified?: boolean;
+    current_streak?: number;
+    longest_streak?: number;
     rank: {
       position: number | null;
       tier: string;
@@ -238,14 +240,18 @@ export const getUserProfile = () =>
     };
   }>("/profile", { requiresAuth: true });
 
-export const getProfileCalendar = (userId?: string, login?: string) => {
+export const getProfileCalendar = (userId?: string, login?: string, tz?: string) => {
   const params = new URLSearchParams();
   if (userId) params.append("user_id", userId);
   if (login) params.append("login", login);
+  // Bucket days in the viewer's timezone unless told otherwise.
+  const timezone = tz ?? Intl.DateTimeFormat().resolvedOptions().timeZone;
+  if (timezone) params.append("tz", timezone);
   const query = params.toString() ? `?${params.toString()}` : "";
   return apiRequest<{
     calendar: Array<{ date: string; count: number; level: number }>;
     total: number;
+    timezone?: string;
   }>(`/profile/calendar${query}`, { requiresAuth: true });
 };
 
@@ -719,7 +725,12 @@ export const updateEcosystem = (id: string, data: {
   });
 
 // Leaderboard
-export const getLeaderboard = (limit = 10, offset = 0, ecosystem?: string) =>
+export const getLeaderboard = (
+  limit = 10,
+  offset = 0,
+  ecosystem?: string,
+  period: "weekly" | "monthly" | "all" = "all",
+) =>
   apiRequest<
     Array<{
       rank: number;
@@ -729,13 +740,15 @@ export const getLeaderboard = (limit = 10, offset = 0, ecosystem?: string) =>
       avatar: string;
       user_id: string;
       contributions: number;
+      merged_prs: number;
+      closed_issues: number;
       ecosystems: string[];
       score: number;
       trend: "up" | "down" | "same";
       trendValue: number;
     }>
   >(
-    `/leaderboard?limit=${limit}&offset=${offset}${ecosystem ? `&ecosystem=${ecosystem}` : ""
+    `/leaderboard?limit=${limit}&offset=${offset}&period=${period}${ecosystem ? `&ecosystem=${encodeURIComponent(ecosystem)}` : ""
     }`,
   );
 